@@ -0,0 +1,132 @@
+package dynabuf
+
+import (
+	"slices"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WithWriteAliases writes the fields annotated with write_aliases under
+// their aliases too, as well as under their own names, when enabled is
+// true. The value usually comes from a flag flipped at runtime: enabled
+// while some deployments still read only the former names, such as during
+// a rolling deployment of the rename, then disabled once every reader reads
+// the new names, which [Unmarshal] does for fields listing their former
+// names as aliases.
+//
+//	string display_name = 2 [(dynabuf.v1.field) = {
+//	  aliases: ["name"]
+//	  write_aliases: true
+//	}];
+//
+// Items written with it enabled are larger, and [UpdateItemInput] only
+// updates fields under their own names.
+//
+// # Example
+//
+//	item, err := dynabuf.Marshal(account, dynabuf.WithWriteAliases(flags.WriteRenamedAttributes.Load()))
+func WithWriteAliases(enabled bool) Option {
+	return func(o *options) {
+		o.writeAliases = enabled
+	}
+}
+
+// fieldAliases returns the attribute names the field is also read from.
+func fieldAliases(fd protoreflect.FieldDescriptor) []string {
+	opts, _ := proto.GetExtension(fd.Options(), dynabufpb.E_Field).(*dynabufpb.FieldOptions)
+	return opts.GetAliases()
+}
+
+// writesAliases reports whether the field is annotated to be written under
+// its aliases too.
+func writesAliases(fd protoreflect.FieldDescriptor) bool {
+	opts, _ := proto.GetExtension(fd.Options(), dynabufpb.E_Field).(*dynabufpb.FieldOptions)
+	return opts.GetWriteAliases()
+}
+
+// aliasedField returns the field of md read from the attribute with the
+// given name, by its JSON name, proto name, or one of its aliases, or nil.
+func aliasedField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if fd := fieldByName(md, name); fd != nil {
+		return fd
+	}
+	fields := md.Fields()
+	for i := range fields.Len() {
+		if slices.Contains(fieldAliases(fields.Get(i)), name) {
+			return fields.Get(i)
+		}
+	}
+	return nil
+}
+
+// resolveAliases renames the attributes of fields, stored by a message of
+// type md, and of its nested messages, named after aliases of fields to
+// the JSON names of the fields. Attributes named after the fields take
+// precedence over aliases, and the first alias listed over later ones;
+// the other aliases are removed.
+func resolveAliases(md protoreflect.MessageDescriptor, fields map[string]any) {
+	for i := range md.Fields().Len() {
+		fd := md.Fields().Get(i)
+
+		aliases := fieldAliases(fd)
+		if len(aliases) == 0 {
+			continue
+		}
+		_, ok := fields[fd.JSONName()]
+		if _, byName := fields[string(fd.Name())]; byName {
+			ok = true
+		}
+		for _, alias := range aliases {
+			v, found := fields[alias]
+			if !found || alias == fd.JSONName() || alias == string(fd.Name()) {
+				continue
+			}
+			if !ok {
+				fields[fd.JSONName()], ok = v, true
+			}
+			delete(fields, alias)
+		}
+	}
+
+	for name, v := range fields {
+		fd := fieldByName(md, name)
+		if fd == nil {
+			continue
+		}
+		eachValue(fd, v, func(fd protoreflect.FieldDescriptor, v any) {
+			if nested, ok := v.(map[string]any); ok && !isWellKnown(fd.Message()) {
+				resolveAliases(fd.Message(), nested)
+			}
+		})
+	}
+}
+
+// writeAliases copies the attributes of fields, stored by a message of type
+// md, and of its nested messages, annotated with write_aliases to their
+// aliases.
+func writeAliases(md protoreflect.MessageDescriptor, fields map[string]any) {
+	for name, v := range fields {
+		fd := md.Fields().ByJSONName(name)
+		if fd == nil {
+			continue
+		}
+		eachValue(fd, v, func(fd protoreflect.FieldDescriptor, v any) {
+			if nested, ok := v.(map[string]any); ok && !isWellKnown(fd.Message()) {
+				writeAliases(fd.Message(), nested)
+			}
+		})
+	}
+
+	for i := range md.Fields().Len() {
+		fd := md.Fields().Get(i)
+		v, ok := fields[fd.JSONName()]
+		if !ok || !writesAliases(fd) {
+			continue
+		}
+		for _, alias := range fieldAliases(fd) {
+			fields[alias] = v
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		item map[string]types.AttributeValue
+		want *testpb.Account
+	}{
+		{
+			name: "current names",
+			item: map[string]types.AttributeValue{
+				"id":          &types.AttributeValueMemberS{Value: "1"},
+				"displayName": &types.AttributeValueMemberS{Value: "Alice"},
+			},
+			want: &testpb.Account{Id: "1", DisplayName: "Alice"},
+		},
+		{
+			name: "alias",
+			item: map[string]types.AttributeValue{
+				"id":   &types.AttributeValueMemberS{Value: "1"},
+				"name": &types.AttributeValueMemberS{Value: "Alice"},
+			},
+			want: &testpb.Account{Id: "1", DisplayName: "Alice"},
+		},
+		{
+			name: "later alias",
+			item: map[string]types.AttributeValue{
+				"full_name": &types.AttributeValueMemberS{Value: "Alice Smith"},
+			},
+			want: &testpb.Account{DisplayName: "Alice Smith"},
+		},
+		{
+			name: "first alias listed",
+			item: map[string]types.AttributeValue{
+				"name":      &types.AttributeValueMemberS{Value: "Alice"},
+				"full_name": &types.AttributeValueMemberS{Value: "Alice Smith"},
+			},
+			want: &testpb.Account{DisplayName: "Alice"},
+		},
+		{
+			name: "current name over aliases",
+			item: map[string]types.AttributeValue{
+				"displayName": &types.AttributeValueMemberS{Value: "Alice"},
+				"name":        &types.AttributeValueMemberS{Value: "Alicia"},
+			},
+			want: &testpb.Account{DisplayName: "Alice"},
+		},
+		{
+			name: "proto name over aliases",
+			item: map[string]types.AttributeValue{
+				"display_name": &types.AttributeValueMemberS{Value: "Alice"},
+				"name":         &types.AttributeValueMemberS{Value: "Alicia"},
+			},
+			want: &testpb.Account{DisplayName: "Alice"},
+		},
+		{
+			name: "converted field",
+			item: map[string]types.AttributeValue{
+				"amount": &types.AttributeValueMemberN{Value: "12.30"},
+			},
+			want: &testpb.Account{Balance: "12.30"},
+		},
+		{
+			name: "nested messages",
+			item: map[string]types.AttributeValue{
+				"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"street": &types.AttributeValueMemberS{Value: "Main St"},
+				}},
+				"linked": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+					&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+						"id":   &types.AttributeValueMemberS{Value: "2"},
+						"name": &types.AttributeValueMemberS{Value: "Bob"},
+					}},
+				}},
+			},
+			want: &testpb.Account{
+				MailingAddress: &testpb.Address{Street: "Main St"},
+				LinkedAccounts: []*testpb.Account{{Id: "2", DisplayName: "Bob"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var account testpb.Account
+			must.NoError(t, dynabuf.Unmarshal(test.item, &account))
+			must.Eq(t, test.want, &account, must.Cmp(protocmp.Transform()))
+
+			// Fields are only written under their current names.
+			av, err := dynabuf.Marshal(&account)
+			must.NoError(t, err)
+			for _, alias := range []string{"name", "full_name", "amount", "address", "linked"} {
+				must.MapNotContainsKey(t, av.(map[string]types.AttributeValue), alias)
+			}
+		})
+	}
+
+	must.StrContains(t, dynabuf.Explain(&testpb.Account{}), `also read from "name" and "full_name"`)
+}
+
+func TestWriteAliases(t *testing.T) {
+	account := &testpb.Account{
+		Id:             "1",
+		DisplayName:    "Alice",
+		MailingAddress: &testpb.Address{Street: "Main St"},
+		LinkedAccounts: []*testpb.Account{{Id: "2", DisplayName: "Bob"}},
+		Balance:        "12.30",
+	}
+
+	// Fields are only written under their aliases when enabled.
+	av, err := dynabuf.Marshal(account, dynabuf.WithWriteAliases(false))
+	must.NoError(t, err)
+	must.MapNotContainsKeys(t, av.(map[string]types.AttributeValue), []string{"name", "full_name", "address"})
+
+	av, err = dynabuf.Marshal(account, dynabuf.WithWriteAliases(true))
+	must.NoError(t, err)
+	item := av.(map[string]types.AttributeValue)
+
+	for _, name := range []string{"displayName", "name", "full_name"} {
+		must.Eq(t, "Alice", item[name].(*types.AttributeValueMemberS).Value)
+	}
+	must.Eq(t, item["mailingAddress"], item["address"])
+
+	// Nested messages are written under aliases too, and fields without
+	// write_aliases only under their own names.
+	linked := item["linkedAccounts"].(*types.AttributeValueMemberL).Value[0].(*types.AttributeValueMemberM).Value
+	must.Eq(t, "Bob", linked["name"].(*types.AttributeValueMemberS).Value)
+	must.MapNotContainsKeys(t, item, []string{"linked", "amount"})
+
+	// Items written under both names read as the written message.
+	var got testpb.Account
+	must.NoError(t, dynabuf.Unmarshal(item, &got))
+	must.Eq(t, account, &got, must.Cmp(protocmp.Transform()))
+}
@@ -0,0 +1,360 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrRecursiveMessage is returned for messages that contain themselves,
+// which cannot be described by a columnar schema.
+var ErrRecursiveMessage = errors.New("analytics: recursive messages are not supported")
+
+// Schema returns the Arrow schema of messages of the given type, with one
+// column per field named after the field.
+//
+// Nested messages are structs, repeated fields are lists, and map fields
+// are maps. Enums are stored by name, timestamps and durations with
+// microsecond precision, and well-known types whose shape is not known in
+// advance, such as google.protobuf.Struct, as their protobuf JSON encoding.
+// Fields with explicit presence, such as messages and optional scalars, are
+// nullable.
+func Schema(md protoreflect.MessageDescriptor) (*arrow.Schema, error) {
+	fields, err := structFields(md, map[protoreflect.FullName]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// structFields returns the Arrow fields of the fields of a message.
+func structFields(md protoreflect.MessageDescriptor, visiting map[protoreflect.FullName]bool) ([]arrow.Field, error) {
+	if visiting[md.FullName()] {
+		return nil, fmt.Errorf("%w: %s", ErrRecursiveMessage, md.FullName())
+	}
+	visiting[md.FullName()] = true
+	defer delete(visiting, md.FullName())
+
+	fds := md.Fields()
+	fields := make([]arrow.Field, fds.Len())
+	for i := range fds.Len() {
+		fd := fds.Get(i)
+
+		typ, err := fieldType(fd, visiting)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[i] = arrow.Field{
+			Name: string(fd.Name()),
+			Type: typ,
+			// Repeated and map fields are empty rather than null.
+			Nullable: !fd.IsList() && !fd.IsMap() && fd.HasPresence(),
+		}
+	}
+	return fields, nil
+}
+
+// fieldType returns the Arrow type of a field.
+func fieldType(fd protoreflect.FieldDescriptor, visiting map[protoreflect.FullName]bool) (arrow.DataType, error) {
+	switch {
+	case fd.IsMap():
+		key, err := valueType(fd.MapKey(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		item, err := valueType(fd.MapValue(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return arrow.MapOf(key, item), nil
+	case fd.IsList():
+		elem, err := valueType(fd, visiting)
+		if err != nil {
+			return nil, err
+		}
+		return arrow.ListOf(elem), nil
+	default:
+		return valueType(fd, visiting)
+	}
+}
+
+// valueType returns the Arrow type of a single value of a field, ignoring
+// whether the field is repeated.
+func valueType(fd protoreflect.FieldDescriptor, visiting map[protoreflect.FullName]bool) (arrow.DataType, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return arrow.PrimitiveTypes.Int32, nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return arrow.PrimitiveTypes.Uint32, nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return arrow.PrimitiveTypes.Int64, nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case protoreflect.FloatKind:
+		return arrow.PrimitiveTypes.Float32, nil
+	case protoreflect.DoubleKind:
+		return arrow.PrimitiveTypes.Float64, nil
+	case protoreflect.StringKind:
+		return arrow.BinaryTypes.String, nil
+	case protoreflect.BytesKind:
+		return arrow.BinaryTypes.Binary, nil
+	case protoreflect.EnumKind:
+		// Enums are stored by name, as dynabuf stores them in items.
+		return arrow.BinaryTypes.String, nil
+	}
+
+	md := fd.Message()
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		return arrow.FixedWidthTypes.Timestamp_us, nil
+	case "google.protobuf.Duration":
+		return arrow.FixedWidthTypes.Duration_us, nil
+	case "google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue",
+		"google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value",
+		"google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		return valueType(md.Fields().ByName("value"), visiting)
+	}
+
+	if isJSONMessage(md) {
+		return arrow.BinaryTypes.String, nil
+	}
+
+	fields, err := structFields(md, visiting)
+	if err != nil {
+		return nil, err
+	}
+	return arrow.StructOf(fields...), nil
+}
+
+// isJSONMessage reports whether values of the message are stored as their
+// protobuf JSON encoding, because their shape is not known in advance.
+func isJSONMessage(md protoreflect.MessageDescriptor) bool {
+	switch md.FullName() {
+	case "google.protobuf.Struct", "google.protobuf.Value", "google.protobuf.ListValue",
+		"google.protobuf.Any", "google.protobuf.FieldMask":
+		return true
+	default:
+		return false
+	}
+}
+
+// RecordBuilder builds Arrow record batches from messages of type T, with
+// the schema returned by [Schema].
+//
+// A RecordBuilder is not safe for concurrent use.
+type RecordBuilder[T proto.Message] struct {
+	builder *array.RecordBuilder
+	rows    int
+}
+
+// NewRecordBuilder returns a [RecordBuilder] allocating memory with mem,
+// such as [memory.DefaultAllocator]. It must be released once it is no
+// longer needed.
+//
+// # Example
+//
+//	b, _ := analytics.NewRecordBuilder[*example.User](memory.DefaultAllocator)
+//	defer b.Release()
+//
+//	for _, user := range users {
+//	  _ = b.Append(user)
+//	}
+//
+//	rec := b.NewRecord()
+//	defer rec.Release()
+func NewRecordBuilder[T proto.Message](mem memory.Allocator) (*RecordBuilder[T], error) {
+	var zero T
+
+	schema, err := Schema(zero.ProtoReflect().Descriptor())
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordBuilder[T]{builder: array.NewRecordBuilder(mem, schema)}, nil
+}
+
+// Schema returns the schema of the records built.
+func (b *RecordBuilder[T]) Schema() *arrow.Schema {
+	return b.builder.Schema()
+}
+
+// Append appends msg as a row of the next record. If it returns an error,
+// the builder is left with a partial row and must not be used further.
+func (b *RecordBuilder[T]) Append(msg T) error {
+	if err := appendMessage(b.builder.Fields(), msg.ProtoReflect()); err != nil {
+		return err
+	}
+	b.rows++
+	return nil
+}
+
+// Len returns the number of rows appended since the last record was built.
+func (b *RecordBuilder[T]) Len() int {
+	return b.rows
+}
+
+// NewRecord returns a record of the rows appended since the last record was
+// built, and resets the builder. The record must be released once it is no
+// longer needed.
+func (b *RecordBuilder[T]) NewRecord() arrow.Record {
+	b.rows = 0
+	return b.builder.NewRecord()
+}
+
+// Release releases the memory held by the builder.
+func (b *RecordBuilder[T]) Release() {
+	b.builder.Release()
+}
+
+// NewRecord returns a record batch of msgs, allocating memory with mem. The
+// record must be released once it is no longer needed.
+func NewRecord[T proto.Message](mem memory.Allocator, msgs []T) (arrow.Record, error) {
+	b, err := NewRecordBuilder[T](mem)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Release()
+
+	for _, msg := range msgs {
+		if err := b.Append(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.NewRecord(), nil
+}
+
+// appendMessage appends the fields of msg to the builders of its fields.
+func appendMessage(builders []array.Builder, msg protoreflect.Message) error {
+	fds := msg.Descriptor().Fields()
+	for i := range fds.Len() {
+		if err := appendField(builders[i], msg, fds.Get(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendField appends the value of a field of msg to b.
+func appendField(b array.Builder, msg protoreflect.Message, fd protoreflect.FieldDescriptor) error {
+	switch {
+	case fd.IsMap():
+		mb := b.(*array.MapBuilder)
+		mb.Append(true)
+
+		var err error
+		msg.Get(fd).Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			if err = appendValue(mb.KeyBuilder(), fd.MapKey(), k.Value()); err != nil {
+				return false
+			}
+			err = appendValue(mb.ItemBuilder(), fd.MapValue(), v)
+			return err == nil
+		})
+		return err
+	case fd.IsList():
+		lb := b.(*array.ListBuilder)
+		lb.Append(true)
+
+		list := msg.Get(fd).List()
+		for i := range list.Len() {
+			if err := appendValue(lb.ValueBuilder(), fd, list.Get(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case fd.HasPresence() && !msg.Has(fd):
+		b.AppendNull()
+		return nil
+	default:
+		return appendValue(b, fd, msg.Get(fd))
+	}
+}
+
+// appendValue appends a single value of a field to b.
+func appendValue(b array.Builder, fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		b.(*array.BooleanBuilder).Append(v.Bool())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		b.(*array.Int32Builder).Append(int32(v.Int()))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		b.(*array.Uint32Builder).Append(uint32(v.Uint()))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		b.(*array.Int64Builder).Append(v.Int())
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		b.(*array.Uint64Builder).Append(v.Uint())
+	case protoreflect.FloatKind:
+		b.(*array.Float32Builder).Append(float32(v.Float()))
+	case protoreflect.DoubleKind:
+		b.(*array.Float64Builder).Append(v.Float())
+	case protoreflect.StringKind:
+		b.(*array.StringBuilder).Append(v.String())
+	case protoreflect.BytesKind:
+		b.(*array.BinaryBuilder).Append(v.Bytes())
+	case protoreflect.EnumKind:
+		name := fmt.Sprint(int32(v.Enum()))
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			name = string(ev.Name())
+		}
+		b.(*array.StringBuilder).Append(name)
+	default:
+		return appendMessageValue(b, v.Message())
+	}
+	return nil
+}
+
+// appendMessageValue appends a message value to b.
+func appendMessageValue(b array.Builder, msg protoreflect.Message) error {
+	md := msg.Descriptor()
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		seconds, nanos := timeFields(msg)
+		b.(*array.TimestampBuilder).Append(arrow.Timestamp(seconds*1e6 + nanos/1e3))
+		return nil
+	case "google.protobuf.Duration":
+		seconds, nanos := timeFields(msg)
+		b.(*array.DurationBuilder).Append(arrow.Duration(seconds*1e6 + nanos/1e3))
+		return nil
+	case "google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue",
+		"google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value",
+		"google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		fd := md.Fields().ByName("value")
+		return appendValue(b, fd, msg.Get(fd))
+	}
+
+	if isJSONMessage(md) {
+		data, err := protojson.Marshal(msg.Interface())
+		if err != nil {
+			return fmt.Errorf("analytics: failed to encode %s as JSON: %w", md.FullName(), err)
+		}
+		b.(*array.StringBuilder).Append(string(data))
+		return nil
+	}
+
+	sb := b.(*array.StructBuilder)
+	sb.Append(true)
+
+	builders := make([]array.Builder, sb.NumField())
+	for i := range builders {
+		builders[i] = sb.FieldBuilder(i)
+	}
+	return appendMessage(builders, msg)
+}
+
+// timeFields returns the seconds and nanos fields of a google.protobuf.Timestamp
+// or google.protobuf.Duration.
+func timeFields(msg protoreflect.Message) (int64, int64) {
+	fds := msg.Descriptor().Fields()
+	return msg.Get(fds.ByName("seconds")).Int(), msg.Get(fds.ByName("nanos")).Int()
+}
@@ -0,0 +1,75 @@
+package analytics_test
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/picatz/dynabuf/analytics"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestSchema(t *testing.T) {
+	schema, err := analytics.Schema((&testpb.User{}).ProtoReflect().Descriptor())
+	must.NoError(t, err)
+
+	must.Eq(t, 6, schema.NumFields())
+
+	age := schema.Field(2)
+	must.Eq(t, "age", age.Name)
+	must.Eq(t, arrow.INT32, age.Type.ID())
+	must.False(t, age.Nullable)
+
+	address := schema.Field(3)
+	must.Eq(t, arrow.STRUCT, address.Type.ID())
+	must.True(t, address.Nullable)
+
+	must.Eq(t, arrow.LIST, schema.Field(4).Type.ID())
+	must.Eq(t, arrow.TIMESTAMP, schema.Field(5).Type.ID())
+
+	// Recursive messages cannot be described.
+	_, err = analytics.Schema((&descriptorpb.DescriptorProto{}).ProtoReflect().Descriptor())
+	must.ErrorIs(t, err, analytics.ErrRecursiveMessage)
+}
+
+func TestNewRecord(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	rec, err := analytics.NewRecord(mem, []*testpb.Job{
+		{Id: "1", State: testpb.Job_STATE_RUNNING},
+		{Id: "2", State: testpb.Job_State(42)},
+	})
+	must.NoError(t, err)
+	defer rec.Release()
+
+	must.Eq(t, 2, rec.NumRows())
+	must.Eq(t, "2", rec.Column(0).(*array.String).Value(1))
+
+	state := rec.Column(1).(*array.String)
+	must.Eq(t, "STATE_RUNNING", state.Value(0))
+	must.Eq(t, "42", state.Value(1))
+}
+
+func TestRecordBuilder(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	b, err := analytics.NewRecordBuilder[*testpb.User](mem)
+	must.NoError(t, err)
+	defer b.Release()
+
+	for range 3 {
+		must.NoError(t, b.Append(&testpb.User{Id: "1"}))
+	}
+	must.Eq(t, 3, b.Len())
+
+	rec := b.NewRecord()
+	must.Eq(t, 3, rec.NumRows())
+	rec.Release()
+
+	must.Eq(t, 0, b.Len())
+}
@@ -0,0 +1,382 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// RepeatedStrategy is how a [CSVWriter] writes columns with several values,
+// such as those of repeated fields, map fields, and fields of messages in
+// repeated fields.
+type RepeatedStrategy int
+
+const (
+	// RepeatedJoin writes the values in a single cell, separated by the
+	// separator set with [WithRepeatedSeparator].
+	RepeatedJoin RepeatedStrategy = iota
+
+	// RepeatedFirst writes only the first value.
+	RepeatedFirst
+
+	// RepeatedRows writes a row per value, repeating the other columns. If
+	// several columns have multiple values, a row is written for each
+	// combination of them.
+	RepeatedRows
+)
+
+// DefaultRepeatedSeparator separates the values of a cell written with
+// [RepeatedJoin], unless configured with [WithRepeatedSeparator].
+const DefaultRepeatedSeparator = "; "
+
+// CSVOption configures optional behavior of a [CSVWriter].
+type CSVOption func(*csvOptions)
+
+// csvOptions holds the configuration built from a list of [CSVOption]
+// values.
+type csvOptions struct {
+	comma     rune
+	repeated  RepeatedStrategy
+	separator string
+}
+
+// WithComma sets the field delimiter, such as '\t' to write TSV instead of
+// CSV.
+func WithComma(r rune) CSVOption {
+	return func(o *csvOptions) {
+		o.comma = r
+	}
+}
+
+// WithRepeatedStrategy sets how columns with several values are written.
+// The default is [RepeatedJoin].
+func WithRepeatedStrategy(s RepeatedStrategy) CSVOption {
+	return func(o *csvOptions) {
+		o.repeated = s
+	}
+}
+
+// WithRepeatedSeparator sets the separator of values written in a single
+// cell with [RepeatedJoin].
+func WithRepeatedSeparator(sep string) CSVOption {
+	return func(o *csvOptions) {
+		o.separator = sep
+	}
+}
+
+// CSVWriter writes selected fields of messages of type T as CSV rows, for
+// consumers such as spreadsheets that cannot read nested data.
+//
+// A CSVWriter is not safe for concurrent use.
+type CSVWriter[T proto.Message] struct {
+	writer  *csv.Writer
+	columns [][]protoreflect.FieldDescriptor
+	opts    *csvOptions
+}
+
+// NewCSVWriter returns a [CSVWriter] writing to w, with a column for each
+// path of mask, in order, and a header row of the paths. If mask is empty,
+// there is a column for each field of T. The writer must be flushed to
+// write the buffered rows.
+//
+// Paths may select fields of nested messages, such as "address.street",
+// including messages in repeated fields, such as
+// "previous_addresses.street", which have a value per message. Enums are
+// written by name, bytes in base64, and other messages, such as
+// google.protobuf.Timestamp, in their protobuf JSON encoding. Map fields
+// have a "key=value" value per entry, sorted by key.
+//
+// # Example
+//
+//	mask, _ := fieldmaskpb.New(&example.User{}, "id", "name", "address.city")
+//
+//	w, _ := analytics.NewCSVWriter[*example.User](f, mask)
+//
+//	for _, user := range users {
+//	  _ = w.Write(user)
+//	}
+//
+//	_ = w.Flush()
+func NewCSVWriter[T proto.Message](w io.Writer, mask *fieldmaskpb.FieldMask, opts ...CSVOption) (*CSVWriter[T], error) {
+	o := &csvOptions{comma: ',', separator: DefaultRepeatedSeparator}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var zero T
+	md := zero.ProtoReflect().Descriptor()
+
+	paths := mask.GetPaths()
+	if len(paths) == 0 {
+		fds := md.Fields()
+		for i := range fds.Len() {
+			paths = append(paths, string(fds.Get(i).Name()))
+		}
+	}
+
+	columns := make([][]protoreflect.FieldDescriptor, len(paths))
+	for i, path := range paths {
+		fields, err := resolvePath(md, path)
+		if err != nil {
+			return nil, err
+		}
+		columns[i] = fields
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = o.comma
+
+	if err := writer.Write(paths); err != nil {
+		return nil, fmt.Errorf("analytics: failed to write csv header: %w", err)
+	}
+
+	return &CSVWriter[T]{
+		writer:  writer,
+		columns: columns,
+		opts:    o,
+	}, nil
+}
+
+// resolvePath returns the fields selected by a field mask path, from the
+// outermost to the innermost.
+func resolvePath(md protoreflect.MessageDescriptor, path string) ([]protoreflect.FieldDescriptor, error) {
+	names := strings.Split(path, ".")
+	fields := make([]protoreflect.FieldDescriptor, len(names))
+
+	for i, name := range names {
+		if i > 0 {
+			// Only fields of messages, or of repeated messages, can be
+			// selected.
+			prev := fields[i-1]
+			if prev.Message() == nil || prev.IsMap() {
+				return nil, fmt.Errorf("analytics: invalid field mask path %q: %s is not a message field", path, prev.Name())
+			}
+			md = prev.Message()
+		}
+
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return nil, fmt.Errorf("analytics: invalid field mask path %q: %s has no field %q", path, md.FullName(), name)
+		}
+		fields[i] = fd
+	}
+
+	return fields, nil
+}
+
+// Write writes the selected fields of msg as one or more rows, depending on
+// the [RepeatedStrategy].
+func (w *CSVWriter[T]) Write(msg T) error {
+	cells := make([][]string, len(w.columns))
+	for i, fields := range w.columns {
+		values, err := pathValues(msg.ProtoReflect(), fields)
+		if err != nil {
+			return err
+		}
+
+		switch w.opts.repeated {
+		case RepeatedFirst:
+			cells[i] = []string{""}
+			if len(values) > 0 {
+				cells[i][0] = values[0]
+			}
+		case RepeatedRows:
+			cells[i] = values
+			if len(values) == 0 {
+				cells[i] = []string{""}
+			}
+		default:
+			cells[i] = []string{strings.Join(values, w.opts.separator)}
+		}
+	}
+
+	for row := range combinations(cells) {
+		if err := w.writer.Write(row); err != nil {
+			return fmt.Errorf("analytics: failed to write csv row: %w", err)
+		}
+	}
+	return nil
+}
+
+// Flush writes the buffered rows to the underlying writer.
+func (w *CSVWriter[T]) Flush() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return fmt.Errorf("analytics: failed to flush csv writer: %w", err)
+	}
+	return nil
+}
+
+// WriteCSV writes the fields selected by mask of every message of seq to w
+// as CSV, stopping at the first error. It is useful to convert the messages
+// of a table scan or export as they are decoded.
+func WriteCSV[T proto.Message](w io.Writer, mask *fieldmaskpb.FieldMask, seq iter.Seq2[T, error], opts ...CSVOption) error {
+	cw, err := NewCSVWriter[T](w, mask, opts...)
+	if err != nil {
+		return err
+	}
+
+	for msg, err := range seq {
+		if err != nil {
+			return err
+		}
+		if err := cw.Write(msg); err != nil {
+			return err
+		}
+	}
+
+	return cw.Flush()
+}
+
+// combinations yields a row for each combination of the values of cells.
+func combinations(cells [][]string) iter.Seq[[]string] {
+	return func(yield func([]string) bool) {
+		row := make([]string, len(cells))
+
+		var walk func(i int) bool
+		walk = func(i int) bool {
+			if i == len(cells) {
+				return yield(slices.Clone(row))
+			}
+			for _, v := range cells[i] {
+				row[i] = v
+				if !walk(i + 1) {
+					return false
+				}
+			}
+			return true
+		}
+
+		walk(0)
+	}
+}
+
+// pathValues returns the formatted values of the innermost field of a path
+// within m, following every message of repeated fields along the way.
+func pathValues(m protoreflect.Message, fields []protoreflect.FieldDescriptor) ([]string, error) {
+	fd := fields[0]
+	if len(fields) == 1 {
+		return fieldValues(m, fd)
+	}
+
+	if fd.IsList() {
+		var values []string
+		list := m.Get(fd).List()
+		for i := range list.Len() {
+			vs, err := pathValues(list.Get(i).Message(), fields[1:])
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, vs...)
+		}
+		return values, nil
+	}
+
+	if !m.Has(fd) {
+		return nil, nil
+	}
+	return pathValues(m.Get(fd).Message(), fields[1:])
+}
+
+// fieldValues returns the formatted values of a field of m, which is
+// empty for unset fields with explicit presence.
+func fieldValues(m protoreflect.Message, fd protoreflect.FieldDescriptor) ([]string, error) {
+	switch {
+	case fd.IsList():
+		list := m.Get(fd).List()
+		values := make([]string, list.Len())
+		for i := range list.Len() {
+			v, err := formatValue(fd, list.Get(i))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	case fd.IsMap():
+		var values []string
+		var err error
+		m.Get(fd).Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			var key, value string
+			if key, err = formatValue(fd.MapKey(), k.Value()); err != nil {
+				return false
+			}
+			if value, err = formatValue(fd.MapValue(), v); err != nil {
+				return false
+			}
+			values = append(values, key+"="+value)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		slices.Sort(values)
+		return values, nil
+	case fd.HasPresence() && !m.Has(fd):
+		return nil, nil
+	default:
+		v, err := formatValue(fd, m.Get(fd))
+		if err != nil {
+			return nil, err
+		}
+		return []string{v}, nil
+	}
+}
+
+// formatValue returns the text of a single value of a field.
+func formatValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (string, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(v.Bool()), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case protoreflect.FloatKind:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), nil
+	case protoreflect.DoubleKind:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	case protoreflect.StringKind:
+		return v.String(), nil
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name()), nil
+		}
+		return strconv.Itoa(int(v.Enum())), nil
+	}
+
+	b, err := protojson.Marshal(v.Message().Interface())
+	if err != nil {
+		return "", fmt.Errorf("analytics: failed to encode %s: %w", fd.FullName(), err)
+	}
+
+	// Messages encoded as JSON strings, such as google.protobuf.Timestamp,
+	// are written without quotes.
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		return s, nil
+	}
+
+	// protojson does not produce stable whitespace.
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return "", fmt.Errorf("analytics: failed to encode %s: %w", fd.FullName(), err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,101 @@
+package analytics_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/picatz/dynabuf/analytics"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCSVWriter(t *testing.T) {
+	users := []*testpb.User{
+		{
+			Id:      "1",
+			Name:    "Alice, Jr.",
+			Address: &testpb.Address{Street: "Main St"},
+			PreviousAddresses: []*testpb.Address{
+				{Street: "Side St"},
+				{Street: "Old St"},
+			},
+			CreateTime: timestamppb.New(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		},
+		{Id: "2", Name: "Bob", Age: 40},
+	}
+
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"id", "name", "age", "address.street", "previous_addresses.street", "create_time"}}
+
+	tests := []struct {
+		name string
+		opts []analytics.CSVOption
+		want string
+	}{
+		{
+			name: "join",
+			want: "id,name,age,address.street,previous_addresses.street,create_time\n" +
+				"1,\"Alice, Jr.\",0,Main St,Side St; Old St,2024-01-02T03:04:05Z\n" +
+				"2,Bob,40,,,\n",
+		},
+		{
+			name: "first",
+			opts: []analytics.CSVOption{analytics.WithRepeatedStrategy(analytics.RepeatedFirst)},
+			want: "id,name,age,address.street,previous_addresses.street,create_time\n" +
+				"1,\"Alice, Jr.\",0,Main St,Side St,2024-01-02T03:04:05Z\n" +
+				"2,Bob,40,,,\n",
+		},
+		{
+			name: "rows",
+			opts: []analytics.CSVOption{analytics.WithRepeatedStrategy(analytics.RepeatedRows)},
+			want: "id,name,age,address.street,previous_addresses.street,create_time\n" +
+				"1,\"Alice, Jr.\",0,Main St,Side St,2024-01-02T03:04:05Z\n" +
+				"1,\"Alice, Jr.\",0,Main St,Old St,2024-01-02T03:04:05Z\n" +
+				"2,Bob,40,,,\n",
+		},
+		{
+			name: "tsv",
+			opts: []analytics.CSVOption{analytics.WithComma('\t'), analytics.WithRepeatedSeparator("|")},
+			want: "id\tname\tage\taddress.street\tprevious_addresses.street\tcreate_time\n" +
+				"1\tAlice, Jr.\t0\tMain St\tSide St|Old St\t2024-01-02T03:04:05Z\n" +
+				"2\tBob\t40\t\t\t\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := analytics.WriteCSV(&buf, mask, func(yield func(*testpb.User, error) bool) {
+				for _, user := range users {
+					if !yield(user, nil) {
+						return
+					}
+				}
+			}, test.opts...)
+			must.NoError(t, err)
+			must.Eq(t, test.want, buf.String())
+		})
+	}
+}
+
+func TestCSVWriter_allFields(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := analytics.NewCSVWriter[*testpb.Job](&buf, nil)
+	must.NoError(t, err)
+	must.NoError(t, w.Write(&testpb.Job{Id: "1", State: testpb.Job_STATE_RUNNING}))
+	must.NoError(t, w.Write(&testpb.Job{Id: "2", State: testpb.Job_State(42)}))
+	must.NoError(t, w.Flush())
+
+	must.Eq(t, "id,state\n1,STATE_RUNNING\n2,42\n", buf.String())
+}
+
+func TestNewCSVWriter_invalidPath(t *testing.T) {
+	for _, path := range []string{"nope", "name.first", "address.nope"} {
+		_, err := analytics.NewCSVWriter[*testpb.User](&bytes.Buffer{}, &fieldmaskpb.FieldMask{Paths: []string{path}})
+		must.Error(t, err)
+	}
+}
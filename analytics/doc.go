@@ -0,0 +1,15 @@
+// Package analytics converts decoded protobuf messages into columnar
+// formats for analytics, such as Apache Arrow record batches and Parquet
+// files, with schemas derived from the message descriptors, and into CSV
+// files of selected fields for spreadsheets.
+//
+// Together with [github.com/picatz/dynabuf.Unmarshal], it completes a
+// pipeline from DynamoDB items, such as those of a table scan or export,
+// to files that query engines like Amazon Athena, DuckDB, or Spark can
+// read directly. Arrow records can also be handed to in-memory engines,
+// such as DuckDB or DataFusion, without an intermediate file.
+//
+// It is a separate module, github.com/picatz/dynabuf/analytics, so that
+// programs only storing messages in DynamoDB do not depend on the Apache
+// Arrow and Parquet libraries.
+package analytics
@@ -0,0 +1,153 @@
+package analytics
+
+import (
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultRowGroupSize is the number of messages written per Parquet row
+// group, unless configured with [WithRowGroupSize].
+const DefaultRowGroupSize = 64 * 1024
+
+// ParquetOption configures optional behavior of a [ParquetWriter].
+type ParquetOption func(*parquetOptions)
+
+// parquetOptions holds the configuration built from a list of
+// [ParquetOption] values.
+type parquetOptions struct {
+	rowGroupSize int
+}
+
+// WithRowGroupSize sets the number of messages buffered in memory and
+// written per row group.
+func WithRowGroupSize(n int) ParquetOption {
+	return func(o *parquetOptions) {
+		o.rowGroupSize = n
+	}
+}
+
+// ParquetWriter writes messages of type T to a Parquet file, with a schema
+// derived from the message descriptor. Messages are buffered in memory and
+// written one row group at a time.
+//
+// A ParquetWriter is not safe for concurrent use.
+type ParquetWriter[T proto.Message] struct {
+	builder      *RecordBuilder[T]
+	writer       *pqarrow.FileWriter
+	rowGroupSize int
+}
+
+// NewParquetWriter returns a [ParquetWriter] writing to w. The writer must
+// be closed to complete the file.
+//
+// The file has the schema returned by [Schema] for T.
+//
+// # Example
+//
+//	w, _ := analytics.NewParquetWriter[*example.User](f)
+//
+//	for _, item := range out.Items {
+//	  var user example.User
+//	  _ = dynabuf.Unmarshal(item, &user)
+//	  _ = w.Write(&user)
+//	}
+//
+//	_ = w.Close()
+func NewParquetWriter[T proto.Message](w io.Writer, opts ...ParquetOption) (*ParquetWriter[T], error) {
+	o := &parquetOptions{rowGroupSize: DefaultRowGroupSize}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	builder, err := NewRecordBuilder[T](memory.DefaultAllocator)
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := pqarrow.NewFileWriter(
+		builder.Schema(),
+		w,
+		parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy)),
+		pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema()),
+	)
+	if err != nil {
+		builder.Release()
+		return nil, fmt.Errorf("analytics: failed to create parquet writer: %w", err)
+	}
+
+	return &ParquetWriter[T]{
+		builder:      builder,
+		writer:       writer,
+		rowGroupSize: o.rowGroupSize,
+	}, nil
+}
+
+// Write buffers msg, writing a row group once enough messages are buffered.
+func (w *ParquetWriter[T]) Write(msg T) error {
+	if err := w.builder.Append(msg); err != nil {
+		return err
+	}
+
+	if w.builder.Len() >= w.rowGroupSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered messages as a row group.
+func (w *ParquetWriter[T]) Flush() error {
+	if w.builder.Len() == 0 {
+		return nil
+	}
+
+	rec := w.builder.NewRecord()
+	defer rec.Release()
+
+	if err := w.writer.Write(rec); err != nil {
+		return fmt.Errorf("analytics: failed to write parquet row group: %w", err)
+	}
+	return nil
+}
+
+// Close writes the buffered messages and the file footer. It does not
+// close the underlying writer.
+func (w *ParquetWriter[T]) Close() error {
+	defer w.builder.Release()
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if err := w.writer.Close(); err != nil {
+		return fmt.Errorf("analytics: failed to close parquet writer: %w", err)
+	}
+	return nil
+}
+
+// WriteParquet writes every message of seq to w as a Parquet file, stopping
+// at the first error. It is useful to convert the messages of a table scan
+// or export as they are decoded.
+func WriteParquet[T proto.Message](w io.Writer, seq iter.Seq2[T, error], opts ...ParquetOption) error {
+	pw, err := NewParquetWriter[T](w, opts...)
+	if err != nil {
+		return err
+	}
+
+	for msg, err := range seq {
+		if err != nil {
+			return err
+		}
+		if err := pw.Write(msg); err != nil {
+			return err
+		}
+	}
+
+	return pw.Close()
+}
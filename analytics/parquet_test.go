@@ -0,0 +1,103 @@
+package analytics_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/picatz/dynabuf/analytics"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestParquetWriter(t *testing.T) {
+	users := []*testpb.User{
+		{
+			Id:   "1",
+			Name: "Alice",
+			Age:  30,
+			Address: &testpb.Address{
+				Street:  "Main St",
+				ZipCode: 12345,
+			},
+			PreviousAddresses: []*testpb.Address{
+				{Street: "Side St"},
+				{Street: "Old St"},
+			},
+			CreateTime: timestamppb.New(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		},
+		{Id: "2", Name: "Bob"},
+		{Id: "3", Name: "Carol"},
+	}
+
+	var buf bytes.Buffer
+
+	w, err := analytics.NewParquetWriter[*testpb.User](&buf, analytics.WithRowGroupSize(2))
+	must.NoError(t, err)
+	for _, user := range users {
+		must.NoError(t, w.Write(user))
+	}
+	must.NoError(t, w.Close())
+
+	table, err := pqarrow.ReadTable(context.Background(), bytes.NewReader(buf.Bytes()), nil, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	must.NoError(t, err)
+	defer table.Release()
+
+	must.Eq(t, 3, table.NumRows())
+	must.Eq(t, 6, table.NumCols())
+
+	tr := array.NewTableReader(table, 3)
+	defer tr.Release()
+	must.True(t, tr.Next())
+	rec := tr.Record()
+
+	must.Eq(t, "Alice", rec.Column(1).(*array.String).Value(0))
+	must.Eq(t, "Carol", rec.Column(1).(*array.String).Value(2))
+	must.Eq(t, 30, rec.Column(2).(*array.Int32).Value(0))
+
+	address := rec.Column(3).(*array.Struct)
+	must.Eq(t, "Main St", address.Field(0).(*array.String).Value(0))
+	must.True(t, address.IsNull(1))
+
+	previous := rec.Column(4).(*array.List)
+	start, end := previous.ValueOffsets(0)
+	must.Eq(t, 2, end-start)
+
+	createTime := rec.Column(5).(*array.Timestamp)
+	must.Eq(t, arrow.Timestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).UnixMicro()), createTime.Value(0))
+	must.True(t, createTime.IsNull(1))
+}
+
+func TestWriteParquet(t *testing.T) {
+	failure := errors.New("scan failed")
+
+	seq := func(yield func(*testpb.Job, error) bool) {
+		if !yield(&testpb.Job{Id: "1", State: testpb.Job_STATE_RUNNING}, nil) {
+			return
+		}
+		yield(nil, failure)
+	}
+
+	var buf bytes.Buffer
+	must.ErrorIs(t, analytics.WriteParquet(&buf, iter.Seq2[*testpb.Job, error](seq)), failure)
+
+	buf.Reset()
+	err := analytics.WriteParquet(&buf, func(yield func(*testpb.Job, error) bool) {
+		yield(&testpb.Job{Id: "1", State: testpb.Job_STATE_RUNNING}, nil)
+	})
+	must.NoError(t, err)
+
+	table, err := pqarrow.ReadTable(context.Background(), bytes.NewReader(buf.Bytes()), nil, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	must.NoError(t, err)
+	defer table.Release()
+
+	must.Eq(t, "STATE_RUNNING", table.Column(1).Data().Chunk(0).(*array.String).Value(0))
+}
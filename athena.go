@@ -0,0 +1,111 @@
+package dynabuf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+)
+
+// AthenaDDL returns an Amazon Athena CREATE EXTERNAL TABLE statement for
+// querying a [DynamoDB export] in the DynamoDB JSON format, whose items map
+// to messages as described by spec. The location is the S3 URI of the
+// export's data directory, such as
+// "s3://bucket/AWSDynamoDB/01234567890123-abcdefgh/data/".
+//
+// Exports wrap each item in an "Item" object, and each attribute value in
+// an object keyed by its type, so the table has a single item column whose
+// struct mirrors that layout:
+//
+//	SELECT item.id.s, CAST(item.age.n AS integer) FROM users
+//
+// Fields whose attribute type depends on their value, such as those of
+// google.protobuf.Value and google.protobuf.Struct, and fields of messages
+// nested within themselves, cannot be described as Athena columns and are
+// left out.
+//
+// [DynamoDB export]: https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/S3DataExport.Output.html
+func AthenaDDL(spec *dynabufpb.MappingSpec, table, location string) (string, error) {
+	messages := map[string]*dynabufpb.MessageSpec{}
+	for _, msg := range spec.GetMessages() {
+		messages[msg.GetName()] = msg
+	}
+
+	root, ok := messages[spec.GetRoot()]
+	if !ok {
+		return "", fmt.Errorf("dynabuf: mapping spec has no message for its root %q", spec.GetRoot())
+	}
+
+	g := athenaTypes{messages: messages, visiting: map[string]bool{}}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE EXTERNAL TABLE `%s` (\n", table)
+	fmt.Fprintf(&b, "  `item` %s\n", g.message(root))
+	b.WriteString(")\n")
+	b.WriteString("ROW FORMAT SERDE 'org.openx.data.jsonserde.JsonSerDe'\n")
+	fmt.Fprintf(&b, "LOCATION '%s'\n", location)
+
+	return b.String(), nil
+}
+
+// athenaTypes builds the Athena types of the messages of a mapping spec.
+type athenaTypes struct {
+	messages map[string]*dynabufpb.MessageSpec
+	visiting map[string]bool
+}
+
+// message returns the struct type of the attributes of a message.
+func (g athenaTypes) message(msg *dynabufpb.MessageSpec) string {
+	g.visiting[msg.GetName()] = true
+	defer delete(g.visiting, msg.GetName())
+
+	var fields []string
+	for _, attr := range msg.GetAttributes() {
+		var typ string
+		switch {
+		case attr.GetElement() != nil && attr.GetValue().GetType() == "L":
+			if elem := g.value(attr.GetElement()); elem != "" {
+				typ = "struct<L:array<" + elem + ">>"
+			}
+		case attr.GetElement() != nil && attr.GetValue().GetType() == "M":
+			if elem := g.value(attr.GetElement()); elem != "" {
+				typ = "struct<M:map<string," + elem + ">>"
+			}
+		default:
+			typ = g.value(attr.GetValue())
+		}
+		if typ == "" {
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("`%s`:%s", attr.GetName(), typ))
+	}
+
+	return "struct<" + strings.Join(fields, ",") + ">"
+}
+
+// value returns the type of a DynamoDB JSON attribute value, or empty if
+// it cannot be described.
+func (g athenaTypes) value(v *dynabufpb.ValueSpec) string {
+	switch v.GetEncoding() {
+	case dynabufpb.Encoding_ENCODING_FLOAT, dynabufpb.Encoding_ENCODING_ENUM_NAME:
+		// Either a number, or a string for special values.
+		return "struct<N:string,S:string>"
+	case dynabufpb.Encoding_ENCODING_JSON, dynabufpb.Encoding_ENCODING_ANY:
+		return ""
+	}
+
+	switch v.GetType() {
+	case "S", "N":
+		return "struct<" + v.GetType() + ":string>"
+	case "BOOL", "NULL":
+		return "struct<" + v.GetType() + ":boolean>"
+	case "M":
+		msg, ok := g.messages[v.GetMessage()]
+		if !ok || g.visiting[msg.GetName()] {
+			return ""
+		}
+		return "struct<M:" + g.message(msg) + ">"
+	default:
+		return ""
+	}
+}
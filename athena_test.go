@@ -0,0 +1,32 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestAthenaDDL(t *testing.T) {
+	ddl, err := dynabuf.AthenaDDL(
+		dynabuf.Spec((&testpb.User{}).ProtoReflect().Descriptor()),
+		"users",
+		"s3://bucket/AWSDynamoDB/01234567890123-abcdefgh/data/",
+	)
+	must.NoError(t, err)
+
+	address := "struct<M:struct<`street`:struct<S:string>,`zipCode`:struct<N:string>>>"
+
+	must.Eq(t, "CREATE EXTERNAL TABLE `users` (\n"+
+		"  `item` struct<"+
+		"`id`:struct<S:string>,"+
+		"`name`:struct<S:string>,"+
+		"`age`:struct<N:string>,"+
+		"`address`:"+address+","+
+		"`previousAddresses`:struct<L:array<"+address+">>,"+
+		"`createTime`:struct<S:string>>\n"+
+		")\n"+
+		"ROW FORMAT SERDE 'org.openx.data.jsonserde.JsonSerDe'\n"+
+		"LOCATION 's3://bucket/AWSDynamoDB/01234567890123-abcdefgh/data/'\n", ddl)
+}
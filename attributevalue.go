@@ -0,0 +1,59 @@
+package dynabuf
+
+import (
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+)
+
+// WithEncoderOptions encodes items with the given options of the encoder
+// of the attributevalue package, the last step of [Marshal]. The fields of
+// a message hold only JSON values, which those options leave unchanged, so
+// they apply to the values hooks given to [WithBeforeEncode] add, such as
+// EncodeTime for values of type time.Time. Options are applied in order,
+// after those of earlier calls.
+//
+// # Example
+//
+//	// Store the time an item was written as Unix seconds.
+//	stamp := func(md protoreflect.MessageDescriptor, fields map[string]any) error {
+//	  fields["updated"] = time.Now()
+//	  return nil
+//	}
+//
+//	item, err := dynabuf.Marshal(user, dynabuf.WithBeforeEncode(stamp), dynabuf.WithEncoderOptions(func(o *attributevalue.EncoderOptions) {
+//	  o.EncodeTime = func(t time.Time) (types.AttributeValue, error) {
+//	    return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}, nil
+//	  }
+//	}))
+func WithEncoderOptions(fns ...func(*attributevalue.EncoderOptions)) Option {
+	fns = slices.Clone(fns)
+	return func(o *options) {
+		o.encoderOpts = append(o.encoderOpts, fns...)
+	}
+}
+
+// WithDecoderOptions decodes items with the given options of the decoder of
+// the attributevalue package, the first step of [Unmarshal], such as the
+// options shared with code decoding the same items into structs. Options
+// are applied in order, after those of earlier calls. Numbers are always
+// decoded keeping their digits, whatever UseNumber is set to.
+//
+// # Example
+//
+//	err := dynabuf.Unmarshal(out.Item, &user, dynabuf.WithDecoderOptions(decoderOptions...))
+func WithDecoderOptions(fns ...func(*attributevalue.DecoderOptions)) Option {
+	fns = slices.Clone(fns)
+	return func(o *options) {
+		o.decoderOpts = append(o.decoderOpts, fns...)
+	}
+}
+
+// attributeValues returns the backend encoding and decoding items with the
+// configured encoder and decoder options.
+func (o *options) attributeValues() attributeValueBackend {
+	return attributeValueBackend{
+		encoderOptions: o.encoderOpts,
+		decoderOptions: o.decoderOpts,
+	}
+}
@@ -0,0 +1,48 @@
+package dynabuf_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestEncoderOptions(t *testing.T) {
+	user := &testpb.User{Id: "1"}
+	stamp := dynabuf.WithBeforeEncode(func(md protoreflect.MessageDescriptor, fields map[string]any) error {
+		fields["updated"] = time.Unix(1700000000, 0).UTC()
+		return nil
+	})
+
+	av, err := dynabuf.Marshal(user, stamp)
+	must.NoError(t, err)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "2023-11-14T22:13:20Z"}, av.(map[string]types.AttributeValue)["updated"])
+
+	av, err = dynabuf.Marshal(user, stamp, dynabuf.WithEncoderOptions(func(o *attributevalue.EncoderOptions) {
+		o.EncodeTime = func(t time.Time) (types.AttributeValue, error) {
+			return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}, nil
+		}
+	}))
+	must.NoError(t, err)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberN{Value: "1700000000"}, av.(map[string]types.AttributeValue)["updated"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "1"}, av.(map[string]types.AttributeValue)["id"])
+}
+
+func TestDecoderOptions(t *testing.T) {
+	var calls int
+	item := dynabuf.MustMarshalItem(&testpb.User{Id: "1", Age: 30})
+
+	var user testpb.User
+	must.NoError(t, dynabuf.Unmarshal(item, &user, dynabuf.WithDecoderOptions(func(o *attributevalue.DecoderOptions) {
+		calls++
+		o.UseNumber = false
+	})))
+	must.Eq(t, 1, calls)
+	must.Eq(t, 30, user.GetAge())
+}
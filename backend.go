@@ -0,0 +1,165 @@
+package dynabuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// Backend encodes messages as the items of a database, of type Item, so
+// databases other than DynamoDB can reuse the mapping of messages to their
+// JSON form.
+//
+// The fields of a message are its JSON form, decoded as by [encoding/json]
+// into strings, float64 numbers, booleans, slices, and maps, so backends
+// only encode those values in the types of their database, and decode them
+// back. Numbers whose digits must be kept, such as decimal fields and
+// formatted floats, are [json.Number] values instead, which backends may
+// also return.
+type Backend[Item any] interface {
+	// EncodeItem encodes the fields of a message as an item.
+	EncodeItem(fields map[string]any) (Item, error)
+
+	// DecodeItem decodes an item into the fields of a message.
+	DecodeItem(item Item) (map[string]any, error)
+}
+
+// DynamoDB is the backend of [Marshal] and [Unmarshal], encoding items as
+// DynamoDB attribute values.
+var DynamoDB Backend[map[string]types.AttributeValue] = attributeValueBackend{}
+
+// attributeValueBackend encodes items as DynamoDB attribute values, with
+// the encoder and decoder options of the attributevalue package.
+type attributeValueBackend struct {
+	encoderOptions []func(*attributevalue.EncoderOptions)
+	decoderOptions []func(*attributevalue.DecoderOptions)
+}
+
+func (b attributeValueBackend) EncodeItem(fields map[string]any) (map[string]types.AttributeValue, error) {
+	av, err := attributevalue.NewEncoder(b.encoderOptions...).Encode(convertNumbers[json.Number, attributevalue.Number](fields))
+	if err != nil {
+		return nil, err
+	}
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok {
+		return nil, fmt.Errorf("item encoded as %T rather than a map", av)
+	}
+	return m.Value, nil
+}
+
+func (b attributeValueBackend) DecodeItem(item map[string]types.AttributeValue) (map[string]any, error) {
+	return b.decodeAttributeValue(&types.AttributeValueMemberM{Value: item})
+}
+
+// decodeAttributeValue decodes a map attribute value into the fields of a
+// message, keeping the digits of its numbers, whatever the decoder options.
+func (b attributeValueBackend) decodeAttributeValue(av types.AttributeValue) (map[string]any, error) {
+	fields := make(map[string]any)
+	d := attributevalue.NewDecoder(append(slices.Clone(b.decoderOptions), func(o *attributevalue.DecoderOptions) {
+		o.UseNumber = true
+	})...)
+	if err := d.Decode(av, &fields); err != nil {
+		return nil, err
+	}
+	return convertNumbers[attributevalue.Number, json.Number](fields).(map[string]any), nil
+}
+
+// MarshalTo encodes msg as an item of the backend, as [Marshal] encodes
+// it for DynamoDB. Optional behavior can be configured with opts.
+//
+// # Example
+//
+//	item, _ := dynabuf.MarshalTo(dynabuf.DynamoDB, &example.User{Id: "123"})
+func MarshalTo[Item any](b Backend[Item], msg proto.Message, opts ...Option) (Item, error) {
+	var zero Item
+
+	o := newOptions(opts)
+	fields, err := messageFields(msg, o)
+	if err != nil {
+		return zero, err
+	}
+	if err := runHooks(o.beforeEncode, msg.ProtoReflect().Descriptor(), fields); err != nil {
+		return zero, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+	}
+
+	item, err := b.EncodeItem(fields)
+	if err != nil {
+		return zero, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+	}
+	return item, nil
+}
+
+// UnmarshalFrom decodes an item of the backend into msg, as [Unmarshal]
+// decodes items of DynamoDB.
+func UnmarshalFrom[Item any](b Backend[Item], item Item, msg proto.Message, opts ...Option) error {
+	o := newOptions(opts)
+	fields, err := b.DecodeItem(item)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToUnmarshal, err)
+	}
+	if err := runHooks(o.afterDecode, msg.ProtoReflect().Descriptor(), fields); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToUnmarshal, err)
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("%w: %w: %w", ErrFailedToUnmarshal, ErrFailedToMarshalIntermediary, err)
+	}
+
+	if err := unmarshalJSONToProto(data, msg, o); err != nil {
+		return fmt.Errorf("%w: %w: %w", ErrFailedToUnmarshal, ErrFailedToUnmarshalIntermediary, err)
+	}
+	return nil
+}
+
+// messageFields returns the JSON form of msg, decoded into a map, with the
+// numbers configured by o.
+func messageFields(msg proto.Message, o *options) (map[string]any, error) {
+	if err := checkAttributeNames(msg.ProtoReflect().Descriptor()); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+	}
+
+	b, err := o.protoJSONMarshal().Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %w: %w", ErrFailedToMarshal, ErrFailedToUnmarshalIntermediary, err)
+	}
+	md := msg.ProtoReflect().Descriptor()
+	if o.diagnostics != nil {
+		o.diagnoseMarshal(md, md, fields, "")
+	}
+	if err := convertFields(md, fields, o.encodeValue); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+	}
+	if o.emptyMessages != EmptyMessageMap {
+		encodeEmptyMessages(md, fields, o.emptyMessages)
+	}
+	if o.writeAliases {
+		writeAliases(md, fields)
+	}
+	if err := composeAttributes(md, fields); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+	}
+	if o.protoNames {
+		renameProtoNames(md, fields)
+	}
+	if isStruct(md) {
+		if err := o.escapeStructKeys(fields); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+		}
+	}
+	if o.metadata {
+		if err := o.writeMetadata(md, fields); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,48 @@
+package dynabuf_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+// documentBackend stores items as JSON documents, as document databases do.
+type documentBackend struct{}
+
+func (documentBackend) EncodeItem(fields map[string]any) ([]byte, error) {
+	return json.Marshal(fields)
+}
+
+func (documentBackend) DecodeItem(item []byte) (map[string]any, error) {
+	var fields map[string]any
+	return fields, json.Unmarshal(item, &fields)
+}
+
+func TestBackends(t *testing.T) {
+	user := &testpb.User{Id: "123", Name: "Alice", Age: 30, Address: &testpb.Address{ZipCode: 12345}}
+
+	item, err := dynabuf.MarshalTo(dynabuf.DynamoDB, user)
+	must.NoError(t, err)
+	must.Eq(t, dynabuf.MustMarshalItem(user), item)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberN{Value: "30"}, item["age"])
+
+	var got testpb.User
+	must.NoError(t, dynabuf.UnmarshalFrom(dynabuf.DynamoDB, item, &got))
+	must.True(t, proto.Equal(user, &got))
+
+	doc, err := dynabuf.MarshalTo[[]byte](documentBackend{}, user)
+	must.NoError(t, err)
+	must.Eq(t, `{"address":{"zipCode":12345},"age":30,"id":"123","name":"Alice"}`, string(doc))
+
+	got.Reset()
+	must.NoError(t, dynabuf.UnmarshalFrom[[]byte](documentBackend{}, doc, &got))
+	must.True(t, proto.Equal(user, &got))
+
+	err = dynabuf.UnmarshalFrom[[]byte](documentBackend{}, []byte(`{"email": "a"}`), &got)
+	must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+}
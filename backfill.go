@@ -0,0 +1,167 @@
+package dynabuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// BackfillResult reports the outcome of a [Backfill].
+type BackfillResult struct {
+	// Scanned is the number of items read from the table.
+	Scanned int
+
+	// Updated is the number of items that were missing derived attributes
+	// and were updated.
+	Updated int
+}
+
+// Backfill adds derived attributes, such as the keys of a new global
+// secondary index, to items written before the attributes existed.
+//
+// Every item of the table is scanned and decoded as T, ignoring attributes
+// that are not fields of the message, and derive returns
+// the attributes the item should have. Only the attributes missing from the
+// stored item are written, with an update that is conditional on the item
+// still existing and the attributes still being missing, so items written
+// concurrently by current code are left untouched. Attributes that are
+// present with a different value are not changed.
+//
+// The keys are the names of the table's key attributes. The progress of a
+// backfill is reported with [WithProgress], counting the items updated as
+// processed, and an interrupted backfill can be continued with
+// [WithResumeToken]. The options extend the [Table.Config] of the table.
+//
+// # Example
+//
+//	table := dynabuf.Table{Client: client, Name: "users"}
+//
+//	result, err := dynabuf.Backfill(ctx, table, []string{"id"}, func(user *example.User) (map[string]types.AttributeValue, error) {
+//	  return map[string]types.AttributeValue{
+//	    "emailDomain": &types.AttributeValueMemberS{Value: domain(user.GetEmail())},
+//	  }, nil
+//	})
+func Backfill[T proto.Message](ctx context.Context, table Table, keys []string, derive func(T) (map[string]types.AttributeValue, error), opts ...ConfigOption) (BackfillResult, error) {
+	var (
+		zero   T
+		result BackfillResult
+	)
+
+	if len(keys) == 0 {
+		return result, fmt.Errorf("dynabuf: backfill requires the table's key attributes")
+	}
+
+	table.Config = table.Config.with(opts)
+	tracker, err := newProgressTracker(ctx, table)
+	if err != nil {
+		return result, err
+	}
+	if tracker.done {
+		return result, nil
+	}
+
+	ctx = ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	err = table.scanSegment(ctx, tracker.startKey, tracker.o.segment, tracker.o.totalSegments, func(items []map[string]types.AttributeValue, next map[string]types.AttributeValue) error {
+		updated := 0
+		for _, item := range items {
+			result.Scanned++
+
+			msg := zero.ProtoReflect().New().Interface().(T)
+			if err := Unmarshal(item, msg, WithDiscardUnknown()); err != nil {
+				return fmt.Errorf("dynabuf: failed to backfill item: %w", err)
+			}
+
+			derived, err := derive(msg)
+			if err != nil {
+				return fmt.Errorf("dynabuf: failed to derive attributes: %w", err)
+			}
+
+			missing := map[string]types.AttributeValue{}
+			for name, v := range derived {
+				if _, ok := item[name]; !ok {
+					missing[name] = v
+				}
+			}
+			if len(missing) == 0 {
+				continue
+			}
+
+			ok, err := table.setMissing(ctx, keys, item, missing)
+			if err != nil {
+				return err
+			}
+			if ok {
+				result.Updated++
+				updated++
+			}
+		}
+		return tracker.page(ctx, items, updated, next)
+	})
+
+	return result, err
+}
+
+// setMissing sets the missing attributes of the item with the given key
+// attributes, if it still exists and they are still missing. It reports
+// whether the item was updated.
+func (t Table) setMissing(ctx context.Context, keys []string, item, missing map[string]types.AttributeValue) (bool, error) {
+	key, err := itemKey(item, keys)
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		names     = map[string]string{}
+		values    = map[string]types.AttributeValue{}
+		update    string
+		condition string
+	)
+
+	for i, name := range keys {
+		names[fmt.Sprintf("#k%d", i)] = name
+		if condition != "" {
+			condition += " AND "
+		}
+		condition += fmt.Sprintf("attribute_exists(#k%d)", i)
+	}
+
+	for i, name := range slices.Sorted(maps.Keys(missing)) {
+		names[fmt.Sprintf("#d%d", i)] = name
+		values[fmt.Sprintf(":d%d", i)] = missing[name]
+
+		condition += fmt.Sprintf(" AND attribute_not_exists(#d%d)", i)
+
+		if update == "" {
+			update = "SET "
+		} else {
+			update += ", "
+		}
+		update += fmt.Sprintf("#d%d = :d%d", i, i)
+	}
+
+	_, err = Do(ctx, t.Config, t.Client.UpdateItem, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(t.Name),
+		Key:                       key,
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return false, nil
+		}
+		return false, fmt.Errorf("dynabuf: failed to update item in table %q: %w", t.Name, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,83 @@
+package dynabuf_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("users"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	for _, user := range []*testpb.User{
+		{Id: "1", Name: "Alice"},
+		{Id: "2", Name: "Bob"},
+	} {
+		item, err := dynabuf.Marshal(user)
+		must.NoError(t, err)
+
+		av := item.(map[string]types.AttributeValue)
+		if user.GetId() == "2" {
+			// Written by current code, which already derives the attribute.
+			av["nameLower"] = &types.AttributeValueMemberS{Value: "bob (current)"}
+		}
+
+		_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String("users"),
+			Item:      av,
+		})
+		must.NoError(t, err)
+	}
+
+	table := dynabuf.Table{Client: client, Name: "users"}
+
+	derive := func(user *testpb.User) (map[string]types.AttributeValue, error) {
+		return map[string]types.AttributeValue{
+			"nameLower": &types.AttributeValueMemberS{Value: strings.ToLower(user.GetName())},
+		}, nil
+	}
+
+	result, err := dynabuf.Backfill(ctx, table, []string{"id"}, derive)
+	must.NoError(t, err)
+	must.Eq(t, dynabuf.BackfillResult{Scanned: 2, Updated: 1}, result)
+
+	get := func(id string) map[string]types.AttributeValue {
+		out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String("users"),
+			Key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: id},
+			},
+		})
+		must.NoError(t, err)
+		return out.Item
+	}
+
+	must.Eq(t, "alice", get("1")["nameLower"].(*types.AttributeValueMemberS).Value)
+	must.Eq(t, "Alice", get("1")["name"].(*types.AttributeValueMemberS).Value)
+	must.Eq(t, "bob (current)", get("2")["nameLower"].(*types.AttributeValueMemberS).Value)
+
+	// Running it again finds nothing to do.
+	result, err = dynabuf.Backfill(ctx, table, []string{"id"}, derive)
+	must.NoError(t, err)
+	must.Eq(t, dynabuf.BackfillResult{Scanned: 2}, result)
+}
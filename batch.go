@@ -0,0 +1,459 @@
+package dynabuf
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/internal/avtext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// batchGetSize is the maximum number of keys in a BatchGetItem call.
+const batchGetSize = 100
+
+// BatchGetItemAPIClient is the subset of the DynamoDB API used by
+// [BatchGet], which the client of its table must implement.
+type BatchGetItemAPIClient interface {
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+}
+
+// BatchResult describes the outcome of every request of a batch operation,
+// such as [BatchPut], by the index of its message or key, so callers can
+// retry, or set aside, exactly the requests that were not done.
+type BatchResult struct {
+	// Succeeded are the indexes of the requests done.
+	Succeeded []int
+
+	// Failed are the errors of the requests failed, such as messages that
+	// could not be marshaled, or requests of batches DynamoDB rejected.
+	Failed map[int]error
+
+	// Unprocessed are the indexes of the requests DynamoDB kept leaving
+	// unprocessed, or that were being retried when the batch operation
+	// stopped.
+	Unprocessed []int
+
+	// NotAttempted are the indexes of the requests never sent, because the
+	// batch operation stopped first, such as when its context is done.
+	NotAttempted []int
+
+	// DeadLettered are the indexes of the failed and unprocessed requests
+	// given to the dead-letter sink of the table, which remain in Failed
+	// or Unprocessed.
+	DeadLettered []int
+}
+
+// Err returns an error describing the requests not done, or nil if every
+// request succeeded. Unprocessed requests, and requests not attempted,
+// match [ErrUnprocessedItems].
+func (r *BatchResult) Err() error {
+	var errs []error
+	if len(r.Failed) > 0 {
+		first := slices.Min(slices.Collect(maps.Keys(r.Failed)))
+		errs = append(errs, fmt.Errorf("dynabuf: %d requests failed, starting with request %d: %w", len(r.Failed), first, r.Failed[first]))
+	}
+	if n := len(r.Unprocessed) + len(r.NotAttempted); n > 0 {
+		errs = append(errs, fmt.Errorf("%w: %d items", ErrUnprocessedItems, n))
+	}
+	return errors.Join(errs...)
+}
+
+// BatchPut marshals msgs and writes them to the table in batches, retrying
+// items DynamoDB leaves unprocessed, and returns the outcome of writing
+// each message, by index. Messages that cannot be marshaled are recorded
+// as failed without stopping the others from being written.
+//
+// The returned error is [BatchResult.Err], or a [BatchDeadlineError] if the
+// deadline of ctx is reached first, and the result is returned either way.
+//
+// If the table has a [Table.DeadLetter] sink, the messages failed, or left
+// unprocessed once retries are exhausted, are given to it with their
+// error, unless ctx is done first, in which case they are left for the
+// caller to retry. Errors of the sink are joined to the returned error.
+//
+// # Example
+//
+//	result, err := dynabuf.BatchPut(ctx, dynabuf.Table{Client: client, Name: "users"}, users)
+//	if err != nil {
+//	  for i, err := range result.Failed {
+//	    log.Printf("failed to write user %s: %v", users[i].GetId(), err)
+//	  }
+//	}
+func BatchPut[T proto.Message](ctx context.Context, table Table, msgs []T, opts ...Option) (*BatchResult, error) {
+	var zero T
+	ctx = ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	failed := map[int]error{}
+	requests := make([]types.WriteRequest, 0, len(msgs))
+	indexes := make([]int, 0, len(msgs))
+	opts = append([]Option{withConfig(table.Config)}, opts...)
+	for i, msg := range msgs {
+		item, err := marshalProtoMessage(msg, opts...)
+		if err != nil {
+			failed[i] = err
+			continue
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+		indexes = append(indexes, i)
+	}
+
+	result, err := table.batchWrite(ctx, requests)
+	result = result.reindex(indexes)
+	maps.Copy(result.Failed, failed)
+	if ctx.Err() != nil {
+		return result, err
+	}
+
+	err = result.Err()
+	if table.DeadLetter != nil {
+		err = errors.Join(err, deadLetter(ctx, table.DeadLetter, msgs, result))
+	}
+	return result, err
+}
+
+// deadLetter gives the messages of the failed and unprocessed requests of
+// the result to the sink, recording those it accepts.
+func deadLetter[T proto.Message](ctx context.Context, sink DeadLetterSink, msgs []T, result *BatchResult) error {
+	failures := maps.Clone(result.Failed)
+	for _, i := range result.Unprocessed {
+		failures[i] = fmt.Errorf("%w: retries exhausted", ErrUnprocessedItems)
+	}
+
+	var errs []error
+	for _, i := range slices.Sorted(maps.Keys(failures)) {
+		if err := sink.DeadLetter(ctx, msgs[i], failures[i]); err != nil {
+			errs = append(errs, fmt.Errorf("dynabuf: failed to dead-letter message %d: %w", i, err))
+			continue
+		}
+		result.DeadLettered = append(result.DeadLettered, i)
+	}
+	return errors.Join(errs...)
+}
+
+// BatchDelete deletes the items with the given keys from the table in
+// batches, retrying keys DynamoDB leaves unprocessed, and returns the
+// outcome of deleting each item, by index.
+//
+// The returned error is [BatchResult.Err], or a [BatchDeadlineError] if the
+// deadline of ctx is reached first, and the result is returned either way.
+//
+// # Example
+//
+//	result, err := dynabuf.BatchDelete(ctx, dynabuf.Table{Client: client, Name: "users"}, keys)
+func BatchDelete(ctx context.Context, table Table, keys []map[string]types.AttributeValue) (*BatchResult, error) {
+	requests := make([]types.WriteRequest, len(keys))
+	for i, key := range keys {
+		requests[i] = types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}}
+	}
+	return table.batchWrite(ctx, requests)
+}
+
+// BatchGet reads the items with the given keys from the table in batches,
+// retrying keys DynamoDB leaves unprocessed, and returns them decoded as T,
+// by index, with nil messages for items that do not exist, along with the
+// outcome of reading each item. Items that cannot be decoded are recorded
+// as failed, and reading items that do not exist succeeds.
+//
+// The returned error is [BatchResult.Err], and the messages and result are
+// returned either way.
+//
+// # Example
+//
+//	users, _, err := dynabuf.BatchGet[*example.User](ctx, dynabuf.Table{Client: client, Name: "users"}, keys)
+func BatchGet[T proto.Message](ctx context.Context, table Table, keys []map[string]types.AttributeValue, opts ...Option) ([]T, *BatchResult, error) {
+	var zero T
+	ctx = ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	gets := make([]batchGetKey, len(keys))
+	for i, key := range keys {
+		gets[i] = batchGetKey{table: table.Name, key: key}
+	}
+
+	msgs := make([]T, len(keys))
+	result, err := table.batchGet(ctx, gets, func(i int, item map[string]types.AttributeValue) error {
+		if item == nil {
+			return nil
+		}
+		msg, err := UnmarshalNew[T](item, opts...)
+		if err != nil {
+			return err
+		}
+		msgs[i] = msg
+		return nil
+	})
+	return msgs, result, err
+}
+
+// TypedKeys are the keys of items of a message type read by
+// [BatchGetTypes].
+type TypedKeys struct {
+	// Table is the name of the table of the items, which defaults to the
+	// name of the table given to [BatchGetTypes].
+	Table string
+
+	// Type is the full name of the message type of the items, as
+	// registered in [protoregistry.GlobalTypes].
+	Type protoreflect.FullName
+
+	// Keys are the keys of the items.
+	Keys []map[string]types.AttributeValue
+}
+
+// BatchGetTypes reads the items with the given keys, of several message
+// types and tables, in batches, as [BatchGet] does, such as the entities
+// of a single-table design making up a page. It returns the messages by
+// the full name of their type, in the order of their keys, with nil
+// messages for items that do not exist, along with the outcome of reading
+// each item, by the index of its key among the keys of every type, in
+// order.
+//
+// The message types are found in [protoregistry.GlobalTypes], and the keys
+// of a type listed more than once are read into the same slice, in order.
+//
+// The returned error is [BatchResult.Err], and the messages and result are
+// returned either way.
+//
+// # Example
+//
+//	msgs, _, err := dynabuf.BatchGetTypes(ctx, dynabuf.Table{Client: client, Name: "app"}, []dynabuf.TypedKeys{
+//	  {Type: "example.User", Keys: userKeys},
+//	  {Type: "example.Order", Keys: orderKeys},
+//	})
+//
+//	for _, msg := range msgs["example.Order"] {
+//	  order, _ := msg.(*example.Order)
+//	  ...
+//	}
+func BatchGetTypes(ctx context.Context, table Table, keys []TypedKeys, opts ...Option) (map[protoreflect.FullName][]proto.Message, *BatchResult, error) {
+	var (
+		gets []batchGetKey
+		msgs = map[protoreflect.FullName][]proto.Message{}
+		mts  = map[protoreflect.FullName]protoreflect.MessageType{}
+
+		// routes are the type and index within its slice of the message
+		// of every key.
+		routes []batchGetRoute
+	)
+	for _, k := range keys {
+		mt, ok := mts[k.Type]
+		if !ok {
+			var err error
+			mt, err = protoregistry.GlobalTypes.FindMessageByName(k.Type)
+			if err != nil {
+				return nil, nil, fmt.Errorf("dynabuf: failed to find message type of batch get: %w", err)
+			}
+			mts[k.Type] = mt
+		}
+		for _, key := range k.Keys {
+			gets = append(gets, batchGetKey{table: cmp.Or(k.Table, table.Name), key: key})
+			routes = append(routes, batchGetRoute{typ: k.Type, index: len(msgs[k.Type])})
+			msgs[k.Type] = append(msgs[k.Type], nil)
+		}
+	}
+
+	result, err := table.batchGet(ctx, gets, func(i int, item map[string]types.AttributeValue) error {
+		if item == nil {
+			return nil
+		}
+		route := routes[i]
+		msg := mts[route.typ].New().Interface()
+		if err := Unmarshal(item, msg, opts...); err != nil {
+			return err
+		}
+		msgs[route.typ][route.index] = msg
+		return nil
+	})
+	return msgs, result, err
+}
+
+// batchGetRoute locates the message of a key read by [BatchGetTypes].
+type batchGetRoute struct {
+	typ   protoreflect.FullName
+	index int
+}
+
+// batchGetKey is the key of an item read by a batch get, and its table.
+type batchGetKey struct {
+	table string
+	key   map[string]types.AttributeValue
+}
+
+// batchGet reads the items with the given keys in batches, retrying keys
+// DynamoDB leaves unprocessed, and calls done with the index of every key
+// read and its item, which is nil if it does not exist. Keys done returns
+// an error for are recorded as failed. Keys of any number of tables can
+// be read at once, with the client and configuration of t.
+func (t Table) batchGet(ctx context.Context, keys []batchGetKey, done func(i int, item map[string]types.AttributeValue) error) (*BatchResult, error) {
+	client, ok := t.Client.(BatchGetItemAPIClient)
+	if !ok {
+		return nil, fmt.Errorf("dynabuf: the client of table %q cannot batch get items", t.Name)
+	}
+	cfg := t.Config
+
+	result := &BatchResult{Failed: map[int]error{}}
+
+	// Keys requested more than once are read once, since DynamoDB rejects
+	// batches with duplicate keys. Keys are identified by their table and
+	// the text of their attributes, whose names are those of the first key
+	// of their table.
+	var (
+		names    = map[string][]string{}
+		distinct []string
+		indexes  = map[string][]int{}
+		byText   = map[string]batchGetKey{}
+	)
+	text := func(table string, key map[string]types.AttributeValue) string {
+		return table + "\x00" + keyText(key, names[table])
+	}
+	for i, k := range keys {
+		if _, ok := names[k.table]; !ok {
+			names[k.table] = slices.Sorted(maps.Keys(k.key))
+		}
+		t := text(k.table, k.key)
+		if _, ok := indexes[t]; !ok {
+			distinct = append(distinct, t)
+			byText[t] = k
+		}
+		indexes[t] = append(indexes[t], i)
+	}
+
+	read := func(t string, item map[string]types.AttributeValue) {
+		for _, i := range indexes[t] {
+			if err := done(i, item); err != nil {
+				result.Failed[i] = err
+				continue
+			}
+			result.Succeeded = append(result.Succeeded, i)
+		}
+	}
+	mark := func(into *[]int, texts ...[]string) {
+		for _, group := range texts {
+			for _, t := range group {
+				*into = append(*into, indexes[t]...)
+			}
+		}
+	}
+
+	for start := 0; start < len(distinct); start += batchGetSize {
+		end := min(start+batchGetSize, len(distinct))
+		pending := distinct[start:end]
+
+		for attempt := 1; ; attempt++ {
+			requested := map[string]types.KeysAndAttributes{}
+			for _, t := range pending {
+				k := byText[t]
+				requested[k.table] = types.KeysAndAttributes{
+					Keys:           append(requested[k.table].Keys, k.key),
+					ConsistentRead: cfg.ConsistentRead(false),
+				}
+			}
+
+			out, err := Do(ctx, cfg, client.BatchGetItem, &dynamodb.BatchGetItemInput{RequestItems: requested})
+			if err != nil {
+				if ctx.Err() != nil {
+					mark(&result.NotAttempted, pending, distinct[end:])
+					return result.sorted(), ctx.Err()
+				}
+				tables := slices.Sorted(maps.Keys(requested))
+				noun := "table"
+				if len(tables) > 1 {
+					noun = "tables"
+				}
+				err = fmt.Errorf("dynabuf: failed to get batch from %s %s: %w", noun, quoteNames(tables), err)
+				for _, t := range pending {
+					for _, i := range indexes[t] {
+						result.Failed[i] = err
+					}
+				}
+				break
+			}
+
+			found := map[string]map[string]types.AttributeValue{}
+			for table, items := range out.Responses {
+				for _, item := range items {
+					found[text(table, item)] = item
+				}
+			}
+			unprocessed := map[string]bool{}
+			for table, ka := range out.UnprocessedKeys {
+				for _, key := range ka.Keys {
+					unprocessed[text(table, key)] = true
+				}
+			}
+
+			var left []string
+			for _, t := range pending {
+				if unprocessed[t] {
+					left = append(left, t)
+					continue
+				}
+				read(t, found[t])
+			}
+			if len(left) == 0 {
+				break
+			}
+			if attempt == cfg.batchAttempts() {
+				mark(&result.Unprocessed, left)
+				break
+			}
+			pending = left
+
+			select {
+			case <-ctx.Done():
+				mark(&result.Unprocessed, left)
+				mark(&result.NotAttempted, distinct[end:])
+				return result.sorted(), ctx.Err()
+			case <-time.After(cfg.backoff(attempt)):
+			}
+		}
+	}
+
+	return result.sorted(), result.Err()
+}
+
+// keyText returns the text of the attributes of an item or key with the
+// given names, identifying the item within a batch.
+func keyText(item map[string]types.AttributeValue, names []string) string {
+	key := make(map[string]types.AttributeValue, len(names))
+	for _, name := range names {
+		key[name] = item[name]
+	}
+	return avtext.Format(&types.AttributeValueMemberM{Value: key})
+}
+
+// sorted sorts the indexes of the result, which requests read more than
+// once leave out of order.
+func (r *BatchResult) sorted() *BatchResult {
+	slices.Sort(r.Succeeded)
+	slices.Sort(r.Unprocessed)
+	slices.Sort(r.NotAttempted)
+	return r
+}
+
+// reindex returns the result with the indexes of requests replaced by the
+// indexes they were made from.
+func (r *BatchResult) reindex(indexes []int) *BatchResult {
+	out := &BatchResult{Failed: map[int]error{}}
+	for _, i := range r.Succeeded {
+		out.Succeeded = append(out.Succeeded, indexes[i])
+	}
+	for i, err := range r.Failed {
+		out.Failed[indexes[i]] = err
+	}
+	for _, i := range r.Unprocessed {
+		out.Unprocessed = append(out.Unprocessed, indexes[i])
+	}
+	for _, i := range r.NotAttempted {
+		out.NotAttempted = append(out.NotAttempted, indexes[i])
+	}
+	return out
+}
@@ -0,0 +1,352 @@
+package dynabuf_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+var errRejected = errors.New("rejected")
+
+// rejectingClient rejects batch writes of requests for the item with the
+// given id, and leaves its key unprocessed by the first batch get.
+type rejectingClient struct {
+	*dynamotest.Client
+
+	id   string
+	gets atomic.Int64
+}
+
+func (c *rejectingClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	for _, requests := range params.RequestItems {
+		for _, r := range requests {
+			item := map[string]types.AttributeValue{}
+			if r.PutRequest != nil {
+				item = r.PutRequest.Item
+			} else if r.DeleteRequest != nil {
+				item = r.DeleteRequest.Key
+			}
+			if id, ok := item["id"].(*types.AttributeValueMemberS); ok && id.Value == c.id {
+				return nil, errRejected
+			}
+		}
+	}
+	return c.Client.BatchWriteItem(ctx, params, optFns...)
+}
+
+func (c *rejectingClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	if c.gets.Add(1) > 1 {
+		return c.Client.BatchGetItem(ctx, params, optFns...)
+	}
+
+	sent := &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{}}
+	unprocessed := map[string]types.KeysAndAttributes{}
+	for table, ka := range params.RequestItems {
+		for _, key := range ka.Keys {
+			if id, ok := key["id"].(*types.AttributeValueMemberS); ok && id.Value == c.id {
+				unprocessed[table] = types.KeysAndAttributes{Keys: append(unprocessed[table].Keys, key)}
+			} else {
+				sent.RequestItems[table] = types.KeysAndAttributes{Keys: append(sent.RequestItems[table].Keys, key)}
+			}
+		}
+	}
+
+	out, err := c.Client.BatchGetItem(ctx, sent, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	out.UnprocessedKeys = unprocessed
+	return out, nil
+}
+
+// newUsersTable returns a client with an empty users table.
+func newUsersTable(t *testing.T) *dynamotest.Client {
+	t.Helper()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("users"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+	return client
+}
+
+// userKey returns the key of the user with the given id.
+func userKey(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}}
+}
+
+// indexes returns the indexes from start up to end.
+func indexes(start, end int) []int {
+	var out []int
+	for i := start; i < end; i++ {
+		out = append(out, i)
+	}
+	return out
+}
+
+func TestBatchPut(t *testing.T) {
+	ctx := context.Background()
+
+	client := &rejectingClient{Client: newUsersTable(t), id: "30"}
+	table := dynabuf.Table{Client: client, Name: "users"}
+
+	var users []*testpb.User
+	for i := range 60 {
+		users = append(users, &testpb.User{Id: fmt.Sprint(i), Name: "Alice"})
+	}
+
+	// The second batch is rejected, and the others are still written.
+	result, err := dynabuf.BatchPut(ctx, table, users)
+	must.ErrorIs(t, err, errRejected)
+	must.Eq(t, append(indexes(0, 25), indexes(50, 60)...), result.Succeeded)
+	must.MapLen(t, 25, result.Failed)
+	for i := range 25 {
+		must.ErrorIs(t, result.Failed[25+i], errRejected)
+	}
+	must.SliceEmpty(t, result.Unprocessed)
+	must.SliceEmpty(t, result.NotAttempted)
+
+	out, err := client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("users")})
+	must.NoError(t, err)
+	must.SliceLen(t, 35, out.Items)
+
+	// Retrying the failed messages writes them.
+	var retry []*testpb.User
+	for i := range result.Failed {
+		if users[i].GetId() != "30" {
+			retry = append(retry, users[i])
+		}
+	}
+	result, err = dynabuf.BatchPut(ctx, table, retry)
+	must.NoError(t, err)
+	must.SliceLen(t, 24, result.Succeeded)
+	must.MapEmpty(t, result.Failed)
+}
+
+func TestBatchPutMetadata(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	table := dynabuf.Table{
+		Client: newUsersTable(t),
+		Name:   "users",
+		Config: dynabuf.NewConfig(dynabuf.WithClock(func() time.Time { return now })),
+	}
+
+	// The metadata is timestamped by the clock of the table.
+	_, err := dynabuf.BatchPut(ctx, table, []*testpb.User{{Id: "1"}}, dynabuf.WithMetadata("v1"))
+	must.NoError(t, err)
+
+	users, _, err := dynabuf.BatchGet[*testpb.User](ctx, table, []map[string]types.AttributeValue{userKey("1")}, dynabuf.WithDiscardUnknown())
+	must.NoError(t, err)
+	must.Eq(t, "1", users[0].GetId())
+
+	out, err := table.Client.(*dynamotest.Client).GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String("users"), Key: userKey("1")})
+	must.NoError(t, err)
+	meta, ok, err := dynabuf.ReadMetadata(out.Item)
+	must.NoError(t, err)
+	must.True(t, ok)
+	must.True(t, meta.WriteTime.Equal(now))
+}
+
+func TestBatchPutDeadLetter(t *testing.T) {
+	ctx := context.Background()
+
+	var letters []string
+	table := dynabuf.Table{
+		Client: &rejectingClient{Client: newUsersTable(t), id: "2"},
+		Name:   "users",
+		DeadLetter: dynabuf.DeadLetterFunc(func(ctx context.Context, msg proto.Message, err error) error {
+			if !errors.Is(err, errRejected) {
+				return fmt.Errorf("unexpected error: %w", err)
+			}
+			letters = append(letters, msg.(*testpb.User).GetId())
+			if len(letters) > 1 {
+				return errors.New("queue is full")
+			}
+			return nil
+		}),
+	}
+
+	users := []*testpb.User{{Id: "1"}, {Id: "2"}}
+	for range 24 {
+		users = append(users, &testpb.User{Id: "3"})
+	}
+
+	// The first batch is rejected, the second written, and the sink only
+	// accepts the first message.
+	result, err := dynabuf.BatchPut(ctx, table, users)
+	must.ErrorIs(t, err, errRejected)
+	must.ErrorContains(t, err, "queue is full")
+	must.Eq(t, []int{25}, result.Succeeded)
+	must.MapLen(t, 25, result.Failed)
+	must.Eq(t, []int{0}, result.DeadLettered)
+	must.SliceLen(t, 25, letters)
+	must.Eq(t, "1", letters[0])
+}
+
+func TestBatchDelete(t *testing.T) {
+	ctx := context.Background()
+
+	client := &rejectingClient{Client: newUsersTable(t), id: "3"}
+	table := dynabuf.Table{Client: client, Name: "users"}
+
+	result, err := dynabuf.BatchPut(ctx, table, []*testpb.User{{Id: "1"}, {Id: "2"}})
+	must.NoError(t, err)
+	must.Eq(t, []int{0, 1}, result.Succeeded)
+
+	result, err = dynabuf.BatchDelete(ctx, table, []map[string]types.AttributeValue{userKey("1"), userKey("2")})
+	must.NoError(t, err)
+	must.Eq(t, []int{0, 1}, result.Succeeded)
+
+	result, err = dynabuf.BatchDelete(ctx, table, []map[string]types.AttributeValue{userKey("3")})
+	must.ErrorIs(t, err, errRejected)
+	must.SliceEmpty(t, result.Succeeded)
+	must.ErrorIs(t, result.Failed[0], errRejected)
+}
+
+func TestBatchGet(t *testing.T) {
+	ctx := context.Background()
+
+	client := &rejectingClient{Client: newUsersTable(t), id: "2"}
+
+	_, err := dynabuf.BatchPut(ctx, dynabuf.Table{Client: client.Client, Name: "users"}, []*testpb.User{
+		{Id: "1", Name: "Alice"},
+		{Id: "2", Name: "Bob"},
+	})
+	must.NoError(t, err)
+
+	// Missing items are nil, keys read twice are read once, and keys left
+	// unprocessed are retried.
+	users, result, err := dynabuf.BatchGet[*testpb.User](ctx, dynabuf.Table{Client: client, Name: "users"}, []map[string]types.AttributeValue{
+		userKey("1"),
+		userKey("3"),
+		userKey("2"),
+		userKey("1"),
+	})
+	must.NoError(t, err)
+	must.Eq(t, []int{0, 1, 2, 3}, result.Succeeded)
+	must.MapEmpty(t, result.Failed)
+	must.SliceLen(t, 4, users)
+	must.Eq(t, "Alice", users[0].GetName())
+	must.Nil(t, users[1])
+	must.Eq(t, "Bob", users[2].GetName())
+	must.Eq(t, "Alice", users[3].GetName())
+	must.Eq(t, 2, client.gets.Load())
+}
+
+func TestBatchGetTypes(t *testing.T) {
+	ctx := context.Background()
+
+	client := &rejectingClient{Client: newUsersTable(t), id: "2"}
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("orders"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("customer"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("sk"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	_, err = dynabuf.BatchPut(ctx, dynabuf.Table{Client: client.Client, Name: "users"}, []*testpb.User{
+		{Id: "1", Name: "Alice"},
+		{Id: "2", Name: "Bob"},
+	})
+	must.NoError(t, err)
+	order := &testpb.Order{Customer: "1", Id: "a", Status: "OPEN"}
+	_, err = dynabuf.BatchPut(ctx, dynabuf.Table{Client: client.Client, Name: "orders"}, []*testpb.Order{order})
+	must.NoError(t, err)
+
+	orderKey, err := dynabuf.Key(order, []string{"customer", "sk"})
+	must.NoError(t, err)
+
+	// Messages are routed to the slices of their types, and the result is
+	// indexed by the keys of every type, in order.
+	msgs, result, err := dynabuf.BatchGetTypes(ctx, dynabuf.Table{Client: client, Name: "users"}, []dynabuf.TypedKeys{
+		{Type: "dynabuf.test.v1.User", Keys: []map[string]types.AttributeValue{userKey("2"), userKey("3")}},
+		{Table: "orders", Type: "dynabuf.test.v1.Order", Keys: []map[string]types.AttributeValue{orderKey}},
+		{Table: "users", Type: "dynabuf.test.v1.User", Keys: []map[string]types.AttributeValue{userKey("1")}},
+	})
+	must.NoError(t, err)
+	must.Eq(t, []int{0, 1, 2, 3}, result.Succeeded)
+
+	users := msgs["dynabuf.test.v1.User"]
+	must.SliceLen(t, 3, users)
+	must.Eq(t, "Bob", users[0].(*testpb.User).GetName())
+	must.Nil(t, users[1])
+	must.Eq(t, "Alice", users[2].(*testpb.User).GetName())
+
+	orders := msgs["dynabuf.test.v1.Order"]
+	must.SliceLen(t, 1, orders)
+	must.Eq(t, "OPEN", orders[0].(*testpb.Order).GetStatus())
+
+	_, _, err = dynabuf.BatchGetTypes(ctx, dynabuf.Table{Client: client, Name: "users"}, []dynabuf.TypedKeys{
+		{Table: "users", Type: "dynabuf.test.v1.Unknown", Keys: []map[string]types.AttributeValue{userKey("1")}},
+	})
+	must.ErrorContains(t, err, "failed to find message type")
+}
+
+func TestBatchResultErr(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      dynabuf.BatchResult
+		wantErr     bool
+		unprocessed bool
+	}{
+		{
+			name:   "succeeded",
+			result: dynabuf.BatchResult{Succeeded: []int{0, 1}},
+		},
+		{
+			name:    "failed",
+			result:  dynabuf.BatchResult{Succeeded: []int{0}, Failed: map[int]error{1: errRejected}},
+			wantErr: true,
+		},
+		{
+			name:        "unprocessed",
+			result:      dynabuf.BatchResult{Unprocessed: []int{0}},
+			wantErr:     true,
+			unprocessed: true,
+		},
+		{
+			name:        "not attempted",
+			result:      dynabuf.BatchResult{NotAttempted: []int{0}},
+			wantErr:     true,
+			unprocessed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.result.Err()
+			must.Eq(t, test.wantErr, err != nil)
+			must.Eq(t, test.unprocessed, errors.Is(err, dynabuf.ErrUnprocessedItems))
+			must.Eq(t, len(test.result.Failed) > 0, errors.Is(err, errRejected))
+		})
+	}
+}
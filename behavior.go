@@ -0,0 +1,93 @@
+package dynabuf
+
+import (
+	"slices"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ClearOutputOnly clears the fields of msg and its nested messages that
+// are annotated as output only, either with dynabuf's field options or
+// the OUTPUT_ONLY field behavior of AIP-203:
+//
+//	google.protobuf.Timestamp create_time = 3 [(dynabuf.v1.field) = { output_only: true }];
+//
+// Messages supplied by clients, such as the resources of Create and Update
+// requests, should be cleared before being stored, so clients cannot set
+// values only the service may set.
+//
+// # Example
+//
+//	book := req.GetBook()
+//	dynabuf.ClearOutputOnly(book)
+//	book.CreateTime = timestamppb.Now()
+func ClearOutputOnly(msg proto.Message) {
+	clearOutputOnly(msg.ProtoReflect())
+}
+
+// clearOutputOnly clears the output only fields of msg and its nested
+// messages.
+func clearOutputOnly(msg protoreflect.Message) {
+	var outputOnly []protoreflect.FieldDescriptor
+
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case isOutputOnly(fd):
+			outputOnly = append(outputOnly, fd)
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := range list.Len() {
+				clearOutputOnly(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				clearOutputOnly(v.Message())
+				return true
+			})
+		case fd.Message() != nil && !fd.IsMap():
+			clearOutputOnly(v.Message())
+		}
+		return true
+	})
+
+	for _, fd := range outputOnly {
+		msg.Clear(fd)
+	}
+}
+
+// isOutputOnly reports whether the field is annotated as output only.
+func isOutputOnly(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := proto.GetExtension(fd.Options(), dynabufpb.E_Field).(*dynabufpb.FieldOptions)
+	return ok && opts.GetOutputOnly() || hasFieldBehavior(fd, annotations.FieldBehavior_OUTPUT_ONLY)
+}
+
+// isImmutable reports whether the field is annotated as immutable, or as
+// the identifier of a resource, which cannot change either.
+func isImmutable(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := proto.GetExtension(fd.Options(), dynabufpb.E_Field).(*dynabufpb.FieldOptions)
+	return ok && opts.GetImmutable() ||
+		hasFieldBehavior(fd, annotations.FieldBehavior_IMMUTABLE) ||
+		hasFieldBehavior(fd, annotations.FieldBehavior_IDENTIFIER)
+}
+
+// hasFieldBehavior reports whether the field is annotated with the
+// google.api.field_behavior.
+func hasFieldBehavior(fd protoreflect.FieldDescriptor, behavior annotations.FieldBehavior) bool {
+	behaviors, _ := proto.GetExtension(fd.Options(), annotations.E_FieldBehavior).([]annotations.FieldBehavior)
+	return slices.Contains(behaviors, behavior)
+}
+
+// fieldBehavior returns a description of the field behavior preventing
+// updates of fd, or an empty string if it can be updated.
+func fieldBehavior(fd protoreflect.FieldDescriptor) string {
+	switch {
+	case isOutputOnly(fd):
+		return "output only"
+	case isImmutable(fd):
+		return "immutable"
+	}
+	return ""
+}
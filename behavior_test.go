@@ -0,0 +1,21 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestClearOutputOnly(t *testing.T) {
+	user := &testpb.User{Id: "1", Name: "Alice", CreateTime: timestamppb.Now()}
+	dynabuf.ClearOutputOnly(user)
+	must.True(t, proto.Equal(&testpb.User{Id: "1", Name: "Alice"}, user))
+
+	book := &testpb.Book{Name: "books/1", Author: "Frank Herbert", CreateTime: timestamppb.Now()}
+	dynabuf.ClearOutputOnly(book)
+	must.True(t, proto.Equal(&testpb.Book{Name: "books/1", Author: "Frank Herbert"}, book))
+}
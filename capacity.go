@@ -0,0 +1,270 @@
+package dynabuf
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// CapacityUsage describes the capacity consumed by a DynamoDB request,
+// as reported to the hook given to [WithCapacityAccounting].
+type CapacityUsage struct {
+	// MessageType is the full name of the protobuf message type noted in
+	// the request's context by [ContextWithMessageType], or empty if none
+	// was noted.
+	MessageType protoreflect.FullName
+
+	// Operation is the name of the DynamoDB operation, such as "PutItem".
+	Operation string
+
+	// ConsumedCapacity is the capacity reported by DynamoDB, with one entry
+	// per table for batch and transaction operations.
+	ConsumedCapacity []types.ConsumedCapacity
+}
+
+// CapacityUnits returns the total capacity units consumed by the request.
+func (u CapacityUsage) CapacityUnits() float64 {
+	var total float64
+	for _, c := range u.ConsumedCapacity {
+		if c.CapacityUnits != nil {
+			total += *c.CapacityUnits
+		}
+	}
+	return total
+}
+
+// capacityAccountingID is the ID of the middleware added by
+// [WithCapacityAccounting].
+const capacityAccountingID = "DynabufCapacityAccounting"
+
+// WithCapacityAccounting returns a DynamoDB client option that requests the
+// consumed capacity of every operation reporting it, and passes it to record
+// along with the message type noted in the request's context by
+// [ContextWithMessageType] and the name of the operation.
+//
+// Requests that already set ReturnConsumedCapacity are left as they are, so
+// callers can still ask for per-index capacity. Adding the option more than
+// once, such as to a client and to a [Config] with [WithClientOptions],
+// accounts for each request once, with the record function added first.
+//
+// # Example
+//
+//	var capacity dynabuf.CapacityMetrics
+//
+//	expvar.Publish("dynabuf_consumed_capacity", &capacity)
+//
+//	client := dynamodb.NewFromConfig(cfg, dynabuf.WithCapacityAccounting(capacity.Record))
+//
+//	client.PutItem(dynabuf.ContextWithMessageType(ctx, user), input)
+//
+//	fmt.Println(capacity.Units("example.User", "PutItem"))
+//	// 1
+func WithCapacityAccounting(record func(ctx context.Context, usage CapacityUsage)) func(*dynamodb.Options) {
+	return func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			if _, ok := stack.Initialize.Get(capacityAccountingID); ok {
+				return nil
+			}
+			return stack.Initialize.Add(middleware.InitializeMiddlewareFunc(capacityAccountingID, func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				params, ok := returnConsumedCapacity(in.Parameters)
+				if !ok {
+					return next.HandleInitialize(ctx, in)
+				}
+				in.Parameters = params
+
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				if err != nil {
+					return out, metadata, err
+				}
+
+				consumed := consumedCapacity(out.Result)
+				if len(consumed) == 0 || record == nil {
+					return out, metadata, err
+				}
+
+				usage := CapacityUsage{
+					Operation:        awsmiddleware.GetOperationName(ctx),
+					ConsumedCapacity: consumed,
+				}
+				usage.MessageType, _ = MessageTypeFromContext(ctx)
+
+				record(ctx, usage)
+
+				return out, metadata, err
+			}), middleware.After)
+		})
+	}
+}
+
+// returnConsumedCapacity returns a copy of the operation input params
+// requesting the total consumed capacity, unless it already requests it.
+// It reports false for operations that do not return consumed capacity.
+func returnConsumedCapacity(params any) (any, bool) {
+	const total = types.ReturnConsumedCapacityTotal
+
+	switch p := params.(type) {
+	case *dynamodb.GetItemInput:
+		c := *p
+		c.ReturnConsumedCapacity = cmp.Or(c.ReturnConsumedCapacity, total)
+		return &c, true
+	case *dynamodb.PutItemInput:
+		c := *p
+		c.ReturnConsumedCapacity = cmp.Or(c.ReturnConsumedCapacity, total)
+		return &c, true
+	case *dynamodb.UpdateItemInput:
+		c := *p
+		c.ReturnConsumedCapacity = cmp.Or(c.ReturnConsumedCapacity, total)
+		return &c, true
+	case *dynamodb.DeleteItemInput:
+		c := *p
+		c.ReturnConsumedCapacity = cmp.Or(c.ReturnConsumedCapacity, total)
+		return &c, true
+	case *dynamodb.QueryInput:
+		c := *p
+		c.ReturnConsumedCapacity = cmp.Or(c.ReturnConsumedCapacity, total)
+		return &c, true
+	case *dynamodb.ScanInput:
+		c := *p
+		c.ReturnConsumedCapacity = cmp.Or(c.ReturnConsumedCapacity, total)
+		return &c, true
+	case *dynamodb.BatchGetItemInput:
+		c := *p
+		c.ReturnConsumedCapacity = cmp.Or(c.ReturnConsumedCapacity, total)
+		return &c, true
+	case *dynamodb.BatchWriteItemInput:
+		c := *p
+		c.ReturnConsumedCapacity = cmp.Or(c.ReturnConsumedCapacity, total)
+		return &c, true
+	case *dynamodb.TransactGetItemsInput:
+		c := *p
+		c.ReturnConsumedCapacity = cmp.Or(c.ReturnConsumedCapacity, total)
+		return &c, true
+	case *dynamodb.TransactWriteItemsInput:
+		c := *p
+		c.ReturnConsumedCapacity = cmp.Or(c.ReturnConsumedCapacity, total)
+		return &c, true
+	default:
+		return nil, false
+	}
+}
+
+// consumedCapacity returns the consumed capacity reported in an operation's
+// output.
+func consumedCapacity(result any) []types.ConsumedCapacity {
+	single := func(c *types.ConsumedCapacity) []types.ConsumedCapacity {
+		if c == nil {
+			return nil
+		}
+		return []types.ConsumedCapacity{*c}
+	}
+
+	switch r := result.(type) {
+	case *dynamodb.GetItemOutput:
+		return single(r.ConsumedCapacity)
+	case *dynamodb.PutItemOutput:
+		return single(r.ConsumedCapacity)
+	case *dynamodb.UpdateItemOutput:
+		return single(r.ConsumedCapacity)
+	case *dynamodb.DeleteItemOutput:
+		return single(r.ConsumedCapacity)
+	case *dynamodb.QueryOutput:
+		return single(r.ConsumedCapacity)
+	case *dynamodb.ScanOutput:
+		return single(r.ConsumedCapacity)
+	case *dynamodb.BatchGetItemOutput:
+		return r.ConsumedCapacity
+	case *dynamodb.BatchWriteItemOutput:
+		return r.ConsumedCapacity
+	case *dynamodb.TransactGetItemsOutput:
+		return r.ConsumedCapacity
+	case *dynamodb.TransactWriteItemsOutput:
+		return r.ConsumedCapacity
+	default:
+		return nil
+	}
+}
+
+// CapacityMetrics sums the capacity units reported by
+// [WithCapacityAccounting], grouped by message type and operation, by
+// passing its [CapacityMetrics.Record] method to it.
+//
+// CapacityMetrics implements [expvar.Var], so it can be published as is.
+// Other metrics systems can export the sums with [CapacityMetrics.Each].
+//
+// The zero value is ready to use, and a CapacityMetrics is safe for
+// concurrent use.
+type CapacityMetrics struct {
+	mu    sync.Mutex
+	units map[capacityKey]float64
+}
+
+// capacityKey identifies an operation on a message type.
+type capacityKey struct {
+	message   string
+	operation string
+}
+
+// Record adds the capacity units consumed by a request.
+func (m *CapacityMetrics) Record(_ context.Context, usage CapacityUsage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.units == nil {
+		m.units = map[capacityKey]float64{}
+	}
+	m.units[capacityKey{message: string(usage.MessageType), operation: usage.Operation}] += usage.CapacityUnits()
+}
+
+// Units returns the capacity units consumed by the operation on the given
+// message type, such as "example.User" and "PutItem". Requests without a
+// message type are recorded under the empty message type.
+func (m *CapacityMetrics) Units(message, operation string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.units[capacityKey{message: message, operation: operation}]
+}
+
+// Each calls fn with the capacity units consumed by every recorded
+// operation, ordered by message type and then operation.
+func (m *CapacityMetrics) Each(fn func(message, operation string, units float64)) {
+	m.mu.Lock()
+	keys := make([]capacityKey, 0, len(m.units))
+	units := make(map[capacityKey]float64, len(m.units))
+	for k, v := range m.units {
+		keys = append(keys, k)
+		units[k] = v
+	}
+	m.mu.Unlock()
+
+	slices.SortFunc(keys, func(a, b capacityKey) int {
+		return cmp.Or(cmp.Compare(a.message, b.message), cmp.Compare(a.operation, b.operation))
+	})
+
+	for _, k := range keys {
+		fn(k.message, k.operation, units[k])
+	}
+}
+
+// String returns the capacity units as a JSON object keyed by message type,
+// then by operation, implementing [expvar.Var].
+func (m *CapacityMetrics) String() string {
+	out := map[string]map[string]float64{}
+	m.Each(func(message, operation string, units float64) {
+		if out[message] == nil {
+			out[message] = map[string]float64{}
+		}
+		out[message][operation] = units
+	})
+
+	b, _ := json.Marshal(out)
+	return string(b)
+}
@@ -0,0 +1,85 @@
+package dynabuf_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestWithCapacityAccounting(t *testing.T) {
+	var (
+		capacity dynabuf.CapacityMetrics
+		requests int
+	)
+
+	client := newTestClient(
+		`{"ConsumedCapacity":{"TableName":"users","CapacityUnits":1.5}}`,
+		&requests,
+		dynabuf.WithCapacityAccounting(capacity.Record),
+	)
+
+	user := &testpb.User{Id: "1", Name: "Alice"}
+
+	item, err := dynabuf.Marshal(user)
+	must.NoError(t, err)
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String("users"),
+		Item:      item.(map[string]types.AttributeValue),
+	}
+
+	ctx := dynabuf.ContextWithMessageType(context.Background(), user)
+
+	for range 2 {
+		_, err = client.PutItem(ctx, input)
+		must.NoError(t, err)
+	}
+
+	_, err = client.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String("users"),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: "1"},
+		},
+	})
+	must.NoError(t, err)
+
+	must.Eq(t, 3, requests)
+	must.Eq(t, 3, capacity.Units("dynabuf.test.v1.User", "PutItem"))
+	must.Eq(t, 1.5, capacity.Units("", "GetItem"))
+	must.Eq(t, `{"":{"GetItem":1.5},"dynabuf.test.v1.User":{"PutItem":3}}`, capacity.String())
+
+	// The caller's input is not modified.
+	must.Eq(t, "", input.ReturnConsumedCapacity)
+}
+
+func TestWithCapacityAccountingTwice(t *testing.T) {
+	var (
+		client, config dynabuf.CapacityMetrics
+		requests       int
+	)
+
+	cfg := dynabuf.NewConfig(dynabuf.WithClientOptions(dynabuf.WithCapacityAccounting(config.Record)))
+	c := newTestClient(
+		`{"ConsumedCapacity":{"TableName":"users","CapacityUnits":1}}`,
+		&requests,
+		dynabuf.WithCapacityAccounting(client.Record),
+	)
+
+	// Requests are accounted for once, by the accounting added first.
+	_, err := dynabuf.Do(context.Background(), cfg, c.GetItem, &dynamodb.GetItemInput{
+		TableName: aws.String("users"),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: "1"},
+		},
+	})
+	must.NoError(t, err)
+	must.Eq(t, 1, requests)
+	must.Eq(t, 1, client.Units("", "GetItem"))
+	must.Eq(t, 0, config.Units("", "GetItem"))
+}
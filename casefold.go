@@ -0,0 +1,189 @@
+package dynabuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrCaseCollision is returned by [Unmarshal] with [CaseCollisionError]
+// when an item has several attributes whose names differ only by case.
+var ErrCaseCollision = errors.New("dynabuf: attribute names differ only by case")
+
+// CaseCollisionPolicy is how [Unmarshal] handles attributes whose names
+// differ only by case, such as "userId" and "UserID", which DynamoDB
+// stores as different attributes but tools comparing names without case,
+// such as some exports and ETL jobs, treat as the same.
+type CaseCollisionPolicy int
+
+const (
+	// CaseCollisionReport reports the attributes as a
+	// [DiagnosticCaseCollision] to the function given to [WithDiagnostics],
+	// reading the item as is.
+	CaseCollisionReport CaseCollisionPolicy = iota + 1
+
+	// CaseCollisionError fails with an error wrapping [ErrCaseCollision].
+	CaseCollisionError
+
+	// CaseCollisionMerge reads one of the attributes, ignoring the others,
+	// and reports the ones ignored as a [DiagnosticCaseCollision]. The
+	// attribute read is the one named after the JSON name of a field, then
+	// after the proto name of a field, then the first sorted by name.
+	CaseCollisionMerge
+)
+
+// WithCaseCollisions checks the attributes read by [Unmarshal], and those
+// of the nested messages, for names differing only by case, and handles
+// them with the given policy. Attributes named after different fields,
+// whose names differ only by case too, are not collisions.
+//
+// # Example
+//
+//	err := dynabuf.Unmarshal(out.Item, &user, dynabuf.WithCaseCollisions(dynabuf.CaseCollisionError))
+//	if errors.Is(err, dynabuf.ErrCaseCollision) {
+//	  ...
+//	}
+func WithCaseCollisions(policy CaseCollisionPolicy) Option {
+	return func(o *options) {
+		o.caseCollisions = policy
+	}
+}
+
+// foldCase returns the JSON form of a message of type md with the
+// attributes whose names differ only by case handled by the configured
+// policy.
+func (o *options) foldCase(md protoreflect.MessageDescriptor, data []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var fields map[string]any
+	if err := d.Decode(&fields); err != nil {
+		// Values other than objects are left to fail to unmarshal.
+		return data, nil
+	}
+
+	merged, err := o.foldFields(md, md, fields, "")
+	if err != nil || !merged {
+		return data, err
+	}
+	return json.Marshal(fields)
+}
+
+// foldFields handles the attributes of fields, stored by a message of type
+// md, and of its nested messages, whose names differ only by case,
+// reporting whether any were merged.
+func (o *options) foldFields(root, md protoreflect.MessageDescriptor, fields map[string]any, prefix string) (bool, error) {
+	groups := map[string][]string{}
+	for name := range fields {
+		key := strings.ToLower(name)
+		groups[key] = append(groups[key], name)
+	}
+
+	var merged bool
+	for _, key := range slices.Sorted(maps.Keys(groups)) {
+		names := groups[key]
+		slices.SortFunc(names, func(a, b string) int {
+			if ra, rb := nameRank(md, a), nameRank(md, b); ra != rb {
+				return ra - rb
+			}
+			return strings.Compare(a, b)
+		})
+
+		// Attributes named after fields are only collisions with the
+		// attributes not named after fields.
+		var others []string
+		for _, name := range names[1:] {
+			if nameRank(md, name) == 2 {
+				others = append(others, name)
+			}
+		}
+		if len(others) == 0 {
+			continue
+		}
+
+		path := prefix + names[0]
+		if fd := fieldByName(md, names[0]); fd != nil {
+			path = prefix + string(fd.Name())
+		}
+
+		switch o.caseCollisions {
+		case CaseCollisionError:
+			return false, fmt.Errorf("%w: attributes %s at %s %s", ErrCaseCollision, quoteNames(append([]string{names[0]}, others...)), root.FullName(), path)
+		case CaseCollisionMerge:
+			for _, name := range others {
+				delete(fields, name)
+			}
+			if o.diagnostics != nil {
+				o.report(DiagnosticCaseCollision, root, path, "attribute %q is read, %s ignored", names[0], quoteNames(others))
+			}
+			merged = true
+		default:
+			if o.diagnostics != nil {
+				o.report(DiagnosticCaseCollision, root, path, "attributes %s differ only by case", quoteNames(append([]string{names[0]}, others...)))
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		fd := fieldByName(md, name)
+		if fd == nil {
+			continue
+		}
+		var err error
+		eachValue(fd, fields[name], func(fd protoreflect.FieldDescriptor, v any) {
+			nested, ok := v.(map[string]any)
+			if !ok || err != nil || isWellKnown(fd.Message()) {
+				return
+			}
+			var m bool
+			m, err = o.foldFields(root, fd.Message(), nested, prefix+string(fd.Name())+".")
+			merged = merged || m
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	return merged, nil
+}
+
+// nameRank returns the priority of an attribute of a message of type md:
+// 0 if it is named after the JSON name of a field, 1 if it is named after
+// its proto name, and 2 otherwise.
+func nameRank(md protoreflect.MessageDescriptor, name string) int {
+	switch {
+	case md.Fields().ByJSONName(name) != nil:
+		return 0
+	case md.Fields().ByName(protoreflect.Name(name)) != nil:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// fieldByName returns the field of md an attribute is named after, by its
+// JSON name or proto name, or nil.
+func fieldByName(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if fd := md.Fields().ByJSONName(name); fd != nil {
+		return fd
+	}
+	return md.Fields().ByName(protoreflect.Name(name))
+}
+
+// quoteNames returns the quoted names joined for messages, such as
+// `"a" and "b"`.
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " and " + quoted[len(quoted)-1]
+}
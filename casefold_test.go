@@ -0,0 +1,102 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestCaseCollisions(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"id":   &types.AttributeValueMemberS{Value: "1"},
+		"name": &types.AttributeValueMemberS{Value: "Alice"},
+		"Name": &types.AttributeValueMemberS{Value: "alice"},
+		"NAME": &types.AttributeValueMemberS{Value: "ALICE"},
+		"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"Street": &types.AttributeValueMemberS{Value: "main st"},
+			"street": &types.AttributeValueMemberS{Value: "Main St"},
+		}},
+		"previousAddresses": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"zip_code": &types.AttributeValueMemberN{Value: "1"},
+				"ZIP_CODE": &types.AttributeValueMemberN{Value: "2"},
+			}},
+		}},
+	}
+
+	tests := []struct {
+		name   string
+		policy dynabuf.CaseCollisionPolicy
+		want   *testpb.User
+		diags  []string
+		err    string
+	}{
+		{
+			name:   "report",
+			policy: dynabuf.CaseCollisionReport,
+			diags: []string{
+				`dynabuf: case collision at dynabuf.test.v1.User name: attributes "name", "NAME" and "Name" differ only by case`,
+				`dynabuf: case collision at dynabuf.test.v1.User address.street: attributes "street" and "Street" differ only by case`,
+				`dynabuf: case collision at dynabuf.test.v1.User previous_addresses.zip_code: attributes "zip_code" and "ZIP_CODE" differ only by case`,
+			},
+			// The item is read as is, failing on the unknown attributes.
+			err: "unknown field",
+		},
+		{
+			name:   "error",
+			policy: dynabuf.CaseCollisionError,
+			err:    `dynabuf: attribute names differ only by case: attributes "name", "NAME" and "Name" at dynabuf.test.v1.User name`,
+		},
+		{
+			name:   "merge",
+			policy: dynabuf.CaseCollisionMerge,
+			want: &testpb.User{
+				Id:                "1",
+				Name:              "Alice",
+				Address:           &testpb.Address{Street: "Main St"},
+				PreviousAddresses: []*testpb.Address{{ZipCode: 1}},
+			},
+			diags: []string{
+				`dynabuf: case collision at dynabuf.test.v1.User name: attribute "name" is read, "NAME" and "Name" ignored`,
+				`dynabuf: case collision at dynabuf.test.v1.User address.street: attribute "street" is read, "Street" ignored`,
+				`dynabuf: case collision at dynabuf.test.v1.User previous_addresses.zip_code: attribute "zip_code" is read, "ZIP_CODE" ignored`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var diags []string
+			var user testpb.User
+			err := dynabuf.Unmarshal(item, &user,
+				dynabuf.WithCaseCollisions(test.policy),
+				dynabuf.WithDiagnostics(func(d dynabuf.Diagnostic) {
+					if d.Kind == dynabuf.DiagnosticCaseCollision {
+						diags = append(diags, d.String())
+					}
+				}),
+			)
+			if test.err != "" {
+				must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+				must.ErrorContains(t, err, test.err)
+			} else {
+				must.NoError(t, err)
+				must.Eq(t, test.want, &user, must.Cmp(protocmp.Transform()))
+			}
+			must.Eq(t, test.diags, diags)
+		})
+	}
+
+	// Items without collisions are read as is.
+	var address testpb.Address
+	err := dynabuf.Unmarshal(map[string]types.AttributeValue{
+		"street":  &types.AttributeValueMemberS{Value: "Main St"},
+		"zipCode": &types.AttributeValueMemberN{Value: "1"},
+	}, &address, dynabuf.WithCaseCollisions(dynabuf.CaseCollisionError))
+	must.NoError(t, err)
+	must.Eq(t, &testpb.Address{Street: "Main St", ZipCode: 1}, &address, must.Cmp(protocmp.Transform()))
+}
@@ -0,0 +1,298 @@
+// Package checkpoint records the progress of stream and queue consumers in
+// DynamoDB, so each record is processed exactly once.
+//
+// Each [Checkpoint] is a single item holding the position of the last
+// record processed. Consumers commit a new position together with the
+// writes resulting from processing the records, in a single transaction
+// conditional on the checkpoint not having changed since it was read. If
+// the consumer crashes before committing, neither the writes nor the new
+// position are stored, and the records are processed again; if another
+// consumer committed in the meantime, the commit fails with [ErrConflict]
+// and nothing is written twice.
+//
+// Storing checkpoints in the same table as the data they guard keeps them
+// next to it, but any table with the right key schema can be used.
+//
+// # Scan Checkpoints
+//
+// A [Store] is also a [dynabuf.ProgressStore], saving the progress of table
+// utilities such as [dynabuf.Copy] and [dynabuf.Backfill] as [ScanCheckpoint]
+// items, so interrupted jobs resume where they left off:
+//
+//	store := checkpoint.New(client, "checkpoints")
+//
+//	result, err := dynabuf.Backfill(ctx, table, keys, derive,
+//	  dynabuf.WithProgressStore(store, "backfill-users-email"),
+//	)
+//
+// # Table Schema
+//
+// The table must use a string partition key named "name":
+//
+//	KeySchema: []types.KeySchemaElement{
+//	  {
+//	    AttributeName: aws.String("name"),
+//	    KeyType:       types.KeyTypeHash,
+//	  },
+//	},
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MaxWrites is the maximum number of writes committed with a checkpoint,
+// which leaves room for the checkpoint in a DynamoDB transaction.
+const MaxWrites = 99
+
+// ErrConflict is returned when a checkpoint was committed by another
+// consumer since it was read.
+var ErrConflict = errors.New("checkpoint: checkpoint was committed concurrently")
+
+// Client is the subset of the DynamoDB API used by a [Store].
+type Client interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+// Store reads and commits checkpoints stored in a DynamoDB table.
+//
+// A Store is safe for concurrent use.
+type Store struct {
+	client Client
+	table  string
+	cfg    *dynabuf.Config
+
+	mu       sync.Mutex
+	versions map[string]uint64
+}
+
+// New returns a [Store] storing checkpoints in the given table, sending
+// its requests with the configuration of opts.
+func New(client Client, table string, opts ...dynabuf.ConfigOption) *Store {
+	return &Store{
+		client: client,
+		table:  table,
+		cfg:    dynabuf.NewConfig(opts...),
+
+		versions: map[string]uint64{},
+	}
+}
+
+// Get returns the named checkpoint. A checkpoint that was never committed
+// is returned with an empty position and version 0.
+func (s *Store) Get(ctx context.Context, name string) (*Checkpoint, error) {
+	ctx = dynabuf.ContextWithMessageType(ctx, &Checkpoint{})
+
+	out, err := dynabuf.Do(ctx, s.cfg, s.client.GetItem, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.table),
+		Key:            checkpointKey(name),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint: failed to get checkpoint %q: %w", name, err)
+	}
+
+	if len(out.Item) == 0 {
+		return &Checkpoint{Name: name}, nil
+	}
+
+	cp := &Checkpoint{}
+	if err := dynabuf.Unmarshal(out.Item, cp); err != nil {
+		return nil, fmt.Errorf("checkpoint: failed to decode checkpoint %q: %w", name, err)
+	}
+
+	return cp, nil
+}
+
+// Commit records position as the last position processed by the consumer
+// of cp, together with writes, atomically: either the checkpoint and every
+// write are stored, or none are. It returns [ErrConflict] if the checkpoint
+// changed since cp was read. On success, cp is updated in place.
+//
+// Writes whose own conditions fail cancel the commit, and the returned
+// error wraps the [types.TransactionCanceledException] describing why.
+//
+// # Example
+//
+//	cp, _ := store.Get(ctx, "projector/shard-0001")
+//
+//	for _, record := range records {
+//	  writes = append(writes, project(record))
+//	}
+//
+//	err := store.Commit(ctx, cp, last.SequenceNumber, writes...)
+func (s *Store) Commit(ctx context.Context, cp *Checkpoint, position string, writes ...types.TransactWriteItem) error {
+	if len(writes) > MaxWrites {
+		return fmt.Errorf("checkpoint: cannot commit more than %d writes at once, got %d", MaxWrites, len(writes))
+	}
+
+	next := &Checkpoint{
+		Name:       cp.GetName(),
+		Position:   position,
+		Version:    cp.GetVersion() + 1,
+		UpdateTime: timestamppb.New(s.cfg.Now()),
+	}
+
+	item, err := dynabuf.Marshal(next)
+	if err != nil {
+		return fmt.Errorf("checkpoint: failed to encode checkpoint %q: %w", next.GetName(), err)
+	}
+
+	put := s.put(item.(map[string]types.AttributeValue), cp.GetVersion())
+	items := append([]types.TransactWriteItem{{Put: put}}, writes...)
+
+	ctx = dynabuf.ContextWithMessage(ctx, next)
+
+	if _, err := dynabuf.Do(ctx, s.cfg, s.client.TransactWriteItems, &dynamodb.TransactWriteItemsInput{TransactItems: items}); err != nil {
+		if isConflict(err) {
+			return fmt.Errorf("%w: %q is no longer at version %d", ErrConflict, cp.GetName(), cp.GetVersion())
+		}
+		return fmt.Errorf("checkpoint: failed to commit checkpoint %q: %w", cp.GetName(), err)
+	}
+
+	cp.Position = next.Position
+	cp.Version = next.Version
+	cp.UpdateTime = next.UpdateTime
+
+	return nil
+}
+
+// LoadProgress returns the progress saved under name by
+// [Store.SaveProgress], or nil if none was. It implements
+// [dynabuf.ProgressStore].
+func (s *Store) LoadProgress(ctx context.Context, name string) (*dynabuf.Progress, error) {
+	ctx = dynabuf.ContextWithMessageType(ctx, &ScanCheckpoint{})
+
+	out, err := dynabuf.Do(ctx, s.cfg, s.client.GetItem, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.table),
+		Key:            checkpointKey(name),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint: failed to get scan checkpoint %q: %w", name, err)
+	}
+
+	if len(out.Item) == 0 {
+		s.setVersion(name, 0)
+		return nil, nil
+	}
+
+	cp := &ScanCheckpoint{}
+	if err := dynabuf.Unmarshal(out.Item, cp); err != nil {
+		return nil, fmt.Errorf("checkpoint: failed to decode scan checkpoint %q: %w", name, err)
+	}
+	s.setVersion(name, cp.GetVersion())
+
+	return &dynabuf.Progress{
+		Scanned:       int(cp.GetScanned()),
+		Processed:     int(cp.GetProcessed()),
+		Bytes:         cp.GetBytes(),
+		Segment:       int(cp.GetSegment()),
+		TotalSegments: int(cp.GetTotalSegments()),
+		Token:         cp.GetToken(),
+		Done:          cp.GetDone(),
+	}, nil
+}
+
+// SaveProgress saves p under name as a [ScanCheckpoint], conditional on the
+// checkpoint being at the version last loaded or saved by the store, so
+// two processes running the same job cannot both save their progress. It
+// returns [ErrConflict] if the checkpoint changed. It implements
+// [dynabuf.ProgressStore].
+func (s *Store) SaveProgress(ctx context.Context, name string, p dynabuf.Progress) error {
+	s.mu.Lock()
+	version := s.versions[name]
+	s.mu.Unlock()
+
+	next := &ScanCheckpoint{
+		Name:          name,
+		Token:         p.Token,
+		Done:          p.Done,
+		Segment:       int32(p.Segment),
+		TotalSegments: int32(p.TotalSegments),
+		Scanned:       int64(p.Scanned),
+		Processed:     int64(p.Processed),
+		Bytes:         p.Bytes,
+		Version:       version + 1,
+		UpdateTime:    timestamppb.New(s.cfg.Now()),
+	}
+
+	item, err := dynabuf.Marshal(next)
+	if err != nil {
+		return fmt.Errorf("checkpoint: failed to encode scan checkpoint %q: %w", name, err)
+	}
+
+	ctx = dynabuf.ContextWithMessage(ctx, next)
+
+	_, err = dynabuf.Do(ctx, s.cfg, s.client.TransactWriteItems, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{{Put: s.put(item.(map[string]types.AttributeValue), version)}},
+	})
+	if err != nil {
+		if isConflict(err) {
+			return fmt.Errorf("%w: %q is no longer at version %d", ErrConflict, name, version)
+		}
+		return fmt.Errorf("checkpoint: failed to save scan checkpoint %q: %w", name, err)
+	}
+
+	s.setVersion(name, next.Version)
+
+	return nil
+}
+
+func (s *Store) setVersion(name string, version uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions[name] = version
+}
+
+// put returns the put of a checkpoint item, conditional on the checkpoint
+// stored being at version, or not existing for version 0.
+func (s *Store) put(item map[string]types.AttributeValue, version uint64) *types.Put {
+	put := &types.Put{
+		TableName: aws.String(s.table),
+		Item:      item,
+	}
+	if version == 0 {
+		put.ConditionExpression = aws.String("attribute_not_exists(#name)")
+		put.ExpressionAttributeNames = map[string]string{"#name": "name"}
+	} else {
+		put.ConditionExpression = aws.String("#version = :version")
+		put.ExpressionAttributeNames = map[string]string{"#version": "version"}
+		put.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":version": versionValue(version),
+		}
+	}
+	return put
+}
+
+// isConflict reports whether err is a transaction canceled because the
+// condition of its first item, the checkpoint, failed.
+func isConflict(err error) bool {
+	var canceled *types.TransactionCanceledException
+	return errors.As(err, &canceled) && len(canceled.CancellationReasons) > 0 &&
+		aws.ToString(canceled.CancellationReasons[0].Code) == "ConditionalCheckFailed"
+}
+
+// versionValue returns the attribute value of a version as written by
+// [dynabuf.Marshal], which follows protojson in encoding 64-bit integers as
+// strings.
+func versionValue(version uint64) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: strconv.FormatUint(version, 10)}
+}
+
+func checkpointKey(name string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"name": &types.AttributeValueMemberS{Value: name},
+	}
+}
@@ -0,0 +1,183 @@
+package checkpoint_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/checkpoint"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/shoenig/test/must"
+)
+
+func newStore(t *testing.T) (*checkpoint.Store, *dynamotest.Client) {
+	t.Helper()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("data"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("name"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	return checkpoint.New(client, "data"), client
+}
+
+// countWrite returns a write incrementing the named counter item.
+func countWrite(name string) types.TransactWriteItem {
+	return types.TransactWriteItem{
+		Update: &types.Update{
+			TableName:                aws.String("data"),
+			Key:                      map[string]types.AttributeValue{"name": &types.AttributeValueMemberS{Value: name}},
+			UpdateExpression:         aws.String("ADD #count :one"),
+			ExpressionAttributeNames: map[string]string{"#count": "count"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":one": &types.AttributeValueMemberN{Value: "1"},
+			},
+		},
+	}
+}
+
+func count(t *testing.T, client *dynamotest.Client, name string) string {
+	t.Helper()
+
+	out, err := client.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String("data"),
+		Key:       map[string]types.AttributeValue{"name": &types.AttributeValueMemberS{Value: name}},
+	})
+	must.NoError(t, err)
+
+	n, ok := out.Item["count"].(*types.AttributeValueMemberN)
+	if !ok {
+		return "0"
+	}
+	return n.Value
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		check func(t *testing.T, store *checkpoint.Store, client *dynamotest.Client)
+	}{
+		{
+			name: "commits positions with writes",
+			check: func(t *testing.T, store *checkpoint.Store, client *dynamotest.Client) {
+				cp, err := store.Get(ctx, "projector/shard-1")
+				must.NoError(t, err)
+				must.Eq(t, "", cp.GetPosition())
+				must.Eq(t, uint64(0), cp.GetVersion())
+
+				must.NoError(t, store.Commit(ctx, cp, "100", countWrite("orders")))
+				must.Eq(t, "100", cp.GetPosition())
+				must.Eq(t, uint64(1), cp.GetVersion())
+
+				must.NoError(t, store.Commit(ctx, cp, "200", countWrite("orders")))
+
+				stored, err := store.Get(ctx, "projector/shard-1")
+				must.NoError(t, err)
+				must.Eq(t, "200", stored.GetPosition())
+				must.Eq(t, uint64(2), stored.GetVersion())
+				must.NotNil(t, stored.GetUpdateTime())
+
+				must.Eq(t, "2", count(t, client, "orders"))
+			},
+		},
+		{
+			name: "concurrent commits are rejected without writing",
+			check: func(t *testing.T, store *checkpoint.Store, client *dynamotest.Client) {
+				first, err := store.Get(ctx, "projector/shard-1")
+				must.NoError(t, err)
+				second, err := store.Get(ctx, "projector/shard-1")
+				must.NoError(t, err)
+
+				must.NoError(t, store.Commit(ctx, first, "100", countWrite("orders")))
+
+				err = store.Commit(ctx, second, "100", countWrite("orders"))
+				must.ErrorIs(t, err, checkpoint.ErrConflict)
+				must.Eq(t, uint64(0), second.GetVersion())
+
+				must.NoError(t, store.Commit(ctx, first, "200", countWrite("orders")))
+
+				err = store.Commit(ctx, second, "200", countWrite("orders"))
+				must.ErrorIs(t, err, checkpoint.ErrConflict)
+
+				must.Eq(t, "2", count(t, client, "orders"))
+			},
+		},
+		{
+			name: "failed writes do not move the checkpoint",
+			check: func(t *testing.T, store *checkpoint.Store, client *dynamotest.Client) {
+				cp, err := store.Get(ctx, "projector/shard-1")
+				must.NoError(t, err)
+
+				write := countWrite("orders")
+				write.Update.ConditionExpression = aws.String("attribute_exists(#count)")
+
+				err = store.Commit(ctx, cp, "100", write)
+				must.Error(t, err)
+				must.False(t, errors.Is(err, checkpoint.ErrConflict))
+
+				var canceled *types.TransactionCanceledException
+				must.True(t, errors.As(err, &canceled))
+
+				stored, err := store.Get(ctx, "projector/shard-1")
+				must.NoError(t, err)
+				must.Eq(t, uint64(0), stored.GetVersion())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store, client := newStore(t)
+			test.check(t, store, client)
+		})
+	}
+}
+
+func TestStoreProgress(t *testing.T) {
+	ctx := context.Background()
+
+	store, client := newStore(t)
+
+	p, err := store.LoadProgress(ctx, "backfill/0")
+	must.NoError(t, err)
+	must.Nil(t, p)
+
+	// Another process running the same job loads no progress either.
+	other := checkpoint.New(client, "data")
+	_, err = other.LoadProgress(ctx, "backfill/0")
+	must.NoError(t, err)
+
+	saved := dynabuf.Progress{Scanned: 2, Processed: 1, Bytes: 64, Segment: 0, TotalSegments: 2, Token: "token"}
+	must.NoError(t, store.SaveProgress(ctx, "backfill/0", saved))
+	saved.Scanned, saved.Processed = 4, 3
+	must.NoError(t, store.SaveProgress(ctx, "backfill/0", saved))
+
+	err = other.SaveProgress(ctx, "backfill/0", dynabuf.Progress{Scanned: 1, TotalSegments: 2})
+	must.ErrorIs(t, err, checkpoint.ErrConflict)
+
+	// A restarted process resumes from the last progress saved.
+	restarted := checkpoint.New(client, "data")
+	p, err = restarted.LoadProgress(ctx, "backfill/0")
+	must.NoError(t, err)
+	must.Eq(t, saved, *p)
+
+	saved = dynabuf.Progress{Scanned: 5, Processed: 4, Bytes: 80, TotalSegments: 2, Done: true}
+	must.NoError(t, restarted.SaveProgress(ctx, "backfill/0", saved))
+
+	p, err = store.LoadProgress(ctx, "backfill/0")
+	must.NoError(t, err)
+	must.Eq(t, saved, *p)
+}
@@ -0,0 +1,69 @@
+// Command protoc-gen-dynabuf-check is a protoc plugin failing code
+// generation when several fields or composite attributes of a message map
+// to the same DynamoDB attribute, as [dynabuf.CheckAttributeNames] reports,
+// so such messages never reach code calling [dynabuf.Marshal].
+//
+// It generates no files, so it runs alongside the other plugins generating
+// code for the messages, such as with buf:
+//
+//	version: v2
+//	plugins:
+//	  - local: protoc-gen-dynabuf-check
+//	    out: .
+//
+// It is installed with:
+//
+//	go install github.com/picatz/dynabuf/cmd/protoc-gen-dynabuf-check@latest
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/picatz/dynabuf"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func main() {
+	protogen.Options{}.Run(check)
+}
+
+// check returns the attribute name collisions of the messages of the files
+// to generate.
+func check(gen *protogen.Plugin) error {
+	gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+
+	var (
+		errs []error
+		seen = map[string]bool{}
+	)
+
+	var visit func(f *protogen.File, messages []*protogen.Message)
+	visit = func(f *protogen.File, messages []*protogen.Message) {
+		for _, m := range messages {
+			if m.Desc.IsMapEntry() {
+				continue
+			}
+
+			// The collisions of message types nested in several messages
+			// are reported once.
+			if err := dynabuf.CheckAttributeNames(m.Desc); err != nil {
+				for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+					if !seen[err.Error()] {
+						seen[err.Error()] = true
+						errs = append(errs, fmt.Errorf("%s: %w", f.Desc.Path(), err))
+					}
+				}
+			}
+			visit(f, m.Messages)
+		}
+	}
+	for _, f := range gen.Files {
+		if f.Generate {
+			visit(f, f.Messages)
+		}
+	}
+
+	return errors.Join(errs...)
+}
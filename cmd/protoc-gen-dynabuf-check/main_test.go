@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/picatz/dynabuf"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// file returns a proto2 file whose Item message has a nested Address
+// message with fields colliding, if collide is set.
+func file(collide bool) *descriptorpb.FileDescriptorProto {
+	line1 := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String("line1"),
+		Number: proto.Int32(2),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+	}
+	if collide {
+		line1.JsonName = proto.String("street")
+	}
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("example/item.proto"),
+		Package: proto.String("example"),
+		Syntax:  proto.String("proto2"),
+		Options: &descriptorpb.FileOptions{GoPackage: proto.String("example.com/example")},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("address"),
+						Number:   proto.Int32(1),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: proto.String(".example.Item.Address"),
+					},
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("Address"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							{
+								Name:   proto.String("street"),
+								Number: proto.Int32(1),
+								Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+								Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+							},
+							line1,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		collide bool
+		err     string
+	}{
+		{
+			name: "no collisions",
+		},
+		{
+			// The collision is reported once, although Address is both
+			// declared in Item and the type of one of its fields.
+			name:    "collisions",
+			collide: true,
+			err:     `example/item.proto: dynabuf: attribute name collision: attribute "street" of example.Item.Address is mapped by field street and field line1`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gen, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{
+				FileToGenerate: []string{"example/item.proto"},
+				ProtoFile:      []*descriptorpb.FileDescriptorProto{file(test.collide)},
+			})
+			must.NoError(t, err)
+
+			err = check(gen)
+			if test.err == "" {
+				must.NoError(t, err)
+				return
+			}
+			must.ErrorIs(t, err, dynabuf.ErrAttributeCollision)
+			must.EqError(t, err, test.err)
+		})
+	}
+}
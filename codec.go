@@ -0,0 +1,54 @@
+package dynabuf
+
+import (
+	"slices"
+)
+
+// Codec marshals and unmarshals items with a fixed set of options. A Codec
+// cannot be changed once built, so it is safe for concurrent use, and can
+// be shared by every goroutine reading and writing items of a table.
+//
+// Options sharing state, such as [WithFieldStats], [WithFailureMetrics],
+// and [WithMessagePool], share it between the goroutines using the codec,
+// which their types are safe for. Functions passed to options, such as
+// with [WithDiagnostics] and [WithBeforeEncode], are called concurrently,
+// so they must be safe for concurrent use too.
+//
+// # Example
+//
+//	var users = dynabuf.NewCodec(
+//	  dynabuf.WithDiscardUnknown(),
+//	  dynabuf.WithLimitChecks(),
+//	)
+//
+//	item, err := users.Marshal(user)
+type Codec struct {
+	opts []Option
+}
+
+// NewCodec returns a [Codec] with opts.
+func NewCodec(opts ...Option) *Codec {
+	return &Codec{opts: slices.Clone(opts)}
+}
+
+// With returns a [Codec] with the options of c followed by opts, leaving c
+// unchanged.
+func (c *Codec) With(opts ...Option) *Codec {
+	return &Codec{opts: slices.Concat(c.opts, opts)}
+}
+
+// Options returns the options of c, for the helpers accepting options, such
+// as [BatchGet].
+func (c *Codec) Options() []Option {
+	return slices.Clone(c.opts)
+}
+
+// Marshal is like [Marshal], with the options of c.
+func (c *Codec) Marshal(v any) (any, error) {
+	return Marshal(v, c.opts...)
+}
+
+// Unmarshal is like [Unmarshal], with the options of c.
+func (c *Codec) Unmarshal(av any, v any) error {
+	return Unmarshal(av, v, c.opts...)
+}
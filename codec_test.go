@@ -0,0 +1,93 @@
+package dynabuf_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCodec(t *testing.T) {
+	keys := []string{"id"}
+	opts := dynabuf.MarshalOptions{KeyAttributes: keys, NormalizeKeys: true, FoldKeys: true}
+	codec := opts.Codec()
+
+	// Changes to the options a codec was built from do not change it.
+	keys[0] = "name"
+	av, err := codec.Marshal(&testpb.User{Id: "USER-1", Name: "Alice"})
+	must.NoError(t, err)
+	item := av.(map[string]types.AttributeValue)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "user-1"}, item["id"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "Alice"}, item["name"])
+
+	// Codecs derived from another leave it unchanged.
+	strict := codec.With(dynabuf.WithLimitChecks())
+	must.SliceLen(t, len(opts.Options()), codec.Options())
+	must.SliceLen(t, len(opts.Options())+1, strict.Options())
+
+	var user testpb.User
+	must.NoError(t, strict.Unmarshal(item, &user))
+	must.Eq(t, "user-1", user.GetId())
+}
+
+func TestCodecConcurrency(t *testing.T) {
+	stats := &dynabuf.FieldStats{}
+	metrics := &dynabuf.FailureMetrics{}
+	codec := dynabuf.NewCodec(
+		dynabuf.WithFieldStats(stats),
+		dynabuf.WithFailureMetrics(metrics),
+		dynabuf.WithMessagePool(dynabuf.NewMessagePool(&testpb.User{})),
+		dynabuf.WithKeyAttributes("id"),
+		dynabuf.WithNormalizedKeys(true),
+		dynabuf.WithLimitChecks(),
+	)
+
+	const goroutines, iterations = 8, 50
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range iterations {
+				want := &testpb.User{
+					Id:      fmt.Sprintf("user-%d-%d", g, i),
+					Name:    "Alice",
+					Age:     int32(i),
+					Address: &testpb.Address{Street: "1 Main St", ZipCode: int32(g)},
+				}
+				av, err := codec.Marshal(want)
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				var users []*testpb.User
+				items := []map[string]types.AttributeValue{av.(map[string]types.AttributeValue)}
+				if err := codec.Unmarshal(items, &users); err != nil {
+					errs <- err
+					return
+				}
+				if !proto.Equal(want, users[0]) {
+					errs <- fmt.Errorf("goroutine %d, iteration %d: got %v, want %v", g, i, users[0], want)
+					return
+				}
+				dynabuf.Release(users[0], codec.Options()...)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		must.NoError(t, err)
+	}
+	must.Eq(t, "{}", metrics.String())
+	must.SliceNotEmpty(t, stats.Report())
+}
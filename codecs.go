@@ -0,0 +1,83 @@
+package dynabuf
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Codecs is a registry of the [Codec] of each table and message type, so
+// services with many entity types configure how each is stored once at
+// startup, rather than passing options at every call site.
+//
+// The codec of a message in a table has the options of the codec of the
+// table, followed by those of the codec of the message type, which take
+// precedence, after those of Default. The zero value is ready to use, and
+// a Codecs is safe for concurrent use.
+//
+// # Example
+//
+//	var codecs dynabuf.Codecs
+//	codecs.SetTable("orders", dynabuf.NewCodec(dynabuf.WithLimitChecks()))
+//	codecs.SetMessage(&example.Invoice{}, dynabuf.NewCodec(dynabuf.WithMetadata("v3")))
+//
+//	item, err := codecs.Marshal("orders", invoice)
+type Codecs struct {
+	// Default, if set, is the codec of tables and messages without one.
+	Default *Codec
+
+	mu       sync.RWMutex
+	tables   map[string]*Codec
+	messages map[protoreflect.FullName]*Codec
+}
+
+// SetTable sets the codec of the items of table, replacing any set before.
+func (c *Codecs) SetTable(table string, codec *Codec) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tables == nil {
+		c.tables = map[string]*Codec{}
+	}
+	c.tables[table] = codec
+}
+
+// SetMessage sets the codec of the messages of the type of msg, in every
+// table, replacing any set before.
+func (c *Codecs) SetMessage(msg proto.Message, codec *Codec) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages == nil {
+		c.messages = map[protoreflect.FullName]*Codec{}
+	}
+	c.messages[msg.ProtoReflect().Descriptor().FullName()] = codec
+}
+
+// Lookup returns the codec of the messages of the type of msg in table.
+func (c *Codecs) Lookup(table string, msg proto.Message) *Codec {
+	c.mu.RLock()
+	tableCodec := c.tables[table]
+	messageCodec := c.messages[msg.ProtoReflect().Descriptor().FullName()]
+	c.mu.RUnlock()
+
+	codec := NewCodec()
+	for _, set := range []*Codec{c.Default, tableCodec, messageCodec} {
+		if set != nil {
+			codec = codec.With(set.opts...)
+		}
+	}
+	return codec
+}
+
+// Marshal is like [Marshal] for a message stored in table, with the options
+// of its codec.
+func (c *Codecs) Marshal(table string, msg proto.Message) (map[string]types.AttributeValue, error) {
+	return MarshalMessage(msg, c.Lookup(table, msg).opts...)
+}
+
+// Unmarshal is like [Unmarshal] for an item of table, with the options of
+// the codec of msg.
+func (c *Codecs) Unmarshal(table string, item map[string]types.AttributeValue, msg proto.Message) error {
+	return Unmarshal(item, msg, c.Lookup(table, msg).opts...)
+}
@@ -0,0 +1,43 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestCodecs(t *testing.T) {
+	codecs := dynabuf.Codecs{Default: dynabuf.NewCodec(dynabuf.WithMetadata("v1"))}
+	codecs.SetTable("users", dynabuf.NewCodec(dynabuf.WithProtoNames(true)))
+	codecs.SetMessage(&testpb.Address{}, dynabuf.NewCodec(dynabuf.WithProtoNames(false)))
+
+	user := &testpb.User{Id: "1", PreviousAddresses: []*testpb.Address{{ZipCode: 1}}}
+
+	// Tables without a codec use the default.
+	item, err := codecs.Marshal("accounts", user)
+	must.NoError(t, err)
+	must.MapContainsKeys(t, item, []string{"previousAddresses", dynabuf.MetadataAttribute})
+
+	// Codecs of tables add to the default.
+	item, err = codecs.Marshal("users", user)
+	must.NoError(t, err)
+	must.MapContainsKeys(t, item, []string{"previous_addresses", dynabuf.MetadataAttribute})
+
+	var got testpb.User
+	must.NoError(t, codecs.Unmarshal("users", item, &got))
+	must.Eq(t, int32(1), got.GetPreviousAddresses()[0].GetZipCode())
+
+	// Codecs of message types take precedence over those of tables.
+	item, err = codecs.Marshal("users", &testpb.Address{ZipCode: 1})
+	must.NoError(t, err)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberN{Value: "1"}, item["zipCode"])
+
+	// The zero value uses no options.
+	var empty dynabuf.Codecs
+	item, err = empty.Marshal("users", user)
+	must.NoError(t, err)
+	must.MapNotContainsKey(t, item, dynabuf.MetadataAttribute)
+}
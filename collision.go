@@ -0,0 +1,112 @@
+package dynabuf
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrAttributeCollision is returned when several fields or composite
+// attributes of a message map to the same attribute, so one would silently
+// overwrite the other.
+var ErrAttributeCollision = errors.New("dynabuf: attribute name collision")
+
+// attributeCollisions caches the result of CheckAttributeNames for every
+// message type marshaled, keyed by descriptor.
+var attributeCollisions sync.Map
+
+// CheckAttributeNames returns an error wrapping [ErrAttributeCollision] for
+// every attribute that several fields or composite attributes of messages
+// of type md, or of the message types nested in it, map to: fields with the
+// same JSON name, which protoc only rejects in proto3 files, aliases of
+// fields named after other fields or aliases, composite attributes with the
+// same name, and composite attributes named after the proto name of a
+// field, which [Unmarshal] also reads the field from.
+//
+// [Marshal] fails with the same error for such messages. Checking message
+// types when their schema changes, such as with the protoc-gen-dynabuf-check
+// plugin or in a test, reports collisions before code using them is
+// deployed.
+//
+// # Example
+//
+//	func TestAttributeNames(t *testing.T) {
+//	  err := dynabuf.CheckAttributeNames((&example.User{}).ProtoReflect().Descriptor())
+//	  if err != nil {
+//	    t.Fatal(err)
+//	  }
+//	}
+func CheckAttributeNames(md protoreflect.MessageDescriptor) error {
+	var errs []error
+
+	seen := map[protoreflect.FullName]bool{}
+
+	var visit func(md protoreflect.MessageDescriptor)
+	visit = func(md protoreflect.MessageDescriptor) {
+		if seen[md.FullName()] || isWellKnown(md) {
+			return
+		}
+		seen[md.FullName()] = true
+
+		sources := map[string][]string{}
+
+		var nested []protoreflect.MessageDescriptor
+
+		fields := md.Fields()
+		for i := range fields.Len() {
+			fd := fields.Get(i)
+			sources[fd.JSONName()] = append(sources[fd.JSONName()], fmt.Sprintf("field %s", fd.Name()))
+			for _, alias := range fieldAliases(fd) {
+				sources[alias] = append(sources[alias], fmt.Sprintf("field %s, by its alias", fd.Name()))
+			}
+
+			if md := fieldMessage(fd); md != nil {
+				nested = append(nested, md)
+			}
+		}
+
+		// Composite attributes are only stored alongside the fields of
+		// items, but checking them for every message type keeps the check
+		// independent of where the type is used.
+		opts, _ := proto.GetExtension(md.Options(), dynabufpb.E_Message).(*dynabufpb.MessageOptions)
+		for _, c := range opts.GetCompositeAttributes() {
+			sources[c.GetName()] = append(sources[c.GetName()], fmt.Sprintf("composite attribute with template %q", c.GetTemplate()))
+
+			if fd := fields.ByName(protoreflect.Name(c.GetName())); fd != nil && fd.JSONName() != c.GetName() {
+				sources[c.GetName()] = append(sources[c.GetName()], fmt.Sprintf("field %s, by its proto name", fd.Name()))
+			}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(sources)) {
+			if s := sources[name]; len(s) > 1 {
+				errs = append(errs, fmt.Errorf("%w: attribute %q of %s is mapped by %s", ErrAttributeCollision, name, md.FullName(), strings.Join(s, " and ")))
+			}
+		}
+
+		for _, md := range nested {
+			visit(md)
+		}
+	}
+	visit(md)
+
+	return errors.Join(errs...)
+}
+
+// checkAttributeNames returns the result of CheckAttributeNames for md,
+// which is only computed once per message type.
+func checkAttributeNames(md protoreflect.MessageDescriptor) error {
+	if err, ok := attributeCollisions.Load(md); ok {
+		err, _ := err.(error)
+		return err
+	}
+	err := CheckAttributeNames(md)
+	attributeCollisions.Store(md, err)
+	return err
+}
@@ -0,0 +1,105 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/dynabufpb"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// collidingMessage returns a proto2 message type whose fields, aliases, and
+// composite attributes collide, which protoc does not reject.
+func collidingMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	opts := &descriptorpb.MessageOptions{}
+	proto.SetExtension(opts, dynabufpb.E_Message, &dynabufpb.MessageOptions{
+		CompositeAttributes: []*dynabufpb.CompositeAttribute{
+			{Name: "sk", Template: "A#{id}"},
+			{Name: "sk", Template: "B#{id}"},
+			{Name: "create_time", Template: "{id}"},
+		},
+	})
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, jsonName string) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   typ.Enum(),
+		}
+		if jsonName != "" {
+			fd.JsonName = proto.String(jsonName)
+		}
+		return fd
+	}
+
+	address := field("address", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, "")
+	address.TypeName = proto.String(".dynabuf.collision.Address")
+	address.Options = &descriptorpb.FieldOptions{}
+	proto.SetExtension(address.Options, dynabufpb.E_Field, &dynabufpb.FieldOptions{Aliases: []string{"id"}})
+
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("collision.proto"),
+		Package: proto.String("dynabuf.collision"),
+		Syntax:  proto.String("proto2"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("create_time", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("legacy_id", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, "id"),
+					address,
+				},
+				Options: opts,
+			},
+			{
+				Name: proto.String("Address"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("street", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("line1", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, "street"),
+				},
+			},
+		},
+	}, nil)
+	must.NoError(t, err)
+
+	return fd.Messages().ByName("Item")
+}
+
+func TestCheckAttributeNames(t *testing.T) {
+	must.NoError(t, dynabuf.CheckAttributeNames((&testpb.Order{}).ProtoReflect().Descriptor()))
+	must.NoError(t, dynabuf.CheckAttributeNames((&testpb.User{}).ProtoReflect().Descriptor()))
+
+	md := collidingMessage(t)
+
+	err := dynabuf.CheckAttributeNames(md)
+	must.ErrorIs(t, err, dynabuf.ErrAttributeCollision)
+
+	var lines []string
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		lines = append(lines, err.Error())
+	}
+	must.Eq(t, []string{
+		`dynabuf: attribute name collision: attribute "create_time" of dynabuf.collision.Item is mapped by composite attribute with template "{id}" and field create_time, by its proto name`,
+		`dynabuf: attribute name collision: attribute "id" of dynabuf.collision.Item is mapped by field id and field legacy_id and field address, by its alias`,
+		`dynabuf: attribute name collision: attribute "sk" of dynabuf.collision.Item is mapped by composite attribute with template "A#{id}" and composite attribute with template "B#{id}"`,
+		`dynabuf: attribute name collision: attribute "street" of dynabuf.collision.Address is mapped by field street and field line1`,
+	}, lines)
+
+	// Marshal fails rather than overwriting one of the values.
+	msg := dynamicpb.NewMessage(md)
+	msg.Set(md.Fields().ByName("id"), protoreflect.ValueOfString("1"))
+	_, err = dynabuf.Marshal(msg)
+	must.ErrorIs(t, err, dynabuf.ErrFailedToMarshal)
+	must.ErrorIs(t, err, dynabuf.ErrAttributeCollision)
+	must.ErrorContains(t, err, "field legacy_id")
+}
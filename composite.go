@@ -0,0 +1,273 @@
+package dynabuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// compositeAttribute is a parsed composite attribute of a message.
+type compositeAttribute struct {
+	name string
+
+	// texts are the text before, between, and after the fields, so there
+	// is one more text than fields.
+	texts  []string
+	fields []protoreflect.FieldDescriptor
+
+	// escaped are the characters escaped within field values, which are
+	// those starting the texts following fields, so values never contain
+	// them unescaped.
+	escaped string
+}
+
+// compositeAttributes returns the composite attributes of messages of type
+// md, returning an error if one of them is invalid.
+func compositeAttributes(md protoreflect.MessageDescriptor) ([]*compositeAttribute, error) {
+	opts, ok := proto.GetExtension(md.Options(), dynabufpb.E_Message).(*dynabufpb.MessageOptions)
+	if !ok {
+		return nil, nil
+	}
+
+	var attrs []*compositeAttribute
+	for _, attr := range opts.GetCompositeAttributes() {
+		c, err := parseCompositeAttribute(md, attr)
+		if err != nil {
+			return nil, fmt.Errorf("composite attribute %q of %s: %w", attr.GetName(), md.FullName(), err)
+		}
+		attrs = append(attrs, c)
+	}
+	return attrs, nil
+}
+
+// parseCompositeAttribute parses the template of a composite attribute.
+func parseCompositeAttribute(md protoreflect.MessageDescriptor, attr *dynabufpb.CompositeAttribute) (*compositeAttribute, error) {
+	if attr.GetName() == "" {
+		return nil, fmt.Errorf("name is empty")
+	}
+	if md.Fields().ByJSONName(attr.GetName()) != nil {
+		return nil, fmt.Errorf("name is the name of a field")
+	}
+
+	c := &compositeAttribute{name: attr.GetName()}
+
+	var text strings.Builder
+	for rest := attr.GetTemplate(); rest != ""; {
+		i := strings.IndexAny(rest, "{}")
+		if i < 0 {
+			text.WriteString(rest)
+			break
+		}
+		text.WriteString(rest[:i])
+		if rest[i] == '}' {
+			return nil, fmt.Errorf("unexpected } in template %q", attr.GetTemplate())
+		}
+
+		name, after, ok := strings.Cut(rest[i+1:], "}")
+		if !ok {
+			return nil, fmt.Errorf("unclosed { in template %q", attr.GetTemplate())
+		}
+		fd := md.Fields().ByJSONName(name)
+		if fd == nil {
+			fd = md.Fields().ByName(protoreflect.Name(name))
+		}
+		switch {
+		case fd == nil:
+			return nil, fmt.Errorf("unknown field %q", name)
+		case fd.IsList() || fd.IsMap():
+			return nil, fmt.Errorf("field %q is not a single value", name)
+		case len(c.fields) > 0 && text.Len() == 0:
+			return nil, fmt.Errorf("fields %q and %q are not separated by text", c.fields[len(c.fields)-1].Name(), name)
+		}
+
+		c.texts = append(c.texts, text.String())
+		c.fields = append(c.fields, fd)
+		text.Reset()
+		rest = after
+	}
+	c.texts = append(c.texts, text.String())
+
+	switch {
+	case len(c.fields) == 0:
+		return nil, fmt.Errorf("template %q has no fields", attr.GetTemplate())
+	case strings.Contains(attr.GetTemplate(), `\`):
+		return nil, fmt.Errorf("template %q has a backslash, which escapes separators", attr.GetTemplate())
+	}
+	for _, text := range c.texts[1:] {
+		if r, _ := utf8.DecodeRuneInString(text); text != "" && !strings.ContainsRune(c.escaped, r) {
+			c.escaped += string(r)
+		}
+	}
+	return c, nil
+}
+
+// uses reports whether the attribute is composed of the field with the
+// JSON name.
+func (c *compositeAttribute) uses(name string) bool {
+	for _, fd := range c.fields {
+		if fd.JSONName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// compose returns the value of the attribute composed of the stored values
+// of its fields, where zero values are empty.
+func (c *compositeAttribute) compose(fields map[string]any) (string, error) {
+	var b strings.Builder
+	b.WriteString(c.texts[0])
+	for i, fd := range c.fields {
+		var s string
+		switch v := fields[fd.JSONName()].(type) {
+		case nil:
+		case string:
+			s = v
+		case json.Number:
+			s = string(v)
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			s = strconv.FormatBool(v)
+		default:
+			return "", fmt.Errorf("field %q of composite attribute %q is not stored as a single value", fd.Name(), c.name)
+		}
+
+		// Control characters are invisible in keys, and in the prefixes
+		// queries match them by.
+		if i := strings.IndexFunc(s, unicode.IsControl); i >= 0 {
+			r, _ := utf8.DecodeRuneInString(s[i:])
+			return "", fmt.Errorf("%w: field %q of composite attribute %q has control character %q", ErrInvalidKey, fd.Name(), c.name, r)
+		}
+		b.WriteString(EscapeKeyComponent(s, c.escaped))
+		b.WriteString(c.texts[i+1])
+	}
+	return b.String(), nil
+}
+
+// decompose returns the stored values of the fields the attribute value s
+// is composed of, leaving out empty values.
+func (c *compositeAttribute) decompose(s string) (map[string]any, error) {
+	rest, ok := strings.CutPrefix(s, c.texts[0])
+	if !ok {
+		return nil, fmt.Errorf("composite attribute %q has invalid value %q", c.name, s)
+	}
+
+	fields := map[string]any{}
+	for i, fd := range c.fields {
+		next := c.texts[i+1]
+
+		var (
+			value strings.Builder
+			found bool
+		)
+		for len(rest) > 0 && !found {
+			switch {
+			case rest[0] == '\\' && len(rest) > 1:
+				value.WriteByte(rest[1])
+				rest = rest[2:]
+			case next != "" && strings.HasPrefix(rest, next):
+				rest, found = rest[len(next):], true
+			default:
+				value.WriteByte(rest[0])
+				rest = rest[1:]
+			}
+		}
+		if next != "" && !found {
+			return nil, fmt.Errorf("composite attribute %q has invalid value %q", c.name, s)
+		}
+
+		if v := value.String(); v != "" {
+			fields[fd.JSONName()] = storedValue(fd, v)
+		}
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("composite attribute %q has invalid value %q", c.name, s)
+	}
+	return fields, nil
+}
+
+// storedValue returns the stored value of fd written as s within a
+// composite attribute.
+func storedValue(fd protoreflect.FieldDescriptor, s string) any {
+	if isSortable(fd) {
+		return s
+	}
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return s == "true"
+	case protoreflect.StringKind:
+		if !isDecimal(fd) {
+			return s
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.FloatKind, protoreflect.DoubleKind:
+	default:
+		return s
+	}
+
+	// NaN and infinities are stored as strings.
+	if !decimalPattern.MatchString(s) {
+		return s
+	}
+	return json.Number(s)
+}
+
+// composeAttributes adds the composite attributes of messages of type md
+// to their stored fields.
+func composeAttributes(md protoreflect.MessageDescriptor, fields map[string]any) error {
+	attrs, err := compositeAttributes(md)
+	if err != nil {
+		return err
+	}
+	for _, c := range attrs {
+		v, err := c.compose(fields)
+		if err != nil {
+			return err
+		}
+		fields[c.name] = v
+	}
+	return nil
+}
+
+// decomposeAttributes removes the composite attributes of messages of type
+// md from their stored fields, setting the fields they are composed of
+// when missing.
+func decomposeAttributes(md protoreflect.MessageDescriptor, fields map[string]any) error {
+	attrs, err := compositeAttributes(md)
+	if err != nil {
+		return err
+	}
+	for _, c := range attrs {
+		s, ok := fields[c.name].(string)
+		if !ok {
+			continue
+		}
+		delete(fields, c.name)
+
+		values, err := c.decompose(s)
+		if err != nil {
+			return err
+		}
+		for name, v := range values {
+			_, ok := fields[name]
+			if fd := fieldByName(md, name); fd != nil {
+				if _, byName := fields[string(fd.Name())]; byName {
+					ok = true
+				}
+			}
+			if !ok {
+				fields[name] = v
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package dynabuf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCompositeAttributes(t *testing.T) {
+	order := &testpb.Order{
+		Customer:   `acme\corp`,
+		Id:         "a#1",
+		Status:     "OPEN",
+		CreateTime: timestamppb.New(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+		Items:      3,
+	}
+
+	item := dynabuf.MustMarshalItem(order)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: `ORDER#OPEN#2024-03-01T00:00:00Z#a\#1`}, item["sk"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: `acme\\corp#3`}, item["bySize"])
+
+	var got testpb.Order
+	must.NoError(t, dynabuf.Unmarshal(item, &got))
+	must.True(t, proto.Equal(order, &got))
+
+	// Items of indexes projecting only keys set the fields composing them.
+	projected := map[string]types.AttributeValue{
+		"customer": item["customer"],
+		"sk":       item["sk"],
+		"bySize":   item["bySize"],
+	}
+	got.Reset()
+	must.NoError(t, dynabuf.Unmarshal(projected, &got))
+	must.True(t, proto.Equal(order, &got))
+
+	// Zero values are empty.
+	item = dynabuf.MustMarshalItem(&testpb.Order{Customer: "acme", Id: "2"})
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "ORDER###2"}, item["sk"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "acme#"}, item["bySize"])
+
+	err := dynabuf.Unmarshal(map[string]types.AttributeValue{
+		"sk": &types.AttributeValueMemberS{Value: "USER#1"},
+	}, &got)
+	must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+	must.StrContains(t, err.Error(), `composite attribute "sk" has invalid value "USER#1"`)
+}
+
+func TestCompositeAttributesUpdate(t *testing.T) {
+	order := &testpb.Order{Customer: "acme", Id: "1", Status: "SHIPPED", Items: 2}
+
+	input, err := dynabuf.UpdateItemInput("orders", order, &fieldmaskpb.FieldMask{Paths: []string{"status"}}, "customer", "id")
+	must.NoError(t, err)
+	must.Eq(t, "SET #u0 = :u0, #u1 = :u1", *input.UpdateExpression)
+	must.Eq(t, "sk", input.ExpressionAttributeNames["#u1"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "ORDER#SHIPPED##1"}, input.ExpressionAttributeValues[":u1"])
+
+	_, err = dynabuf.UpdateItemInput("orders", order, &fieldmaskpb.FieldMask{Paths: []string{"status"}}, "customer", "sk")
+	must.ErrorIs(t, err, dynabuf.ErrInvalidFieldMask)
+	must.StrContains(t, err.Error(), `field "status" is part of key attribute "sk"`)
+}
+
+func TestCompositeAttributesSpec(t *testing.T) {
+	spec := dynabuf.Spec((&testpb.Order{}).ProtoReflect().Descriptor())
+	attrs := spec.GetMessages()[0].GetAttributes()
+	must.Eq(t, "sk", attrs[6].GetName())
+	must.Eq(t, "ORDER#{status}#{create_time}#{id}", attrs[6].GetTemplate())
+	must.Eq(t, "S", attrs[6].GetValue().GetType())
+}
@@ -0,0 +1,295 @@
+package dynabuf
+
+import (
+	"cmp"
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// RetryPolicy is how requests are retried by a [Config].
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent, both by
+	// the DynamoDB client when it fails, and by the batch helpers while
+	// DynamoDB leaves some of its items unprocessed. Zero leaves the
+	// client's setting, and sends batches up to 8 times.
+	MaxAttempts int
+
+	// Backoff is the time waited before the first retry of a batch whose
+	// items were left unprocessed, which doubles after every retry, and
+	// defaults to 50ms.
+	Backoff time.Duration
+
+	// MaxBackoff, if set, is the maximum time waited before a retry, both
+	// by the DynamoDB client and by the batch helpers.
+	MaxBackoff time.Duration
+}
+
+// defaultBatchAttempts is the number of times a batch is sent while
+// DynamoDB leaves some of its items unprocessed, unless the retry policy
+// says otherwise.
+const defaultBatchAttempts = 8
+
+// defaultBackoff is the default of [RetryPolicy.Backoff].
+const defaultBackoff = 50 * time.Millisecond
+
+// Config configures how the stores, batch helpers, and table utilities of
+// this module send their requests to DynamoDB, so the retry policy,
+// timeouts, read consistency, and client options, such as capacity
+// accounting, are set once for all of them, along with the clock and
+// random source of their timestamps and random choices, so tests can be
+// deterministic. It also holds how the table utilities scanning a table
+// report and resume their progress. A nil Config uses the defaults.
+//
+// A Config is built with [NewConfig], and is safe for concurrent use.
+type Config struct {
+	retry           RetryPolicy
+	timeout         time.Duration
+	consistentReads *bool
+	clientOpts      []func(*dynamodb.Options)
+	clock           func() time.Time
+	rand            *rand.Rand
+	scan            scanOptions
+	truncate        truncateOptions
+}
+
+// ConfigOption configures a [Config]. The stores and table utilities of
+// this module take ConfigOption values, which extend the [Config] given to
+// them with [WithConfig] or [Table.Config].
+type ConfigOption func(*Config)
+
+// NewConfig returns the configuration built from opts.
+//
+// # Example
+//
+//	cfg := dynabuf.NewConfig(
+//	  dynabuf.WithRetryPolicy(dynabuf.RetryPolicy{MaxAttempts: 5, MaxBackoff: time.Second}),
+//	  dynabuf.WithTimeout(2*time.Second),
+//	  dynabuf.WithClientOptions(dynabuf.WithCapacityAccounting(capacity.Record)),
+//	)
+//
+//	users := dynabuf.Table{Client: client, Name: "users", Config: cfg}
+//
+//	locks := lock.New(client, "locks", time.Minute, dynabuf.WithConfig(cfg))
+func NewConfig(opts ...ConfigOption) *Config {
+	c := &Config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithConfig returns an option applying the options of cfg, so it can be
+// given to stores, or extended with other options.
+func WithConfig(cfg *Config) ConfigOption {
+	return func(c *Config) {
+		if cfg != nil {
+			*c = *cfg
+		}
+	}
+}
+
+// withConfig marshals with cfg, whose clock sets the write time of the
+// metadata.
+func withConfig(cfg *Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// with returns c extended with opts, leaving c unchanged.
+func (c *Config) with(opts []ConfigOption) *Config {
+	if len(opts) == 0 {
+		return c
+	}
+	return NewConfig(append([]ConfigOption{WithConfig(c)}, opts...)...)
+}
+
+// WithRetryPolicy retries requests with the given policy.
+func WithRetryPolicy(policy RetryPolicy) ConfigOption {
+	return func(c *Config) {
+		c.retry = policy
+	}
+}
+
+// WithTimeout gives up on each request to DynamoDB, including its retries
+// by the client, after d, in addition to the deadline of its context.
+// Batches of a batch write timing out are retried as if unprocessed.
+func WithTimeout(d time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.timeout = d
+	}
+}
+
+// WithConsistentReads sets whether the batch helpers and table utilities
+// read strongly consistent data, rather than each using its own default.
+// Stores always read strongly consistent data, as their conditional
+// writes rely on it.
+func WithConsistentReads(consistent bool) ConfigOption {
+	return func(c *Config) {
+		c.consistentReads = &consistent
+	}
+}
+
+// WithClientOptions applies fns to every request sent to DynamoDB, after
+// the options of the client, such as [WithCapacityAccounting] to account
+// for the capacity the requests consume, or [WithInterceptors].
+//
+// # Example
+//
+//	cfg := dynabuf.NewConfig(dynabuf.WithClientOptions(dynabuf.WithCapacityAccounting(capacity.Record)))
+func WithClientOptions(fns ...func(*dynamodb.Options)) ConfigOption {
+	return func(c *Config) {
+		c.clientOpts = slices.Concat(c.clientOpts, fns)
+	}
+}
+
+// WithClock reads the current time from now, rather than [time.Now], for
+// the timestamps written by stores, and the expirations and durations
+// measured by helpers. Timeouts and backoffs still wait in real time.
+//
+// # Example
+//
+//	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+//
+//	locks := lock.New(client, "locks", time.Minute, dynabuf.WithClock(func() time.Time { return start }))
+func WithClock(now func() time.Time) ConfigOption {
+	return func(c *Config) {
+		c.clock = now
+	}
+}
+
+// WithRand makes the random choices of helpers, such as sampling and the
+// items picked by load tests, with src, rather than the global source of
+// [math/rand/v2], so they are reproducible given a seeded source.
+//
+// # Example
+//
+//	cfg := dynabuf.NewConfig(dynabuf.WithRand(rand.NewPCG(1, 2)))
+func WithRand(src rand.Source) ConfigOption {
+	return func(c *Config) {
+		c.rand = rand.New(&lockedSource{src: src})
+	}
+}
+
+// Now returns the current time of the clock set with [WithClock], or
+// [time.Now].
+func (c *Config) Now() time.Time {
+	if c == nil || c.clock == nil {
+		return time.Now()
+	}
+	return c.clock()
+}
+
+// Rand returns the random number generator of the source set with
+// [WithRand], or of the global source of [math/rand/v2]. It is safe for
+// concurrent use.
+func (c *Config) Rand() *rand.Rand {
+	if c == nil || c.rand == nil {
+		return globalRand
+	}
+	return c.rand
+}
+
+// globalRand generates random numbers from the global source of
+// [math/rand/v2].
+var globalRand = rand.New(globalSource{})
+
+// globalSource is the global source of [math/rand/v2].
+type globalSource struct{}
+
+// Uint64 implements [rand.Source].
+func (globalSource) Uint64() uint64 {
+	return rand.Uint64()
+}
+
+// lockedSource is a source safe for concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+// Uint64 implements [rand.Source].
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+// ConsistentRead returns whether reads are strongly consistent, which is
+// def unless set with [WithConsistentReads].
+func (c *Config) ConsistentRead(def bool) *bool {
+	if c != nil && c.consistentReads != nil {
+		return aws.Bool(*c.consistentReads)
+	}
+	return aws.Bool(def)
+}
+
+// batchAttempts returns the number of times a batch is sent while DynamoDB
+// leaves some of its items unprocessed.
+func (c *Config) batchAttempts() int {
+	if c == nil || c.retry.MaxAttempts <= 0 {
+		return defaultBatchAttempts
+	}
+	return c.retry.MaxAttempts
+}
+
+// backoff returns the time waited before the nth retry of a batch,
+// starting from 1.
+func (c *Config) backoff(n int) time.Duration {
+	var policy RetryPolicy
+	if c != nil {
+		policy = c.retry
+	}
+
+	d := cmp.Or(policy.Backoff, defaultBackoff) << min(n-1, 30)
+	if policy.MaxBackoff > 0 && d > policy.MaxBackoff {
+		d = policy.MaxBackoff
+	}
+	return d
+}
+
+// clientOptions returns the options of the requests sent to DynamoDB.
+func (c *Config) clientOptions() []func(*dynamodb.Options) {
+	if c == nil {
+		return nil
+	}
+
+	var optFns []func(*dynamodb.Options)
+	if c.retry.MaxAttempts > 0 {
+		optFns = append(optFns, func(o *dynamodb.Options) {
+			o.RetryMaxAttempts = c.retry.MaxAttempts
+		})
+	}
+	if c.retry.MaxBackoff > 0 {
+		optFns = append(optFns, func(o *dynamodb.Options) {
+			o.Retryer = retry.AddWithMaxBackoffDelay(o.Retryer, c.retry.MaxBackoff)
+		})
+	}
+	return append(optFns, c.clientOpts...)
+}
+
+// Do sends a request to DynamoDB with op, a method of a client, applying
+// the timeout, retry policy, and client options of c, for stores and
+// helpers built on this module to share its configuration.
+//
+// # Example
+//
+//	out, err := dynabuf.Do(ctx, cfg, client.GetItem, &dynamodb.GetItemInput{
+//	  TableName: aws.String("users"),
+//	  Key:       key,
+//	})
+func Do[In, Out any](ctx context.Context, c *Config, op func(context.Context, In, ...func(*dynamodb.Options)) (Out, error), in In) (Out, error) {
+	if c != nil && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return op(ctx, in, c.clientOptions()...)
+}
@@ -0,0 +1,141 @@
+package dynabuf_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+// unprocessingClient leaves every request of batch writes unprocessed, and
+// records the consistency of batch gets.
+type unprocessingClient struct {
+	*dynamotest.Client
+
+	writes     atomic.Int64
+	consistent atomic.Bool
+}
+
+func (c *unprocessingClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	c.writes.Add(1)
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: params.RequestItems}, nil
+}
+
+func (c *unprocessingClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	for _, ka := range params.RequestItems {
+		c.consistent.Store(aws.ToBool(ka.ConsistentRead))
+	}
+	return c.Client.BatchGetItem(ctx, params, optFns...)
+}
+
+func TestConfigRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	client := &unprocessingClient{Client: newUsersTable(t)}
+	table := dynabuf.Table{
+		Client: client,
+		Name:   "users",
+		Config: dynabuf.NewConfig(dynabuf.WithRetryPolicy(dynabuf.RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     time.Millisecond,
+		})),
+	}
+
+	result, err := dynabuf.BatchPut(ctx, table, []*testpb.User{{Id: "1"}})
+	must.ErrorIs(t, err, dynabuf.ErrUnprocessedItems)
+	must.Eq(t, []int{0}, result.Unprocessed)
+	must.Eq(t, 3, client.writes.Load())
+}
+
+func TestConfigTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	// The first request hangs until the timeout of the configuration, as
+	// the context has no deadline, and is retried.
+	hanging := &hangingClient{Client: newUsersTable(t)}
+	hanging.hangs.Store(1)
+
+	table := dynabuf.Table{
+		Client: hanging,
+		Name:   "users",
+		Config: dynabuf.NewConfig(dynabuf.WithTimeout(50 * time.Millisecond)),
+	}
+
+	result, err := dynabuf.BatchPut(ctx, table, []*testpb.User{{Id: "1"}})
+	must.NoError(t, err)
+	must.Eq(t, []int{0}, result.Succeeded)
+}
+
+func TestConfigConsistentReads(t *testing.T) {
+	ctx := context.Background()
+
+	client := &unprocessingClient{Client: newUsersTable(t)}
+	keys := []map[string]types.AttributeValue{userKey("1")}
+
+	_, _, err := dynabuf.BatchGet[*testpb.User](ctx, dynabuf.Table{Client: client, Name: "users"}, keys)
+	must.NoError(t, err)
+	must.False(t, client.consistent.Load())
+
+	cfg := dynabuf.NewConfig(dynabuf.WithConsistentReads(true))
+	_, _, err = dynabuf.BatchGet[*testpb.User](ctx, dynabuf.Table{Client: client, Name: "users", Config: cfg}, keys)
+	must.NoError(t, err)
+	must.True(t, client.consistent.Load())
+}
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := dynabuf.NewConfig(
+		dynabuf.WithRetryPolicy(dynabuf.RetryPolicy{MaxAttempts: 5}),
+		dynabuf.WithTimeout(time.Minute),
+	)
+
+	getItem := func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+		_, ok := ctx.Deadline()
+		must.True(t, ok)
+
+		var o dynamodb.Options
+		for _, fn := range optFns {
+			fn(&o)
+		}
+		must.Eq(t, 5, o.RetryMaxAttempts)
+		return &dynamodb.GetItemOutput{}, nil
+	}
+
+	_, err := dynabuf.Do(ctx, cfg, getItem, &dynamodb.GetItemInput{TableName: aws.String("users")})
+	must.NoError(t, err)
+
+	// Options extend the configuration they start from.
+	extended := dynabuf.NewConfig(dynabuf.WithConfig(cfg), dynabuf.WithConsistentReads(true))
+	must.True(t, *extended.ConsistentRead(false))
+	_, err = dynabuf.Do(ctx, extended, getItem, &dynamodb.GetItemInput{TableName: aws.String("users")})
+	must.NoError(t, err)
+}
+
+func TestConfigRand(t *testing.T) {
+	draw := func(cfg *dynabuf.Config) []int {
+		var n []int
+		for range 10 {
+			n = append(n, cfg.Rand().IntN(1000))
+		}
+		return n
+	}
+
+	// Sources seeded alike make the same choices.
+	a := dynabuf.NewConfig(dynabuf.WithRand(rand.NewPCG(1, 2)))
+	b := dynabuf.NewConfig(dynabuf.WithRand(rand.NewPCG(1, 2)))
+	must.Eq(t, draw(a), draw(b))
+
+	// A nil configuration uses the global source.
+	var unset *dynabuf.Config
+	must.SliceLen(t, 10, draw(unset))
+}
@@ -0,0 +1,138 @@
+package dynabuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// protoJSON returns the JSON form of a message of type md, with the values
+// of fields stored in other forms, such as decimal fields and google.type
+// messages, converted back to their JSON form, attributes named after
+// aliases of fields renamed, and composite attributes removed, as protojson
+// expects.
+func protoJSON(data []byte, md protoreflect.MessageDescriptor) ([]byte, error) {
+	opts, _ := proto.GetExtension(md.Options(), dynabufpb.E_Message).(*dynabufpb.MessageOptions)
+	if len(opts.GetCompositeAttributes()) == 0 && !hasConvertedFields(md, map[protoreflect.FullName]bool{}) {
+		return data, nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var fields map[string]any
+	if err := d.Decode(&fields); err != nil {
+		return nil, err
+	}
+	resolveAliases(md, fields)
+	if err := decomposeAttributes(md, fields); err != nil {
+		return nil, err
+	}
+	if err := convertFields(md, fields, decodeValue); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// hasConvertedFields reports whether md or its nested messages have fields
+// stored in a form other than their JSON form, or read from aliases.
+func hasConvertedFields(md protoreflect.MessageDescriptor, seen map[protoreflect.FullName]bool) bool {
+	if seen[md.FullName()] {
+		return false
+	}
+	seen[md.FullName()] = true
+
+	fields := md.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if isDecimal(fd) || isSortable(fd) || len(fieldAliases(fd)) > 0 || googleTypeEncoding(fd) != dynabufpb.Encoding_ENCODING_UNSPECIFIED {
+			return true
+		}
+		if nested := fieldMessage(fd); nested != nil && hasConvertedFields(nested, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// convertFields replaces the scalar values of the fields of md in fields,
+// and of its nested messages, with the results of convert.
+func convertFields(md protoreflect.MessageDescriptor, fields map[string]any, convert func(protoreflect.FieldDescriptor, any) (any, error)) error {
+	for name, v := range fields {
+		fd := md.Fields().ByJSONName(name)
+		if fd == nil {
+			fd = md.Fields().ByName(protoreflect.Name(name))
+		}
+		if fd == nil {
+			continue
+		}
+
+		var err error
+		switch {
+		case fd.IsMap():
+			m, _ := v.(map[string]any)
+			for k, elem := range m {
+				if m[k], err = convertValue(fd.MapValue(), elem, convert); err != nil {
+					return err
+				}
+			}
+		case fd.IsList():
+			list, _ := v.([]any)
+			for i, elem := range list {
+				if list[i], err = convertValue(fd, elem, convert); err != nil {
+					return err
+				}
+			}
+		default:
+			if fields[name], err = convertValue(fd, v, convert); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// convertValue returns the conversion of a single value of fd, which is a
+// scalar, or a google.type message.
+func convertValue(fd protoreflect.FieldDescriptor, v any, convert func(protoreflect.FieldDescriptor, any) (any, error)) (any, error) {
+	md := fd.Message()
+	switch {
+	case md == nil:
+		return convert(fd, v)
+	case md.FullName() == "google.protobuf.DoubleValue", md.FullName() == "google.protobuf.FloatValue":
+		// Wrappers are stored as their value.
+		return convert(md.Fields().ByName("value"), v)
+	case strings.HasPrefix(string(md.FullName()), "google.protobuf."),
+		strings.HasPrefix(string(md.FullName()), "google.type."):
+		// Well-known types are stored as scalars, or converted as a
+		// whole.
+		return convert(fd, v)
+	}
+
+	if m, ok := v.(map[string]any); ok {
+		return v, convertFields(md, m, convert)
+	}
+	return v, nil
+}
+
+// convertNumbers replaces the numbers of a decoded value, of type From,
+// with numbers of type To.
+func convertNumbers[From, To ~string](v any) any {
+	switch v := v.(type) {
+	case From:
+		return To(v)
+	case map[string]any:
+		for k, elem := range v {
+			v[k] = convertNumbers[From, To](elem)
+		}
+	case []any:
+		for i, elem := range v {
+			v[i] = convertNumbers[From, To](elem)
+		}
+	}
+	return v
+}
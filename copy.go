@@ -0,0 +1,115 @@
+package dynabuf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// CopyProgress reports the progress of a [Copy].
+type CopyProgress struct {
+	// Scanned is the number of items read from the source table.
+	Scanned int
+
+	// Written is the number of items written to the destination table.
+	Written int
+
+	// ResumeKey is the key to pass to [WithCopyResumeKey] to continue the
+	// copy after the items copied so far. It is nil once the copy is done.
+	ResumeKey map[string]types.AttributeValue
+}
+
+// WithCopyProgress calls fn after every page of items is copied.
+func WithCopyProgress(fn func(CopyProgress)) ConfigOption {
+	return func(c *Config) {
+		c.scan.copyProgress = fn
+	}
+}
+
+// WithCopyResumeKey continues a copy after the given key, as reported
+// by [CopyProgress.ResumeKey]. It is ignored if [WithResumeToken] is given
+// a token.
+func WithCopyResumeKey(key map[string]types.AttributeValue) ConfigOption {
+	return func(c *Config) {
+		c.scan.resumeKey = key
+	}
+}
+
+// Copy scans every item of the src table, decodes it as T, and writes the
+// message returned by transform to the dst table, which may be in another
+// account or region. The transform may return a different message type,
+// such as when moving items to a new schema, and returning a nil message
+// skips the item.
+//
+// Items are written in batches, one page of scanned items at a time. If the
+// copy fails or is interrupted, it can be continued from the last reported
+// [Progress.Token] with [WithResumeToken], or [CopyProgress.ResumeKey] with
+// [WithCopyResumeKey]. Items of the page that was being copied are written
+// again, so transforms should be deterministic. Copies can be split between
+// workers with [WithSegment].
+// Give dst a [Table.WriteLimiter] to leave capacity to the traffic of a
+// provisioned table. The options extend the [Table.Config] of src.
+//
+// # Example
+//
+//	src := dynabuf.Table{Client: client, Name: "users"}
+//	dst := dynabuf.Table{Client: client, Name: "users-v2"}
+//
+//	err := dynabuf.Copy(ctx, src, dst, func(user *example.User) (*examplev2.User, error) {
+//	  return &examplev2.User{Id: user.GetId(), DisplayName: user.GetName()}, nil
+//	}, dynabuf.WithProgress(func(p dynabuf.Progress) {
+//	  log.Printf("copied %d of %d items, %s left, resume with %s", p.Processed, p.Scanned, p.ETA, p.Token)
+//	}))
+func Copy[T, U proto.Message](ctx context.Context, src, dst Table, transform func(T) (U, error), opts ...ConfigOption) error {
+	var (
+		zeroT T
+		zeroU U
+	)
+
+	src.Config = src.Config.with(opts)
+	tracker, err := newProgressTracker(ctx, src)
+	if err != nil {
+		return err
+	}
+	if tracker.done {
+		return nil
+	}
+
+	srcCtx := ContextWithMessageType(ctx, zeroT.ProtoReflect().Interface())
+	dstCtx := ContextWithMessageType(ctx, zeroU.ProtoReflect().Interface())
+
+	return src.scanSegment(srcCtx, tracker.startKey, tracker.o.segment, tracker.o.totalSegments, func(items []map[string]types.AttributeValue, next map[string]types.AttributeValue) error {
+		requests := make([]types.WriteRequest, 0, len(items))
+
+		for _, item := range items {
+			msg := zeroT.ProtoReflect().New().Interface().(T)
+			if err := Unmarshal(item, msg); err != nil {
+				return fmt.Errorf("dynabuf: failed to copy item: %w", err)
+			}
+
+			out, err := transform(msg)
+			if err != nil {
+				return fmt.Errorf("dynabuf: failed to transform item: %w", err)
+			}
+			if any(out) == nil || !out.ProtoReflect().IsValid() {
+				continue
+			}
+
+			av, err := marshalProtoMessage(out)
+			if err != nil {
+				return err
+			}
+			requests = append(requests, types.WriteRequest{
+				PutRequest: &types.PutRequest{Item: av},
+			})
+		}
+
+		if _, err := dst.batchWrite(dstCtx, requests); err != nil {
+			return err
+		}
+
+		return tracker.page(ctx, items, len(requests), next)
+	})
+}
@@ -0,0 +1,90 @@
+package dynabuf_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+
+	client := dynamotest.NewClient()
+	for _, name := range []string{"users", "jobs"} {
+		_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+			TableName: aws.String(name),
+			KeySchema: []types.KeySchemaElement{
+				{
+					AttributeName: aws.String("id"),
+					KeyType:       types.KeyTypeHash,
+				},
+			},
+		})
+		must.NoError(t, err)
+	}
+
+	for _, user := range []*testpb.User{
+		{Id: "1", Name: "Alice", Age: 30},
+		{Id: "2", Name: "Bob"},
+		{Id: "3", Name: "Carol", Age: 40},
+	} {
+		item, err := dynabuf.Marshal(user)
+		must.NoError(t, err)
+
+		_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String("users"),
+			Item:      item.(map[string]types.AttributeValue),
+		})
+		must.NoError(t, err)
+	}
+
+	src := dynabuf.Table{Client: client, Name: "users"}
+	dst := dynabuf.Table{Client: client, Name: "jobs"}
+
+	// Users without an age are skipped.
+	transform := func(user *testpb.User) (*testpb.Job, error) {
+		if user.GetAge() == 0 {
+			return nil, nil
+		}
+		return &testpb.Job{Id: user.GetId(), State: testpb.Job_STATE_RUNNING}, nil
+	}
+
+	var progress []dynabuf.CopyProgress
+	err := dynabuf.Copy(ctx, src, dst, transform, dynabuf.WithCopyProgress(func(p dynabuf.CopyProgress) {
+		progress = append(progress, p)
+	}))
+	must.NoError(t, err)
+
+	must.SliceLen(t, 1, progress)
+	must.Eq(t, 3, progress[0].Scanned)
+	must.Eq(t, 2, progress[0].Written)
+	must.Nil(t, progress[0].ResumeKey)
+
+	out, err := client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("jobs")})
+	must.NoError(t, err)
+
+	var jobs []*testpb.Job
+	must.NoError(t, dynabuf.Unmarshal(out.Items, &jobs))
+	must.SliceLen(t, 2, jobs)
+	must.Eq(t, "1", jobs[0].GetId())
+	must.Eq(t, testpb.Job_STATE_RUNNING, jobs[0].GetState())
+	must.Eq(t, "3", jobs[1].GetId())
+
+	// Resuming continues after the given key.
+	var written int
+	err = dynabuf.Copy(ctx, src, dst, func(user *testpb.User) (*testpb.Job, error) {
+		written++
+		return &testpb.Job{Id: user.GetId()}, nil
+	}, dynabuf.WithCopyResumeKey(map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberS{Value: "2"},
+	}))
+	must.NoError(t, err)
+	must.Eq(t, 1, written)
+}
@@ -0,0 +1,34 @@
+package dynabuf
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DeadLetterSink receives the messages a batch write gave up on, with the
+// error they failed with, so they can be inspected and replayed later
+// instead of being lost. The deadletter package provides a sink sending
+// them to an SQS queue.
+type DeadLetterSink interface {
+	DeadLetter(ctx context.Context, msg proto.Message, err error) error
+}
+
+// DeadLetterFunc adapts a function to a [DeadLetterSink].
+//
+// # Example
+//
+//	table := dynabuf.Table{
+//	  Client: client,
+//	  Name:   "users",
+//	  DeadLetter: dynabuf.DeadLetterFunc(func(ctx context.Context, msg proto.Message, err error) error {
+//	    log.Printf("giving up on %v: %v", msg, err)
+//	    return nil
+//	  }),
+//	}
+type DeadLetterFunc func(ctx context.Context, msg proto.Message, err error) error
+
+// DeadLetter calls fn.
+func (fn DeadLetterFunc) DeadLetter(ctx context.Context, msg proto.Message, err error) error {
+	return fn(ctx, msg, err)
+}
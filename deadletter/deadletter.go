@@ -0,0 +1,113 @@
+// Package deadletter provides [dynabuf.DeadLetterSink] implementations,
+// keeping the messages batch writes give up on so they can be inspected
+// and replayed.
+//
+// # Example
+//
+//	table := dynabuf.Table{
+//	  Client:     dynamodb.NewFromConfig(cfg),
+//	  Name:       "users",
+//	  DeadLetter: &deadletter.SQS{Client: sqs.NewFromConfig(cfg), QueueURL: queueURL},
+//	}
+//
+//	result, err := dynabuf.BatchPut(ctx, table, users)
+//
+// It is a separate module, github.com/picatz/dynabuf/deadletter, so that
+// programs not keeping dead letters do not depend on the SQS client.
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/picatz/dynabuf"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// SQSAPIClient is the subset of the SQS API used by [SQS].
+type SQSAPIClient interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// Letter is the body of the SQS messages sent by [SQS], encoded as JSON.
+type Letter struct {
+	// Type is the full name of the message type, such as "example.User".
+	Type string `json:"type"`
+
+	// Error is the text of the error the message failed with.
+	Error string `json:"error"`
+
+	// Message is the message, encoded with protojson.
+	Message json.RawMessage `json:"message"`
+}
+
+// Decode decodes the message of the letter into msg, which must be of the
+// letter's type.
+func (l *Letter) Decode(msg proto.Message) error {
+	if name := string(msg.ProtoReflect().Descriptor().FullName()); name != l.Type {
+		return fmt.Errorf("deadletter: letter holds a %s, not a %s", l.Type, name)
+	}
+	if err := protojson.Unmarshal(l.Message, msg); err != nil {
+		return fmt.Errorf("deadletter: failed to decode %s: %w", l.Type, err)
+	}
+	return nil
+}
+
+// SQS is a [dynabuf.DeadLetterSink] sending every message to an SQS queue,
+// as a [Letter], with a "type" message attribute holding the message type,
+// so consumers can filter letters without decoding them.
+type SQS struct {
+	// Client is the SQS client of the queue's account and region.
+	Client SQSAPIClient
+
+	// QueueURL is the URL of the queue.
+	QueueURL string
+
+	// MessageGroupID is the message group of the letters, which must be set
+	// for FIFO queues, and their content-based deduplication enabled.
+	MessageGroupID string
+}
+
+var _ dynabuf.DeadLetterSink = (*SQS)(nil)
+
+// DeadLetter sends msg and err to the queue.
+func (s *SQS) DeadLetter(ctx context.Context, msg proto.Message, err error) error {
+	b, merr := protojson.Marshal(msg)
+	if merr != nil {
+		return fmt.Errorf("deadletter: failed to encode message: %w", merr)
+	}
+
+	letter := Letter{
+		Type:    string(msg.ProtoReflect().Descriptor().FullName()),
+		Error:   err.Error(),
+		Message: b,
+	}
+	body, merr := json.Marshal(letter)
+	if merr != nil {
+		return fmt.Errorf("deadletter: failed to encode letter: %w", merr)
+	}
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(s.QueueURL),
+		MessageBody: aws.String(string(body)),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"type": {
+				DataType:    aws.String("String"),
+				StringValue: aws.String(letter.Type),
+			},
+		},
+	}
+	if s.MessageGroupID != "" {
+		input.MessageGroupId = aws.String(s.MessageGroupID)
+	}
+
+	if _, err := s.Client.SendMessage(ctx, input); err != nil {
+		return fmt.Errorf("deadletter: failed to send message to queue %q: %w", s.QueueURL, err)
+	}
+	return nil
+}
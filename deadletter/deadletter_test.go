@@ -0,0 +1,92 @@
+package deadletter_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/deadletter"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeSQS records the messages sent.
+type fakeSQS struct {
+	sent []*sqs.SendMessageInput
+	err  error
+}
+
+func (c *fakeSQS) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.sent = append(c.sent, params)
+	return &sqs.SendMessageOutput{MessageId: aws.String("1")}, nil
+}
+
+// failingClient fails every batch write.
+type failingClient struct {
+	*dynamotest.Client
+}
+
+func (c failingClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	return nil, errors.New("internal server error")
+}
+
+func TestSQS(t *testing.T) {
+	ctx := context.Background()
+
+	queue := &fakeSQS{}
+	table := dynabuf.Table{
+		Client:     failingClient{dynamotest.NewClient()},
+		Name:       "users",
+		DeadLetter: &deadletter.SQS{Client: queue, QueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/users-dlq"},
+	}
+
+	users := []*testpb.User{
+		{Id: "1", Name: "Alice"},
+		{Id: "2", Name: "Bob"},
+	}
+	result, err := dynabuf.BatchPut(ctx, table, users)
+	must.ErrorContains(t, err, "internal server error")
+	must.MapLen(t, 2, result.Failed)
+	must.Eq(t, []int{0, 1}, result.DeadLettered)
+	must.SliceLen(t, 2, queue.sent)
+
+	for i, input := range queue.sent {
+		must.Eq(t, "https://sqs.us-east-1.amazonaws.com/123456789012/users-dlq", aws.ToString(input.QueueUrl))
+		must.Eq(t, "dynabuf.test.v1.User", aws.ToString(input.MessageAttributes["type"].StringValue))
+		must.Nil(t, input.MessageGroupId)
+
+		var letter deadletter.Letter
+		must.NoError(t, json.Unmarshal([]byte(aws.ToString(input.MessageBody)), &letter))
+		must.Eq(t, "dynabuf.test.v1.User", letter.Type)
+		must.StrContains(t, letter.Error, "internal server error")
+
+		user := &testpb.User{}
+		must.NoError(t, letter.Decode(user))
+		must.True(t, proto.Equal(users[i], user))
+
+		must.ErrorContains(t, letter.Decode(&testpb.Job{}), "not a")
+	}
+}
+
+func TestSQSError(t *testing.T) {
+	queue := &fakeSQS{err: errors.New("access denied")}
+	sink := &deadletter.SQS{Client: queue, QueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/users-dlq.fifo", MessageGroupID: "users"}
+
+	err := sink.DeadLetter(context.Background(), &testpb.User{Id: "1"}, errors.New("rejected"))
+	must.ErrorContains(t, err, "access denied")
+	must.SliceEmpty(t, queue.sent)
+
+	queue.err = nil
+	must.NoError(t, sink.DeadLetter(context.Background(), &testpb.User{Id: "1"}, errors.New("rejected")))
+	must.Eq(t, "users", aws.ToString(queue.sent[0].MessageGroupId))
+}
@@ -0,0 +1,106 @@
+package dynabuf
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/internal/avtext"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DebugString returns a human readable representation of an item decoded as
+// a message of type mt, for logs and test failures. The message is rendered
+// in the protobuf text format, followed by a comment for each attribute that
+// is not a field of the message, including those of nested messages, which
+// [Unmarshal] would reject.
+//
+// If the item cannot be decoded, the error is followed by a comment for each
+// attribute instead. The output is not stable and must not be parsed.
+//
+// # Example
+//
+//	fmt.Println(dynabuf.DebugString(out.Item, (*example.User)(nil).ProtoReflect().Type()))
+//
+// Prints:
+//
+//	id: "123"
+//	address: {
+//	  city: "Springfield"
+//	}
+//	# unknown attribute "address.zip": N:12345
+func DebugString(item map[string]types.AttributeValue, mt protoreflect.MessageType) string {
+	var b strings.Builder
+
+	msg := mt.New().Interface()
+	if err := Unmarshal(item, msg, WithDiscardUnknown()); err != nil {
+		fmt.Fprintf(&b, "# failed to decode as %s: %v\n", mt.Descriptor().FullName(), err)
+		for _, name := range slices.Sorted(maps.Keys(item)) {
+			fmt.Fprintf(&b, "# attribute %q: %s\n", name, avtext.Format(item[name]))
+		}
+		return b.String()
+	}
+
+	b.WriteString(prototext.MarshalOptions{Multiline: true}.Format(msg))
+	for _, unknown := range unknownAttributes("", item, mt.Descriptor()) {
+		fmt.Fprintf(&b, "# unknown attribute %q: %s\n", unknown.path, avtext.Format(unknown.value))
+	}
+
+	return b.String()
+}
+
+// unknownAttribute is an attribute that is not a field of its message.
+type unknownAttribute struct {
+	path  string
+	value types.AttributeValue
+}
+
+// unknownAttributes returns the attributes of an item, or of a nested
+// message's map attribute, that are not fields of md, sorted by name.
+func unknownAttributes(prefix string, item map[string]types.AttributeValue, md protoreflect.MessageDescriptor) []unknownAttribute {
+	var unknown []unknownAttribute
+
+	for _, name := range slices.Sorted(maps.Keys(item)) {
+		path := prefix + name
+
+		fd := aliasedField(md, name)
+		if fd == nil {
+			unknown = append(unknown, unknownAttribute{path: path, value: item[name]})
+			continue
+		}
+
+		// Only messages stored as attributes of their fields can have
+		// unknown attributes; well-known types have their own encoding.
+		nested := fd.Message()
+		if fd.IsMap() {
+			nested = fd.MapValue().Message()
+		}
+		if nested == nil || nested.ParentFile().Package() == "google.protobuf" {
+			continue
+		}
+
+		switch av := item[name].(type) {
+		case *types.AttributeValueMemberM:
+			if !fd.IsMap() {
+				unknown = append(unknown, unknownAttributes(path+".", av.Value, nested)...)
+				break
+			}
+			for _, key := range slices.Sorted(maps.Keys(av.Value)) {
+				if m, ok := av.Value[key].(*types.AttributeValueMemberM); ok {
+					unknown = append(unknown, unknownAttributes(fmt.Sprintf("%s[%q].", path, key), m.Value, nested)...)
+				}
+			}
+		case *types.AttributeValueMemberL:
+			for i, elem := range av.Value {
+				if m, ok := elem.(*types.AttributeValueMemberM); ok {
+					unknown = append(unknown, unknownAttributes(fmt.Sprintf("%s[%d].", path, i), m.Value, nested)...)
+				}
+			}
+		}
+	}
+
+	return unknown
+}
@@ -0,0 +1,51 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestDebugString(t *testing.T) {
+	mt := (*testpb.User)(nil).ProtoReflect().Type()
+
+	item := map[string]types.AttributeValue{
+		"id":   &types.AttributeValueMemberS{Value: "1"},
+		"name": &types.AttributeValueMemberS{Value: "Alice"},
+		"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"street": &types.AttributeValueMemberS{Value: "Main St"},
+			"floor":  &types.AttributeValueMemberN{Value: "3"},
+		}},
+		"previousAddresses": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"street": &types.AttributeValueMemberS{Value: "Old St"},
+				"tags":   &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+			}},
+		}},
+		"legacy": &types.AttributeValueMemberBOOL{Value: true},
+	}
+
+	s := dynabuf.DebugString(item, mt)
+	must.StrContains(t, s, `"Alice"`)
+	must.StrContains(t, s, `"Main St"`)
+	must.StrContains(t, s, `"Old St"`)
+	must.StrContains(t, s, `# unknown attribute "address.floor": N:3`)
+	must.StrContains(t, s, `# unknown attribute "legacy": BOOL:true`)
+	must.StrContains(t, s, `# unknown attribute "previousAddresses[0].tags": SS:["a", "b"]`)
+
+	s = dynabuf.DebugString(map[string]types.AttributeValue{
+		"age": &types.AttributeValueMemberS{Value: "old"},
+		"tags": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"b": &types.AttributeValueMemberNULL{Value: true},
+			"a": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberB{Value: []byte("hi")},
+			}},
+		}},
+	}, mt)
+	must.StrContains(t, s, "# failed to decode as dynabuf.test.v1.User")
+	must.StrContains(t, s, `# attribute "age": S:"old"`)
+	must.StrContains(t, s, `# attribute "tags": M:{"a": L:[B:aGk=], "b": NULL:true}`)
+}
@@ -0,0 +1,172 @@
+package dynabuf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// CanonicalHash returns a hex encoded SHA-256 hash of the content of msg,
+// ignoring fields annotated as volatile:
+//
+//	string id = 1 [(dynabuf.v1.field) = { volatile: true }];
+//
+// Messages with equal non-volatile fields have the same hash within the
+// same binary. Hashes are not guaranteed to be stable across protobuf
+// library versions, so they should not be stored.
+func CanonicalHash(msg proto.Message) (string, error) {
+	content := proto.Clone(msg)
+	clearVolatile(content.ProtoReflect())
+
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("dynabuf: failed to hash message: %w", err)
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// clearVolatile clears the volatile fields of msg and its nested messages.
+func clearVolatile(msg protoreflect.Message) {
+	var volatile []protoreflect.FieldDescriptor
+
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case isVolatile(fd):
+			volatile = append(volatile, fd)
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := range list.Len() {
+				clearVolatile(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				clearVolatile(v.Message())
+				return true
+			})
+		case fd.Message() != nil && !fd.IsMap():
+			clearVolatile(v.Message())
+		}
+		return true
+	})
+
+	for _, fd := range volatile {
+		msg.Clear(fd)
+	}
+}
+
+// isVolatile reports whether the field is annotated as volatile.
+func isVolatile(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := proto.GetExtension(fd.Options(), dynabufpb.E_Field).(*dynabufpb.FieldOptions)
+	return ok && opts.GetVolatile()
+}
+
+// Duplicates is a group of items in a table with the same content, as
+// found by [FindDuplicates].
+type Duplicates struct {
+	// Hash is the [CanonicalHash] of the items.
+	Hash string
+
+	// Keep is the key of the first item found with this content.
+	Keep map[string]types.AttributeValue
+
+	// Remove are the keys of the other items with the same content.
+	Remove []map[string]types.AttributeValue
+}
+
+// FindDuplicates scans the table, decoding every item as T, and returns the
+// groups of items whose messages have the same [CanonicalHash]. Fields that
+// differ between otherwise duplicate items, such as generated identifiers
+// and timestamps, must be annotated as volatile to be ignored.
+//
+// The keys are the names of the table's key attributes, which are used to
+// identify the items of each group.
+//
+// The hashes of every item are kept in memory while scanning.
+//
+// # Example
+//
+//	table := dynabuf.Table{Client: client, Name: "users"}
+//
+//	dups, _ := dynabuf.FindDuplicates[*example.User](ctx, table, []string{"id"})
+//
+//	removed, _ := dynabuf.RemoveDuplicates(ctx, table, dups)
+func FindDuplicates[T proto.Message](ctx context.Context, table Table, keys []string) ([]Duplicates, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("dynabuf: finding duplicates requires the table's key attributes")
+	}
+
+	var (
+		zero   T
+		groups = map[string]int{}
+		dups   []Duplicates
+	)
+
+	ctx = ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	err := table.scanPages(ctx, nil, func(items []map[string]types.AttributeValue, _ map[string]types.AttributeValue) error {
+		for _, item := range items {
+			msg := zero.ProtoReflect().New().Interface().(T)
+			if err := Unmarshal(item, msg, WithDiscardUnknown()); err != nil {
+				return fmt.Errorf("dynabuf: failed to check item for duplicates: %w", err)
+			}
+
+			hash, err := CanonicalHash(msg)
+			if err != nil {
+				return err
+			}
+
+			key, err := itemKey(item, keys)
+			if err != nil {
+				return err
+			}
+
+			idx, ok := groups[hash]
+			if !ok {
+				groups[hash] = len(dups)
+				dups = append(dups, Duplicates{Hash: hash, Keep: key})
+				continue
+			}
+			dups[idx].Remove = append(dups[idx].Remove, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := dups[:0]
+	for _, d := range dups {
+		if len(d.Remove) > 0 {
+			out = append(out, d)
+		}
+	}
+
+	return out, nil
+}
+
+// RemoveDuplicates deletes the items to remove of every group found by
+// [FindDuplicates], keeping one item of each, and returns the number of
+// items deleted, including when it fails part way. If the deadline of ctx
+// is reached first, the error is a [BatchDeadlineError] holding the delete
+// requests left.
+func RemoveDuplicates(ctx context.Context, table Table, dups []Duplicates) (int, error) {
+	var requests []types.WriteRequest
+	for _, d := range dups {
+		for _, key := range d.Remove {
+			requests = append(requests, types.WriteRequest{
+				DeleteRequest: &types.DeleteRequest{Key: key},
+			})
+		}
+	}
+
+	result, err := table.batchWrite(ctx, requests)
+	return len(result.Succeeded), err
+}
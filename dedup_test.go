@@ -0,0 +1,98 @@
+package dynabuf_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCanonicalHash(t *testing.T) {
+	a := &testpb.User{
+		Id:         "1",
+		Name:       "Alice",
+		Address:    &testpb.Address{Street: "Main St"},
+		CreateTime: timestamppb.New(time.Unix(1, 0)),
+	}
+	b := &testpb.User{
+		Id:         "2",
+		Name:       "Alice",
+		Address:    &testpb.Address{Street: "Main St"},
+		CreateTime: timestamppb.New(time.Unix(2, 0)),
+	}
+
+	ha, err := dynabuf.CanonicalHash(a)
+	must.NoError(t, err)
+
+	hb, err := dynabuf.CanonicalHash(b)
+	must.NoError(t, err)
+	must.Eq(t, ha, hb)
+
+	// The message itself is not modified.
+	must.Eq(t, "1", a.GetId())
+
+	b.Address.Street = "Side St"
+	hb, err = dynabuf.CanonicalHash(b)
+	must.NoError(t, err)
+	must.NotEq(t, ha, hb)
+}
+
+func TestDuplicates(t *testing.T) {
+	ctx := context.Background()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("users"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	for _, user := range []*testpb.User{
+		{Id: "1", Name: "Alice"},
+		{Id: "2", Name: "Bob"},
+		{Id: "3", Name: "Alice"},
+		{Id: "4", Name: "Alice"},
+	} {
+		item, err := dynabuf.Marshal(user)
+		must.NoError(t, err)
+
+		_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String("users"),
+			Item:      item.(map[string]types.AttributeValue),
+		})
+		must.NoError(t, err)
+	}
+
+	table := dynabuf.Table{Client: client, Name: "users"}
+
+	dups, err := dynabuf.FindDuplicates[*testpb.User](ctx, table, []string{"id"})
+	must.NoError(t, err)
+	must.SliceLen(t, 1, dups)
+	must.Eq(t, "1", dups[0].Keep["id"].(*types.AttributeValueMemberS).Value)
+	must.SliceLen(t, 2, dups[0].Remove)
+
+	removed, err := dynabuf.RemoveDuplicates(ctx, table, dups)
+	must.NoError(t, err)
+	must.Eq(t, 2, removed)
+
+	out, err := client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("users")})
+	must.NoError(t, err)
+	must.SliceLen(t, 2, out.Items)
+
+	dups, err = dynabuf.FindDuplicates[*testpb.User](ctx, table, []string{"id"})
+	must.NoError(t, err)
+	must.SliceEmpty(t, dups)
+}
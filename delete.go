@@ -0,0 +1,115 @@
+package dynabuf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// DeleteWhere deletes the items of the table selected by the query or scan
+// input, such as the items of a partition, or the items a filter expression
+// matches, and returns the number of items deleted, including when it fails
+// part way.
+//
+// The items are read one page at a time, and the keys of each page deleted
+// in batches, retrying keys DynamoDB leaves unprocessed, before the next
+// page is read. The keys are the attributes named by the key_attributes
+// annotation of T, which the projection of input, if any, must include:
+//
+//	option (dynabuf.v1.message) = {
+//	  key_attributes: ["customer", "sk"]
+//	};
+//
+// The table name of input is replaced by the name of the table. If the
+// deadline of ctx is reached first, the error is a [BatchDeadlineError]
+// holding the delete requests left of the page being deleted, and deleting
+// can be continued by calling DeleteWhere again.
+//
+// # Example
+//
+//	table := dynabuf.Table{Client: client, Name: "orders"}
+//
+//	deleted, err := dynabuf.DeleteWhere[*example.Order](ctx, table, &dynamodb.QueryInput{
+//	  KeyConditionExpression: aws.String("customer = :customer"),
+//	  ExpressionAttributeValues: map[string]types.AttributeValue{
+//	    ":customer": &types.AttributeValueMemberS{Value: "acme"},
+//	  },
+//	})
+func DeleteWhere[T proto.Message, In *dynamodb.QueryInput | *dynamodb.ScanInput](ctx context.Context, table Table, input In) (int, error) {
+	var zero T
+	md := zero.ProtoReflect().Descriptor()
+
+	names := keyAttributes(md)
+	if len(names) == 0 {
+		return 0, fmt.Errorf("dynabuf: deleting items of %s requires its key attributes, annotated with key_attributes", md.FullName())
+	}
+
+	ctx = ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	var deleted int
+	err := table.selectPages(ctx, input, func(items []map[string]types.AttributeValue) error {
+		requests := make([]types.WriteRequest, len(items))
+		for i, item := range items {
+			key, err := itemKey(item, names)
+			if err != nil {
+				return fmt.Errorf("dynabuf: failed to delete item: %w", err)
+			}
+			requests[i] = types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}}
+		}
+
+		result, err := table.batchWrite(ctx, requests)
+		deleted += len(result.Succeeded)
+		return err
+	})
+	return deleted, err
+}
+
+// selectPages calls fn with every page of the items of the table selected
+// by the query or scan input, until the last page or fn returns an error.
+func (t Table) selectPages(ctx context.Context, input any, fn func(items []map[string]types.AttributeValue) error) error {
+	switch input := input.(type) {
+	case *dynamodb.QueryInput:
+		if input == nil {
+			return fmt.Errorf("dynabuf: query of table %q is nil", t.Name)
+		}
+		query := *input
+		query.TableName = aws.String(t.Name)
+		for {
+			out, err := Do(ctx, t.Config, t.Client.Query, &query)
+			if err != nil {
+				return fmt.Errorf("dynabuf: failed to query table %q: %w", t.Name, err)
+			}
+			if err := fn(out.Items); err != nil {
+				return err
+			}
+			if len(out.LastEvaluatedKey) == 0 {
+				return nil
+			}
+			query.ExclusiveStartKey = out.LastEvaluatedKey
+		}
+	case *dynamodb.ScanInput:
+		if input == nil {
+			return fmt.Errorf("dynabuf: scan of table %q is nil", t.Name)
+		}
+		scan := *input
+		scan.TableName = aws.String(t.Name)
+		for {
+			out, err := Do(ctx, t.Config, t.Client.Scan, &scan)
+			if err != nil {
+				return fmt.Errorf("dynabuf: failed to scan table %q: %w", t.Name, err)
+			}
+			if err := fn(out.Items); err != nil {
+				return err
+			}
+			if len(out.LastEvaluatedKey) == 0 {
+				return nil
+			}
+			scan.ExclusiveStartKey = out.LastEvaluatedKey
+		}
+	}
+	return fmt.Errorf("dynabuf: unsupported input %T", input)
+}
@@ -0,0 +1,80 @@
+package dynabuf_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestDeleteWhere(t *testing.T) {
+	ctx := context.Background()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("orders"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("customer"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("sk"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	var orders []*testpb.Order
+	for i := range 5 {
+		orders = append(orders, &testpb.Order{Customer: "acme", Id: fmt.Sprint(i), Status: "OPEN"})
+	}
+	orders = append(orders,
+		&testpb.Order{Customer: "globex", Id: "1", Status: "OPEN"},
+		&testpb.Order{Customer: "globex", Id: "2", Status: "SHIPPED"},
+	)
+
+	table := dynabuf.Table{Client: client, Name: "orders"}
+	_, err = dynabuf.BatchPut(ctx, table, orders)
+	must.NoError(t, err)
+
+	// The items of the partition are deleted a page at a time.
+	deleted, err := dynabuf.DeleteWhere[*testpb.Order](ctx, table, &dynamodb.QueryInput{
+		KeyConditionExpression: aws.String("customer = :customer"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":customer": &types.AttributeValueMemberS{Value: "acme"},
+		},
+		Limit: aws.Int32(2),
+	})
+	must.NoError(t, err)
+	must.Eq(t, 5, deleted)
+
+	deleted, err = dynabuf.DeleteWhere[*testpb.Order](ctx, table, &dynamodb.ScanInput{
+		FilterExpression: aws.String("#status = :status"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: "SHIPPED"},
+		},
+	})
+	must.NoError(t, err)
+	must.Eq(t, 1, deleted)
+
+	out, err := client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("orders")})
+	must.NoError(t, err)
+	must.SliceLen(t, 1, out.Items)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "1"}, out.Items[0]["id"])
+
+	// Messages without annotated key attributes cannot be deleted.
+	_, err = dynabuf.DeleteWhere[*testpb.User](ctx, table, &dynamodb.ScanInput{})
+	must.ErrorContains(t, err, "requires its key attributes")
+}
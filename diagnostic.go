@@ -0,0 +1,336 @@
+package dynabuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DiagnosticKind is the kind of issue reported by a [Diagnostic].
+type DiagnosticKind int
+
+const (
+	// DiagnosticPrecisionLoss reports a number not stored or read exactly,
+	// such as a float rounded by [WithFloatFormat], or a number attribute
+	// with more digits than a double holds, such as a 64-bit integer, read
+	// into a double field or a google.protobuf.Value.
+	DiagnosticPrecisionLoss DiagnosticKind = iota
+
+	// DiagnosticFloatSpecial reports a NaN or infinite float stored as a
+	// string, which condition and filter expressions do not compare as a
+	// number.
+	DiagnosticFloatSpecial
+
+	// DiagnosticDroppedNull reports a NULL attribute read as an unset
+	// field, so writing the message back removes the attribute.
+	DiagnosticDroppedNull
+
+	// DiagnosticDiscardedAttribute reports an attribute that is not a field
+	// of the message, ignored because of [WithDiscardUnknown], so writing
+	// the message back removes the attribute.
+	DiagnosticDiscardedAttribute
+
+	// DiagnosticNameCollision reports an attribute named after the proto
+	// name of a field rather than its JSON name, which [Marshal] writes, so
+	// the item can end up with both, and expressions using one name miss
+	// the values stored under the other.
+	DiagnosticNameCollision
+
+	// DiagnosticCaseCollision reports attributes whose names differ only
+	// by case, found by [WithCaseCollisions].
+	DiagnosticCaseCollision
+)
+
+// String returns the name of the kind, such as "precision loss".
+func (k DiagnosticKind) String() string {
+	switch k {
+	case DiagnosticPrecisionLoss:
+		return "precision loss"
+	case DiagnosticFloatSpecial:
+		return "float special"
+	case DiagnosticDroppedNull:
+		return "dropped null"
+	case DiagnosticDiscardedAttribute:
+		return "discarded attribute"
+	case DiagnosticNameCollision:
+		return "name collision"
+	case DiagnosticCaseCollision:
+		return "case collision"
+	default:
+		return fmt.Sprintf("DiagnosticKind(%d)", int(k))
+	}
+}
+
+// Diagnostic is an issue found while converting a message, which does not
+// fail the conversion but may lose or alter data, reported to the function
+// given to [WithDiagnostics].
+type Diagnostic struct {
+	// Kind is the kind of issue.
+	Kind DiagnosticKind
+
+	// Message is the full name of the message type converted, such as
+	// "example.User".
+	Message protoreflect.FullName
+
+	// Path is the path of the field, using proto field names, such as
+	// "address.zip_code", or the name of the attribute if it is not a
+	// field.
+	Path string
+
+	// Detail describes the issue.
+	Detail string
+}
+
+// String returns a description of the diagnostic for logs.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("dynabuf: %s at %s %s: %s", d.Kind, d.Message, d.Path, d.Detail)
+}
+
+// WithDiagnostics calls fn with every [Diagnostic] found while converting
+// messages with [Marshal] and [Unmarshal], so lossy conversions can be
+// logged without failing requests. Diagnostics are only reported for
+// conversions that succeed.
+//
+// # Example
+//
+//	err := dynabuf.Unmarshal(out.Item, &user, dynabuf.WithDiagnostics(func(d dynabuf.Diagnostic) {
+//	  slog.Warn("lossy conversion", "kind", d.Kind, "message", d.Message, "path", d.Path, "detail", d.Detail)
+//	}))
+func WithDiagnostics(fn func(Diagnostic)) Option {
+	return func(o *options) {
+		o.diagnostics = fn
+	}
+}
+
+// report calls the diagnostics function of the options.
+func (o *options) report(kind DiagnosticKind, root protoreflect.MessageDescriptor, path, detail string, args ...any) {
+	o.diagnostics(Diagnostic{
+		Kind:    kind,
+		Message: root.FullName(),
+		Path:    path,
+		Detail:  fmt.Sprintf(detail, args...),
+	})
+}
+
+// diagnoseMarshal reports the lossy conversions of the JSON form of a
+// message of type md, before its values are converted to their stored form.
+func (o *options) diagnoseMarshal(root, md protoreflect.MessageDescriptor, fields map[string]any, prefix string) {
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		fd := md.Fields().ByJSONName(name)
+		if fd == nil {
+			continue
+		}
+		path := prefix + string(fd.Name())
+
+		eachValue(fd, fields[name], func(fd protoreflect.FieldDescriptor, v any) {
+			if nested, ok := v.(map[string]any); ok && !isWellKnown(fd.Message()) {
+				o.diagnoseMarshal(root, fd.Message(), nested, path+".")
+				return
+			}
+
+			fd = unwrapFloat(fd)
+			if !isFloat(fd) || isSortable(fd) {
+				return
+			}
+			switch v := v.(type) {
+			case string:
+				o.report(DiagnosticFloatSpecial, root, path, "%s is stored as a string", v)
+			case float64:
+				if o.floatFormat == 0 {
+					return
+				}
+				bitSize := floatBitSize(fd)
+				s := strconv.FormatFloat(v, o.floatFormat, o.floatPrec, bitSize)
+				if f, _ := strconv.ParseFloat(s, bitSize); f != v && (bitSize == 64 || float32(f) != float32(v)) {
+					o.report(DiagnosticPrecisionLoss, root, path, "%v is stored as %s", v, s)
+				}
+			}
+		})
+	}
+}
+
+// diagnoseUnmarshal reports the lossy conversions of the stored form of a
+// message of type md.
+func (o *options) diagnoseUnmarshal(root, md protoreflect.MessageDescriptor, fields map[string]any, prefix string) {
+	mo, _ := proto.GetExtension(md.Options(), dynabufpb.E_Message).(*dynabufpb.MessageOptions)
+
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if slices.ContainsFunc(mo.GetCompositeAttributes(), func(c *dynabufpb.CompositeAttribute) bool { return c.GetName() == name }) {
+			continue
+		}
+
+		fd := md.Fields().ByJSONName(name)
+		if fd == nil {
+			fd = md.Fields().ByName(protoreflect.Name(name))
+			if fd != nil && fd.JSONName() != name {
+				o.report(DiagnosticNameCollision, root, prefix+name, "attribute is stored by Marshal as %q", fd.JSONName())
+			}
+		}
+		if fd == nil {
+			fd = aliasedField(md, name)
+		}
+		if fd == nil {
+			if o.discardUnknown {
+				o.report(DiagnosticDiscardedAttribute, root, prefix+name, "attribute is not a field of %s", md.FullName())
+			}
+			continue
+		}
+		path := prefix + string(fd.Name())
+
+		if fields[name] == nil {
+			if !acceptsNull(fd) {
+				o.report(DiagnosticDroppedNull, root, path, "NULL is read as an unset field")
+			}
+			continue
+		}
+
+		eachValue(fd, fields[name], func(fd protoreflect.FieldDescriptor, v any) {
+			switch md := fd.Message(); {
+			case md != nil && (md.FullName() == "google.protobuf.Value" ||
+				md.FullName() == "google.protobuf.Struct" ||
+				md.FullName() == "google.protobuf.ListValue"):
+				eachNumber(v, func(n json.Number) {
+					if !exactFloat(string(n), 64) {
+						o.report(DiagnosticPrecisionLoss, root, path, "%s is read as a double", n)
+					}
+				})
+				return
+			case md != nil && !isWellKnown(md):
+				if nested, ok := v.(map[string]any); ok {
+					o.diagnoseUnmarshal(root, md, nested, path+".")
+				}
+				return
+			}
+
+			fd = unwrapFloat(fd)
+			if n, ok := v.(json.Number); ok && isFloat(fd) && !isSortable(fd) && !exactFloat(string(n), floatBitSize(fd)) {
+				o.report(DiagnosticPrecisionLoss, root, path, "%s is read as a %s", n, fd.Kind())
+			}
+		})
+	}
+}
+
+// diagnoseStored decodes the stored form of a message of type md and
+// reports its lossy conversions.
+func (o *options) diagnoseStored(md protoreflect.MessageDescriptor, data []byte) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var fields map[string]any
+	if d.Decode(&fields) != nil {
+		return
+	}
+
+	// The attributes of a google.protobuf.Struct stored as the item are its
+	// entries, with the numbers read as doubles.
+	if isWellKnown(md) {
+		if md.FullName() == "google.protobuf.Struct" {
+			for _, name := range slices.Sorted(maps.Keys(fields)) {
+				eachNumber(fields[name], func(n json.Number) {
+					if !exactFloat(string(n), 64) {
+						o.report(DiagnosticPrecisionLoss, md, name, "%s is read as a double", n)
+					}
+				})
+			}
+		}
+		return
+	}
+	o.diagnoseUnmarshal(md, md, fields, "")
+}
+
+// eachValue calls fn with each value of a field, which are the elements of
+// lists, and the values of maps, with the field describing them.
+func eachValue(fd protoreflect.FieldDescriptor, v any, fn func(protoreflect.FieldDescriptor, any)) {
+	switch {
+	case fd.IsMap():
+		m, _ := v.(map[string]any)
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			fn(fd.MapValue(), m[k])
+		}
+	case fd.IsList():
+		list, _ := v.([]any)
+		for _, elem := range list {
+			fn(fd, elem)
+		}
+	default:
+		fn(fd, v)
+	}
+}
+
+// eachNumber calls fn with each number of a decoded JSON value.
+func eachNumber(v any, fn func(json.Number)) {
+	switch v := v.(type) {
+	case json.Number:
+		fn(v)
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			eachNumber(v[k], fn)
+		}
+	case []any:
+		for _, elem := range v {
+			eachNumber(elem, fn)
+		}
+	}
+}
+
+// isWellKnown reports whether md is nil or a well-known type, which has its
+// own JSON form.
+func isWellKnown(md protoreflect.MessageDescriptor) bool {
+	return md == nil ||
+		strings.HasPrefix(string(md.FullName()), "google.protobuf.") ||
+		strings.HasPrefix(string(md.FullName()), "google.type.")
+}
+
+// unwrapFloat returns the value field of float and double wrappers, which
+// are stored as their value, or fd otherwise.
+func unwrapFloat(fd protoreflect.FieldDescriptor) protoreflect.FieldDescriptor {
+	if md := fd.Message(); md != nil && (md.FullName() == "google.protobuf.DoubleValue" || md.FullName() == "google.protobuf.FloatValue") {
+		return md.Fields().ByName("value")
+	}
+	return fd
+}
+
+func isFloat(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.FloatKind || fd.Kind() == protoreflect.DoubleKind
+}
+
+func floatBitSize(fd protoreflect.FieldDescriptor) int {
+	if fd.Kind() == protoreflect.FloatKind {
+		return 32
+	}
+	return 64
+}
+
+// acceptsNull reports whether fd holds NULL values, as google.protobuf.Value
+// and NullValue fields do.
+func acceptsNull(fd protoreflect.FieldDescriptor) bool {
+	if md := fd.Message(); md != nil {
+		return md.FullName() == "google.protobuf.Value"
+	}
+	return fd.Enum() != nil && fd.Enum().FullName() == "google.protobuf.NullValue"
+}
+
+// exactFloat reports whether the decimal number s is read as a float of the
+// bit size without losing digits, which is when the shortest decimal form
+// of the float is the same number.
+func exactFloat(s string, bitSize int) bool {
+	want, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return true
+	}
+	f, err := strconv.ParseFloat(s, bitSize)
+	if err != nil {
+		return false
+	}
+	got, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	return got != nil && want.Cmp(got) == 0
+}
@@ -0,0 +1,128 @@
+package dynabuf_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestDiagnostics(t *testing.T) {
+	// diagnostic is a diagnostic without its detail, to compare them.
+	type diagnostic struct {
+		Kind dynabuf.DiagnosticKind
+		Path string
+	}
+
+	tests := []struct {
+		name   string
+		msg    proto.Message
+		item   map[string]types.AttributeValue
+		into   proto.Message
+		opts   []dynabuf.Option
+		expect []diagnostic
+	}{
+		{
+			name: "exact",
+			msg:  &testpb.Product{Id: "p1", Price: "9.90", Weight: 1.5, Rating: 0.1},
+			opts: []dynabuf.Option{dynabuf.WithFloatFormat('f', 2)},
+		},
+		{
+			name:   "rounded float",
+			msg:    &testpb.Product{Weight: 1.555, Bundled: &testpb.Product{Rating: 4.25}},
+			opts:   []dynabuf.Option{dynabuf.WithFloatFormat('f', 1)},
+			expect: []diagnostic{{dynabuf.DiagnosticPrecisionLoss, "bundled.rating"}, {dynabuf.DiagnosticPrecisionLoss, "weight"}},
+		},
+		{
+			name: "float specials",
+			msg: &testpb.Product{
+				Weight:       math.NaN(),
+				DiscountRate: wrapperspb.Double(math.Inf(1)),
+			},
+			expect: []diagnostic{{dynabuf.DiagnosticFloatSpecial, "discount_rate"}, {dynabuf.DiagnosticFloatSpecial, "weight"}},
+		},
+		{
+			name: "numbers read as floats",
+			item: map[string]types.AttributeValue{
+				"weight": &types.AttributeValueMemberN{Value: "12345678901234567891"},
+				"rating": &types.AttributeValueMemberN{Value: "0.1"},
+				"price":  &types.AttributeValueMemberN{Value: "12345678901234567891"},
+			},
+			into:   &testpb.Product{},
+			expect: []diagnostic{{dynabuf.DiagnosticPrecisionLoss, "weight"}},
+		},
+		{
+			name: "numbers read as values",
+			item: map[string]types.AttributeValue{
+				"small": &types.AttributeValueMemberN{Value: "42"},
+				"big":   &types.AttributeValueMemberN{Value: "9007199254740993"},
+			},
+			into:   &structpb.Struct{},
+			expect: []diagnostic{{dynabuf.DiagnosticPrecisionLoss, "big"}},
+		},
+		{
+			name: "nulls",
+			item: map[string]types.AttributeValue{
+				"id":   &types.AttributeValueMemberS{Value: "1"},
+				"name": &types.AttributeValueMemberNULL{Value: true},
+				"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"street": &types.AttributeValueMemberNULL{Value: true},
+				}},
+			},
+			into:   &testpb.User{},
+			expect: []diagnostic{{dynabuf.DiagnosticDroppedNull, "address.street"}, {dynabuf.DiagnosticDroppedNull, "name"}},
+		},
+		{
+			name: "discarded attributes",
+			item: map[string]types.AttributeValue{
+				"id":     &types.AttributeValueMemberS{Value: "1"},
+				"legacy": &types.AttributeValueMemberBOOL{Value: true},
+			},
+			into:   &testpb.User{},
+			opts:   []dynabuf.Option{dynabuf.WithDiscardUnknown()},
+			expect: []diagnostic{{dynabuf.DiagnosticDiscardedAttribute, "legacy"}},
+		},
+		{
+			name: "proto names",
+			item: map[string]types.AttributeValue{
+				"id":          &types.AttributeValueMemberS{Value: "1"},
+				"create_time": &types.AttributeValueMemberS{Value: "2024-01-02T03:04:05Z"},
+			},
+			into:   &testpb.User{},
+			expect: []diagnostic{{dynabuf.DiagnosticNameCollision, "create_time"}},
+		},
+		{
+			name: "composite attributes",
+			item: map[string]types.AttributeValue{
+				"customer": &types.AttributeValueMemberS{Value: "alice"},
+				"sk":       &types.AttributeValueMemberS{Value: "ORDER#OPEN#2024-01-02T03:04:05Z#o1"},
+			},
+			into: &testpb.Order{},
+			opts: []dynabuf.Option{dynabuf.WithDiscardUnknown()},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []diagnostic
+			opts := append(test.opts, dynabuf.WithDiagnostics(func(d dynabuf.Diagnostic) {
+				must.NotEq(t, "", d.Detail)
+				got = append(got, diagnostic{d.Kind, d.Path})
+			}))
+
+			if test.msg != nil {
+				_, err := dynabuf.Marshal(test.msg, opts...)
+				must.NoError(t, err)
+			} else {
+				must.NoError(t, dynabuf.Unmarshal(test.item, test.into, opts...))
+			}
+			must.Eq(t, test.expect, got)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package dynabuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/smithy-go/document"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// DocumentValue returns the value of a smithy document holding msg, using
+// the same mapping as [Marshal], for APIs that accept document types, such
+// as tool inputs and model parameters. Numbers are returned as
+// [document.Number], so they keep their precision.
+//
+// Documents are constructed by the document package of each API client,
+// so the value is meant to be passed to its constructor.
+//
+// # Example
+//
+//	v, _ := dynabuf.DocumentValue(&example.Weather{City: "Paris"})
+//
+//	input := document.NewLazyDocument(v)
+func DocumentValue(msg proto.Message) (map[string]any, error) {
+	b, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var v map[string]any
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%w: %w: %w", ErrFailedToMarshal, ErrFailedToUnmarshalIntermediary, err)
+	}
+	if err := convertFields(msg.ProtoReflect().Descriptor(), v, newOptions(nil).encodeValue); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+	}
+	return convertNumbers[json.Number, document.Number](v).(map[string]any), nil
+}
+
+// UnmarshalDocument decodes a smithy document into msg, using the same
+// mapping as [Unmarshal], so documents returned by APIs, or sent to them by
+// callers, decode like stored items.
+//
+// # Example
+//
+//	var weather example.Weather
+//	_ = dynabuf.UnmarshalDocument(out.Input, &weather)
+func UnmarshalDocument(doc document.Marshaler, msg proto.Message, opts ...Option) error {
+	b, err := doc.MarshalSmithyDocument()
+	if err != nil {
+		return fmt.Errorf("%w: %w: %w", ErrFailedToUnmarshal, ErrFailedToMarshalIntermediary, err)
+	}
+
+	if err := unmarshalJSONToProto(b, msg, newOptions(opts)); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToUnmarshal, err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package dynabuf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/document"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// jsonDocument is a smithy document encoded as JSON, as API clients encode
+// them.
+type jsonDocument string
+
+func (d jsonDocument) MarshalSmithyDocument() ([]byte, error) { return []byte(d), nil }
+
+func TestDocumentValue(t *testing.T) {
+	v, err := dynabuf.DocumentValue(&testpb.User{
+		Name:              "Alice",
+		Age:               30,
+		PreviousAddresses: []*testpb.Address{{ZipCode: 12345}},
+		CreateTime:        timestamppb.New(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+	})
+	must.NoError(t, err)
+	must.Eq(t, map[string]any{
+		"name":              "Alice",
+		"age":               document.Number("30"),
+		"previousAddresses": []any{map[string]any{"zipCode": document.Number("12345")}},
+		"createTime":        "2024-03-01T00:00:00Z",
+	}, v)
+}
+
+func TestUnmarshalDocument(t *testing.T) {
+	var user testpb.User
+	err := dynabuf.UnmarshalDocument(jsonDocument(`{"name": "Alice", "age": 30, "address": {"zipCode": 12345}}`), &user)
+	must.NoError(t, err)
+	must.True(t, proto.Equal(&testpb.User{Name: "Alice", Age: 30, Address: &testpb.Address{ZipCode: 12345}}, &user))
+
+	err = dynabuf.UnmarshalDocument(jsonDocument(`{"name": "Alice", "email": "alice@example.com"}`), &user)
+	must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+
+	err = dynabuf.UnmarshalDocument(jsonDocument(`{"name": "Alice", "email": "alice@example.com"}`), &user, dynabuf.WithDiscardUnknown())
+	must.NoError(t, err)
+}
@@ -0,0 +1,463 @@
+package dynabuf
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DraftAPIClient is the subset of the DynamoDB API used by
+// [DraftTableProto].
+type DraftAPIClient interface {
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
+// DraftTableProto describes the table, scans up to samples of its items,
+// and returns a draft .proto file declaring a message in package pkg for
+// them, as [DraftProto] does.
+//
+// # Example
+//
+//	draft, _ := dynabuf.DraftTableProto(ctx, client, "example.v1", "users", 1000)
+//
+//	_ = os.WriteFile("users.proto", []byte(draft), 0o644)
+func DraftTableProto(ctx context.Context, client DraftAPIClient, pkg, table string, samples int) (string, error) {
+	desc, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return "", fmt.Errorf("dynabuf: failed to describe table %q: %w", table, err)
+	}
+
+	var (
+		items    []map[string]types.AttributeValue
+		startKey map[string]types.AttributeValue
+	)
+	for len(items) < samples {
+		out, err := client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:         aws.String(table),
+			ExclusiveStartKey: startKey,
+			Limit:             aws.Int32(int32(min(samples-len(items), 1000))),
+		})
+		if err != nil {
+			return "", fmt.Errorf("dynabuf: failed to scan table %q: %w", table, err)
+		}
+		items = append(items, out.Items...)
+
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = out.LastEvaluatedKey
+	}
+
+	return DraftProto(pkg, desc.Table, items)
+}
+
+// DraftProto returns a draft .proto file declaring a message in package
+// pkg whose fields map to the attributes of the items of the table
+// described by desc, as returned by DescribeTable, inferred from a sample
+// of its items, to start using dynabuf with an existing table.
+//
+// The field of every attribute found in the items, or in the key schemas
+// of the table and its indexes, is declared with a type [Marshal] stores
+// as the attribute's type, if there is one:
+//
+//   - strings as string, or google.protobuf.Timestamp if they are all
+//     RFC 3339 timestamps
+//   - integers as int32 if they all fit, and other numbers as double if
+//     they all have 15 significant digits or less, or as string fields
+//     annotated as decimal otherwise, so they are stored exactly
+//   - maps as nested messages, and lists, and sets, as repeated fields
+//   - attributes holding values of several types, or only nulls, as
+//     google.protobuf.Value
+//
+// Fields are named after their attribute in snake case, with a json_name
+// option when the attribute name is not the field's JSON name, and
+// commented with the keys of the table and indexes they are part of.
+// Binary values, and sets, which [Marshal] stores as base64 strings and
+// lists, are commented too, since their stored form changes once written
+// by dynabuf.
+//
+// The draft is meant to be reviewed: attributes missing from the sample
+// are missing from the message, and types are those of the values sampled.
+func DraftProto(pkg string, desc *types.TableDescription, items []map[string]types.AttributeValue) (string, error) {
+	if desc == nil || aws.ToString(desc.TableName) == "" {
+		return "", fmt.Errorf("dynabuf: table description has no table name")
+	}
+
+	root := newDraftAttribute()
+	for _, item := range items {
+		root.add(&types.AttributeValueMemberM{Value: item})
+	}
+
+	// Key attributes come first, whether sampled or not, typed as defined
+	// by the table.
+	keyTypes := map[string]string{}
+	for _, def := range desc.AttributeDefinitions {
+		keyTypes[aws.ToString(def.AttributeName)] = string(def.AttributeType)
+	}
+	var keys []string
+	comments := map[string][]string{}
+	addKeys := func(index string, schema []types.KeySchemaElement) {
+		for _, k := range schema {
+			name := aws.ToString(k.AttributeName)
+			if !slices.Contains(keys, name) {
+				keys = append(keys, name)
+			}
+
+			key := "Partition key"
+			if k.KeyType == types.KeyTypeRange {
+				key = "Sort key"
+			}
+			if index == "" {
+				comments[name] = append(comments[name], key+" of the table.")
+			} else {
+				comments[name] = append(comments[name], fmt.Sprintf("%s of index %q.", key, index))
+			}
+		}
+	}
+	addKeys("", desc.KeySchema)
+	for _, index := range desc.GlobalSecondaryIndexes {
+		addKeys(aws.ToString(index.IndexName), index.KeySchema)
+	}
+	for _, index := range desc.LocalSecondaryIndexes {
+		addKeys(aws.ToString(index.IndexName), index.KeySchema)
+	}
+
+	for _, name := range keys {
+		if _, ok := root.fields[name]; !ok {
+			a := newDraftAttribute()
+			a.types[keyTypes[name]]++
+			root.fields[name] = a
+		}
+	}
+
+	g := &draftGenerator{imports: map[string]bool{}}
+
+	order := slices.Clone(keys)
+	for _, name := range slices.Sorted(maps.Keys(root.fields)) {
+		if !slices.Contains(order, name) {
+			order = append(order, name)
+		}
+	}
+
+	var body strings.Builder
+	message := draftMessageName(aws.ToString(desc.TableName))
+	fmt.Fprintf(&body, "// %s is a draft of the items of the DynamoDB table %q, inferred\n", message, aws.ToString(desc.TableName))
+	fmt.Fprintf(&body, "// from %d sampled items.\n", len(items))
+	g.message(&body, "", message, root, order, comments)
+
+	return g.file(pkg, body.String()), nil
+}
+
+// draftAttribute accumulates the values sampled of an attribute, or of the
+// elements of lists and sets.
+type draftAttribute struct {
+	// types counts the values by attribute value type.
+	types map[string]int
+
+	// timestamps counts the strings that are RFC 3339 timestamps.
+	timestamps int
+
+	// integers and int32s count the numbers that are integers, and that
+	// fit in an int32, and digits is the most significant digits of one.
+	integers, int32s, digits int
+
+	// fields are the attributes of maps, and elements the elements of
+	// lists and sets.
+	fields   map[string]*draftAttribute
+	elements *draftAttribute
+}
+
+// newDraftAttribute returns an attribute with no values.
+func newDraftAttribute() *draftAttribute {
+	return &draftAttribute{types: map[string]int{}, fields: map[string]*draftAttribute{}}
+}
+
+// add records a value of the attribute.
+func (a *draftAttribute) add(v types.AttributeValue) {
+	a.types[attributeValueType(v)]++
+
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		a.addString(v.Value)
+	case *types.AttributeValueMemberN:
+		a.addNumber(v.Value)
+	case *types.AttributeValueMemberM:
+		for name, v := range v.Value {
+			field, ok := a.fields[name]
+			if !ok {
+				field = newDraftAttribute()
+				a.fields[name] = field
+			}
+			field.add(v)
+		}
+	case *types.AttributeValueMemberL:
+		for _, v := range v.Value {
+			a.element().add(v)
+		}
+	case *types.AttributeValueMemberSS:
+		for _, s := range v.Value {
+			a.element().add(&types.AttributeValueMemberS{Value: s})
+		}
+	case *types.AttributeValueMemberNS:
+		for _, n := range v.Value {
+			a.element().add(&types.AttributeValueMemberN{Value: n})
+		}
+	case *types.AttributeValueMemberBS:
+		for _, b := range v.Value {
+			a.element().add(&types.AttributeValueMemberB{Value: b})
+		}
+	}
+}
+
+// addString records a string value.
+func (a *draftAttribute) addString(s string) {
+	if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		a.timestamps++
+	}
+}
+
+// addNumber records a number value.
+func (a *draftAttribute) addNumber(s string) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		a.integers++
+		if int64(int32(n)) == n {
+			a.int32s++
+		}
+	}
+
+	mantissa, _, _ := strings.Cut(strings.ToLower(s), "e")
+	digits := strings.TrimLeft(strings.NewReplacer("-", "", ".", "").Replace(mantissa), "0")
+	a.digits = max(a.digits, len(digits), 1)
+}
+
+// element returns the attribute of the elements of lists and sets.
+func (a *draftAttribute) element() *draftAttribute {
+	if a.elements == nil {
+		a.elements = newDraftAttribute()
+	}
+	return a.elements
+}
+
+// draftGenerator writes the messages of a draft .proto file.
+type draftGenerator struct {
+	imports map[string]bool
+}
+
+// file returns a .proto file of package pkg declaring the messages of
+// body, importing the files they use.
+func (g *draftGenerator) file(pkg, body string) string {
+	var b strings.Builder
+	b.WriteString("syntax = \"proto3\";\n\n")
+	if pkg != "" {
+		fmt.Fprintf(&b, "package %s;\n\n", pkg)
+	}
+	if len(g.imports) > 0 {
+		for _, path := range slices.Sorted(maps.Keys(g.imports)) {
+			fmt.Fprintf(&b, "import %q;\n", path)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(body)
+	return b.String()
+}
+
+// message writes the declaration of a message whose fields are the
+// attributes of a, in order.
+func (g *draftGenerator) message(b *strings.Builder, indent, name string, a *draftAttribute, order []string, comments map[string][]string) {
+	fmt.Fprintf(b, "%smessage %s {\n", indent, name)
+
+	used := map[string]bool{}
+	var nested []func()
+	for i, attr := range order {
+		field := draftFieldName(attr)
+		for n := 2; used[field]; n++ {
+			field = fmt.Sprintf("%s_%d", draftFieldName(attr), n)
+		}
+		used[field] = true
+
+		f := a.fields[attr]
+		typ, notes, decimal := g.fieldType(f)
+		if typ == "" {
+			message := draftMessageName(field)
+			typ = message
+			if f.types["M"] == 0 {
+				typ = "repeated " + message
+				f = f.elements
+			}
+			nested = append(nested, func() {
+				b.WriteString("\n")
+				g.message(b, indent+"  ", message, f, slices.Sorted(maps.Keys(f.fields)), nil)
+			})
+		}
+
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		g.field(b, indent+"  ", typ, field, attr, i+1, append(comments[attr], notes...), decimal)
+	}
+	for _, fn := range nested {
+		fn()
+	}
+
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// field writes the declaration of a field of the attribute attr, preceded
+// by comments.
+func (g *draftGenerator) field(b *strings.Builder, indent, typ, field, attr string, number int, comments []string, decimal bool) {
+	for _, comment := range comments {
+		fmt.Fprintf(b, "%s// %s\n", indent, comment)
+	}
+
+	var opts []string
+	if draftJSONName(field) != attr {
+		opts = append(opts, fmt.Sprintf("json_name = %q", attr))
+	}
+	if decimal {
+		g.imports["dynabufpb/options.proto"] = true
+		opts = append(opts, "(dynabuf.v1.field) = {decimal: true}")
+	}
+	fmt.Fprintf(b, "%s%s %s = %d", indent, typ, field, number)
+	if len(opts) > 0 {
+		fmt.Fprintf(b, " [%s]", strings.Join(opts, ", "))
+	}
+	b.WriteString(";\n")
+}
+
+// fieldType returns the type of the field of the attribute, with notes
+// about how the attribute is stored and whether it is a decimal field, or
+// an empty type if it is a nested message, or a list of them.
+func (g *draftGenerator) fieldType(a *draftAttribute) (typ string, notes []string, decimal bool) {
+	if len(a.types) != 1 {
+		if len(a.types) > 1 {
+			notes = append(notes, fmt.Sprintf("Sampled values have types %s.", strings.Join(slices.Sorted(maps.Keys(a.types)), ", ")))
+		}
+		return g.value(), notes, false
+	}
+
+	switch typ := slices.Collect(maps.Keys(a.types))[0]; typ {
+	case "SS", "NS", "BS":
+		notes = append(notes, fmt.Sprintf("Sampled values are %s sets, which dynabuf stores as lists.", typ))
+		fallthrough
+	case "L":
+		if a.elements == nil {
+			return "repeated string", notes, false
+		}
+		elem, elemNotes, decimal := g.fieldType(a.elements)
+		switch {
+		case elem == "" && a.elements.types["M"] > 0:
+			return "", notes, false
+		case elem == "" || strings.HasPrefix(elem, "repeated ") || elem == "google.protobuf.Value" && len(a.elements.types) > 1:
+			g.imports["google/protobuf/struct.proto"] = true
+			return "google.protobuf.ListValue", notes, false
+		}
+		return "repeated " + elem, append(notes, elemNotes...), decimal
+	case "M":
+		return "", nil, false
+	}
+	return g.scalar(a)
+}
+
+// scalar returns the type of the field of an attribute of a single scalar
+// type.
+func (g *draftGenerator) scalar(a *draftAttribute) (typ string, notes []string, decimal bool) {
+	switch {
+	case a.types["S"] > 0:
+		if a.timestamps == a.types["S"] {
+			g.imports["google/protobuf/timestamp.proto"] = true
+			return "google.protobuf.Timestamp", nil, false
+		}
+		return "string", nil, false
+	case a.types["N"] > 0:
+		switch n := a.types["N"]; {
+		case a.int32s == n:
+			return "int32", nil, false
+		case a.integers < n && a.digits > 0 && a.digits <= 15:
+			return "double", nil, false
+		}
+		return "string", nil, true
+	case a.types["BOOL"] > 0:
+		return "bool", nil, false
+	case a.types["B"] > 0:
+		return "bytes", []string{"Sampled values are binary, which dynabuf stores as base64 strings."}, false
+	}
+	return g.value(), nil, false
+}
+
+// value returns the type of fields of attributes of any type.
+func (g *draftGenerator) value() string {
+	g.imports["google/protobuf/struct.proto"] = true
+	return "google.protobuf.Value"
+}
+
+// draftFieldName returns the snake case field name of an attribute, such
+// as "user_id" for "userId", "UserID", or "user-id".
+func draftFieldName(attr string) string {
+	var b strings.Builder
+	runes := []rune(attr)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// Split words before an uppercase letter following a lowercase
+			// letter or digit, or starting a word after an acronym.
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case r < unicode.MaxASCII && (unicode.IsLower(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	name := strings.Trim(b.String(), "_")
+	for strings.Contains(name, "__") {
+		name = strings.ReplaceAll(name, "__", "_")
+	}
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "field_" + name
+	}
+	return strings.TrimSuffix(name, "_")
+}
+
+// draftJSONName returns the JSON name protoc gives a field by default.
+func draftJSONName(field string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range field {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// draftMessageName returns the name of a message in upper camel case, such
+// as "UserEvents" for "user-events".
+func draftMessageName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(draftFieldName(name), "_") {
+		if word != "" {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package dynabuf_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/shoenig/test/must"
+)
+
+func TestDraftTableProto(t *testing.T) {
+	ctx := context.Background()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("user-orders"),
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("PK"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("SK"), KeyType: types.KeyTypeRange},
+		},
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("PK"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("SK"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("GSI1PK"), AttributeType: types.ScalarAttributeTypeN},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String("gsi1"),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("GSI1PK"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("SK"), KeyType: types.KeyTypeRange},
+				},
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	for _, item := range []map[string]types.AttributeValue{
+		{
+			"PK":        &types.AttributeValueMemberS{Value: "USER#1"},
+			"SK":        &types.AttributeValueMemberS{Value: "ORDER#1"},
+			"createdAt": &types.AttributeValueMemberS{Value: "2024-03-01T00:00:00Z"},
+			"total":     &types.AttributeValueMemberN{Value: "12.5"},
+			"quantity":  &types.AttributeValueMemberN{Value: "3"},
+			"tags":      &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+			"items": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"sku":   &types.AttributeValueMemberS{Value: "X"},
+					"price": &types.AttributeValueMemberN{Value: "123456789.123456789"},
+				}},
+			}},
+		},
+		{
+			"PK":        &types.AttributeValueMemberS{Value: "USER#1"},
+			"SK":        &types.AttributeValueMemberS{Value: "ORDER#2"},
+			"createdAt": &types.AttributeValueMemberS{Value: "2024-03-02T00:00:00Z"},
+			"note":      &types.AttributeValueMemberS{Value: "gift"},
+			"extra":     &types.AttributeValueMemberBOOL{Value: true},
+			"shipping": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"address": &types.AttributeValueMemberS{Value: "Main St"},
+				"express": &types.AttributeValueMemberBOOL{Value: false},
+			}},
+		},
+		{
+			"PK":    &types.AttributeValueMemberS{Value: "USER#2"},
+			"SK":    &types.AttributeValueMemberS{Value: "ORDER#3"},
+			"extra": &types.AttributeValueMemberS{Value: "yes"},
+			"id":    &types.AttributeValueMemberB{Value: []byte{1}},
+		},
+	} {
+		_, err := client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("user-orders"), Item: item})
+		must.NoError(t, err)
+	}
+
+	draft, err := dynabuf.DraftTableProto(ctx, client, "example.v1", "user-orders", 10)
+	must.NoError(t, err)
+
+	must.Eq(t, `syntax = "proto3";
+
+package example.v1;
+
+import "dynabufpb/options.proto";
+import "google/protobuf/struct.proto";
+import "google/protobuf/timestamp.proto";
+
+// UserOrders is a draft of the items of the DynamoDB table "user-orders", inferred
+// from 3 sampled items.
+message UserOrders {
+  // Partition key of the table.
+  string pk = 1 [json_name = "PK"];
+
+  // Sort key of the table.
+  // Sort key of index "gsi1".
+  string sk = 2 [json_name = "SK"];
+
+  // Partition key of index "gsi1".
+  string gsi1_pk = 3 [json_name = "GSI1PK", (dynabuf.v1.field) = {decimal: true}];
+
+  google.protobuf.Timestamp created_at = 4;
+
+  // Sampled values have types BOOL, S.
+  google.protobuf.Value extra = 5;
+
+  // Sampled values are binary, which dynabuf stores as base64 strings.
+  bytes id = 6;
+
+  repeated Items items = 7;
+
+  string note = 8;
+
+  int32 quantity = 9;
+
+  Shipping shipping = 10;
+
+  // Sampled values are SS sets, which dynabuf stores as lists.
+  repeated string tags = 11;
+
+  double total = 12;
+
+  message Items {
+    string price = 1 [(dynabuf.v1.field) = {decimal: true}];
+
+    string sku = 2;
+  }
+
+  message Shipping {
+    string address = 1;
+
+    bool express = 2;
+  }
+}
+`, draft)
+}
+
+func TestDraftProtoRequiresTableName(t *testing.T) {
+	_, err := dynabuf.DraftProto("example.v1", &types.TableDescription{}, nil)
+	must.ErrorContains(t, err, "no table name")
+}
@@ -0,0 +1,285 @@
+package dynabuf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+)
+
+// DraftStructProto returns a draft .proto file declaring a message in
+// package pkg for the struct type of every value, and of the structs they
+// are made of, whose fields map to the same attributes as the attributevalue
+// package maps the struct fields to, following their dynamodbav tags, to
+// convert hand-written models to messages.
+//
+// Fields are declared with a type [Marshal] stores as attributevalue does,
+// if there is one:
+//
+//   - 8, 16, and 32-bit integers as int32 and uint32, and 64-bit integers,
+//     including int and uint, as string fields annotated as decimal, which
+//     are stored as numbers, since 64-bit integer fields are stored as
+//     strings, unless tagged with the string option
+//   - time.Time as google.protobuf.Timestamp, or as a decimal string field
+//     of epoch seconds if tagged with the unixtime option
+//   - slices as repeated fields, structs as messages, maps with string keys
+//     as maps, and interfaces as google.protobuf.Value
+//   - pointers to scalars as optional fields
+//
+// Fields are named after their attribute in snake case, with a json_name
+// option when the attribute name is not the field's JSON name. Fields
+// stored differently by dynabuf, such as byte slices and sets, which
+// [Marshal] stores as base64 strings and lists, and fields of types with
+// their own MarshalDynamoDBAttributeValue method, are commented.
+//
+// An error is returned if a value is not a struct, or a pointer to one, or
+// if a field has a type that cannot be stored, such as a channel.
+//
+// # Example
+//
+//	draft, _ := dynabuf.DraftStructProto("example.v1", User{}, Order{})
+func DraftStructProto(pkg string, values ...any) (string, error) {
+	d := &structDrafter{
+		g:     &draftGenerator{imports: map[string]bool{}},
+		names: map[reflect.Type]string{},
+		used:  map[string]bool{},
+	}
+
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return "", fmt.Errorf("dynabuf: %T is not a struct", v)
+		}
+		d.messageName(t, "")
+	}
+
+	var body strings.Builder
+	for i := 0; i < len(d.queue); i++ {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		if err := d.message(&body, d.queue[i]); err != nil {
+			return "", err
+		}
+	}
+
+	return d.g.file(pkg, body.String()), nil
+}
+
+// structDrafter writes the messages of a draft .proto file for struct
+// types.
+type structDrafter struct {
+	g *draftGenerator
+
+	// names are the message names of struct types, which are unique
+	// within used, and queue the struct types in the order they are found.
+	names map[reflect.Type]string
+	used  map[string]bool
+	queue []reflect.Type
+}
+
+// structField is a struct field stored as an attribute.
+type structField struct {
+	attr string
+	typ  reflect.Type
+	path string
+
+	// opts are the options of the dynamodbav tag, such as "omitempty".
+	opts []string
+}
+
+// messageName returns the name of the message of struct type t, named
+// after fallback if the type has no name, queueing it the first time.
+func (d *structDrafter) messageName(t reflect.Type, fallback string) string {
+	if name, ok := d.names[t]; ok {
+		return name
+	}
+
+	base := draftMessageName(t.Name())
+	if base == "" {
+		base = draftMessageName(fallback)
+	}
+	name := base
+	for n := 2; d.used[name]; n++ {
+		name = fmt.Sprintf("%s%d", base, n)
+	}
+
+	d.names[t], d.used[name] = name, true
+	d.queue = append(d.queue, t)
+	return name
+}
+
+// message writes the declaration of the message of struct type t.
+func (d *structDrafter) message(b *strings.Builder, t reflect.Type) error {
+	name := d.names[t]
+	if t.Name() != "" {
+		fmt.Fprintf(b, "// %s is a draft of the Go struct %s.\n", name, t)
+	}
+	fmt.Fprintf(b, "message %s {\n", name)
+
+	used := map[string]bool{}
+	for i, f := range structFields(t, "") {
+		field := draftFieldName(f.attr)
+		for n := 2; used[field]; n++ {
+			field = fmt.Sprintf("%s_%d", draftFieldName(f.attr), n)
+		}
+		used[field] = true
+
+		typ, notes, decimal, err := d.fieldType(f.typ, f.opts, field)
+		if err != nil {
+			return fmt.Errorf("dynabuf: field %s of %s: %w", f.path, t, err)
+		}
+
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		d.g.field(b, "  ", typ, field, f.attr, i+1, notes, decimal)
+	}
+
+	b.WriteString("}\n")
+	return nil
+}
+
+// structFields returns the fields of struct type t stored as attributes,
+// with the fields of embedded structs promoted, as the attributevalue
+// package does.
+func structFields(t reflect.Type, prefix string) []structField {
+	var fields []structField
+	for i := range t.NumField() {
+		sf := t.Field(i)
+
+		tag := sf.Tag.Get("dynamodbav")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			fields = append(fields, structFields(ft, prefix+sf.Name+".")...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		f := structField{attr: name, typ: sf.Type, path: prefix + sf.Name}
+		if opts != "" {
+			f.opts = strings.Split(opts, ",")
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// fieldType returns the type of the field of Go type t with tag options
+// opts, with notes about how it is stored and whether it is a decimal
+// field. Messages of structs are named after the field if the struct has
+// no name.
+func (d *structDrafter) fieldType(t reflect.Type, opts []string, field string) (typ string, notes []string, decimal bool, err error) {
+	has := func(opt string) bool {
+		for _, o := range opts {
+			if o == opt {
+				return true
+			}
+		}
+		return false
+	}
+
+	marshaler := reflect.TypeFor[attributevalue.Marshaler]()
+	if t.Implements(marshaler) || reflect.PointerTo(t).Implements(marshaler) {
+		return d.g.value(), []string{fmt.Sprintf("%s has its own MarshalDynamoDBAttributeValue method.", t)}, false, nil
+	}
+
+	optional := ""
+	if t.Kind() == reflect.Pointer {
+		t, optional = t.Elem(), "optional "
+	}
+
+	if t == reflect.TypeFor[time.Time]() {
+		if has("unixtime") {
+			return "string", []string{"Epoch seconds, stored as a number."}, true, nil
+		}
+		d.g.imports["google/protobuf/timestamp.proto"] = true
+		return "google.protobuf.Timestamp", nil, false, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return optional + "string", nil, false, nil
+	case reflect.Bool:
+		return optional + "bool", nil, false, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		if has("string") {
+			return optional + "string", nil, false, nil
+		}
+		return optional + "int32", nil, false, nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		if has("string") {
+			return optional + "string", nil, false, nil
+		}
+		return optional + "uint32", nil, false, nil
+	case reflect.Int, reflect.Int64:
+		if has("string") {
+			return optional + "int64", nil, false, nil
+		}
+		return optional + "string", nil, true, nil
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		if has("string") {
+			return optional + "uint64", nil, false, nil
+		}
+		return optional + "string", nil, true, nil
+	case reflect.Float32:
+		return optional + "float", nil, false, nil
+	case reflect.Float64:
+		return optional + "double", nil, false, nil
+	case reflect.Interface:
+		return d.g.value(), nil, false, nil
+	case reflect.Struct:
+		return d.messageName(t, field), nil, false, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", []string{"Stored as binary by attributevalue, and as a base64 string by dynabuf."}, false, nil
+		}
+		for _, set := range []string{"stringset", "numberset", "binaryset"} {
+			if has(set) {
+				notes = append(notes, "Stored as a set by attributevalue, and as a list by dynabuf.")
+			}
+		}
+
+		elem, elemNotes, decimal, err := d.fieldType(t.Elem(), nil, field)
+		if err != nil {
+			return "", nil, false, err
+		}
+		if strings.HasPrefix(elem, "repeated ") || strings.HasPrefix(elem, "map<") {
+			d.g.imports["google/protobuf/struct.proto"] = true
+			return "google.protobuf.ListValue", notes, false, nil
+		}
+		return "repeated " + strings.TrimPrefix(elem, "optional "), append(notes, elemNotes...), decimal, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return "", nil, false, fmt.Errorf("map keys of type %s cannot be stored", t.Key())
+		}
+		elem, elemNotes, decimal, err := d.fieldType(t.Elem(), nil, field)
+		if err != nil {
+			return "", nil, false, err
+		}
+		if strings.HasPrefix(elem, "repeated ") || strings.HasPrefix(elem, "map<") {
+			d.g.imports["google/protobuf/struct.proto"] = true
+			return "google.protobuf.Struct", nil, false, nil
+		}
+		return "map<string, " + strings.TrimPrefix(elem, "optional ") + ">", elemNotes, decimal, nil
+	}
+	return "", nil, false, fmt.Errorf("values of type %s cannot be stored", t)
+}
@@ -0,0 +1,105 @@
+package dynabuf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/picatz/dynabuf"
+	"github.com/shoenig/test/must"
+)
+
+type draftBase struct {
+	PK string `dynamodbav:"pk"`
+	SK string `dynamodbav:"sk"`
+}
+
+type draftAddress struct {
+	Street string
+	Zip    int32 `dynamodbav:"zip,string"`
+}
+
+type draftUser struct {
+	draftBase
+
+	Name      string            `dynamodbav:"name,omitempty"`
+	Age       *int32            `dynamodbav:"age"`
+	Balance   int64             `dynamodbav:"balance"`
+	Version   int64             `dynamodbav:"version,string"`
+	Score     float64           `dynamodbav:"score"`
+	Created   time.Time         `dynamodbav:"created"`
+	Expires   time.Time         `dynamodbav:"ttl,unixtime"`
+	Tags      []string          `dynamodbav:"tags,stringset"`
+	Avatar    []byte            `dynamodbav:"avatar"`
+	Home      *draftAddress     `dynamodbav:"home"`
+	Addresses []draftAddress    `dynamodbav:"addresses"`
+	Labels    map[string]string `dynamodbav:"labels"`
+	Extra     any               `dynamodbav:"extra"`
+	Ignored   string            `dynamodbav:"-"`
+
+	secret string
+}
+
+func TestDraftStructProto(t *testing.T) {
+	draft, err := dynabuf.DraftStructProto("example.v1", &draftUser{})
+	must.NoError(t, err)
+
+	must.Eq(t, `syntax = "proto3";
+
+package example.v1;
+
+import "dynabufpb/options.proto";
+import "google/protobuf/struct.proto";
+import "google/protobuf/timestamp.proto";
+
+// DraftUser is a draft of the Go struct dynabuf_test.draftUser.
+message DraftUser {
+  string pk = 1;
+
+  string sk = 2;
+
+  string name = 3;
+
+  optional int32 age = 4;
+
+  string balance = 5 [(dynabuf.v1.field) = {decimal: true}];
+
+  int64 version = 6;
+
+  double score = 7;
+
+  google.protobuf.Timestamp created = 8;
+
+  // Epoch seconds, stored as a number.
+  string ttl = 9 [(dynabuf.v1.field) = {decimal: true}];
+
+  // Stored as a set by attributevalue, and as a list by dynabuf.
+  repeated string tags = 10;
+
+  // Stored as binary by attributevalue, and as a base64 string by dynabuf.
+  bytes avatar = 11;
+
+  DraftAddress home = 12;
+
+  repeated DraftAddress addresses = 13;
+
+  map<string, string> labels = 14;
+
+  google.protobuf.Value extra = 15;
+}
+
+// DraftAddress is a draft of the Go struct dynabuf_test.draftAddress.
+message DraftAddress {
+  string street = 1 [json_name = "Street"];
+
+  string zip = 2;
+}
+`, draft)
+}
+
+func TestDraftStructProtoErrors(t *testing.T) {
+	_, err := dynabuf.DraftStructProto("example.v1", "user")
+	must.ErrorContains(t, err, "string is not a struct")
+
+	_, err = dynabuf.DraftStructProto("example.v1", struct{ C chan int }{})
+	must.ErrorContains(t, err, "field C of struct { C chan int }: values of type chan int cannot be stored")
+}
@@ -0,0 +1,108 @@
+package dynabuf
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DryRunWrite describes a write operation skipped by [WithDryRun].
+type DryRunWrite struct {
+	// Operation is the name of the skipped DynamoDB operation, such as "PutItem".
+	Operation string
+
+	// Input is the input of the skipped operation, such as a
+	// *dynamodb.PutItemInput holding the marshaled item.
+	Input any
+}
+
+// WithDryRun returns a DynamoDB client option that short-circuits all write
+// operations, passing them to record instead of sending them to DynamoDB.
+// Skipped operations return an empty output and no error. Read operations
+// are sent as usual.
+//
+// This allows deployment pipelines and tests to verify what would be
+// written to a table without touching it.
+//
+// The skipped operations are PutItem, UpdateItem, DeleteItem,
+// BatchWriteItem, and TransactWriteItems.
+//
+// # Example
+//
+//	var recorder dynabuf.DryRunRecorder
+//
+//	client := dynamodb.NewFromConfig(cfg, dynabuf.WithDryRun(recorder.Record))
+//
+//	item, _ := dynabuf.Marshal(user)
+//
+//	client.PutItem(ctx, &dynamodb.PutItemInput{
+//	  TableName: aws.String("users"),
+//	  Item:      item.(map[string]types.AttributeValue),
+//	})
+//
+//	fmt.Println(len(recorder.Writes()))
+//	// 1
+func WithDryRun(record func(ctx context.Context, w DryRunWrite)) func(*dynamodb.Options) {
+	return func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("DynabufDryRun", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				var (
+					operation string
+					result    any
+				)
+
+				switch in.Parameters.(type) {
+				case *dynamodb.PutItemInput:
+					operation, result = "PutItem", &dynamodb.PutItemOutput{}
+				case *dynamodb.UpdateItemInput:
+					operation, result = "UpdateItem", &dynamodb.UpdateItemOutput{}
+				case *dynamodb.DeleteItemInput:
+					operation, result = "DeleteItem", &dynamodb.DeleteItemOutput{}
+				case *dynamodb.BatchWriteItemInput:
+					operation, result = "BatchWriteItem", &dynamodb.BatchWriteItemOutput{}
+				case *dynamodb.TransactWriteItemsInput:
+					operation, result = "TransactWriteItems", &dynamodb.TransactWriteItemsOutput{}
+				default:
+					return next.HandleInitialize(ctx, in)
+				}
+
+				if record != nil {
+					record(ctx, DryRunWrite{
+						Operation: operation,
+						Input:     in.Parameters,
+					})
+				}
+
+				return middleware.InitializeOutput{Result: result}, middleware.Metadata{}, nil
+			}), middleware.Before)
+		})
+	}
+}
+
+// DryRunRecorder collects the writes skipped by [WithDryRun], by passing
+// its [DryRunRecorder.Record] method to it.
+//
+// The zero value is ready to use, and a DryRunRecorder is safe for
+// concurrent use.
+type DryRunRecorder struct {
+	mu     sync.Mutex
+	writes []DryRunWrite
+}
+
+// Record records a skipped write.
+func (r *DryRunRecorder) Record(_ context.Context, w DryRunWrite) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.writes = append(r.writes, w)
+}
+
+// Writes returns the recorded writes, in the order they were skipped.
+func (r *DryRunRecorder) Writes() []DryRunWrite {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]DryRunWrite(nil), r.writes...)
+}
@@ -0,0 +1,94 @@
+package dynabuf_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+// httpClientFunc is an HTTP client for the AWS SDK that answers requests
+// with a function instead of the network.
+type httpClientFunc func(*http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// newTestClient returns a DynamoDB client whose HTTP requests are answered
+// with the given JSON body, counting the requests sent.
+func newTestClient(body string, requests *int, optFns ...func(*dynamodb.Options)) *dynamodb.Client {
+	return dynamodb.New(dynamodb.Options{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+		HTTPClient: httpClientFunc(func(r *http.Request) (*http.Response, error) {
+			*requests++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		}),
+	}, optFns...)
+}
+
+func TestWithDryRun(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		recorder dynabuf.DryRunRecorder
+		requests int
+	)
+
+	client := newTestClient(`{"Item":{"id":{"S":"1"}}}`, &requests, dynabuf.WithDryRun(recorder.Record))
+
+	item, err := dynabuf.Marshal(&testpb.User{Id: "1", Name: "Alice"})
+	must.NoError(t, err)
+
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String("users"),
+		Item:      item.(map[string]types.AttributeValue),
+	})
+	must.NoError(t, err)
+
+	_, err = client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String("users"),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: "1"},
+		},
+	})
+	must.NoError(t, err)
+
+	must.Eq(t, 0, requests)
+
+	writes := recorder.Writes()
+	must.SliceLen(t, 2, writes)
+	must.Eq(t, "PutItem", writes[0].Operation)
+	must.Eq(t, "DeleteItem", writes[1].Operation)
+
+	var user testpb.User
+	must.NoError(t, dynabuf.Unmarshal(writes[0].Input.(*dynamodb.PutItemInput).Item, &user))
+	must.Eq(t, "Alice", user.GetName())
+
+	// Reads are still sent.
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("users"),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: "1"},
+		},
+	})
+	must.NoError(t, err)
+	must.Eq(t, 1, requests)
+	must.MapContainsKeys(t, out.Item, []string{"id"})
+	must.SliceLen(t, 2, recorder.Writes())
+}
@@ -6,9 +6,7 @@ import (
 	"fmt"
 	"reflect"
 
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -37,6 +35,18 @@ var (
 // protobuf message or slice of messages. If there are any issues with
 // marshaling, an error is returned.
 //
+// Slices may also be arrays, such as a [2]*example.User, or pointers to
+// slices or arrays, such as a *[]*example.User, hold message values, such
+// as a []example.User, or be iterators, such as an
+// iter.Seq[proto.Message], which are encoded as slices of items. Arrays of
+// message values are passed by pointer, such as a *[2]example.User, since
+// messages must not be copied.
+//
+// A map of messages keyed by strings, such as a map[string]*example.User,
+// is encoded as a *types.AttributeValueMemberM holding the item of each
+// message under its key, for collections keyed outside of a message, or
+// into an item per message with [WithMapItems].
+//
 // # Protocol Buffer to DynamoDB Attribute Value Marshaling
 //
 // We use a three-step process to marshal a protobuf message to a DynamoDB
@@ -54,6 +64,21 @@ var (
 // The process is similar for a slice of protobuf messages, but the function
 // iterates over each message in the slice and marshals them individually.
 //
+// Numbers are stored with the shortest representation that round-trips to
+// the same value, which [WithFloatFormat] changes for floats, and string
+// fields annotated as decimal are stored as numbers:
+//
+//	string amount = 1 [(dynabuf.v1.field) = { decimal: true }];
+//
+// The google.type.Date and google.type.TimeOfDay messages are stored as
+// strings sorting chronologically, such as "2024-03-01" and "09:30:00",
+// so they can be compared and used as sort keys. The google.type.Money
+// message is stored as a map of its units, nanos, and currency code, or of
+// its amount, as a number, and currency code if annotated as decimal.
+//
+// [MarshalMap] and [MarshalList] return the items of a message and of a
+// slice of messages without a type assertion.
+//
 // # Example
 //
 //	import (
@@ -79,59 +104,103 @@ var (
 // [DynamoDB]: https://aws.amazon.com/dynamodb/
 // [attribute value]: https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_AttributeValue.html
 // [JSON]: https://protobuf.dev/programming-guides/proto3/#json
-func Marshal(v any) (any, error) {
-	if reflect.ValueOf(v).Kind() == reflect.Slice {
-		return marshalProtoSlice(v)
+func Marshal(v any, opts ...Option) (any, error) {
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return marshalProtoSlice(v, opts...)
+	case reflect.Ptr:
+		if isProtoListPtr(rv) {
+			return marshalProtoSlice(v, opts...)
+		}
+	case reflect.Func:
+		return marshalProtoSeq(v, opts...)
+	case reflect.Map:
+		return marshalProtoMap(v, opts...)
 	}
 
-	return marshalProtoMessage(v)
+	return marshalProtoMessage(v, opts...)
+}
+
+// MarshalMap is like [Marshal] for a single message, returning its item.
+//
+// # Example
+//
+//	item, err := dynabuf.MarshalMap(&example.User{Id: "123"})
+func MarshalMap(msg proto.Message, opts ...Option) (map[string]types.AttributeValue, error) {
+	return marshalProtoMessage(msg, opts...)
+}
+
+// MarshalList is like [Marshal] for a slice of messages, such as a
+// []*example.User, or an array, pointer to a slice, or iterator of
+// messages, returning their items.
+//
+// # Example
+//
+//	items, err := dynabuf.MarshalList(users)
+func MarshalList(v any, opts ...Option) ([]map[string]types.AttributeValue, error) {
+	if reflect.ValueOf(v).Kind() == reflect.Func {
+		return marshalProtoSeq(v, opts...)
+	}
+	return marshalProtoSlice(v, opts...)
 }
 
 // marshalProtoMessage handles marshaling of a single protobuf message
 // to a DynamoDB attribute value. It returns the DynamoDB attribute value
 // map or an error if there are any issues.
-func marshalProtoMessage(v any) (map[string]types.AttributeValue, error) {
+func marshalProtoMessage(v any, opts ...Option) (map[string]types.AttributeValue, error) {
 	if !isProtoMessage(v) {
 		return nil, fmt.Errorf("%w: %w: %T", ErrFailedToMarshal, ErrInvalidInput, v)
 	}
 
-	b, err := protojson.Marshal(v.(proto.Message))
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
-	}
+	msg := v.(proto.Message)
+	o := newOptions(opts)
 
-	var intermediary map[string]any
-	err = json.Unmarshal(b, &intermediary)
+	item, err := MarshalTo(o.attributeValues(), msg, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w: %w", ErrFailedToMarshal, ErrFailedToUnmarshalIntermediary, err)
+		return nil, err
 	}
 
-	av, err := attributevalue.MarshalMap(intermediary)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+	if o.normalizeKeys {
+		o.normalizeKeyAttributes(msg.ProtoReflect().Descriptor(), item)
 	}
-
-	return av, nil
+	if o.checkLimits {
+		if err := checkLimits(item); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+		}
+	}
+	if o.fieldStats != nil {
+		o.fieldStats.record(msg, item)
+	}
+	return item, nil
 }
 
-// marshalProtoSlice handles marshaling of a slice of protobuf messages to
-// a slice of DynamoDB attribute values. It returns the DynamoDB attribute
-// value slice or an error if there are any issues.
-func marshalProtoSlice(v any) ([]map[string]types.AttributeValue, error) {
+// marshalProtoSlice handles marshaling of a slice or array of protobuf
+// messages, or of message values, to a slice of DynamoDB attribute values.
+// It returns the DynamoDB attribute value slice or an error if there are
+// any issues.
+func marshalProtoSlice(v any, opts ...Option) ([]map[string]types.AttributeValue, error) {
 	sliceValue := reflect.ValueOf(v)
-	if sliceValue.Kind() != reflect.Slice {
+	if isProtoListPtr(sliceValue) {
+		sliceValue = sliceValue.Elem()
+	}
+	if !isProtoList(sliceValue) {
 		return nil, fmt.Errorf("%w: %w: %T", ErrFailedToMarshal, ErrInvalidInput, v)
 	}
 
-	if !isProtoSlice(sliceValue) {
-		return nil, fmt.Errorf("%w: %w: %T", ErrFailedToMarshal, ErrInvalidInput, v)
+	// Messages must not be copied, so those of arrays of message values
+	// held by interfaces, which cannot be addressed, are rejected.
+	if !sliceValue.CanAddr() && sliceValue.Kind() == reflect.Array && sliceValue.Type().Elem().Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("%w: %w: array of message values %T cannot be addressed, use a pointer to it or a slice", ErrFailedToMarshal, ErrInvalidInput, v)
 	}
 
 	result := make([]map[string]types.AttributeValue, sliceValue.Len())
 
 	for i := 0; i < sliceValue.Len(); i++ {
-		item := sliceValue.Index(i).Interface()
-		av, err := marshalProtoMessage(item)
+		item := sliceValue.Index(i)
+		if item.Kind() != reflect.Ptr {
+			item = item.Addr()
+		}
+		av, err := marshalProtoMessage(item.Interface(), opts...)
 		if err != nil {
 			return nil, fmt.Errorf("%w: at index %d: %w", ErrFailedToMarshal, i, err)
 		}
@@ -141,6 +210,40 @@ func marshalProtoSlice(v any) ([]map[string]types.AttributeValue, error) {
 	return result, nil
 }
 
+// marshalProtoSeq handles marshaling of an iterator of protobuf messages,
+// such as an iter.Seq[proto.Message] or an iter.Seq[*example.User], to a
+// slice of DynamoDB attribute values.
+func marshalProtoSeq(v any, opts ...Option) ([]map[string]types.AttributeValue, error) {
+	seqValue := reflect.ValueOf(v)
+	if !isProtoSeq(seqValue) {
+		return nil, fmt.Errorf("%w: %w: %T", ErrFailedToMarshal, ErrInvalidInput, v)
+	}
+
+	var (
+		result []map[string]types.AttributeValue
+		err    error
+	)
+	yieldType := seqValue.Type().In(0)
+	yield := reflect.MakeFunc(yieldType, func(args []reflect.Value) []reflect.Value {
+		if err != nil {
+			return []reflect.Value{reflect.ValueOf(false)}
+		}
+		msg, _ := args[0].Interface().(proto.Message)
+		var av map[string]types.AttributeValue
+		if av, err = marshalProtoMessage(msg, opts...); err != nil {
+			err = fmt.Errorf("%w: at index %d: %w", ErrFailedToMarshal, len(result), err)
+			return []reflect.Value{reflect.ValueOf(false)}
+		}
+		result = append(result, av)
+		return []reflect.Value{reflect.ValueOf(true)}
+	})
+	seqValue.Call([]reflect.Value{yield})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // isProtoMessage checks if the given any is a protobuf message type
 // by asserting it as a [proto.Message] interface.
 //
@@ -165,9 +268,51 @@ func isProtoSlice(v reflect.Value) bool {
 	return isProtoMessage(reflect.New(elemType.Elem()).Interface())
 }
 
+// isProtoList reports whether v is a slice or array of protobuf messages,
+// such as a []*example.User or a [2]*example.User, or of message values,
+// such as a []example.User.
+func isProtoList(v reflect.Value) bool {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false
+	}
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	return isProtoMessage(reflect.New(elemType).Interface())
+}
+
+// isProtoListPtr reports whether v is a non-nil pointer to a slice or
+// array of protobuf messages, such as a *[]*example.User.
+func isProtoListPtr(v reflect.Value) bool {
+	return v.Kind() == reflect.Ptr && !v.IsNil() && isProtoList(v.Elem())
+}
+
+// isProtoSeq reports whether v is an iterator of protobuf messages, such
+// as an iter.Seq[proto.Message] or an iter.Seq[*example.User].
+func isProtoSeq(v reflect.Value) bool {
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return false
+	}
+	t := v.Type()
+	if t.NumIn() != 1 || t.NumOut() != 0 {
+		return false
+	}
+	yield := t.In(0)
+	if yield.Kind() != reflect.Func || yield.NumIn() != 1 || yield.NumOut() != 1 || yield.Out(0).Kind() != reflect.Bool {
+		return false
+	}
+	return yield.In(0).Implements(reflect.TypeFor[proto.Message]())
+}
+
 // Unmarshal parses the [DynamoDB] attribute values in av and stores the result in v.
 // v must be a pointer to a single protobuf message or a slice of protobuf messages.
-// If there are any issues with unmarshaling, an error is returned.
+// If there are any issues with unmarshaling, an error is returned. Optional
+// behavior can be configured with opts.
+//
+// v may also point to a map of messages keyed by strings, such as a
+// map[string]*example.User, to read back the map attributes [Marshal]
+// encodes such maps as.
 //
 // # DynamoDB Attribute Value to Protocol Buffer Unmarshaling
 //
@@ -186,6 +331,15 @@ func isProtoSlice(v reflect.Value) bool {
 // The process is similar for a slice of protobuf messages, but the function
 // iterates over each item in the slice and unmarshals them individually.
 //
+// A single attribute value other than a map, such as an attribute read
+// from an item, is stored in v too: google.protobuf messages, such as
+// google.protobuf.Value and the wrapper types, hold the value as they
+// would in JSON, and other messages with a single field hold it in that
+// field.
+//
+//	var name wrapperspb.StringValue
+//	_ = dynabuf.Unmarshal(item["name"], &name)
+//
 // # Example
 //
 //	import (
@@ -206,12 +360,17 @@ func isProtoSlice(v reflect.Value) bool {
 // [DynamoDB]: https://aws.amazon.com/dynamodb/
 // [attribute value]: https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_AttributeValue.html
 // [JSON]: https://protobuf.dev/programming-guides/proto3/#json
-func Unmarshal(av any, v any) error {
+func Unmarshal(av any, v any, opts ...Option) error {
+	o := newOptions(opts)
+
 	vValue := reflect.ValueOf(v)
 	if vValue.Kind() != reflect.Ptr {
 		return fmt.Errorf("%w: %w: %T", ErrFailedToUnmarshal, ErrInvalidOutput, v)
 	}
 	vElem := vValue.Elem()
+	if isProtoMap(vElem) {
+		return unmarshalProtoMap(av, vElem, opts...)
+	}
 	isSlice := vElem.Kind() == reflect.Slice
 
 	if isSlice && !isProtoSlice(vElem) || !isSlice && !isProtoMessage(v) {
@@ -221,28 +380,62 @@ func Unmarshal(av any, v any) error {
 	var intermediateValue any
 	switch typedAV := av.(type) {
 	case types.AttributeValue:
-		intermediateValue = make(map[string]any)
-		err := attributevalue.Unmarshal(typedAV, &intermediateValue)
+		if _, ok := typedAV.(*types.AttributeValueMemberM); !ok && !isSlice {
+			data, err := scalarJSON(typedAV, v.(proto.Message))
+			if err != nil {
+				return fmt.Errorf("%w: failed to unmarshal DynamoDB attribute value: %w", ErrFailedToUnmarshal, err)
+			}
+			if err := unmarshalJSONToProto(data, v.(proto.Message), o); err != nil {
+				return fmt.Errorf("%w: %w: %w", ErrFailedToUnmarshal, ErrFailedToUnmarshalIntermediary, err)
+			}
+			return nil
+		}
+		fields, err := o.attributeValues().decodeAttributeValue(typedAV)
 		if err != nil {
 			return fmt.Errorf("%w: failed to unmarshal DynamoDB attribute value: %w", ErrFailedToUnmarshal, err)
 		}
+		if !isSlice {
+			if err := runHooks(o.afterDecode, v.(proto.Message).ProtoReflect().Descriptor(), fields); err != nil {
+				return fmt.Errorf("%w: %w", ErrFailedToUnmarshal, err)
+			}
+		}
+		intermediateValue = fields
 	case map[string]types.AttributeValue:
-		intermediateValue = make(map[string]any)
-		err := attributevalue.UnmarshalMap(typedAV, &intermediateValue)
+		fields, err := o.attributeValues().DecodeItem(typedAV)
 		if err != nil {
 			return fmt.Errorf("%w: failed to unmarshal DynamoDB attribute map: %w", ErrFailedToUnmarshal, err)
 		}
+		if o.fieldStats != nil && !isSlice {
+			o.fieldStats.record(v.(proto.Message), typedAV)
+		}
+		if !isSlice {
+			if err := runHooks(o.afterDecode, v.(proto.Message).ProtoReflect().Descriptor(), fields); err != nil {
+				return fmt.Errorf("%w: %w", ErrFailedToUnmarshal, err)
+			}
+		}
+		intermediateValue = fields
 	case []map[string]types.AttributeValue:
 		if !isSlice {
 			return fmt.Errorf("%w: %w: %T", ErrFailedToUnmarshal, ErrInvalidOutput, v)
 		}
-		intermediateValue = make([]map[string]any, len(typedAV))
+		items := make([]map[string]any, len(typedAV))
 		for i, item := range typedAV {
-			err := attributevalue.UnmarshalMap(item, &intermediateValue.([]map[string]any)[i])
+			fields, err := o.attributeValues().DecodeItem(item)
 			if err != nil {
 				return fmt.Errorf("%w: failed to unmarshal DynamoDB attribute map: %w", ErrFailedToUnmarshal, err)
 			}
+			if o.fieldStats != nil {
+				o.fieldStats.record(reflect.New(vElem.Type().Elem().Elem()).Interface().(proto.Message), item)
+			}
+			if len(o.afterDecode) > 0 {
+				md := reflect.New(vElem.Type().Elem().Elem()).Interface().(proto.Message).ProtoReflect().Descriptor()
+				if err := runHooks(o.afterDecode, md, fields); err != nil {
+					return fmt.Errorf("%w: at index %d: %w", ErrFailedToUnmarshal, i, err)
+				}
+			}
+			items[i] = fields
 		}
+		intermediateValue = items
 	default:
 		return fmt.Errorf("%w: %w: unsupported type: %T", ErrFailedToUnmarshal, ErrInvalidOutput, v)
 	}
@@ -253,9 +446,9 @@ func Unmarshal(av any, v any) error {
 	}
 
 	if isSlice {
-		err = unmarshalJSONToProtoSlice(intermediateBytes, v)
+		err = unmarshalJSONToProtoSlice(intermediateBytes, v, o)
 	} else {
-		err = protojson.Unmarshal(intermediateBytes, v.(proto.Message))
+		err = unmarshalJSONToProto(intermediateBytes, v.(proto.Message), o)
 	}
 	if err != nil {
 		return fmt.Errorf("%w: %w: %w", ErrFailedToUnmarshal, ErrFailedToUnmarshalIntermediary, err)
@@ -264,8 +457,52 @@ func Unmarshal(av any, v any) error {
 	return nil
 }
 
+// unmarshalJSONToProto unmarshals JSON data to a protobuf message, recording
+// the fields that failed to unmarshal in the configured failure metrics, and
+// reporting the lossy conversions to the configured diagnostics.
+func unmarshalJSONToProto(data []byte, msg proto.Message, o *options) error {
+	data, err := o.stripMetadata(msg.ProtoReflect().Descriptor(), data)
+	if err != nil {
+		return err
+	}
+
+	if o.structKeys == StructKeyEscape && isStruct(msg.ProtoReflect().Descriptor()) {
+		if data, err = o.unescapeStructKeys(data); err != nil {
+			return err
+		}
+	}
+
+	if o.caseCollisions != 0 {
+		var err error
+		if data, err = o.foldCase(msg.ProtoReflect().Descriptor(), data); err != nil {
+			return err
+		}
+	}
+
+	if o.emptyMessages == EmptyMessagePlaceholder || o.emptyMessages == EmptyMessageNull {
+		var err error
+		if data, err = decodeEmptyMessages(msg.ProtoReflect().Descriptor(), data, o.emptyMessages); err != nil {
+			return err
+		}
+	}
+
+	converted, err := protoJSON(data, msg.ProtoReflect().Descriptor())
+	if err != nil {
+		return err
+	}
+
+	err = o.protoJSONUnmarshal().Unmarshal(converted, msg)
+	if err != nil && o.failureMetrics != nil {
+		o.failureMetrics.record(msg, converted)
+	}
+	if err == nil && o.diagnostics != nil {
+		o.diagnoseStored(msg.ProtoReflect().Descriptor(), data)
+	}
+	return err
+}
+
 // unmarshalJSONToProtoSlice unmarshals JSON data to a slice of protobuf messages
-func unmarshalJSONToProtoSlice(data []byte, v any) error {
+func unmarshalJSONToProtoSlice(data []byte, v any, o *options) error {
 	slice := reflect.ValueOf(v).Elem()
 	var jsonSlice []json.RawMessage
 	if err := json.Unmarshal(data, &jsonSlice); err != nil {
@@ -274,8 +511,20 @@ func unmarshalJSONToProtoSlice(data []byte, v any) error {
 
 	for _, item := range jsonSlice {
 		elemType := slice.Type().Elem()
-		elem := reflect.New(elemType.Elem()).Interface().(proto.Message)
-		if err := protojson.Unmarshal(item, elem); err != nil {
+		var elem proto.Message
+		if o.pool != nil {
+			elem = o.pool.New()
+			if reflect.TypeOf(elem) != elemType {
+				o.pool.Reset(elem)
+				return fmt.Errorf("dynabuf: message pool returned %T, rather than %v", elem, elemType)
+			}
+		} else {
+			elem = reflect.New(elemType.Elem()).Interface().(proto.Message)
+		}
+		if err := unmarshalJSONToProto(item, elem, o); err != nil {
+			if o.pool != nil {
+				o.pool.Reset(elem)
+			}
 			return err
 		}
 		slice.Set(reflect.Append(slice, reflect.ValueOf(elem)))
@@ -1,12 +1,23 @@
 package dynabuf_test
 
 import (
+	"errors"
+	"iter"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
 	"github.com/shoenig/test/must"
+	"google.golang.org/genproto/googleapis/type/date"
+	"google.golang.org/genproto/googleapis/type/money"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protocmp"
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // TestMarshal tests the Marshal function with a struct from the
@@ -91,6 +102,71 @@ func TestMarshal(t *testing.T) {
 	}
 }
 
+func TestMarshalMapAndList(t *testing.T) {
+	users := []*testpb.User{{Id: "1"}, {Id: "2"}}
+
+	item, err := dynabuf.MarshalMap(users[0])
+	must.NoError(t, err)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "1"}, item["id"])
+
+	items, err := dynabuf.MarshalList(users)
+	must.NoError(t, err)
+	must.SliceLen(t, 2, items)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "2"}, items[1]["id"])
+
+	_, err = dynabuf.MarshalList(users[0])
+	must.ErrorIs(t, err, dynabuf.ErrInvalidInput)
+}
+
+func TestMarshalListShapes(t *testing.T) {
+	users := []*testpb.User{{Id: "1"}, {Id: "2"}}
+
+	tests := []struct {
+		name  string
+		input any
+	}{
+		{name: "array", input: [2]*testpb.User{users[0], users[1]}},
+		{name: "value slice", input: []testpb.User{{Id: "1"}, {Id: "2"}}},
+		{name: "pointer to slice", input: &users},
+		{name: "pointer to array", input: &[2]*testpb.User{users[0], users[1]}},
+		{name: "pointer to value array", input: &[2]testpb.User{{Id: "1"}, {Id: "2"}}},
+		{name: "typed iterator", input: slices.Values(users)},
+		{name: "message iterator", input: iter.Seq[proto.Message](func(yield func(proto.Message) bool) {
+			for _, user := range users {
+				if !yield(user) {
+					return
+				}
+			}
+		})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := dynabuf.Marshal(test.input)
+			must.NoError(t, err)
+			items, ok := out.([]map[string]types.AttributeValue)
+			must.True(t, ok)
+			must.SliceLen(t, 2, items)
+			must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "2"}, items[1]["id"])
+		})
+	}
+
+	// Arrays of message values cannot be addressed without copying them.
+	_, err := dynabuf.Marshal([2]testpb.User{{Id: "1"}, {Id: "2"}})
+	must.ErrorIs(t, err, dynabuf.ErrInvalidInput)
+
+	// Nil pointers are not slices.
+	var nilUsers *[]*testpb.User
+	_, err = dynabuf.Marshal(nilUsers)
+	must.ErrorIs(t, err, dynabuf.ErrInvalidInput)
+
+	// Iterators stop at the first message failing to marshal.
+	_, err = dynabuf.MarshalList(slices.Values([]*testpb.Account{{Id: "1"}}), dynabuf.WithBeforeEncode(func(protoreflect.MessageDescriptor, map[string]any) error {
+		return errors.New("rejected")
+	}))
+	must.ErrorIs(t, err, dynabuf.ErrFailedToMarshal)
+}
+
 func TestUnmarshal(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -285,3 +361,77 @@ func TestRoudtrip(t *testing.T) {
 		})
 	}
 }
+
+// catalog returns a message with lists and maps nested in each other,
+// through the messages of their values, holding every kind of value.
+func catalog(t *testing.T) *testpb.Catalog {
+	t.Helper()
+
+	list := func(values ...any) *structpb.ListValue {
+		l, err := structpb.NewList(values)
+		must.NoError(t, err)
+		return l
+	}
+
+	shelf := &testpb.Catalog_Shelf{
+		Labels:   []string{"a", "", "c"},
+		Blobs:    [][]byte{[]byte("x"), {}, []byte("zz")},
+		Products: []*testpb.Product{{Id: "p", Price: "1.10", Discounts: []string{"0.5", "0.25"}}, {}},
+		Entries: map[string]*testpb.Entry{
+			"e":     {Sequence: -5, Time: timestamppb.New(time.Unix(10, 0))},
+			"empty": {},
+		},
+	}
+
+	return &testpb.Catalog{
+		Id:       "1",
+		Shelves:  map[string]*testpb.Catalog_Shelf{"top": shelf, "empty": {}},
+		Products: map[int64]*testpb.Product{-1: {Price: "12345678901234567890.123"}, 7: {}},
+		Flagged:  map[bool]*testpb.Catalog_Shelf{true: shelf, false: {Labels: []string{"f"}}},
+		Dates:    map[string]*date.Date{"due": {Year: 2024, Month: 3, Day: 1}},
+		Prices:   map[string]*money.Money{"usd": {CurrencyCode: "USD", Units: 3, Nanos: 5}},
+		Values: map[string]*structpb.Value{
+			"null": structpb.NewNullValue(),
+			"list": structpb.NewListValue(list(1, "a", []any{true})),
+		},
+		Blobs: map[string][]byte{"b": []byte("hi"), "empty": {}},
+		Rows: []*testpb.Catalog_Row{
+			{
+				Shelves: []*testpb.Catalog_Shelf{shelf, {}},
+				Cells:   []*structpb.ListValue{list(1, 2), list(), list([]any{"x"})},
+			},
+			{},
+		},
+		Matrix: list([]any{1, 2}, []any{3, []any{4}}, []any{}),
+	}
+}
+
+func TestNestedShapes(t *testing.T) {
+	msg := catalog(t)
+
+	av, err := dynabuf.Marshal(msg)
+	must.NoError(t, err)
+	item := av.(map[string]types.AttributeValue)
+
+	// Maps of messages are stored as maps of maps, with keys of any type
+	// as strings, and lists of lists as lists of lists.
+	shelves := item["shelves"].(*types.AttributeValueMemberM).Value
+	must.MapContainsKeys(t, shelves, []string{"top", "empty"})
+	must.Eq(t, map[string]types.AttributeValue{}, shelves["empty"].(*types.AttributeValueMemberM).Value)
+	must.MapContainsKeys(t, item["products"].(*types.AttributeValueMemberM).Value, []string{"-1", "7"})
+	must.MapContainsKeys(t, item["flagged"].(*types.AttributeValueMemberM).Value, []string{"true", "false"})
+	matrix := item["matrix"].(*types.AttributeValueMemberL).Value
+	must.Len(t, 3, matrix)
+	must.Len(t, 2, matrix[1].(*types.AttributeValueMemberL).Value)
+
+	// Fields converted at the top level are converted in the messages of
+	// map values and lists too.
+	top := shelves["top"].(*types.AttributeValueMemberM).Value
+	product := top["products"].(*types.AttributeValueMemberL).Value[0].(*types.AttributeValueMemberM).Value
+	must.Eq(t, "1.10", product["price"].(*types.AttributeValueMemberN).Value)
+	must.Eq(t, "2024-03-01", item["dates"].(*types.AttributeValueMemberM).Value["due"].(*types.AttributeValueMemberS).Value)
+
+	out := &testpb.Catalog{}
+	must.NoError(t, dynabuf.Unmarshal(item, out))
+	must.Eq(t, msg, out, must.Cmp(protocmp.Transform()))
+}
@@ -0,0 +1,14 @@
+// Package dynabufpb contains the protobuf options used to annotate
+// messages, fields, and enums with dynabuf specific behavior, and the
+// [MappingSpec] message describing how messages map to DynamoDB items.
+//
+// The options are defined in dynabufpb/options.proto, which can be
+// imported from other proto files once it is on the include path:
+//
+//	import "dynabufpb/options.proto";
+//
+//	enum State {
+//	  STATE_UNSPECIFIED = 0 [(dynabuf.v1.enum_value) = { transitions: ["STATE_RUNNING"] }];
+//	  STATE_RUNNING = 1;
+//	}
+package dynabufpb
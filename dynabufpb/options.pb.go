@@ -0,0 +1,780 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: dynabufpb/options.proto
+
+package dynabufpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// MessageOptions are dynabuf options that can be set on messages.
+//
+//	message Account {
+//	  option (dynabuf.v1.message) = {
+//	    snapshot: { every: 100 }
+//	    history: { retention: { seconds: 2592000 } }
+//	  };
+//
+//	  string id = 1;
+//	  int64 balance = 2;
+//	}
+type MessageOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// How aggregates of this type are snapshotted, when the message is the
+	// state of an event sourced aggregate.
+	Snapshot *SnapshotOptions `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	// How long the version history of messages of this type is kept, when
+	// they are stored with their history.
+	History *HistoryOptions `protobuf:"bytes,2,opt,name=history,proto3" json:"history,omitempty"`
+	// Attributes composed of the values of several fields, such as sort
+	// keys, stored alongside the fields of items of this type.
+	CompositeAttributes []*CompositeAttribute `protobuf:"bytes,3,rep,name=composite_attributes,json=compositeAttributes,proto3" json:"composite_attributes,omitempty"`
+	// The names of the key attributes of the table storing items of this
+	// type, the partition key and then the sort key, if any, used by helpers
+	// working with the keys of items, such as deleting the items selected by
+	// a query.
+	//
+	//	option (dynabuf.v1.message) = {
+	//	  key_attributes: ["customer", "sk"]
+	//	};
+	KeyAttributes []string `protobuf:"bytes,4,rep,name=key_attributes,json=keyAttributes,proto3" json:"key_attributes,omitempty"`
+	// The name of the table storing items of this type, used by helpers
+	// building requests for messages, such as the input of PutItem.
+	//
+	//	option (dynabuf.v1.message) = {
+	//	  table: "orders"
+	//	};
+	Table string `protobuf:"bytes,5,opt,name=table,proto3" json:"table,omitempty"`
+}
+
+func (x *MessageOptions) Reset() {
+	*x = MessageOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dynabufpb_options_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MessageOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageOptions) ProtoMessage() {}
+
+func (x *MessageOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_dynabufpb_options_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageOptions.ProtoReflect.Descriptor instead.
+func (*MessageOptions) Descriptor() ([]byte, []int) {
+	return file_dynabufpb_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MessageOptions) GetSnapshot() *SnapshotOptions {
+	if x != nil {
+		return x.Snapshot
+	}
+	return nil
+}
+
+func (x *MessageOptions) GetHistory() *HistoryOptions {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *MessageOptions) GetCompositeAttributes() []*CompositeAttribute {
+	if x != nil {
+		return x.CompositeAttributes
+	}
+	return nil
+}
+
+func (x *MessageOptions) GetKeyAttributes() []string {
+	if x != nil {
+		return x.KeyAttributes
+	}
+	return nil
+}
+
+func (x *MessageOptions) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+// CompositeAttribute is a string attribute composed of the values of
+// several fields, such as a sort key sorting items by status, then
+// creation time:
+//
+//	option (dynabuf.v1.message) = {
+//	  composite_attributes: { name: "sk", template: "{status}#{create_time}#{id}" }
+//	};
+//
+// Separator characters, and backslashes, within field values are escaped
+// with a backslash, so the attribute can be parsed back into the fields
+// it is composed of, which are set from it when missing from an item, such
+// as items of indexes projecting only their keys.
+type CompositeAttribute struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The attribute name, which must not be the JSON name of a field.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The template of the attribute value, made of text and the names of
+	// top-level fields in braces, which must be separated by text.
+	Template string `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+}
+
+func (x *CompositeAttribute) Reset() {
+	*x = CompositeAttribute{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dynabufpb_options_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompositeAttribute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompositeAttribute) ProtoMessage() {}
+
+func (x *CompositeAttribute) ProtoReflect() protoreflect.Message {
+	mi := &file_dynabufpb_options_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompositeAttribute.ProtoReflect.Descriptor instead.
+func (*CompositeAttribute) Descriptor() ([]byte, []int) {
+	return file_dynabufpb_options_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CompositeAttribute) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CompositeAttribute) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+// SnapshotOptions configure the snapshots of an event sourced aggregate.
+type SnapshotOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The number of events after which a new snapshot is saved when the
+	// aggregate is loaded. Aggregates are never snapshotted if zero.
+	Every uint32 `protobuf:"varint,1,opt,name=every,proto3" json:"every,omitempty"`
+}
+
+func (x *SnapshotOptions) Reset() {
+	*x = SnapshotOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dynabufpb_options_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SnapshotOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotOptions) ProtoMessage() {}
+
+func (x *SnapshotOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_dynabufpb_options_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotOptions.ProtoReflect.Descriptor instead.
+func (*SnapshotOptions) Descriptor() ([]byte, []int) {
+	return file_dynabufpb_options_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SnapshotOptions) GetEvery() uint32 {
+	if x != nil {
+		return x.Every
+	}
+	return 0
+}
+
+// HistoryOptions configure the retention of the version history of a
+// message. Versions are only removed once they are no longer needed to read
+// the message as it was at any time within the retention period.
+type HistoryOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// How long versions are kept after being replaced by a newer version.
+	// Versions are kept forever if unset.
+	Retention *durationpb.Duration `protobuf:"bytes,1,opt,name=retention,proto3" json:"retention,omitempty"`
+	// The maximum number of versions kept, including the latest. All
+	// versions are kept if zero.
+	MaxVersions uint32 `protobuf:"varint,2,opt,name=max_versions,json=maxVersions,proto3" json:"max_versions,omitempty"`
+}
+
+func (x *HistoryOptions) Reset() {
+	*x = HistoryOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dynabufpb_options_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HistoryOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryOptions) ProtoMessage() {}
+
+func (x *HistoryOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_dynabufpb_options_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryOptions.ProtoReflect.Descriptor instead.
+func (*HistoryOptions) Descriptor() ([]byte, []int) {
+	return file_dynabufpb_options_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HistoryOptions) GetRetention() *durationpb.Duration {
+	if x != nil {
+		return x.Retention
+	}
+	return nil
+}
+
+func (x *HistoryOptions) GetMaxVersions() uint32 {
+	if x != nil {
+		return x.MaxVersions
+	}
+	return 0
+}
+
+// FieldOptions are dynabuf options that can be set on fields.
+//
+//	message User {
+//	  string id = 1 [(dynabuf.v1.field) = { volatile: true, immutable: true }];
+//	  string name = 2;
+//	  google.protobuf.Timestamp update_time = 3 [(dynabuf.v1.field) = { volatile: true, output_only: true }];
+//	}
+type FieldOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Whether the field's value is incidental to the item's content, such as
+	// generated identifiers and modification times, so it is ignored when
+	// comparing items by content.
+	Volatile bool `protobuf:"varint,1,opt,name=volatile,proto3" json:"volatile,omitempty"`
+	// Whether the field is only ever set by the service storing the message,
+	// such as creation times, so it is cleared from messages supplied by
+	// clients and never updated from them.
+	OutputOnly bool `protobuf:"varint,2,opt,name=output_only,json=outputOnly,proto3" json:"output_only,omitempty"`
+	// Whether the field can only be set when the message is created, so it
+	// is never updated afterwards.
+	Immutable bool `protobuf:"varint,3,opt,name=immutable,proto3" json:"immutable,omitempty"`
+	// Whether the string field holds a decimal number, such as an amount of
+	// money, so it is stored as a number, which DynamoDB compares and adds
+	// exactly, rather than as a string. Repeated string fields store each
+	// element as a number. The values must be JSON numbers, such as "12.30".
+	//
+	// google.type.Money fields annotated as decimal are stored as a map of
+	// their amount, as a number, and currency code, rather than of their
+	// units, nanos, and currency code.
+	Decimal bool `protobuf:"varint,4,opt,name=decimal,proto3" json:"decimal,omitempty"`
+	// Whether the field is stored as a string sorting as its values do, so
+	// it can be used as a sort key compared with begins_with and BETWEEN.
+	// Integers are zero-padded to a fixed width, floats stored as the hex
+	// digits of their bits, timestamps as zero-padded epoch seconds and
+	// nanoseconds, and UUIDs, such as time-ordered UUIDv7 ids, in lowercase
+	// canonical form, whether string or 16-byte bytes fields.
+	Sortable bool `protobuf:"varint,5,opt,name=sortable,proto3" json:"sortable,omitempty"`
+	// The attribute names the field was stored under before being renamed,
+	// which it is read from when items don't have an attribute named after
+	// the field, so items written before a rename are still read. The field
+	// is only ever written under its own name.
+	//
+	//	string display_name = 2 [(dynabuf.v1.field) = { aliases: ["name"] }];
+	Aliases []string `protobuf:"bytes,6,rep,name=aliases,proto3" json:"aliases,omitempty"`
+	// Whether the field is also written under its aliases when marshaling
+	// with the WithWriteAliases option enabled, so readers not yet reading
+	// the field's new name, such as older deployments during a rolling
+	// rename, still find the value under its former names.
+	WriteAliases bool `protobuf:"varint,7,opt,name=write_aliases,json=writeAliases,proto3" json:"write_aliases,omitempty"`
+}
+
+func (x *FieldOptions) Reset() {
+	*x = FieldOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dynabufpb_options_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FieldOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldOptions) ProtoMessage() {}
+
+func (x *FieldOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_dynabufpb_options_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldOptions.ProtoReflect.Descriptor instead.
+func (*FieldOptions) Descriptor() ([]byte, []int) {
+	return file_dynabufpb_options_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FieldOptions) GetVolatile() bool {
+	if x != nil {
+		return x.Volatile
+	}
+	return false
+}
+
+func (x *FieldOptions) GetOutputOnly() bool {
+	if x != nil {
+		return x.OutputOnly
+	}
+	return false
+}
+
+func (x *FieldOptions) GetImmutable() bool {
+	if x != nil {
+		return x.Immutable
+	}
+	return false
+}
+
+func (x *FieldOptions) GetDecimal() bool {
+	if x != nil {
+		return x.Decimal
+	}
+	return false
+}
+
+func (x *FieldOptions) GetSortable() bool {
+	if x != nil {
+		return x.Sortable
+	}
+	return false
+}
+
+func (x *FieldOptions) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+func (x *FieldOptions) GetWriteAliases() bool {
+	if x != nil {
+		return x.WriteAliases
+	}
+	return false
+}
+
+// EnumValueOptions are dynabuf options that can be set on enum values.
+//
+//	enum State {
+//	  STATE_UNSPECIFIED = 0 [(dynabuf.v1.enum_value) = { transitions: ["STATE_RUNNING"] }];
+//	  STATE_RUNNING = 1 [(dynabuf.v1.enum_value) = { transitions: ["STATE_SUCCEEDED", "STATE_FAILED"] }];
+//	  STATE_SUCCEEDED = 2;
+//	  STATE_FAILED = 3;
+//	}
+type EnumValueOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The names of the enum values a field in this state may transition to,
+	// when the enum is used as a state machine.
+	Transitions []string `protobuf:"bytes,1,rep,name=transitions,proto3" json:"transitions,omitempty"`
+}
+
+func (x *EnumValueOptions) Reset() {
+	*x = EnumValueOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dynabufpb_options_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EnumValueOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnumValueOptions) ProtoMessage() {}
+
+func (x *EnumValueOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_dynabufpb_options_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnumValueOptions.ProtoReflect.Descriptor instead.
+func (*EnumValueOptions) Descriptor() ([]byte, []int) {
+	return file_dynabufpb_options_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EnumValueOptions) GetTransitions() []string {
+	if x != nil {
+		return x.Transitions
+	}
+	return nil
+}
+
+var file_dynabufpb_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.EnumValueOptions)(nil),
+		ExtensionType: (*EnumValueOptions)(nil),
+		Field:         52301,
+		Name:          "dynabuf.v1.enum_value",
+		Tag:           "bytes,52301,opt,name=enum_value",
+		Filename:      "dynabufpb/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*FieldOptions)(nil),
+		Field:         52302,
+		Name:          "dynabuf.v1.field",
+		Tag:           "bytes,52302,opt,name=field",
+		Filename:      "dynabufpb/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*MessageOptions)(nil),
+		Field:         52303,
+		Name:          "dynabuf.v1.message",
+		Tag:           "bytes,52303,opt,name=message",
+		Filename:      "dynabufpb/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.EnumValueOptions.
+var (
+	// Options for the enum value.
+	//
+	// optional dynabuf.v1.EnumValueOptions enum_value = 52301;
+	E_EnumValue = &file_dynabufpb_options_proto_extTypes[0]
+)
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// Options for the field.
+	//
+	// optional dynabuf.v1.FieldOptions field = 52302;
+	E_Field = &file_dynabufpb_options_proto_extTypes[1]
+)
+
+// Extension fields to descriptorpb.MessageOptions.
+var (
+	// Options for the message.
+	//
+	// optional dynabuf.v1.MessageOptions message = 52303;
+	E_Message = &file_dynabufpb_options_proto_extTypes[2]
+)
+
+var File_dynabufpb_options_proto protoreflect.FileDescriptor
+
+var file_dynabufpb_options_proto_rawDesc = []byte{
+	0x0a, 0x17, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x70, 0x62, 0x2f, 0x6f, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x64, 0x79, 0x6e, 0x61, 0x62,
+	0x75, 0x66, 0x2e, 0x76, 0x31, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f,
+	0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x8f, 0x02, 0x0a, 0x0e, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x37, 0x0a, 0x08, 0x73, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x64,
+	0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
+	0x6f, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x12, 0x34, 0x0a, 0x07, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x2e, 0x76,
+	0x31, 0x2e, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x52, 0x07, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x51, 0x0a, 0x14, 0x63, 0x6f, 0x6d,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x65, 0x5f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75,
+	0x66, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x65, 0x41, 0x74,
+	0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x52, 0x13, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x65, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x0e,
+	0x6b, 0x65, 0x79, 0x5f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x6b, 0x65, 0x79, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x44, 0x0a, 0x12, 0x43, 0x6f, 0x6d,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x65, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x22,
+	0x27, 0x0a, 0x0f, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x05, 0x65, 0x76, 0x65, 0x72, 0x79, 0x22, 0x6c, 0x0a, 0x0e, 0x48, 0x69, 0x73, 0x74,
+	0x6f, 0x72, 0x79, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x37, 0x0a, 0x09, 0x72, 0x65,
+	0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x72, 0x65, 0x74, 0x65, 0x6e, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xde, 0x01, 0x0a, 0x0c, 0x46, 0x69, 0x65, 0x6c, 0x64,
+	0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x6f, 0x6c, 0x61, 0x74,
+	0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x76, 0x6f, 0x6c, 0x61, 0x74,
+	0x69, 0x6c, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x6f, 0x6e,
+	0x6c, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6d, 0x6d, 0x75, 0x74, 0x61, 0x62, 0x6c,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x6d, 0x6d, 0x75, 0x74, 0x61, 0x62,
+	0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x12, 0x1a, 0x0a, 0x08,
+	0x73, 0x6f, 0x72, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08,
+	0x73, 0x6f, 0x72, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x6c, 0x69, 0x61,
+	0x73, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x61, 0x6c, 0x69, 0x61, 0x73,
+	0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x61, 0x6c, 0x69, 0x61,
+	0x73, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x77, 0x72, 0x69, 0x74, 0x65,
+	0x41, 0x6c, 0x69, 0x61, 0x73, 0x65, 0x73, 0x22, 0x34, 0x0a, 0x10, 0x45, 0x6e, 0x75, 0x6d, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x3a, 0x60, 0x0a,
+	0x0a, 0x65, 0x6e, 0x75, 0x6d, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x21, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6e,
+	0x75, 0x6d, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xcd,
+	0x98, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75, 0x66,
+	0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x75, 0x6d, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x4f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x09, 0x65, 0x6e, 0x75, 0x6d, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x4f, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64,
+	0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xce, 0x98, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x65,
+	0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64,
+	0x3a, 0x57, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xcf, 0x98, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x2e, 0x76,
+	0x31, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x25, 0x5a, 0x23, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x69, 0x63, 0x61, 0x74, 0x7a, 0x2f, 0x64,
+	0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_dynabufpb_options_proto_rawDescOnce sync.Once
+	file_dynabufpb_options_proto_rawDescData = file_dynabufpb_options_proto_rawDesc
+)
+
+func file_dynabufpb_options_proto_rawDescGZIP() []byte {
+	file_dynabufpb_options_proto_rawDescOnce.Do(func() {
+		file_dynabufpb_options_proto_rawDescData = protoimpl.X.CompressGZIP(file_dynabufpb_options_proto_rawDescData)
+	})
+	return file_dynabufpb_options_proto_rawDescData
+}
+
+var file_dynabufpb_options_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_dynabufpb_options_proto_goTypes = []any{
+	(*MessageOptions)(nil),                // 0: dynabuf.v1.MessageOptions
+	(*CompositeAttribute)(nil),            // 1: dynabuf.v1.CompositeAttribute
+	(*SnapshotOptions)(nil),               // 2: dynabuf.v1.SnapshotOptions
+	(*HistoryOptions)(nil),                // 3: dynabuf.v1.HistoryOptions
+	(*FieldOptions)(nil),                  // 4: dynabuf.v1.FieldOptions
+	(*EnumValueOptions)(nil),              // 5: dynabuf.v1.EnumValueOptions
+	(*durationpb.Duration)(nil),           // 6: google.protobuf.Duration
+	(*descriptorpb.EnumValueOptions)(nil), // 7: google.protobuf.EnumValueOptions
+	(*descriptorpb.FieldOptions)(nil),     // 8: google.protobuf.FieldOptions
+	(*descriptorpb.MessageOptions)(nil),   // 9: google.protobuf.MessageOptions
+}
+var file_dynabufpb_options_proto_depIdxs = []int32{
+	2,  // 0: dynabuf.v1.MessageOptions.snapshot:type_name -> dynabuf.v1.SnapshotOptions
+	3,  // 1: dynabuf.v1.MessageOptions.history:type_name -> dynabuf.v1.HistoryOptions
+	1,  // 2: dynabuf.v1.MessageOptions.composite_attributes:type_name -> dynabuf.v1.CompositeAttribute
+	6,  // 3: dynabuf.v1.HistoryOptions.retention:type_name -> google.protobuf.Duration
+	7,  // 4: dynabuf.v1.enum_value:extendee -> google.protobuf.EnumValueOptions
+	8,  // 5: dynabuf.v1.field:extendee -> google.protobuf.FieldOptions
+	9,  // 6: dynabuf.v1.message:extendee -> google.protobuf.MessageOptions
+	5,  // 7: dynabuf.v1.enum_value:type_name -> dynabuf.v1.EnumValueOptions
+	4,  // 8: dynabuf.v1.field:type_name -> dynabuf.v1.FieldOptions
+	0,  // 9: dynabuf.v1.message:type_name -> dynabuf.v1.MessageOptions
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	7,  // [7:10] is the sub-list for extension type_name
+	4,  // [4:7] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_dynabufpb_options_proto_init() }
+func file_dynabufpb_options_proto_init() {
+	if File_dynabufpb_options_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_dynabufpb_options_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*MessageOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dynabufpb_options_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*CompositeAttribute); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dynabufpb_options_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*SnapshotOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dynabufpb_options_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*HistoryOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dynabufpb_options_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*FieldOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dynabufpb_options_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*EnumValueOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_dynabufpb_options_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 3,
+			NumServices:   0,
+		},
+		GoTypes:           file_dynabufpb_options_proto_goTypes,
+		DependencyIndexes: file_dynabufpb_options_proto_depIdxs,
+		MessageInfos:      file_dynabufpb_options_proto_msgTypes,
+		ExtensionInfos:    file_dynabufpb_options_proto_extTypes,
+	}.Build()
+	File_dynabufpb_options_proto = out.File
+	file_dynabufpb_options_proto_rawDesc = nil
+	file_dynabufpb_options_proto_goTypes = nil
+	file_dynabufpb_options_proto_depIdxs = nil
+}
@@ -0,0 +1,671 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: dynabufpb/spec.proto
+
+package dynabufpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Encoding describes how a value is encoded within its attribute value
+// type, following the protobuf JSON mapping.
+type Encoding int32
+
+const (
+	// The value is stored as is.
+	Encoding_ENCODING_UNSPECIFIED Encoding = 0
+	// 64-bit integers are stored as decimal strings.
+	Encoding_ENCODING_DECIMAL_STRING Encoding = 1
+	// Bytes are stored as standard base64 strings with padding.
+	Encoding_ENCODING_BASE64 Encoding = 2
+	// Enums are stored as the name of their value, or as a number for
+	// values unknown to the schema.
+	Encoding_ENCODING_ENUM_NAME Encoding = 3
+	// Floating point numbers are stored as numbers, or as the strings
+	// "NaN", "Infinity", and "-Infinity".
+	Encoding_ENCODING_FLOAT Encoding = 4
+	// google.protobuf.Timestamp is stored as an RFC 3339 string in UTC.
+	Encoding_ENCODING_RFC3339 Encoding = 5
+	// google.protobuf.Duration is stored as a string of seconds with an "s"
+	// suffix, such as "1.5s".
+	Encoding_ENCODING_DURATION Encoding = 6
+	// google.protobuf.FieldMask is stored as a comma separated string of
+	// lowerCamelCase paths.
+	Encoding_ENCODING_FIELD_MASK Encoding = 7
+	// google.protobuf.Struct, Value, and ListValue are stored as the JSON
+	// value they represent.
+	Encoding_ENCODING_JSON Encoding = 8
+	// google.protobuf.Any is stored as a map with an "@type" attribute and
+	// the attributes of the packed message.
+	Encoding_ENCODING_ANY Encoding = 9
+	// Strings annotated as decimal are stored as numbers with the same
+	// digits.
+	Encoding_ENCODING_DECIMAL Encoding = 10
+	// google.type.Date values are stored as YYYY-MM-DD strings, with zeros
+	// for the parts left unset, so they sort chronologically.
+	Encoding_ENCODING_DATE Encoding = 11
+	// google.type.TimeOfDay values are stored as HH:MM:SS strings, with the
+	// fraction of a second, if any, so they sort chronologically.
+	Encoding_ENCODING_TIME_OF_DAY Encoding = 12
+	// google.type.Money values annotated as decimal are stored as a map of
+	// their amount, as a number, and currency code.
+	Encoding_ENCODING_DECIMAL_MONEY Encoding = 13
+	// Fields annotated as sortable are stored as strings sorting as their
+	// values do.
+	Encoding_ENCODING_SORTABLE Encoding = 14
+)
+
+// Enum value maps for Encoding.
+var (
+	Encoding_name = map[int32]string{
+		0:  "ENCODING_UNSPECIFIED",
+		1:  "ENCODING_DECIMAL_STRING",
+		2:  "ENCODING_BASE64",
+		3:  "ENCODING_ENUM_NAME",
+		4:  "ENCODING_FLOAT",
+		5:  "ENCODING_RFC3339",
+		6:  "ENCODING_DURATION",
+		7:  "ENCODING_FIELD_MASK",
+		8:  "ENCODING_JSON",
+		9:  "ENCODING_ANY",
+		10: "ENCODING_DECIMAL",
+		11: "ENCODING_DATE",
+		12: "ENCODING_TIME_OF_DAY",
+		13: "ENCODING_DECIMAL_MONEY",
+		14: "ENCODING_SORTABLE",
+	}
+	Encoding_value = map[string]int32{
+		"ENCODING_UNSPECIFIED":    0,
+		"ENCODING_DECIMAL_STRING": 1,
+		"ENCODING_BASE64":         2,
+		"ENCODING_ENUM_NAME":      3,
+		"ENCODING_FLOAT":          4,
+		"ENCODING_RFC3339":        5,
+		"ENCODING_DURATION":       6,
+		"ENCODING_FIELD_MASK":     7,
+		"ENCODING_JSON":           8,
+		"ENCODING_ANY":            9,
+		"ENCODING_DECIMAL":        10,
+		"ENCODING_DATE":           11,
+		"ENCODING_TIME_OF_DAY":    12,
+		"ENCODING_DECIMAL_MONEY":  13,
+		"ENCODING_SORTABLE":       14,
+	}
+)
+
+func (x Encoding) Enum() *Encoding {
+	p := new(Encoding)
+	*p = x
+	return p
+}
+
+func (x Encoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Encoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_dynabufpb_spec_proto_enumTypes[0].Descriptor()
+}
+
+func (Encoding) Type() protoreflect.EnumType {
+	return &file_dynabufpb_spec_proto_enumTypes[0]
+}
+
+func (x Encoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Encoding.Descriptor instead.
+func (Encoding) EnumDescriptor() ([]byte, []int) {
+	return file_dynabufpb_spec_proto_rawDescGZIP(), []int{0}
+}
+
+// MappingSpec describes how dynabuf maps a protobuf message to the
+// attributes of a DynamoDB item, so other tools can mirror the mapping.
+type MappingSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The full name of the message stored as the item.
+	Root string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// The specs of the root message and of every message type nested in it
+	// that is stored as a map of attributes, in the order they are found.
+	Messages []*MessageSpec `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty"`
+}
+
+func (x *MappingSpec) Reset() {
+	*x = MappingSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dynabufpb_spec_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MappingSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MappingSpec) ProtoMessage() {}
+
+func (x *MappingSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_dynabufpb_spec_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MappingSpec.ProtoReflect.Descriptor instead.
+func (*MappingSpec) Descriptor() ([]byte, []int) {
+	return file_dynabufpb_spec_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MappingSpec) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *MappingSpec) GetMessages() []*MessageSpec {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+// MessageSpec describes the attributes of a message stored as a map.
+type MessageSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The full name of the message.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The attributes of the message's fields, in field declaration order.
+	Attributes []*AttributeSpec `protobuf:"bytes,2,rep,name=attributes,proto3" json:"attributes,omitempty"`
+}
+
+func (x *MessageSpec) Reset() {
+	*x = MessageSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dynabufpb_spec_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MessageSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageSpec) ProtoMessage() {}
+
+func (x *MessageSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_dynabufpb_spec_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageSpec.ProtoReflect.Descriptor instead.
+func (*MessageSpec) Descriptor() ([]byte, []int) {
+	return file_dynabufpb_spec_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MessageSpec) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MessageSpec) GetAttributes() []*AttributeSpec {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+// AttributeSpec describes the attribute of a single field.
+type AttributeSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The attribute name, which is the field's JSON name.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The full name of the field.
+	Field string `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	// The field number.
+	Number int32 `protobuf:"varint,3,opt,name=number,proto3" json:"number,omitempty"`
+	// The value of the attribute. Repeated fields are stored as lists and map
+	// fields as maps, described by element.
+	Value *ValueSpec `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	// The elements of lists, or the values of maps, keyed by the string form
+	// of the map key.
+	Element *ValueSpec `protobuf:"bytes,5,opt,name=element,proto3" json:"element,omitempty"`
+	// Whether the attribute is omitted when the field has its default value.
+	// Only fields with explicit presence are stored with default values.
+	OmittedWhenDefault bool `protobuf:"varint,6,opt,name=omitted_when_default,json=omittedWhenDefault,proto3" json:"omitted_when_default,omitempty"`
+	// Whether the field is annotated as volatile.
+	Volatile bool `protobuf:"varint,7,opt,name=volatile,proto3" json:"volatile,omitempty"`
+	// Whether the field is annotated as output only.
+	OutputOnly bool `protobuf:"varint,8,opt,name=output_only,json=outputOnly,proto3" json:"output_only,omitempty"`
+	// Whether the field is annotated as immutable.
+	Immutable bool `protobuf:"varint,9,opt,name=immutable,proto3" json:"immutable,omitempty"`
+	// The template of a composite attribute, which is composed of the values
+	// of several fields rather than storing a single field.
+	Template string `protobuf:"bytes,10,opt,name=template,proto3" json:"template,omitempty"`
+	// The attribute names the field is also read from, which it was stored
+	// under before being renamed.
+	Aliases []string `protobuf:"bytes,11,rep,name=aliases,proto3" json:"aliases,omitempty"`
+	// Whether the field is also written under its aliases, when enabled.
+	WriteAliases bool `protobuf:"varint,12,opt,name=write_aliases,json=writeAliases,proto3" json:"write_aliases,omitempty"`
+}
+
+func (x *AttributeSpec) Reset() {
+	*x = AttributeSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dynabufpb_spec_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AttributeSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeSpec) ProtoMessage() {}
+
+func (x *AttributeSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_dynabufpb_spec_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeSpec.ProtoReflect.Descriptor instead.
+func (*AttributeSpec) Descriptor() ([]byte, []int) {
+	return file_dynabufpb_spec_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AttributeSpec) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AttributeSpec) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *AttributeSpec) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *AttributeSpec) GetValue() *ValueSpec {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *AttributeSpec) GetElement() *ValueSpec {
+	if x != nil {
+		return x.Element
+	}
+	return nil
+}
+
+func (x *AttributeSpec) GetOmittedWhenDefault() bool {
+	if x != nil {
+		return x.OmittedWhenDefault
+	}
+	return false
+}
+
+func (x *AttributeSpec) GetVolatile() bool {
+	if x != nil {
+		return x.Volatile
+	}
+	return false
+}
+
+func (x *AttributeSpec) GetOutputOnly() bool {
+	if x != nil {
+		return x.OutputOnly
+	}
+	return false
+}
+
+func (x *AttributeSpec) GetImmutable() bool {
+	if x != nil {
+		return x.Immutable
+	}
+	return false
+}
+
+func (x *AttributeSpec) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *AttributeSpec) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+func (x *AttributeSpec) GetWriteAliases() bool {
+	if x != nil {
+		return x.WriteAliases
+	}
+	return false
+}
+
+// ValueSpec describes a stored value.
+type ValueSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The DynamoDB attribute value type, such as "S", "N", "BOOL", "M", or
+	// "L", or empty if it depends on the value.
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// How the value is encoded within its type.
+	Encoding Encoding `protobuf:"varint,2,opt,name=encoding,proto3,enum=dynabuf.v1.Encoding" json:"encoding,omitempty"`
+	// The full name of the message, when the value is a message stored as a
+	// map described by a MessageSpec.
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// The full name of the enum, when the value is an enum.
+	Enum string `protobuf:"bytes,4,opt,name=enum,proto3" json:"enum,omitempty"`
+}
+
+func (x *ValueSpec) Reset() {
+	*x = ValueSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dynabufpb_spec_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValueSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValueSpec) ProtoMessage() {}
+
+func (x *ValueSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_dynabufpb_spec_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValueSpec.ProtoReflect.Descriptor instead.
+func (*ValueSpec) Descriptor() ([]byte, []int) {
+	return file_dynabufpb_spec_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ValueSpec) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ValueSpec) GetEncoding() Encoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return Encoding_ENCODING_UNSPECIFIED
+}
+
+func (x *ValueSpec) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ValueSpec) GetEnum() string {
+	if x != nil {
+		return x.Enum
+	}
+	return ""
+}
+
+var File_dynabufpb_spec_proto protoreflect.FileDescriptor
+
+var file_dynabufpb_spec_proto_rawDesc = []byte{
+	0x0a, 0x14, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x70, 0x62, 0x2f, 0x73, 0x70, 0x65, 0x63,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x2e,
+	0x76, 0x31, 0x22, 0x56, 0x0a, 0x0b, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x53, 0x70, 0x65,
+	0x63, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x72, 0x6f, 0x6f, 0x74, 0x12, 0x33, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75,
+	0x66, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x53, 0x70, 0x65, 0x63,
+	0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x22, 0x5c, 0x0a, 0x0b, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x39, 0x0a,
+	0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x2e, 0x76, 0x31, 0x2e, 0x41,
+	0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x53, 0x70, 0x65, 0x63, 0x52, 0x0a, 0x61, 0x74,
+	0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x22, 0x97, 0x03, 0x0a, 0x0d, 0x41, 0x74, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x66,
+	0x69, 0x65, 0x6c, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x2b, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x79,
+	0x6e, 0x61, 0x62, 0x75, 0x66, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x53, 0x70,
+	0x65, 0x63, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x2f, 0x0a, 0x07, 0x65, 0x6c, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x79, 0x6e,
+	0x61, 0x62, 0x75, 0x66, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x53, 0x70, 0x65,
+	0x63, 0x52, 0x07, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x30, 0x0a, 0x14, 0x6f, 0x6d,
+	0x69, 0x74, 0x74, 0x65, 0x64, 0x5f, 0x77, 0x68, 0x65, 0x6e, 0x5f, 0x64, 0x65, 0x66, 0x61, 0x75,
+	0x6c, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x6f, 0x6d, 0x69, 0x74, 0x74, 0x65,
+	0x64, 0x57, 0x68, 0x65, 0x6e, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x12, 0x1a, 0x0a, 0x08,
+	0x76, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6c, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08,
+	0x76, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6c, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x6f, 0x75, 0x74, 0x70,
+	0x75, 0x74, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6d, 0x6d,
+	0x75, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x6d,
+	0x6d, 0x75, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x74, 0x65, 0x6d, 0x70, 0x6c,
+	0x61, 0x74, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x65, 0x6d, 0x70, 0x6c,
+	0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x65, 0x73, 0x18, 0x0b,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x65, 0x73, 0x12, 0x23, 0x0a,
+	0x0d, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x65, 0x73, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x77, 0x72, 0x69, 0x74, 0x65, 0x41, 0x6c, 0x69, 0x61, 0x73,
+	0x65, 0x73, 0x22, 0x7f, 0x0a, 0x09, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x12, 0x30, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x2e,
+	0x76, 0x31, 0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x65, 0x6e, 0x63,
+	0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x65, 0x6e, 0x75, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x65,
+	0x6e, 0x75, 0x6d, 0x2a, 0xe3, 0x02, 0x0a, 0x08, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67,
+	0x12, 0x18, 0x0a, 0x14, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x55, 0x4e, 0x53,
+	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1b, 0x0a, 0x17, 0x45, 0x4e,
+	0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x44, 0x45, 0x43, 0x49, 0x4d, 0x41, 0x4c, 0x5f, 0x53,
+	0x54, 0x52, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x45, 0x4e, 0x43, 0x4f, 0x44,
+	0x49, 0x4e, 0x47, 0x5f, 0x42, 0x41, 0x53, 0x45, 0x36, 0x34, 0x10, 0x02, 0x12, 0x16, 0x0a, 0x12,
+	0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x45, 0x4e, 0x55, 0x4d, 0x5f, 0x4e, 0x41,
+	0x4d, 0x45, 0x10, 0x03, 0x12, 0x12, 0x0a, 0x0e, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47,
+	0x5f, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x10, 0x04, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x4e, 0x43, 0x4f,
+	0x44, 0x49, 0x4e, 0x47, 0x5f, 0x52, 0x46, 0x43, 0x33, 0x33, 0x33, 0x39, 0x10, 0x05, 0x12, 0x15,
+	0x0a, 0x11, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x44, 0x55, 0x52, 0x41, 0x54,
+	0x49, 0x4f, 0x4e, 0x10, 0x06, 0x12, 0x17, 0x0a, 0x13, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e,
+	0x47, 0x5f, 0x46, 0x49, 0x45, 0x4c, 0x44, 0x5f, 0x4d, 0x41, 0x53, 0x4b, 0x10, 0x07, 0x12, 0x11,
+	0x0a, 0x0d, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x4a, 0x53, 0x4f, 0x4e, 0x10,
+	0x08, 0x12, 0x10, 0x0a, 0x0c, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x41, 0x4e,
+	0x59, 0x10, 0x09, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f,
+	0x44, 0x45, 0x43, 0x49, 0x4d, 0x41, 0x4c, 0x10, 0x0a, 0x12, 0x11, 0x0a, 0x0d, 0x45, 0x4e, 0x43,
+	0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x44, 0x41, 0x54, 0x45, 0x10, 0x0b, 0x12, 0x18, 0x0a, 0x14,
+	0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x5f, 0x4f, 0x46,
+	0x5f, 0x44, 0x41, 0x59, 0x10, 0x0c, 0x12, 0x1a, 0x0a, 0x16, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49,
+	0x4e, 0x47, 0x5f, 0x44, 0x45, 0x43, 0x49, 0x4d, 0x41, 0x4c, 0x5f, 0x4d, 0x4f, 0x4e, 0x45, 0x59,
+	0x10, 0x0d, 0x12, 0x15, 0x0a, 0x11, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x53,
+	0x4f, 0x52, 0x54, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x0e, 0x42, 0x25, 0x5a, 0x23, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x69, 0x63, 0x61, 0x74, 0x7a, 0x2f, 0x64,
+	0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x79, 0x6e, 0x61, 0x62, 0x75, 0x66, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_dynabufpb_spec_proto_rawDescOnce sync.Once
+	file_dynabufpb_spec_proto_rawDescData = file_dynabufpb_spec_proto_rawDesc
+)
+
+func file_dynabufpb_spec_proto_rawDescGZIP() []byte {
+	file_dynabufpb_spec_proto_rawDescOnce.Do(func() {
+		file_dynabufpb_spec_proto_rawDescData = protoimpl.X.CompressGZIP(file_dynabufpb_spec_proto_rawDescData)
+	})
+	return file_dynabufpb_spec_proto_rawDescData
+}
+
+var file_dynabufpb_spec_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_dynabufpb_spec_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_dynabufpb_spec_proto_goTypes = []any{
+	(Encoding)(0),         // 0: dynabuf.v1.Encoding
+	(*MappingSpec)(nil),   // 1: dynabuf.v1.MappingSpec
+	(*MessageSpec)(nil),   // 2: dynabuf.v1.MessageSpec
+	(*AttributeSpec)(nil), // 3: dynabuf.v1.AttributeSpec
+	(*ValueSpec)(nil),     // 4: dynabuf.v1.ValueSpec
+}
+var file_dynabufpb_spec_proto_depIdxs = []int32{
+	2, // 0: dynabuf.v1.MappingSpec.messages:type_name -> dynabuf.v1.MessageSpec
+	3, // 1: dynabuf.v1.MessageSpec.attributes:type_name -> dynabuf.v1.AttributeSpec
+	4, // 2: dynabuf.v1.AttributeSpec.value:type_name -> dynabuf.v1.ValueSpec
+	4, // 3: dynabuf.v1.AttributeSpec.element:type_name -> dynabuf.v1.ValueSpec
+	0, // 4: dynabuf.v1.ValueSpec.encoding:type_name -> dynabuf.v1.Encoding
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_dynabufpb_spec_proto_init() }
+func file_dynabufpb_spec_proto_init() {
+	if File_dynabufpb_spec_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_dynabufpb_spec_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*MappingSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dynabufpb_spec_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*MessageSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dynabufpb_spec_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*AttributeSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dynabufpb_spec_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*ValueSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_dynabufpb_spec_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_dynabufpb_spec_proto_goTypes,
+		DependencyIndexes: file_dynabufpb_spec_proto_depIdxs,
+		EnumInfos:         file_dynabufpb_spec_proto_enumTypes,
+		MessageInfos:      file_dynabufpb_spec_proto_msgTypes,
+	}.Build()
+	File_dynabufpb_spec_proto = out.File
+	file_dynabufpb_spec_proto_rawDesc = nil
+	file_dynabufpb_spec_proto_goTypes = nil
+	file_dynabufpb_spec_proto_depIdxs = nil
+}
@@ -0,0 +1,244 @@
+package dynabuftest
+
+import (
+	"context"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Client is the subset of the DynamoDB API wrapped by a [ChaosClient],
+// which covers the clients of every dynabuf package.
+type Client interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+// ChaosConfig configures the failures injected by a [ChaosClient]. Rates
+// are fractions from 0, never, to 1, always.
+type ChaosConfig struct {
+	// Seed seeds the choice of the requests failing, so a test fails the
+	// same requests every run if its requests are sent in the same order.
+	Seed int64
+
+	// ThrottleRate is the fraction of requests failing with a
+	// ProvisionedThroughputExceededException before being sent.
+	ThrottleRate float64
+
+	// ConditionalFailureRate is the fraction of conditional writes failing
+	// with a ConditionalCheckFailedException, or transactions with a
+	// condition failing with a TransactionCanceledException, before being
+	// sent, as if another writer had changed the item first.
+	ConditionalFailureRate float64
+
+	// UnprocessedRate is the fraction of the requests of batch writes left
+	// unprocessed, and returned as UnprocessedItems without being sent.
+	UnprocessedRate float64
+}
+
+// ChaosStats counts the failures injected by a [ChaosClient].
+type ChaosStats struct {
+	// Throttles is the number of requests throttled.
+	Throttles int
+
+	// ConditionalFailures is the number of conditional writes and
+	// transactions failed.
+	ConditionalFailures int
+
+	// Unprocessed is the number of batch write requests left unprocessed.
+	Unprocessed int
+}
+
+// ChaosClient wraps a DynamoDB client, injecting throttling, conditional
+// check failures, and partially processed batch writes, to test the retry
+// and conflict handling of code using the client. Requests not failed are
+// sent to the wrapped client as they are.
+//
+// A ChaosClient is safe for concurrent use if the wrapped client is.
+//
+// # Example
+//
+//	client := dynabuftest.NewChaosClient(dynamodb.NewFromConfig(cfg), dynabuftest.ChaosConfig{
+//	  ThrottleRate:           0.1,
+//	  ConditionalFailureRate: 0.05,
+//	})
+//
+//	locker := lock.New(client, "locks", time.Minute)
+type ChaosClient struct {
+	client Client
+	cfg    ChaosConfig
+
+	mu    sync.Mutex
+	r     *rand.Rand
+	stats ChaosStats
+}
+
+// NewChaosClient returns a [ChaosClient] wrapping client.
+func NewChaosClient(client Client, cfg ChaosConfig) *ChaosClient {
+	return &ChaosClient{
+		client: client,
+		cfg:    cfg,
+		r:      rand.New(rand.NewPCG(uint64(cfg.Seed), 0x6368616f73)),
+	}
+}
+
+// Stats returns the failures injected so far.
+func (c *ChaosClient) Stats() ChaosStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// GetItem gets an item, unless throttled.
+func (c *ChaosClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if err := c.fail(false); err != nil {
+		return nil, err
+	}
+	return c.client.GetItem(ctx, params, optFns...)
+}
+
+// PutItem puts an item, unless throttled, or failed if conditional.
+func (c *ChaosClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if err := c.fail(params.ConditionExpression != nil); err != nil {
+		return nil, err
+	}
+	return c.client.PutItem(ctx, params, optFns...)
+}
+
+// UpdateItem updates an item, unless throttled, or failed if conditional.
+func (c *ChaosClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	if err := c.fail(params.ConditionExpression != nil); err != nil {
+		return nil, err
+	}
+	return c.client.UpdateItem(ctx, params, optFns...)
+}
+
+// DeleteItem deletes an item, unless throttled, or failed if conditional.
+func (c *ChaosClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	if err := c.fail(params.ConditionExpression != nil); err != nil {
+		return nil, err
+	}
+	return c.client.DeleteItem(ctx, params, optFns...)
+}
+
+// Query queries a table, unless throttled.
+func (c *ChaosClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	if err := c.fail(false); err != nil {
+		return nil, err
+	}
+	return c.client.Query(ctx, params, optFns...)
+}
+
+// Scan scans a table, unless throttled.
+func (c *ChaosClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if err := c.fail(false); err != nil {
+		return nil, err
+	}
+	return c.client.Scan(ctx, params, optFns...)
+}
+
+// BatchWriteItem writes a batch of items, unless throttled, leaving some of
+// its requests unprocessed.
+func (c *ChaosClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	if err := c.fail(false); err != nil {
+		return nil, err
+	}
+
+	sent := *params
+	sent.RequestItems = map[string][]types.WriteRequest{}
+	unprocessed := map[string][]types.WriteRequest{}
+
+	c.mu.Lock()
+	for _, table := range slices.Sorted(maps.Keys(params.RequestItems)) {
+		for _, request := range params.RequestItems[table] {
+			if c.r.Float64() < c.cfg.UnprocessedRate {
+				unprocessed[table] = append(unprocessed[table], request)
+				c.stats.Unprocessed++
+			} else {
+				sent.RequestItems[table] = append(sent.RequestItems[table], request)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	out := &dynamodb.BatchWriteItemOutput{}
+	if len(sent.RequestItems) > 0 {
+		var err error
+		out, err = c.client.BatchWriteItem(ctx, &sent, optFns...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for table, requests := range out.UnprocessedItems {
+		unprocessed[table] = append(unprocessed[table], requests...)
+	}
+	out.UnprocessedItems = unprocessed
+	return out, nil
+}
+
+// TransactWriteItems writes items in a transaction, unless throttled, or
+// canceled if one of its actions is conditional.
+func (c *ChaosClient) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	conditional := -1
+	for i, item := range params.TransactItems {
+		if item.ConditionCheck != nil ||
+			item.Put != nil && item.Put.ConditionExpression != nil ||
+			item.Update != nil && item.Update.ConditionExpression != nil ||
+			item.Delete != nil && item.Delete.ConditionExpression != nil {
+			conditional = i
+			break
+		}
+	}
+
+	if err := c.fail(conditional >= 0); err != nil {
+		if _, ok := err.(*types.ConditionalCheckFailedException); !ok {
+			return nil, err
+		}
+
+		reasons := make([]types.CancellationReason, len(params.TransactItems))
+		for i := range reasons {
+			reasons[i].Code = aws.String("None")
+		}
+		reasons[conditional].Code = aws.String("ConditionalCheckFailed")
+		reasons[conditional].Message = aws.String("The conditional request failed")
+
+		return nil, &types.TransactionCanceledException{
+			Message:             aws.String("Transaction cancelled, please refer cancellation reasons for specific reasons"),
+			CancellationReasons: reasons,
+		}
+	}
+	return c.client.TransactWriteItems(ctx, params, optFns...)
+}
+
+// fail returns the error a request fails with, if any, which is a
+// ConditionalCheckFailedException only if the request is conditional.
+func (c *ChaosClient) fail(conditional bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.r.Float64() < c.cfg.ThrottleRate {
+		c.stats.Throttles++
+		return &types.ProvisionedThroughputExceededException{
+			Message: aws.String("The level of configured provisioned throughput for the table was exceeded"),
+		}
+	}
+	if conditional && c.r.Float64() < c.cfg.ConditionalFailureRate {
+		c.stats.ConditionalFailures++
+		return &types.ConditionalCheckFailedException{
+			Message: aws.String("The conditional request failed"),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package dynabuftest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/dynabuftest"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/shoenig/test/must"
+)
+
+func newChaosTable(t *testing.T) *dynamotest.Client {
+	t.Helper()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("items"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+	return client
+}
+
+func item(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}}
+}
+
+func TestChaosClientThrottle(t *testing.T) {
+	ctx := context.Background()
+
+	client := dynabuftest.NewChaosClient(newChaosTable(t), dynabuftest.ChaosConfig{ThrottleRate: 1})
+
+	_, err := client.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String("items"), Key: item("1")})
+	var throttled *types.ProvisionedThroughputExceededException
+	must.True(t, errors.As(err, &throttled))
+
+	_, err = client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("items")})
+	must.True(t, errors.As(err, &throttled))
+
+	must.Eq(t, dynabuftest.ChaosStats{Throttles: 2}, client.Stats())
+}
+
+func TestChaosClientConditionalFailure(t *testing.T) {
+	ctx := context.Background()
+
+	table := newChaosTable(t)
+	client := dynabuftest.NewChaosClient(table, dynabuftest.ChaosConfig{ConditionalFailureRate: 1})
+
+	_, err := client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("items"), Item: item("1")})
+	must.NoError(t, err)
+
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String("items"),
+		Item:                item("2"),
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	var ccf *types.ConditionalCheckFailedException
+	must.True(t, errors.As(err, &ccf))
+
+	out, err := table.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String("items"), Key: item("2")})
+	must.NoError(t, err)
+	must.Nil(t, out.Item)
+
+	_, err = client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{TableName: aws.String("items"), Item: item("3")}},
+			{ConditionCheck: &types.ConditionCheck{
+				TableName:           aws.String("items"),
+				Key:                 item("1"),
+				ConditionExpression: aws.String("attribute_exists(id)"),
+			}},
+		},
+	})
+	var canceled *types.TransactionCanceledException
+	must.True(t, errors.As(err, &canceled))
+	must.Eq(t, "None", aws.ToString(canceled.CancellationReasons[0].Code))
+	must.Eq(t, "ConditionalCheckFailed", aws.ToString(canceled.CancellationReasons[1].Code))
+
+	must.Eq(t, dynabuftest.ChaosStats{ConditionalFailures: 2}, client.Stats())
+}
+
+func TestChaosClientUnprocessed(t *testing.T) {
+	ctx := context.Background()
+
+	table := newChaosTable(t)
+	client := dynabuftest.NewChaosClient(table, dynabuftest.ChaosConfig{Seed: 1, UnprocessedRate: 0.5})
+
+	var requests []types.WriteRequest
+	for _, id := range []string{"1", "2", "3", "4", "5", "6", "7", "8"} {
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item(id)}})
+	}
+
+	out, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{"items": requests},
+	})
+	must.NoError(t, err)
+
+	unprocessed := len(out.UnprocessedItems["items"])
+	must.Positive(t, unprocessed)
+	must.Less(t, len(requests), unprocessed)
+	must.Eq(t, dynabuftest.ChaosStats{Unprocessed: unprocessed}, client.Stats())
+
+	scan, err := table.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("items")})
+	must.NoError(t, err)
+	must.Eq(t, len(requests)-unprocessed, len(scan.Items))
+}
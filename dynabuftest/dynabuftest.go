@@ -0,0 +1,160 @@
+// Package dynabuftest provides utilities for testing code that stores
+// messages in DynamoDB with dynabuf.
+package dynabuftest
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/internal/avtext"
+)
+
+// Diff returns a human readable description of the differences between two
+// items, or an empty string if they are equal. Each difference is a line
+// prefixed with "-" for the wanted value and "+" for the value got, labeled
+// with the path of the attribute and the types of the values:
+//
+//	unexpected item (-want +got):
+//	- address.street: S:"Main St"
+//	+ address.street: S:"Side St"
+//	- age: N:42
+//	+ age: S:"42"
+//
+// Numbers are compared by value, so "1.0" equals "1", and sets are compared
+// regardless of order, as DynamoDB does.
+//
+// # Example
+//
+//	if diff := dynabuftest.Diff(want, out.Item); diff != "" {
+//	  t.Errorf("unexpected item (-want +got):\n%s", diff)
+//	}
+func Diff(want, got map[string]types.AttributeValue) string {
+	var b strings.Builder
+	diffMap(&b, "", want, got)
+	return b.String()
+}
+
+// diffMap writes the differences between the attributes of two maps.
+func diffMap(b *strings.Builder, prefix string, want, got map[string]types.AttributeValue) {
+	names := slices.Sorted(maps.Keys(want))
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		diffValue(b, prefix+name, want[name], got[name])
+	}
+}
+
+// diffValue writes the differences between two attribute values, either of
+// which may be nil if the attribute is missing.
+func diffValue(b *strings.Builder, path string, want, got types.AttributeValue) {
+	switch {
+	case want == nil && got == nil:
+		return
+	case want == nil:
+		fmt.Fprintf(b, "+ %s: %s\n", path, avtext.Format(got))
+		return
+	case got == nil:
+		fmt.Fprintf(b, "- %s: %s\n", path, avtext.Format(want))
+		return
+	}
+
+	switch w := want.(type) {
+	case *types.AttributeValueMemberM:
+		if g, ok := got.(*types.AttributeValueMemberM); ok {
+			diffMap(b, path+".", w.Value, g.Value)
+			return
+		}
+	case *types.AttributeValueMemberL:
+		if g, ok := got.(*types.AttributeValueMemberL); ok {
+			for i := range max(len(w.Value), len(g.Value)) {
+				var wv, gv types.AttributeValue
+				if i < len(w.Value) {
+					wv = w.Value[i]
+				}
+				if i < len(g.Value) {
+					gv = g.Value[i]
+				}
+				diffValue(b, fmt.Sprintf("%s[%d]", path, i), wv, gv)
+			}
+			return
+		}
+	}
+
+	if !equal(want, got) {
+		fmt.Fprintf(b, "- %s: %s\n", path, avtext.Format(want))
+		fmt.Fprintf(b, "+ %s: %s\n", path, avtext.Format(got))
+	}
+}
+
+// equal reports whether two scalar or set attribute values are equal.
+func equal(want, got types.AttributeValue) bool {
+	switch w := want.(type) {
+	case *types.AttributeValueMemberS:
+		g, ok := got.(*types.AttributeValueMemberS)
+		return ok && w.Value == g.Value
+	case *types.AttributeValueMemberN:
+		g, ok := got.(*types.AttributeValueMemberN)
+		return ok && equalNumber(w.Value, g.Value)
+	case *types.AttributeValueMemberB:
+		g, ok := got.(*types.AttributeValueMemberB)
+		return ok && bytes.Equal(w.Value, g.Value)
+	case *types.AttributeValueMemberBOOL:
+		g, ok := got.(*types.AttributeValueMemberBOOL)
+		return ok && w.Value == g.Value
+	case *types.AttributeValueMemberNULL:
+		g, ok := got.(*types.AttributeValueMemberNULL)
+		return ok && w.Value == g.Value
+	case *types.AttributeValueMemberSS:
+		g, ok := got.(*types.AttributeValueMemberSS)
+		return ok && equalSet(w.Value, g.Value, func(a, b string) bool { return a == b })
+	case *types.AttributeValueMemberNS:
+		g, ok := got.(*types.AttributeValueMemberNS)
+		return ok && equalSet(w.Value, g.Value, equalNumber)
+	case *types.AttributeValueMemberBS:
+		g, ok := got.(*types.AttributeValueMemberBS)
+		return ok && equalSet(w.Value, g.Value, bytes.Equal)
+	default:
+		return false
+	}
+}
+
+// equalNumber reports whether two DynamoDB numbers have the same value,
+// falling back to comparing their text if either is not a valid number.
+func equalNumber(a, b string) bool {
+	x, okx := new(big.Rat).SetString(a)
+	y, oky := new(big.Rat).SetString(b)
+	if !okx || !oky {
+		return a == b
+	}
+	return x.Cmp(y) == 0
+}
+
+// equalSet reports whether two sets have the same elements, in any order.
+func equalSet[T any](a, b []T, eq func(T, T) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	matched := make([]bool, len(b))
+outer:
+	for _, x := range a {
+		for i, y := range b {
+			if !matched[i] && eq(x, y) {
+				matched[i] = true
+				continue outer
+			}
+		}
+		return false
+	}
+	return true
+}
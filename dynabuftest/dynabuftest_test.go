@@ -0,0 +1,68 @@
+package dynabuftest_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/dynabuftest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestDiff(t *testing.T) {
+	want := map[string]types.AttributeValue{
+		"id":   &types.AttributeValueMemberS{Value: "1"},
+		"age":  &types.AttributeValueMemberN{Value: "42"},
+		"size": &types.AttributeValueMemberN{Value: "1.50"},
+		"tags": &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"street": &types.AttributeValueMemberS{Value: "Main St"},
+		}},
+		"history": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "x"},
+		}},
+		"legacy": &types.AttributeValueMemberBOOL{Value: true},
+	}
+
+	must.Eq(t, "", dynabuftest.Diff(want, want))
+
+	got := map[string]types.AttributeValue{
+		"id":   &types.AttributeValueMemberS{Value: "1"},
+		"age":  &types.AttributeValueMemberS{Value: "42"},
+		"size": &types.AttributeValueMemberN{Value: "1.5"},
+		"tags": &types.AttributeValueMemberSS{Value: []string{"b", "a"}},
+		"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"street": &types.AttributeValueMemberS{Value: "Side St"},
+		}},
+		"history": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "x"},
+			&types.AttributeValueMemberS{Value: "y"},
+		}},
+		"extra": &types.AttributeValueMemberNULL{Value: true},
+	}
+
+	must.Eq(t, ""+
+		"- address.street: S:\"Main St\"\n"+
+		"+ address.street: S:\"Side St\"\n"+
+		"- age: N:42\n"+
+		"+ age: S:\"42\"\n"+
+		"+ extra: NULL:true\n"+
+		"+ history[1]: S:\"y\"\n"+
+		"- legacy: BOOL:true\n",
+		dynabuftest.Diff(want, got),
+	)
+}
+
+func TestDiff_marshaled(t *testing.T) {
+	want, err := dynabuf.Marshal(&testpb.User{Id: "1", Name: "Alice"})
+	must.NoError(t, err)
+
+	got, err := dynabuf.Marshal(&testpb.User{Id: "1", Name: "Bob"})
+	must.NoError(t, err)
+
+	must.Eq(t, "- name: S:\"Alice\"\n+ name: S:\"Bob\"\n", dynabuftest.Diff(
+		want.(map[string]types.AttributeValue),
+		got.(map[string]types.AttributeValue),
+	))
+}
@@ -0,0 +1,233 @@
+package dynabuftest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeDepth is the number of levels of nested messages set by [Fake], so
+// recursive messages are finite.
+const fakeDepth = 3
+
+// fakeEpoch is the earliest time of the timestamps set by [Fake], which
+// are within the year following it.
+var fakeEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeWords are the words strings set by [Fake] are made of.
+var fakeWords = []string{
+	"alpha", "amber", "atlas", "birch", "cedar", "comet", "delta", "ember",
+	"falcon", "harbor", "indigo", "juniper", "lumen", "maple", "nova", "orbit",
+	"pebble", "quartz", "raven", "sierra", "tundra", "umber", "violet", "willow",
+}
+
+// Fake returns a message of type T with every field set to a plausible
+// value generated from seed, so the same seed always returns the same
+// message, for fixtures and load tests.
+//
+// Values respect the annotations of fields, so messages can be stored with
+// [dynabuf.Marshal]:
+//
+//   - identifiers, which are fields named id or ending with _id, and
+//     fields with the IDENTIFIER field behavior, are random UUIDs, so keys
+//     of messages of different seeds are unique
+//   - enums are set to one of their values other than the zero value
+//   - decimal strings are decimal numbers with two decimal places, and
+//     sortable strings and bytes are UUIDs
+//   - timestamps are within 2024, and google.type dates, times of day, and
+//     amounts of money are valid
+//
+// Lists and maps have one to three elements, one field of every oneof is
+// set, and nested messages are set up to three levels deep.
+// google.protobuf.Any, Struct, Value, ListValue, and FieldMask fields are
+// left unset.
+//
+// # Example
+//
+//	for i := range 1000 {
+//	  item, _ := dynabuf.Marshal(dynabuftest.Fake[*example.User](int64(i)))
+//	  // ...
+//	}
+func Fake[T proto.Message](seed int64) T {
+	var zero T
+	msg := zero.ProtoReflect().New()
+
+	f := faker{r: rand.New(rand.NewPCG(uint64(seed), 0x64796e61627566))}
+	f.message(msg, 0)
+
+	return msg.Interface().(T)
+}
+
+// faker sets fields to random values.
+type faker struct {
+	r *rand.Rand
+}
+
+// message sets the fields of msg, at the given depth of nested messages.
+func (f faker) message(msg protoreflect.Message, depth int) {
+	md := msg.Descriptor()
+
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		t := fakeEpoch.Add(time.Duration(f.r.Int64N(int64(365*24*time.Hour/time.Second))) * time.Second)
+		proto.Merge(msg.Interface(), timestamppb.New(t))
+		return
+	case "google.protobuf.Duration":
+		proto.Merge(msg.Interface(), durationpb.New(time.Duration(1+f.r.IntN(3600))*time.Second))
+		return
+	case "google.protobuf.Any", "google.protobuf.Struct", "google.protobuf.Value",
+		"google.protobuf.ListValue", "google.protobuf.FieldMask", "google.protobuf.Empty":
+		return
+	case "google.type.Date":
+		f.set(msg, "year", protoreflect.ValueOfInt32(int32(2000+f.r.IntN(31))))
+		f.set(msg, "month", protoreflect.ValueOfInt32(int32(1+f.r.IntN(12))))
+		f.set(msg, "day", protoreflect.ValueOfInt32(int32(1+f.r.IntN(28))))
+		return
+	case "google.type.TimeOfDay":
+		f.set(msg, "hours", protoreflect.ValueOfInt32(int32(f.r.IntN(24))))
+		f.set(msg, "minutes", protoreflect.ValueOfInt32(int32(f.r.IntN(60))))
+		f.set(msg, "seconds", protoreflect.ValueOfInt32(int32(f.r.IntN(60))))
+		return
+	case "google.type.Money":
+		f.set(msg, "currency_code", protoreflect.ValueOfString([]string{"EUR", "GBP", "JPY", "USD"}[f.r.IntN(4)]))
+		f.set(msg, "units", protoreflect.ValueOfInt64(f.r.Int64N(1000)))
+		f.set(msg, "nanos", protoreflect.ValueOfInt32(int32(f.r.IntN(100))*10_000_000))
+		return
+	}
+
+	fields := md.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+
+		// Only the first field of a oneof is set.
+		if oneof := fd.ContainingOneof(); oneof != nil && oneof.Fields().Get(0) != fd {
+			continue
+		}
+		if md := fieldMessage(fd); md != nil && !isWellKnown(md) && depth+1 >= fakeDepth {
+			continue
+		}
+
+		switch {
+		case fd.IsList():
+			list := msg.Mutable(fd).List()
+			for range 1 + f.r.IntN(3) {
+				if fd.Message() != nil {
+					elem := list.NewElement()
+					f.message(elem.Message(), depth+1)
+					list.Append(elem)
+				} else {
+					list.Append(f.value(fd))
+				}
+			}
+		case fd.IsMap():
+			m := msg.Mutable(fd).Map()
+			for range 1 + f.r.IntN(3) {
+				key := f.value(fd.MapKey()).MapKey()
+				if fd.MapValue().Message() != nil {
+					f.message(m.Mutable(key).Message(), depth+1)
+				} else {
+					m.Set(key, f.value(fd.MapValue()))
+				}
+			}
+		case fd.Message() != nil:
+			f.message(msg.Mutable(fd).Message(), depth+1)
+		default:
+			msg.Set(fd, f.value(fd))
+		}
+	}
+}
+
+// fieldMessage returns the message type of a message field, or of the
+// values of a map field, or nil.
+func fieldMessage(fd protoreflect.FieldDescriptor) protoreflect.MessageDescriptor {
+	if fd.IsMap() {
+		return fd.MapValue().Message()
+	}
+	return fd.Message()
+}
+
+// isWellKnown reports whether md is a well-known type or a google.type
+// message, which are set whatever their depth.
+func isWellKnown(md protoreflect.MessageDescriptor) bool {
+	return strings.HasPrefix(string(md.FullName()), "google.protobuf.") ||
+		strings.HasPrefix(string(md.FullName()), "google.type.")
+}
+
+// set sets the field of msg with the given name, if it exists.
+func (f faker) set(msg protoreflect.Message, name protoreflect.Name, v protoreflect.Value) {
+	if fd := msg.Descriptor().Fields().ByName(name); fd != nil {
+		msg.Set(fd, v)
+	}
+}
+
+// value returns a random value of a scalar field.
+func (f faker) value(fd protoreflect.FieldDescriptor) protoreflect.Value {
+	opts, _ := proto.GetExtension(fd.Options(), dynabufpb.E_Field).(*dynabufpb.FieldOptions)
+
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(f.r.IntN(2) == 1)
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		if values.Len() == 1 {
+			return protoreflect.ValueOfEnum(values.Get(0).Number())
+		}
+		return protoreflect.ValueOfEnum(values.Get(1 + f.r.IntN(values.Len()-1)).Number())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(int32(f.r.IntN(1000)))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(f.r.Int64N(1_000_000))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(uint32(f.r.IntN(1000)))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(f.r.Uint64N(1_000_000))
+	case protoreflect.FloatKind:
+		return protoreflect.ValueOfFloat32(float32(f.r.IntN(100_000)) / 100)
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(float64(f.r.IntN(100_000)) / 100)
+	case protoreflect.BytesKind:
+		b := make([]byte, 16)
+		for i := range b {
+			b[i] = byte(f.r.Uint32())
+		}
+		return protoreflect.ValueOfBytes(b)
+	}
+
+	switch {
+	case opts.GetDecimal():
+		return protoreflect.ValueOfString(fmt.Sprintf("%d.%02d", f.r.IntN(1000), f.r.IntN(100)))
+	case opts.GetSortable() || isIdentifier(fd):
+		return protoreflect.ValueOfString(f.uuid())
+	}
+	return protoreflect.ValueOfString(fmt.Sprintf("%s-%d", fakeWords[f.r.IntN(len(fakeWords))], f.r.IntN(1000)))
+}
+
+// uuid returns a random version 4 UUID in canonical form.
+func (f faker) uuid() string {
+	b := make([]byte, 16)
+	for i := range b {
+		b[i] = byte(f.r.Uint32())
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// isIdentifier reports whether the field identifies its message, such as a
+// key attribute.
+func isIdentifier(fd protoreflect.FieldDescriptor) bool {
+	if fd.Name() == "id" || strings.HasSuffix(string(fd.Name()), "_id") {
+		return true
+	}
+	behaviors, _ := proto.GetExtension(fd.Options(), annotations.E_FieldBehavior).([]annotations.FieldBehavior)
+	return slices.Contains(behaviors, annotations.FieldBehavior_IDENTIFIER)
+}
@@ -0,0 +1,53 @@
+package dynabuftest_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/dynabuftest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFake(t *testing.T) {
+	user := dynabuftest.Fake[*testpb.User](1)
+	must.True(t, proto.Equal(user, dynabuftest.Fake[*testpb.User](1)))
+	must.NotEq(t, user.GetId(), dynabuftest.Fake[*testpb.User](2).GetId())
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	must.RegexMatch(t, uuid, user.GetId())
+	must.NotEq(t, "", user.GetAddress().GetStreet())
+	must.True(t, user.GetCreateTime().IsValid())
+
+	job := dynabuftest.Fake[*testpb.Job](1)
+	must.NotEq(t, testpb.Job_STATE_UNSPECIFIED, job.GetState())
+
+	product := dynabuftest.Fake[*testpb.Product](1)
+	must.RegexMatch(t, regexp.MustCompile(`^[0-9]+\.[0-9]{2}$`), product.GetPrice())
+	must.NotNil(t, product.GetBundled().GetBundled())
+	must.Nil(t, product.GetBundled().GetBundled().GetBundled())
+
+	entry := dynabuftest.Fake[*testpb.Entry](1)
+	must.RegexMatch(t, uuid, entry.GetRequestId())
+	must.SliceLen(t, 16, entry.GetUuid())
+
+	for seed := range int64(20) {
+		for _, msg := range []proto.Message{
+			dynabuftest.Fake[*testpb.User](seed),
+			dynabuftest.Fake[*testpb.Book](seed),
+			dynabuftest.Fake[*testpb.Product](seed),
+			dynabuftest.Fake[*testpb.Invoice](seed),
+			dynabuftest.Fake[*testpb.Entry](seed),
+			dynabuftest.Fake[*testpb.Order](seed),
+		} {
+			item, err := dynabuf.Marshal(msg)
+			must.NoError(t, err)
+
+			got := msg.ProtoReflect().New().Interface()
+			must.NoError(t, dynabuf.Unmarshal(item, got))
+			must.True(t, proto.Equal(msg, got))
+		}
+	}
+}
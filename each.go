@@ -0,0 +1,194 @@
+package dynabuf
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnmarshalEach unmarshals items one at a time into new messages of the
+// type of msgType, calling fn with each, so large result sets are handled
+// without holding a message for every item. It stops at the first error,
+// of unmarshaling or returned by fn, returning it.
+//
+// With [WithMessagePool], the messages are taken from the pool, and given
+// back to it once fn returns, so fn must not keep them.
+//
+// # Example
+//
+//	err := dynabuf.UnmarshalEach(out.Items, &example.Order{}, func(msg proto.Message) error {
+//	  total += msg.(*example.Order).GetTotal()
+//	  return nil
+//	})
+func UnmarshalEach(items []map[string]types.AttributeValue, msgType proto.Message, fn func(proto.Message) error, opts ...Option) error {
+	for i, item := range items {
+		if err := unmarshalOne(item, msgType, fn, opts); err != nil {
+			return fmt.Errorf("dynabuf: at index %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// UnmarshalEachExport is like [UnmarshalEach] for the items of a DynamoDB
+// export in the DynamoDB JSON format, such as a data file of an export to
+// Amazon S3, read from r, holding one object per item:
+//
+//	{"Item":{"id":{"S":"1"},"total":{"N":"42"}}}
+//
+// Items are read from r as they are unmarshaled, so exports of any size
+// are handled with the memory of a single item.
+//
+// # Example
+//
+//	f, err := gzip.NewReader(object.Body)
+//	...
+//	err = dynabuf.UnmarshalEachExport(f, &example.Order{}, handle)
+func UnmarshalEachExport(r io.Reader, msgType proto.Message, fn func(proto.Message) error, opts ...Option) error {
+	d := json.NewDecoder(r)
+	for i := 0; ; i++ {
+		var record struct {
+			Item map[string]json.RawMessage `json:"Item"`
+		}
+		if err := d.Decode(&record); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: at index %d: %w", ErrFailedToUnmarshal, i, err)
+		}
+
+		item, err := decodeExportItem(record.Item)
+		if err != nil {
+			return fmt.Errorf("%w: at index %d: %w", ErrFailedToUnmarshal, i, err)
+		}
+		if err := unmarshalOne(item, msgType, fn, opts); err != nil {
+			return fmt.Errorf("dynabuf: at index %d: %w", i, err)
+		}
+	}
+}
+
+// unmarshalOne unmarshals item into a new message of the type of msgType,
+// taken from the configured pool, if any, and calls fn with it.
+func unmarshalOne(item map[string]types.AttributeValue, msgType proto.Message, fn func(proto.Message) error, opts []Option) error {
+	o := newOptions(opts)
+
+	var msg proto.Message
+	if o.pool != nil {
+		msg = o.pool.New()
+		if msg.ProtoReflect().Descriptor() != msgType.ProtoReflect().Descriptor() {
+			o.pool.Reset(msg)
+			return fmt.Errorf("dynabuf: message pool returned %T, rather than %T", msg, msgType)
+		}
+		defer o.pool.Reset(msg)
+	} else {
+		msg = msgType.ProtoReflect().New().Interface()
+	}
+
+	if err := Unmarshal(item, msg, opts...); err != nil {
+		return err
+	}
+	return fn(msg)
+}
+
+// decodeExportItem decodes the attributes of an item in the DynamoDB JSON
+// format.
+func decodeExportItem(attrs map[string]json.RawMessage) (map[string]types.AttributeValue, error) {
+	item := make(map[string]types.AttributeValue, len(attrs))
+	for name, raw := range attrs {
+		av, err := decodeExportValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", name, err)
+		}
+		item[name] = av
+	}
+	return item, nil
+}
+
+// decodeExportValue decodes an attribute value in the DynamoDB JSON format,
+// such as {"S":"hello"}.
+func decodeExportValue(raw json.RawMessage) (types.AttributeValue, error) {
+	var typed map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &typed); err != nil {
+		return nil, err
+	}
+	if len(typed) != 1 {
+		return nil, fmt.Errorf("attribute value %s must have a single type", raw)
+	}
+
+	for typ, v := range typed {
+		switch typ {
+		case "S":
+			var s string
+			err := json.Unmarshal(v, &s)
+			return &types.AttributeValueMemberS{Value: s}, err
+		case "N":
+			var n string
+			err := json.Unmarshal(v, &n)
+			return &types.AttributeValueMemberN{Value: n}, err
+		case "B":
+			var b []byte
+			err := json.Unmarshal(v, &b)
+			return &types.AttributeValueMemberB{Value: b}, err
+		case "BOOL":
+			var b bool
+			err := json.Unmarshal(v, &b)
+			return &types.AttributeValueMemberBOOL{Value: b}, err
+		case "NULL":
+			var b bool
+			err := json.Unmarshal(v, &b)
+			return &types.AttributeValueMemberNULL{Value: b}, err
+		case "SS":
+			var ss []string
+			err := json.Unmarshal(v, &ss)
+			return &types.AttributeValueMemberSS{Value: ss}, err
+		case "NS":
+			var ns []string
+			err := json.Unmarshal(v, &ns)
+			return &types.AttributeValueMemberNS{Value: ns}, err
+		case "BS":
+			var encoded []string
+			if err := json.Unmarshal(v, &encoded); err != nil {
+				return nil, err
+			}
+			bs := make([][]byte, len(encoded))
+			for i, s := range encoded {
+				b, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return nil, err
+				}
+				bs[i] = b
+			}
+			return &types.AttributeValueMemberBS{Value: bs}, nil
+		case "L":
+			var elems []json.RawMessage
+			if err := json.Unmarshal(v, &elems); err != nil {
+				return nil, err
+			}
+			l := make([]types.AttributeValue, len(elems))
+			for i, elem := range elems {
+				av, err := decodeExportValue(elem)
+				if err != nil {
+					return nil, err
+				}
+				l[i] = av
+			}
+			return &types.AttributeValueMemberL{Value: l}, nil
+		case "M":
+			var attrs map[string]json.RawMessage
+			if err := json.Unmarshal(v, &attrs); err != nil {
+				return nil, err
+			}
+			m, err := decodeExportItem(attrs)
+			if err != nil {
+				return nil, err
+			}
+			return &types.AttributeValueMemberM{Value: m}, nil
+		default:
+			return nil, fmt.Errorf("unknown attribute value type %q", typ)
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,66 @@
+package dynabuf_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUnmarshalEach(t *testing.T) {
+	items := []map[string]types.AttributeValue{
+		dynabuf.MustMarshalItem(&testpb.User{Id: "1", Name: "Alice"}),
+		dynabuf.MustMarshalItem(&testpb.User{Id: "2", Name: "Bob"}),
+	}
+
+	var names []string
+	err := dynabuf.UnmarshalEach(items, &testpb.User{}, func(msg proto.Message) error {
+		names = append(names, msg.(*testpb.User).GetName())
+		return nil
+	})
+	must.NoError(t, err)
+	must.Eq(t, []string{"Alice", "Bob"}, names)
+
+	// Messages of a pool are given back once handled.
+	pool := &countingPool{}
+	err = dynabuf.UnmarshalEach(items, &testpb.User{}, func(proto.Message) error { return nil }, dynabuf.WithMessagePool(pool))
+	must.NoError(t, err)
+	must.Eq(t, 2, pool.taken)
+	must.Eq(t, 2, pool.resets)
+	must.SliceLen(t, 1, pool.free)
+
+	// Errors of fn stop the iteration.
+	errStop := errors.New("stop")
+	calls := 0
+	err = dynabuf.UnmarshalEach(items, &testpb.User{}, func(proto.Message) error {
+		calls++
+		return errStop
+	})
+	must.ErrorIs(t, err, errStop)
+	must.Eq(t, 1, calls)
+}
+
+func TestUnmarshalEachExport(t *testing.T) {
+	export := `{"Item":{"id":{"S":"1"},"age":{"N":"30"},"previousAddresses":{"L":[{"M":{"street":{"S":"1 Main St"}}}]}}}
+{"Item":{"id":{"S":"2"},"name":{"S":"Bob"}}}
+`
+
+	var users []*testpb.User
+	err := dynabuf.UnmarshalEachExport(strings.NewReader(export), &testpb.User{}, func(msg proto.Message) error {
+		users = append(users, msg.(*testpb.User))
+		return nil
+	})
+	must.NoError(t, err)
+	must.SliceLen(t, 2, users)
+	must.Eq(t, int32(30), users[0].GetAge())
+	must.Eq(t, "1 Main St", users[0].GetPreviousAddresses()[0].GetStreet())
+	must.Eq(t, "Bob", users[1].GetName())
+
+	err = dynabuf.UnmarshalEachExport(strings.NewReader(`{"Item":{"id":{"X":"1"}}}`), &testpb.User{}, func(proto.Message) error { return nil })
+	must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+}
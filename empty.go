@@ -0,0 +1,183 @@
+package dynabuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// EmptyMessagePolicy is how [Marshal] stores the message fields set to an
+// empty message, such as a google.protobuf.Empty, or a message whose fields
+// are all default values, and how [Unmarshal] reads them back.
+//
+// Every policy but [EmptyMessageOmit] keeps whether such a field is set.
+// Messages which are not empty are always stored as maps of their fields,
+// and the item of an empty message itself, rather than of a field, is an
+// empty map.
+type EmptyMessagePolicy int
+
+const (
+	// EmptyMessageMap stores an empty map, which is the default.
+	EmptyMessageMap EmptyMessagePolicy = iota
+
+	// EmptyMessagePlaceholder stores a map holding only the
+	// [EmptyMessageAttribute] attribute, set to true, for tools and
+	// expressions treating empty maps as missing values.
+	EmptyMessagePlaceholder
+
+	// EmptyMessageNull stores a NULL value.
+	EmptyMessageNull
+
+	// EmptyMessageOmit leaves the field out, so it is read back as unset.
+	// Empty messages within lists and maps are stored as empty maps, as
+	// leaving them out would drop their elements.
+	EmptyMessageOmit
+)
+
+// EmptyMessageAttribute is the attribute of the map stored for an empty
+// message with [EmptyMessagePlaceholder].
+const EmptyMessageAttribute = "_empty"
+
+// WithEmptyMessages stores the message fields set to an empty message with
+// the given policy when marshaling, and reads back the placeholders and
+// NULL values of the policy as empty messages when unmarshaling, so the
+// fields are set again. Items must be read with the policy they were
+// written with.
+//
+// Well-known messages other than google.protobuf.Empty, and google.type
+// messages, are stored as is, as most have a JSON form other than a map of
+// their fields.
+//
+// # Example
+//
+//	item, _ := dynabuf.Marshal(task, dynabuf.WithEmptyMessages(dynabuf.EmptyMessageNull))
+//
+//	_ = dynabuf.Unmarshal(item, &task, dynabuf.WithEmptyMessages(dynabuf.EmptyMessageNull))
+func WithEmptyMessages(policy EmptyMessagePolicy) Option {
+	return func(o *options) {
+		o.emptyMessages = policy
+	}
+}
+
+// hasEmptyForm reports whether empty messages of type md are stored by the
+// policy of [WithEmptyMessages].
+func hasEmptyForm(md protoreflect.MessageDescriptor) bool {
+	name := string(md.FullName())
+	if name == "google.protobuf.Empty" {
+		return true
+	}
+	return !strings.HasPrefix(name, "google.protobuf.") && !strings.HasPrefix(name, "google.type.")
+}
+
+// encodeEmptyMessages replaces the empty messages of the fields of md in
+// fields, and of its nested messages, with their form of the policy.
+func encodeEmptyMessages(md protoreflect.MessageDescriptor, fields map[string]any, policy EmptyMessagePolicy) {
+	encode := func(nested protoreflect.MessageDescriptor, v any) (any, bool) {
+		m, ok := v.(map[string]any)
+		switch {
+		case !ok || !hasEmptyForm(nested):
+			return v, true
+		case len(m) > 0:
+			encodeEmptyMessages(nested, m, policy)
+			return v, true
+		case policy == EmptyMessagePlaceholder:
+			return map[string]any{EmptyMessageAttribute: true}, true
+		case policy == EmptyMessageNull:
+			return nil, true
+		}
+		return v, false
+	}
+
+	for name, v := range fields {
+		fd := aliasedField(md, name)
+		if fd == nil {
+			continue
+		}
+		nested := fieldMessage(fd)
+		if nested == nil {
+			continue
+		}
+
+		switch {
+		case fd.IsMap():
+			m, _ := v.(map[string]any)
+			for k, elem := range m {
+				m[k], _ = encode(nested, elem)
+			}
+		case fd.IsList():
+			list, _ := v.([]any)
+			for i, elem := range list {
+				list[i], _ = encode(nested, elem)
+			}
+		default:
+			var keep bool
+			if fields[name], keep = encode(nested, v); !keep {
+				delete(fields, name)
+			}
+		}
+	}
+}
+
+// decodeEmptyMessages returns the stored JSON form of a message of type
+// md, with the forms of empty messages of the policy replaced by empty
+// messages.
+func decodeEmptyMessages(md protoreflect.MessageDescriptor, data []byte, policy EmptyMessagePolicy) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var fields map[string]any
+	if err := d.Decode(&fields); err != nil {
+		return nil, err
+	}
+	decodeEmptyFields(md, fields, policy)
+	return json.Marshal(fields)
+}
+
+// decodeEmptyFields replaces the forms of empty messages of the policy of
+// the fields of md in fields, and of its nested messages, with empty maps.
+func decodeEmptyFields(md protoreflect.MessageDescriptor, fields map[string]any, policy EmptyMessagePolicy) {
+	decode := func(nested protoreflect.MessageDescriptor, v any) any {
+		if !hasEmptyForm(nested) {
+			return v
+		}
+		m, ok := v.(map[string]any)
+		switch {
+		case v == nil && policy == EmptyMessageNull:
+			return map[string]any{}
+		case !ok:
+			return v
+		case policy == EmptyMessagePlaceholder && len(m) == 1 && m[EmptyMessageAttribute] == true:
+			return map[string]any{}
+		}
+		decodeEmptyFields(nested, m, policy)
+		return v
+	}
+
+	for name, v := range fields {
+		fd := aliasedField(md, name)
+		if fd == nil {
+			continue
+		}
+		nested := fieldMessage(fd)
+		if nested == nil {
+			continue
+		}
+
+		switch {
+		case fd.IsMap():
+			m, _ := v.(map[string]any)
+			for k, elem := range m {
+				m[k] = decode(nested, elem)
+			}
+		case fd.IsList():
+			list, _ := v.([]any)
+			for i, elem := range list {
+				list[i] = decode(nested, elem)
+			}
+		default:
+			fields[name] = decode(nested, v)
+		}
+	}
+}
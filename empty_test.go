@@ -0,0 +1,81 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEmptyMessages(t *testing.T) {
+	user := &testpb.User{
+		Id:                "1",
+		Address:           &testpb.Address{},
+		PreviousAddresses: []*testpb.Address{{}, {Street: "Main St"}},
+	}
+	placeholder := &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		dynabuf.EmptyMessageAttribute: &types.AttributeValueMemberBOOL{Value: true},
+	}}
+
+	tests := []struct {
+		name     string
+		policy   dynabuf.EmptyMessagePolicy
+		address  types.AttributeValue
+		previous types.AttributeValue
+		want     *testpb.User
+	}{
+		{
+			name:     "map",
+			policy:   dynabuf.EmptyMessageMap,
+			address:  &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+			previous: &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+			want:     user,
+		},
+		{
+			name:     "placeholder",
+			policy:   dynabuf.EmptyMessagePlaceholder,
+			address:  placeholder,
+			previous: placeholder,
+			want:     user,
+		},
+		{
+			name:     "null",
+			policy:   dynabuf.EmptyMessageNull,
+			address:  &types.AttributeValueMemberNULL{Value: true},
+			previous: &types.AttributeValueMemberNULL{Value: true},
+			want:     user,
+		},
+		{
+			name:     "omit",
+			policy:   dynabuf.EmptyMessageOmit,
+			previous: &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+			want: &testpb.User{
+				Id:                "1",
+				PreviousAddresses: user.GetPreviousAddresses(),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			av, err := dynabuf.Marshal(user, dynabuf.WithEmptyMessages(test.policy))
+			must.NoError(t, err)
+			item := av.(map[string]types.AttributeValue)
+
+			if test.address == nil {
+				must.MapNotContainsKey(t, item, "address")
+			} else {
+				must.Eq(t, test.address, item["address"])
+			}
+			previous := item["previousAddresses"].(*types.AttributeValueMemberL).Value
+			must.Eq(t, test.previous, previous[0])
+
+			var got testpb.User
+			must.NoError(t, dynabuf.Unmarshal(item, &got, dynabuf.WithEmptyMessages(test.policy)))
+			must.True(t, proto.Equal(test.want, &got))
+		})
+	}
+}
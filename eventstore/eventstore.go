@@ -0,0 +1,344 @@
+// Package eventstore implements append-only event streams backed by
+// DynamoDB, for event sourced aggregates whose events are protobuf
+// messages.
+//
+// Each event is an item keyed by the ID of its aggregate and its sequence
+// number within the aggregate's stream, starting at 1. Events are appended
+// with conditional writes that never overwrite an existing event, so two
+// writers that decided on new events from the same version of an aggregate
+// cannot both succeed, and readers replay every event in order.
+//
+// # Table Schema
+//
+// The table must use a string partition key named "aggregateId" and a
+// number sort key named "sequence":
+//
+//	KeySchema: []types.KeySchemaElement{
+//	  {
+//	    AttributeName: aws.String("aggregateId"),
+//	    KeyType:       types.KeyTypeHash,
+//	  },
+//	  {
+//	    AttributeName: aws.String("sequence"),
+//	    KeyType:       types.KeyTypeRange,
+//	  },
+//	},
+//
+// The event message is stored in the "event" map attribute of the item as
+// encoded by [dynabuf.Marshal], along with its type in "eventType" and the
+// time it was appended in "createTime".
+//
+// # Snapshots
+//
+// Aggregates with long streams can be loaded from a snapshot of their state
+// with [Load], which replays only the events following it. The latest
+// snapshot of an aggregate is stored in the same partition, as the item
+// with sequence number 0, so snapshots need no other table.
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxAppend is the maximum number of events appended at once, which is the
+// maximum number of items in a DynamoDB transaction.
+const MaxAppend = 100
+
+// ErrVersionConflict is returned when appending to an aggregate whose
+// stream has changed since the expected version.
+var ErrVersionConflict = errors.New("eventstore: aggregate was changed concurrently")
+
+// Client is the subset of the DynamoDB API used by a [Store].
+type Client interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+// Event is an event of type E read from an aggregate's stream.
+type Event[E proto.Message] struct {
+	// AggregateID is the ID of the aggregate the event belongs to.
+	AggregateID string
+
+	// Sequence is the position of the event in the aggregate's stream,
+	// starting at 1.
+	Sequence uint64
+
+	// CreateTime is the time the event was appended.
+	CreateTime time.Time
+
+	// Data is the decoded event message.
+	Data E
+}
+
+// Store appends and reads events of type E stored in a DynamoDB table.
+// Aggregates with several kinds of events usually use a single event
+// message with a oneof of every kind.
+//
+// A Store is safe for concurrent use.
+type Store[E proto.Message] struct {
+	client Client
+	table  string
+	cfg    *dynabuf.Config
+}
+
+// New returns a [Store] storing events in the given table, sending its
+// requests with the configuration of opts.
+func New[E proto.Message](client Client, table string, opts ...dynabuf.ConfigOption) *Store[E] {
+	return &Store[E]{
+		client: client,
+		table:  table,
+		cfg:    dynabuf.NewConfig(opts...),
+	}
+}
+
+// Append appends events to the stream of an aggregate whose latest event
+// is expected to have the given sequence number, or 0 for a new aggregate,
+// and returns the sequence number of the last event appended.
+//
+// Either all events are appended or none are. If another event was
+// appended after the expected version, Append returns
+// [ErrVersionConflict], and the caller should reload the aggregate and
+// decide again.
+//
+// # Example
+//
+//	store := eventstore.New[*example.AccountEvent](client, "events")
+//
+//	version, err := store.Append(ctx, "account-1", 0, &example.AccountEvent{
+//	  Event: &example.AccountEvent_Opened{Opened: &example.Opened{Owner: "alice"}},
+//	})
+func (s *Store[E]) Append(ctx context.Context, aggregateID string, expected uint64, events ...E) (uint64, error) {
+	if len(events) == 0 {
+		return expected, nil
+	}
+	if len(events) > MaxAppend {
+		return 0, fmt.Errorf("eventstore: cannot append more than %d events at once, got %d", MaxAppend, len(events))
+	}
+
+	now := s.cfg.Now()
+
+	items := make([]types.TransactWriteItem, len(events))
+	for i, event := range events {
+		seq := expected + uint64(i) + 1
+
+		data, err := dynabuf.Marshal(event)
+		if err != nil {
+			return 0, fmt.Errorf("eventstore: failed to encode event %d of %q: %w", seq, aggregateID, err)
+		}
+
+		items[i] = types.TransactWriteItem{
+			Put: &types.Put{
+				TableName: aws.String(s.table),
+				Item: map[string]types.AttributeValue{
+					"aggregateId": &types.AttributeValueMemberS{Value: aggregateID},
+					"sequence":    sequenceValue(seq),
+					"eventType":   &types.AttributeValueMemberS{Value: string(event.ProtoReflect().Descriptor().FullName())},
+					"createTime":  &types.AttributeValueMemberS{Value: now.UTC().Format(time.RFC3339Nano)},
+					"event":       &types.AttributeValueMemberM{Value: data.(map[string]types.AttributeValue)},
+				},
+				ConditionExpression:      aws.String("attribute_not_exists(#sequence)"),
+				ExpressionAttributeNames: map[string]string{"#sequence": "sequence"},
+			},
+		}
+	}
+
+	// The stream has no gaps, so the first event must also follow the
+	// expected version.
+	if expected > 0 {
+		items = append(items, types.TransactWriteItem{
+			ConditionCheck: &types.ConditionCheck{
+				TableName: aws.String(s.table),
+				Key: map[string]types.AttributeValue{
+					"aggregateId": &types.AttributeValueMemberS{Value: aggregateID},
+					"sequence":    sequenceValue(expected),
+				},
+				ConditionExpression:      aws.String("attribute_exists(#sequence)"),
+				ExpressionAttributeNames: map[string]string{"#sequence": "sequence"},
+			},
+		})
+	}
+
+	var zero E
+	ctx = dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	_, err := dynabuf.Do(ctx, s.cfg, s.client.TransactWriteItems, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) && isConditionalCheckFailed(canceled) {
+			return 0, fmt.Errorf("%w: %q is not at version %d", ErrVersionConflict, aggregateID, expected)
+		}
+		return 0, fmt.Errorf("eventstore: failed to append events to %q: %w", aggregateID, err)
+	}
+
+	return expected + uint64(len(events)), nil
+}
+
+// Events returns the events of an aggregate's stream with a sequence
+// number greater than after, in order, reading them page by page as the
+// sequence is iterated. Pass 0 to replay the whole stream.
+//
+// # Example
+//
+//	var account example.Account
+//
+//	for event, err := range store.Events(ctx, "account-1", 0) {
+//	  if err != nil {
+//	    return err
+//	  }
+//	  apply(&account, event.Data)
+//	  version = event.Sequence
+//	}
+func (s *Store[E]) Events(ctx context.Context, aggregateID string, after uint64) iter.Seq2[Event[E], error] {
+	return func(yield func(Event[E], error) bool) {
+		var zero E
+		ctx := dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(s.table),
+			KeyConditionExpression: aws.String("#aggregateId = :aggregateId AND #sequence > :after"),
+			ExpressionAttributeNames: map[string]string{
+				"#aggregateId": "aggregateId",
+				"#sequence":    "sequence",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":aggregateId": &types.AttributeValueMemberS{Value: aggregateID},
+				":after":       sequenceValue(after),
+			},
+			ConsistentRead: aws.Bool(true),
+		}
+
+		for {
+			out, err := dynabuf.Do(ctx, s.cfg, s.client.Query, input)
+			if err != nil {
+				yield(Event[E]{}, fmt.Errorf("eventstore: failed to read events of %q: %w", aggregateID, err))
+				return
+			}
+
+			for _, item := range out.Items {
+				event, err := decodeEvent[E](item)
+				if err != nil {
+					yield(Event[E]{}, err)
+					return
+				}
+				if !yield(event, nil) {
+					return
+				}
+			}
+
+			if len(out.LastEvaluatedKey) == 0 {
+				return
+			}
+			input.ExclusiveStartKey = out.LastEvaluatedKey
+		}
+	}
+}
+
+// Version returns the sequence number of the latest event of an
+// aggregate's stream, or 0 if it has no events.
+func (s *Store[E]) Version(ctx context.Context, aggregateID string) (uint64, error) {
+	var zero E
+	ctx = dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	out, err := dynabuf.Do(ctx, s.cfg, s.client.Query, &dynamodb.QueryInput{
+		TableName:                aws.String(s.table),
+		KeyConditionExpression:   aws.String("#aggregateId = :aggregateId AND #sequence > :snapshot"),
+		ExpressionAttributeNames: map[string]string{"#aggregateId": "aggregateId", "#sequence": "sequence"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":aggregateId": &types.AttributeValueMemberS{Value: aggregateID},
+			":snapshot":    sequenceValue(snapshotSequence),
+		},
+		ProjectionExpression: aws.String("#sequence"),
+		ScanIndexForward:     aws.Bool(false),
+		Limit:                aws.Int32(1),
+		ConsistentRead:       aws.Bool(true),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: failed to read version of %q: %w", aggregateID, err)
+	}
+
+	if len(out.Items) == 0 {
+		return 0, nil
+	}
+	return parseSequence(out.Items[0])
+}
+
+// decodeEvent decodes an event item.
+func decodeEvent[E proto.Message](item map[string]types.AttributeValue) (Event[E], error) {
+	var event Event[E]
+
+	id, _ := item["aggregateId"].(*types.AttributeValueMemberS)
+	if id == nil {
+		return event, fmt.Errorf("eventstore: event item is missing its aggregate ID")
+	}
+	event.AggregateID = id.Value
+
+	seq, err := parseSequence(item)
+	if err != nil {
+		return event, err
+	}
+	event.Sequence = seq
+
+	if ct, ok := item["createTime"].(*types.AttributeValueMemberS); ok {
+		event.CreateTime, err = time.Parse(time.RFC3339Nano, ct.Value)
+		if err != nil {
+			return event, fmt.Errorf("eventstore: invalid create time of event %d of %q: %w", seq, id.Value, err)
+		}
+	}
+
+	data, ok := item["event"].(*types.AttributeValueMemberM)
+	if !ok {
+		return event, fmt.Errorf("eventstore: event %d of %q has no event message", seq, id.Value)
+	}
+
+	var zero E
+	event.Data = zero.ProtoReflect().New().Interface().(E)
+	if err := dynabuf.Unmarshal(data.Value, event.Data); err != nil {
+		return event, fmt.Errorf("eventstore: failed to decode event %d of %q: %w", seq, id.Value, err)
+	}
+
+	return event, nil
+}
+
+// sequenceValue returns the attribute value of a sequence number, stored
+// as a number so events sort in order.
+func sequenceValue(seq uint64) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatUint(seq, 10)}
+}
+
+// parseSequence returns the sequence number of an event item.
+func parseSequence(item map[string]types.AttributeValue) (uint64, error) {
+	n, ok := item["sequence"].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, fmt.Errorf("eventstore: event item is missing its sequence number")
+	}
+
+	seq, err := strconv.ParseUint(n.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: invalid sequence number: %w", err)
+	}
+	return seq, nil
+}
+
+// isConditionalCheckFailed reports whether a transaction was canceled
+// because of a failed condition.
+func isConditionalCheckFailed(err *types.TransactionCanceledException) bool {
+	for _, r := range err.CancellationReasons {
+		if aws.ToString(r.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package eventstore_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/eventstore"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func newStore(t *testing.T) *eventstore.Store[*testpb.Job] {
+	t.Helper()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("events"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("aggregateId"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("sequence"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	return eventstore.New[*testpb.Job](client, "events")
+}
+
+func collect(t *testing.T, store *eventstore.Store[*testpb.Job], id string, after uint64) []eventstore.Event[*testpb.Job] {
+	t.Helper()
+
+	var events []eventstore.Event[*testpb.Job]
+	for event, err := range store.Events(context.Background(), id, after) {
+		must.NoError(t, err)
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		check func(t *testing.T, store *eventstore.Store[*testpb.Job])
+	}{
+		{
+			name: "appends and replays in order",
+			check: func(t *testing.T, store *eventstore.Store[*testpb.Job]) {
+				version, err := store.Append(ctx, "job-1", 0,
+					&testpb.Job{Id: "job-1", State: testpb.Job_STATE_RUNNING},
+				)
+				must.NoError(t, err)
+				must.Eq(t, uint64(1), version)
+
+				// Enough events for the sequence numbers to sort
+				// differently as strings than as numbers.
+				var more []*testpb.Job
+				for range 10 {
+					more = append(more, &testpb.Job{Id: "job-1", State: testpb.Job_STATE_FAILED})
+				}
+				version, err = store.Append(ctx, "job-1", version, more...)
+				must.NoError(t, err)
+				must.Eq(t, uint64(11), version)
+
+				_, err = store.Append(ctx, "job-2", 0, &testpb.Job{Id: "job-2"})
+				must.NoError(t, err)
+
+				events := collect(t, store, "job-1", 0)
+				must.SliceLen(t, 11, events)
+				for i, event := range events {
+					must.Eq(t, "job-1", event.AggregateID)
+					must.Eq(t, uint64(i+1), event.Sequence)
+					must.False(t, event.CreateTime.IsZero())
+				}
+				must.Eq(t, testpb.Job_STATE_RUNNING, events[0].Data.GetState())
+				must.Eq(t, testpb.Job_STATE_FAILED, events[10].Data.GetState())
+
+				events = collect(t, store, "job-1", 9)
+				must.SliceLen(t, 2, events)
+				must.Eq(t, uint64(10), events[0].Sequence)
+
+				version, err = store.Version(ctx, "job-1")
+				must.NoError(t, err)
+				must.Eq(t, uint64(11), version)
+			},
+		},
+		{
+			name: "empty stream",
+			check: func(t *testing.T, store *eventstore.Store[*testpb.Job]) {
+				must.SliceEmpty(t, collect(t, store, "job-1", 0))
+
+				version, err := store.Version(ctx, "job-1")
+				must.NoError(t, err)
+				must.Eq(t, uint64(0), version)
+			},
+		},
+		{
+			name: "rejects stale versions without appending",
+			check: func(t *testing.T, store *eventstore.Store[*testpb.Job]) {
+				_, err := store.Append(ctx, "job-1", 0, &testpb.Job{Id: "1"}, &testpb.Job{Id: "2"})
+				must.NoError(t, err)
+
+				_, err = store.Append(ctx, "job-1", 1, &testpb.Job{Id: "3"}, &testpb.Job{Id: "4"})
+				must.ErrorIs(t, err, eventstore.ErrVersionConflict)
+
+				// Versions ahead of the stream would leave a gap.
+				_, err = store.Append(ctx, "job-1", 5, &testpb.Job{Id: "3"})
+				must.ErrorIs(t, err, eventstore.ErrVersionConflict)
+
+				events := collect(t, store, "job-1", 0)
+				must.SliceLen(t, 2, events)
+				must.Eq(t, "2", events[1].Data.GetId())
+			},
+		},
+		{
+			name: "concurrent appends from the same version",
+			check: func(t *testing.T, store *eventstore.Store[*testpb.Job]) {
+				var (
+					wg        sync.WaitGroup
+					mu        sync.Mutex
+					succeeded int
+				)
+
+				for range 10 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, err := store.Append(ctx, "job-1", 0, &testpb.Job{Id: "1"})
+						if err != nil {
+							must.ErrorIs(t, err, eventstore.ErrVersionConflict)
+							return
+						}
+
+						mu.Lock()
+						defer mu.Unlock()
+						succeeded++
+					}()
+				}
+				wg.Wait()
+
+				must.Eq(t, 1, succeeded)
+				must.SliceLen(t, 1, collect(t, store, "job-1", 0))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.check(t, newStore(t))
+		})
+	}
+}
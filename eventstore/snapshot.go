@@ -0,0 +1,187 @@
+package eventstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// snapshotSequence is the sequence number of the item holding the latest
+// snapshot of an aggregate, which sorts before every event.
+const snapshotSequence = 0
+
+// SaveSnapshot saves state as the snapshot of an aggregate after the event
+// with the given sequence number, replacing any older snapshot. It reports
+// whether the snapshot was saved, which it is not if a snapshot of the same
+// or a later version exists.
+//
+// The snapshot is stored as the gzip compressed binary encoding of state,
+// so it stays small and is not affected by changes to the JSON names of
+// its fields.
+func (s *Store[E]) SaveSnapshot(ctx context.Context, aggregateID string, version uint64, state proto.Message) (bool, error) {
+	var buf bytes.Buffer
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(state)
+	if err != nil {
+		return false, fmt.Errorf("eventstore: failed to encode snapshot of %q: %w", aggregateID, err)
+	}
+
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return false, fmt.Errorf("eventstore: failed to compress snapshot of %q: %w", aggregateID, err)
+	}
+	if err := zw.Close(); err != nil {
+		return false, fmt.Errorf("eventstore: failed to compress snapshot of %q: %w", aggregateID, err)
+	}
+
+	_, err = dynabuf.Do(dynabuf.ContextWithMessage(ctx, state), s.cfg, s.client.PutItem, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item: map[string]types.AttributeValue{
+			"aggregateId":      &types.AttributeValueMemberS{Value: aggregateID},
+			"sequence":         sequenceValue(snapshotSequence),
+			"snapshotSequence": sequenceValue(version),
+			"snapshotType":     &types.AttributeValueMemberS{Value: string(state.ProtoReflect().Descriptor().FullName())},
+			"snapshot":         &types.AttributeValueMemberB{Value: buf.Bytes()},
+			"createTime":       &types.AttributeValueMemberS{Value: s.cfg.Now().UTC().Format(time.RFC3339Nano)},
+		},
+		ConditionExpression:      aws.String("attribute_not_exists(#snapshotSequence) OR #snapshotSequence < :version"),
+		ExpressionAttributeNames: map[string]string{"#snapshotSequence": "snapshotSequence"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":version": sequenceValue(version),
+		},
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return false, nil
+		}
+		return false, fmt.Errorf("eventstore: failed to save snapshot of %q: %w", aggregateID, err)
+	}
+
+	return true, nil
+}
+
+// LoadSnapshot decodes the latest snapshot of an aggregate into state, and
+// returns the sequence number of the last event it includes. It returns 0
+// and leaves state unchanged if the aggregate has no snapshot, or only one
+// of a different message type.
+func (s *Store[E]) LoadSnapshot(ctx context.Context, aggregateID string, state proto.Message) (uint64, error) {
+	out, err := dynabuf.Do(dynabuf.ContextWithMessageType(ctx, state), s.cfg, s.client.GetItem, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key: map[string]types.AttributeValue{
+			"aggregateId": &types.AttributeValueMemberS{Value: aggregateID},
+			"sequence":    sequenceValue(snapshotSequence),
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: failed to read snapshot of %q: %w", aggregateID, err)
+	}
+
+	typ, _ := out.Item["snapshotType"].(*types.AttributeValueMemberS)
+	if typ == nil || typ.Value != string(state.ProtoReflect().Descriptor().FullName()) {
+		return 0, nil
+	}
+
+	n, ok := out.Item["snapshotSequence"].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, fmt.Errorf("eventstore: snapshot of %q is missing its sequence number", aggregateID)
+	}
+	version, err := strconv.ParseUint(n.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: invalid snapshot sequence number of %q: %w", aggregateID, err)
+	}
+
+	compressed, ok := out.Item["snapshot"].(*types.AttributeValueMemberB)
+	if !ok {
+		return 0, fmt.Errorf("eventstore: snapshot of %q has no data", aggregateID)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed.Value))
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: failed to decompress snapshot of %q: %w", aggregateID, err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: failed to decompress snapshot of %q: %w", aggregateID, err)
+	}
+
+	if err := proto.Unmarshal(data, state); err != nil {
+		return 0, fmt.Errorf("eventstore: failed to decode snapshot of %q: %w", aggregateID, err)
+	}
+
+	return version, nil
+}
+
+// Load returns the current state of an aggregate and the sequence number of
+// its latest event, starting from its latest snapshot, if any, and applying
+// every following event in order with apply. An aggregate without snapshot
+// or events is returned as an empty message at version 0.
+//
+// If the aggregate's message is annotated with snapshot options, a new
+// snapshot is saved once at least that many events were applied:
+//
+//	message Account {
+//	  option (dynabuf.v1.message) = { snapshot: { every: 100 } };
+//	  ...
+//	}
+//
+// # Example
+//
+//	account, version, err := eventstore.Load(ctx, store, "account-1",
+//	  func(account *example.Account, event eventstore.Event[*example.AccountEvent]) error {
+//	    account.Balance += event.Data.GetDeposited().GetAmount()
+//	    return nil
+//	  },
+//	)
+func Load[A, E proto.Message](ctx context.Context, store *Store[E], aggregateID string, apply func(A, Event[E]) error) (A, uint64, error) {
+	var zero A
+	state := zero.ProtoReflect().New().Interface().(A)
+
+	snapshot, err := store.LoadSnapshot(ctx, aggregateID, state)
+	if err != nil {
+		return zero, 0, err
+	}
+
+	version := snapshot
+	for event, err := range store.Events(ctx, aggregateID, snapshot) {
+		if err != nil {
+			return zero, 0, err
+		}
+		if err := apply(state, event); err != nil {
+			return zero, 0, fmt.Errorf("eventstore: failed to apply event %d of %q: %w", event.Sequence, aggregateID, err)
+		}
+		version = event.Sequence
+	}
+
+	if every := snapshotEvery(state.ProtoReflect().Descriptor()); every > 0 && version-snapshot >= uint64(every) {
+		if _, err := store.SaveSnapshot(ctx, aggregateID, version, state); err != nil {
+			return zero, 0, err
+		}
+	}
+
+	return state, version, nil
+}
+
+// snapshotEvery returns the number of events between snapshots of an
+// aggregate, as annotated on its message, or 0 if it is not annotated.
+func snapshotEvery(md protoreflect.MessageDescriptor) uint32 {
+	opts, ok := proto.GetExtension(md.Options(), dynabufpb.E_Message).(*dynabufpb.MessageOptions)
+	if !ok {
+		return 0
+	}
+	return opts.GetSnapshot().GetEvery()
+}
@@ -0,0 +1,107 @@
+package eventstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/picatz/dynabuf/eventstore"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	var applied int
+	apply := func(summary *testpb.JobSummary, event eventstore.Event[*testpb.Job]) error {
+		applied++
+		summary.Id = event.Data.GetId()
+		summary.State = event.Data.GetState()
+		summary.Changes++
+		return nil
+	}
+
+	summary, version, err := eventstore.Load(ctx, store, "job-1", apply)
+	must.NoError(t, err)
+	must.Eq(t, uint64(0), version)
+	must.True(t, proto.Equal(&testpb.JobSummary{}, summary))
+
+	_, err = store.Append(ctx, "job-1", 0,
+		&testpb.Job{Id: "job-1", State: testpb.Job_STATE_RUNNING},
+		&testpb.Job{Id: "job-1", State: testpb.Job_STATE_FAILED},
+	)
+	must.NoError(t, err)
+
+	// Fewer events than the annotated interval are not snapshotted.
+	_, version, err = eventstore.Load(ctx, store, "job-1", apply)
+	must.NoError(t, err)
+	must.Eq(t, uint64(2), version)
+	must.Eq(t, 2, applied)
+
+	snapshot, err := store.LoadSnapshot(ctx, "job-1", &testpb.JobSummary{})
+	must.NoError(t, err)
+	must.Eq(t, uint64(0), snapshot)
+
+	_, err = store.Append(ctx, "job-1", 2, &testpb.Job{Id: "job-1", State: testpb.Job_STATE_RUNNING})
+	must.NoError(t, err)
+
+	applied = 0
+	summary, version, err = eventstore.Load(ctx, store, "job-1", apply)
+	must.NoError(t, err)
+	must.Eq(t, uint64(3), version)
+	must.Eq(t, 3, applied)
+	must.Eq(t, int32(3), summary.GetChanges())
+
+	snapshot, err = store.LoadSnapshot(ctx, "job-1", &testpb.JobSummary{})
+	must.NoError(t, err)
+	must.Eq(t, uint64(3), snapshot)
+
+	// Later loads start from the snapshot.
+	_, err = store.Append(ctx, "job-1", 3, &testpb.Job{Id: "job-1", State: testpb.Job_STATE_SUCCEEDED})
+	must.NoError(t, err)
+
+	applied = 0
+	summary, version, err = eventstore.Load(ctx, store, "job-1", apply)
+	must.NoError(t, err)
+	must.Eq(t, uint64(4), version)
+	must.Eq(t, 1, applied)
+	must.True(t, proto.Equal(&testpb.JobSummary{
+		Id:      "job-1",
+		State:   testpb.Job_STATE_SUCCEEDED,
+		Changes: 4,
+	}, summary))
+
+	// The snapshot is not part of the stream.
+	must.SliceLen(t, 4, collect(t, store, "job-1", 0))
+
+	version, err = store.Version(ctx, "job-1")
+	must.NoError(t, err)
+	must.Eq(t, uint64(4), version)
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	saved, err := store.SaveSnapshot(ctx, "job-1", 5, &testpb.JobSummary{Changes: 5})
+	must.NoError(t, err)
+	must.True(t, saved)
+
+	// Older snapshots never replace newer ones.
+	saved, err = store.SaveSnapshot(ctx, "job-1", 4, &testpb.JobSummary{Changes: 4})
+	must.NoError(t, err)
+	must.False(t, saved)
+
+	var summary testpb.JobSummary
+	version, err := store.LoadSnapshot(ctx, "job-1", &summary)
+	must.NoError(t, err)
+	must.Eq(t, uint64(5), version)
+	must.Eq(t, int32(5), summary.GetChanges())
+
+	// Snapshots of other message types are ignored.
+	version, err = store.LoadSnapshot(ctx, "job-1", &testpb.User{})
+	must.NoError(t, err)
+	must.Eq(t, uint64(0), version)
+}
@@ -0,0 +1,197 @@
+package dynabuf
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// encodingNotes describe how values are encoded within their type.
+var encodingNotes = map[dynabufpb.Encoding]string{
+	dynabufpb.Encoding_ENCODING_DECIMAL_STRING: "64-bit integer as decimal string",
+	dynabufpb.Encoding_ENCODING_BASE64:         "bytes as base64",
+	dynabufpb.Encoding_ENCODING_ENUM_NAME:      "enum value name",
+	dynabufpb.Encoding_ENCODING_FLOAT:          "float as number",
+	dynabufpb.Encoding_ENCODING_RFC3339:        "RFC 3339 timestamp",
+	dynabufpb.Encoding_ENCODING_DURATION:       "duration in seconds",
+	dynabufpb.Encoding_ENCODING_FIELD_MASK:     "comma separated field mask",
+	dynabufpb.Encoding_ENCODING_JSON:           "JSON value",
+	dynabufpb.Encoding_ENCODING_ANY:            "Any with @type",
+	dynabufpb.Encoding_ENCODING_DECIMAL:        "decimal string as number",
+	dynabufpb.Encoding_ENCODING_DATE:           "YYYY-MM-DD date",
+	dynabufpb.Encoding_ENCODING_TIME_OF_DAY:    "HH:MM:SS time of day",
+	dynabufpb.Encoding_ENCODING_DECIMAL_MONEY:  "money as amount number and currency code",
+	dynabufpb.Encoding_ENCODING_SORTABLE:       "sortable string",
+}
+
+// WithKeyAttributes names the key attributes of the items, the partition
+// key and then the sort key, if any, for [Explain] to report and validate
+// them as [ValidateKey] does, and for [WithNormalizedKeys] to normalize.
+// [Unmarshal] ignores it.
+func WithKeyAttributes(names ...string) Option {
+	names = slices.Clone(names)
+	return func(o *options) {
+		o.keyAttributes = names
+	}
+}
+
+// Explain returns a human readable breakdown of how [Marshal] stores msg
+// with the given options, for debugging mappings: the attribute every field
+// is stored as, with its attribute value type, size, and encoding, the
+// annotations and options which affected it, the fields omitted, and the
+// composite attributes derived from the fields, followed by the size of
+// the item.
+//
+// Key attributes named with [WithKeyAttributes] are marked, and problems
+// such as invalid keys and items larger than DynamoDB accepts are reported
+// as comments. The output is not stable and must not be parsed.
+//
+// # Example
+//
+//	fmt.Println(dynabuf.Explain(order, dynabuf.WithKeyAttributes("customer", "sk")))
+//
+// Prints:
+//
+//	ATTRIBUTE   TYPE  SIZE  SOURCE     NOTES
+//	customer    S     13    field 1    partition key
+//	id          S     4     field 2
+//	status      S     10    field 3
+//	createTime  S     30    field 4    RFC 3339 timestamp
+//	items       -     -     field 5    omitted: default value
+//	note        -     -     field 6    omitted: default value
+//	sk          S     36    composite  sort key, template "ORDER#{status}#{create_time}#{id}"
+//	bySize      S     12    composite  template "{customer}#{items}"
+//	# example.Order: 6 attributes, 105 bytes
+func Explain(msg proto.Message, opts ...Option) string {
+	o := newOptions(opts)
+	md := msg.ProtoReflect().Descriptor()
+
+	var b strings.Builder
+
+	item, err := marshalProtoMessage(msg, opts...)
+	if err != nil {
+		fmt.Fprintf(&b, "# failed to marshal %s: %v\n", md.FullName(), err)
+		return b.String()
+	}
+
+	roles := map[string]string{}
+	for i, name := range o.keyAttributes {
+		roles[name] = "partition key"
+		if i > 0 {
+			roles[name] = "sort key"
+		}
+	}
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ATTRIBUTE\tTYPE\tSIZE\tSOURCE\tNOTES")
+
+	row := func(name, source, omitted string, notes []string) {
+		if role, ok := roles[name]; ok {
+			notes = append([]string{role}, notes...)
+		}
+		typ, size := "-", "-"
+		if v, ok := item[name]; ok {
+			typ = attributeValueType(v)
+			size = fmt.Sprint(len(name) + attributeValueSize(v))
+		} else {
+			notes = append(notes, "omitted: "+omitted)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, typ, size, source, strings.Join(notes, ", "))
+	}
+
+	seen := map[string]bool{}
+
+	fields := md.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		seen[fd.JSONName()] = true
+
+		omitted := "default value"
+		if fd.HasPresence() {
+			omitted = "not set"
+		}
+		row(fd.JSONName(), fmt.Sprintf("field %d", fd.Number()), omitted, fieldNotes(fd, o))
+	}
+
+	mo, _ := proto.GetExtension(md.Options(), dynabufpb.E_Message).(*dynabufpb.MessageOptions)
+	for _, c := range mo.GetCompositeAttributes() {
+		seen[c.GetName()] = true
+		row(c.GetName(), "composite", "missing field values", []string{fmt.Sprintf("template %q", c.GetTemplate())})
+	}
+
+	// Attributes from neither, such as the entries of a google.protobuf.Struct
+	// stored as the item, are listed as they are.
+	for _, name := range slices.Sorted(maps.Keys(item)) {
+		if !seen[name] {
+			row(name, "value", "", nil)
+		}
+	}
+	w.Flush()
+
+	size := itemSize(item)
+	fmt.Fprintf(&b, "# %s: %d attributes, %d bytes\n", md.FullName(), len(item), size)
+
+	if len(o.keyAttributes) > 0 {
+		if err := ValidateKey(item, o.keyAttributes...); err != nil {
+			fmt.Fprintf(&b, "# %v\n", err)
+		}
+	}
+	if size > MaxItemSize {
+		fmt.Fprintf(&b, "# item is larger than the DynamoDB limit of %d bytes\n", MaxItemSize)
+	}
+
+	return b.String()
+}
+
+// fieldNotes returns the notes explaining how the values of the field are
+// stored, and which annotations and options affect them.
+func fieldNotes(fd protoreflect.FieldDescriptor, o *options) []string {
+	var notes []string
+
+	value := valueSpec(fd)
+	switch {
+	case fd.IsMap():
+		value = valueSpec(fd.MapValue())
+		notes = append(notes, "map")
+	case fd.IsList():
+		notes = append(notes, "list")
+	}
+
+	if note, ok := encodingNotes[value.GetEncoding()]; ok {
+		notes = append(notes, note)
+	}
+	if value.GetMessage() != "" {
+		notes = append(notes, value.GetMessage())
+	}
+	if value.GetEncoding() == dynabufpb.Encoding_ENCODING_FLOAT && o.floatFormat != 0 {
+		notes = append(notes, fmt.Sprintf("WithFloatFormat(%q, %d)", o.floatFormat, o.floatPrec))
+	}
+
+	if aliases := fieldAliases(fd); len(aliases) > 0 {
+		notes = append(notes, "also read from "+quoteNames(aliases))
+	}
+
+	for _, a := range []struct {
+		name string
+		ok   bool
+	}{
+		{"decimal", isDecimal(fd) || value.GetEncoding() == dynabufpb.Encoding_ENCODING_DECIMAL_MONEY},
+		{"sortable", isSortable(fd)},
+		{"volatile", isVolatile(fd)},
+		{"output only", isOutputOnly(fd)},
+		{"immutable", isImmutable(fd)},
+		{"write aliases", writesAliases(fd)},
+	} {
+		if a.ok {
+			notes = append(notes, a.name+" annotation")
+		}
+	}
+
+	return notes
+}
@@ -0,0 +1,86 @@
+package dynabuf_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name  string
+		msg   proto.Message
+		opts  []dynabuf.Option
+		lines []string
+	}{
+		{
+			name: "composite keys",
+			msg: &testpb.Order{
+				Customer:   "alice",
+				Id:         "o1",
+				Status:     "OPEN",
+				CreateTime: timestamppb.New(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+			},
+			opts: []dynabuf.Option{dynabuf.WithKeyAttributes("customer", "sk")},
+			lines: []string{
+				`customer S 13 field 1 partition key`,
+				`id S 4 field 2`,
+				`createTime S 30 field 4 RFC 3339 timestamp`,
+				`items - - field 5 omitted: default value`,
+				`sk S 36 composite sort key, template "ORDER#{status}#{create_time}#{id}"`,
+				`bySize S 12 composite template "{customer}#{items}"`,
+				`# dynabuf.test.v1.Order: 6 attributes, 105 bytes`,
+			},
+		},
+		{
+			name: "annotations and options",
+			msg:  &testpb.Product{Id: "p1", Price: "9.90", Weight: 1.5},
+			opts: []dynabuf.Option{dynabuf.WithFloatFormat('f', 2), dynabuf.WithKeyAttributes("sku")},
+			lines: []string{
+				`price N 8 field 2 decimal string as number, decimal annotation`,
+				`discounts - - field 3 list, decimal string as number, decimal annotation, omitted: default value`,
+				`weight N 9 field 4 float as number, WithFloatFormat('f', 2)`,
+				`bundled - - field 6 dynabuf.test.v1.Product, omitted: not set`,
+				`# dynabuf: invalid key: partition key attribute "sku" is missing`,
+			},
+		},
+		{
+			name: "behaviors",
+			msg:  &testpb.User{Id: "1"},
+			lines: []string{
+				`id S 3 field 1 volatile annotation, immutable annotation`,
+				`createTime - - field 6 RFC 3339 timestamp, volatile annotation, output only annotation, omitted: not set`,
+			},
+		},
+		{
+			name: "values",
+			msg:  &structpb.Struct{Fields: map[string]*structpb.Value{"a": structpb.NewBoolValue(true)}},
+			lines: []string{
+				`a BOOL 2 value`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Columns are aligned with spaces, which are collapsed to compare
+			// lines.
+			s := regexp.MustCompile(` +`).ReplaceAllString(dynabuf.Explain(test.msg, test.opts...), " ")
+			lines := strings.Split(s, "\n")
+			for i := range lines {
+				lines[i] = strings.TrimSpace(lines[i])
+			}
+			for _, line := range test.lines {
+				must.SliceContains(t, lines, line)
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+package dynabuf
+
+// Hedge exposes hedge to the tests of the failure paths of hedged reads,
+// whose requests are sent by a fake rather than a client.
+var Hedge = hedge
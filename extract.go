@@ -0,0 +1,118 @@
+package dynabuf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrAttributeNotFound is returned by [Extract] when the item has no
+// attribute at the path.
+var ErrAttributeNotFound = errors.New("dynabuf: attribute not found")
+
+// Extract returns the attribute at fieldPath of an item, decoded as T,
+// without decoding the rest of the item into a message, for hot paths
+// only needing a key or status field of items.
+//
+// The item av is a map[string]types.AttributeValue or a map attribute
+// value. The path names the attributes as they are stored, which are the
+// JSON names of fields, separated by dots, and the elements of lists by
+// their index in brackets, such as "previousAddresses[0].street".
+//
+// Attributes are decoded into messages as [Unmarshal] does, and into other
+// types as the attributevalue package does, so numbers decode into numeric
+// types, and timestamps, stored as strings, into time.Time. T may also be
+// types.AttributeValue, to get the attribute as is. If there is no
+// attribute at the path, the error wraps [ErrAttributeNotFound].
+//
+// # Example
+//
+//	status, err := dynabuf.Extract[string](out.Item, "status")
+//
+//	zip, err := dynabuf.Extract[int](out.Item, "address.zipCode")
+func Extract[T any](av any, fieldPath string) (T, error) {
+	var zero T
+
+	var item map[string]types.AttributeValue
+	switch av := av.(type) {
+	case map[string]types.AttributeValue:
+		item = av
+	case *types.AttributeValueMemberM:
+		item = av.Value
+	default:
+		return zero, fmt.Errorf("dynabuf: cannot extract attributes from %T", av)
+	}
+
+	attr, err := attributeAt(item, fieldPath)
+	if err != nil {
+		return zero, err
+	}
+
+	switch out := any(&zero).(type) {
+	case *types.AttributeValue:
+		*out = attr
+		return zero, nil
+	}
+
+	if _, ok := any(zero).(proto.Message); ok {
+		t := reflect.TypeFor[T]()
+		if t.Kind() != reflect.Pointer {
+			return zero, fmt.Errorf("dynabuf: cannot extract attribute %q into non-pointer message %v", fieldPath, t)
+		}
+		msg := reflect.New(t.Elem()).Interface().(T)
+		if err := Unmarshal(attr, msg); err != nil {
+			return zero, fmt.Errorf("dynabuf: failed to extract attribute %q: %w", fieldPath, err)
+		}
+		return msg, nil
+	}
+
+	if err := attributevalue.Unmarshal(attr, &zero); err != nil {
+		return zero, fmt.Errorf("dynabuf: failed to extract attribute %q: %w", fieldPath, err)
+	}
+	return zero, nil
+}
+
+// attributeAt returns the attribute of item at a path of attribute names
+// and list indexes, such as "a.b[0].c".
+func attributeAt(item map[string]types.AttributeValue, fieldPath string) (types.AttributeValue, error) {
+	if fieldPath == "" {
+		return nil, fmt.Errorf("dynabuf: empty attribute path")
+	}
+
+	var v types.AttributeValue = &types.AttributeValueMemberM{Value: item}
+	for _, segment := range strings.Split(fieldPath, ".") {
+		name, rest, _ := strings.Cut(segment, "[")
+		if name == "" {
+			return nil, fmt.Errorf("dynabuf: invalid attribute path %q", fieldPath)
+		}
+		m, ok := v.(*types.AttributeValueMemberM)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrAttributeNotFound, fieldPath)
+		}
+		if v = m.Value[name]; v == nil {
+			return nil, fmt.Errorf("%w: %q", ErrAttributeNotFound, fieldPath)
+		}
+
+		for rest != "" {
+			index, after, ok := strings.Cut(rest, "]")
+			i, err := strconv.Atoi(index)
+			if !ok || err != nil || i < 0 || (after != "" && after[0] != '[') {
+				return nil, fmt.Errorf("dynabuf: invalid attribute path %q", fieldPath)
+			}
+			rest = strings.TrimPrefix(after, "[")
+
+			l, ok := v.(*types.AttributeValueMemberL)
+			if !ok || i >= len(l.Value) {
+				return nil, fmt.Errorf("%w: %q", ErrAttributeNotFound, fieldPath)
+			}
+			v = l.Value[i]
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,63 @@
+package dynabuf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestExtract(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	item := dynabuf.MustMarshalItem(&testpb.User{
+		Id:                "1",
+		Name:              "Alice",
+		Age:               30,
+		Address:           &testpb.Address{Street: "Main St", ZipCode: 12345},
+		PreviousAddresses: []*testpb.Address{{Street: "Elm St"}},
+		CreateTime:        timestamppb.New(created),
+	})
+
+	name, err := dynabuf.Extract[string](item, "name")
+	must.NoError(t, err)
+	must.Eq(t, "Alice", name)
+
+	age, err := dynabuf.Extract[int](item, "age")
+	must.NoError(t, err)
+	must.Eq(t, 30, age)
+
+	zip, err := dynabuf.Extract[int64](item, "address.zipCode")
+	must.NoError(t, err)
+	must.Eq(t, 12345, zip)
+
+	street, err := dynabuf.Extract[string](item, "previousAddresses[0].street")
+	must.NoError(t, err)
+	must.Eq(t, "Elm St", street)
+
+	createTime, err := dynabuf.Extract[time.Time](item, "createTime")
+	must.NoError(t, err)
+	must.True(t, created.Equal(createTime))
+
+	// Messages are decoded as Unmarshal decodes them.
+	address, err := dynabuf.Extract[*testpb.Address](item, "address")
+	must.NoError(t, err)
+	must.True(t, proto.Equal(&testpb.Address{Street: "Main St", ZipCode: 12345}, address))
+
+	av, err := dynabuf.Extract[types.AttributeValue](item, "id")
+	must.NoError(t, err)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "1"}, av)
+
+	for _, path := range []string{"email", "address.city", "previousAddresses[1]", "name.first"} {
+		_, err := dynabuf.Extract[string](item, path)
+		must.ErrorIs(t, err, dynabuf.ErrAttributeNotFound)
+	}
+	for _, path := range []string{"", "address.", "previousAddresses[x]", "previousAddresses[0"} {
+		_, err := dynabuf.Extract[string](item, path)
+		must.Error(t, err)
+	}
+}
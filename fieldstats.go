@@ -0,0 +1,209 @@
+package dynabuf
+
+import (
+	"cmp"
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/internal/avtext"
+	"google.golang.org/protobuf/proto"
+)
+
+// cardinalitySketchSize is the number of value hashes kept per attribute to
+// estimate its cardinality. Up to this many distinct values are counted
+// exactly, and larger cardinalities are estimated within a few percent.
+const cardinalitySketchSize = 1024
+
+// FieldStats samples the top-level attributes of the items converted by
+// [Marshal] and [Unmarshal] with [WithFieldStats], recording the sizes and
+// the number of distinct values of each attribute, grouped by message type.
+//
+// Unlike [Stats], which scans a table, it observes the items an application
+// actually reads and writes, to guide the design of secondary indexes and
+// their projections: attributes with few distinct values make poor
+// partition keys, and large attributes are costly to project.
+//
+// FieldStats implements [expvar.Var], so it can be published as is:
+//
+//	stats := &dynabuf.FieldStats{SampleRate: 0.01}
+//
+//	expvar.Publish("dynabuf_field_stats", stats)
+//
+//	item, err := dynabuf.Marshal(&user, dynabuf.WithFieldStats(stats))
+//
+// A FieldStats is safe for concurrent use, and must not be copied after
+// first use.
+type FieldStats struct {
+	// SampleRate is the fraction of conversions sampled, between 0 and 1.
+	// Zero samples every conversion.
+	SampleRate float64
+
+	// Config, if set, provides the random source conversions are sampled
+	// with.
+	Config *Config
+
+	mu      sync.Mutex
+	items   map[string]int
+	samples map[failureKey]*fieldSample
+}
+
+// FieldReport describes the values of a top-level attribute of the items of
+// a message type sampled by a [FieldStats].
+type FieldReport struct {
+	// Message is the full name of the message type, such as "example.User".
+	Message string `json:"message"`
+
+	// Attribute is the name of the attribute.
+	Attribute string `json:"attribute"`
+
+	// Items is the number of items of the message type sampled, and Count
+	// the number of those with the attribute.
+	Items int `json:"items"`
+	Count int `json:"count"`
+
+	// MinSize, MaxSize, and TotalSize describe the sizes of the values in
+	// bytes, including the attribute name, as counted towards the DynamoDB
+	// item size limit.
+	MinSize   int `json:"minSize"`
+	MaxSize   int `json:"maxSize"`
+	TotalSize int `json:"totalSize"`
+
+	// Cardinality is the estimated number of distinct values sampled.
+	Cardinality int `json:"cardinality"`
+}
+
+// AverageSize returns the average size of the values in bytes.
+func (r FieldReport) AverageSize() float64 {
+	if r.Count == 0 {
+		return 0
+	}
+	return float64(r.TotalSize) / float64(r.Count)
+}
+
+// fieldSample holds the statistics of an attribute.
+type fieldSample struct {
+	count     int
+	minSize   int
+	maxSize   int
+	totalSize int
+
+	// hashes are the smallest distinct hashes of the values, in increasing
+	// order, from which the cardinality is estimated.
+	hashes []uint64
+}
+
+// WithFieldStats samples the items converted in s.
+func WithFieldStats(s *FieldStats) Option {
+	return func(o *options) {
+		o.fieldStats = s
+	}
+}
+
+// Report returns the statistics of every attribute sampled, ordered by
+// message type and then attribute name.
+func (s *FieldStats) Report() []FieldReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reports := make([]FieldReport, 0, len(s.samples))
+	for k, f := range s.samples {
+		reports = append(reports, FieldReport{
+			Message:     k.message,
+			Attribute:   k.path,
+			Items:       s.items[k.message],
+			Count:       f.count,
+			MinSize:     f.minSize,
+			MaxSize:     f.maxSize,
+			TotalSize:   f.totalSize,
+			Cardinality: f.cardinality(),
+		})
+	}
+
+	slices.SortFunc(reports, func(a, b FieldReport) int {
+		return cmp.Or(cmp.Compare(a.Message, b.Message), cmp.Compare(a.Attribute, b.Attribute))
+	})
+	return reports
+}
+
+// Reset discards the statistics sampled so far.
+func (s *FieldStats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items, s.samples = nil, nil
+}
+
+// String returns the report as a JSON array, implementing [expvar.Var].
+func (s *FieldStats) String() string {
+	b, _ := json.Marshal(s.Report())
+	return string(b)
+}
+
+// record samples the attributes of an item of the message type of msg.
+func (s *FieldStats) record(msg proto.Message, item map[string]types.AttributeValue) {
+	if s.SampleRate > 0 && s.Config.Rand().Float64() >= s.SampleRate {
+		return
+	}
+
+	message := string(msg.ProtoReflect().Descriptor().FullName())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.samples == nil {
+		s.items = map[string]int{}
+		s.samples = map[failureKey]*fieldSample{}
+	}
+	s.items[message]++
+
+	for name, v := range item {
+		k := failureKey{message: message, path: name}
+		f, ok := s.samples[k]
+		if !ok {
+			f = &fieldSample{}
+			s.samples[k] = f
+		}
+		f.add(len(name)+attributeValueSize(v), avtext.Format(v))
+	}
+}
+
+// add records a value of the given size and text.
+func (f *fieldSample) add(size int, text string) {
+	if f.count == 0 || size < f.minSize {
+		f.minSize = size
+	}
+	f.maxSize = max(f.maxSize, size)
+	f.totalSize += size
+	f.count++
+
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	hash := h.Sum64()
+
+	if len(f.hashes) == cardinalitySketchSize && hash >= f.hashes[len(f.hashes)-1] {
+		return
+	}
+	i, found := slices.BinarySearch(f.hashes, hash)
+	if found {
+		return
+	}
+	f.hashes = slices.Insert(f.hashes, i, hash)
+	if len(f.hashes) > cardinalitySketchSize {
+		f.hashes = f.hashes[:cardinalitySketchSize]
+	}
+}
+
+// cardinality returns the estimated number of distinct values, which is
+// exact while fewer than cardinalitySketchSize were seen, and otherwise
+// estimated from how densely the smallest hashes fill the hash space.
+func (f *fieldSample) cardinality() int {
+	if len(f.hashes) < cardinalitySketchSize {
+		return len(f.hashes)
+	}
+	last := float64(f.hashes[len(f.hashes)-1]) / math.MaxUint64
+	return int(float64(cardinalitySketchSize-1) / last)
+}
@@ -0,0 +1,85 @@
+package dynabuf_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestFieldStats(t *testing.T) {
+	stats := &dynabuf.FieldStats{}
+
+	names := []string{"Alice", "Bob", "Carol"}
+	items := make([]map[string]types.AttributeValue, 5000)
+	for i := range items {
+		user := &testpb.User{Id: fmt.Sprint(i), Name: names[i%len(names)]}
+		if i%2 == 0 {
+			user.Age = 30
+		}
+		av, err := dynabuf.Marshal(user, dynabuf.WithFieldStats(stats))
+		must.NoError(t, err)
+		items[i] = av.(map[string]types.AttributeValue)
+	}
+
+	reports := stats.Report()
+	must.SliceLen(t, 3, reports)
+
+	age, id, name := reports[0], reports[1], reports[2]
+
+	must.Eq(t, "dynabuf.test.v1.User", age.Message)
+	must.Eq(t, "age", age.Attribute)
+	must.Eq(t, 5000, age.Items)
+	must.Eq(t, 2500, age.Count)
+	must.Eq(t, 1, age.Cardinality)
+
+	// Beyond the values counted exactly, the cardinality is estimated.
+	must.Eq(t, "id", id.Attribute)
+	must.Eq(t, 5000, id.Count)
+	must.Between(t, 4500, id.Cardinality, 5500)
+	must.Eq(t, len("id")+1, id.MinSize)
+	must.Eq(t, len("id")+4, id.MaxSize)
+
+	must.Eq(t, "name", name.Attribute)
+	must.Eq(t, 3, name.Cardinality)
+	must.Eq(t, len("name")+len("Bob"), name.MinSize)
+	must.Eq(t, len("name")+len("Alice"), name.MaxSize)
+	must.Eq(t, float64(name.TotalSize)/5000, name.AverageSize())
+
+	var out []dynabuf.FieldReport
+	must.NoError(t, json.Unmarshal([]byte(stats.String()), &out))
+	must.Eq(t, reports, out)
+
+	// Items read are sampled too, one by one or as a slice.
+	stats.Reset()
+	must.SliceEmpty(t, stats.Report())
+
+	var user testpb.User
+	must.NoError(t, dynabuf.Unmarshal(items[0], &user, dynabuf.WithFieldStats(stats)))
+	var users []*testpb.User
+	must.NoError(t, dynabuf.Unmarshal(items[1:10], &users, dynabuf.WithFieldStats(stats)))
+
+	reports = stats.Report()
+	must.SliceLen(t, 3, reports)
+	must.Eq(t, 10, reports[1].Items)
+	must.Eq(t, 10, reports[1].Cardinality)
+	must.Eq(t, 5, reports[0].Count)
+}
+
+func TestFieldStatsSampleRate(t *testing.T) {
+	stats := &dynabuf.FieldStats{SampleRate: 0.1}
+
+	for i := range 1000 {
+		_, err := dynabuf.Marshal(&testpb.User{Id: fmt.Sprint(i)}, dynabuf.WithFieldStats(stats))
+		must.NoError(t, err)
+	}
+
+	reports := stats.Report()
+	must.SliceLen(t, 1, reports)
+	must.Between(t, 50, reports[0].Items, 150)
+	must.Eq(t, reports[0].Items, reports[0].Cardinality)
+}
@@ -0,0 +1,46 @@
+package dynabuf
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// MarshalMessage is like [Marshal] for a single message, returning its item
+// without a type assertion.
+//
+// # Example
+//
+//	item, err := dynabuf.MarshalMessage(&example.User{Id: "123"})
+func MarshalMessage[T proto.Message](msg T, opts ...Option) (map[string]types.AttributeValue, error) {
+	return marshalProtoMessage(msg, opts...)
+}
+
+// MarshalSlice is like [Marshal] for a slice of messages, returning their
+// items without a type assertion.
+//
+// # Example
+//
+//	items, err := dynabuf.MarshalSlice(users)
+func MarshalSlice[T proto.Message](msgs []T, opts ...Option) ([]map[string]types.AttributeValue, error) {
+	items := make([]map[string]types.AttributeValue, len(msgs))
+	for i, msg := range msgs {
+		item, err := marshalProtoMessage(msg, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: at index %d: %w", ErrFailedToMarshal, i, err)
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// UnmarshalMessage is like [Unmarshal] for a single item, decoding it into
+// a new message of type T, as [UnmarshalNew] does.
+//
+// # Example
+//
+//	user, err := dynabuf.UnmarshalMessage[*example.User](out.Item)
+func UnmarshalMessage[T proto.Message](av map[string]types.AttributeValue, opts ...Option) (T, error) {
+	return UnmarshalNew[T](av, opts...)
+}
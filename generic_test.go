@@ -0,0 +1,37 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestGenericMarshal(t *testing.T) {
+	users := []*testpb.User{
+		{Id: "1", Name: "Alice"},
+		{Id: "2", Name: "Bob"},
+	}
+
+	item, err := dynabuf.MarshalMessage(users[0])
+	must.NoError(t, err)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "Alice"}, item["name"])
+
+	items, err := dynabuf.MarshalSlice(users)
+	must.NoError(t, err)
+	must.SliceLen(t, 2, items)
+
+	for i, item := range items {
+		user, err := dynabuf.UnmarshalMessage[*testpb.User](item)
+		must.NoError(t, err)
+		must.Eq(t, users[i], user, must.Cmp(protocmp.Transform()))
+	}
+
+	_, err = dynabuf.UnmarshalMessage[*testpb.User](map[string]types.AttributeValue{
+		"age": &types.AttributeValueMemberS{Value: "old"},
+	})
+	must.Error(t, err)
+}
@@ -0,0 +1,206 @@
+package dynabuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	// datePattern matches google.type.Date values stored as strings.
+	datePattern = regexp.MustCompile(`^([0-9]{4})-([0-9]{2})-([0-9]{2})$`)
+
+	// timeOfDayPattern matches google.type.TimeOfDay values stored as
+	// strings.
+	timeOfDayPattern = regexp.MustCompile(`^([0-9]{2}):([0-9]{2}):([0-9]{2})(?:\.([0-9]{1,9}))?$`)
+)
+
+// googleTypeEncoding returns the encoding of the values of fd if it is a
+// google.type message stored in a form other than its JSON form, or
+// ENCODING_UNSPECIFIED otherwise.
+//
+// Dates and times of day are stored as strings sorting chronologically, so
+// they can be compared and used as sort keys, and amounts of money as
+// numbers if the field is annotated as decimal.
+func googleTypeEncoding(fd protoreflect.FieldDescriptor) dynabufpb.Encoding {
+	md := fd.Message()
+	if md == nil {
+		return dynabufpb.Encoding_ENCODING_UNSPECIFIED
+	}
+
+	switch md.FullName() {
+	case "google.type.Date":
+		return dynabufpb.Encoding_ENCODING_DATE
+	case "google.type.TimeOfDay":
+		return dynabufpb.Encoding_ENCODING_TIME_OF_DAY
+	case "google.type.Money":
+		opts, ok := proto.GetExtension(fd.Options(), dynabufpb.E_Field).(*dynabufpb.FieldOptions)
+		if ok && opts.GetDecimal() {
+			return dynabufpb.Encoding_ENCODING_DECIMAL_MONEY
+		}
+	}
+	return dynabufpb.Encoding_ENCODING_UNSPECIFIED
+}
+
+// encodeGoogleType returns the stored form of the JSON form of a
+// google.type message.
+func encodeGoogleType(fd protoreflect.FieldDescriptor, v any) (any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v, nil
+	}
+
+	switch googleTypeEncoding(fd) {
+	case dynabufpb.Encoding_ENCODING_DATE:
+		year, month, day := intValue(m["year"]), intValue(m["month"]), intValue(m["day"])
+		if year < 0 || year > 9999 || month < 0 || month > 12 || day < 0 || day > 31 {
+			return nil, fmt.Errorf("invalid date of field %s", fd.FullName())
+		}
+		return fmt.Sprintf("%04d-%02d-%02d", year, month, day), nil
+	case dynabufpb.Encoding_ENCODING_TIME_OF_DAY:
+		hours, minutes, seconds, nanos := intValue(m["hours"]), intValue(m["minutes"]), intValue(m["seconds"]), intValue(m["nanos"])
+		if hours < 0 || hours > 24 || minutes < 0 || minutes > 59 || seconds < 0 || seconds > 60 || nanos < 0 || nanos > 999_999_999 {
+			return nil, fmt.Errorf("invalid time of day of field %s", fd.FullName())
+		}
+		s := fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+		if nanos != 0 {
+			s += "." + strings.TrimRight(fmt.Sprintf("%09d", nanos), "0")
+		}
+		return s, nil
+	case dynabufpb.Encoding_ENCODING_DECIMAL_MONEY:
+		units, err := strconv.ParseInt(fmt.Sprint(m["units"]), 10, 64)
+		if m["units"] == nil {
+			units, err = 0, nil
+		}
+		nanos := intValue(m["nanos"])
+		if err != nil || units > 0 && nanos < 0 || units < 0 && nanos > 0 || nanos <= -1e9 || nanos >= 1e9 {
+			return nil, fmt.Errorf("invalid money of field %s", fd.FullName())
+		}
+
+		money := map[string]any{"amount": json.Number(formatAmount(units, nanos))}
+		if code, ok := m["currencyCode"]; ok {
+			money["currencyCode"] = code
+		}
+		return money, nil
+	}
+	return v, nil
+}
+
+// decodeGoogleType returns the JSON form of a stored google.type message.
+// Values stored in their JSON form, before the field was annotated, are
+// returned as they are.
+func decodeGoogleType(fd protoreflect.FieldDescriptor, v any) (any, error) {
+	switch googleTypeEncoding(fd) {
+	case dynabufpb.Encoding_ENCODING_DATE:
+		s, ok := v.(string)
+		if !ok {
+			return v, nil
+		}
+		parts := datePattern.FindStringSubmatch(s)
+		if parts == nil {
+			return nil, fmt.Errorf("invalid date %q of field %s", s, fd.FullName())
+		}
+		return map[string]any{
+			"year":  atoi(parts[1]),
+			"month": atoi(parts[2]),
+			"day":   atoi(parts[3]),
+		}, nil
+	case dynabufpb.Encoding_ENCODING_TIME_OF_DAY:
+		s, ok := v.(string)
+		if !ok {
+			return v, nil
+		}
+		parts := timeOfDayPattern.FindStringSubmatch(s)
+		if parts == nil {
+			return nil, fmt.Errorf("invalid time of day %q of field %s", s, fd.FullName())
+		}
+		return map[string]any{
+			"hours":   atoi(parts[1]),
+			"minutes": atoi(parts[2]),
+			"seconds": atoi(parts[3]),
+			"nanos":   atoi((parts[4] + "000000000")[:9]),
+		}, nil
+	case dynabufpb.Encoding_ENCODING_DECIMAL_MONEY:
+		m, ok := v.(map[string]any)
+		if !ok || m["amount"] == nil {
+			return v, nil
+		}
+		units, nanos, ok := parseAmount(fmt.Sprint(m["amount"]))
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %v of field %s", m["amount"], fd.FullName())
+		}
+
+		money := map[string]any{"units": strconv.FormatInt(units, 10), "nanos": nanos}
+		if code, ok := m["currencyCode"]; ok {
+			money["currencyCode"] = code
+		}
+		return money, nil
+	}
+	return v, nil
+}
+
+// formatAmount returns the decimal amount of money of units and nanos,
+// which have the same sign.
+func formatAmount(units, nanos int64) string {
+	u, n := uint64(units), nanos
+	if units < 0 {
+		u = -u
+	}
+	if n < 0 {
+		n = -n
+	}
+
+	s := strconv.FormatUint(u, 10)
+	if n != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%09d", n), "0")
+	}
+	if units < 0 || nanos < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// parseAmount returns the units and nanos of a decimal amount of money,
+// reporting whether it is representable.
+func parseAmount(s string) (units, nanos int64, ok bool) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, 0, false
+	}
+
+	n := new(big.Rat).Mul(r, big.NewRat(1e9, 1))
+	if !n.IsInt() {
+		return 0, 0, false
+	}
+	u, rem := new(big.Int).QuoRem(n.Num(), big.NewInt(1e9), new(big.Int))
+	if !u.IsInt64() {
+		return 0, 0, false
+	}
+	return u.Int64(), rem.Int64(), true
+}
+
+// intValue returns the value of a 32-bit integer of the JSON form of a
+// message, or zero if it is not set.
+func intValue(v any) int64 {
+	switch v := v.(type) {
+	case float64:
+		return int64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	}
+	return 0
+}
+
+// atoi returns the value of a string of digits matched by a pattern.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,127 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/genproto/googleapis/type/date"
+	"google.golang.org/genproto/googleapis/type/money"
+	"google.golang.org/genproto/googleapis/type/timeofday"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMarshalGoogleTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		invoice *testpb.Invoice
+		want    map[string]types.AttributeValue
+	}{
+		{
+			name: "dates and times",
+			invoice: &testpb.Invoice{
+				DueDate:   &date.Date{Year: 2024, Month: 3, Day: 1},
+				DueTime:   &timeofday.TimeOfDay{Hours: 9, Minutes: 5, Seconds: 30, Nanos: 250_000_000},
+				Reminders: []*date.Date{{Month: 2, Day: 29}, {Year: 2024, Month: 12}},
+			},
+			want: map[string]types.AttributeValue{
+				"dueDate": &types.AttributeValueMemberS{Value: "2024-03-01"},
+				"dueTime": &types.AttributeValueMemberS{Value: "09:05:30.25"},
+				"reminders": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+					&types.AttributeValueMemberS{Value: "0000-02-29"},
+					&types.AttributeValueMemberS{Value: "2024-12-00"},
+				}},
+			},
+		},
+		{
+			name: "money",
+			invoice: &testpb.Invoice{
+				Total: &money.Money{CurrencyCode: "USD", Units: 12, Nanos: 500_000_000},
+				Tax:   &money.Money{CurrencyCode: "USD", Units: -1, Nanos: -50_000_000},
+			},
+			want: map[string]types.AttributeValue{
+				"total": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"currencyCode": &types.AttributeValueMemberS{Value: "USD"},
+					"units":        &types.AttributeValueMemberS{Value: "12"},
+					"nanos":        &types.AttributeValueMemberN{Value: "500000000"},
+				}},
+				"tax": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"currencyCode": &types.AttributeValueMemberS{Value: "USD"},
+					"amount":       &types.AttributeValueMemberN{Value: "-1.05"},
+				}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item := dynabuf.MustMarshalItem(test.invoice)
+			must.Eq(t, test.want, item)
+
+			var got testpb.Invoice
+			must.NoError(t, dynabuf.Unmarshal(item, &got))
+			must.True(t, proto.Equal(test.invoice, &got))
+		})
+	}
+}
+
+func TestGoogleTypesSortKeys(t *testing.T) {
+	dates := []*date.Date{
+		{Year: 999, Month: 12, Day: 31},
+		{Year: 2023, Month: 11, Day: 30},
+		{Year: 2024, Month: 2, Day: 1},
+		{Year: 2024, Month: 10, Day: 1},
+	}
+	times := []*timeofday.TimeOfDay{
+		{Hours: 9, Minutes: 59},
+		{Hours: 10},
+		{Hours: 10, Nanos: 50_000_000},
+		{Hours: 10, Nanos: 500_000_000},
+	}
+
+	var prev string
+	for _, d := range dates {
+		key := dynabuf.MustKey(&testpb.Invoice{Id: "1", DueDate: d}, []string{"id", "dueDate"})
+		sk := key["dueDate"].(*types.AttributeValueMemberS).Value
+		must.Greater(t, prev, sk)
+		prev = sk
+	}
+
+	prev = ""
+	for _, tod := range times {
+		item := dynabuf.MustMarshalItem(&testpb.Invoice{DueTime: tod})
+		s := item["dueTime"].(*types.AttributeValueMemberS).Value
+		must.Greater(t, prev, s)
+		prev = s
+	}
+}
+
+func TestUnmarshalGoogleTypes(t *testing.T) {
+	// Money stored as units and nanos, before its field was annotated as
+	// decimal, still decodes.
+	item := map[string]types.AttributeValue{
+		"tax": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"currencyCode": &types.AttributeValueMemberS{Value: "EUR"},
+			"units":        &types.AttributeValueMemberS{Value: "3"},
+		}},
+	}
+	var got testpb.Invoice
+	must.NoError(t, dynabuf.Unmarshal(item, &got))
+	must.True(t, proto.Equal(&testpb.Invoice{Tax: &money.Money{CurrencyCode: "EUR", Units: 3}}, &got))
+
+	for name, av := range map[string]types.AttributeValue{
+		"dueDate": &types.AttributeValueMemberS{Value: "2024-3-1"},
+		"dueTime": &types.AttributeValueMemberS{Value: "9:00"},
+		"tax": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"amount": &types.AttributeValueMemberN{Value: "0.0000000001"},
+		}},
+	} {
+		err := dynabuf.Unmarshal(map[string]types.AttributeValue{name: av}, &testpb.Invoice{})
+		must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+	}
+
+	_, err := dynabuf.Marshal(&testpb.Invoice{DueDate: &date.Date{Year: 10000}})
+	must.ErrorContains(t, err, "invalid date")
+}
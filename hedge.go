@@ -0,0 +1,220 @@
+package dynabuf
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// HedgedRead describes a read sent by a client using [WithHedgedReads], as
+// reported to the hook given to it.
+type HedgedRead struct {
+	// MessageType is the full name of the protobuf message type noted in
+	// the request's context by [ContextWithMessageType], or empty if none
+	// was noted.
+	MessageType protoreflect.FullName
+
+	// Operation is the name of the DynamoDB operation, such as "GetItem".
+	Operation string
+
+	// Hedged reports whether a second request was sent, because the first
+	// had not been answered within the delay.
+	Hedged bool
+
+	// HedgeWon reports whether the second request was answered first, and
+	// its response returned.
+	HedgeWon bool
+
+	// Latency is the time from sending the first request to returning a
+	// response.
+	Latency time.Duration
+
+	// Err is the error returned, if both requests failed.
+	Err error
+}
+
+// WithHedgedReads returns a DynamoDB client option that sends a second
+// GetItem request when the first has not been answered within delay, and
+// returns the response answered first, canceling the other request. It
+// trades some extra read capacity for lower tail latency on latency
+// sensitive read paths, where a few slow requests would otherwise dominate
+// the P99. A delay around the usual P95 latency of the reads hedges about
+// one read in twenty.
+//
+// If one of the requests fails while the other is still pending, the other
+// one's response is returned, so the error is only returned if both fail.
+// A first request failing before the delay is returned as is, leaving
+// retries to the client's retryer.
+//
+// The record hook, which may be nil, is called after every read with how
+// it was answered, such as by [HedgeMetrics.Record].
+//
+// # Example
+//
+//	var hedges dynabuf.HedgeMetrics
+//
+//	expvar.Publish("dynabuf_hedged_reads", &hedges)
+//
+//	client := dynamodb.NewFromConfig(cfg, dynabuf.WithHedgedReads(20*time.Millisecond, hedges.Record))
+//
+//	store := history.New[*example.User](client, "users")
+func WithHedgedReads(delay time.Duration, record func(ctx context.Context, read HedgedRead)) func(*dynamodb.Options) {
+	return func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("DynabufHedgedReads", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				if _, ok := in.Parameters.(*dynamodb.GetItemInput); !ok {
+					return next.HandleInitialize(ctx, in)
+				}
+
+				read := HedgedRead{Operation: "GetItem"}
+				read.MessageType, _ = MessageTypeFromContext(ctx)
+
+				out, metadata, err := hedge(ctx, delay, &read, func(ctx context.Context) (middleware.InitializeOutput, middleware.Metadata, error) {
+					return next.HandleInitialize(ctx, in)
+				})
+
+				if record != nil {
+					read.Err = err
+					record(ctx, read)
+				}
+				return out, metadata, err
+			}), middleware.After)
+		})
+	}
+}
+
+// hedgeResult is the response to one of the requests of a hedged read.
+type hedgeResult struct {
+	out      middleware.InitializeOutput
+	metadata middleware.Metadata
+	err      error
+	hedge    bool
+}
+
+// hedge sends a request, and a second one if the first is not answered
+// within delay, returning the first successful response, and noting how the
+// read was answered in read.
+func hedge(ctx context.Context, delay time.Duration, read *HedgedRead, send func(context.Context) (middleware.InitializeOutput, middleware.Metadata, error)) (middleware.InitializeOutput, middleware.Metadata, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	start := time.Now()
+
+	// The channel holds both responses, so the request losing the race
+	// does not block once the read returned.
+	results := make(chan hedgeResult, 2)
+	request := func(hedge bool) {
+		go func() {
+			out, metadata, err := send(ctx)
+			results <- hedgeResult{out: out, metadata: metadata, err: err, hedge: hedge}
+		}()
+	}
+	request(false)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var (
+		pending = 1
+		failed  *hedgeResult
+	)
+	for {
+		var r hedgeResult
+		select {
+		case <-timer.C:
+			// The first request may have been answered as the delay
+			// passed, which needs no hedge.
+			select {
+			case r = <-results:
+			default:
+				read.Hedged = true
+				pending++
+				request(true)
+				continue
+			}
+		case r = <-results:
+		}
+
+		pending--
+		if r.err != nil {
+			if failed == nil {
+				failed = &r
+			}
+			// Wait for the other request, if sent. A first request
+			// failing before the delay is returned as is.
+			if pending > 0 {
+				continue
+			}
+			r = *failed
+		}
+		read.HedgeWon = r.err == nil && r.hedge
+		read.Latency = time.Since(start)
+		return r.out, r.metadata, r.err
+	}
+}
+
+// HedgeMetrics counts the reads reported by [WithHedgedReads], to measure
+// how often reads are hedged and how often hedging pays off, by passing its
+// [HedgeMetrics.Record] method to it.
+//
+// HedgeMetrics implements [expvar.Var], so it can be published as is.
+//
+// The zero value is ready to use, and a HedgeMetrics is safe for concurrent
+// use.
+type HedgeMetrics struct {
+	mu    sync.Mutex
+	stats HedgeStats
+}
+
+// HedgeStats are the counts of a [HedgeMetrics].
+type HedgeStats struct {
+	// Reads is the number of reads.
+	Reads int `json:"reads"`
+
+	// Hedged is the number of reads a second request was sent for.
+	Hedged int `json:"hedged"`
+
+	// HedgeWins is the number of hedged reads answered by the second
+	// request first. Hedged reads won by the first request only cost
+	// capacity; a low ratio of wins suggests a longer delay.
+	HedgeWins int `json:"hedge_wins"`
+
+	// Errors is the number of reads failing.
+	Errors int `json:"errors"`
+}
+
+// Record counts a read.
+func (m *HedgeMetrics) Record(_ context.Context, read HedgedRead) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stats.Reads++
+	if read.Hedged {
+		m.stats.Hedged++
+	}
+	if read.HedgeWon {
+		m.stats.HedgeWins++
+	}
+	if read.Err != nil {
+		m.stats.Errors++
+	}
+}
+
+// Stats returns the counts so far.
+func (m *HedgeMetrics) Stats() HedgeStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stats
+}
+
+// String returns the counts as a JSON object, implementing [expvar.Var].
+func (m *HedgeMetrics) String() string {
+	b, _ := json.Marshal(m.Stats())
+	return string(b)
+}
@@ -0,0 +1,225 @@
+package dynabuf_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestWithHedgedReads(t *testing.T) {
+	ctx := dynabuf.ContextWithMessageType(context.Background(), &testpb.User{})
+
+	// A response answers the request of the same index after its delay.
+	type response struct {
+		delay  time.Duration
+		status int
+		body   string
+	}
+
+	const (
+		slow = time.Second
+		fast = time.Duration(0)
+		ok   = http.StatusOK
+		bad  = http.StatusBadRequest
+
+		invalid = `{"__type":"com.amazonaws.dynamodb.v20120810#ValidationException","message":"invalid"}`
+	)
+
+	tests := []struct {
+		name      string
+		responses []response
+		requests  int32
+		want      string
+		wantErr   bool
+		read      dynabuf.HedgedRead
+	}{
+		{
+			name:      "answered before the delay",
+			responses: []response{{fast, ok, `{"Item":{"id":{"S":"first"}}}`}},
+			requests:  1,
+			want:      "first",
+		},
+		{
+			name: "hedge wins",
+			responses: []response{
+				{slow, ok, `{"Item":{"id":{"S":"first"}}}`},
+				{fast, ok, `{"Item":{"id":{"S":"second"}}}`},
+			},
+			requests: 2,
+			want:     "second",
+			read:     dynabuf.HedgedRead{Hedged: true, HedgeWon: true},
+		},
+		{
+			name: "first wins",
+			responses: []response{
+				{100 * time.Millisecond, ok, `{"Item":{"id":{"S":"first"}}}`},
+				{slow, ok, `{"Item":{"id":{"S":"second"}}}`},
+			},
+			requests: 2,
+			want:     "first",
+			read:     dynabuf.HedgedRead{Hedged: true},
+		},
+		{
+			name: "hedge answers a failed request",
+			responses: []response{
+				{100 * time.Millisecond, bad, invalid},
+				{200 * time.Millisecond, ok, `{"Item":{"id":{"S":"second"}}}`},
+			},
+			requests: 2,
+			want:     "second",
+			read:     dynabuf.HedgedRead{Hedged: true, HedgeWon: true},
+		},
+		{
+			name:      "failed before the delay",
+			responses: []response{{fast, bad, invalid}},
+			requests:  1,
+			wantErr:   true,
+		},
+		{
+			name: "both failed",
+			responses: []response{
+				{100 * time.Millisecond, bad, invalid},
+				{fast, bad, invalid},
+			},
+			requests: 2,
+			wantErr:  true,
+			read:     dynabuf.HedgedRead{Hedged: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				requests atomic.Int32
+				metrics  dynabuf.HedgeMetrics
+				reads    []dynabuf.HedgedRead
+			)
+
+			client := dynamodb.New(dynamodb.Options{
+				Region: "us-east-1",
+				Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+					return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+				}),
+				RetryMaxAttempts: 1,
+				HTTPClient: httpClientFunc(func(r *http.Request) (*http.Response, error) {
+					resp := test.responses[requests.Add(1)-1]
+					select {
+					case <-time.After(resp.delay):
+					case <-r.Context().Done():
+						return nil, r.Context().Err()
+					}
+					return &http.Response{
+						StatusCode: resp.status,
+						Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+						Body:       io.NopCloser(strings.NewReader(resp.body)),
+					}, nil
+				}),
+			}, dynabuf.WithHedgedReads(50*time.Millisecond, func(ctx context.Context, read dynabuf.HedgedRead) {
+				metrics.Record(ctx, read)
+				reads = append(reads, read)
+			}))
+
+			out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+				TableName: aws.String("users"),
+				Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}},
+			})
+			must.Eq(t, test.requests, requests.Load())
+			must.SliceLen(t, 1, reads)
+			must.Eq(t, "dynabuf.test.v1.User", reads[0].MessageType)
+			must.Eq(t, "GetItem", reads[0].Operation)
+			must.Eq(t, test.read.Hedged, reads[0].Hedged)
+			must.Eq(t, test.read.HedgeWon, reads[0].HedgeWon)
+			must.Less(t, slow, reads[0].Latency)
+
+			stats := metrics.Stats()
+			must.Eq(t, 1, stats.Reads)
+			if test.wantErr {
+				must.Error(t, err)
+				must.ErrorIs(t, err, reads[0].Err)
+				must.Eq(t, 1, stats.Errors)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, test.want, out.Item["id"].(*types.AttributeValueMemberS).Value)
+		})
+	}
+}
+
+func TestHedgeFailures(t *testing.T) {
+	var (
+		errFirst  = errors.New("first failed")
+		errSecond = errors.New("second failed")
+	)
+
+	t.Run("first fails before the delay", func(t *testing.T) {
+		var (
+			read  dynabuf.HedgedRead
+			calls atomic.Int32
+		)
+		_, _, err := dynabuf.Hedge(context.Background(), time.Hour, &read, func(context.Context) (middleware.InitializeOutput, middleware.Metadata, error) {
+			calls.Add(1)
+			return middleware.InitializeOutput{}, middleware.Metadata{}, errFirst
+		})
+		must.ErrorIs(t, err, errFirst)
+		must.Eq(t, 1, calls.Load())
+		must.False(t, read.Hedged)
+		must.False(t, read.HedgeWon)
+	})
+
+	t.Run("both fail", func(t *testing.T) {
+		var (
+			read   dynabuf.HedgedRead
+			calls  atomic.Int32
+			hedged = make(chan struct{})
+		)
+		_, _, err := dynabuf.Hedge(context.Background(), time.Millisecond, &read, func(context.Context) (middleware.InitializeOutput, middleware.Metadata, error) {
+			if calls.Add(1) == 1 {
+				// The first request fails once the hedge is sent.
+				<-hedged
+				return middleware.InitializeOutput{}, middleware.Metadata{}, errFirst
+			}
+			close(hedged)
+			return middleware.InitializeOutput{}, middleware.Metadata{}, errSecond
+		})
+		must.Error(t, err)
+		must.True(t, errors.Is(err, errFirst) || errors.Is(err, errSecond))
+		must.Eq(t, 2, calls.Load())
+		must.True(t, read.Hedged)
+		must.False(t, read.HedgeWon)
+	})
+}
+
+func TestHedgeMetrics(t *testing.T) {
+	var (
+		metrics  dynabuf.HedgeMetrics
+		requests int
+	)
+
+	client := newTestClient(`{}`, &requests, dynabuf.WithHedgedReads(time.Minute, metrics.Record))
+
+	// Only reads are hedged.
+	_, err := client.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String("users"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}},
+	})
+	must.NoError(t, err)
+	must.Eq(t, dynabuf.HedgeStats{}, metrics.Stats())
+
+	metrics.Record(context.Background(), dynabuf.HedgedRead{})
+	metrics.Record(context.Background(), dynabuf.HedgedRead{Hedged: true})
+	metrics.Record(context.Background(), dynabuf.HedgedRead{Hedged: true, HedgeWon: true})
+	must.Eq(t, `{"reads":3,"hedged":2,"hedge_wins":1,"errors":0}`, metrics.String())
+}
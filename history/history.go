@@ -0,0 +1,337 @@
+// Package history stores messages in DynamoDB along with every version
+// they ever had, for reading them as they were at any point in time.
+//
+// Each message is stored as its latest item and, in the same transaction,
+// as a history item keyed by the time it was written. Deletes remove the
+// latest item and record a history item marking the deletion, so reads at
+// a later time find nothing while earlier versions remain.
+//
+// # Table Schema
+//
+// The table must use a string partition key named "id" and a string sort
+// key named "version":
+//
+//	KeySchema: []types.KeySchemaElement{
+//	  {
+//	    AttributeName: aws.String("id"),
+//	    KeyType:       types.KeyTypeHash,
+//	  },
+//	  {
+//	    AttributeName: aws.String("version"),
+//	    KeyType:       types.KeyTypeRange,
+//	  },
+//	},
+//
+// The latest item of a message has the version "latest", and its history
+// items have versions starting with "v#" followed by the time they were
+// written, so they sort in order. The message is stored in the "data" map
+// attribute as encoded by [dynabuf.Marshal].
+//
+// # Retention
+//
+// Version history grows with every write. Messages annotated with a
+// retention policy have their old versions removed by [Store.Prune] and
+// [Store.Collect].
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// latestVersion is the version of the latest item of a message.
+	latestVersion = "latest"
+
+	// historyPrefix prefixes the versions of history items.
+	historyPrefix = "v#"
+
+	// timeLayout is the fixed width layout of the time of history items,
+	// which sorts as strings in chronological order.
+	timeLayout = "2006-01-02T15:04:05.000000000Z"
+)
+
+// ErrVersionExists is returned when a version was already written at the
+// same time, usually by a concurrent writer.
+var ErrVersionExists = errors.New("history: a version was already written at the same time")
+
+// Client is the subset of the DynamoDB API used by a [Store].
+type Client interface {
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+// Version is a version of a message of type T read from its history.
+type Version[T proto.Message] struct {
+	// ID is the ID of the message.
+	ID string
+
+	// Time is when the version was written.
+	Time time.Time
+
+	// Deleted reports whether the message was deleted at this time, in
+	// which case Data is the zero value.
+	Deleted bool
+
+	// Data is the decoded message.
+	Data T
+}
+
+// Store reads and writes messages of type T and their history in a
+// DynamoDB table.
+//
+// A Store is safe for concurrent use.
+type Store[T proto.Message] struct {
+	client Client
+	table  string
+	cfg    *dynabuf.Config
+}
+
+// New returns a [Store] storing messages in the given table, sending its
+// requests with the configuration of opts.
+func New[T proto.Message](client Client, table string, opts ...dynabuf.ConfigOption) *Store[T] {
+	return &Store[T]{
+		client: client,
+		table:  table,
+		cfg:    dynabuf.NewConfig(opts...),
+	}
+}
+
+// Put writes msg as the latest version of the message with the given ID,
+// and records it in the message's history.
+//
+// # Example
+//
+//	store := history.New[*example.User](client, "users")
+//
+//	_ = store.Put(ctx, "123", &example.User{Name: "Alice"})
+//
+//	user, _ := store.GetAsOf(ctx, "123", time.Now().Add(-24*time.Hour))
+func (s *Store[T]) Put(ctx context.Context, id string, msg T) error {
+	av, err := dynabuf.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("history: failed to encode %q: %w", id, err)
+	}
+	data := av.(map[string]types.AttributeValue)
+
+	now := s.cfg.Now().UTC()
+
+	latest := item(id, latestVersion, now)
+	latest["data"] = &types.AttributeValueMemberM{Value: data}
+
+	version := item(id, historyPrefix+now.Format(timeLayout), now)
+	version["data"] = &types.AttributeValueMemberM{Value: data}
+
+	return s.write(dynabuf.ContextWithMessage(ctx, msg), id, version, types.TransactWriteItem{
+		Put: &types.Put{
+			TableName: aws.String(s.table),
+			Item:      latest,
+		},
+	})
+}
+
+// Delete deletes the message with the given ID, recording the deletion in
+// its history.
+func (s *Store[T]) Delete(ctx context.Context, id string) error {
+	now := s.cfg.Now().UTC()
+
+	version := item(id, historyPrefix+now.Format(timeLayout), now)
+	version["deleted"] = &types.AttributeValueMemberBOOL{Value: true}
+
+	return s.write(ctx, id, version, types.TransactWriteItem{
+		Delete: &types.Delete{
+			TableName: aws.String(s.table),
+			Key:       key(id, latestVersion),
+		},
+	})
+}
+
+// write writes a history item together with a change of the latest item.
+func (s *Store[T]) write(ctx context.Context, id string, version map[string]types.AttributeValue, latest types.TransactWriteItem) error {
+	var zero T
+	ctx = dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	_, err := dynabuf.Do(ctx, s.cfg, s.client.TransactWriteItems, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			latest,
+			{
+				Put: &types.Put{
+					TableName:                aws.String(s.table),
+					Item:                     version,
+					ConditionExpression:      aws.String("attribute_not_exists(#version)"),
+					ExpressionAttributeNames: map[string]string{"#version": "version"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) {
+			return fmt.Errorf("%w: %q", ErrVersionExists, id)
+		}
+		return fmt.Errorf("history: failed to write %q: %w", id, err)
+	}
+
+	return nil
+}
+
+// Get returns the latest version of the message with the given ID, or the
+// zero value if it does not exist.
+func (s *Store[T]) Get(ctx context.Context, id string) (T, error) {
+	var zero T
+	ctx = dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	out, err := dynabuf.Do(ctx, s.cfg, s.client.GetItem, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.table),
+		Key:            key(id, latestVersion),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return zero, fmt.Errorf("history: failed to get %q: %w", id, err)
+	}
+
+	if len(out.Item) == 0 {
+		return zero, nil
+	}
+
+	v, err := decodeVersion[T](id, out.Item)
+	return v.Data, err
+}
+
+// GetAsOf returns the version of the message with the given ID in effect
+// at time t, or the zero value if it did not exist or was deleted then.
+func (s *Store[T]) GetAsOf(ctx context.Context, id string, t time.Time) (T, error) {
+	var zero T
+	ctx = dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	out, err := dynabuf.Do(ctx, s.cfg, s.client.Query, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("#id = :id AND #version BETWEEN :first AND :last"),
+		ExpressionAttributeNames: map[string]string{
+			"#id":      "id",
+			"#version": "version",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id":    &types.AttributeValueMemberS{Value: id},
+			":first": &types.AttributeValueMemberS{Value: historyPrefix},
+			":last":  &types.AttributeValueMemberS{Value: historyPrefix + t.UTC().Format(timeLayout)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(1),
+		ConsistentRead:   aws.Bool(true),
+	})
+	if err != nil {
+		return zero, fmt.Errorf("history: failed to get %q as of %s: %w", id, t, err)
+	}
+
+	if len(out.Items) == 0 {
+		return zero, nil
+	}
+
+	v, err := decodeVersion[T](id, out.Items[0])
+	return v.Data, err
+}
+
+// Versions returns every version of the message with the given ID, oldest
+// first, reading them page by page as the sequence is iterated.
+func (s *Store[T]) Versions(ctx context.Context, id string) iter.Seq2[Version[T], error] {
+	return func(yield func(Version[T], error) bool) {
+		var zero T
+		ctx := dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(s.table),
+			KeyConditionExpression: aws.String("#id = :id AND begins_with(#version, :prefix)"),
+			ExpressionAttributeNames: map[string]string{
+				"#id":      "id",
+				"#version": "version",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":id":     &types.AttributeValueMemberS{Value: id},
+				":prefix": &types.AttributeValueMemberS{Value: historyPrefix},
+			},
+			ConsistentRead: aws.Bool(true),
+		}
+
+		for {
+			out, err := dynabuf.Do(ctx, s.cfg, s.client.Query, input)
+			if err != nil {
+				yield(Version[T]{}, fmt.Errorf("history: failed to read versions of %q: %w", id, err))
+				return
+			}
+
+			for _, item := range out.Items {
+				v, err := decodeVersion[T](id, item)
+				if err != nil {
+					yield(Version[T]{}, err)
+					return
+				}
+				if !yield(v, nil) {
+					return
+				}
+			}
+
+			if len(out.LastEvaluatedKey) == 0 {
+				return
+			}
+			input.ExclusiveStartKey = out.LastEvaluatedKey
+		}
+	}
+}
+
+// decodeVersion decodes a latest or history item.
+func decodeVersion[T proto.Message](id string, item map[string]types.AttributeValue) (Version[T], error) {
+	v := Version[T]{ID: id}
+
+	if ut, ok := item["updateTime"].(*types.AttributeValueMemberS); ok {
+		t, err := time.Parse(time.RFC3339Nano, ut.Value)
+		if err != nil {
+			return v, fmt.Errorf("history: invalid update time of %q: %w", id, err)
+		}
+		v.Time = t
+	}
+
+	if deleted, ok := item["deleted"].(*types.AttributeValueMemberBOOL); ok && deleted.Value {
+		v.Deleted = true
+		return v, nil
+	}
+
+	data, ok := item["data"].(*types.AttributeValueMemberM)
+	if !ok {
+		return v, fmt.Errorf("history: version of %q at %s has no data", id, v.Time)
+	}
+
+	var zero T
+	v.Data = zero.ProtoReflect().New().Interface().(T)
+	if err := dynabuf.Unmarshal(data.Value, v.Data); err != nil {
+		return v, fmt.Errorf("history: failed to decode %q: %w", id, err)
+	}
+
+	return v, nil
+}
+
+// item returns the key and update time attributes of an item.
+func item(id, version string, t time.Time) map[string]types.AttributeValue {
+	item := key(id, version)
+	item["updateTime"] = &types.AttributeValueMemberS{Value: t.Format(time.RFC3339Nano)}
+	return item
+}
+
+func key(id, version string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"id":      &types.AttributeValueMemberS{Value: id},
+		"version": &types.AttributeValueMemberS{Value: version},
+	}
+}
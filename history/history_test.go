@@ -0,0 +1,97 @@
+package history_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/history"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func newStore(t *testing.T) *history.Store[*testpb.User] {
+	t.Helper()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("users"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("version"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	return history.New[*testpb.User](client, "users")
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	// now returns the current time, making sure it differs from the time
+	// of any write before or after it.
+	now := func() time.Time {
+		time.Sleep(time.Millisecond)
+		defer time.Sleep(time.Millisecond)
+		return time.Now()
+	}
+
+	before := now()
+	must.NoError(t, store.Put(ctx, "1", &testpb.User{Name: "Alice"}))
+	first := now()
+	must.NoError(t, store.Put(ctx, "1", &testpb.User{Name: "Alice", Age: 30}))
+	second := now()
+	must.NoError(t, store.Delete(ctx, "1"))
+	deleted := now()
+
+	must.NoError(t, store.Put(ctx, "2", &testpb.User{Name: "Bob"}))
+
+	user, err := store.Get(ctx, "1")
+	must.NoError(t, err)
+	must.Nil(t, user)
+
+	user, err = store.Get(ctx, "2")
+	must.NoError(t, err)
+	must.Eq(t, "Bob", user.GetName())
+
+	user, err = store.GetAsOf(ctx, "1", before)
+	must.NoError(t, err)
+	must.Nil(t, user)
+
+	user, err = store.GetAsOf(ctx, "1", first)
+	must.NoError(t, err)
+	must.Eq(t, "Alice", user.GetName())
+	must.Eq(t, 0, user.GetAge())
+
+	user, err = store.GetAsOf(ctx, "1", second)
+	must.NoError(t, err)
+	must.Eq(t, 30, user.GetAge())
+
+	user, err = store.GetAsOf(ctx, "1", deleted)
+	must.NoError(t, err)
+	must.Nil(t, user)
+
+	var versions []history.Version[*testpb.User]
+	for v, err := range store.Versions(ctx, "1") {
+		must.NoError(t, err)
+		versions = append(versions, v)
+	}
+	must.SliceLen(t, 3, versions)
+	must.Eq(t, "Alice", versions[0].Data.GetName())
+	must.True(t, versions[0].Time.After(before) && versions[0].Time.Before(first))
+	must.Eq(t, 30, versions[1].Data.GetAge())
+	must.True(t, versions[2].Deleted)
+	must.Nil(t, versions[2].Data)
+}
@@ -0,0 +1,187 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Prune deletes the versions of the message with the given ID that are no
+// longer kept under the retention policy annotated on T, and returns the
+// number of versions deleted:
+//
+//	message Document {
+//	  option (dynabuf.v1.message) = {
+//	    history: { retention: { seconds: 2592000 }, max_versions: 100 }
+//	  };
+//	  ...
+//	}
+//
+// A version is kept until it was replaced by a newer version longer than
+// the retention period ago, so [Store.GetAsOf] reads the same message at
+// any time within the period, and at most the annotated maximum number of
+// versions are kept. The latest version is always kept. Messages without
+// a retention policy keep their whole history.
+func (s *Store[T]) Prune(ctx context.Context, id string) (int, error) {
+	var zero T
+	policy := historyOptions(zero.ProtoReflect().Descriptor())
+	if policy.GetRetention() == nil && policy.GetMaxVersions() == 0 {
+		return 0, nil
+	}
+
+	ctx = dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	versions, err := s.versionTimes(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	remove := make([]bool, len(versions))
+	if limit := int(policy.GetMaxVersions()); limit > 0 {
+		for i := range max(len(versions)-limit, 0) {
+			remove[i] = true
+		}
+	}
+	if retention := policy.GetRetention(); retention != nil {
+		cutoff := s.cfg.Now().Add(-retention.AsDuration())
+		for i := range len(versions) - 1 {
+			if !versions[i+1].After(cutoff) {
+				remove[i] = true
+			}
+		}
+	}
+
+	var deleted int
+	for i, t := range versions {
+		if !remove[i] {
+			continue
+		}
+		_, err := dynabuf.Do(ctx, s.cfg, s.client.DeleteItem, &dynamodb.DeleteItemInput{
+			TableName: aws.String(s.table),
+			Key:       key(id, historyPrefix+t.Format(timeLayout)),
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("history: failed to delete version of %q at %s: %w", id, t, err)
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// Collect prunes the history of every message in the table, as
+// [Store.Prune] does for a single message, and returns the number of
+// versions deleted. It scans the whole table, so it is meant to run
+// periodically in the background rather than on every write.
+func (s *Store[T]) Collect(ctx context.Context) (int, error) {
+	var zero T
+	ctx = dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	var (
+		ids  []string
+		seen = map[string]bool{}
+	)
+
+	input := &dynamodb.ScanInput{
+		TableName:                aws.String(s.table),
+		ProjectionExpression:     aws.String("#id"),
+		FilterExpression:         aws.String("begins_with(#version, :prefix)"),
+		ExpressionAttributeNames: map[string]string{"#id": "id", "#version": "version"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":prefix": &types.AttributeValueMemberS{Value: historyPrefix},
+		},
+	}
+	for {
+		out, err := dynabuf.Do(ctx, s.cfg, s.client.Scan, input)
+		if err != nil {
+			return 0, fmt.Errorf("history: failed to scan %q: %w", s.table, err)
+		}
+
+		for _, item := range out.Items {
+			id, ok := item["id"].(*types.AttributeValueMemberS)
+			if ok && !seen[id.Value] {
+				seen[id.Value] = true
+				ids = append(ids, id.Value)
+			}
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+
+	var deleted int
+	for _, id := range ids {
+		n, err := s.Prune(ctx, id)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// versionTimes returns the times of the history items of the message with
+// the given ID, oldest first.
+func (s *Store[T]) versionTimes(ctx context.Context, id string) ([]time.Time, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("#id = :id AND begins_with(#version, :prefix)"),
+		ExpressionAttributeNames: map[string]string{
+			"#id":      "id",
+			"#version": "version",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id":     &types.AttributeValueMemberS{Value: id},
+			":prefix": &types.AttributeValueMemberS{Value: historyPrefix},
+		},
+		ProjectionExpression: aws.String("#version"),
+		ConsistentRead:       aws.Bool(true),
+	}
+
+	var times []time.Time
+	for {
+		out, err := dynabuf.Do(ctx, s.cfg, s.client.Query, input)
+		if err != nil {
+			return nil, fmt.Errorf("history: failed to read versions of %q: %w", id, err)
+		}
+
+		for _, item := range out.Items {
+			version, _ := item["version"].(*types.AttributeValueMemberS)
+			if version == nil {
+				continue
+			}
+			t, err := time.Parse(timeLayout, strings.TrimPrefix(version.Value, historyPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("history: invalid version %q of %q: %w", version.Value, id, err)
+			}
+			times = append(times, t)
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			return times, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// historyOptions returns the history options annotated on a message, or
+// nil if it is not annotated.
+func historyOptions(md protoreflect.MessageDescriptor) *dynabufpb.HistoryOptions {
+	opts, ok := proto.GetExtension(md.Options(), dynabufpb.E_Message).(*dynabufpb.MessageOptions)
+	if !ok {
+		return nil
+	}
+	return opts.GetHistory()
+}
@@ -0,0 +1,146 @@
+package history_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/history"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func newDocumentStore(t *testing.T) *history.Store[*testpb.Document] {
+	t.Helper()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("documents"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("version"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	return history.New[*testpb.Document](client, "documents")
+}
+
+func revisions(t *testing.T, store *history.Store[*testpb.Document], id string) []int32 {
+	t.Helper()
+
+	var revisions []int32
+	for v, err := range store.Versions(context.Background(), id) {
+		must.NoError(t, err)
+		revisions = append(revisions, v.Data.GetRevision())
+	}
+	return revisions
+}
+
+func TestStorePrune(t *testing.T) {
+	ctx := context.Background()
+
+	// Document keeps at most 3 versions, and those needed to read it as it
+	// was within the last 50ms.
+	put := func(t *testing.T, store *history.Store[*testpb.Document], id string, revision int32) {
+		t.Helper()
+		time.Sleep(time.Millisecond)
+		must.NoError(t, store.Put(ctx, id, &testpb.Document{Title: id, Revision: revision}))
+	}
+
+	tests := []struct {
+		name  string
+		check func(t *testing.T, store *history.Store[*testpb.Document])
+	}{
+		{
+			name: "keeps at most the maximum number of versions",
+			check: func(t *testing.T, store *history.Store[*testpb.Document]) {
+				for revision := range int32(5) {
+					put(t, store, "a", revision)
+				}
+
+				deleted, err := store.Prune(ctx, "a")
+				must.NoError(t, err)
+				must.Eq(t, 2, deleted)
+				must.Eq(t, []int32{2, 3, 4}, revisions(t, store, "a"))
+
+				deleted, err = store.Prune(ctx, "a")
+				must.NoError(t, err)
+				must.Eq(t, 0, deleted)
+			},
+		},
+		{
+			name: "keeps versions in effect within the retention period",
+			check: func(t *testing.T, store *history.Store[*testpb.Document]) {
+				put(t, store, "a", 1)
+				put(t, store, "a", 2)
+				time.Sleep(60 * time.Millisecond)
+
+				deleted, err := store.Prune(ctx, "a")
+				must.NoError(t, err)
+				must.Eq(t, 1, deleted)
+				must.Eq(t, []int32{2}, revisions(t, store, "a"))
+
+				cutoff := time.Now()
+				put(t, store, "a", 3)
+
+				deleted, err = store.Prune(ctx, "a")
+				must.NoError(t, err)
+				must.Eq(t, 0, deleted)
+
+				doc, err := store.GetAsOf(ctx, "a", cutoff)
+				must.NoError(t, err)
+				must.Eq(t, 2, doc.GetRevision())
+			},
+		},
+		{
+			name: "collects every message in the table",
+			check: func(t *testing.T, store *history.Store[*testpb.Document]) {
+				put(t, store, "a", 1)
+				put(t, store, "a", 2)
+				put(t, store, "b", 1)
+				time.Sleep(60 * time.Millisecond)
+				put(t, store, "b", 2)
+
+				deleted, err := store.Collect(ctx)
+				must.NoError(t, err)
+				must.Eq(t, 1, deleted)
+				must.Eq(t, []int32{2}, revisions(t, store, "a"))
+				must.Eq(t, []int32{1, 2}, revisions(t, store, "b"))
+
+				doc, err := store.Get(ctx, "b")
+				must.NoError(t, err)
+				must.Eq(t, 2, doc.GetRevision())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.check(t, newDocumentStore(t))
+		})
+	}
+}
+
+func TestStorePruneWithoutPolicy(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	must.NoError(t, store.Put(ctx, "1", &testpb.User{Name: "Alice"}))
+	time.Sleep(time.Millisecond)
+	must.NoError(t, store.Put(ctx, "1", &testpb.User{Name: "Alice", Age: 30}))
+
+	deleted, err := store.Prune(ctx, "1")
+	must.NoError(t, err)
+	must.Eq(t, 0, deleted)
+}
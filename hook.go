@@ -0,0 +1,67 @@
+package dynabuf
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FieldsHook changes the fields of an item of a message of type md, which
+// are the JSON form of the message as described by [Backend], in place.
+// Returning an error fails the call marshaling or unmarshaling the item.
+type FieldsHook func(md protoreflect.MessageDescriptor, fields map[string]any) error
+
+// WithBeforeEncode calls hook with the fields of each message marshaled,
+// after they are converted into the form they are stored in, and before
+// they are encoded as an item, so items can be restructured in ways no
+// other option covers, such as into the layout of a legacy table. Hooks
+// are called in order.
+//
+// # Example
+//
+//	// Nest the address under the legacy "details" attribute.
+//	nest := func(md protoreflect.MessageDescriptor, fields map[string]any) error {
+//	  if address, ok := fields["address"]; ok {
+//	    fields["details"] = map[string]any{"address": address}
+//	    delete(fields, "address")
+//	  }
+//	  return nil
+//	}
+//
+//	item, err := dynabuf.Marshal(user, dynabuf.WithBeforeEncode(nest))
+func WithBeforeEncode(hook FieldsHook) Option {
+	return func(o *options) {
+		o.beforeEncode = append(o.beforeEncode, hook)
+	}
+}
+
+// WithAfterDecode calls hook with the fields of each item unmarshaled,
+// after they are decoded from the item, and before they are converted back
+// and unmarshaled into a message, undoing the changes of the hooks of
+// [WithBeforeEncode]. Hooks are called in order.
+//
+// # Example
+//
+//	unnest := func(md protoreflect.MessageDescriptor, fields map[string]any) error {
+//	  if details, ok := fields["details"].(map[string]any); ok {
+//	    fields["address"] = details["address"]
+//	    delete(fields, "details")
+//	  }
+//	  return nil
+//	}
+//
+//	err := dynabuf.Unmarshal(out.Item, &user, dynabuf.WithAfterDecode(unnest))
+func WithAfterDecode(hook FieldsHook) Option {
+	return func(o *options) {
+		o.afterDecode = append(o.afterDecode, hook)
+	}
+}
+
+// runHooks calls each hook with the fields of an item of a message of type
+// md, stopping at the first error.
+func runHooks(hooks []FieldsHook, md protoreflect.MessageDescriptor, fields map[string]any) error {
+	for _, hook := range hooks {
+		if err := hook(md, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package dynabuf_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestFieldsHooks(t *testing.T) {
+	nest := func(md protoreflect.MessageDescriptor, fields map[string]any) error {
+		must.Eq(t, "dynabuf.test.v1.User", string(md.FullName()))
+		if address, ok := fields["address"]; ok {
+			fields["details"] = map[string]any{"address": address}
+			delete(fields, "address")
+		}
+		return nil
+	}
+	unnest := func(md protoreflect.MessageDescriptor, fields map[string]any) error {
+		if details, ok := fields["details"].(map[string]any); ok {
+			fields["address"] = details["address"]
+			delete(fields, "details")
+		}
+		return nil
+	}
+
+	user := &testpb.User{Id: "1", Address: &testpb.Address{Street: "1 Main St"}}
+	av, err := dynabuf.Marshal(user, dynabuf.WithBeforeEncode(nest))
+	must.NoError(t, err)
+	item := av.(map[string]types.AttributeValue)
+	must.MapNotContainsKey(t, item, "address")
+	must.MapContainsKey(t, item, "details")
+
+	var got testpb.User
+	must.NoError(t, dynabuf.Unmarshal(item, &got, dynabuf.WithAfterDecode(unnest)))
+	must.Eq(t, user, &got, must.Cmp(protocmp.Transform()))
+
+	var users []*testpb.User
+	must.NoError(t, dynabuf.Unmarshal([]map[string]types.AttributeValue{item}, &users, dynabuf.WithAfterDecode(unnest)))
+	must.Eq(t, user, users[0], must.Cmp(protocmp.Transform()))
+
+	// Hooks set in the fields of option structs are called too.
+	av, err = dynabuf.Marshal(user, dynabuf.MarshalOptions{BeforeEncode: []dynabuf.FieldsHook{nest}}.Options()...)
+	must.NoError(t, err)
+	must.MapContainsKey(t, av.(map[string]types.AttributeValue), "details")
+	got.Reset()
+	must.NoError(t, dynabuf.Unmarshal(item, &got, dynabuf.UnmarshalOptions{AfterDecode: []dynabuf.FieldsHook{unnest}}.Options()...))
+	must.Eq(t, user, &got, must.Cmp(protocmp.Transform()))
+
+	// Errors of hooks fail the call.
+	errHook := errors.New("hook failed")
+	fail := func(protoreflect.MessageDescriptor, map[string]any) error { return errHook }
+	_, err = dynabuf.Marshal(user, dynabuf.WithBeforeEncode(fail))
+	must.True(t, errors.Is(err, errHook))
+	err = dynabuf.Unmarshal(item, &got, dynabuf.WithAfterDecode(fail))
+	must.True(t, errors.Is(err, errHook))
+}
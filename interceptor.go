@@ -0,0 +1,120 @@
+package dynabuf
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Call describes a DynamoDB operation passed to an [Interceptor].
+type Call struct {
+	// Operation is the name of the DynamoDB operation, such as "PutItem".
+	Operation string
+
+	// MessageType is the full name of the protobuf message type the
+	// operation operates on, noted in its context by
+	// [ContextWithMessageType] or [ContextWithMessage], or empty.
+	MessageType protoreflect.FullName
+
+	// Message is the message the operation writes, noted in its context by
+	// [ContextWithMessage], or nil.
+	Message proto.Message
+
+	// Input is the input of the operation, such as a
+	// *dynamodb.PutItemInput, which interceptors may change or replace with
+	// another input of the same type before it is sent.
+	Input any
+}
+
+// Invoker sends a call to DynamoDB, or to the next interceptor, returning
+// the output of its operation, such as a *dynamodb.GetItemOutput.
+type Invoker func(ctx context.Context, call *Call) (any, error)
+
+// Interceptor runs around DynamoDB operations of clients using
+// [WithInterceptors], calling next to send the call, and returning its
+// output and error, which it may change. Interceptors can return without
+// calling next, such as to deny a call or answer it from a cache, in which
+// case they must return an output of the operation's type, such as a
+// *dynamodb.GetItemOutput for GetItem.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) (any, error)
+
+// messageKey is the context key for the message of a request.
+type messageKey struct{}
+
+// ContextWithMessage returns a copy of ctx noting that requests made with
+// it write msg, for use by interceptors, as well as its type, as
+// [ContextWithMessageType] does.
+func ContextWithMessage(ctx context.Context, msg proto.Message) context.Context {
+	return context.WithValue(ContextWithMessageType(ctx, msg), messageKey{}, msg)
+}
+
+// MessageFromContext returns the message noted in ctx by
+// [ContextWithMessage], if any.
+func MessageFromContext(ctx context.Context) (proto.Message, bool) {
+	msg, ok := ctx.Value(messageKey{}).(proto.Message)
+	return msg, ok
+}
+
+// WithInterceptors returns a DynamoDB client option that runs every
+// operation of the client through the interceptors, the first one
+// outermost, so concerns such as authorization, caching, metrics, and
+// auditing are attached once for every store and helper using the client,
+// rather than by wrapping each of their methods.
+//
+// The helpers and stores of this module note the message type of their
+// requests, and the message written, in their contexts, which
+// interceptors receive in the [Call].
+//
+// # Example
+//
+//	audit := func(ctx context.Context, call *dynabuf.Call, next dynabuf.Invoker) (any, error) {
+//	  out, err := next(ctx, call)
+//	  slog.InfoContext(ctx, "dynamodb", "operation", call.Operation, "message", call.MessageType, "error", err)
+//	  return out, err
+//	}
+//
+//	client := dynamodb.NewFromConfig(cfg, dynabuf.WithInterceptors(audit))
+//
+//	store := history.New[*example.User](client, "users")
+func WithInterceptors(interceptors ...Interceptor) func(*dynamodb.Options) {
+	return func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("DynabufInterceptors", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				operation := strings.TrimSuffix(reflect.TypeOf(in.Parameters).Elem().Name(), "Input")
+				call := &Call{
+					Operation: operation,
+					Input:     in.Parameters,
+				}
+				call.MessageType, _ = MessageTypeFromContext(ctx)
+				call.Message, _ = MessageFromContext(ctx)
+
+				var metadata middleware.Metadata
+				invoke := Invoker(func(ctx context.Context, call *Call) (any, error) {
+					in.Parameters = call.Input
+					out, md, err := next.HandleInitialize(ctx, in)
+					metadata = md
+					return out.Result, err
+				})
+				for i := len(interceptors) - 1; i >= 0; i-- {
+					interceptor, next := interceptors[i], invoke
+					invoke = func(ctx context.Context, call *Call) (any, error) {
+						return interceptor(ctx, call, next)
+					}
+				}
+
+				// The client expects the output of the operation's type.
+				result, err := invoke(ctx, call)
+				if t := reflect.TypeOf(result); err == nil && (t == nil || t.Kind() != reflect.Pointer || t.Elem().Name() != operation+"Output") {
+					return middleware.InitializeOutput{}, metadata, fmt.Errorf("dynabuf: interceptor returned %T as the output of %s", result, operation)
+				}
+				return middleware.InitializeOutput{Result: result}, metadata, err
+			}), middleware.After)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package dynabuf_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWithInterceptors(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		requests int
+		events   []string
+		calls    []dynabuf.Call
+	)
+
+	trace := func(name string) dynabuf.Interceptor {
+		return func(ctx context.Context, call *dynabuf.Call, next dynabuf.Invoker) (any, error) {
+			events = append(events, name+" before "+call.Operation)
+			out, err := next(ctx, call)
+			events = append(events, name+" after "+call.Operation)
+			return out, err
+		}
+	}
+	record := func(ctx context.Context, call *dynabuf.Call, next dynabuf.Invoker) (any, error) {
+		calls = append(calls, *call)
+		return next(ctx, call)
+	}
+
+	client := newTestClient(`{"Item":{"id":{"S":"1"},"name":{"S":"Alice"}}}`, &requests, dynabuf.WithInterceptors(trace("outer"), trace("inner"), record))
+
+	user := &testpb.User{Id: "1", Name: "Alice"}
+	item, err := dynabuf.Marshal(user)
+	must.NoError(t, err)
+
+	_, err = client.PutItem(dynabuf.ContextWithMessage(ctx, user), &dynamodb.PutItemInput{
+		TableName: aws.String("users"),
+		Item:      item.(map[string]types.AttributeValue),
+	})
+	must.NoError(t, err)
+
+	out, err := client.GetItem(dynabuf.ContextWithMessageType(ctx, &testpb.User{}), &dynamodb.GetItemInput{
+		TableName: aws.String("users"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}},
+	})
+	must.NoError(t, err)
+	must.Eq(t, "Alice", out.Item["name"].(*types.AttributeValueMemberS).Value)
+
+	// Interceptors run in order around every operation.
+	must.Eq(t, 2, requests)
+	must.Eq(t, []string{
+		"outer before PutItem", "inner before PutItem", "inner after PutItem", "outer after PutItem",
+		"outer before GetItem", "inner before GetItem", "inner after GetItem", "outer after GetItem",
+	}, events)
+
+	// Calls carry the message noted in their contexts.
+	must.SliceLen(t, 2, calls)
+	must.Eq(t, "dynabuf.test.v1.User", calls[0].MessageType)
+	must.True(t, proto.Equal(user, calls[0].Message))
+	must.Eq(t, "users", aws.ToString(calls[0].Input.(*dynamodb.PutItemInput).TableName))
+	must.Eq(t, "dynabuf.test.v1.User", calls[1].MessageType)
+	must.Nil(t, calls[1].Message)
+}
+
+func TestWithInterceptorsShortCircuit(t *testing.T) {
+	ctx := context.Background()
+	errDenied := errors.New("denied")
+
+	var requests int
+	client := newTestClient(`{}`, &requests, dynabuf.WithInterceptors(func(ctx context.Context, call *dynabuf.Call, next dynabuf.Invoker) (any, error) {
+		switch call.Operation {
+		case "GetItem":
+			// Answered from a cache.
+			return &dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "cached"}}}, nil
+		case "DeleteItem":
+			return nil, errDenied
+		case "Scan":
+			return &dynamodb.QueryOutput{}, nil
+		}
+		// Inputs can be changed before they are sent.
+		call.Input.(*dynamodb.PutItemInput).TableName = aws.String("audited")
+		return next(ctx, call)
+	}))
+
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String("users"), Key: map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}}})
+	must.NoError(t, err)
+	must.Eq(t, "cached", out.Item["id"].(*types.AttributeValueMemberS).Value)
+
+	_, err = client.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String("users"), Key: map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}}})
+	must.ErrorIs(t, err, errDenied)
+
+	_, err = client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("users")})
+	must.ErrorContains(t, err, "dynabuf: interceptor returned *dynamodb.QueryOutput as the output of Scan")
+
+	input := &dynamodb.PutItemInput{TableName: aws.String("users"), Item: map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}}}
+	_, err = client.PutItem(ctx, input)
+	must.NoError(t, err)
+	must.Eq(t, "audited", aws.ToString(input.TableName))
+	must.Eq(t, 1, requests)
+}
@@ -0,0 +1,61 @@
+// Package avtext formats DynamoDB attribute values as compact text for
+// debugging output.
+package avtext
+
+import (
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Format returns a compact representation of an attribute value, labeled
+// with its type as in the DynamoDB JSON format, such as:
+//
+//	M:{"id": S:"123", "age": N:42}
+func Format(av types.AttributeValue) string {
+	switch av := av.(type) {
+	case *types.AttributeValueMemberS:
+		return "S:" + strconv.Quote(av.Value)
+	case *types.AttributeValueMemberN:
+		return "N:" + av.Value
+	case *types.AttributeValueMemberB:
+		return "B:" + base64.StdEncoding.EncodeToString(av.Value)
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL:" + strconv.FormatBool(av.Value)
+	case *types.AttributeValueMemberNULL:
+		return "NULL:" + strconv.FormatBool(av.Value)
+	case *types.AttributeValueMemberSS:
+		values := make([]string, len(av.Value))
+		for i, v := range av.Value {
+			values[i] = strconv.Quote(v)
+		}
+		return "SS:[" + strings.Join(values, ", ") + "]"
+	case *types.AttributeValueMemberNS:
+		return "NS:[" + strings.Join(av.Value, ", ") + "]"
+	case *types.AttributeValueMemberBS:
+		values := make([]string, len(av.Value))
+		for i, v := range av.Value {
+			values[i] = base64.StdEncoding.EncodeToString(v)
+		}
+		return "BS:[" + strings.Join(values, ", ") + "]"
+	case *types.AttributeValueMemberL:
+		values := make([]string, len(av.Value))
+		for i, v := range av.Value {
+			values[i] = Format(v)
+		}
+		return "L:[" + strings.Join(values, ", ") + "]"
+	case *types.AttributeValueMemberM:
+		values := make([]string, 0, len(av.Value))
+		for _, name := range slices.Sorted(maps.Keys(av.Value)) {
+			values = append(values, strconv.Quote(name)+": "+Format(av.Value[name]))
+		}
+		return "M:{" + strings.Join(values, ", ") + "}"
+	default:
+		return fmt.Sprintf("%T", av)
+	}
+}
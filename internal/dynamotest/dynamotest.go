@@ -0,0 +1,741 @@
+// Package dynamotest provides an in-memory implementation of the subset of
+// the DynamoDB API used by dynabuf's packages, for use in tests.
+//
+// It understands key schemas, condition expressions, update expressions,
+// and projection expressions well enough to exercise conditional writes
+// without a real table or DynamoDB Local.
+package dynamotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/internal/avtext"
+)
+
+// Client is an in-memory DynamoDB client. The zero value is not usable,
+// use [NewClient] instead.
+type Client struct {
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+// table holds the items and key schema of a single table.
+type table struct {
+	hashKey  string
+	rangeKey string
+	items    []map[string]types.AttributeValue
+
+	// desc is the description returned by DescribeTable.
+	desc *types.TableDescription
+}
+
+// NewClient returns an empty in-memory DynamoDB client.
+func NewClient() *Client {
+	return &Client{tables: map[string]*table{}}
+}
+
+// CreateTable creates a table with the key schema in params. Only the
+// table name, key schema, attribute definitions, and global secondary
+// indexes are used, and indexes are only returned by DescribeTable.
+func (c *Client) CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := aws.ToString(params.TableName)
+	if _, ok := c.tables[name]; ok {
+		return nil, &types.ResourceInUseException{Message: aws.String("table already exists: " + name)}
+	}
+
+	t := &table{desc: &types.TableDescription{
+		TableName:              aws.String(name),
+		KeySchema:              params.KeySchema,
+		AttributeDefinitions:   params.AttributeDefinitions,
+		TableStatus:            types.TableStatusActive,
+		GlobalSecondaryIndexes: make([]types.GlobalSecondaryIndexDescription, len(params.GlobalSecondaryIndexes)),
+	}}
+	for i, index := range params.GlobalSecondaryIndexes {
+		t.desc.GlobalSecondaryIndexes[i] = types.GlobalSecondaryIndexDescription{
+			IndexName:  index.IndexName,
+			KeySchema:  index.KeySchema,
+			Projection: index.Projection,
+		}
+	}
+	for _, k := range params.KeySchema {
+		switch k.KeyType {
+		case types.KeyTypeHash:
+			t.hashKey = aws.ToString(k.AttributeName)
+		case types.KeyTypeRange:
+			t.rangeKey = aws.ToString(k.AttributeName)
+		}
+	}
+	c.tables[name] = t
+
+	return &dynamodb.CreateTableOutput{}, nil
+}
+
+// DescribeTable returns the name, key schema, attribute definitions, and
+// global secondary indexes the table was created with.
+func (c *Client) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+	desc := *t.desc
+	desc.ItemCount = aws.Int64(int64(len(t.items)))
+
+	return &dynamodb.DescribeTableOutput{Table: &desc}, nil
+}
+
+// GetItem returns the item with the given key, if any.
+func (c *Client) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	_, item := t.find(params.Key)
+	item, err = project(aws.ToString(params.ProjectionExpression), params.ExpressionAttributeNames, clone(item))
+	if err != nil {
+		return nil, validationError(err)
+	}
+
+	return &dynamodb.GetItemOutput{Item: item}, nil
+}
+
+// PutItem creates or replaces an item, honoring any condition expression.
+func (c *Client) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.validateKey(params.Item); err != nil {
+		return nil, err
+	}
+
+	idx, old := t.find(params.Item)
+	if err := checkCondition(params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues, old); err != nil {
+		return nil, err
+	}
+
+	t.store(idx, clone(params.Item))
+
+	out := &dynamodb.PutItemOutput{}
+	if params.ReturnValues == types.ReturnValueAllOld {
+		out.Attributes = old
+	}
+	return out, nil
+}
+
+// UpdateItem applies an update expression to an item, creating it if
+// needed, and honoring any condition expression.
+func (c *Client) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.validateKey(params.Key); err != nil {
+		return nil, err
+	}
+
+	idx, old := t.find(params.Key)
+	if err := checkCondition(params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues, old); err != nil {
+		return nil, err
+	}
+
+	item := clone(old)
+	if item == nil {
+		item = clone(params.Key)
+	}
+	if params.UpdateExpression != nil {
+		if err := applyUpdate(aws.ToString(params.UpdateExpression), params.ExpressionAttributeNames, params.ExpressionAttributeValues, item); err != nil {
+			return nil, validationError(err)
+		}
+	}
+
+	t.store(idx, item)
+
+	out := &dynamodb.UpdateItemOutput{}
+	switch params.ReturnValues {
+	case types.ReturnValueAllNew:
+		out.Attributes = clone(item)
+	case types.ReturnValueAllOld:
+		out.Attributes = old
+	case types.ReturnValueUpdatedNew:
+		out.Attributes = changed(old, item)
+	case types.ReturnValueUpdatedOld:
+		out.Attributes = changed(item, old)
+	}
+	return out, nil
+}
+
+// DeleteItem deletes an item, honoring any condition expression.
+func (c *Client) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, old := t.find(params.Key)
+	if err := checkCondition(params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues, old); err != nil {
+		return nil, err
+	}
+
+	if idx >= 0 {
+		t.items = append(t.items[:idx], t.items[idx+1:]...)
+	}
+
+	out := &dynamodb.DeleteItemOutput{}
+	if params.ReturnValues == types.ReturnValueAllOld {
+		out.Attributes = old
+	}
+	return out, nil
+}
+
+// Scan returns a page of items in the order they were first written,
+// honoring the segment, exclusive start key, limit, filter expression, and
+// projection expression. Items are assigned to segments by a hash of their
+// key.
+func (c *Client) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	items := t.items
+	if total := aws.ToInt32(params.TotalSegments); total > 0 {
+		segment := aws.ToInt32(params.Segment)
+		if segment < 0 || segment >= total {
+			return nil, validationError(fmt.Errorf("the segment %d is out of range for %d total segments", segment, total))
+		}
+		items = nil
+		for _, item := range t.items {
+			h := fnv.New32a()
+			h.Write([]byte(avtext.Format(&types.AttributeValueMemberM{Value: t.key(item)})))
+			if int32(h.Sum32()%uint32(total)) == segment {
+				items = append(items, item)
+			}
+		}
+	}
+
+	start := 0
+	if params.ExclusiveStartKey != nil {
+		idx := slices.IndexFunc(items, func(item map[string]types.AttributeValue) bool {
+			return t.sameKey(item, params.ExclusiveStartKey)
+		})
+		if idx < 0 {
+			return nil, validationError(fmt.Errorf("the exclusive start key does not match an item"))
+		}
+		start = idx + 1
+	}
+
+	end := len(items)
+	if limit := int(aws.ToInt32(params.Limit)); limit > 0 && start+limit < end {
+		end = start + limit
+	}
+
+	out := &dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{}}
+	for _, item := range items[start:end] {
+		out.ScannedCount++
+
+		if params.FilterExpression != nil {
+			ok, err := evalCondition(aws.ToString(params.FilterExpression), params.ExpressionAttributeNames, params.ExpressionAttributeValues, item)
+			if err != nil {
+				return nil, validationError(err)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		projected, err := project(aws.ToString(params.ProjectionExpression), params.ExpressionAttributeNames, clone(item))
+		if err != nil {
+			return nil, validationError(err)
+		}
+		out.Items = append(out.Items, projected)
+		out.Count++
+	}
+
+	if end < len(items) {
+		out.LastEvaluatedKey = t.key(items[end-1])
+	}
+
+	return out, nil
+}
+
+// BatchWriteItem applies the put and delete requests of every table.
+// All requests are processed, so UnprocessedItems is always empty.
+func (c *Client) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, requests := range params.RequestItems {
+		t, err := c.table(aws.String(name))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(requests) > 25 {
+			return nil, validationError(fmt.Errorf("too many items requested for the BatchWriteItem call"))
+		}
+
+		for _, r := range requests {
+			switch {
+			case r.PutRequest != nil:
+				if err := t.validateKey(r.PutRequest.Item); err != nil {
+					return nil, err
+				}
+				idx, _ := t.find(r.PutRequest.Item)
+				t.store(idx, clone(r.PutRequest.Item))
+			case r.DeleteRequest != nil:
+				if idx, _ := t.find(r.DeleteRequest.Key); idx >= 0 {
+					t.items = append(t.items[:idx], t.items[idx+1:]...)
+				}
+			}
+		}
+	}
+
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{}}, nil
+}
+
+// BatchGetItem returns the items with the given keys of every table,
+// honoring projection expressions. All keys are processed, so
+// UnprocessedKeys is always empty.
+func (c *Client) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := &dynamodb.BatchGetItemOutput{
+		Responses:       map[string][]map[string]types.AttributeValue{},
+		UnprocessedKeys: map[string]types.KeysAndAttributes{},
+	}
+	for name, ka := range params.RequestItems {
+		t, err := c.table(aws.String(name))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ka.Keys) > 100 {
+			return nil, validationError(fmt.Errorf("too many items requested for the BatchGetItem call"))
+		}
+
+		for i, key := range ka.Keys {
+			for _, prev := range ka.Keys[:i] {
+				if t.sameKey(key, prev) {
+					return nil, validationError(fmt.Errorf("provided list of item keys contains duplicates"))
+				}
+			}
+
+			_, item := t.find(key)
+			if item == nil {
+				continue
+			}
+			item, err = project(aws.ToString(ka.ProjectionExpression), ka.ExpressionAttributeNames, clone(item))
+			if err != nil {
+				return nil, validationError(err)
+			}
+			out.Responses[name] = append(out.Responses[name], item)
+		}
+	}
+
+	return out, nil
+}
+
+// Query returns a page of the items matching the key condition expression,
+// ordered by sort key, honoring the scan direction, exclusive start key,
+// limit, filter expression, and projection expression.
+func (c *Client) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.KeyConditionExpression == nil {
+		return nil, validationError(fmt.Errorf("the key condition expression is required"))
+	}
+
+	var matched []map[string]types.AttributeValue
+	for _, item := range t.items {
+		ok, err := evalCondition(aws.ToString(params.KeyConditionExpression), params.ExpressionAttributeNames, params.ExpressionAttributeValues, item)
+		if err != nil {
+			return nil, validationError(err)
+		}
+		if ok {
+			matched = append(matched, item)
+		}
+	}
+
+	if t.rangeKey != "" {
+		slices.SortStableFunc(matched, func(a, b map[string]types.AttributeValue) int {
+			c, _ := compare(a[t.rangeKey], b[t.rangeKey])
+			return c
+		})
+	}
+	forward := params.ScanIndexForward == nil || *params.ScanIndexForward
+	if !forward {
+		slices.Reverse(matched)
+	}
+
+	// The query resumes after the sort key of the exclusive start key, so
+	// items deleted since the previous page, such as while deleting the
+	// items queried, are skipped as DynamoDB does.
+	start := 0
+	if key := params.ExclusiveStartKey; key != nil {
+		start = len(matched)
+		for i, item := range matched {
+			if t.rangeKey == "" {
+				if t.sameKey(item, key) {
+					start = i + 1
+					break
+				}
+				continue
+			}
+			c, _ := compare(item[t.rangeKey], key[t.rangeKey])
+			if (forward && c > 0) || (!forward && c < 0) {
+				start = i
+				break
+			}
+		}
+	}
+
+	end := len(matched)
+	if limit := int(aws.ToInt32(params.Limit)); limit > 0 && start+limit < end {
+		end = start + limit
+	}
+
+	out := &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{}}
+	for _, item := range matched[start:end] {
+		out.ScannedCount++
+
+		if params.FilterExpression != nil {
+			ok, err := evalCondition(aws.ToString(params.FilterExpression), params.ExpressionAttributeNames, params.ExpressionAttributeValues, item)
+			if err != nil {
+				return nil, validationError(err)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		projected, err := project(aws.ToString(params.ProjectionExpression), params.ExpressionAttributeNames, clone(item))
+		if err != nil {
+			return nil, validationError(err)
+		}
+		out.Items = append(out.Items, projected)
+		out.Count++
+	}
+
+	if end < len(matched) {
+		out.LastEvaluatedKey = t.key(matched[end-1])
+	}
+
+	return out, nil
+}
+
+// TransactGetItems returns the items with the keys of the get actions, in
+// order, honoring projection expressions, with an empty response for
+// items that do not exist.
+func (c *Client) TransactGetItems(ctx context.Context, params *dynamodb.TransactGetItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactGetItemsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(params.TransactItems) > 100 {
+		return nil, validationError(fmt.Errorf("too many items requested for the TransactGetItems call"))
+	}
+
+	out := &dynamodb.TransactGetItemsOutput{Responses: make([]types.ItemResponse, len(params.TransactItems))}
+	for i, action := range params.TransactItems {
+		get := action.Get
+		if get == nil {
+			return nil, validationError(fmt.Errorf("transact item %d has no get action", i))
+		}
+		t, err := c.table(get.TableName)
+		if err != nil {
+			return nil, err
+		}
+		_, item := t.find(get.Key)
+		item, err = project(aws.ToString(get.ProjectionExpression), get.ExpressionAttributeNames, item)
+		if err != nil {
+			return nil, validationError(err)
+		}
+		out.Responses[i] = types.ItemResponse{Item: item}
+	}
+
+	return out, nil
+}
+
+// TransactWriteItems applies the put, update, delete, and condition check
+// actions atomically: if any condition fails, none of them are applied and
+// a TransactionCanceledException lists the reason of each action.
+func (c *Client) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(params.TransactItems) > 100 {
+		return nil, validationError(fmt.Errorf("too many items requested for the TransactWriteItems call"))
+	}
+
+	type write struct {
+		table *table
+		key   map[string]types.AttributeValue
+		item  map[string]types.AttributeValue // nil to delete
+		check bool
+	}
+
+	var (
+		writes  []write
+		reasons = make([]types.CancellationReason, len(params.TransactItems))
+		failed  bool
+	)
+
+	for i, ti := range params.TransactItems {
+		var (
+			w         write
+			tableName *string
+			expr      *string
+			names     map[string]string
+			values    map[string]types.AttributeValue
+		)
+
+		switch {
+		case ti.Put != nil:
+			tableName, expr, names, values = ti.Put.TableName, ti.Put.ConditionExpression, ti.Put.ExpressionAttributeNames, ti.Put.ExpressionAttributeValues
+			w.key, w.item = ti.Put.Item, clone(ti.Put.Item)
+		case ti.Update != nil:
+			tableName, expr, names, values = ti.Update.TableName, ti.Update.ConditionExpression, ti.Update.ExpressionAttributeNames, ti.Update.ExpressionAttributeValues
+			w.key = ti.Update.Key
+		case ti.Delete != nil:
+			tableName, expr, names, values = ti.Delete.TableName, ti.Delete.ConditionExpression, ti.Delete.ExpressionAttributeNames, ti.Delete.ExpressionAttributeValues
+			w.key = ti.Delete.Key
+		case ti.ConditionCheck != nil:
+			tableName, expr, names, values = ti.ConditionCheck.TableName, ti.ConditionCheck.ConditionExpression, ti.ConditionCheck.ExpressionAttributeNames, ti.ConditionCheck.ExpressionAttributeValues
+			w.key, w.check = ti.ConditionCheck.Key, true
+		default:
+			return nil, validationError(fmt.Errorf("transact item %d has no action", i))
+		}
+
+		t, err := c.table(tableName)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.validateKey(w.key); err != nil {
+			return nil, err
+		}
+		w.table = t
+
+		for _, prev := range writes {
+			if prev.table == t && t.sameKey(prev.key, w.key) {
+				return nil, validationError(fmt.Errorf("transaction request cannot include multiple operations on one item"))
+			}
+		}
+
+		_, old := t.find(w.key)
+		if ti.Update != nil {
+			w.item = clone(old)
+			if w.item == nil {
+				w.item = clone(w.key)
+			}
+			if err := applyUpdate(aws.ToString(ti.Update.UpdateExpression), names, values, w.item); err != nil {
+				return nil, validationError(err)
+			}
+		}
+
+		reasons[i].Code = aws.String("None")
+		if err := checkCondition(expr, names, values, old); err != nil {
+			if !isConditionalCheckFailed(err) {
+				return nil, err
+			}
+			reasons[i].Code = aws.String("ConditionalCheckFailed")
+			reasons[i].Message = aws.String("The conditional request failed")
+			failed = true
+		}
+
+		writes = append(writes, w)
+	}
+
+	if failed {
+		return nil, &types.TransactionCanceledException{
+			Message:             aws.String("Transaction cancelled, please refer cancellation reasons for specific reasons"),
+			CancellationReasons: reasons,
+		}
+	}
+
+	for _, w := range writes {
+		idx, _ := w.table.find(w.key)
+		switch {
+		case w.check:
+		case w.item == nil:
+			if idx >= 0 {
+				w.table.items = append(w.table.items[:idx], w.table.items[idx+1:]...)
+			}
+		default:
+			w.table.store(idx, w.item)
+		}
+	}
+
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (c *Client) table(name *string) (*table, error) {
+	t, ok := c.tables[aws.ToString(name)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("table not found: " + aws.ToString(name))}
+	}
+	return t, nil
+}
+
+func (t *table) validateKey(item map[string]types.AttributeValue) error {
+	for _, k := range []string{t.hashKey, t.rangeKey} {
+		if k == "" {
+			continue
+		}
+		if _, ok := item[k]; !ok {
+			return validationError(fmt.Errorf("missing the key %s in the item", k))
+		}
+	}
+	return nil
+}
+
+// find returns the index and a copy of the item matching the key
+// attributes of key, or -1 and nil.
+func (t *table) find(key map[string]types.AttributeValue) (int, map[string]types.AttributeValue) {
+	for i, item := range t.items {
+		if t.sameKey(item, key) {
+			return i, clone(item)
+		}
+	}
+	return -1, nil
+}
+
+func (t *table) sameKey(a, b map[string]types.AttributeValue) bool {
+	if !equal(a[t.hashKey], b[t.hashKey]) {
+		return false
+	}
+	return t.rangeKey == "" || equal(a[t.rangeKey], b[t.rangeKey])
+}
+
+// key returns the key attributes of item.
+func (t *table) key(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := map[string]types.AttributeValue{t.hashKey: cloneValue(item[t.hashKey])}
+	if t.rangeKey != "" {
+		key[t.rangeKey] = cloneValue(item[t.rangeKey])
+	}
+	return key
+}
+
+func (t *table) store(idx int, item map[string]types.AttributeValue) {
+	if idx < 0 {
+		t.items = append(t.items, item)
+		return
+	}
+	t.items[idx] = item
+}
+
+func checkCondition(expr *string, names map[string]string, values map[string]types.AttributeValue, item map[string]types.AttributeValue) error {
+	ok, err := evalCondition(aws.ToString(expr), names, values, item)
+	if err != nil {
+		return validationError(err)
+	}
+	if !ok {
+		return &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	return nil
+}
+
+func isConditionalCheckFailed(err error) bool {
+	var ccf *types.ConditionalCheckFailedException
+	return errors.As(err, &ccf)
+}
+
+// changed returns the attributes of b that differ from a.
+func changed(a, b map[string]types.AttributeValue) map[string]types.AttributeValue {
+	out := map[string]types.AttributeValue{}
+	for k, v := range b {
+		if !equal(a[k], v) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func equal(a, b types.AttributeValue) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// clone returns a deep copy of item.
+func clone(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	out := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		out[k] = cloneValue(v)
+	}
+	return out
+}
+
+func cloneValue(v types.AttributeValue) types.AttributeValue {
+	switch val := v.(type) {
+	case *types.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: clone(val.Value)}
+	case *types.AttributeValueMemberL:
+		l := make([]types.AttributeValue, len(val.Value))
+		for i, e := range val.Value {
+			l[i] = cloneValue(e)
+		}
+		return &types.AttributeValueMemberL{Value: l}
+	case *types.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: append([]string{}, val.Value...)}
+	case *types.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: append([]string{}, val.Value...)}
+	default:
+		return v
+	}
+}
+
+func validationError(err error) error {
+	return &smithyValidationError{err: err}
+}
+
+// smithyValidationError mimics the ValidationException returned by
+// DynamoDB for malformed requests.
+type smithyValidationError struct {
+	err error
+}
+
+func (e *smithyValidationError) Error() string {
+	return "ValidationException: " + e.err.Error()
+}
+
+func (e *smithyValidationError) Unwrap() error { return e.err }
@@ -0,0 +1,904 @@
+package dynamotest
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// token is a single lexical token of a DynamoDB expression.
+type token struct {
+	kind string // "ident", "name", "value", "number", "op", or "eof"
+	text string
+}
+
+// lex splits a DynamoDB expression into tokens.
+func lex(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '#' || c == ':':
+			j := i + 1
+			for j < len(s) && (isIdentRune(rune(s[j]))) {
+				j++
+			}
+			kind := "name"
+			if c == ':' {
+				kind = "value"
+			}
+			toks = append(toks, token{kind: kind, text: s[i:j]})
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(s) && unicode.IsDigit(rune(s[j])) {
+				j++
+			}
+			toks = append(toks, token{kind: "number", text: s[i:j]})
+			i = j
+		case isIdentRune(c):
+			j := i
+			for j < len(s) && isIdentRune(rune(s[j])) {
+				j++
+			}
+			toks = append(toks, token{kind: "ident", text: s[i:j]})
+			i = j
+		case strings.HasPrefix(s[i:], "<>") || strings.HasPrefix(s[i:], "<=") || strings.HasPrefix(s[i:], ">="):
+			toks = append(toks, token{kind: "op", text: s[i : i+2]})
+			i += 2
+		case strings.ContainsRune("=<>(),.[]+-", c):
+			toks = append(toks, token{kind: "op", text: string(c)})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q in expression %q", c, s)
+		}
+	}
+	return append(toks, token{kind: "eof"}), nil
+}
+
+func isIdentRune(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// pathElem is one element of a document path, either a map key or a list index.
+type pathElem struct {
+	name  string
+	index int
+	isIdx bool
+}
+
+type path []pathElem
+
+// parser is a recursive descent parser for condition, key condition,
+// update, and projection expressions.
+type parser struct {
+	toks   []token
+	pos    int
+	names  map[string]string
+	values map[string]types.AttributeValue
+}
+
+func newParser(expr string, names map[string]string, values map[string]types.AttributeValue) (*parser, error) {
+	toks, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{toks: toks, names: names, values: values}, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != "eof" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == "ident" && strings.EqualFold(t.text, kw)
+}
+
+func (p *parser) expectOp(op string) error {
+	if t := p.next(); t.kind != "op" || t.text != op {
+		return fmt.Errorf("expected %q, got %q", op, t.text)
+	}
+	return nil
+}
+
+// parsePath parses a document path such as "#a.b[0]".
+func (p *parser) parsePath() (path, error) {
+	var out path
+	for {
+		t := p.next()
+		var name string
+		switch t.kind {
+		case "name":
+			n, ok := p.names[t.text]
+			if !ok {
+				return nil, fmt.Errorf("undefined expression attribute name %s", t.text)
+			}
+			name = n
+		case "ident":
+			name = t.text
+		default:
+			return nil, fmt.Errorf("expected attribute path, got %q", t.text)
+		}
+		out = append(out, pathElem{name: name})
+		for p.peek().kind == "op" && p.peek().text == "[" {
+			p.next()
+			n := p.next()
+			if n.kind != "number" {
+				return nil, fmt.Errorf("expected list index, got %q", n.text)
+			}
+			idx, _ := strconv.Atoi(n.text)
+			out = append(out, pathElem{index: idx, isIdx: true})
+			if err := p.expectOp("]"); err != nil {
+				return nil, err
+			}
+		}
+		if p.peek().kind == "op" && p.peek().text == "." {
+			p.next()
+			continue
+		}
+		return out, nil
+	}
+}
+
+// operand is a value producing node of a condition or update expression.
+type operand func(item map[string]types.AttributeValue) (types.AttributeValue, error)
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.peek()
+	switch {
+	case t.kind == "value":
+		p.next()
+		v, ok := p.values[t.text]
+		if !ok {
+			return nil, fmt.Errorf("undefined expression attribute value %s", t.text)
+		}
+		return func(map[string]types.AttributeValue) (types.AttributeValue, error) { return v, nil }, nil
+	case t.kind == "ident" && strings.EqualFold(t.text, "size"):
+		p.next()
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		pth, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+			v := lookup(item, pth)
+			if v == nil {
+				return nil, nil
+			}
+			return &types.AttributeValueMemberN{Value: strconv.Itoa(sizeOf(v))}, nil
+		}, nil
+	case t.kind == "ident" && strings.EqualFold(t.text, "if_not_exists"):
+		p.next()
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		pth, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(","); err != nil {
+			return nil, err
+		}
+		def, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+			if v := lookup(item, pth); v != nil {
+				return v, nil
+			}
+			return def(item)
+		}, nil
+	case t.kind == "ident" && strings.EqualFold(t.text, "list_append"):
+		p.next()
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		a, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(","); err != nil {
+			return nil, err
+		}
+		b, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+			av, err := a(item)
+			if err != nil {
+				return nil, err
+			}
+			bv, err := b(item)
+			if err != nil {
+				return nil, err
+			}
+			al, ok1 := av.(*types.AttributeValueMemberL)
+			bl, ok2 := bv.(*types.AttributeValueMemberL)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("list_append operands must be lists")
+			}
+			out := append(append([]types.AttributeValue{}, al.Value...), bl.Value...)
+			return &types.AttributeValueMemberL{Value: out}, nil
+		}, nil
+	default:
+		pth, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+			return lookup(item, pth), nil
+		}, nil
+	}
+}
+
+// parseArith parses an operand optionally followed by "+" or "-" and
+// another operand, as allowed in SET actions.
+func (p *parser) parseArith() (operand, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t.kind != "op" || (t.text != "+" && t.text != "-") {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+		l, err := left(item)
+		if err != nil {
+			return nil, err
+		}
+		r, err := right(item)
+		if err != nil {
+			return nil, err
+		}
+		ln, ok1 := l.(*types.AttributeValueMemberN)
+		rn, ok2 := r.(*types.AttributeValueMemberN)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("arithmetic operands must be numbers")
+		}
+		a, b := parseNumber(ln.Value), parseNumber(rn.Value)
+		if t.text == "+" {
+			return &types.AttributeValueMemberN{Value: formatNumber(new(big.Rat).Add(a, b))}, nil
+		}
+		return &types.AttributeValueMemberN{Value: formatNumber(new(big.Rat).Sub(a, b))}, nil
+	}, nil
+}
+
+// condition is a boolean node of a condition expression.
+type condition func(item map[string]types.AttributeValue) (bool, error)
+
+func (p *parser) parseCondition() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(item map[string]types.AttributeValue) (bool, error) {
+			ok, err := l(item)
+			if err != nil || ok {
+				return ok, err
+			}
+			return right(item)
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(item map[string]types.AttributeValue) (bool, error) {
+			ok, err := l(item)
+			if err != nil || !ok {
+				return ok, err
+			}
+			return right(item)
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (condition, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		c, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) (bool, error) {
+			ok, err := c(item)
+			return !ok, err
+		}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (condition, error) {
+	t := p.peek()
+	if t.kind == "op" && t.text == "(" {
+		p.next()
+		c, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		return c, p.expectOp(")")
+	}
+
+	if t.kind == "ident" && p.toks[p.pos+1].kind == "op" && p.toks[p.pos+1].text == "(" {
+		switch strings.ToLower(t.text) {
+		case "attribute_exists", "attribute_not_exists":
+			p.next()
+			p.next()
+			pth, err := p.parsePath()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			want := strings.EqualFold(t.text, "attribute_exists")
+			return func(item map[string]types.AttributeValue) (bool, error) {
+				return (lookup(item, pth) != nil) == want, nil
+			}, nil
+		case "attribute_type":
+			p.next()
+			p.next()
+			pth, err := p.parsePath()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(","); err != nil {
+				return nil, err
+			}
+			typ, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			return func(item map[string]types.AttributeValue) (bool, error) {
+				v := lookup(item, pth)
+				tv, _ := typ(item)
+				s, ok := tv.(*types.AttributeValueMemberS)
+				return v != nil && ok && typeName(v) == s.Value, nil
+			}, nil
+		case "begins_with", "contains":
+			p.next()
+			p.next()
+			a, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(","); err != nil {
+				return nil, err
+			}
+			b, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			fn := strings.ToLower(t.text)
+			return func(item map[string]types.AttributeValue) (bool, error) {
+				av, _ := a(item)
+				bv, _ := b(item)
+				if av == nil || bv == nil {
+					return false, nil
+				}
+				if fn == "begins_with" {
+					as, ok1 := av.(*types.AttributeValueMemberS)
+					bs, ok2 := bv.(*types.AttributeValueMemberS)
+					return ok1 && ok2 && strings.HasPrefix(as.Value, bs.Value), nil
+				}
+				return contains(av, bv), nil
+			}, nil
+		}
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isKeyword("BETWEEN") {
+		p.next()
+		lo, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isKeyword("AND") {
+			return nil, fmt.Errorf("expected AND in BETWEEN")
+		}
+		p.next()
+		hi, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) (bool, error) {
+			v, _ := left(item)
+			l, _ := lo(item)
+			h, _ := hi(item)
+			c1, ok1 := compare(v, l)
+			c2, ok2 := compare(v, h)
+			return ok1 && ok2 && c1 >= 0 && c2 <= 0, nil
+		}, nil
+	}
+
+	if p.isKeyword("IN") {
+		p.next()
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		var opts []operand
+		for {
+			o, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, o)
+			if p.peek().text == "," {
+				p.next()
+				continue
+			}
+			break
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) (bool, error) {
+			v, _ := left(item)
+			for _, o := range opts {
+				ov, _ := o(item)
+				if c, ok := compare(v, ov); ok && c == 0 {
+					return true, nil
+				}
+			}
+			return false, nil
+		}, nil
+	}
+
+	op := p.next()
+	if op.kind != "op" {
+		return nil, fmt.Errorf("expected comparator, got %q", op.text)
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return func(item map[string]types.AttributeValue) (bool, error) {
+		l, _ := left(item)
+		r, _ := right(item)
+		c, ok := compare(l, r)
+		switch op.text {
+		case "=":
+			return ok && c == 0, nil
+		case "<>":
+			return !ok || c != 0, nil
+		case "<":
+			return ok && c < 0, nil
+		case "<=":
+			return ok && c <= 0, nil
+		case ">":
+			return ok && c > 0, nil
+		case ">=":
+			return ok && c >= 0, nil
+		default:
+			return false, fmt.Errorf("unknown comparator %q", op.text)
+		}
+	}, nil
+}
+
+// evalCondition evaluates a condition expression against item.
+func evalCondition(expr string, names map[string]string, values map[string]types.AttributeValue, item map[string]types.AttributeValue) (bool, error) {
+	if expr == "" {
+		return true, nil
+	}
+	p, err := newParser(expr, names, values)
+	if err != nil {
+		return false, err
+	}
+	c, err := p.parseCondition()
+	if err != nil {
+		return false, err
+	}
+	if t := p.peek(); t.kind != "eof" {
+		return false, fmt.Errorf("unexpected token %q in expression %q", t.text, expr)
+	}
+	return c(item)
+}
+
+// applyUpdate applies an update expression to item in place.
+func applyUpdate(expr string, names map[string]string, values map[string]types.AttributeValue, item map[string]types.AttributeValue) error {
+	p, err := newParser(expr, names, values)
+	if err != nil {
+		return err
+	}
+
+	// All operands are evaluated against the original item.
+	orig := clone(item)
+
+	for p.peek().kind != "eof" {
+		clause := p.next()
+		if clause.kind != "ident" {
+			return fmt.Errorf("expected update clause, got %q", clause.text)
+		}
+		kw := strings.ToUpper(clause.text)
+		for {
+			pth, err := p.parsePath()
+			if err != nil {
+				return err
+			}
+			switch kw {
+			case "SET":
+				if err := p.expectOp("="); err != nil {
+					return err
+				}
+				o, err := p.parseArith()
+				if err != nil {
+					return err
+				}
+				v, err := o(orig)
+				if err != nil {
+					return err
+				}
+				if v == nil {
+					return fmt.Errorf("operand in SET refers to a missing attribute")
+				}
+				if err := set(item, pth, v); err != nil {
+					return err
+				}
+			case "REMOVE":
+				remove(item, pth)
+			case "ADD", "DELETE":
+				o, err := p.parseOperand()
+				if err != nil {
+					return err
+				}
+				v, err := o(orig)
+				if err != nil {
+					return err
+				}
+				cur := lookup(item, pth)
+				nv, err := addOrDelete(kw, cur, v)
+				if err != nil {
+					return err
+				}
+				if nv == nil {
+					remove(item, pth)
+				} else if err := set(item, pth, nv); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unknown update clause %q", clause.text)
+			}
+			if p.peek().kind == "op" && p.peek().text == "," {
+				p.next()
+				continue
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// project returns the subset of item named by a projection expression.
+func project(expr string, names map[string]string, item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	if expr == "" || item == nil {
+		return item, nil
+	}
+	p, err := newParser(expr, names, nil)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]types.AttributeValue{}
+	for {
+		pth, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		if v, ok := item[pth[0].name]; ok {
+			out[pth[0].name] = v
+		}
+		if p.peek().kind == "op" && p.peek().text == "," {
+			p.next()
+			continue
+		}
+		break
+	}
+	return out, nil
+}
+
+func lookup(item map[string]types.AttributeValue, pth path) types.AttributeValue {
+	var cur types.AttributeValue = &types.AttributeValueMemberM{Value: item}
+	for _, e := range pth {
+		switch v := cur.(type) {
+		case *types.AttributeValueMemberM:
+			if e.isIdx {
+				return nil
+			}
+			next, ok := v.Value[e.name]
+			if !ok {
+				return nil
+			}
+			cur = next
+		case *types.AttributeValueMemberL:
+			if !e.isIdx || e.index >= len(v.Value) {
+				return nil
+			}
+			cur = v.Value[e.index]
+		default:
+			return nil
+		}
+	}
+	return cur
+}
+
+func set(item map[string]types.AttributeValue, pth path, v types.AttributeValue) error {
+	parent := lookup(item, pth[:len(pth)-1])
+	last := pth[len(pth)-1]
+	switch pv := parent.(type) {
+	case *types.AttributeValueMemberM:
+		if last.isIdx {
+			return fmt.Errorf("cannot index into a map")
+		}
+		pv.Value[last.name] = v
+	case *types.AttributeValueMemberL:
+		if !last.isIdx {
+			return fmt.Errorf("cannot set a named attribute on a list")
+		}
+		if last.index >= len(pv.Value) {
+			pv.Value = append(pv.Value, v)
+		} else {
+			pv.Value[last.index] = v
+		}
+	default:
+		return fmt.Errorf("document path provided in the update expression is invalid for update")
+	}
+	return nil
+}
+
+func remove(item map[string]types.AttributeValue, pth path) {
+	parent := lookup(item, pth[:len(pth)-1])
+	last := pth[len(pth)-1]
+	switch pv := parent.(type) {
+	case *types.AttributeValueMemberM:
+		delete(pv.Value, last.name)
+	case *types.AttributeValueMemberL:
+		if last.isIdx && last.index < len(pv.Value) {
+			pv.Value = append(pv.Value[:last.index], pv.Value[last.index+1:]...)
+		}
+	}
+}
+
+func addOrDelete(kw string, cur, v types.AttributeValue) (types.AttributeValue, error) {
+	switch val := v.(type) {
+	case *types.AttributeValueMemberN:
+		if kw != "ADD" {
+			return nil, fmt.Errorf("DELETE requires a set operand")
+		}
+		sum := parseNumber(val.Value)
+		if cur != nil {
+			cn, ok := cur.(*types.AttributeValueMemberN)
+			if !ok {
+				return nil, fmt.Errorf("an operand in the update expression has an incorrect data type")
+			}
+			sum = new(big.Rat).Add(sum, parseNumber(cn.Value))
+		}
+		return &types.AttributeValueMemberN{Value: formatNumber(sum)}, nil
+	case *types.AttributeValueMemberSS:
+		var existing []string
+		if cs, ok := cur.(*types.AttributeValueMemberSS); ok {
+			existing = cs.Value
+		}
+		out := mergeSet(kw, existing, val.Value)
+		if len(out) == 0 {
+			return nil, nil
+		}
+		return &types.AttributeValueMemberSS{Value: out}, nil
+	case *types.AttributeValueMemberNS:
+		var existing []string
+		if cs, ok := cur.(*types.AttributeValueMemberNS); ok {
+			existing = cs.Value
+		}
+		out := mergeSet(kw, existing, val.Value)
+		if len(out) == 0 {
+			return nil, nil
+		}
+		return &types.AttributeValueMemberNS{Value: out}, nil
+	default:
+		return nil, fmt.Errorf("%s requires a number or set operand", kw)
+	}
+}
+
+func mergeSet(kw string, existing, operand []string) []string {
+	out := append([]string{}, existing...)
+	for _, s := range operand {
+		idx := -1
+		for i, e := range out {
+			if e == s {
+				idx = i
+				break
+			}
+		}
+		switch {
+		case kw == "ADD" && idx < 0:
+			out = append(out, s)
+		case kw == "DELETE" && idx >= 0:
+			out = append(out[:idx], out[idx+1:]...)
+		}
+	}
+	return out
+}
+
+// compare compares two scalar attribute values of the same type. The
+// boolean result reports whether the values are comparable.
+func compare(a, b types.AttributeValue) (int, bool) {
+	switch av := a.(type) {
+	case *types.AttributeValueMemberS:
+		bv, ok := b.(*types.AttributeValueMemberS)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av.Value, bv.Value), true
+	case *types.AttributeValueMemberN:
+		bv, ok := b.(*types.AttributeValueMemberN)
+		if !ok {
+			return 0, false
+		}
+		return parseNumber(av.Value).Cmp(parseNumber(bv.Value)), true
+	case *types.AttributeValueMemberB:
+		bv, ok := b.(*types.AttributeValueMemberB)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(string(av.Value), string(bv.Value)), true
+	case *types.AttributeValueMemberBOOL:
+		bv, ok := b.(*types.AttributeValueMemberBOOL)
+		if !ok || av.Value != bv.Value {
+			return 1, ok
+		}
+		return 0, true
+	case nil:
+		return 0, false
+	default:
+		if equal(a, b) {
+			return 0, true
+		}
+		return 1, true
+	}
+}
+
+func contains(a, b types.AttributeValue) bool {
+	switch av := a.(type) {
+	case *types.AttributeValueMemberS:
+		bs, ok := b.(*types.AttributeValueMemberS)
+		return ok && strings.Contains(av.Value, bs.Value)
+	case *types.AttributeValueMemberSS:
+		bs, ok := b.(*types.AttributeValueMemberS)
+		if !ok {
+			return false
+		}
+		for _, s := range av.Value {
+			if s == bs.Value {
+				return true
+			}
+		}
+	case *types.AttributeValueMemberL:
+		for _, v := range av.Value {
+			if equal(v, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeName(v types.AttributeValue) string {
+	switch v.(type) {
+	case *types.AttributeValueMemberS:
+		return "S"
+	case *types.AttributeValueMemberN:
+		return "N"
+	case *types.AttributeValueMemberB:
+		return "B"
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL"
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberM:
+		return "M"
+	case *types.AttributeValueMemberL:
+		return "L"
+	case *types.AttributeValueMemberSS:
+		return "SS"
+	case *types.AttributeValueMemberNS:
+		return "NS"
+	case *types.AttributeValueMemberBS:
+		return "BS"
+	}
+	return ""
+}
+
+func sizeOf(v types.AttributeValue) int {
+	switch val := v.(type) {
+	case *types.AttributeValueMemberS:
+		return len(val.Value)
+	case *types.AttributeValueMemberB:
+		return len(val.Value)
+	case *types.AttributeValueMemberM:
+		return len(val.Value)
+	case *types.AttributeValueMemberL:
+		return len(val.Value)
+	case *types.AttributeValueMemberSS:
+		return len(val.Value)
+	case *types.AttributeValueMemberNS:
+		return len(val.Value)
+	case *types.AttributeValueMemberBS:
+		return len(val.Value)
+	}
+	return 0
+}
+
+func parseNumber(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+func formatNumber(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	f, _ := r.Float64()
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
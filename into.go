@@ -0,0 +1,92 @@
+package dynabuf
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrAttributeExists is returned by [MarshalInto], with
+// [ConflictError], when the item already has an attribute of the message.
+var ErrAttributeExists = errors.New("dynabuf: attribute already exists")
+
+// ConflictPolicy is how [MarshalInto] handles the attributes of a message
+// which the item it writes into already has.
+type ConflictPolicy int
+
+const (
+	// ConflictOverwrite replaces the attributes of the item with those of
+	// the message, which is the default.
+	ConflictOverwrite ConflictPolicy = iota
+
+	// ConflictKeep keeps the attributes of the item, leaving out those of
+	// the message.
+	ConflictKeep
+
+	// ConflictError fails with an error wrapping [ErrAttributeExists],
+	// leaving the item unchanged.
+	ConflictError
+)
+
+// WithConflicts handles the attributes of the message which the item
+// [MarshalInto] writes into already has with the given policy.
+//
+// # Example
+//
+//	err := dynabuf.MarshalInto(order, item, dynabuf.WithConflicts(dynabuf.ConflictError))
+func WithConflicts(policy ConflictPolicy) Option {
+	return func(o *options) {
+		o.conflicts = policy
+	}
+}
+
+// MarshalInto marshals msg as [Marshal] does, writing its attributes into
+// dst, such as an item holding computed attributes stored alongside the
+// message, like keys, timestamps, and entity types. Attributes dst already
+// has are handled as set with [WithConflicts]. If msg cannot be marshaled,
+// dst is left unchanged.
+//
+// # Example
+//
+//	item := map[string]types.AttributeValue{
+//	  "pk":   &types.AttributeValueMemberS{Value: "USER#" + user.GetId()},
+//	  "type": &types.AttributeValueMemberS{Value: "user"},
+//	}
+//	if err := dynabuf.MarshalInto(user, item, dynabuf.WithConflicts(dynabuf.ConflictError)); err != nil {
+//	  return err
+//	}
+func MarshalInto(msg proto.Message, dst map[string]types.AttributeValue, opts ...Option) error {
+	if dst == nil {
+		return fmt.Errorf("%w: nil item to marshal into", ErrFailedToMarshal)
+	}
+
+	item, err := marshalProtoMessage(msg, opts...)
+	if err != nil {
+		return err
+	}
+
+	policy := newOptions(opts).conflicts
+	if policy == ConflictError {
+		var conflicts []string
+		for name := range item {
+			if _, ok := dst[name]; ok {
+				conflicts = append(conflicts, name)
+			}
+		}
+		if len(conflicts) > 0 {
+			slices.Sort(conflicts)
+			return fmt.Errorf("%w: %w: %s", ErrFailedToMarshal, ErrAttributeExists, quoteNames(conflicts))
+		}
+	}
+
+	for name, v := range item {
+		if _, ok := dst[name]; ok && policy == ConflictKeep {
+			continue
+		}
+		dst[name] = v
+	}
+	return nil
+}
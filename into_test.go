@@ -0,0 +1,49 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestMarshalInto(t *testing.T) {
+	user := &testpb.User{Id: "1", Name: "Alice"}
+	base := func() map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"pk":   &types.AttributeValueMemberS{Value: "USER#1"},
+			"name": &types.AttributeValueMemberS{Value: "computed"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		policy   dynabuf.ConflictPolicy
+		wantName string
+		wantErr  bool
+	}{
+		{name: "overwrite", policy: dynabuf.ConflictOverwrite, wantName: "Alice"},
+		{name: "keep", policy: dynabuf.ConflictKeep, wantName: "computed"},
+		{name: "error", policy: dynabuf.ConflictError, wantName: "computed", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item := base()
+			err := dynabuf.MarshalInto(user, item, dynabuf.WithConflicts(test.policy))
+			if test.wantErr {
+				must.ErrorIs(t, err, dynabuf.ErrAttributeExists)
+				must.MapNotContainsKey(t, item, "id")
+			} else {
+				must.NoError(t, err)
+				must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "1"}, item["id"])
+			}
+			must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "USER#1"}, item["pk"])
+			must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: test.wantName}, item["name"])
+		})
+	}
+
+	must.Error(t, dynabuf.MarshalInto(user, nil))
+}
@@ -0,0 +1,188 @@
+package dynabuf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// ItemBuilder builds an item attribute by attribute, mixing raw attributes
+// with messages, such as for fixtures and partial items in tests. It is
+// returned by [Item].
+//
+// Attributes set more than once keep the last value. Errors, such as a
+// message failing to marshal, are returned by [ItemBuilder.Build].
+type ItemBuilder struct {
+	item map[string]types.AttributeValue
+	errs []error
+}
+
+// Item returns an [ItemBuilder] of an empty item.
+//
+// # Example
+//
+//	item := dynabuf.Item().
+//	  S("pk", "USER#1").
+//	  S("sk", "PROFILE").
+//	  N("age", 30).
+//	  Msg("profile", profile).
+//	  MustBuild()
+func Item() *ItemBuilder {
+	return &ItemBuilder{item: map[string]types.AttributeValue{}}
+}
+
+// S sets a string attribute.
+func (b *ItemBuilder) S(name, value string) *ItemBuilder {
+	return b.Attr(name, &types.AttributeValueMemberS{Value: value})
+}
+
+// N sets a number attribute to value, which is an integer or floating
+// point number, a *big.Int or *big.Float, or a string holding a number.
+func (b *ItemBuilder) N(name string, value any) *ItemBuilder {
+	n, err := numberText(value)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("dynabuf: attribute %q: %w", name, err))
+		return b
+	}
+	return b.Attr(name, &types.AttributeValueMemberN{Value: n})
+}
+
+// B sets a binary attribute.
+func (b *ItemBuilder) B(name string, value []byte) *ItemBuilder {
+	return b.Attr(name, &types.AttributeValueMemberB{Value: value})
+}
+
+// Bool sets a boolean attribute.
+func (b *ItemBuilder) Bool(name string, value bool) *ItemBuilder {
+	return b.Attr(name, &types.AttributeValueMemberBOOL{Value: value})
+}
+
+// Null sets a NULL attribute.
+func (b *ItemBuilder) Null(name string) *ItemBuilder {
+	return b.Attr(name, &types.AttributeValueMemberNULL{Value: true})
+}
+
+// SS sets a string set attribute.
+func (b *ItemBuilder) SS(name string, values ...string) *ItemBuilder {
+	return b.Attr(name, &types.AttributeValueMemberSS{Value: values})
+}
+
+// NS sets a number set attribute, with values of the types accepted by
+// [ItemBuilder.N].
+func (b *ItemBuilder) NS(name string, values ...any) *ItemBuilder {
+	ns := make([]string, len(values))
+	for i, v := range values {
+		n, err := numberText(v)
+		if err != nil {
+			b.errs = append(b.errs, fmt.Errorf("dynabuf: attribute %q: %w", name, err))
+			return b
+		}
+		ns[i] = n
+	}
+	return b.Attr(name, &types.AttributeValueMemberNS{Value: ns})
+}
+
+// Attr sets an attribute to a raw attribute value.
+func (b *ItemBuilder) Attr(name string, value types.AttributeValue) *ItemBuilder {
+	b.item[name] = value
+	return b
+}
+
+// Msg sets a map attribute to msg, marshaled with opts.
+func (b *ItemBuilder) Msg(name string, msg proto.Message, opts ...Option) *ItemBuilder {
+	item, err := marshalProtoMessage(msg, opts...)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("dynabuf: attribute %q: %w", name, err))
+		return b
+	}
+	return b.Attr(name, &types.AttributeValueMemberM{Value: item})
+}
+
+// Merge sets the attributes of the item of msg, marshaled with opts, such
+// as to start from a message and add the attributes stored alongside it.
+func (b *ItemBuilder) Merge(msg proto.Message, opts ...Option) *ItemBuilder {
+	item, err := marshalProtoMessage(msg, opts...)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	for name, v := range item {
+		b.item[name] = v
+	}
+	return b
+}
+
+// Build returns the item, or the errors of the attributes which could not
+// be set.
+func (b *ItemBuilder) Build() (map[string]types.AttributeValue, error) {
+	if err := errors.Join(b.errs...); err != nil {
+		return nil, err
+	}
+	item := make(map[string]types.AttributeValue, len(b.item))
+	for name, v := range b.item {
+		item[name] = v
+	}
+	return item, nil
+}
+
+// MustBuild is like [ItemBuilder.Build] but panics if an attribute could
+// not be set. It is intended for tests and fixtures whose values are known
+// to be valid.
+func (b *ItemBuilder) MustBuild() map[string]types.AttributeValue {
+	item, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return item
+}
+
+// numberText returns the text of a number attribute holding v.
+func numberText(v any) (string, error) {
+	switch v := v.(type) {
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return "", fmt.Errorf("%v is not a number DynamoDB can store", v)
+		}
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", fmt.Errorf("%v is not a number DynamoDB can store", v)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case *big.Int:
+		return v.String(), nil
+	case *big.Float:
+		return v.Text('g', -1), nil
+	case string:
+		if f, ok := new(big.Float).SetString(v); !ok || f.IsInf() {
+			return "", fmt.Errorf("%q is not a number", v)
+		}
+		return v, nil
+	}
+	return "", fmt.Errorf("%T is not a number", v)
+}
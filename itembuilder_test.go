@@ -0,0 +1,60 @@
+package dynabuf_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/dynabuftest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestItemBuilder(t *testing.T) {
+	address := &testpb.Address{Street: "Main St"}
+
+	item, err := dynabuf.Item().
+		S("pk", "USER#1").
+		N("age", 30).
+		N("score", 1.5).
+		N("big", new(big.Int).Lsh(big.NewInt(1), 100)).
+		Bool("active", true).
+		Null("deleted").
+		SS("tags", "a", "b").
+		NS("lucky", 7, "13").
+		B("blob", []byte{1}).
+		Msg("address", address).
+		Build()
+	must.NoError(t, err)
+
+	want := map[string]types.AttributeValue{
+		"pk":      &types.AttributeValueMemberS{Value: "USER#1"},
+		"age":     &types.AttributeValueMemberN{Value: "30"},
+		"score":   &types.AttributeValueMemberN{Value: "1.5"},
+		"big":     &types.AttributeValueMemberN{Value: "1267650600228229401496703205376"},
+		"active":  &types.AttributeValueMemberBOOL{Value: true},
+		"deleted": &types.AttributeValueMemberNULL{Value: true},
+		"tags":    &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"lucky":   &types.AttributeValueMemberNS{Value: []string{"7", "13"}},
+		"blob":    &types.AttributeValueMemberB{Value: []byte{1}},
+		"address": &types.AttributeValueMemberM{Value: dynabuf.MustMarshalItem(address)},
+	}
+	must.Eq(t, "", dynabuftest.Diff(want, item))
+
+	// Messages merged into the item set their attributes.
+	item = dynabuf.Item().
+		Merge(&testpb.User{Id: "1", Name: "Alice"}).
+		S("gsi1pk", "NAME#Alice").
+		MustBuild()
+	must.Eq(t, "", dynabuftest.Diff(map[string]types.AttributeValue{
+		"id":     &types.AttributeValueMemberS{Value: "1"},
+		"name":   &types.AttributeValueMemberS{Value: "Alice"},
+		"gsi1pk": &types.AttributeValueMemberS{Value: "NAME#Alice"},
+	}, item))
+
+	// Invalid attributes are reported by Build.
+	_, err = dynabuf.Item().N("age", "thirty").N("score", struct{}{}).Build()
+	must.ErrorContains(t, err, `"age"`)
+	must.ErrorContains(t, err, `"score"`)
+}
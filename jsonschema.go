@@ -0,0 +1,198 @@
+package dynabuf
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// JSONSchema returns a [JSON Schema] describing the items of a table whose
+// messages map to attributes as described by spec, in the DynamoDB JSON
+// format used by table exports and the DynamoDB API, such as:
+//
+//	{"id": {"S": "123"}, "age": {"N": "42"}}
+//
+// The keys are the names of the attributes every item must have, usually
+// the table's key attributes. Other attributes are optional, since
+// [Marshal] omits fields with default values.
+//
+// The schema uses the 2020-12 dialect, which is also the schema dialect of
+// OpenAPI 3.1, so it can be embedded in OpenAPI documents as is.
+//
+// # Example
+//
+//	spec := dynabuf.Spec((&example.User{}).ProtoReflect().Descriptor())
+//
+//	schema, _ := dynabuf.JSONSchema(spec, "id")
+//
+// [JSON Schema]: https://json-schema.org/
+func JSONSchema(spec *dynabufpb.MappingSpec, keys ...string) ([]byte, error) {
+	defs := map[string]any{}
+	for _, msg := range spec.GetMessages() {
+		defs[msg.GetName()] = messageSchema(msg)
+	}
+
+	root, ok := defs[spec.GetRoot()].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("dynabuf: mapping spec has no message for its root %q", spec.GetRoot())
+	}
+
+	schema := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   spec.GetRoot(),
+		"$defs":   defs,
+	}
+	for k, v := range root {
+		schema[k] = v
+	}
+	if len(keys) > 0 {
+		schema["required"] = keys
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// messageSchema returns the schema of the attributes of a message.
+func messageSchema(msg *dynabufpb.MessageSpec) map[string]any {
+	properties := map[string]any{}
+	for _, attr := range msg.GetAttributes() {
+		var schema map[string]any
+		switch attr.GetValue().GetType() {
+		case "L":
+			schema = attributeValueSchema("L", map[string]any{
+				"type":  "array",
+				"items": valueSchema(attr.GetElement()),
+			})
+		case "M":
+			if attr.GetElement() != nil {
+				schema = attributeValueSchema("M", map[string]any{
+					"type":                 "object",
+					"additionalProperties": valueSchema(attr.GetElement()),
+				})
+				break
+			}
+			fallthrough
+		default:
+			schema = valueSchema(attr.GetValue())
+		}
+		schema["description"] = attr.GetField()
+		properties[attr.GetName()] = schema
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// valueSchema returns the schema of a single DynamoDB JSON attribute value.
+func valueSchema(v *dynabufpb.ValueSpec) map[string]any {
+	switch v.GetEncoding() {
+	case dynabufpb.Encoding_ENCODING_FLOAT:
+		return map[string]any{
+			"oneOf": []any{
+				attributeValueSchema("N", map[string]any{"type": "string"}),
+				attributeValueSchema("S", map[string]any{"enum": []string{"NaN", "Infinity", "-Infinity"}}),
+			},
+		}
+	case dynabufpb.Encoding_ENCODING_ENUM_NAME:
+		// Values unknown to the schema are stored as numbers.
+		return map[string]any{
+			"oneOf": []any{
+				attributeValueSchema("S", enumSchema(v.GetEnum())),
+				attributeValueSchema("N", map[string]any{"type": "string"}),
+			},
+		}
+	case dynabufpb.Encoding_ENCODING_JSON:
+		if v.GetType() == "" {
+			return anyAttributeValueSchema()
+		}
+	}
+
+	switch v.GetType() {
+	case "S":
+		return attributeValueSchema("S", stringSchema(v))
+	case "N":
+		return attributeValueSchema("N", map[string]any{"type": "string"})
+	case "BOOL":
+		return attributeValueSchema("BOOL", map[string]any{"type": "boolean"})
+	case "NULL":
+		return attributeValueSchema("NULL", map[string]any{"const": true})
+	case "L":
+		return attributeValueSchema("L", map[string]any{"type": "array"})
+	case "M":
+		if v.GetMessage() != "" {
+			return attributeValueSchema("M", map[string]any{"$ref": "#/$defs/" + v.GetMessage()})
+		}
+		return attributeValueSchema("M", map[string]any{"type": "object"})
+	default:
+		return anyAttributeValueSchema()
+	}
+}
+
+// stringSchema returns the schema of the string of an S attribute value.
+func stringSchema(v *dynabufpb.ValueSpec) map[string]any {
+	schema := map[string]any{"type": "string"}
+
+	switch v.GetEncoding() {
+	case dynabufpb.Encoding_ENCODING_DECIMAL_STRING:
+		schema["pattern"] = "^-?[0-9]+$"
+	case dynabufpb.Encoding_ENCODING_BASE64:
+		schema["contentEncoding"] = "base64"
+	case dynabufpb.Encoding_ENCODING_RFC3339:
+		schema["format"] = "date-time"
+	case dynabufpb.Encoding_ENCODING_DURATION:
+		schema["pattern"] = `^-?[0-9]+(\.[0-9]+)?s$`
+	case dynabufpb.Encoding_ENCODING_DATE:
+		schema["pattern"] = "^[0-9]{4}-[0-9]{2}-[0-9]{2}$"
+	case dynabufpb.Encoding_ENCODING_TIME_OF_DAY:
+		schema["pattern"] = `^[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?$`
+	}
+
+	return schema
+}
+
+// enumSchema returns the schema of the names of an enum's values, limited
+// to the values known if the enum is registered in this binary.
+func enumSchema(name string) map[string]any {
+	schema := map[string]any{"type": "string"}
+
+	et, err := protoregistry.GlobalTypes.FindEnumByName(protoreflect.FullName(name))
+	if err != nil {
+		return schema
+	}
+
+	values := et.Descriptor().Values()
+	names := make([]string, values.Len())
+	for i := range values.Len() {
+		names[i] = string(values.Get(i).Name())
+	}
+	schema["enum"] = names
+
+	return schema
+}
+
+// attributeValueSchema returns the schema of a DynamoDB JSON attribute
+// value of the given type, whose value matches schema.
+func attributeValueSchema(typ string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{typ: schema},
+		"required":             []string{typ},
+		"additionalProperties": false,
+	}
+}
+
+// anyAttributeValueSchema returns the schema of a DynamoDB JSON attribute
+// value of any type.
+func anyAttributeValueSchema() map[string]any {
+	return map[string]any{
+		"type":          "object",
+		"minProperties": 1,
+		"maxProperties": 1,
+	}
+}
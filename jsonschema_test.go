@@ -0,0 +1,50 @@
+package dynabuf_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestJSONSchema(t *testing.T) {
+	b, err := dynabuf.JSONSchema(dynabuf.Spec((&testpb.User{}).ProtoReflect().Descriptor()), "id")
+	must.NoError(t, err)
+
+	var schema struct {
+		Schema     string         `json:"$schema"`
+		Title      string         `json:"title"`
+		Required   []string       `json:"required"`
+		Defs       map[string]any `json:"$defs"`
+		Properties map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+		} `json:"properties"`
+	}
+	must.NoError(t, json.Unmarshal(b, &schema))
+
+	must.Eq(t, "https://json-schema.org/draft/2020-12/schema", schema.Schema)
+	must.Eq(t, "dynabuf.test.v1.User", schema.Title)
+	must.Eq(t, []string{"id"}, schema.Required)
+	must.MapContainsKeys(t, schema.Defs, []string{"dynabuf.test.v1.User", "dynabuf.test.v1.Address"})
+
+	must.Eq(t, []string{"N"}, schema.Properties["age"].Required)
+	must.Eq[any](t, "date-time", schema.Properties["createTime"].Properties["S"]["format"])
+	must.Eq[any](t, "#/$defs/dynabuf.test.v1.Address", schema.Properties["address"].Properties["M"]["$ref"])
+	must.Eq[any](t, "array", schema.Properties["previousAddresses"].Properties["L"]["type"])
+
+	b, err = dynabuf.JSONSchema(dynabuf.Spec((&testpb.Job{}).ProtoReflect().Descriptor()))
+	must.NoError(t, err)
+
+	var job struct {
+		Properties map[string]struct {
+			OneOf []struct {
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"oneOf"`
+		} `json:"properties"`
+	}
+	must.NoError(t, json.Unmarshal(b, &job))
+	must.Eq[any](t, []any{"STATE_UNSPECIFIED", "STATE_RUNNING", "STATE_SUCCEEDED", "STATE_FAILED"}, job.Properties["state"].OneOf[0].Properties["S"]["enum"])
+}
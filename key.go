@@ -0,0 +1,150 @@
+package dynabuf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// The maximum lengths of key attribute values, in bytes, which DynamoDB
+// rejects longer values of.
+const (
+	MaxPartitionKeyLength = 2048
+	MaxSortKeyLength      = 1024
+)
+
+// ErrInvalidKey is returned when the value of a key attribute cannot be
+// stored by DynamoDB, such as empty or overly long values, which usually
+// come from user input.
+var ErrInvalidKey = errors.New("dynabuf: invalid key")
+
+// Key returns the key of the item storing msg, made of the attributes with
+// the given names, such as the table's partition and sort key attributes.
+// It returns an error if msg has no value for one of them, or an error
+// wrapping [ErrInvalidKey] if the key is invalid, as [ValidateKey] checks.
+//
+// The options are those the item was marshaled with, so the key matches
+// it, such as [WithProtoNames] naming the attributes, and
+// [WithNormalizedKeys] normalizing the values of the named attributes.
+//
+// # Example
+//
+//	key, _ := dynabuf.Key(&example.User{Id: "123"}, []string{"id"})
+//
+//	out, _ := client.GetItem(ctx, &dynamodb.GetItemInput{
+//	  TableName: aws.String("users"),
+//	  Key:       key,
+//	})
+func Key(msg proto.Message, names []string, opts ...Option) (map[string]types.AttributeValue, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("dynabuf: a key requires at least one attribute")
+	}
+
+	av, err := Marshal(msg, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := itemKey(av.(map[string]types.AttributeValue), names)
+	if err != nil {
+		return nil, err
+	}
+	if o := newOptions(opts); o.normalizeKeys {
+		NormalizeKey(key, o.foldKeys)
+	}
+	return key, nil
+}
+
+// keyAttributes returns the names of the key attributes of the table
+// storing messages of type md, as annotated with key_attributes, or nil if
+// they are not annotated.
+func keyAttributes(md protoreflect.MessageDescriptor) []string {
+	opts, _ := proto.GetExtension(md.Options(), dynabufpb.E_Message).(*dynabufpb.MessageOptions)
+	return opts.GetKeyAttributes()
+}
+
+// itemKey returns the attributes of item with the given names.
+func itemKey(item map[string]types.AttributeValue, names []string) (map[string]types.AttributeValue, error) {
+	key := make(map[string]types.AttributeValue, len(names))
+	for _, name := range names {
+		v, ok := item[name]
+		if !ok {
+			return nil, fmt.Errorf("dynabuf: item is missing key attribute %q", name)
+		}
+		key[name] = v
+	}
+	if err := ValidateKey(key, names...); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ValidateKey returns an error wrapping [ErrInvalidKey] if the attributes of
+// key with the given names, the partition key and then the sort key, if
+// any, are not values DynamoDB accepts in keys: strings, numbers, or
+// binary values, which are neither empty nor longer than
+// [MaxPartitionKeyLength] and [MaxSortKeyLength] bytes.
+//
+// Keys returned by [Key] are validated, so keys built from user input are
+// rejected with a descriptive error before reaching DynamoDB.
+//
+// # Example
+//
+//	if err := dynabuf.ValidateKey(key, "id", "sk"); err != nil {
+//	  return status.Error(codes.InvalidArgument, err.Error())
+//	}
+func ValidateKey(key map[string]types.AttributeValue, names ...string) error {
+	for i, name := range names {
+		role, limit := "partition key", MaxPartitionKeyLength
+		if i > 0 {
+			role, limit = "sort key", MaxSortKeyLength
+		}
+
+		var n int
+		switch v := key[name].(type) {
+		case nil:
+			return fmt.Errorf("%w: %s attribute %q is missing", ErrInvalidKey, role, name)
+		case *types.AttributeValueMemberS:
+			n = len(v.Value)
+		case *types.AttributeValueMemberB:
+			n = len(v.Value)
+		case *types.AttributeValueMemberN:
+			continue
+		default:
+			return fmt.Errorf("%w: %s attribute %q must be a string, number, or binary value, got %T", ErrInvalidKey, role, name, v)
+		}
+
+		switch {
+		case n == 0:
+			return fmt.Errorf("%w: %s attribute %q is empty", ErrInvalidKey, role, name)
+		case n > limit:
+			return fmt.Errorf("%w: %s attribute %q is %d bytes, longer than the limit of %d bytes", ErrInvalidKey, role, name, n, limit)
+		}
+	}
+	return nil
+}
+
+// EscapeKeyComponent escapes the characters of separators, and backslashes,
+// in s with a backslash, so it can be joined with other components into a
+// key, such as "ORDER#" + EscapeKeyComponent(id, "#"), which can be split
+// back unambiguously. Composite attributes escape the values of their
+// fields so.
+func EscapeKeyComponent(s, separators string) string {
+	if !strings.ContainsAny(s, separators+`\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		if r == '\\' || strings.ContainsRune(separators, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
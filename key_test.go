@@ -0,0 +1,144 @@
+package dynabuf_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestKey(t *testing.T) {
+	key, err := dynabuf.Key(&testpb.User{Id: "1", Name: "Alice"}, []string{"id"})
+	must.NoError(t, err)
+	must.Eq(t, map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberS{Value: "1"},
+	}, key)
+
+	// Default values are not stored, so they cannot be part of a key.
+	_, err = dynabuf.Key(&testpb.User{Name: "Alice"}, []string{"id"})
+	must.Error(t, err)
+
+	_, err = dynabuf.Key(&testpb.User{Id: "1"}, nil)
+	must.Error(t, err)
+}
+
+func TestKeyOptions(t *testing.T) {
+	ctx := context.Background()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("users"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	opts := []dynabuf.Option{dynabuf.WithNormalizedKeys(true), dynabuf.WithKeyAttributes("id")}
+
+	// "Caf\u00e9" with a combining accent is stored normalized.
+	item, err := dynabuf.Marshal(&testpb.User{Id: "Cafe\u0301", Name: "Alice"}, opts...)
+	must.NoError(t, err)
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String("users"),
+		Item:      item.(map[string]types.AttributeValue),
+	})
+	must.NoError(t, err)
+
+	// Keys built with the same options find the item.
+	key, err := dynabuf.Key(&testpb.User{Id: "CAF\u00c9"}, []string{"id"}, opts...)
+	must.NoError(t, err)
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("users"),
+		Key:       key,
+	})
+	must.NoError(t, err)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "Alice"}, out.Item["name"])
+
+	// Attributes are named as the options name them.
+	key, err = dynabuf.Key(&testpb.Address{ZipCode: 12345}, []string{"zip_code"}, dynabuf.WithProtoNames(true))
+	must.NoError(t, err)
+	must.Eq(t, map[string]types.AttributeValue{
+		"zip_code": &types.AttributeValueMemberN{Value: "12345"},
+	}, key)
+}
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  map[string]types.AttributeValue
+		err  string
+	}{
+		{
+			name: "valid",
+			key: map[string]types.AttributeValue{
+				"pk": &types.AttributeValueMemberS{Value: strings.Repeat("a", dynabuf.MaxPartitionKeyLength)},
+				"sk": &types.AttributeValueMemberN{Value: "1"},
+			},
+		},
+		{
+			name: "missing",
+			key:  map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "a"}},
+			err:  `sort key attribute "sk" is missing`,
+		},
+		{
+			name: "empty",
+			key: map[string]types.AttributeValue{
+				"pk": &types.AttributeValueMemberB{Value: []byte{}},
+				"sk": &types.AttributeValueMemberS{Value: "a"},
+			},
+			err: `partition key attribute "pk" is empty`,
+		},
+		{
+			name: "too long",
+			key: map[string]types.AttributeValue{
+				"pk": &types.AttributeValueMemberS{Value: "a"},
+				"sk": &types.AttributeValueMemberS{Value: strings.Repeat("a", dynabuf.MaxSortKeyLength+1)},
+			},
+			err: `sort key attribute "sk" is 1025 bytes, longer than the limit of 1024 bytes`,
+		},
+		{
+			name: "wrong type",
+			key: map[string]types.AttributeValue{
+				"pk": &types.AttributeValueMemberBOOL{Value: true},
+				"sk": &types.AttributeValueMemberS{Value: "a"},
+			},
+			err: `partition key attribute "pk" must be a string, number, or binary value`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := dynabuf.ValidateKey(test.key, "pk", "sk")
+			if test.err == "" {
+				must.NoError(t, err)
+				return
+			}
+			must.ErrorIs(t, err, dynabuf.ErrInvalidKey)
+			must.StrContains(t, err.Error(), test.err)
+		})
+	}
+
+	_, err := dynabuf.Key(&testpb.User{Id: strings.Repeat("x", 3000)}, []string{"id"})
+	must.ErrorIs(t, err, dynabuf.ErrInvalidKey)
+}
+
+func TestEscapeKeyComponent(t *testing.T) {
+	must.Eq(t, "plain", dynabuf.EscapeKeyComponent("plain", "#"))
+	must.Eq(t, `a\#b\\c`, dynabuf.EscapeKeyComponent(`a#b\c`, "#"))
+	must.Eq(t, `a\|b\#c`, dynabuf.EscapeKeyComponent("a|b#c", "#|"))
+
+	_, err := dynabuf.Marshal(&testpb.Order{Customer: "acme", Id: "1\x00"})
+	must.ErrorIs(t, err, dynabuf.ErrInvalidKey)
+	must.StrContains(t, err.Error(), `field "id" of composite attribute "sk" has control character '\x00'`)
+}
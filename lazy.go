@@ -0,0 +1,56 @@
+package dynabuf
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// LazyItem is an item read from DynamoDB, decoded as a message of type T
+// only when [LazyItem.Message] is first called, so consumers filtering most
+// items away by their attributes do not pay to decode them.
+//
+// A LazyItem is safe for concurrent use.
+type LazyItem[T proto.Message] struct {
+	item map[string]types.AttributeValue
+	opts []Option
+
+	once sync.Once
+	msg  T
+	err  error
+}
+
+// NewLazyItem returns a [LazyItem] decoding item with opts when its message
+// is first read.
+func NewLazyItem[T proto.Message](item map[string]types.AttributeValue, opts ...Option) *LazyItem[T] {
+	return &LazyItem[T]{item: item, opts: opts}
+}
+
+// Item returns the attributes of the item, as read, which must not be
+// changed.
+func (l *LazyItem[T]) Item() map[string]types.AttributeValue {
+	return l.item
+}
+
+// Message returns the item decoded as a message of type T, decoding it on
+// the first call, and returning the same message and error afterwards.
+//
+// # Example
+//
+//	for lazy, err := range query.ReadLazy[*example.Order](ctx, client, "orders", expr) {
+//	  if err != nil {
+//	    return err
+//	  }
+//	  if status, _ := lazy.Item()["status"].(*types.AttributeValueMemberS); status == nil || status.Value != "OPEN" {
+//	    continue
+//	  }
+//	  order, err := lazy.Message()
+//	  ...
+//	}
+func (l *LazyItem[T]) Message() (T, error) {
+	l.once.Do(func() {
+		l.msg, l.err = UnmarshalNew[T](l.item, l.opts...)
+	})
+	return l.msg, l.err
+}
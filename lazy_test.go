@@ -0,0 +1,35 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestLazyItem(t *testing.T) {
+	item := dynabuf.MustMarshalItem(&testpb.User{Id: "1", Name: "Alice"})
+
+	lazy := dynabuf.NewLazyItem[*testpb.User](item)
+	must.Eq(t, item, lazy.Item())
+
+	user, err := lazy.Message()
+	must.NoError(t, err)
+	must.Eq(t, "Alice", user.GetName())
+
+	// The item is decoded once.
+	again, err := lazy.Message()
+	must.NoError(t, err)
+	must.True(t, user == again)
+
+	// Items failing to decode return the same error every time.
+	bad := dynabuf.NewLazyItem[*testpb.User](map[string]types.AttributeValue{
+		"age": &types.AttributeValueMemberS{Value: "old"},
+	})
+	_, err = bad.Message()
+	must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+	_, errAgain := bad.Message()
+	must.True(t, err == errAgain)
+}
@@ -0,0 +1,158 @@
+package dynabuf
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// The limits DynamoDB enforces on items, which [WithLimitChecks] checks
+// items against before they are sent.
+const (
+	// MaxItemSize is the maximum size of an item, in bytes, including the
+	// names of its attributes.
+	MaxItemSize = 400 * 1024
+
+	// MaxNestingDepth is the maximum number of maps and lists an attribute
+	// value can be nested in, within a top-level attribute.
+	MaxNestingDepth = 32
+
+	// MaxAttributeNameLength is the maximum length of an attribute name, in
+	// bytes, which applies to the keys of maps too.
+	MaxAttributeNameLength = 64 * 1024
+
+	// MaxNumberDigits is the maximum number of significant digits of a
+	// number.
+	MaxNumberDigits = 38
+
+	// MinNumberExponent and MaxNumberExponent are the smallest and largest
+	// exponents of numbers in scientific notation, which are from 1E-130 to
+	// 9.9999999999999999999999999999999999999E+125 in magnitude.
+	MinNumberExponent = -130
+	MaxNumberExponent = 125
+)
+
+// ErrLimitExceeded is returned by [Marshal] with [WithLimitChecks] when an
+// item exceeds one of the limits DynamoDB enforces on items.
+var ErrLimitExceeded = errors.New("dynabuf: item exceeds DynamoDB limit")
+
+// WithLimitChecks checks items stored by [Marshal] against the limits
+// DynamoDB enforces on items, failing with an error wrapping
+// [ErrLimitExceeded] and naming the offending attribute, rather than
+// sending the item to have DynamoDB reject it: the item size, the nesting
+// depth of values, the length of attribute names, and the digits and
+// magnitude of numbers, such as formatted floats and decimal fields.
+//
+// # Example
+//
+//	item, err := dynabuf.Marshal(doc, dynabuf.WithLimitChecks())
+//	if errors.Is(err, dynabuf.ErrLimitExceeded) {
+//	  return status.Error(codes.InvalidArgument, err.Error())
+//	}
+func WithLimitChecks() Option {
+	return func(o *options) {
+		o.checkLimits = true
+	}
+}
+
+// checkLimits returns an error wrapping ErrLimitExceeded if the item
+// exceeds one of the limits DynamoDB enforces on items.
+func checkLimits(item map[string]types.AttributeValue) error {
+	if size := itemSize(item); size > MaxItemSize {
+		return fmt.Errorf("%w: item is %d bytes, larger than %d bytes", ErrLimitExceeded, size, MaxItemSize)
+	}
+	for _, name := range slices.Sorted(maps.Keys(item)) {
+		if err := checkAttributeName(name, name); err != nil {
+			return err
+		}
+		if err := checkValueLimits(name, item[name], 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAttributeName returns an error if the name of the attribute at path
+// is too long. Empty names are already rejected when encoding items.
+func checkAttributeName(path, name string) error {
+	if len(name) > MaxAttributeNameLength {
+		return fmt.Errorf("%w: attribute %q has a name of %d bytes, longer than %d bytes", ErrLimitExceeded, path, len(name), MaxAttributeNameLength)
+	}
+	return nil
+}
+
+// checkValueLimits returns an error if the value at path, nested in depth
+// maps and lists, or the values nested in it, exceed a limit.
+func checkValueLimits(path string, v types.AttributeValue, depth int) error {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberN:
+		return checkNumber(path, v.Value)
+	case *types.AttributeValueMemberNS:
+		for _, n := range v.Value {
+			if err := checkNumber(path, n); err != nil {
+				return err
+			}
+		}
+	case *types.AttributeValueMemberM:
+		if depth++; depth > MaxNestingDepth {
+			return fmt.Errorf("%w: attribute %q is nested more than %d levels deep", ErrLimitExceeded, path, MaxNestingDepth)
+		}
+		for _, name := range slices.Sorted(maps.Keys(v.Value)) {
+			if err := checkAttributeName(path+"."+name, name); err != nil {
+				return err
+			}
+			if err := checkValueLimits(path+"."+name, v.Value[name], depth); err != nil {
+				return err
+			}
+		}
+	case *types.AttributeValueMemberL:
+		if depth++; depth > MaxNestingDepth {
+			return fmt.Errorf("%w: attribute %q is nested more than %d levels deep", ErrLimitExceeded, path, MaxNestingDepth)
+		}
+		for i, elem := range v.Value {
+			if err := checkValueLimits(fmt.Sprintf("%s[%d]", path, i), elem, depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkNumber returns an error if the number at path has more significant
+// digits, or a larger or smaller magnitude, than DynamoDB stores.
+func checkNumber(path, n string) error {
+	mantissa, exp := strings.TrimLeft(n, "+-"), 0
+	if i := strings.IndexAny(mantissa, "eE"); i >= 0 {
+		e, err := strconv.Atoi(mantissa[i+1:])
+		if err != nil {
+			// Exponents too large for an int are out of range either way.
+			return fmt.Errorf("%w: number %q of attribute %q is out of range", ErrLimitExceeded, n, path)
+		}
+		mantissa, exp = mantissa[:i], e
+	}
+
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+	digits := whole + fraction
+
+	// The decimal point is after point digits, which is moved left for
+	// every leading zero removed.
+	point := len(whole) + exp
+	trimmed := strings.TrimLeft(digits, "0")
+	point -= len(digits) - len(trimmed)
+	digits = strings.TrimRight(trimmed, "0")
+
+	switch {
+	case digits == "":
+		return nil
+	case len(digits) > MaxNumberDigits:
+		return fmt.Errorf("%w: number %q of attribute %q has %d significant digits, more than %d", ErrLimitExceeded, n, path, len(digits), MaxNumberDigits)
+	case point-1 < MinNumberExponent || point-1 > MaxNumberExponent:
+		return fmt.Errorf("%w: number %q of attribute %q is out of range", ErrLimitExceeded, n, path)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package dynabuf_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// nestedLists returns a struct with an attribute nested in depth lists.
+func nestedLists(depth int) *structpb.Struct {
+	v := structpb.NewStringValue("deep")
+	for range depth {
+		v = structpb.NewListValue(&structpb.ListValue{Values: []*structpb.Value{v}})
+	}
+	return &structpb.Struct{Fields: map[string]*structpb.Value{"a": v}}
+}
+
+func TestLimitChecks(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		err  string
+	}{
+		{
+			name: "within limits",
+			msg:  &testpb.User{Id: "1", Name: "Alice", Address: &testpb.Address{Street: "Main St"}},
+		},
+		{
+			name: "item size",
+			msg:  &testpb.User{Id: "1", Name: strings.Repeat("a", dynabuf.MaxItemSize)},
+			err:  "is 409607 bytes, larger than 409600 bytes",
+		},
+		{
+			name: "nesting depth",
+			msg:  nestedLists(dynabuf.MaxNestingDepth),
+		},
+		{
+			name: "nesting too deep",
+			msg:  nestedLists(dynabuf.MaxNestingDepth + 1),
+			err:  `attribute "a` + strings.Repeat("[0]", dynabuf.MaxNestingDepth) + `" is nested more than 32 levels deep`,
+		},
+		{
+			name: "long attribute name",
+			msg: &structpb.Struct{Fields: map[string]*structpb.Value{
+				strings.Repeat("a", dynabuf.MaxAttributeNameLength+1): structpb.NewBoolValue(true),
+			}},
+			err: "has a name of 65537 bytes, longer than 65536 bytes",
+		},
+		{
+			name: "numbers within range",
+			msg:  &testpb.Product{Price: "1" + strings.Repeat("0", 125), Discounts: []string{"-12.50", "0.000", "1e-130", "9.9999999999999999999999999999999999999E+125"}},
+		},
+		{
+			name: "too many digits",
+			msg:  &testpb.Product{Price: "1." + strings.Repeat("1", dynabuf.MaxNumberDigits)},
+			err:  `of attribute "price" has 39 significant digits, more than 38`,
+		},
+		{
+			name: "too large",
+			msg:  &testpb.Product{Price: "1" + strings.Repeat("0", 126)},
+			err:  `of attribute "price" is out of range`,
+		},
+		{
+			name: "too small",
+			msg:  &testpb.Product{Discounts: []string{"1", "0.1e-130"}},
+			err:  `number "0.1e-130" of attribute "discounts[1]" is out of range`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Items are only checked when asked to.
+			_, err := dynabuf.Marshal(test.msg)
+			must.NoError(t, err)
+
+			_, err = dynabuf.Marshal(test.msg, dynabuf.WithLimitChecks())
+			if test.err == "" {
+				must.NoError(t, err)
+				return
+			}
+			must.ErrorIs(t, err, dynabuf.ErrFailedToMarshal)
+			must.ErrorIs(t, err, dynabuf.ErrLimitExceeded)
+			must.ErrorContains(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,345 @@
+// Package loadtest drives a mix of writes and reads of fake messages
+// against a DynamoDB table, reporting throughput and throttling, to
+// validate the key design of a message before traffic reaches it.
+//
+// Items are generated with [dynabuftest.Fake], so the item written for a
+// seed can be generated again to read it back by key, without keeping the
+// items written in memory.
+//
+// The AWS SDK retries throttled requests by default, so requests are only
+// reported as throttled once its retries are exhausted. Use a client with
+// retries disabled to report every throttled request:
+//
+//	client := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+//	  o.RetryMaxAttempts = 1
+//	})
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/dynabuftest"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxBatchSize is the maximum number of requests in a BatchWriteItem call.
+const maxBatchSize = 25
+
+// Client is the subset of the DynamoDB API used by [Run].
+type Client interface {
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+}
+
+// Config configures a load test.
+type Config struct {
+	// Table is the name of the table.
+	Table string
+
+	// Keys are the names of the key attributes of the table, partition key
+	// first, used to read items back.
+	Keys []string
+
+	// Operations is the number of operations, each either a batch write or
+	// a read of a single item. The test runs until Duration elapses if
+	// zero.
+	Operations int
+
+	// Duration is how long the test runs, if Operations is zero, or the
+	// maximum duration of the test otherwise. It is unlimited if zero, in
+	// which case Operations must be set.
+	Duration time.Duration
+
+	// Concurrency is the number of operations in flight at once. It
+	// defaults to 1.
+	Concurrency int
+
+	// ReadRatio is the fraction of operations that are reads, from 0 to 1.
+	// Reads are of items written earlier in the test, so operations are
+	// writes until the first batch is written.
+	ReadRatio float64
+
+	// BatchSize is the number of items written by a write operation, up to
+	// 25, which is the default.
+	BatchSize int
+
+	// Seed is the seed of the first item written, so tests with different
+	// seeds write different items.
+	Seed int64
+
+	// Config, if set, configures the requests of the test, and provides
+	// the random source the items read are picked with, so the reads of
+	// tests run one operation at a time are reproducible.
+	Config *dynabuf.Config
+}
+
+// Report describes the outcome of a load test.
+type Report struct {
+	// Elapsed is how long the test ran.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Writes and Reads are the number of items written and read.
+	Writes int `json:"writes"`
+	Reads  int `json:"reads"`
+
+	// WriteRequests and ReadRequests are the number of BatchWriteItem and
+	// GetItem requests sent.
+	WriteRequests int `json:"writeRequests"`
+	ReadRequests  int `json:"readRequests"`
+
+	// Throttles is the number of requests that failed because the table,
+	// or the account, was throttled.
+	Throttles int `json:"throttles"`
+
+	// Unprocessed is the number of items DynamoDB left unprocessed in
+	// batch writes, which it does when throttled, and which are not
+	// retried.
+	Unprocessed int `json:"unprocessed"`
+
+	// Misses is the number of reads that found no item.
+	Misses int `json:"misses"`
+
+	// Errors is the number of requests that failed for other reasons.
+	Errors int `json:"errors"`
+}
+
+// WriteThroughput returns the number of items written per second.
+func (r *Report) WriteThroughput() float64 {
+	return perSecond(r.Writes, r.Elapsed)
+}
+
+// ReadThroughput returns the number of items read per second.
+func (r *Report) ReadThroughput() float64 {
+	return perSecond(r.Reads, r.Elapsed)
+}
+
+// ThrottleRate returns the fraction of requests that were throttled,
+// counting batch writes with unprocessed items as throttled.
+func (r *Report) ThrottleRate() float64 {
+	requests := r.WriteRequests + r.ReadRequests
+	if requests == 0 {
+		return 0
+	}
+	return float64(r.Throttles) / float64(requests)
+}
+
+// perSecond returns n per second of elapsed time.
+func perSecond(n int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(n) / elapsed.Seconds()
+}
+
+// Run writes fake messages of type T to the table in batches, and reads
+// them back, in the mix given by cfg, returning a report of the outcome.
+// Failed requests are counted in the report rather than stopping the test,
+// which only returns an error if cfg is invalid, or if items cannot be
+// generated.
+//
+// # Example
+//
+//	report, _ := loadtest.Run[*example.User](ctx, client, loadtest.Config{
+//	  Table:       "users",
+//	  Keys:        []string{"id"},
+//	  Duration:    time.Minute,
+//	  Concurrency: 16,
+//	  ReadRatio:   0.8,
+//	})
+//
+//	fmt.Printf("%.0f writes/s, %.0f reads/s, %.1f%% throttled\n",
+//	  report.WriteThroughput(), report.ReadThroughput(), 100*report.ThrottleRate())
+func Run[T proto.Message](ctx context.Context, client Client, cfg Config) (*Report, error) {
+	switch {
+	case cfg.Table == "":
+		return nil, fmt.Errorf("loadtest: table is empty")
+	case len(cfg.Keys) == 0:
+		return nil, fmt.Errorf("loadtest: no key attributes")
+	case cfg.Operations <= 0 && cfg.Duration <= 0:
+		return nil, fmt.Errorf("loadtest: neither operations nor duration is set")
+	case cfg.ReadRatio < 0 || cfg.ReadRatio > 1:
+		return nil, fmt.Errorf("loadtest: read ratio %v is not between 0 and 1", cfg.ReadRatio)
+	case cfg.BatchSize < 0 || cfg.BatchSize > maxBatchSize:
+		return nil, fmt.Errorf("loadtest: batch size %d is not between 1 and %d", cfg.BatchSize, maxBatchSize)
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = maxBatchSize
+	}
+
+	// Make sure items can be generated, so errors are reported once.
+	if _, err := dynabuf.Key(dynabuftest.Fake[T](cfg.Seed), cfg.Keys); err != nil {
+		return nil, fmt.Errorf("loadtest: %w", err)
+	}
+
+	if cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
+	r := &runner[T]{client: client, cfg: cfg}
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for range max(cfg.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				op := r.ops.Add(1) - 1
+				if cfg.Operations > 0 && op >= int64(cfg.Operations) {
+					return
+				}
+				r.run(ctx, op)
+			}
+		}()
+	}
+	wg.Wait()
+
+	r.report.Elapsed = time.Since(start)
+	return &r.report, nil
+}
+
+// runner runs the operations of a load test.
+type runner[T proto.Message] struct {
+	client Client
+	cfg    Config
+
+	// ops is the number of operations started, and seeds the number of
+	// seeds of items assigned to writes.
+	ops, seeds atomic.Int64
+
+	// written holds the seeds of the batches written, which reads pick
+	// items from.
+	mu      sync.Mutex
+	written []int64
+	report  Report
+}
+
+// run runs operation op, which is a read if reads are behind the read
+// ratio.
+func (r *runner[T]) run(ctx context.Context, op int64) {
+	r.mu.Lock()
+	var seed int64
+	read := len(r.written) > 0 && int64(float64(op+1)*r.cfg.ReadRatio) > int64(float64(op)*r.cfg.ReadRatio)
+	if read {
+		rnd := r.cfg.Config.Rand()
+		seed = r.written[rnd.IntN(len(r.written))] + rnd.Int64N(int64(r.cfg.BatchSize))
+	}
+	r.mu.Unlock()
+
+	if read {
+		r.read(ctx, seed)
+	} else {
+		r.write(ctx)
+	}
+}
+
+// write writes a batch of items.
+func (r *runner[T]) write(ctx context.Context) {
+	first := r.cfg.Seed + r.seeds.Add(int64(r.cfg.BatchSize)) - int64(r.cfg.BatchSize)
+
+	requests := make([]types.WriteRequest, 0, r.cfg.BatchSize)
+	for seed := first; seed < first+int64(r.cfg.BatchSize); seed++ {
+		item, err := dynabuf.MarshalTo(dynabuf.DynamoDB, dynabuftest.Fake[T](seed))
+		if err != nil {
+			r.count(func(report *Report) { report.Errors++ })
+			return
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+
+	out, err := dynabuf.Do(ctx, r.cfg.Config, r.client.BatchWriteItem, &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{r.cfg.Table: requests},
+	})
+	if ctx.Err() != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.report.WriteRequests++
+	switch {
+	case isThrottle(err):
+		r.report.Throttles++
+	case err != nil:
+		r.report.Errors++
+	default:
+		unprocessed := len(out.UnprocessedItems[r.cfg.Table])
+		if unprocessed > 0 {
+			r.report.Throttles++
+			r.report.Unprocessed += unprocessed
+		}
+		r.report.Writes += len(requests) - unprocessed
+
+		// Batches with unprocessed items are not read back, since their
+		// items may be missing.
+		if unprocessed == 0 {
+			r.written = append(r.written, first)
+		}
+	}
+}
+
+// read reads the item of a seed back by key.
+func (r *runner[T]) read(ctx context.Context, seed int64) {
+	key, err := dynabuf.Key(dynabuftest.Fake[T](seed), r.cfg.Keys)
+	if err != nil {
+		r.count(func(report *Report) { report.Errors++ })
+		return
+	}
+
+	out, err := dynabuf.Do(ctx, r.cfg.Config, r.client.GetItem, &dynamodb.GetItemInput{
+		TableName: aws.String(r.cfg.Table),
+		Key:       key,
+	})
+	if ctx.Err() != nil {
+		return
+	}
+
+	r.count(func(report *Report) {
+		report.ReadRequests++
+		switch {
+		case isThrottle(err):
+			report.Throttles++
+		case err != nil:
+			report.Errors++
+		case out.Item == nil:
+			report.Misses++
+		default:
+			report.Reads++
+		}
+	})
+}
+
+// count updates the report.
+func (r *runner[T]) count(fn func(report *Report)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.report)
+}
+
+// isThrottle reports whether err is a throttling error of the table or the
+// account.
+func isThrottle(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "ProvisionedThroughputExceededException", "ThrottlingException", "RequestLimitExceeded":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package loadtest_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/picatz/dynabuf/loadtest"
+	"github.com/shoenig/test/must"
+)
+
+func newTable(t *testing.T) *dynamotest.Client {
+	t.Helper()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("users"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+	return client
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	report, err := loadtest.Run[*testpb.User](ctx, newTable(t), loadtest.Config{
+		Table:       "users",
+		Keys:        []string{"id"},
+		Operations:  40,
+		Concurrency: 4,
+		ReadRatio:   0.5,
+		BatchSize:   5,
+	})
+	must.NoError(t, err)
+
+	must.Eq(t, 40, report.WriteRequests+report.ReadRequests)
+	must.Positive(t, report.ReadRequests)
+	must.Eq(t, 5*report.WriteRequests, report.Writes)
+	must.Eq(t, report.ReadRequests, report.Reads)
+	must.Eq(t, 0, report.Misses)
+	must.Eq(t, 0, report.Errors)
+	must.Eq(t, 0, report.ThrottleRate())
+	must.Positive(t, report.WriteThroughput())
+}
+
+// throttlingClient throttles every other request.
+type throttlingClient struct {
+	*dynamotest.Client
+
+	requests atomic.Int64
+}
+
+func (c *throttlingClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	if c.requests.Add(1)%2 == 0 {
+		return &dynamodb.BatchWriteItemOutput{UnprocessedItems: params.RequestItems}, nil
+	}
+	return c.Client.BatchWriteItem(ctx, params, optFns...)
+}
+
+func (c *throttlingClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if c.requests.Add(1)%2 == 0 {
+		return nil, &types.ProvisionedThroughputExceededException{Message: aws.String("throttled")}
+	}
+	return c.Client.GetItem(ctx, params, optFns...)
+}
+
+func TestRunThrottled(t *testing.T) {
+	ctx := context.Background()
+
+	report, err := loadtest.Run[*testpb.User](ctx, &throttlingClient{Client: newTable(t)}, loadtest.Config{
+		Table:      "users",
+		Keys:       []string{"id"},
+		Operations: 20,
+		ReadRatio:  0.5,
+		BatchSize:  2,
+	})
+	must.NoError(t, err)
+
+	must.Eq(t, 10, report.Throttles)
+	must.Eq(t, 0.5, report.ThrottleRate())
+	must.Eq(t, 2*(report.WriteRequests-report.Unprocessed/2), report.Writes)
+	must.Eq(t, 0, report.Errors)
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	ctx := context.Background()
+
+	for _, cfg := range []loadtest.Config{
+		{Keys: []string{"id"}, Operations: 1},
+		{Table: "users", Operations: 1},
+		{Table: "users", Keys: []string{"id"}},
+		{Table: "users", Keys: []string{"id"}, Operations: 1, ReadRatio: 2},
+		{Table: "users", Keys: []string{"id"}, Operations: 1, BatchSize: 26},
+		{Table: "users", Keys: []string{"missing"}, Operations: 1},
+	} {
+		_, err := loadtest.Run[*testpb.User](ctx, newTable(t), cfg)
+		must.Error(t, err)
+	}
+}
@@ -0,0 +1,278 @@
+// Package lock implements distributed locks backed by DynamoDB items.
+//
+// Each lock is a single [Lease] item keyed by its name. Owners acquire,
+// renew, and release leases using conditional writes, so two owners can
+// never believe they hold the same lock at the same time, and a crashed
+// owner's lease can be taken over once it expires.
+//
+// Every acquisition increments the lease's fencing token. Systems guarded
+// by the lock should record the highest token they have seen and reject
+// requests carrying a lower one, protecting against owners that were paused
+// past their lease expiry.
+//
+// # Table Schema
+//
+// The table must use a string partition key named "name":
+//
+//	KeySchema: []types.KeySchemaElement{
+//	  {
+//	    AttributeName: aws.String("name"),
+//	    KeyType:       types.KeyTypeHash,
+//	  },
+//	},
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Set of errors that can be returned by a [Locker].
+var (
+	// ErrLockHeld is returned when the lock is currently held by another owner.
+	ErrLockHeld = errors.New("lock: lock is held by another owner")
+
+	// ErrLockLost is returned when the lease was released, expired and was
+	// acquired by another owner, or otherwise changed since it was acquired.
+	ErrLockLost = errors.New("lock: lease is no longer held")
+)
+
+// Client is the subset of the DynamoDB API used by a [Locker].
+type Client interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// Locker acquires and maintains leases stored in a DynamoDB table.
+//
+// A Locker is safe for concurrent use.
+type Locker struct {
+	client Client
+	table  string
+	cfg    *dynabuf.Config
+	ttl    time.Duration
+}
+
+// New returns a [Locker] storing leases in the given table, sending its
+// requests with the configuration of opts. Leases are valid for ttl after
+// they are acquired or renewed with [Locker.Heartbeat].
+func New(client Client, table string, ttl time.Duration, opts ...dynabuf.ConfigOption) *Locker {
+	return &Locker{
+		client: client,
+		table:  table,
+		cfg:    dynabuf.NewConfig(opts...),
+		ttl:    ttl,
+	}
+}
+
+// Get returns the current lease for the named lock, or nil if the lock
+// has never been acquired.
+func (l *Locker) Get(ctx context.Context, name string) (*Lease, error) {
+	ctx = dynabuf.ContextWithMessageType(ctx, &Lease{})
+
+	out, err := dynabuf.Do(ctx, l.cfg, l.client.GetItem, &dynamodb.GetItemInput{
+		TableName:      aws.String(l.table),
+		Key:            leaseKey(name),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("lock: failed to get lease %q: %w", name, err)
+	}
+
+	if len(out.Item) == 0 {
+		return nil, nil
+	}
+
+	lease := &Lease{}
+	if err := dynabuf.Unmarshal(out.Item, lease); err != nil {
+		return nil, fmt.Errorf("lock: failed to decode lease %q: %w", name, err)
+	}
+
+	return lease, nil
+}
+
+// Acquire acquires the named lock for owner, returning [ErrLockHeld] if
+// another owner holds an unexpired lease. Acquiring a lock already held by
+// the same owner takes it over with a new fencing token.
+func (l *Locker) Acquire(ctx context.Context, name, owner string) (*Lease, error) {
+	current, err := l.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := l.cfg.Now()
+
+	if current != nil && current.GetOwner() != "" && current.GetOwner() != owner && current.GetExpireTime().AsTime().After(now) {
+		return nil, fmt.Errorf("%w: %q is held by %q", ErrLockHeld, name, current.GetOwner())
+	}
+
+	lease := &Lease{
+		Name:          name,
+		Owner:         owner,
+		FencingToken:  current.GetFencingToken() + 1,
+		HeartbeatTime: timestamppb.New(now),
+		ExpireTime:    timestamppb.New(now.Add(l.ttl)),
+	}
+
+	// The write only succeeds if nobody else changed the lease since we
+	// read it, which makes the read-check-write sequence atomic.
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(l.table),
+	}
+	if current == nil {
+		input.ConditionExpression = aws.String("attribute_not_exists(#name)")
+		input.ExpressionAttributeNames = map[string]string{"#name": "name"}
+	} else {
+		var err error
+		input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues, err = unchangedCondition(current)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.put(ctx, input, lease); err != nil {
+		if isConditionalCheckFailed(err) {
+			return nil, fmt.Errorf("%w: %q was acquired concurrently", ErrLockHeld, name)
+		}
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// Heartbeat renews the lease, extending its expiry by the [Locker]'s TTL.
+// It returns [ErrLockLost] if the lease is no longer held by its owner.
+// On success, the lease is updated in place.
+func (l *Locker) Heartbeat(ctx context.Context, lease *Lease) error {
+	now := l.cfg.Now()
+
+	renewed := &Lease{
+		Name:          lease.GetName(),
+		Owner:         lease.GetOwner(),
+		FencingToken:  lease.GetFencingToken(),
+		HeartbeatTime: timestamppb.New(now),
+		ExpireTime:    timestamppb.New(now.Add(l.ttl)),
+	}
+
+	if err := l.putHeld(ctx, lease, renewed); err != nil {
+		return err
+	}
+
+	lease.HeartbeatTime = renewed.HeartbeatTime
+	lease.ExpireTime = renewed.ExpireTime
+
+	return nil
+}
+
+// Release gives up the lease so another owner can acquire it immediately.
+// It returns [ErrLockLost] if the lease is no longer held by its owner.
+//
+// The lease item is kept with an empty owner rather than deleted, so the
+// next owner continues from the same fencing token.
+func (l *Locker) Release(ctx context.Context, lease *Lease) error {
+	now := timestamppb.New(l.cfg.Now())
+
+	released := &Lease{
+		Name:          lease.GetName(),
+		FencingToken:  lease.GetFencingToken(),
+		HeartbeatTime: now,
+		ExpireTime:    now,
+	}
+
+	return l.putHeld(ctx, lease, released)
+}
+
+// putHeld writes next if the stored lease is still held by the owner and
+// fencing token of lease.
+func (l *Locker) putHeld(ctx context.Context, lease, next *Lease) error {
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(l.table),
+		ConditionExpression: aws.String("#owner = :owner AND #token = :token"),
+		ExpressionAttributeNames: map[string]string{
+			"#owner": "owner",
+			"#token": "fencingToken",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":owner": &types.AttributeValueMemberS{Value: lease.GetOwner()},
+			":token": fencingTokenValue(lease.GetFencingToken()),
+		},
+	}
+
+	if err := l.put(ctx, input, next); err != nil {
+		if isConditionalCheckFailed(err) {
+			return fmt.Errorf("%w: %q", ErrLockLost, lease.GetName())
+		}
+		return err
+	}
+
+	return nil
+}
+
+// put marshals lease into the input's item and writes it.
+func (l *Locker) put(ctx context.Context, input *dynamodb.PutItemInput, lease *Lease) error {
+	item, err := dynabuf.MarshalMap(lease)
+	if err != nil {
+		return fmt.Errorf("lock: failed to encode lease %q: %w", lease.GetName(), err)
+	}
+	input.Item = item
+
+	if _, err := dynabuf.Do(dynabuf.ContextWithMessage(ctx, lease), l.cfg, l.client.PutItem, input); err != nil {
+		if isConditionalCheckFailed(err) {
+			return err
+		}
+		return fmt.Errorf("lock: failed to write lease %q: %w", lease.GetName(), err)
+	}
+
+	return nil
+}
+
+// unchangedCondition returns a condition expression that only holds if
+// the stored lease still has the fencing token and expiry of current.
+func unchangedCondition(current *Lease) (*string, map[string]string, map[string]types.AttributeValue, error) {
+	item, err := dynabuf.MarshalMap(current)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("lock: failed to encode lease %q: %w", current.GetName(), err)
+	}
+
+	names := map[string]string{
+		"#token":  "fencingToken",
+		"#expire": "expireTime",
+	}
+	values := map[string]types.AttributeValue{
+		":token": fencingTokenValue(current.GetFencingToken()),
+	}
+
+	expire, ok := item["expireTime"]
+	if !ok {
+		return aws.String("#token = :token AND attribute_not_exists(#expire)"), names, values, nil
+	}
+	values[":expire"] = expire
+	return aws.String("#token = :token AND #expire = :expire"), names, values, nil
+}
+
+// fencingTokenValue returns the attribute value of a fencing token as
+// written by [dynabuf.Marshal], which follows protojson in encoding 64-bit
+// integers as strings.
+func fencingTokenValue(token uint64) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: strconv.FormatUint(token, 10)}
+}
+
+func leaseKey(name string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"name": &types.AttributeValueMemberS{Value: name},
+	}
+}
+
+func isConditionalCheckFailed(err error) bool {
+	var ccf *types.ConditionalCheckFailedException
+	return errors.As(err, &ccf)
+}
@@ -0,0 +1,190 @@
+package lock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/lock"
+	"github.com/shoenig/test/must"
+)
+
+func newLocker(t *testing.T, ttl time.Duration, opts ...dynabuf.ConfigOption) *lock.Locker {
+	t.Helper()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("locks"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("name"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	return lock.New(client, "locks", ttl, opts...)
+}
+
+func TestLocker(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		check func(t *testing.T, locker *lock.Locker)
+	}{
+		{
+			name: "acquire unheld lock",
+			ttl:  time.Minute,
+			check: func(t *testing.T, locker *lock.Locker) {
+				lease, err := locker.Acquire(ctx, "job", "alice")
+				must.NoError(t, err)
+				must.Eq(t, "alice", lease.GetOwner())
+				must.Eq(t, uint64(1), lease.GetFencingToken())
+
+				stored, err := locker.Get(ctx, "job")
+				must.NoError(t, err)
+				must.Eq(t, lease.GetFencingToken(), stored.GetFencingToken())
+				must.Eq(t, lease.GetOwner(), stored.GetOwner())
+			},
+		},
+		{
+			name: "acquire held lock",
+			ttl:  time.Minute,
+			check: func(t *testing.T, locker *lock.Locker) {
+				_, err := locker.Acquire(ctx, "job", "alice")
+				must.NoError(t, err)
+
+				_, err = locker.Acquire(ctx, "job", "bob")
+				must.ErrorIs(t, err, lock.ErrLockHeld)
+			},
+		},
+		{
+			name: "acquire released lock",
+			ttl:  time.Minute,
+			check: func(t *testing.T, locker *lock.Locker) {
+				lease, err := locker.Acquire(ctx, "job", "alice")
+				must.NoError(t, err)
+				must.NoError(t, locker.Release(ctx, lease))
+
+				next, err := locker.Acquire(ctx, "job", "bob")
+				must.NoError(t, err)
+				must.Eq(t, "bob", next.GetOwner())
+				must.Eq(t, uint64(2), next.GetFencingToken())
+			},
+		},
+		{
+			name: "acquire expired lock",
+			ttl:  10 * time.Millisecond,
+			check: func(t *testing.T, locker *lock.Locker) {
+				lease, err := locker.Acquire(ctx, "job", "alice")
+				must.NoError(t, err)
+
+				time.Sleep(20 * time.Millisecond)
+
+				next, err := locker.Acquire(ctx, "job", "bob")
+				must.NoError(t, err)
+				must.Eq(t, uint64(2), next.GetFencingToken())
+
+				err = locker.Heartbeat(ctx, lease)
+				must.ErrorIs(t, err, lock.ErrLockLost)
+
+				err = locker.Release(ctx, lease)
+				must.ErrorIs(t, err, lock.ErrLockLost)
+			},
+		},
+		{
+			name: "heartbeat extends lease",
+			ttl:  time.Minute,
+			check: func(t *testing.T, locker *lock.Locker) {
+				lease, err := locker.Acquire(ctx, "job", "alice")
+				must.NoError(t, err)
+
+				expiry := lease.GetExpireTime().AsTime()
+				time.Sleep(time.Millisecond)
+
+				must.NoError(t, locker.Heartbeat(ctx, lease))
+				must.True(t, lease.GetExpireTime().AsTime().After(expiry))
+
+				stored, err := locker.Get(ctx, "job")
+				must.NoError(t, err)
+				must.Eq(t, lease.GetExpireTime().AsTime(), stored.GetExpireTime().AsTime())
+			},
+		},
+		{
+			name: "get unknown lock",
+			ttl:  time.Minute,
+			check: func(t *testing.T, locker *lock.Locker) {
+				lease, err := locker.Get(ctx, "job")
+				must.NoError(t, err)
+				must.Nil(t, lease)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.check(t, newLocker(t, test.ttl))
+		})
+	}
+}
+
+func TestLockerClock(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	locker := newLocker(t, time.Minute, dynabuf.WithClock(func() time.Time { return now }))
+
+	lease, err := locker.Acquire(ctx, "job", "alice")
+	must.NoError(t, err)
+	must.Eq(t, now, lease.GetHeartbeatTime().AsTime())
+	must.Eq(t, now.Add(time.Minute), lease.GetExpireTime().AsTime())
+
+	_, err = locker.Acquire(ctx, "job", "bob")
+	must.ErrorIs(t, err, lock.ErrLockHeld)
+
+	// The lease expires once the clock passes its expiry.
+	now = now.Add(2 * time.Minute)
+	lease, err = locker.Acquire(ctx, "job", "bob")
+	must.NoError(t, err)
+	must.Eq(t, "bob", lease.GetOwner())
+	must.Eq(t, now, lease.GetHeartbeatTime().AsTime())
+}
+
+func TestLockerLeaseWithoutExpiry(t *testing.T) {
+	ctx := context.Background()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("locks"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("name"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	// A lease released by a writer leaving out its expiry can be acquired.
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String("locks"),
+		Item: map[string]types.AttributeValue{
+			"name":         &types.AttributeValueMemberS{Value: "job"},
+			"fencingToken": &types.AttributeValueMemberS{Value: "3"},
+		},
+	})
+	must.NoError(t, err)
+
+	locker := lock.New(client, "locks", time.Minute)
+	lease, err := locker.Acquire(ctx, "job", "alice")
+	must.NoError(t, err)
+	must.Eq(t, uint64(4), lease.GetFencingToken())
+}
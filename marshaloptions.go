@@ -0,0 +1,288 @@
+package dynabuf
+
+import (
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// MarshalOptions configures [Marshal] with fields rather than [Option]
+// values, for callers keeping their configuration in a struct, such as one
+// loaded from flags or a configuration file. The zero value marshals as
+// [Marshal] does without options, and each option of marshaling has a
+// field.
+//
+// # Example
+//
+//	codec := dynabuf.MarshalOptions{
+//	  FloatFormat:    'f',
+//	  FloatPrecision: 2,
+//	  CheckLimits:    true,
+//	}
+//
+//	item, err := codec.Marshal(product)
+type MarshalOptions struct {
+	// WriteAliases writes the fields annotated with aliases under their
+	// aliases too, as [WithWriteAliases] does.
+	WriteAliases bool
+
+	// UseProtoNames names the attributes of fields after their names in the
+	// .proto file, as [WithProtoNames] does.
+	UseProtoNames bool
+
+	// FloatFormat and FloatPrecision format float and double fields, as
+	// [WithFloatFormat] does, if FloatFormat is set.
+	FloatFormat    byte
+	FloatPrecision int
+
+	// EmptyMessages is how fields set to empty messages are stored, as set
+	// with [WithEmptyMessages].
+	EmptyMessages EmptyMessagePolicy
+
+	// Conflicts is how the attributes the item written into by
+	// [MarshalInto] already has are handled, as set with [WithConflicts].
+	Conflicts ConflictPolicy
+
+	// StructKeys is how the keys of structs stored as items which are
+	// reserved attribute names are handled, as set with [WithStructKeys].
+	StructKeys StructKeyPolicy
+
+	// KeyAttributes are the names of the key attributes of the item, as
+	// set with [WithKeyAttributes].
+	KeyAttributes []string
+
+	// NormalizeKeys normalizes the string key attributes, also folding
+	// their case if FoldKeys is set, as [WithNormalizedKeys] does.
+	NormalizeKeys bool
+	FoldKeys      bool
+
+	// Metadata writes the metadata of items, with the version of their
+	// schema SchemaVersion, as [WithMetadata] does.
+	Metadata      bool
+	SchemaVersion string
+
+	// MapItems marshals maps of messages into an item per message, storing
+	// their keys in the MapKeyAttribute attribute if set, as
+	// [WithMapItems] does.
+	MapItems        bool
+	MapKeyAttribute string
+
+	// CheckLimits checks the item against the limits of DynamoDB, as
+	// [WithLimitChecks] does.
+	CheckLimits bool
+
+	// Diagnostics, if set, receives the lossy conversions, as set with
+	// [WithDiagnostics].
+	Diagnostics func(Diagnostic)
+
+	// FieldStats, if set, samples the attributes of the items, as set with
+	// [WithFieldStats].
+	FieldStats *FieldStats
+
+	// ProtoJSON, if set, marshals messages into their JSON form, as set
+	// with [WithProtoJSONMarshalOptions].
+	ProtoJSON protojson.MarshalOptions
+
+	// EncoderOptions encode the items, as set with [WithEncoderOptions].
+	EncoderOptions []func(*attributevalue.EncoderOptions)
+
+	// BeforeEncode are called in order with the fields of each message
+	// before they are encoded, as set with [WithBeforeEncode].
+	BeforeEncode []FieldsHook
+
+	// Config, if set, is the configuration whose clock sets the write time
+	// of the metadata, as the [Table.Config] of [BatchPut] does.
+	Config *Config
+}
+
+// Options returns the [Option] values of the fields set, for the helpers
+// accepting options, such as [BatchPut].
+func (m MarshalOptions) Options() []Option {
+	var opts []Option
+	if m.WriteAliases {
+		opts = append(opts, WithWriteAliases(true))
+	}
+	if m.UseProtoNames {
+		opts = append(opts, WithProtoNames(true))
+	}
+	if m.FloatFormat != 0 {
+		opts = append(opts, WithFloatFormat(m.FloatFormat, m.FloatPrecision))
+	}
+	if m.EmptyMessages != EmptyMessageMap {
+		opts = append(opts, WithEmptyMessages(m.EmptyMessages))
+	}
+	if m.Conflicts != ConflictOverwrite {
+		opts = append(opts, WithConflicts(m.Conflicts))
+	}
+	if m.StructKeys != StructKeyError {
+		opts = append(opts, WithStructKeys(m.StructKeys))
+	}
+	if len(m.KeyAttributes) > 0 {
+		opts = append(opts, WithKeyAttributes(m.KeyAttributes...))
+	}
+	if m.NormalizeKeys {
+		opts = append(opts, WithNormalizedKeys(m.FoldKeys))
+	}
+	if m.Metadata {
+		opts = append(opts, WithMetadata(m.SchemaVersion))
+	}
+	if m.MapItems {
+		opts = append(opts, WithMapItems(m.MapKeyAttribute))
+	}
+	if m.CheckLimits {
+		opts = append(opts, WithLimitChecks())
+	}
+	if m.Diagnostics != nil {
+		opts = append(opts, WithDiagnostics(m.Diagnostics))
+	}
+	if m.FieldStats != nil {
+		opts = append(opts, WithFieldStats(m.FieldStats))
+	}
+	if m.ProtoJSON != (protojson.MarshalOptions{}) {
+		opts = append(opts, WithProtoJSONMarshalOptions(m.ProtoJSON))
+	}
+	if len(m.EncoderOptions) > 0 {
+		opts = append(opts, WithEncoderOptions(m.EncoderOptions...))
+	}
+	for _, hook := range m.BeforeEncode {
+		opts = append(opts, WithBeforeEncode(hook))
+	}
+	if m.Config != nil {
+		opts = append(opts, withConfig(m.Config))
+	}
+	return opts
+}
+
+// Codec returns a [Codec] configured by m, which is not changed by later
+// changes to m.
+func (m MarshalOptions) Codec() *Codec {
+	return NewCodec(m.Options()...)
+}
+
+// Marshal is like [Marshal], configured by m.
+func (m MarshalOptions) Marshal(v any) (any, error) {
+	return Marshal(v, m.Options()...)
+}
+
+// UnmarshalOptions configures [Unmarshal] with fields rather than [Option]
+// values, as [MarshalOptions] does for [Marshal]. The zero value unmarshals
+// as [Unmarshal] does without options, and each option of unmarshaling has
+// a field.
+//
+// # Example
+//
+//	codec := dynabuf.UnmarshalOptions{
+//	  DiscardUnknown: true,
+//	  CaseCollisions: dynabuf.CaseCollisionError,
+//	}
+//
+//	err := codec.Unmarshal(out.Item, &user)
+type UnmarshalOptions struct {
+	// DiscardUnknown ignores attributes not matching a field of the
+	// message, as [WithDiscardUnknown] does.
+	DiscardUnknown bool
+
+	// CaseCollisions is how attributes whose names differ only by case are
+	// handled, if set, as with [WithCaseCollisions].
+	CaseCollisions CaseCollisionPolicy
+
+	// EmptyMessages is the policy the fields set to empty messages were
+	// stored with, as set with [WithEmptyMessages].
+	EmptyMessages EmptyMessagePolicy
+
+	// StructKeys is the policy the keys of structs stored as items were
+	// handled with, as set with [WithStructKeys].
+	StructKeys StructKeyPolicy
+
+	// Metadata removes the metadata of items, checking it names the
+	// message, as [WithMetadata] does.
+	Metadata bool
+
+	// MapItems unmarshals items into maps of messages keyed by their
+	// MapKeyAttribute attribute, as [WithMapItems] does.
+	MapItems        bool
+	MapKeyAttribute string
+
+	// FailureMetrics, if set, records the fields failing to unmarshal, as
+	// set with [WithFailureMetrics].
+	FailureMetrics *FailureMetrics
+
+	// Diagnostics, if set, receives the lossy conversions, as set with
+	// [WithDiagnostics].
+	Diagnostics func(Diagnostic)
+
+	// FieldStats, if set, samples the attributes of the items, as set with
+	// [WithFieldStats].
+	FieldStats *FieldStats
+
+	// MessagePool, if set, supplies the messages of slices and streams of
+	// items, as set with [WithMessagePool].
+	MessagePool MessagePool
+
+	// ProtoJSON, if set, unmarshals messages from their JSON form, as set
+	// with [WithProtoJSONUnmarshalOptions].
+	ProtoJSON protojson.UnmarshalOptions
+
+	// DecoderOptions decode the items, as set with [WithDecoderOptions].
+	DecoderOptions []func(*attributevalue.DecoderOptions)
+
+	// AfterDecode are called in order with the fields of each item after
+	// they are decoded, as set with [WithAfterDecode].
+	AfterDecode []FieldsHook
+}
+
+// Options returns the [Option] values of the fields set, for the helpers
+// accepting options, such as [BatchGet].
+func (u UnmarshalOptions) Options() []Option {
+	var opts []Option
+	if u.DiscardUnknown {
+		opts = append(opts, WithDiscardUnknown())
+	}
+	if u.CaseCollisions != 0 {
+		opts = append(opts, WithCaseCollisions(u.CaseCollisions))
+	}
+	if u.EmptyMessages != EmptyMessageMap {
+		opts = append(opts, WithEmptyMessages(u.EmptyMessages))
+	}
+	if u.StructKeys != StructKeyError {
+		opts = append(opts, WithStructKeys(u.StructKeys))
+	}
+	if u.Metadata {
+		opts = append(opts, WithMetadata(""))
+	}
+	if u.MapItems {
+		opts = append(opts, WithMapItems(u.MapKeyAttribute))
+	}
+	if u.FailureMetrics != nil {
+		opts = append(opts, WithFailureMetrics(u.FailureMetrics))
+	}
+	if u.Diagnostics != nil {
+		opts = append(opts, WithDiagnostics(u.Diagnostics))
+	}
+	if u.FieldStats != nil {
+		opts = append(opts, WithFieldStats(u.FieldStats))
+	}
+	if u.MessagePool != nil {
+		opts = append(opts, WithMessagePool(u.MessagePool))
+	}
+	if u.ProtoJSON != (protojson.UnmarshalOptions{}) {
+		opts = append(opts, WithProtoJSONUnmarshalOptions(u.ProtoJSON))
+	}
+	if len(u.DecoderOptions) > 0 {
+		opts = append(opts, WithDecoderOptions(u.DecoderOptions...))
+	}
+	for _, hook := range u.AfterDecode {
+		opts = append(opts, WithAfterDecode(hook))
+	}
+	return opts
+}
+
+// Codec returns a [Codec] configured by u, which is not changed by later
+// changes to u.
+func (u UnmarshalOptions) Codec() *Codec {
+	return NewCodec(u.Options()...)
+}
+
+// Unmarshal is like [Unmarshal], configured by u.
+func (u UnmarshalOptions) Unmarshal(av any, v any) error {
+	return Unmarshal(av, v, u.Options()...)
+}
@@ -0,0 +1,62 @@
+package dynabuf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMarshalOptions(t *testing.T) {
+	user := &testpb.User{Id: "1", Name: "Alice", Address: &testpb.Address{}}
+
+	// The zero value marshals as Marshal does.
+	want, err := dynabuf.Marshal(user)
+	must.NoError(t, err)
+	got, err := dynabuf.MarshalOptions{}.Marshal(user)
+	must.NoError(t, err)
+	must.Eq(t, want, got)
+
+	codec := dynabuf.MarshalOptions{EmptyMessages: dynabuf.EmptyMessageNull}
+	av, err := codec.Marshal(user)
+	must.NoError(t, err)
+	item := av.(map[string]types.AttributeValue)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberNULL{Value: true}, item["address"])
+
+	var decoded testpb.User
+	must.NoError(t, dynabuf.UnmarshalOptions{EmptyMessages: dynabuf.EmptyMessageNull}.Unmarshal(item, &decoded))
+	must.True(t, proto.Equal(user, &decoded))
+}
+
+func TestUnmarshalOptions(t *testing.T) {
+	item := dynabuf.MustMarshalItem(&testpb.User{Id: "1"})
+	item["gsi1pk"] = &types.AttributeValueMemberS{Value: "USER#1"}
+
+	var user testpb.User
+	must.ErrorIs(t, dynabuf.UnmarshalOptions{}.Unmarshal(item, &user), dynabuf.ErrFailedToUnmarshal)
+
+	codec := dynabuf.UnmarshalOptions{DiscardUnknown: true}
+	must.NoError(t, codec.Unmarshal(item, &user))
+	must.Eq(t, "1", user.GetId())
+
+	// The options can be given to helpers accepting options.
+	must.SliceLen(t, 1, codec.Options())
+}
+
+func TestMarshalOptionsConfig(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cfg := dynabuf.NewConfig(dynabuf.WithClock(func() time.Time { return now }))
+
+	codec := dynabuf.MarshalOptions{Metadata: true, Config: cfg}
+	av, err := dynabuf.Marshal(&testpb.User{Id: "1"}, codec.Options()...)
+	must.NoError(t, err)
+
+	meta, ok, err := dynabuf.ReadMetadata(av.(map[string]types.AttributeValue))
+	must.NoError(t, err)
+	must.True(t, ok)
+	must.True(t, meta.WriteTime.Equal(now))
+}
@@ -0,0 +1,152 @@
+package dynabuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MetadataAttribute is the attribute of the metadata written with items by
+// [WithMetadata].
+const MetadataAttribute = "_meta"
+
+// ErrMetadataMismatch is returned by [Unmarshal] when the metadata of an
+// item names another message than the one it is read into.
+var ErrMetadataMismatch = errors.New("dynabuf: item metadata does not match message")
+
+// Metadata describes how an item was written, for finding out which
+// writers wrote items of a format, such as items left behind by an old
+// deployment. It is stored in the [MetadataAttribute] attribute.
+type Metadata struct {
+	// SchemaVersion is the version of the schema set with [WithMetadata].
+	SchemaVersion string `dynamodbav:"schemaVersion,omitempty"`
+
+	// Message is the full name of the message of the item.
+	Message string `dynamodbav:"message"`
+
+	// Version is the version of the dynabuf module of the writer, or
+	// "(devel)" if unknown.
+	Version string `dynamodbav:"version"`
+
+	// WriteTime is when the item was marshaled, by the clock of the
+	// [Table.Config] of [BatchPut], or of [MarshalOptions.Config], if any.
+	WriteTime time.Time `dynamodbav:"writeTime"`
+}
+
+// WithMetadata writes the [Metadata] of items, with the given version of
+// their schema, in the [MetadataAttribute] attribute when marshaling.
+//
+// When unmarshaling, the option removes the attribute, failing with
+// [ErrMetadataMismatch] if it names another message, and the version is
+// ignored. Without it, the attribute is read as any other, so readers of
+// items written with metadata set the option too, or [WithDiscardUnknown].
+// Messages with a field stored under the same name keep the field instead.
+// [ReadMetadata] reads the metadata of an item.
+//
+// # Example
+//
+//	item, err := dynabuf.Marshal(order, dynabuf.WithMetadata("2024-06"))
+//
+//	err = dynabuf.Unmarshal(item, order, dynabuf.WithMetadata(""))
+func WithMetadata(schemaVersion string) Option {
+	return func(o *options) {
+		o.metadata = true
+		o.schemaVersion = schemaVersion
+	}
+}
+
+// ReadMetadata returns the [Metadata] of an item, and whether it has any.
+//
+// # Example
+//
+//	meta, ok, err := dynabuf.ReadMetadata(out.Item)
+//	if ok {
+//	  log.Printf("written by dynabuf %s at %s", meta.Version, meta.WriteTime)
+//	}
+func ReadMetadata(item map[string]types.AttributeValue) (Metadata, bool, error) {
+	var meta Metadata
+	av, ok := item[MetadataAttribute]
+	if !ok {
+		return meta, false, nil
+	}
+	if err := attributevalue.Unmarshal(av, &meta); err != nil {
+		return meta, true, fmt.Errorf("dynabuf: invalid item metadata: %w", err)
+	}
+	return meta, true, nil
+}
+
+// moduleVersion returns the version of the dynabuf module built into the
+// program.
+var moduleVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	if info.Main.Path == "github.com/picatz/dynabuf" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/picatz/dynabuf" {
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "(devel)"
+})
+
+// writeMetadata sets the metadata of an item of a message of type md in
+// its fields.
+func (o *options) writeMetadata(md protoreflect.MessageDescriptor, fields map[string]any) error {
+	if fd := fieldByName(md, MetadataAttribute); fd != nil {
+		return fmt.Errorf("%w: field %q of %s is stored as metadata attribute %q", ErrAttributeCollision, fd.Name(), md.FullName(), MetadataAttribute)
+	}
+	meta := map[string]any{
+		"message":   string(md.FullName()),
+		"version":   moduleVersion(),
+		"writeTime": o.config.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if o.schemaVersion != "" {
+		meta["schemaVersion"] = o.schemaVersion
+	}
+	fields[MetadataAttribute] = meta
+	return nil
+}
+
+// stripMetadata removes the metadata attribute from the stored fields of a
+// message of type md, checking it names the message, if set with
+// [WithMetadata].
+func (o *options) stripMetadata(md protoreflect.MessageDescriptor, data []byte) ([]byte, error) {
+	if !o.metadata || !bytes.Contains(data, []byte(`"`+MetadataAttribute+`"`)) || fieldByName(md, MetadataAttribute) != nil {
+		return data, nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var fields map[string]any
+	if err := d.Decode(&fields); err != nil {
+		// Values other than objects are left to fail to unmarshal.
+		return data, nil
+	}
+	meta, ok := fields[MetadataAttribute]
+	if !ok {
+		return data, nil
+	}
+	m, _ := meta.(map[string]any)
+	name, _ := m["message"].(string)
+	if name != string(md.FullName()) {
+		return nil, fmt.Errorf("%w: item of %q read into %s", ErrMetadataMismatch, name, md.FullName())
+	}
+	delete(fields, MetadataAttribute)
+	return json.Marshal(fields)
+}
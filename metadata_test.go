@@ -0,0 +1,63 @@
+package dynabuf_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestMetadata(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cfg := dynabuf.NewConfig(dynabuf.WithClock(func() time.Time { return now }))
+
+	user := &testpb.User{Id: "1", Name: "Alice"}
+	av, err := dynabuf.MarshalOptions{Metadata: true, SchemaVersion: "v2", Config: cfg}.Marshal(user)
+	must.NoError(t, err)
+	item := av.(map[string]types.AttributeValue)
+	must.MapContainsKey(t, item, dynabuf.MetadataAttribute)
+
+	meta, ok, err := dynabuf.ReadMetadata(item)
+	must.NoError(t, err)
+	must.True(t, ok)
+	must.Eq(t, "v2", meta.SchemaVersion)
+	must.Eq(t, "dynabuf.test.v1.User", meta.Message)
+	must.NotEq(t, "", meta.Version)
+	must.True(t, meta.WriteTime.Equal(now))
+
+	// The metadata is removed when unmarshaling with the option.
+	var got testpb.User
+	must.NoError(t, dynabuf.Unmarshal(item, &got, dynabuf.WithMetadata("")))
+	must.Eq(t, user, &got, must.Cmp(protocmp.Transform()))
+
+	// Items of another message are rejected.
+	var address testpb.Address
+	err = dynabuf.Unmarshal(item, &address, dynabuf.WithMetadata(""))
+	must.True(t, errors.Is(err, dynabuf.ErrMetadataMismatch))
+
+	// Items without metadata have none.
+	_, ok, err = dynabuf.ReadMetadata(dynabuf.MustMarshalItem(user))
+	must.NoError(t, err)
+	must.False(t, ok)
+}
+
+func TestMetadataDisabled(t *testing.T) {
+	// Attributes of the same name are read as any other without the option.
+	item := map[string]types.AttributeValue{
+		dynabuf.MetadataAttribute: &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"message": &types.AttributeValueMemberS{Value: "example.Other"},
+		}},
+		"name": &types.AttributeValueMemberS{Value: "Alice"},
+	}
+
+	var doc structpb.Struct
+	must.NoError(t, dynabuf.Unmarshal(item, &doc))
+	must.Eq(t, "example.Other", doc.GetFields()[dynabuf.MetadataAttribute].GetStructValue().GetFields()["message"].GetStringValue())
+	must.Eq(t, "Alice", doc.GetFields()["name"].GetStringValue())
+}
@@ -0,0 +1,210 @@
+package dynabuf
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FailureMetrics counts the fields that [Unmarshal] failed to convert from
+// DynamoDB attribute values to protobuf, grouped by message type and field
+// path. Rising counts for a field usually mean items in a table are drifting
+// away from the message's schema, such as a string attribute written where a
+// number is expected.
+//
+// FailureMetrics implements [expvar.Var], so it can be published as is:
+//
+//	var failures dynabuf.FailureMetrics
+//
+//	expvar.Publish("dynabuf_unmarshal_failures", &failures)
+//
+//	err := dynabuf.Unmarshal(av, &msg, dynabuf.WithFailureMetrics(&failures))
+//
+// Other metrics systems, such as Prometheus, can export the counts with
+// [FailureMetrics.Each] from a custom collector.
+//
+// The zero value is ready to use, and a FailureMetrics is safe for
+// concurrent use.
+type FailureMetrics struct {
+	mu     sync.Mutex
+	counts map[failureKey]uint64
+}
+
+// failureKey identifies a field of a message type.
+type failureKey struct {
+	message string
+	path    string
+}
+
+// Count returns the number of failures recorded for the field path of the
+// given message type, such as "example.User" and "address.zip_code".
+func (m *FailureMetrics) Count(message, path string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.counts[failureKey{message: message, path: path}]
+}
+
+// Each calls fn with the count of every field path with recorded failures,
+// ordered by message type and then field path.
+func (m *FailureMetrics) Each(fn func(message, path string, count uint64)) {
+	m.mu.Lock()
+	keys := make([]failureKey, 0, len(m.counts))
+	counts := make(map[failureKey]uint64, len(m.counts))
+	for k, v := range m.counts {
+		keys = append(keys, k)
+		counts[k] = v
+	}
+	m.mu.Unlock()
+
+	slices.SortFunc(keys, func(a, b failureKey) int {
+		return cmp.Or(cmp.Compare(a.message, b.message), cmp.Compare(a.path, b.path))
+	})
+
+	for _, k := range keys {
+		fn(k.message, k.path, counts[k])
+	}
+}
+
+// String returns the counts as a JSON object keyed by message type, then by
+// field path, implementing [expvar.Var].
+func (m *FailureMetrics) String() string {
+	out := map[string]map[string]uint64{}
+	m.Each(func(message, path string, count uint64) {
+		if out[message] == nil {
+			out[message] = map[string]uint64{}
+		}
+		out[message][path] = count
+	})
+
+	b, _ := json.Marshal(out)
+	return string(b)
+}
+
+// record increments the count of each field of msg that fails to
+// unmarshal from the intermediary JSON data.
+func (m *FailureMetrics) record(msg proto.Message, data []byte) {
+	var intermediary map[string]any
+	if err := json.Unmarshal(data, &intermediary); err != nil {
+		return
+	}
+
+	paths := failedFieldPaths(msg.ProtoReflect(), intermediary, "")
+	if len(paths) == 0 {
+		return
+	}
+
+	message := string(msg.ProtoReflect().Descriptor().FullName())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counts == nil {
+		m.counts = map[failureKey]uint64{}
+	}
+	for _, path := range paths {
+		m.counts[failureKey{message: message, path: path}]++
+	}
+}
+
+// failedFieldPaths returns the paths of the fields in the intermediary map
+// which fail to unmarshal into the given message type, narrowing down into
+// nested messages to find the offending fields. Paths use the proto field
+// names, and attributes which don't match any field use their own name.
+func failedFieldPaths(msg protoreflect.Message, intermediary map[string]any, prefix string) []string {
+	fields := msg.Descriptor().Fields()
+
+	var paths []string
+	for _, key := range slices.Sorted(maps.Keys(intermediary)) {
+		value := intermediary[key]
+
+		b, err := json.Marshal(map[string]any{key: value})
+		if err != nil {
+			continue
+		}
+		if protojson.Unmarshal(b, msg.New().Interface()) == nil {
+			continue
+		}
+
+		fd := fields.ByJSONName(key)
+		if fd == nil {
+			fd = fields.ByName(protoreflect.Name(key))
+		}
+		if fd == nil {
+			paths = append(paths, prefix+key)
+			continue
+		}
+
+		path := prefix + string(fd.Name())
+
+		// Narrow down into nested messages, and the messages of map
+		// values, except for well-known types, which have their own
+		// special JSON representations.
+		md := fd.Message()
+		if fd.IsMap() {
+			md = fd.MapValue().Message()
+		}
+		if md == nil || strings.HasPrefix(string(md.FullName()), "google.protobuf.") {
+			paths = append(paths, path)
+			continue
+		}
+
+		var nested []map[string]any
+		switch v := value.(type) {
+		case map[string]any:
+			switch {
+			case fd.IsMap():
+				for _, key := range slices.Sorted(maps.Keys(v)) {
+					if m, ok := v[key].(map[string]any); ok {
+						nested = append(nested, m)
+					}
+				}
+			case !fd.IsList():
+				nested = append(nested, v)
+			}
+		case []any:
+			if fd.IsList() {
+				for _, elem := range v {
+					if m, ok := elem.(map[string]any); ok {
+						nested = append(nested, m)
+					}
+				}
+			}
+		}
+
+		elem := msg.NewField(fd)
+		var nestedMsg protoreflect.Message
+		switch {
+		case fd.IsMap():
+			nestedMsg = elem.Map().NewValue().Message()
+		case fd.IsList():
+			nestedMsg = elem.List().NewElement().Message()
+		default:
+			nestedMsg = elem.Message()
+		}
+
+		var nestedPaths []string
+		for _, n := range nested {
+			for _, p := range failedFieldPaths(nestedMsg, n, path+".") {
+				if !slices.Contains(nestedPaths, p) {
+					nestedPaths = append(nestedPaths, p)
+				}
+			}
+		}
+
+		if len(nestedPaths) == 0 {
+			paths = append(paths, path)
+		} else {
+			paths = append(paths, nestedPaths...)
+		}
+	}
+
+	return paths
+}
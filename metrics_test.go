@@ -0,0 +1,140 @@
+package dynabuf_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestFailureMetrics(t *testing.T) {
+	tests := []struct {
+		name  string
+		input any
+		check func(t *testing.T, metrics *dynabuf.FailureMetrics, err error)
+	}{
+		{
+			name: "valid item",
+			input: map[string]types.AttributeValue{
+				"id":  &types.AttributeValueMemberS{Value: "1"},
+				"age": &types.AttributeValueMemberN{Value: "42"},
+			},
+			check: func(t *testing.T, metrics *dynabuf.FailureMetrics, err error) {
+				must.NoError(t, err)
+				must.Eq(t, "{}", metrics.String())
+			},
+		},
+		{
+			name: "top level field",
+			input: map[string]types.AttributeValue{
+				"id":  &types.AttributeValueMemberS{Value: "1"},
+				"age": &types.AttributeValueMemberS{Value: "forty-two"},
+			},
+			check: func(t *testing.T, metrics *dynabuf.FailureMetrics, err error) {
+				must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+				must.Eq(t, 1, metrics.Count("dynabuf.test.v1.User", "age"))
+				must.Eq(t, 0, metrics.Count("dynabuf.test.v1.User", "id"))
+			},
+		},
+		{
+			name: "nested and repeated fields",
+			input: map[string]types.AttributeValue{
+				"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"street":  &types.AttributeValueMemberS{Value: "Main St"},
+					"zipCode": &types.AttributeValueMemberBOOL{Value: true},
+				}},
+				"previousAddresses": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+					&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+						"street": &types.AttributeValueMemberN{Value: "1"},
+					}},
+				}},
+				"createTime": &types.AttributeValueMemberS{Value: "yesterday"},
+			},
+			check: func(t *testing.T, metrics *dynabuf.FailureMetrics, err error) {
+				must.Error(t, err)
+				must.Eq(t, 1, metrics.Count("dynabuf.test.v1.User", "address.zip_code"))
+				must.Eq(t, 1, metrics.Count("dynabuf.test.v1.User", "previous_addresses.street"))
+				must.Eq(t, 1, metrics.Count("dynabuf.test.v1.User", "create_time"))
+			},
+		},
+		{
+			name: "unknown attribute",
+			input: map[string]types.AttributeValue{
+				"nickname": &types.AttributeValueMemberS{Value: "bob"},
+			},
+			check: func(t *testing.T, metrics *dynabuf.FailureMetrics, err error) {
+				must.Error(t, err)
+				must.Eq(t, 1, metrics.Count("dynabuf.test.v1.User", "nickname"))
+			},
+		},
+		{
+			name: "list of items",
+			input: []map[string]types.AttributeValue{
+				{"age": &types.AttributeValueMemberS{Value: "one"}},
+				{"age": &types.AttributeValueMemberN{Value: "2"}},
+			},
+			check: func(t *testing.T, metrics *dynabuf.FailureMetrics, err error) {
+				must.Error(t, err)
+				must.Eq(t, 1, metrics.Count("dynabuf.test.v1.User", "age"))
+
+				var published map[string]map[string]uint64
+				must.NoError(t, json.Unmarshal([]byte(metrics.String()), &published))
+				must.Eq(t, map[string]map[string]uint64{
+					"dynabuf.test.v1.User": {"age": 1},
+				}, published)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				metrics dynabuf.FailureMetrics
+				err     error
+			)
+
+			switch test.input.(type) {
+			case []map[string]types.AttributeValue:
+				var users []*testpb.User
+				err = dynabuf.Unmarshal(test.input, &users, dynabuf.WithFailureMetrics(&metrics))
+			default:
+				err = dynabuf.Unmarshal(test.input, &testpb.User{}, dynabuf.WithFailureMetrics(&metrics))
+			}
+
+			test.check(t, &metrics, err)
+		})
+	}
+}
+
+func TestFailureMetricsMapValues(t *testing.T) {
+	var metrics dynabuf.FailureMetrics
+
+	err := dynabuf.Unmarshal(map[string]types.AttributeValue{
+		"shelves": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"top": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"labels": &types.AttributeValueMemberS{Value: "a"},
+			}},
+			"bottom": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"entries": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"e": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+						"log": &types.AttributeValueMemberN{Value: "1"},
+					}},
+				}},
+			}},
+		}},
+		"products": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"one": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+		}},
+	}, &testpb.Catalog{}, dynabuf.WithFailureMetrics(&metrics))
+	must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+
+	// The fields of the messages of map values are narrowed down into, and
+	// invalid keys are counted against the map.
+	must.Eq(t, 1, metrics.Count("dynabuf.test.v1.Catalog", "shelves.labels"))
+	must.Eq(t, 1, metrics.Count("dynabuf.test.v1.Catalog", "shelves.entries.log"))
+	must.Eq(t, 1, metrics.Count("dynabuf.test.v1.Catalog", "products"))
+	must.Eq(t, 0, metrics.Count("dynabuf.test.v1.Catalog", "shelves"))
+}
@@ -0,0 +1,181 @@
+// Package mongodb provides dynabuf backends encoding messages as MongoDB
+// documents, in BSON or Extended JSON, with the same field mapping as
+// items stored in DynamoDB, so one message definition drives both stores
+// when data is mirrored between them.
+//
+// Fields are named by their JSON names, and hold the values of the JSON
+// form of messages, as in DynamoDB: enums are stored by name, timestamps
+// as RFC 3339 strings, and 64-bit integers as strings. Whole numbers are
+// stored as 32-bit or 64-bit integers, and other numbers as doubles,
+// except the numbers of decimal fields and formatted floats, which are
+// stored as Decimal128 values to keep their digits.
+//
+// Documents written by other MongoDB clients decode with object IDs as
+// their hex strings and dates as RFC 3339 strings, so they can be decoded
+// into string and timestamp fields. The _id field MongoDB adds to every
+// document must be declared by messages, with json_name = "_id", or be
+// discarded with [dynabuf.WithDiscardUnknown].
+//
+// # Example
+//
+//	doc, _ := dynabuf.MarshalTo(mongodb.BSON, &example.User{Id: "123", Name: "Alice"})
+//
+//	_, _ = collection.InsertOne(ctx, doc)
+//
+// It is a separate module, github.com/picatz/dynabuf/mongodb, so that
+// programs only storing messages in DynamoDB do not depend on the MongoDB
+// driver.
+package mongodb
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/picatz/dynabuf"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// BSON encodes messages as BSON documents.
+	BSON dynabuf.Backend[bson.Raw] = bsonBackend{}
+
+	// ExtendedJSON encodes messages as canonical MongoDB Extended JSON
+	// documents, which keep the BSON types of their values, and decodes
+	// both canonical and relaxed documents.
+	ExtendedJSON dynabuf.Backend[[]byte] = extendedJSONBackend{}
+)
+
+type bsonBackend struct{}
+
+func (bsonBackend) EncodeItem(fields map[string]any) (bson.Raw, error) {
+	b, err := bson.Marshal(document(fields))
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to encode BSON: %w", err)
+	}
+	return b, nil
+}
+
+func (bsonBackend) DecodeItem(item bson.Raw) (map[string]any, error) {
+	var doc bson.D
+	if err := bson.Unmarshal(item, &doc); err != nil {
+		return nil, fmt.Errorf("mongodb: failed to decode BSON: %w", err)
+	}
+	return fields(doc)
+}
+
+type extendedJSONBackend struct{}
+
+func (extendedJSONBackend) EncodeItem(fields map[string]any) ([]byte, error) {
+	b, err := bson.MarshalExtJSON(document(fields), true, false)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to encode Extended JSON: %w", err)
+	}
+	return b, nil
+}
+
+func (extendedJSONBackend) DecodeItem(item []byte) (map[string]any, error) {
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON(item, false, &doc); err != nil {
+		return nil, fmt.Errorf("mongodb: failed to decode Extended JSON: %w", err)
+	}
+	return fields(doc)
+}
+
+// document returns the fields of a message as a document, with its fields
+// sorted by name, so documents of equal messages are equal.
+func document(fields map[string]any) bson.D {
+	doc := make(bson.D, 0, len(fields))
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		doc = append(doc, bson.E{Key: name, Value: value(fields[name])})
+	}
+	return doc
+}
+
+// value returns the BSON value of a JSON value.
+func value(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return document(v)
+	case []any:
+		a := make(bson.A, len(v))
+		for i, elem := range v {
+			a[i] = value(elem)
+		}
+		return a
+	case json.Number:
+		if d, err := primitive.ParseDecimal128(string(v)); err == nil {
+			return d
+		}
+		f, _ := v.Float64()
+		return f
+	case float64:
+		switch {
+		case v != math.Trunc(v) || math.Abs(v) > 1<<53:
+			return v
+		case v >= math.MinInt32 && v <= math.MaxInt32:
+			return int32(v)
+		default:
+			return int64(v)
+		}
+	}
+	return v
+}
+
+// fields returns the fields of a message stored as a document.
+func fields(doc bson.D) (map[string]any, error) {
+	m := make(map[string]any, len(doc))
+	for _, e := range doc {
+		v, err := jsonValue(e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("mongodb: field %q: %w", e.Key, err)
+		}
+		m[e.Key] = v
+	}
+	return m, nil
+}
+
+// jsonValue returns the JSON value of a BSON value.
+func jsonValue(v any) (any, error) {
+	switch v := v.(type) {
+	case nil, string, bool, float64:
+		return v, nil
+	case primitive.ObjectID:
+		return v.Hex(), nil
+	case primitive.DateTime:
+		return v.Time().UTC().Format(time.RFC3339Nano), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		if v > 1<<53 || v < -(1<<53) {
+			return fmt.Sprint(v), nil
+		}
+		return float64(v), nil
+	case primitive.Decimal128:
+		return json.Number(v.String()), nil
+	case bson.D:
+		m := make(map[string]any, len(v))
+		for _, e := range v {
+			elem, err := jsonValue(e.Value)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", e.Key, err)
+			}
+			m[e.Key] = elem
+		}
+		return m, nil
+	case bson.A:
+		a := make([]any, len(v))
+		for i, elem := range v {
+			var err error
+			if a[i], err = jsonValue(elem); err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		return a, nil
+	}
+	return nil, fmt.Errorf("unsupported BSON value of type %T", v)
+}
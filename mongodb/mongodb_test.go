@@ -0,0 +1,120 @@
+package mongodb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/picatz/dynabuf/mongodb"
+	"github.com/shoenig/test/must"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var user = &testpb.User{
+	Id:                "123",
+	Name:              "Alice",
+	Age:               30,
+	Address:           &testpb.Address{Street: "Main St", ZipCode: 12345},
+	PreviousAddresses: []*testpb.Address{{Street: "Elm St"}, {ZipCode: 1}},
+	CreateTime:        timestamppb.New(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+}
+
+func TestBSON(t *testing.T) {
+	doc, err := dynabuf.MarshalTo(mongodb.BSON, user)
+	must.NoError(t, err)
+
+	var d bson.D
+	must.NoError(t, bson.Unmarshal(doc, &d))
+	must.Eq(t, bson.D{
+		{Key: "address", Value: bson.D{{Key: "street", Value: "Main St"}, {Key: "zipCode", Value: int32(12345)}}},
+		{Key: "age", Value: int32(30)},
+		{Key: "createTime", Value: "2024-03-01T00:00:00Z"},
+		{Key: "id", Value: "123"},
+		{Key: "name", Value: "Alice"},
+		{Key: "previousAddresses", Value: bson.A{
+			bson.D{{Key: "street", Value: "Elm St"}},
+			bson.D{{Key: "zipCode", Value: int32(1)}},
+		}},
+	}, d)
+
+	var got testpb.User
+	must.NoError(t, dynabuf.UnmarshalFrom(mongodb.BSON, doc, &got))
+	must.True(t, proto.Equal(user, &got))
+}
+
+func TestExtendedJSON(t *testing.T) {
+	doc, err := dynabuf.MarshalTo(mongodb.ExtendedJSON, &testpb.Address{Street: "Main St", ZipCode: 12345})
+	must.NoError(t, err)
+	must.Eq(t, `{"street":"Main St","zipCode":{"$numberInt":"12345"}}`, string(doc))
+
+	doc, err = dynabuf.MarshalTo(mongodb.ExtendedJSON, user)
+	must.NoError(t, err)
+
+	var got testpb.User
+	must.NoError(t, dynabuf.UnmarshalFrom(mongodb.ExtendedJSON, doc, &got))
+	must.True(t, proto.Equal(user, &got))
+
+	// Documents written by other MongoDB clients decode too.
+	err = dynabuf.UnmarshalFrom(mongodb.ExtendedJSON, []byte(`{"name": "Bob", "age": {"$numberLong": "40"}}`), &got)
+	must.NoError(t, err)
+	must.True(t, proto.Equal(&testpb.User{Name: "Bob", Age: 40}, &got))
+
+	err = dynabuf.UnmarshalFrom(mongodb.ExtendedJSON, []byte(`{"_id": {"$oid": "5f1d7d7c7d7c7d7c7d7c7d7c"}, "createTime": {"$date": "2024-03-01T00:00:00Z"}}`), &got, dynabuf.WithDiscardUnknown())
+	must.NoError(t, err)
+	must.True(t, proto.Equal(&testpb.User{CreateTime: user.CreateTime}, &got))
+
+	err = dynabuf.UnmarshalFrom(mongodb.ExtendedJSON, []byte(`{"name": {"$regularExpression": {"pattern": "a", "options": ""}}}`), &got)
+	must.ErrorContains(t, err, `mongodb: field "name": unsupported BSON value of type primitive.Regex`)
+}
+
+func TestBSONDecimal(t *testing.T) {
+	product := &testpb.Product{Id: "1", Price: "12.30", Discounts: []string{"0.5"}}
+
+	doc, err := dynabuf.MarshalTo(mongodb.BSON, product)
+	must.NoError(t, err)
+
+	price, err := primitive.ParseDecimal128("12.30")
+	must.NoError(t, err)
+	must.Eq[any](t, price, doc.Lookup("price").Decimal128())
+
+	var got testpb.Product
+	must.NoError(t, dynabuf.UnmarshalFrom(mongodb.BSON, doc, &got))
+	must.True(t, proto.Equal(product, &got))
+}
+
+func TestNestedShapes(t *testing.T) {
+	matrix, err := structpb.NewList([]any{[]any{1, 2}, []any{}, []any{"x", []any{true}}})
+	must.NoError(t, err)
+
+	catalog := &testpb.Catalog{
+		Shelves: map[string]*testpb.Catalog_Shelf{
+			"top": {
+				Labels:   []string{"a", ""},
+				Blobs:    [][]byte{[]byte("x"), {}},
+				Products: []*testpb.Product{{Price: "1.10", Weight: 0.1}},
+				Entries:  map[string]*testpb.Entry{"e": {Sequence: -5}},
+			},
+		},
+		Products: map[int64]*testpb.Product{-1: {Price: "12345678901234567890.123"}},
+		Flagged:  map[bool]*testpb.Catalog_Shelf{true: {Labels: []string{"f"}}},
+		Rows:     []*testpb.Catalog_Row{{Cells: []*structpb.ListValue{matrix}}},
+		Matrix:   matrix,
+	}
+
+	doc, err := dynabuf.MarshalTo(mongodb.BSON, catalog)
+	must.NoError(t, err)
+	var got testpb.Catalog
+	must.NoError(t, dynabuf.UnmarshalFrom(mongodb.BSON, doc, &got))
+	must.True(t, proto.Equal(catalog, &got))
+
+	data, err := dynabuf.MarshalTo(mongodb.ExtendedJSON, catalog)
+	must.NoError(t, err)
+	got.Reset()
+	must.NoError(t, dynabuf.UnmarshalFrom(mongodb.ExtendedJSON, data, &got))
+	must.True(t, proto.Equal(catalog, &got))
+}
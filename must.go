@@ -0,0 +1,48 @@
+package dynabuf
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// MustMarshal is like [Marshal] but panics if v cannot be marshaled. It is
+// intended for tests and fixtures whose values are known to be valid.
+func MustMarshal(v any) any {
+	av, err := Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return av
+}
+
+// MustMarshalItem is like [MustMarshal] for a single message, returning its
+// item.
+//
+// # Example
+//
+//	client.PutItem(ctx, &dynamodb.PutItemInput{
+//	  TableName: aws.String("users"),
+//	  Item:      dynabuf.MustMarshalItem(&example.User{Id: "123"}),
+//	})
+func MustMarshalItem(msg proto.Message) map[string]types.AttributeValue {
+	return MustMarshal(msg).(map[string]types.AttributeValue)
+}
+
+// MustUnmarshal is like [Unmarshal] but panics if av cannot be unmarshaled.
+// It is intended for tests and fixtures whose values are known to be valid.
+func MustUnmarshal(av any, v any, opts ...Option) {
+	if err := Unmarshal(av, v, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// MustKey is like [Key] but panics if msg has no value for one of the key
+// attributes. It is intended for tests and fixtures whose values are known
+// to be valid.
+func MustKey(msg proto.Message, names []string, opts ...Option) map[string]types.AttributeValue {
+	key, err := Key(msg, names, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
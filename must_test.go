@@ -0,0 +1,36 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMust(t *testing.T) {
+	user := &testpb.User{Id: "1", Name: "Alice"}
+
+	item := dynabuf.MustMarshalItem(user)
+	must.Eq(t, item, dynabuf.MustMarshal(user).(map[string]types.AttributeValue))
+
+	var got testpb.User
+	dynabuf.MustUnmarshal(item, &got)
+	must.True(t, proto.Equal(user, &got))
+
+	must.MapLen(t, 1, dynabuf.MustKey(user, []string{"id"}))
+
+	mustPanic := func(fn func()) {
+		t.Helper()
+		defer func() {
+			must.NotNil(t, recover())
+		}()
+		fn()
+	}
+
+	mustPanic(func() { dynabuf.MustMarshal("not a message") })
+	mustPanic(func() { dynabuf.MustUnmarshal(item, &testpb.Job{}) })
+	mustPanic(func() { dynabuf.MustKey(user, []string{"age"}) })
+}
@@ -0,0 +1,264 @@
+package dynabuf
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// NegativeCacheConfig configures a [NegativeCache].
+type NegativeCacheConfig struct {
+	// TTL is how long an item found missing is remembered as missing, for
+	// message types not listed in MessageTTLs, or for reads without a
+	// message type noted by [ContextWithMessageType]. Zero disables the
+	// cache for them.
+	TTL time.Duration
+
+	// MessageTTLs overrides TTL for the reads of the listed message types,
+	// such as a longer TTL for keys rarely created, or zero to never cache
+	// a message type.
+	MessageTTLs map[protoreflect.FullName]time.Duration
+
+	// Config, if set, provides the clock the expirations of items found
+	// missing are measured with.
+	Config *Config
+}
+
+// NegativeCacheStats counts the reads and writes seen by a
+// [NegativeCache].
+type NegativeCacheStats struct {
+	// Hits is the number of reads answered from the cache.
+	Hits int `json:"hits"`
+
+	// Coalesced is the number of reads that waited for a concurrent read
+	// of the same key, instead of being sent at the same time.
+	Coalesced int `json:"coalesced"`
+
+	// Stored is the number of items remembered as missing.
+	Stored int `json:"stored"`
+
+	// Invalidated is the number of items forgotten because they were
+	// written.
+	Invalidated int `json:"invalidated"`
+}
+
+// NegativeCache is an [Interceptor] remembering the items GetItem found
+// missing for a short TTL, and answering later reads of them without
+// sending them, which protects a table from read-through stampedes on hot
+// keys that do not exist, such as a cache in front of the table that never
+// fills for them. Concurrent reads of a key are sent once, the others
+// waiting for its response.
+//
+// Writes through the same client, by PutItem, UpdateItem, DeleteItem,
+// BatchWriteItem, and TransactWriteItems, forget the items they write, so
+// a client reads its own writes. Items written by other clients may be
+// reported missing until the TTL expires, and strongly consistent reads are
+// always sent.
+//
+// NegativeCache implements [expvar.Var], so its stats can be published as
+// is. A NegativeCache is safe for concurrent use.
+//
+// # Example
+//
+//	cache := dynabuf.NewNegativeCache(dynabuf.NegativeCacheConfig{
+//	  TTL: time.Second,
+//	  MessageTTLs: map[protoreflect.FullName]time.Duration{
+//	    "example.Session": 0,
+//	  },
+//	})
+//
+//	client := dynamodb.NewFromConfig(cfg, dynabuf.WithInterceptors(cache.Intercept))
+func NewNegativeCache(cfg NegativeCacheConfig) *NegativeCache {
+	return &NegativeCache{
+		cfg:      cfg,
+		missing:  map[string]map[string]absence{},
+		versions: map[string]int{},
+		inflight: map[string]chan struct{}{},
+	}
+}
+
+// NegativeCache remembers missing items. See [NewNegativeCache].
+type NegativeCache struct {
+	cfg NegativeCacheConfig
+
+	mu sync.Mutex
+	// missing holds the items found missing by table, then by key text.
+	missing map[string]map[string]absence
+	// versions counts the writes to each table, so reads sent before a
+	// write finished do not remember items it may have written.
+	versions map[string]int
+	// inflight holds the reads being sent by table and key text, closed
+	// once answered.
+	inflight map[string]chan struct{}
+	stats    NegativeCacheStats
+}
+
+// absence marks an item found missing.
+type absence struct {
+	names   []string
+	expires time.Time
+}
+
+// Intercept answers GetItem calls from the cache, and forgets the items
+// written by other calls. Pass it to [WithInterceptors].
+func (c *NegativeCache) Intercept(ctx context.Context, call *Call, next Invoker) (any, error) {
+	if in, ok := call.Input.(*dynamodb.GetItemInput); ok {
+		return c.get(ctx, call, in, next)
+	}
+
+	writes := writtenItems(call.Input)
+	if len(writes) == 0 {
+		return next(ctx, call)
+	}
+
+	c.forget(writes)
+	out, err := next(ctx, call)
+	c.forget(writes)
+	return out, err
+}
+
+// get answers a GetItem call.
+func (c *NegativeCache) get(ctx context.Context, call *Call, in *dynamodb.GetItemInput, next Invoker) (any, error) {
+	ttl, ok := c.cfg.MessageTTLs[call.MessageType]
+	if !ok {
+		ttl = c.cfg.TTL
+	}
+	if ttl <= 0 || aws.ToBool(in.ConsistentRead) {
+		return next(ctx, call)
+	}
+
+	table := aws.ToString(in.TableName)
+	names := slices.Sorted(maps.Keys(in.Key))
+	key := keyText(in.Key, names)
+	flight := table + "\x00" + key
+
+	c.mu.Lock()
+	for {
+		if a, ok := c.missing[table][key]; ok && c.cfg.Config.Now().Before(a.expires) {
+			c.stats.Hits++
+			c.mu.Unlock()
+			return &dynamodb.GetItemOutput{}, nil
+		}
+		wait, ok := c.inflight[flight]
+		if !ok {
+			break
+		}
+		c.stats.Coalesced++
+		c.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		c.mu.Lock()
+	}
+	done := make(chan struct{})
+	c.inflight[flight] = done
+	version := c.versions[table]
+	c.mu.Unlock()
+
+	out, err := next(ctx, call)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.inflight, flight)
+	close(done)
+
+	if o, ok := out.(*dynamodb.GetItemOutput); ok && err == nil && o.Item == nil && c.versions[table] == version {
+		if c.missing[table] == nil {
+			c.missing[table] = map[string]absence{}
+		}
+		c.missing[table][key] = absence{names: names, expires: c.cfg.Config.Now().Add(ttl)}
+		c.stats.Stored++
+	}
+	return out, err
+}
+
+// forget forgets the written items, as items or keys by table.
+func (c *NegativeCache) forget(writes map[string][]map[string]types.AttributeValue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.cfg.Config.Now()
+	for table, items := range writes {
+		c.versions[table]++
+		for key, a := range c.missing[table] {
+			if now.After(a.expires) {
+				delete(c.missing[table], key)
+				continue
+			}
+			for _, item := range items {
+				if keyText(item, a.names) == key {
+					delete(c.missing[table], key)
+					c.stats.Invalidated++
+					break
+				}
+			}
+		}
+	}
+}
+
+// writtenItems returns the items, or keys of items, written by the input
+// of a write operation, by table.
+func writtenItems(input any) map[string][]map[string]types.AttributeValue {
+	writes := map[string][]map[string]types.AttributeValue{}
+	add := func(table *string, item map[string]types.AttributeValue) {
+		if item != nil {
+			writes[aws.ToString(table)] = append(writes[aws.ToString(table)], item)
+		}
+	}
+
+	switch in := input.(type) {
+	case *dynamodb.PutItemInput:
+		add(in.TableName, in.Item)
+	case *dynamodb.UpdateItemInput:
+		add(in.TableName, in.Key)
+	case *dynamodb.DeleteItemInput:
+		add(in.TableName, in.Key)
+	case *dynamodb.BatchWriteItemInput:
+		for table, requests := range in.RequestItems {
+			for _, r := range requests {
+				if r.PutRequest != nil {
+					add(&table, r.PutRequest.Item)
+				}
+				if r.DeleteRequest != nil {
+					add(&table, r.DeleteRequest.Key)
+				}
+			}
+		}
+	case *dynamodb.TransactWriteItemsInput:
+		for _, item := range in.TransactItems {
+			switch {
+			case item.Put != nil:
+				add(item.Put.TableName, item.Put.Item)
+			case item.Update != nil:
+				add(item.Update.TableName, item.Update.Key)
+			case item.Delete != nil:
+				add(item.Delete.TableName, item.Delete.Key)
+			}
+		}
+	}
+	return writes
+}
+
+// Stats returns the counts so far.
+func (c *NegativeCache) Stats() NegativeCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stats
+}
+
+// String returns the stats as a JSON object, implementing [expvar.Var].
+func (c *NegativeCache) String() string {
+	b, _ := json.Marshal(c.Stats())
+	return string(b)
+}
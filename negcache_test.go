@@ -0,0 +1,134 @@
+package dynabuf_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestNegativeCache(t *testing.T) {
+	var requests int
+
+	cache := dynabuf.NewNegativeCache(dynabuf.NegativeCacheConfig{
+		TTL: time.Hour,
+		MessageTTLs: map[protoreflect.FullName]time.Duration{
+			"dynabuf.test.v1.User":    50 * time.Millisecond,
+			"dynabuf.test.v1.Account": 0,
+		},
+	})
+	client := newTestClient(`{}`, &requests, dynabuf.WithInterceptors(cache.Intercept))
+
+	get := func(ctx context.Context, id string, consistent bool) {
+		t.Helper()
+		out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName:      aws.String("users"),
+			Key:            map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}},
+			ConsistentRead: aws.Bool(consistent),
+		})
+		must.NoError(t, err)
+		must.Nil(t, out.Item)
+	}
+
+	users := dynabuf.ContextWithMessageType(context.Background(), &testpb.User{})
+	accounts := dynabuf.ContextWithMessageType(context.Background(), &testpb.Account{})
+
+	// Missing items are remembered for the TTL of their message type.
+	get(users, "1", false)
+	get(users, "1", false)
+	must.Eq(t, 1, requests)
+	get(users, "2", false)
+	must.Eq(t, 2, requests)
+	time.Sleep(60 * time.Millisecond)
+	get(users, "1", false)
+	must.Eq(t, 3, requests)
+
+	// Message types with a zero TTL, and strongly consistent reads, are
+	// always sent.
+	get(accounts, "1", false)
+	get(accounts, "1", false)
+	get(users, "1", true)
+	must.Eq(t, 6, requests)
+
+	// Reads without a message type use the default TTL.
+	get(context.Background(), "3", false)
+	get(context.Background(), "3", false)
+	must.Eq(t, 7, requests)
+
+	// Writes forget the items they write.
+	item, err := dynabuf.Marshal(&testpb.User{Id: "3", Name: "Alice"})
+	must.NoError(t, err)
+	_, err = client.PutItem(users, &dynamodb.PutItemInput{
+		TableName: aws.String("users"),
+		Item:      item.(map[string]types.AttributeValue),
+	})
+	must.NoError(t, err)
+	get(context.Background(), "3", false)
+	must.Eq(t, 9, requests)
+
+	// Writes to other tables do not.
+	_, err = client.DeleteItem(users, &dynamodb.DeleteItemInput{
+		TableName: aws.String("sessions"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "3"}},
+	})
+	must.NoError(t, err)
+	get(context.Background(), "3", false)
+	must.Eq(t, 10, requests)
+
+	must.Eq(t, dynabuf.NegativeCacheStats{Hits: 3, Stored: 5, Invalidated: 1}, cache.Stats())
+	must.Eq(t, `{"hits":3,"coalesced":0,"stored":5,"invalidated":1}`, cache.String())
+}
+
+func TestNegativeCacheStampede(t *testing.T) {
+	var requests atomic.Int32
+
+	cache := dynabuf.NewNegativeCache(dynabuf.NegativeCacheConfig{TTL: time.Minute})
+	client := dynamodb.New(dynamodb.Options{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+		HTTPClient: httpClientFunc(func(r *http.Request) (*http.Response, error) {
+			requests.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+				Body:       io.NopCloser(strings.NewReader(`{}`)),
+			}, nil
+		}),
+	}, dynabuf.WithInterceptors(cache.Intercept))
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := client.GetItem(context.Background(), &dynamodb.GetItemInput{
+				TableName: aws.String("users"),
+				Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "missing"}},
+			})
+			must.NoError(t, err)
+			must.Nil(t, out.Item)
+		}()
+	}
+	wg.Wait()
+
+	must.Eq(t, 1, requests.Load())
+	stats := cache.Stats()
+	must.Eq(t, 1, stats.Stored)
+	must.Eq(t, 19, stats.Hits)
+	must.Positive(t, stats.Coalesced)
+}
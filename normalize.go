@@ -0,0 +1,71 @@
+package dynabuf
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WithNormalizedKeys normalizes the string values of the key attributes of
+// items to Unicode Normalization Form C (NFC) when marshaling, and folds
+// their case too if fold is true, so keys typed differently by users, such
+// as "é" as one code point or as "e" and a combining accent, which look
+// identical, identify the same item instead of duplicates.
+//
+// The key attributes are those named with [WithKeyAttributes], or else
+// those annotated with key_attributes on the message type, including
+// composite attributes. Values are stored normalized, so messages decoded
+// from items hold the normalized values. Keys built with [Key] given the
+// option are normalized too, and keys built by hand from user input are
+// normalized the same way with [NormalizeKey].
+//
+// # Example
+//
+//	item, _ := dynabuf.Marshal(user, dynabuf.WithNormalizedKeys(true), dynabuf.WithKeyAttributes("email"))
+func WithNormalizedKeys(fold bool) Option {
+	return func(o *options) {
+		o.normalizeKeys, o.foldKeys = true, fold
+	}
+}
+
+// NormalizeKey normalizes the string values of key to Unicode NFC, and
+// folds their case too if fold is true, as [WithNormalizedKeys] does when
+// marshaling, and returns it. Other values are left as is.
+//
+// # Example
+//
+//	key := dynabuf.NormalizeKey(map[string]types.AttributeValue{
+//	  "email": &types.AttributeValueMemberS{Value: r.FormValue("email")},
+//	}, true)
+func NormalizeKey(key map[string]types.AttributeValue, fold bool) map[string]types.AttributeValue {
+	for name, v := range key {
+		if s, ok := v.(*types.AttributeValueMemberS); ok {
+			key[name] = &types.AttributeValueMemberS{Value: normalizeKeyString(s.Value, fold)}
+		}
+	}
+	return key
+}
+
+// normalizeKeyAttributes normalizes the string values of the key attributes of the
+// item of a message of type md, as configured by [WithNormalizedKeys].
+func (o *options) normalizeKeyAttributes(md protoreflect.MessageDescriptor, item map[string]types.AttributeValue) {
+	names := o.keyAttributes
+	if len(names) == 0 {
+		names = keyAttributes(md)
+	}
+	for _, name := range names {
+		if s, ok := item[name].(*types.AttributeValueMemberS); ok {
+			item[name] = &types.AttributeValueMemberS{Value: normalizeKeyString(s.Value, o.foldKeys)}
+		}
+	}
+}
+
+// normalizeKeyString returns s in Unicode NFC, with its case folded first
+// if fold is true, since folding can leave strings denormalized.
+func normalizeKeyString(s string, fold bool) string {
+	if fold {
+		s = cases.Fold().String(s)
+	}
+	return norm.NFC.String(s)
+}
@@ -0,0 +1,48 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestNormalizedKeys(t *testing.T) {
+	// "Caf\u00e9" with a combining accent, rather than "é" as one code point.
+	const decomposed = "Cafe\u0301"
+
+	order := &testpb.Order{Customer: decomposed, Id: decomposed, Note: decomposed}
+
+	// Annotated key attributes are normalized, including composite ones,
+	// and other attributes are left as is.
+	av, err := dynabuf.Marshal(order, dynabuf.WithNormalizedKeys(false))
+	must.NoError(t, err)
+	item := av.(map[string]types.AttributeValue)
+	must.Eq(t, "Caf\u00e9", item["customer"].(*types.AttributeValueMemberS).Value)
+	must.Eq(t, "ORDER###Caf\u00e9", item["sk"].(*types.AttributeValueMemberS).Value)
+	must.Eq(t, decomposed, item["note"].(*types.AttributeValueMemberS).Value)
+
+	av, err = dynabuf.Marshal(order, dynabuf.WithNormalizedKeys(true))
+	must.NoError(t, err)
+	item = av.(map[string]types.AttributeValue)
+	must.Eq(t, "caf\u00e9", item["customer"].(*types.AttributeValueMemberS).Value)
+	must.Eq(t, "order###caf\u00e9", item["sk"].(*types.AttributeValueMemberS).Value)
+
+	// Key attributes named with WithKeyAttributes are normalized instead.
+	user := &testpb.User{Id: decomposed, Name: decomposed}
+	av, err = dynabuf.Marshal(user, dynabuf.WithNormalizedKeys(true), dynabuf.WithKeyAttributes("id"))
+	must.NoError(t, err)
+	item = av.(map[string]types.AttributeValue)
+	must.Eq(t, "caf\u00e9", item["id"].(*types.AttributeValueMemberS).Value)
+	must.Eq(t, decomposed, item["name"].(*types.AttributeValueMemberS).Value)
+
+	// Keys built by hand match the keys of items.
+	key := dynabuf.NormalizeKey(map[string]types.AttributeValue{
+		"id":    &types.AttributeValueMemberS{Value: "CAF\u00c9"},
+		"count": &types.AttributeValueMemberN{Value: "1"},
+	}, true)
+	must.Eq(t, item["id"], key["id"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberN{Value: "1"}, key["count"])
+}
@@ -0,0 +1,105 @@
+package dynabuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WithFloatFormat formats the float and double fields of messages stored by
+// [Marshal] as strconv.FormatFloat does with the format and precision,
+// instead of the shortest representation that round-trips to the same
+// value, which is the default.
+//
+// The format is one of 'e', 'E', 'f', 'g', or 'G', and other formats are
+// ignored. A precision of -1 keeps the shortest representation, so it only
+// changes the notation. DynamoDB numbers, like IEEE 754 decimal128 values,
+// hold up to 38 and 34 significant digits respectively, so precisions
+// leading to more digits fail to store. NaN and infinities are stored as
+// strings whatever the format.
+//
+// # Example
+//
+//	// Store prices with exactly two decimal places, such as "9.90".
+//	item, _ := dynabuf.Marshal(product, dynabuf.WithFloatFormat('f', 2))
+func WithFloatFormat(format byte, prec int) Option {
+	return func(o *options) {
+		switch format {
+		case 'e', 'E', 'f', 'g', 'G':
+			o.floatFormat, o.floatPrec = format, prec
+		}
+	}
+}
+
+// decimalPattern matches the JSON numbers accepted as the values of decimal
+// fields, which DynamoDB accepts as numbers.
+var decimalPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// isDecimal reports whether the string field is annotated as decimal.
+func isDecimal(fd protoreflect.FieldDescriptor) bool {
+	if fd.Kind() != protoreflect.StringKind {
+		return false
+	}
+	opts, ok := proto.GetExtension(fd.Options(), dynabufpb.E_Field).(*dynabufpb.FieldOptions)
+	return ok && opts.GetDecimal()
+}
+
+// encodeValue returns the value stored for a value of fd, which is a
+// number for decimal fields, and formatted float fields, a string for
+// sortable fields, and the stored form of google.type messages.
+func (o *options) encodeValue(fd protoreflect.FieldDescriptor, v any) (any, error) {
+	if isSortable(fd) {
+		return encodeSortable(fd, v)
+	}
+
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		return encodeGoogleType(fd, v)
+	case protoreflect.StringKind:
+		if !isDecimal(fd) {
+			return v, nil
+		}
+		s, _ := v.(string)
+		if !decimalPattern.MatchString(s) {
+			return nil, fmt.Errorf("invalid decimal %q of field %s", s, fd.FullName())
+		}
+		return json.Number(s), nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f, ok := v.(float64)
+		if !ok || o.floatFormat == 0 {
+			return v, nil
+		}
+		bitSize := 64
+		if fd.Kind() == protoreflect.FloatKind {
+			bitSize = 32
+		}
+		return json.Number(strconv.FormatFloat(f, o.floatFormat, o.floatPrec, bitSize)), nil
+	}
+	return v, nil
+}
+
+// decodeValue returns the JSON value of a stored value of fd, reversing
+// encodeValue.
+func decodeValue(fd protoreflect.FieldDescriptor, v any) (any, error) {
+	if isSortable(fd) {
+		return decodeSortable(fd, v)
+	}
+	if fd.Kind() == protoreflect.MessageKind {
+		return decodeGoogleType(fd, v)
+	}
+	if !isDecimal(fd) {
+		return v, nil
+	}
+	switch n := v.(type) {
+	case json.Number:
+		return string(n), nil
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	}
+	return v, nil
+}
@@ -0,0 +1,86 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestMarshalDecimal(t *testing.T) {
+	product := &testpb.Product{
+		Id:        "1",
+		Price:     "12.30",
+		Discounts: []string{"0.5", "-1e2"},
+		Bundled:   &testpb.Product{Id: "2", Price: "12345678901234567890.123456789"},
+	}
+
+	item := dynabuf.MustMarshalItem(product)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberN{Value: "12.30"}, item["price"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberL{Value: []types.AttributeValue{
+		&types.AttributeValueMemberN{Value: "0.5"},
+		&types.AttributeValueMemberN{Value: "-1e2"},
+	}}, item["discounts"])
+	bundled := item["bundled"].(*types.AttributeValueMemberM).Value
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberN{Value: "12345678901234567890.123456789"}, bundled["price"])
+
+	var got testpb.Product
+	must.NoError(t, dynabuf.Unmarshal(item, &got))
+	must.True(t, proto.Equal(product, &got))
+
+	for _, price := range []string{"12,30", "1.", "+1", "01", "NaN"} {
+		_, err := dynabuf.Marshal(&testpb.Product{Price: price})
+		must.ErrorIs(t, err, dynabuf.ErrFailedToMarshal)
+		must.StrContains(t, err.Error(), "invalid decimal")
+	}
+}
+
+func TestWithFloatFormat(t *testing.T) {
+	product := &testpb.Product{Weight: 9.9, Rating: 0.1, DiscountRate: wrapperspb.Double(0.3)}
+
+	tests := []struct {
+		name string
+		opts []dynabuf.Option
+		want map[string]string
+	}{
+		{
+			name: "shortest",
+			want: map[string]string{"weight": "9.9", "rating": "0.1", "discountRate": "0.3"},
+		},
+		{
+			name: "fixed",
+			opts: []dynabuf.Option{dynabuf.WithFloatFormat('f', 2)},
+			want: map[string]string{"weight": "9.90", "rating": "0.10", "discountRate": "0.30"},
+		},
+		{
+			name: "exponent",
+			opts: []dynabuf.Option{dynabuf.WithFloatFormat('e', -1)},
+			want: map[string]string{"weight": "9.9e+00", "rating": "1e-01", "discountRate": "3e-01"},
+		},
+		{
+			name: "unsupported",
+			opts: []dynabuf.Option{dynabuf.WithFloatFormat('x', 2)},
+			want: map[string]string{"weight": "9.9", "rating": "0.1", "discountRate": "0.3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := dynabuf.Marshal(product, test.opts...)
+			must.NoError(t, err)
+
+			item := v.(map[string]types.AttributeValue)
+			for name, want := range test.want {
+				must.Eq[types.AttributeValue](t, &types.AttributeValueMemberN{Value: want}, item[name])
+			}
+
+			var got testpb.Product
+			must.NoError(t, dynabuf.Unmarshal(item, &got))
+			must.True(t, proto.Equal(product, &got))
+		})
+	}
+}
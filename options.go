@@ -0,0 +1,67 @@
+package dynabuf
+
+import (
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Option configures optional behavior of [Marshal] and [Unmarshal].
+type Option func(*options)
+
+// options holds the configuration built from a list of [Option] values.
+type options struct {
+	marshalJSON    protojson.MarshalOptions
+	unmarshalJSON  protojson.UnmarshalOptions
+	encoderOpts    []func(*attributevalue.EncoderOptions)
+	decoderOpts    []func(*attributevalue.DecoderOptions)
+	beforeEncode   []FieldsHook
+	afterDecode    []FieldsHook
+	failureMetrics *FailureMetrics
+	fieldStats     *FieldStats
+	keyAttributes  []string
+	schemaVersion  string
+	mapKeyAttr     string
+	diagnostics    func(Diagnostic)
+	config         *Config
+	pool           MessagePool
+	caseCollisions CaseCollisionPolicy
+	emptyMessages  EmptyMessagePolicy
+	conflicts      ConflictPolicy
+	structKeys     StructKeyPolicy
+	checkLimits    bool
+	writeAliases   bool
+	protoNames     bool
+	metadata       bool
+	mapItems       bool
+	normalizeKeys  bool
+	foldKeys       bool
+	discardUnknown bool
+	floatFormat    byte
+	floatPrec      int
+}
+
+// newOptions returns the configuration built from opts.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithFailureMetrics records the fields that fail to unmarshal in m,
+// grouped by message type and field path.
+func WithFailureMetrics(m *FailureMetrics) Option {
+	return func(o *options) {
+		o.failureMetrics = m
+	}
+}
+
+// WithDiscardUnknown ignores attributes that do not match a field of the
+// message, such as derived attributes and secondary index keys written
+// alongside it, instead of failing.
+func WithDiscardUnknown() Option {
+	return func(o *options) {
+		o.discardUnknown = true
+	}
+}
@@ -0,0 +1,76 @@
+package dynabuf
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnmarshalGetItemOutput unmarshals the item of out into msg, or returns
+// [ErrItemNotFound] if there is no item with the key read.
+//
+// # Example
+//
+//	out, err := client.GetItem(ctx, input)
+//	if err != nil {
+//	  return err
+//	}
+//	var user example.User
+//	if err := dynabuf.UnmarshalGetItemOutput(out, &user); errors.Is(err, dynabuf.ErrItemNotFound) {
+//	  ...
+//	}
+func UnmarshalGetItemOutput(out *dynamodb.GetItemOutput, msg proto.Message, opts ...Option) error {
+	if out == nil || out.Item == nil {
+		return ErrItemNotFound
+	}
+	return Unmarshal(out.Item, msg, opts...)
+}
+
+// UnmarshalQueryOutput unmarshals the items of out, appending them to the
+// slice of messages v points to, such as a *[]*example.User, so the pages
+// of a query are read into one slice.
+//
+// # Example
+//
+//	var orders []*example.Order
+//	for paginator.HasMorePages() {
+//	  out, err := paginator.NextPage(ctx)
+//	  if err != nil {
+//	    return err
+//	  }
+//	  if err := dynabuf.UnmarshalQueryOutput(out, &orders); err != nil {
+//	    return err
+//	  }
+//	}
+func UnmarshalQueryOutput(out *dynamodb.QueryOutput, v any, opts ...Option) error {
+	if out == nil {
+		return unmarshalItems(nil, v, opts...)
+	}
+	return unmarshalItems(out.Items, v, opts...)
+}
+
+// UnmarshalScanOutput unmarshals the items of out, appending them to the
+// slice of messages v points to, as [UnmarshalQueryOutput] does.
+func UnmarshalScanOutput(out *dynamodb.ScanOutput, v any, opts ...Option) error {
+	if out == nil {
+		return unmarshalItems(nil, v, opts...)
+	}
+	return unmarshalItems(out.Items, v, opts...)
+}
+
+// unmarshalItems appends items, unmarshaled, to the slice of messages v
+// points to, growing it once for all of them.
+func unmarshalItems(items []map[string]types.AttributeValue, v any, opts ...Option) error {
+	vValue := reflect.ValueOf(v)
+	if vValue.Kind() != reflect.Ptr || !isProtoSlice(vValue.Elem()) {
+		return fmt.Errorf("%w: %w: %T", ErrFailedToUnmarshal, ErrInvalidOutput, v)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	vValue.Elem().Grow(len(items))
+	return Unmarshal(items, v, opts...)
+}
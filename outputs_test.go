@@ -0,0 +1,31 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestUnmarshalOutputs(t *testing.T) {
+	alice := dynabuf.MustMarshalItem(&testpb.User{Id: "1", Name: "Alice"})
+	bob := dynabuf.MustMarshalItem(&testpb.User{Id: "2", Name: "Bob"})
+
+	var user testpb.User
+	must.NoError(t, dynabuf.UnmarshalGetItemOutput(&dynamodb.GetItemOutput{Item: alice}, &user))
+	must.Eq(t, "Alice", user.GetName())
+	must.ErrorIs(t, dynabuf.UnmarshalGetItemOutput(&dynamodb.GetItemOutput{}, &user), dynabuf.ErrItemNotFound)
+
+	// Pages are appended to the slice.
+	var users []*testpb.User
+	must.NoError(t, dynabuf.UnmarshalQueryOutput(&dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{alice}}, &users))
+	must.NoError(t, dynabuf.UnmarshalScanOutput(&dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{bob}}, &users))
+	must.NoError(t, dynabuf.UnmarshalQueryOutput(&dynamodb.QueryOutput{}, &users))
+	must.SliceLen(t, 2, users)
+	must.Eq(t, "Bob", users[1].GetName())
+
+	must.ErrorIs(t, dynabuf.UnmarshalQueryOutput(&dynamodb.QueryOutput{}, &user), dynabuf.ErrInvalidOutput)
+}
@@ -0,0 +1,126 @@
+package dynabuf
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MessagePool supplies the messages items are decoded into when decoding
+// slices and streams of items, so consumers decoding many items can reuse
+// messages they are done with, rather than allocate one per item.
+//
+// A MessagePool must be safe for concurrent use.
+type MessagePool interface {
+	// New returns an empty message to decode an item into.
+	New() proto.Message
+
+	// Reset takes back a message its user is done with, so New can return
+	// it again once it is reset.
+	Reset(msg proto.Message)
+}
+
+// NewMessagePool returns a [MessagePool] of messages of the type of msg,
+// backed by a [sync.Pool].
+//
+// # Example
+//
+//	pool := dynabuf.NewMessagePool(&example.Event{})
+//
+//	for event, err := range query.Read[*example.Event](ctx, client, "events", expr, dynabuf.WithMessagePool(pool)) {
+//	  ...
+//	  pool.Reset(event)
+//	}
+func NewMessagePool(msg proto.Message) MessagePool {
+	mt := msg.ProtoReflect().Type()
+	return &messagePool{
+		typ: mt,
+		pool: sync.Pool{
+			New: func() any { return mt.New().Interface() },
+		},
+	}
+}
+
+// messagePool is the [MessagePool] returned by [NewMessagePool].
+type messagePool struct {
+	typ  protoreflect.MessageType
+	pool sync.Pool
+}
+
+// New implements [MessagePool].
+func (p *messagePool) New() proto.Message {
+	return p.pool.Get().(proto.Message)
+}
+
+// Reset implements [MessagePool]. Messages of another type are dropped.
+func (p *messagePool) Reset(msg proto.Message) {
+	if msg == nil || msg.ProtoReflect().Type() != p.typ {
+		return
+	}
+	proto.Reset(msg)
+	p.pool.Put(msg)
+}
+
+// WithMessagePool decodes the messages of slices and streams of items into
+// messages of pool, rather than new messages, such as by [Unmarshal] into a
+// slice of messages, [UnmarshalNew], and [BatchGet]. The caller gives the
+// messages it is done with back to the pool with [MessagePool.Reset].
+func WithMessagePool(pool MessagePool) Option {
+	return func(o *options) {
+		o.pool = pool
+	}
+}
+
+// UnmarshalNew decodes item into a new message of type T, taken from the
+// pool of [WithMessagePool] if set, for decoders of streams of items. The
+// message is given back to the pool if it cannot be decoded.
+//
+// # Example
+//
+//	for _, item := range out.Items {
+//	  user, err := dynabuf.UnmarshalNew[*example.User](item, dynabuf.WithMessagePool(pool))
+//	  ...
+//	  pool.Reset(user)
+//	}
+func UnmarshalNew[T proto.Message](item map[string]types.AttributeValue, opts ...Option) (T, error) {
+	var zero T
+
+	msg, err := newMessage[T](newOptions(opts))
+	if err != nil {
+		return zero, err
+	}
+	if err := Unmarshal(item, msg, opts...); err != nil {
+		Release(msg, opts...)
+		return zero, err
+	}
+	return msg, nil
+}
+
+// Release gives msg back to the pool of [WithMessagePool], if set, for
+// decoders of streams to give back the messages they decode but do not
+// return, such as the messages filtered out of a query.
+func Release(msg proto.Message, opts ...Option) {
+	if pool := newOptions(opts).pool; pool != nil {
+		pool.Reset(msg)
+	}
+}
+
+// newMessage returns a new message of type T, taken from the pool of o if
+// set.
+func newMessage[T proto.Message](o *options) (T, error) {
+	var zero T
+	if o.pool == nil {
+		return zero.ProtoReflect().New().Interface().(T), nil
+	}
+
+	got := o.pool.New()
+	msg, ok := got.(T)
+	if !ok {
+		o.pool.Reset(got)
+		return zero, fmt.Errorf("dynabuf: message pool returned %T, rather than %T", got, zero)
+	}
+	return msg, nil
+}
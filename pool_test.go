@@ -0,0 +1,89 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+)
+
+// countingPool is a pool of users counting the messages taken and given
+// back.
+type countingPool struct {
+	free          []proto.Message
+	taken, resets int
+}
+
+func (p *countingPool) New() proto.Message {
+	p.taken++
+	if n := len(p.free); n > 0 {
+		msg := p.free[n-1]
+		p.free = p.free[:n-1]
+		return msg
+	}
+	return &testpb.User{}
+}
+
+func (p *countingPool) Reset(msg proto.Message) {
+	p.resets++
+	proto.Reset(msg)
+	p.free = append(p.free, msg)
+}
+
+func TestMessagePool(t *testing.T) {
+	items := []map[string]types.AttributeValue{
+		dynabuf.MustMarshalItem(&testpb.User{Id: "1", Name: "Alice"}),
+		dynabuf.MustMarshalItem(&testpb.User{Id: "2", Name: "Bob"}),
+	}
+
+	pool := &countingPool{}
+	reused := &testpb.User{}
+	pool.Reset(reused)
+
+	// Slices are decoded into messages of the pool.
+	var users []*testpb.User
+	must.NoError(t, dynabuf.Unmarshal(items, &users, dynabuf.WithMessagePool(pool)))
+	must.SliceLen(t, 2, users)
+	must.Eq(t, 2, pool.taken)
+	must.True(t, users[0] == reused)
+	must.Eq(t, "Alice", users[0].GetName())
+	must.Eq(t, "Bob", users[1].GetName())
+
+	// Messages given back are decoded into again, once reset.
+	pool.Reset(users[1])
+	user, err := dynabuf.UnmarshalNew[*testpb.User](items[0], dynabuf.WithMessagePool(pool))
+	must.NoError(t, err)
+	must.True(t, user == users[1])
+	must.Eq(t, "Alice", user.GetName())
+
+	// Messages released are given back to the pool, if any.
+	dynabuf.Release(user, dynabuf.WithMessagePool(pool))
+	must.Eq(t, 3, pool.resets)
+	dynabuf.Release(user)
+	must.Eq(t, 3, pool.resets)
+
+	// Pools of another type are rejected.
+	_, err = dynabuf.UnmarshalNew[*testpb.Address](items[0], dynabuf.WithMessagePool(pool))
+	must.Error(t, err)
+	must.Eq(t, 4, pool.resets)
+}
+
+func TestNewMessagePool(t *testing.T) {
+	pool := dynabuf.NewMessagePool(&testpb.User{})
+
+	user, err := dynabuf.UnmarshalNew[*testpb.User](dynabuf.MustMarshalItem(&testpb.User{Id: "1"}), dynabuf.WithMessagePool(pool))
+	must.NoError(t, err)
+	must.Eq(t, "1", user.GetId())
+
+	// Messages are reset as they are given back.
+	pool.Reset(user)
+	must.Eq(t, "", user.GetId())
+
+	// Messages of another type are dropped.
+	pool.Reset(&testpb.Address{})
+	_, ok := pool.New().(*testpb.User)
+	must.True(t, ok)
+}
@@ -0,0 +1,343 @@
+package dynabuf
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrInvalidProgressToken is returned when a progress token passed to
+// [WithResumeToken] is malformed, or was issued for another segment.
+var ErrInvalidProgressToken = errors.New("dynabuf: invalid progress token")
+
+// Progress reports the progress of a long-running table utility, such as
+// [Copy] or [Backfill], after every page of items it processes.
+type Progress struct {
+	// Scanned is the number of items read, including the items read before
+	// the utility was resumed.
+	Scanned int
+
+	// Processed is the number of items the utility acted on, such as the
+	// items written by a copy, or updated by a backfill.
+	Processed int
+
+	// Bytes is the approximate size of the items read.
+	Bytes int64
+
+	// Segment and TotalSegments are the segment of the table scanned, as
+	// set with [WithSegment], which is segment 0 of 1 by default.
+	Segment       int
+	TotalSegments int
+
+	// Elapsed is the time since the utility started, or was resumed.
+	Elapsed time.Duration
+
+	// ETA is the estimated time left, from the rate items are read at and
+	// the item count of the table, which is only known if the client of
+	// the table can describe it. It is zero if unknown, and once done.
+	ETA time.Duration
+
+	// Token is the token to pass to [WithResumeToken] to continue after the
+	// items processed so far, such as after a crash. It is empty once done.
+	Token string
+
+	// Done reports whether every item of the segment was processed.
+	Done bool
+}
+
+// ProgressStore persists the progress of table utilities, so a job given
+// the same name with [WithProgressStore] resumes where it left off across
+// process restarts. The checkpoint package implements it with items of a
+// DynamoDB table.
+type ProgressStore interface {
+	// LoadProgress returns the progress last saved under name, or nil if
+	// none was.
+	LoadProgress(ctx context.Context, name string) (*Progress, error)
+
+	// SaveProgress saves the progress under name, replacing the progress
+	// saved before.
+	SaveProgress(ctx context.Context, name string, p Progress) error
+}
+
+// scanOptions holds the configuration of the table utilities scanning a
+// table, such as [Copy] and [Backfill], set by the options of a [Config].
+type scanOptions struct {
+	progress      func(Progress)
+	copyProgress  func(CopyProgress)
+	resumeKey     map[string]types.AttributeValue
+	resumeToken   string
+	segment       int
+	totalSegments int
+	store         ProgressStore
+	storeName     string
+}
+
+// scanning returns the configuration of scanning a table, which scans the
+// whole table unless set with [WithSegment].
+func (c *Config) scanning() *scanOptions {
+	var o scanOptions
+	if c != nil {
+		o = c.scan
+	}
+	if o.segment == 0 && o.totalSegments == 0 {
+		o.totalSegments = 1
+	}
+	return &o
+}
+
+// WithProgress calls fn after every page of items is processed.
+func WithProgress(fn func(Progress)) ConfigOption {
+	return func(c *Config) {
+		c.scan.progress = fn
+	}
+}
+
+// WithResumeToken continues after the items processed when the token was
+// reported by [Progress.Token], with the counts of the progress reported
+// continuing from there. An empty token starts from the beginning.
+func WithResumeToken(token string) ConfigOption {
+	return func(c *Config) {
+		c.scan.resumeToken = token
+	}
+}
+
+// WithSegment scans only segment of the totalSegments segments of the
+// table, so the work can be split between workers, each scanning its own
+// segment, as with the Segment and TotalSegments of a parallel scan.
+func WithSegment(segment, totalSegments int) ConfigOption {
+	return func(c *Config) {
+		c.scan.segment, c.scan.totalSegments = segment, totalSegments
+	}
+}
+
+// WithProgressStore saves the progress to store under name after every
+// page of items is processed, and resumes from the progress saved under
+// name, unless [WithResumeToken] is given a token. A job whose saved
+// progress is done is not run again. Saving the progress failing stops the
+// job with an error.
+//
+// Workers scanning segments of the same table, as set with [WithSegment],
+// must use different names, such as names suffixed with their segment.
+//
+// # Example
+//
+//	store := checkpoint.New(client, "checkpoints")
+//
+//	err := dynabuf.Copy(ctx, src, dst, convert,
+//	  dynabuf.WithProgressStore(store, "copy-users-v2"),
+//	)
+func WithProgressStore(store ProgressStore, name string) ConfigOption {
+	return func(c *Config) {
+		c.scan.store, c.scan.storeName = store, name
+	}
+}
+
+// progressToken is the content of a progress token.
+type progressToken struct {
+	Key           map[string]keyValue `json:"k"`
+	Segment       int                 `json:"s"`
+	TotalSegments int                 `json:"t"`
+	Scanned       int                 `json:"n"`
+	Processed     int                 `json:"p"`
+	Bytes         int64               `json:"b"`
+}
+
+// keyValue is a key attribute value, which is a string, number, or binary.
+type keyValue struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+	B []byte  `json:"B,omitempty"`
+}
+
+// progressTracker tracks the progress of a table utility.
+type progressTracker struct {
+	o        *scanOptions
+	progress Progress
+	startKey map[string]types.AttributeValue
+	done     bool
+	cfg      *Config
+
+	start   time.Time
+	resumed int
+	total   int64
+}
+
+// newProgressTracker returns the tracker of a utility scanning the table,
+// resuming from the progress token or key of its configuration, or from the
+// progress saved in the store of its configuration, if any. If the saved progress
+// is done, the tracker is done too, and the utility must not scan.
+func newProgressTracker(ctx context.Context, table Table) (*progressTracker, error) {
+	o := table.Config.scanning()
+	if o.totalSegments < 1 || o.segment < 0 || o.segment >= o.totalSegments {
+		return nil, fmt.Errorf("dynabuf: segment %d of %d is out of range", o.segment, o.totalSegments)
+	}
+
+	p := &progressTracker{
+		o: o,
+		progress: Progress{
+			Segment:       o.segment,
+			TotalSegments: o.totalSegments,
+		},
+		startKey: o.resumeKey,
+		cfg:      table.Config,
+		start:    table.Config.Now(),
+		total:    -1,
+	}
+
+	token := o.resumeToken
+	if o.store != nil {
+		saved, err := o.store.LoadProgress(ctx, o.storeName)
+		if err != nil {
+			return nil, fmt.Errorf("dynabuf: failed to load progress %q: %w", o.storeName, err)
+		}
+		if saved != nil && token == "" {
+			if saved.Segment != o.segment || saved.TotalSegments != o.totalSegments {
+				return nil, fmt.Errorf("dynabuf: progress %q was saved for segment %d of %d", o.storeName, saved.Segment, saved.TotalSegments)
+			}
+			if saved.Done {
+				p.progress = *saved
+				p.done = true
+				return p, nil
+			}
+			token = saved.Token
+		}
+	}
+
+	if token != "" {
+		t, err := decodeProgressToken(token)
+		if err != nil {
+			return nil, err
+		}
+		if t.Segment != o.segment || t.TotalSegments != o.totalSegments {
+			return nil, fmt.Errorf("%w: issued for segment %d of %d", ErrInvalidProgressToken, t.Segment, t.TotalSegments)
+		}
+		p.progress.Scanned, p.progress.Processed, p.progress.Bytes = t.Scanned, t.Processed, t.Bytes
+		p.startKey = t.key()
+		p.resumed = t.Scanned
+	}
+
+	// The item count is only used for estimates, so failing to get it is
+	// not an error.
+	if client, ok := table.Client.(interface {
+		DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	}); ok && o.progress != nil {
+		out, err := Do(ctx, table.Config, client.DescribeTable, &dynamodb.DescribeTableInput{TableName: aws.String(table.Name)})
+		if err == nil && out.Table != nil && out.Table.ItemCount != nil {
+			p.total = *out.Table.ItemCount / int64(o.totalSegments)
+		}
+	}
+
+	return p, nil
+}
+
+// page records a page of items read, of which processed were acted on,
+// and reports and saves the progress.
+func (p *progressTracker) page(ctx context.Context, items []map[string]types.AttributeValue, processed int, next map[string]types.AttributeValue) error {
+	p.progress.Scanned += len(items)
+	p.progress.Processed += processed
+	for _, item := range items {
+		p.progress.Bytes += int64(itemSize(item))
+	}
+	p.progress.Elapsed = p.cfg.Now().Sub(p.start)
+
+	p.progress.ETA = 0
+	if read := p.progress.Scanned - p.resumed; len(next) > 0 && read > 0 && p.total > int64(p.progress.Scanned) {
+		left := p.total - int64(p.progress.Scanned)
+		p.progress.ETA = time.Duration(float64(p.progress.Elapsed) / float64(read) * float64(left))
+	}
+
+	p.progress.Token = ""
+	if len(next) > 0 {
+		token, err := p.token(next)
+		if err != nil {
+			return err
+		}
+		p.progress.Token = token
+	}
+	p.progress.Done = len(next) == 0
+
+	if p.o.store != nil {
+		if err := p.o.store.SaveProgress(ctx, p.o.storeName, p.progress); err != nil {
+			return fmt.Errorf("dynabuf: failed to save progress %q: %w", p.o.storeName, err)
+		}
+	}
+
+	if p.o.progress != nil {
+		p.o.progress(p.progress)
+	}
+	if p.o.copyProgress != nil {
+		p.o.copyProgress(CopyProgress{
+			Scanned:   p.progress.Scanned,
+			Written:   p.progress.Processed,
+			ResumeKey: next,
+		})
+	}
+	return nil
+}
+
+// token returns the progress token continuing after the key.
+func (p *progressTracker) token(key map[string]types.AttributeValue) (string, error) {
+	t := progressToken{
+		Key:           make(map[string]keyValue, len(key)),
+		Segment:       p.progress.Segment,
+		TotalSegments: p.progress.TotalSegments,
+		Scanned:       p.progress.Scanned,
+		Processed:     p.progress.Processed,
+		Bytes:         p.progress.Bytes,
+	}
+	for name, av := range key {
+		switch av := av.(type) {
+		case *types.AttributeValueMemberS:
+			t.Key[name] = keyValue{S: &av.Value}
+		case *types.AttributeValueMemberN:
+			t.Key[name] = keyValue{N: &av.Value}
+		case *types.AttributeValueMemberB:
+			t.Key[name] = keyValue{B: av.Value}
+		default:
+			return "", fmt.Errorf("dynabuf: unexpected key attribute %q of type %T", name, av)
+		}
+	}
+
+	payload, err := json.Marshal(t)
+	if err != nil {
+		return "", fmt.Errorf("dynabuf: failed to encode progress token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// decodeProgressToken returns the content of a progress token.
+func decodeProgressToken(token string) (*progressToken, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidProgressToken)
+	}
+
+	var t progressToken
+	if err := json.Unmarshal(payload, &t); err != nil || len(t.Key) == 0 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidProgressToken)
+	}
+	return &t, nil
+}
+
+// key returns the key the token continues after.
+func (t *progressToken) key() map[string]types.AttributeValue {
+	key := make(map[string]types.AttributeValue, len(t.Key))
+	for name, v := range t.Key {
+		switch {
+		case v.S != nil:
+			key[name] = &types.AttributeValueMemberS{Value: *v.S}
+		case v.N != nil:
+			key[name] = &types.AttributeValueMemberN{Value: *v.N}
+		default:
+			key[name] = &types.AttributeValueMemberB{Value: v.B}
+		}
+	}
+	return key
+}
@@ -0,0 +1,242 @@
+package dynabuf_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+// pagingClient scans two items per page.
+type pagingClient struct {
+	*dynamotest.Client
+}
+
+func (c pagingClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	scan := *params
+	scan.Limit = aws.Int32(2)
+	return c.Client.Scan(ctx, &scan, optFns...)
+}
+
+// newUsersAndJobs returns a client with a users table holding n users, and
+// an empty jobs table.
+func newUsersAndJobs(t *testing.T, n int) pagingClient {
+	t.Helper()
+
+	client := newUsersTable(t)
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("jobs"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	users := make([]*testpb.User, n)
+	for i := range users {
+		users[i] = &testpb.User{Id: fmt.Sprint(i), Name: "Alice"}
+	}
+	_, err = dynabuf.BatchPut(context.Background(), dynabuf.Table{Client: client, Name: "users"}, users)
+	must.NoError(t, err)
+
+	return pagingClient{client}
+}
+
+func TestCopyProgress(t *testing.T) {
+	ctx := context.Background()
+
+	client := newUsersAndJobs(t, 5)
+	src := dynabuf.Table{Client: client, Name: "users"}
+	dst := dynabuf.Table{Client: client, Name: "jobs"}
+
+	// The copy fails on the fourth user, after two pages.
+	var progress []dynabuf.Progress
+	err := dynabuf.Copy(ctx, src, dst, func(user *testpb.User) (*testpb.Job, error) {
+		if user.GetId() == "3" {
+			return nil, errors.New("boom")
+		}
+		return &testpb.Job{Id: user.GetId()}, nil
+	}, dynabuf.WithProgress(func(p dynabuf.Progress) {
+		progress = append(progress, p)
+	}))
+	must.ErrorContains(t, err, "boom")
+	must.SliceLen(t, 1, progress)
+	must.Eq(t, 2, progress[0].Scanned)
+	must.Eq(t, 2, progress[0].Processed)
+	must.Positive(t, progress[0].Bytes)
+	must.Eq(t, 0, progress[0].Segment)
+	must.Eq(t, 1, progress[0].TotalSegments)
+	must.Positive(t, progress[0].ETA)
+	must.NotEq(t, "", progress[0].Token)
+
+	// Resuming continues after the first page, with its counts.
+	var resumed []dynabuf.Progress
+	err = dynabuf.Copy(ctx, src, dst, func(user *testpb.User) (*testpb.Job, error) {
+		return &testpb.Job{Id: user.GetId()}, nil
+	}, dynabuf.WithResumeToken(progress[0].Token), dynabuf.WithProgress(func(p dynabuf.Progress) {
+		resumed = append(resumed, p)
+	}))
+	must.NoError(t, err)
+	must.SliceLen(t, 2, resumed)
+	must.Eq(t, 4, resumed[0].Scanned)
+	must.Eq(t, 5, resumed[1].Scanned)
+	must.Eq(t, 5, resumed[1].Processed)
+	must.Greater(t, progress[0].Bytes, resumed[1].Bytes)
+	must.Zero(t, resumed[1].ETA)
+	must.Eq(t, "", resumed[1].Token)
+
+	out, err := client.Client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("jobs")})
+	must.NoError(t, err)
+	must.SliceLen(t, 5, out.Items)
+}
+
+func TestBackfillProgressSegments(t *testing.T) {
+	ctx := context.Background()
+
+	client := newUsersAndJobs(t, 30)
+	table := dynabuf.Table{Client: client, Name: "users"}
+
+	derive := func(user *testpb.User) (map[string]types.AttributeValue, error) {
+		return map[string]types.AttributeValue{
+			"nameLength": &types.AttributeValueMemberN{Value: fmt.Sprint(len(user.GetName()))},
+		}, nil
+	}
+
+	var scanned, updated int
+	for segment := range 3 {
+		var last dynabuf.Progress
+		result, err := dynabuf.Backfill(ctx, table, []string{"id"}, derive,
+			dynabuf.WithSegment(segment, 3),
+			dynabuf.WithProgress(func(p dynabuf.Progress) { last = p }),
+		)
+		must.NoError(t, err)
+		must.Positive(t, result.Scanned)
+		must.Less(t, 30, result.Scanned)
+		must.Eq(t, segment, last.Segment)
+		must.Eq(t, 3, last.TotalSegments)
+		must.Eq(t, result.Scanned, last.Scanned)
+		must.Eq(t, result.Updated, last.Processed)
+
+		scanned += result.Scanned
+		updated += result.Updated
+	}
+	must.Eq(t, 30, scanned)
+	must.Eq(t, 30, updated)
+}
+
+func TestResumeTokenErrors(t *testing.T) {
+	ctx := context.Background()
+
+	client := newUsersAndJobs(t, 3)
+	table := dynabuf.Table{Client: client, Name: "users"}
+
+	derive := func(user *testpb.User) (map[string]types.AttributeValue, error) {
+		return nil, nil
+	}
+
+	var token string
+	_, err := dynabuf.Backfill(ctx, table, []string{"id"}, derive, dynabuf.WithProgress(func(p dynabuf.Progress) {
+		if token == "" {
+			token = p.Token
+		}
+	}))
+	must.NoError(t, err)
+	must.NotEq(t, "", token)
+
+	tests := []struct {
+		name string
+		opts []dynabuf.ConfigOption
+	}{
+		{
+			name: "malformed",
+			opts: []dynabuf.ConfigOption{dynabuf.WithResumeToken("not a token")},
+		},
+		{
+			name: "other segment",
+			opts: []dynabuf.ConfigOption{dynabuf.WithResumeToken(token), dynabuf.WithSegment(1, 2)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := dynabuf.Backfill(ctx, table, []string{"id"}, derive, test.opts...)
+			must.ErrorIs(t, err, dynabuf.ErrInvalidProgressToken)
+		})
+	}
+
+	_, err = dynabuf.Backfill(ctx, table, []string{"id"}, derive, dynabuf.WithSegment(2, 2))
+	must.ErrorContains(t, err, "out of range")
+}
+
+// memoryStore is a [dynabuf.ProgressStore] keeping progress in memory.
+type memoryStore map[string]dynabuf.Progress
+
+func (s memoryStore) LoadProgress(ctx context.Context, name string) (*dynabuf.Progress, error) {
+	p, ok := s[name]
+	if !ok {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+func (s memoryStore) SaveProgress(ctx context.Context, name string, p dynabuf.Progress) error {
+	s[name] = p
+	return nil
+}
+
+func TestProgressStore(t *testing.T) {
+	ctx := context.Background()
+
+	client := newUsersAndJobs(t, 5)
+	src := dynabuf.Table{Client: client, Name: "users"}
+	dst := dynabuf.Table{Client: client, Name: "jobs"}
+	store := memoryStore{}
+
+	// The copy fails on the fourth user, after saving the first page.
+	var (
+		fail   = true
+		copied []string
+	)
+	convert := func(user *testpb.User) (*testpb.Job, error) {
+		if user.GetId() == "3" && fail {
+			return nil, errors.New("boom")
+		}
+		copied = append(copied, user.GetId())
+		return &testpb.Job{Id: user.GetId()}, nil
+	}
+	err := dynabuf.Copy(ctx, src, dst, convert, dynabuf.WithProgressStore(store, "copy"))
+	must.ErrorContains(t, err, "boom")
+	must.Eq(t, 2, store["copy"].Scanned)
+	must.False(t, store["copy"].Done)
+
+	// Running the job again resumes after the first page, until done.
+	fail, copied = false, nil
+	err = dynabuf.Copy(ctx, src, dst, convert, dynabuf.WithProgressStore(store, "copy"))
+	must.NoError(t, err)
+	must.SliceLen(t, 3, copied)
+	must.Eq(t, 5, store["copy"].Scanned)
+	must.Eq(t, "", store["copy"].Token)
+	must.True(t, store["copy"].Done)
+
+	// A job that is done is not run again.
+	err = dynabuf.Copy(ctx, src, dst, convert, dynabuf.WithProgressStore(store, "copy"))
+	must.NoError(t, err)
+	must.SliceLen(t, 3, copied)
+
+	// Progress saved for another segment is an error.
+	_, err = dynabuf.Backfill(ctx, src, []string{"id"}, func(user *testpb.User) (map[string]types.AttributeValue, error) {
+		return nil, nil
+	}, dynabuf.WithSegment(1, 2), dynabuf.WithProgressStore(store, "copy"))
+	must.ErrorContains(t, err, "saved for segment 0 of 1")
+}
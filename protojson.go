@@ -0,0 +1,62 @@
+package dynabuf
+
+import (
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// WithProtoJSONMarshalOptions marshals messages into their JSON form, which
+// items are encoded from, with opts rather than the protojson defaults,
+// such as to store unset fields with EmitUnpopulated or EmitDefaultValues,
+// or enums as numbers with UseEnumNumbers, or to marshal messages missing
+// required fields with AllowPartial.
+//
+// UseProtoNames is set by [WithProtoNames] instead, and Multiline and
+// Indent have no effect on items.
+//
+// # Example
+//
+//	item, err := dynabuf.Marshal(order, dynabuf.WithProtoJSONMarshalOptions(protojson.MarshalOptions{
+//	  EmitDefaultValues: true,
+//	  UseEnumNumbers:    true,
+//	}))
+func WithProtoJSONMarshalOptions(opts protojson.MarshalOptions) Option {
+	return func(o *options) {
+		o.marshalJSON = opts
+	}
+}
+
+// WithProtoJSONUnmarshalOptions unmarshals messages from their JSON form,
+// which items are decoded into, with opts rather than the protojson
+// defaults, such as to read messages missing required fields with
+// AllowPartial. Unknown attributes are discarded if either DiscardUnknown
+// or [WithDiscardUnknown] is set.
+//
+// # Example
+//
+//	err := dynabuf.Unmarshal(out.Item, &order, dynabuf.WithProtoJSONUnmarshalOptions(protojson.UnmarshalOptions{
+//	  AllowPartial: true,
+//	}))
+func WithProtoJSONUnmarshalOptions(opts protojson.UnmarshalOptions) Option {
+	return func(o *options) {
+		o.unmarshalJSON = opts
+	}
+}
+
+// protoJSONMarshal returns the protojson options messages are marshaled
+// with, which always use JSON names, as the attributes of fields are
+// renamed after the stored fields are converted.
+func (o *options) protoJSONMarshal() protojson.MarshalOptions {
+	opts := o.marshalJSON
+	opts.UseProtoNames = false
+	opts.Multiline = false
+	opts.Indent = ""
+	return opts
+}
+
+// protoJSONUnmarshal returns the protojson options messages are
+// unmarshaled with.
+func (o *options) protoJSONUnmarshal() protojson.UnmarshalOptions {
+	opts := o.unmarshalJSON
+	opts.DiscardUnknown = opts.DiscardUnknown || o.discardUnknown
+	return opts
+}
@@ -0,0 +1,41 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestProtoJSONOptions(t *testing.T) {
+	summary := &testpb.JobSummary{Id: "1", State: testpb.Job_STATE_RUNNING}
+
+	av, err := dynabuf.Marshal(summary, dynabuf.WithProtoJSONMarshalOptions(protojson.MarshalOptions{
+		EmitDefaultValues: true,
+		UseEnumNumbers:    true,
+	}))
+	must.NoError(t, err)
+	item := av.(map[string]types.AttributeValue)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberN{Value: "1"}, item["state"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberN{Value: "0"}, item["changes"])
+
+	var got testpb.JobSummary
+	must.NoError(t, dynabuf.Unmarshal(item, &got))
+	must.Eq(t, summary, &got, must.Cmp(protocmp.Transform()))
+
+	// Unknown attributes are discarded if set in either option.
+	item["derived"] = &types.AttributeValueMemberS{Value: "x"}
+	must.Error(t, dynabuf.Unmarshal(item, &got))
+	must.NoError(t, dynabuf.Unmarshal(item, &got, dynabuf.WithProtoJSONUnmarshalOptions(protojson.UnmarshalOptions{
+		DiscardUnknown: true,
+	})))
+
+	// Proto names are set by their own option only.
+	av, err = dynabuf.MarshalOptions{ProtoJSON: protojson.MarshalOptions{UseProtoNames: true}}.Marshal(&testpb.Address{ZipCode: 1})
+	must.NoError(t, err)
+	must.MapContainsKey(t, av.(map[string]types.AttributeValue), "zipCode")
+}
@@ -0,0 +1,172 @@
+package dynabuf
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// isProtoMap reports whether v is a map of protobuf messages keyed by
+// strings, such as a map[string]*example.User.
+func isProtoMap(v reflect.Value) bool {
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return false
+	}
+	elemType := v.Type().Elem()
+	if elemType.Kind() != reflect.Ptr {
+		return false
+	}
+	return isProtoMessage(reflect.New(elemType.Elem()).Interface())
+}
+
+// WithMapItems marshals maps of messages keyed by strings, such as a
+// map[string]*example.Order, into an item per message, returned by
+// [Marshal] as a map[string]map[string]types.AttributeValue keyed by the
+// keys of the map, rather than into a single map attribute.
+//
+// If keyAttribute is not empty, the key of each message is stored in the
+// attribute of that name too, replacing the field of the same name, if
+// any, so the items can be written to a table and read back into a map,
+// with [Unmarshal] given a []map[string]types.AttributeValue. The
+// attribute is removed from the items read, unless it names a field.
+//
+// # Example
+//
+//	av, err := dynabuf.Marshal(ordersByID, dynabuf.WithMapItems("pk"))
+//	items := av.(map[string]map[string]types.AttributeValue)
+//
+//	var orders map[string]*example.Order
+//	err = dynabuf.Unmarshal(out.Items, &orders, dynabuf.WithMapItems("pk"))
+func WithMapItems(keyAttribute string) Option {
+	return func(o *options) {
+		o.mapItems, o.mapKeyAttr = true, keyAttribute
+	}
+}
+
+// marshalProtoMap marshals a map of protobuf messages to a map attribute
+// holding the item of each message under its key, or to the items of the
+// messages keyed by their keys with [WithMapItems].
+func marshalProtoMap(v any, opts ...Option) (any, error) {
+	o := newOptions(opts)
+
+	items, err := marshalProtoMapItems(v, o, opts)
+	if err != nil {
+		return nil, err
+	}
+	if o.mapItems {
+		return items, nil
+	}
+
+	result := make(map[string]types.AttributeValue, len(items))
+	for key, item := range items {
+		result[key] = &types.AttributeValueMemberM{Value: item}
+	}
+	return &types.AttributeValueMemberM{Value: result}, nil
+}
+
+// marshalProtoMapItems marshals each message of a map of protobuf messages
+// to an item, keyed by its key, storing the key in the configured
+// attribute, if any.
+func marshalProtoMapItems(v any, o *options, opts []Option) (map[string]map[string]types.AttributeValue, error) {
+	mapValue := reflect.ValueOf(v)
+	if !isProtoMap(mapValue) {
+		return nil, fmt.Errorf("%w: %w: %T", ErrFailedToMarshal, ErrInvalidInput, v)
+	}
+
+	keys := mapValue.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	result := make(map[string]map[string]types.AttributeValue, len(keys))
+	for _, key := range keys {
+		item, err := marshalProtoMessage(mapValue.MapIndex(key).Interface(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: at key %q: %w", ErrFailedToMarshal, key.String(), err)
+		}
+		if o.mapItems && o.mapKeyAttr != "" {
+			item[o.mapKeyAttr] = &types.AttributeValueMemberS{Value: key.String()}
+		}
+		result[key.String()] = item
+	}
+	return result, nil
+}
+
+// unmarshalProtoMap unmarshals a map attribute, or the attributes of an
+// item, holding an item under each key, or items keyed by their keys, or
+// items storing their keys in the attribute of [WithMapItems], into the
+// map of protobuf messages mapValue.
+func unmarshalProtoMap(av any, mapValue reflect.Value, opts ...Option) error {
+	o := newOptions(opts)
+
+	var entries map[string]map[string]types.AttributeValue
+	switch av := av.(type) {
+	case *types.AttributeValueMemberM:
+		var err error
+		if entries, err = mapMembers(av.Value); err != nil {
+			return err
+		}
+	case map[string]types.AttributeValue:
+		var err error
+		if entries, err = mapMembers(av); err != nil {
+			return err
+		}
+	case map[string]map[string]types.AttributeValue:
+		entries = av
+	case []map[string]types.AttributeValue:
+		if o.mapKeyAttr == "" {
+			return fmt.Errorf("%w: %w: items need a key attribute set with WithMapItems to unmarshal into %v", ErrFailedToUnmarshal, ErrInvalidOutput, mapValue.Type())
+		}
+		entries = make(map[string]map[string]types.AttributeValue, len(av))
+		for i, item := range av {
+			key, ok := item[o.mapKeyAttr].(*types.AttributeValueMemberS)
+			if !ok {
+				return fmt.Errorf("%w: at index %d: item has no string attribute %q", ErrFailedToUnmarshal, i, o.mapKeyAttr)
+			}
+			if _, ok := entries[key.Value]; ok {
+				return fmt.Errorf("%w: at index %d: duplicate key %q", ErrFailedToUnmarshal, i, key.Value)
+			}
+			entries[key.Value] = item
+		}
+	default:
+		return fmt.Errorf("%w: %w: cannot unmarshal %T into %v", ErrFailedToUnmarshal, ErrInvalidOutput, av, mapValue.Type())
+	}
+
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMapWithSize(mapValue.Type(), len(entries)))
+	}
+	keyType, elemType := mapValue.Type().Key(), mapValue.Type().Elem().Elem()
+	for _, key := range slices.Sorted(maps.Keys(entries)) {
+		msg := reflect.New(elemType)
+		item := entries[key]
+		if name := o.mapKeyAttr; name != "" && fieldByName(msg.Interface().(proto.Message).ProtoReflect().Descriptor(), name) == nil {
+			if _, ok := item[name]; ok {
+				item = maps.Clone(item)
+				delete(item, name)
+			}
+		}
+		if err := Unmarshal(item, msg.Interface().(proto.Message), opts...); err != nil {
+			return fmt.Errorf("%w: at key %q: %w", ErrFailedToUnmarshal, key, err)
+		}
+		mapValue.SetMapIndex(reflect.ValueOf(key).Convert(keyType), msg)
+	}
+	return nil
+}
+
+// mapMembers returns the items held by the members of a map attribute.
+func mapMembers(members map[string]types.AttributeValue) (map[string]map[string]types.AttributeValue, error) {
+	entries := make(map[string]map[string]types.AttributeValue, len(members))
+	for key, v := range members {
+		item, ok := v.(*types.AttributeValueMemberM)
+		if !ok {
+			return nil, fmt.Errorf("%w: at key %q: %T is not a map attribute", ErrFailedToUnmarshal, key, v)
+		}
+		entries[key] = item.Value
+	}
+	return entries, nil
+}
@@ -0,0 +1,91 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestProtoMap(t *testing.T) {
+	users := map[string]*testpb.User{
+		"alice": {Id: "1", Name: "Alice"},
+		"bob":   {Id: "2", Name: "Bob"},
+	}
+
+	av, err := dynabuf.Marshal(users)
+	must.NoError(t, err)
+	m, ok := av.(*types.AttributeValueMemberM)
+	must.True(t, ok)
+	must.MapLen(t, 2, m.Value)
+	alice, ok := m.Value["alice"].(*types.AttributeValueMemberM)
+	must.True(t, ok)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "Alice"}, alice.Value["name"])
+
+	// Map attributes and items are read back into maps.
+	var got map[string]*testpb.User
+	must.NoError(t, dynabuf.Unmarshal(m, &got))
+	must.Eq(t, users, got, must.Cmp(protocmp.Transform()))
+
+	got = nil
+	must.NoError(t, dynabuf.Unmarshal(m.Value, &got))
+	must.Eq(t, users, got, must.Cmp(protocmp.Transform()))
+
+	// Members other than maps are rejected.
+	err = dynabuf.Unmarshal(map[string]types.AttributeValue{
+		"alice": &types.AttributeValueMemberS{Value: "Alice"},
+	}, &got)
+	must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+
+	// Maps of other values are not messages.
+	_, err = dynabuf.Marshal(map[string]string{"a": "b"})
+	must.ErrorIs(t, err, dynabuf.ErrInvalidInput)
+}
+
+func TestMapItems(t *testing.T) {
+	users := map[string]*testpb.User{
+		"alice": {Id: "1", Name: "Alice"},
+		"bob":   {Id: "2", Name: "Bob"},
+	}
+
+	av, err := dynabuf.Marshal(users, dynabuf.WithMapItems("pk"))
+	must.NoError(t, err)
+	items, ok := av.(map[string]map[string]types.AttributeValue)
+	must.True(t, ok)
+	must.MapLen(t, 2, items)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "alice"}, items["alice"]["pk"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "Alice"}, items["alice"]["name"])
+
+	// Items read from a table are keyed by their key attribute.
+	var got map[string]*testpb.User
+	must.NoError(t, dynabuf.Unmarshal([]map[string]types.AttributeValue{items["alice"], items["bob"]}, &got, dynabuf.WithMapItems("pk")))
+	must.Eq(t, users, got, must.Cmp(protocmp.Transform()))
+
+	got = nil
+	must.NoError(t, dynabuf.Unmarshal(items, &got, dynabuf.WithMapItems("pk")))
+	must.Eq(t, users, got, must.Cmp(protocmp.Transform()))
+
+	// The fields of option structs set the option too.
+	av, err = dynabuf.Marshal(users, dynabuf.MarshalOptions{MapItems: true, MapKeyAttribute: "pk"}.Options()...)
+	must.NoError(t, err)
+	items = av.(map[string]map[string]types.AttributeValue)
+	got = nil
+	must.NoError(t, dynabuf.Unmarshal(items, &got, dynabuf.UnmarshalOptions{MapItems: true, MapKeyAttribute: "pk"}.Options()...))
+	must.Eq(t, users, got, must.Cmp(protocmp.Transform()))
+
+	// Keys stored in a field are kept.
+	av, err = dynabuf.Marshal(map[string]*testpb.User{"3": {Name: "Carol"}}, dynabuf.WithMapItems("id"))
+	must.NoError(t, err)
+	got = nil
+	must.NoError(t, dynabuf.Unmarshal(av, &got, dynabuf.WithMapItems("id")))
+	must.Eq(t, "3", got["3"].GetId())
+
+	// Items without their key, or without a key attribute, are rejected.
+	err = dynabuf.Unmarshal([]map[string]types.AttributeValue{dynabuf.MustMarshalItem(users["alice"])}, &got, dynabuf.WithMapItems("pk"))
+	must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+	err = dynabuf.Unmarshal([]map[string]types.AttributeValue{items["alice"]}, &got)
+	must.ErrorIs(t, err, dynabuf.ErrInvalidOutput)
+}
@@ -0,0 +1,47 @@
+package dynabuf
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WithProtoNames names the attributes of fields after their names in the
+// .proto file, such as "display_name", rather than their JSON names, such
+// as "displayName", when enabled is true, for tables shared with readers
+// and writers naming attributes as the schema does.
+//
+// [Unmarshal] reads attributes named either way, so items written before
+// the option was enabled are still read. Aliases, composite attributes, and
+// [UpdateItemInput] are not affected.
+//
+// # Example
+//
+//	item, err := dynabuf.Marshal(account, dynabuf.WithProtoNames(true))
+func WithProtoNames(enabled bool) Option {
+	return func(o *options) {
+		o.protoNames = enabled
+	}
+}
+
+// renameProtoNames renames the attributes of fields, stored by a message of
+// type md, and of its nested messages, from the JSON names of the fields to
+// their proto names.
+func renameProtoNames(md protoreflect.MessageDescriptor, fields map[string]any) {
+	for i := range md.Fields().Len() {
+		fd := md.Fields().Get(i)
+
+		v, ok := fields[fd.JSONName()]
+		if !ok {
+			continue
+		}
+		eachValue(fd, v, func(fd protoreflect.FieldDescriptor, v any) {
+			if nested, ok := v.(map[string]any); ok && !isWellKnown(fd.Message()) {
+				renameProtoNames(fd.Message(), nested)
+			}
+		})
+
+		if name := string(fd.Name()); name != fd.JSONName() {
+			delete(fields, fd.JSONName())
+			fields[name] = v
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestProtoNames(t *testing.T) {
+	user := &testpb.User{
+		Id:   "1",
+		Name: "Alice",
+		PreviousAddresses: []*testpb.Address{
+			{Street: "1 Main St", ZipCode: 12345},
+		},
+	}
+
+	av, err := dynabuf.Marshal(user, dynabuf.WithProtoNames(true))
+	must.NoError(t, err)
+	item := av.(map[string]types.AttributeValue)
+	must.MapContainsKeys(t, item, []string{"id", "name", "previous_addresses"})
+	must.MapNotContainsKey(t, item, "previousAddresses")
+
+	addresses, ok := item["previous_addresses"].(*types.AttributeValueMemberL)
+	must.True(t, ok)
+	address, ok := addresses.Value[0].(*types.AttributeValueMemberM)
+	must.True(t, ok)
+	must.MapContainsKey(t, address.Value, "zip_code")
+
+	// Items are read back whichever names they were written with.
+	var got testpb.User
+	must.NoError(t, dynabuf.Unmarshal(item, &got))
+	must.Eq(t, user, &got, must.Cmp(protocmp.Transform()))
+
+	got.Reset()
+	must.NoError(t, dynabuf.Unmarshal(dynabuf.MustMarshalItem(user), &got))
+	must.Eq(t, user, &got, must.Cmp(protocmp.Transform()))
+
+	// The struct form sets the same option.
+	m, err := dynabuf.MarshalOptions{UseProtoNames: true}.Marshal(user)
+	must.NoError(t, err)
+	must.MapContainsKey(t, m.(map[string]types.AttributeValue), "previous_addresses")
+}
@@ -0,0 +1,49 @@
+package dynabuf
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// PutItemInput returns the input of a PutItem request writing the item of
+// msg, marshaled with opts, to the table its type is annotated with:
+//
+//	option (dynabuf.v1.message) = {
+//	  table: "orders"
+//	};
+//
+// # Example
+//
+//	input, err := dynabuf.PutItemInput(order)
+//	if err != nil {
+//	  return err
+//	}
+//	_, err = client.PutItem(ctx, input)
+func PutItemInput(msg proto.Message, opts ...Option) (*dynamodb.PutItemInput, error) {
+	md := msg.ProtoReflect().Descriptor()
+	table := tableName(md)
+	if table == "" {
+		return nil, fmt.Errorf("dynabuf: message %s is not annotated with a table", md.FullName())
+	}
+
+	item, err := marshalProtoMessage(msg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item:      item,
+	}, nil
+}
+
+// tableName returns the name of the table storing the items of messages of
+// type md, as annotated, or "".
+func tableName(md protoreflect.MessageDescriptor) string {
+	opts, _ := proto.GetExtension(md.Options(), dynabufpb.E_Message).(*dynabufpb.MessageOptions)
+	return opts.GetTable()
+}
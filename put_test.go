@@ -0,0 +1,23 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestPutItemInput(t *testing.T) {
+	input, err := dynabuf.PutItemInput(&testpb.Order{Customer: "alice", Id: "1", Status: "OPEN"})
+	must.NoError(t, err)
+	must.Eq(t, "orders", aws.ToString(input.TableName))
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "alice"}, input.Item["customer"])
+	must.MapContainsKey(t, input.Item, "sk")
+
+	// Messages without a table are rejected.
+	_, err = dynabuf.PutItemInput(&testpb.User{Id: "1"})
+	must.Error(t, err)
+}
@@ -0,0 +1,315 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// CompileAIP160 parses an AIP-160 filter about messages described by md,
+// such as the filter field of a List request, and compiles it to DynamoDB
+// expressions like [Compile], returning an error wrapping [ErrInvalidQuery]
+// if it is not valid or uses a feature DynamoDB cannot support.
+//
+// Restrictions compare a field with =, !=, <, <=, >, >=, or the has
+// operator, and are combined with AND, OR, NOT, or -, and parentheses.
+// Restrictions separated only by spaces must all match, and OR binds more
+// tightly than AND, as AIP-160 specifies:
+//
+//	a = 1 b = 2 OR c = 3    // a = 1 AND (b = 2 OR c = 3)
+//
+// The has operator matches fields holding any value with *, keys of map
+// fields, elements of repeated fields, and is equality otherwise:
+//
+//	address:*
+//	labels:env
+//	tags:urgent
+//
+// Equality with a string ending in *, and optionally starting with one,
+// matches strings with the prefix, or containing the substring. Global
+// restrictions, which match values of any field, and functions are not
+// supported.
+//
+// # Example
+//
+//	expr, err := query.CompileAIP160((&example.Order{}).ProtoReflect().Descriptor(), req.GetFilter(), "customerId", "createTime")
+//	if err != nil {
+//	  return nil, status.Error(codes.InvalidArgument, err.Error())
+//	}
+func CompileAIP160(md protoreflect.MessageDescriptor, filter string, keys ...string) (*Expression, error) {
+	if len(keys) > 2 {
+		return nil, fmt.Errorf("query: expected a partition key and an optional sort key, got %d keys", len(keys))
+	}
+
+	if strings.TrimSpace(filter) == "" {
+		return &Expression{}, nil
+	}
+
+	toks, err := lexAIP(filter)
+	if err != nil {
+		return nil, err
+	}
+	n, err := (&aipParser{parser: &parser{md: md, toks: toks}}).parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return compile(n, keys), nil
+}
+
+// lexAIP splits an AIP-160 filter into tokens. Words are runs of
+// characters other than spaces, quotes, parentheses, commas, colons, and
+// comparison operators, and strings are quoted with single or double
+// quotes.
+func lexAIP(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(s) && rune(s[j]) != c {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, invalid(i, "unterminated string")
+			}
+			quoted := s[i : j+1]
+			if c == '\'' {
+				quoted = `"` + strings.ReplaceAll(strings.ReplaceAll(s[i+1:j], `\'`, "'"), `"`, `\"`) + `"`
+			}
+			text, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, invalid(i, "invalid string %s", s[i:j+1])
+			}
+			toks = append(toks, token{kind: "string", text: text, offset: i})
+			i = j + 1
+		case strings.HasPrefix(s[i:], "!=") || strings.HasPrefix(s[i:], "<=") || strings.HasPrefix(s[i:], ">="):
+			toks = append(toks, token{kind: "op", text: s[i : i+2], offset: i})
+			i += 2
+		case strings.ContainsRune("=<>:(),", c):
+			toks = append(toks, token{kind: "op", text: string(c), offset: i})
+			i++
+		default:
+			j := i
+			for j < len(s) && !unicode.IsSpace(rune(s[j])) && !strings.ContainsRune("\"'=<>!:(),", rune(s[j])) {
+				j++
+			}
+			if j == i {
+				return nil, invalid(i, "unexpected %q", c)
+			}
+			toks = append(toks, token{kind: "word", text: s[i:j], offset: i})
+			i = j
+		}
+	}
+	return append(toks, token{kind: "eof", offset: len(s)}), nil
+}
+
+// aipComparators maps the comparators of AIP-160 to those of DynamoDB.
+var aipComparators = map[string]string{
+	"=":  "=",
+	"!=": "<>",
+	"<":  "<",
+	"<=": "<=",
+	">":  ">",
+	">=": ">=",
+	":":  ":",
+}
+
+// aipParser parses AIP-160 filters, whose keywords are case-sensitive.
+type aipParser struct {
+	*parser
+}
+
+func (p *aipParser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == "word" && t.text == kw
+}
+
+func (p *aipParser) parse() (node, error) {
+	n, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != "eof" {
+		return nil, unexpected(t, "expected AND, OR, or end of filter")
+	}
+	return n, nil
+}
+
+// parseExpression parses sequences joined by AND.
+func (p *aipParser) parseExpression() (node, error) {
+	left, err := p.parseSequence()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.next()
+		right, err := p.parseSequence()
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseSequence parses factors separated by spaces, which must all match.
+func (p *aipParser) parseSequence() (node, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if t := p.peek(); t.kind == "eof" || p.isOp(")") || p.isKeyword("AND") {
+			return left, nil
+		}
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{op: "AND", left: left, right: right}
+	}
+}
+
+// parseFactor parses terms joined by OR.
+func (p *aipParser) parseFactor() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseTerm parses a restriction or parenthesized expression, negated by
+// NOT or a leading -.
+func (p *aipParser) parseTerm() (node, error) {
+	negate := false
+	switch t := p.peek(); {
+	case p.isKeyword("NOT"):
+		p.next()
+		negate = true
+	case t.kind == "word" && t.text == "-":
+		p.next()
+		negate = true
+	case t.kind == "word" && t.text[0] == '-':
+		p.toks[p.pos] = token{kind: t.kind, text: t.text[1:], offset: t.offset + 1}
+		negate = true
+	}
+
+	n, err := p.parseSimple()
+	if err != nil {
+		return nil, err
+	}
+	if negate {
+		return &not{node: n}, nil
+	}
+	return n, nil
+}
+
+func (p *aipParser) parseSimple() (node, error) {
+	if p.isOp("(") {
+		p.next()
+		n, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expectOp(")")
+	}
+	return p.parseRestriction()
+}
+
+// parseRestriction parses a comparison of a field with a value.
+func (p *aipParser) parseRestriction() (node, error) {
+	t := p.peek()
+	if t.kind == "eof" {
+		return nil, unexpected(t, "expected a field")
+	}
+	if next := p.toks[p.pos+1]; next.kind != "op" || aipComparators[next.text] == "" {
+		if t.kind == "word" && next.kind == "op" && next.text == "(" {
+			return nil, invalid(t.offset, "functions are not supported, got %q", t.text)
+		}
+		return nil, invalid(t.offset, "global restrictions are not supported, got %q", t.text)
+	}
+
+	f, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	comparator := aipComparators[p.next().text]
+	if comparator == ":" {
+		return p.parseHas(f, t)
+	}
+
+	if err := checkComparable(f, t); err != nil {
+		return nil, err
+	}
+	if v := p.peek(); comparator == "=" && f.valueField().Kind() == protoreflect.StringKind && strings.Contains(v.text, "*") {
+		return p.parseWildcard(f, t)
+	}
+	return p.parseComparison(f, t, comparator)
+}
+
+// parseHas parses the value of a restriction with the has operator.
+func (p *aipParser) parseHas(f field, t token) (node, error) {
+	if v := p.peek(); v.kind == "word" && v.text == "*" {
+		p.next()
+		return &comparison{path: f.path, fd: f.valueField(), op: "attribute_exists"}, nil
+	}
+
+	switch {
+	case f.isMap():
+		if f.fd.MapKey().Kind() != protoreflect.StringKind {
+			return nil, invalid(t.offset, "map field %q does not have string keys", strings.Join(f.path, "."))
+		}
+		k := p.next()
+		if k.kind != "word" && k.kind != "string" {
+			return nil, unexpected(k, "expected a key")
+		}
+		return &comparison{path: append(f.path, k.text), fd: f.fd.MapValue(), op: "attribute_exists"}, nil
+	case f.isList():
+		v, err := p.parseValue(f.fd)
+		if err != nil {
+			return nil, err
+		}
+		return &comparison{path: f.path, fd: f.valueField(), op: "contains", values: []types.AttributeValue{v}}, nil
+	}
+
+	if err := checkComparable(f, t); err != nil {
+		return nil, err
+	}
+	return p.parseComparison(f, t, "=")
+}
+
+// parseWildcard parses a string with wildcards, which must end in *, and
+// may start with one, that f must have as a prefix or contain.
+func (p *aipParser) parseWildcard(f field, t token) (node, error) {
+	v := p.next()
+	text, ok := strings.CutSuffix(v.text, "*")
+	op := "begins_with"
+	if strings.HasPrefix(text, "*") {
+		text = text[1:]
+		op = "contains"
+	}
+	if !ok || text == "" || strings.Contains(text, "*") {
+		return nil, invalid(v.offset, "unsupported wildcard %q of field %q, only prefixes and substrings are supported", v.text, strings.Join(f.path, "."))
+	}
+	return &comparison{path: f.path, fd: f.valueField(), op: op, values: []types.AttributeValue{&types.AttributeValueMemberS{Value: text}}}, nil
+}
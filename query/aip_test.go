@@ -0,0 +1,196 @@
+package query_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/picatz/dynabuf/query"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestCompileAIP160(t *testing.T) {
+	tests := []struct {
+		name         string
+		md           protoreflect.MessageDescriptor
+		filter       string
+		keys         []string
+		keyCondition string
+		filterExpr   string
+		values       map[string]types.AttributeValue
+	}{
+		{
+			name:       "empty",
+			filter:     "  ",
+			filterExpr: "",
+		},
+		{
+			name:       "comparison",
+			filter:     `name = "Alice"`,
+			filterExpr: "#n0 = :v0",
+			values: map[string]types.AttributeValue{
+				":v0": &types.AttributeValueMemberS{Value: "Alice"},
+			},
+		},
+		{
+			name:       "single quotes and JSON names",
+			filter:     `address.zipCode >= 10000 AND address.street != 'Main St'`,
+			filterExpr: "#n0.#n1 >= :v0 AND #n0.#n2 <> :v1",
+			values: map[string]types.AttributeValue{
+				":v0": &types.AttributeValueMemberN{Value: "10000"},
+				":v1": &types.AttributeValueMemberS{Value: "Main St"},
+			},
+		},
+		{
+			name:       "OR binds more tightly than AND",
+			filter:     "name = a AND name = b OR age > 3",
+			filterExpr: "#n0 = :v0 AND (#n0 = :v1 OR #n1 > :v2)",
+		},
+		{
+			name:       "implicit AND",
+			filter:     "name = a age > 3 OR age < 1",
+			filterExpr: "#n0 = :v0 AND (#n1 > :v1 OR #n1 < :v2)",
+		},
+		{
+			name:       "negation",
+			filter:     "NOT name = a -age > 3 -(age < 1 OR name = b)",
+			filterExpr: "NOT #n0 = :v0 AND NOT #n1 > :v1 AND NOT (#n1 < :v2 OR #n0 = :v3)",
+		},
+		{
+			name:       "negative numbers",
+			filter:     "age > -3",
+			filterExpr: "#n0 > :v0",
+			values: map[string]types.AttributeValue{
+				":v0": &types.AttributeValueMemberN{Value: "-3"},
+			},
+		},
+		{
+			name:       "timestamps",
+			filter:     `create_time > "2024-01-01T00:00:00Z" create_time <= 2024-06-30`,
+			filterExpr: "#n0 > :v0 AND #n0 <= :v1",
+			values: map[string]types.AttributeValue{
+				":v0": &types.AttributeValueMemberS{Value: "2024-01-01T00:00:00Z"},
+				":v1": &types.AttributeValueMemberS{Value: "2024-06-30T00:00:00Z"},
+			},
+		},
+		{
+			name:       "wildcards",
+			filter:     `name = "Al*" address.street = *Main*`,
+			filterExpr: "begins_with(#n0, :v0) AND contains(#n1.#n2, :v1)",
+			values: map[string]types.AttributeValue{
+				":v0": &types.AttributeValueMemberS{Value: "Al"},
+				":v1": &types.AttributeValueMemberS{Value: "Main"},
+			},
+		},
+		{
+			name:       "has any value",
+			filter:     "address:* previous_addresses:*",
+			filterExpr: "attribute_exists(#n0) AND attribute_exists(#n1)",
+		},
+		{
+			name:       "has equality",
+			filter:     "age:30",
+			filterExpr: "#n0 = :v0",
+		},
+		{
+			name:       "has map keys",
+			md:         (&structpb.Struct{}).ProtoReflect().Descriptor(),
+			filter:     "fields:env",
+			filterExpr: "attribute_exists(#n0.#n1)",
+		},
+		{
+			name:       "has repeated elements",
+			md:         (&descriptorpb.FileDescriptorProto{}).ProtoReflect().Descriptor(),
+			filter:     `dependency:"a.proto"`,
+			filterExpr: "contains(#n0, :v0)",
+		},
+		{
+			name:         "key conditions",
+			filter:       "age > 3 id = 1 name = A*",
+			keys:         []string{"id", "name"},
+			keyCondition: "#n0 = :v0 AND begins_with(#n1, :v1)",
+			filterExpr:   "#n2 > :v2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			md := test.md
+			if md == nil {
+				md = userDescriptor
+			}
+
+			expr, err := query.CompileAIP160(md, test.filter, test.keys...)
+			must.NoError(t, err)
+			must.Eq(t, test.keyCondition, expr.KeyCondition)
+			must.Eq(t, test.filterExpr, expr.Filter)
+			if test.values != nil {
+				must.Eq(t, test.values, expr.Values)
+			}
+		})
+	}
+}
+
+func TestCompileAIP160Invalid(t *testing.T) {
+	tests := []struct {
+		filter string
+		err    string
+	}{
+		{filter: "email = a", err: `unknown field "email" of dynabuf.test.v1.User at offset 0`},
+		{filter: "Alice", err: `global restrictions are not supported, got "Alice"`},
+		{filter: "name = a and age = 1", err: `global restrictions are not supported, got "and"`},
+		{filter: `regex(name, "A.*")`, err: `functions are not supported, got "regex"`},
+		{filter: "name = a AND", err: "expected a field, got end of query"},
+		{filter: "(name = a", err: `expected ")", got end of query`},
+		{filter: "name = 'a", err: "unterminated string"},
+		{filter: "name = *ice", err: `unsupported wildcard "*ice"`},
+		{filter: "name = A*e*", err: `unsupported wildcard "A*e*"`},
+		{filter: "address = x", err: `field "address" cannot be compared`},
+		{filter: "address:x", err: `field "address" cannot be compared`},
+		{filter: "previous_addresses:x", err: "fields of kind message cannot be compared"},
+		{filter: "create_time > yesterday", err: `invalid time "yesterday"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			_, err := query.CompileAIP160(userDescriptor, test.filter)
+			must.ErrorIs(t, err, query.ErrInvalidQuery)
+			must.StrContains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestCompileAIP160Read(t *testing.T) {
+	ctx := context.Background()
+	client := newUsersTable(t, users...)
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{filter: "id = 1 name = Al*", want: []string{"Alan", "Alice"}},
+		{filter: "age = 30 OR age = 40 -name = Carol", want: []string{"Alice", "Bob"}},
+		{filter: "address:* AND NOT address.zip_code = 0", want: []string{"Alan"}},
+		{filter: "create_time < 2025-01-01 OR previous_addresses:*", want: []string{"Alice", "Bob"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			expr, err := query.CompileAIP160(userDescriptor, test.filter, "id", "name")
+			must.NoError(t, err)
+
+			var got []string
+			for user, err := range query.Read[*testpb.User](ctx, client, "users", expr) {
+				must.NoError(t, err)
+				got = append(got, user.GetName())
+			}
+			slices.Sort(got)
+			must.Eq(t, test.want, got)
+		})
+	}
+}
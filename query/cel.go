@@ -0,0 +1,440 @@
+package query
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// CompileCEL compiles a CEL expression evaluating to a bool, in which the
+// fields of the message described by md are variables, returning an error
+// wrapping [ErrInvalidQuery] if it is not valid:
+//
+//	state == Job.State.STATE_RUNNING && name.startsWith("import-")
+//
+// keys are the names of the partition key and, optionally, the sort key
+// attributes of the table or index being read, as for [Compile].
+//
+// Conditions combined with && are compiled to DynamoDB expressions where
+// they compare fields with constants, test fields with has, or match
+// strings with startsWith or contains. Other conditions, such as
+// comprehensions or arithmetic, are left to [Expression.Match], which
+// evaluates the whole CEL expression, so the filter has the same meaning on
+// both sides: fields holding their zero value are not stored by
+// [dynabuf.Marshal], so comparisons satisfied by a field's zero value also
+// match items without it.
+//
+// # Example
+//
+//	expr, err := query.CompileCEL(md, `customer_id == "123" && items.exists(i, i.quantity > 10)`, "customerId")
+//	if err != nil {
+//	  return err
+//	}
+//
+//	for order, err := range query.Read[*example.Order](ctx, client, "orders", expr) {
+//	  ...
+//	}
+func CompileCEL(md protoreflect.MessageDescriptor, expr string, keys ...string) (*Expression, error) {
+	if len(keys) > 2 {
+		return nil, fmt.Errorf("query: expected a partition key and an optional sort key, got %d keys", len(keys))
+	}
+
+	env, err := cel.NewEnv(
+		cel.Container(string(md.ParentFile().Package())),
+		cel.DeclareContextProto(md),
+		cel.CrossTypeNumericComparisons(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: failed to declare %s: %w", md.FullName(), err)
+	}
+
+	checked, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, iss.Err())
+	}
+	if !checked.OutputType().IsExactType(cel.BoolType) {
+		return nil, fmt.Errorf("%w: expression evaluates to %s, not bool", ErrInvalidQuery, checked.OutputType())
+	}
+
+	program, err := env.Program(checked)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+
+	t := &translator{md: md, ast: checked.NativeRep()}
+
+	var (
+		conds []node
+		exact = true
+	)
+	for _, e := range celConjuncts(t.ast.Expr()) {
+		if n, ok := t.node(e); ok {
+			conds = append(conds, n)
+		} else {
+			exact = false
+		}
+	}
+
+	compiled, rest := build(conds, keys)
+	compiled.partial = !exact || len(rest) > 0
+	compiled.match = func(msg proto.Message) (bool, error) {
+		vars, err := cel.ContextProtoVars(msg)
+		if err != nil {
+			return false, fmt.Errorf("query: failed to read %s: %w", msg.ProtoReflect().Descriptor().FullName(), err)
+		}
+
+		out, _, err := program.Eval(vars)
+		if err != nil {
+			return false, fmt.Errorf("query: failed to evaluate filter: %w", err)
+		}
+
+		ok, _ := out.Value().(bool)
+		return ok, nil
+	}
+
+	return compiled, nil
+}
+
+// celConjuncts returns the operands of the top-level conjunction of e.
+func celConjuncts(e celast.Expr) []celast.Expr {
+	if e.Kind() == celast.CallKind && e.AsCall().FunctionName() == operators.LogicalAnd {
+		var out []celast.Expr
+		for _, arg := range e.AsCall().Args() {
+			out = append(out, celConjuncts(arg)...)
+		}
+		return out
+	}
+	return []celast.Expr{e}
+}
+
+// translator translates checked CEL expressions to query nodes.
+type translator struct {
+	md  protoreflect.MessageDescriptor
+	ast *celast.AST
+}
+
+// celComparators are the DynamoDB comparators of CEL's comparison
+// operators, and of their mirror images for constants on the left.
+var celComparators = map[string][2]string{
+	operators.Equals:        {"=", "="},
+	operators.NotEquals:     {"<>", "<>"},
+	operators.Less:          {"<", ">"},
+	operators.LessEquals:    {"<=", ">="},
+	operators.Greater:       {">", "<"},
+	operators.GreaterEquals: {">=", "<="},
+}
+
+// node returns the node of a CEL expression, and whether DynamoDB can
+// evaluate it.
+func (t *translator) node(e celast.Expr) (node, bool) {
+	switch e.Kind() {
+	case celast.SelectKind:
+		if e.AsSelect().IsTestOnly() {
+			f, ok := t.path(e.AsSelect().Operand(), e.AsSelect().FieldName())
+			if !ok {
+				return nil, false
+			}
+			return &comparison{path: f.path, op: "attribute_exists"}, true
+		}
+		return t.boolField(e)
+	case celast.IdentKind:
+		return t.boolField(e)
+	case celast.CallKind:
+	default:
+		return nil, false
+	}
+
+	call := e.AsCall()
+	args := call.Args()
+
+	switch fn := call.FunctionName(); fn {
+	case operators.LogicalAnd, operators.LogicalOr:
+		left, ok := t.node(args[0])
+		if !ok {
+			return nil, false
+		}
+		right, ok := t.node(args[1])
+		if !ok {
+			return nil, false
+		}
+		op := "AND"
+		if fn == operators.LogicalOr {
+			op = "OR"
+		}
+		return &logical{op: op, left: left, right: right}, true
+
+	case operators.LogicalNot:
+		n, ok := t.node(args[0])
+		if !ok {
+			return nil, false
+		}
+		return &not{node: n}, true
+
+	case operators.In:
+		// A field in a list of constants.
+		if f, ok := t.fieldOf(args[0]); ok && args[1].Kind() == celast.ListKind && orderable(f, "=") {
+			c := &comparison{path: f.path, op: "IN"}
+			for _, elem := range args[1].AsList().Elements() {
+				v, ok := t.constant(f.fd, elem)
+				if !ok {
+					return nil, false
+				}
+				c.values = append(c.values, v)
+			}
+			if len(c.values) == 0 || len(c.values) > 100 {
+				return nil, false
+			}
+			c.missing = zeroMatches(f, c.op, c.values)
+			return c, true
+		}
+
+		// A constant in a repeated field.
+		if f, ok := t.fieldOf(args[1]); ok && f.isList() {
+			v, ok := t.constant(f.fd, args[0])
+			if !ok {
+				return nil, false
+			}
+			return &comparison{path: f.path, op: "contains", values: []types.AttributeValue{v}}, true
+		}
+		return nil, false
+
+	case "startsWith", "contains":
+		if !call.IsMemberFunction() {
+			return nil, false
+		}
+		f, ok := t.fieldOf(call.Target())
+		if !ok || f.isList() || f.fd.Kind() != protoreflect.StringKind || isDecimal(f.fd) {
+			return nil, false
+		}
+		v, ok := t.constant(f.fd, args[0])
+		if !ok || v.(*types.AttributeValueMemberS).Value == "" {
+			return nil, false
+		}
+		op := "contains"
+		if fn == "startsWith" {
+			op = "begins_with"
+		}
+		return &comparison{path: f.path, op: op, values: []types.AttributeValue{v}}, true
+	}
+
+	ops, ok := celComparators[call.FunctionName()]
+	if !ok {
+		return nil, false
+	}
+
+	op, fieldArg, constArg := ops[0], args[0], args[1]
+	if _, ok := t.fieldOf(fieldArg); !ok {
+		op, fieldArg, constArg = ops[1], args[1], args[0]
+	}
+
+	f, ok := t.fieldOf(fieldArg)
+	if !ok || !orderable(f, op) {
+		return nil, false
+	}
+	v, ok := t.constant(f.fd, constArg)
+	if !ok {
+		return nil, false
+	}
+
+	c := &comparison{path: f.path, op: op, values: []types.AttributeValue{v}}
+	c.missing = zeroMatches(f, op, c.values)
+	return c, true
+}
+
+// boolField returns the node of a bool field used as a condition.
+func (t *translator) boolField(e celast.Expr) (node, bool) {
+	f, ok := t.fieldOf(e)
+	if !ok || f.isList() || f.fd.Kind() != protoreflect.BoolKind {
+		return nil, false
+	}
+	return &comparison{
+		path:   f.path,
+		op:     "=",
+		values: []types.AttributeValue{&types.AttributeValueMemberBOOL{Value: true}},
+	}, true
+}
+
+// fieldOf returns the field an identifier or a chain of selections refers
+// to.
+func (t *translator) fieldOf(e celast.Expr) (field, bool) {
+	if ref, ok := t.ast.ReferenceMap()[e.ID()]; ok && ref.Value != nil {
+		// An enum constant.
+		return field{}, false
+	}
+
+	switch e.Kind() {
+	case celast.IdentKind:
+		return t.path(nil, e.AsIdent())
+	case celast.SelectKind:
+		if e.AsSelect().IsTestOnly() {
+			return field{}, false
+		}
+		return t.path(e.AsSelect().Operand(), e.AsSelect().FieldName())
+	}
+	return field{}, false
+}
+
+// path returns the field named name of the message operand refers to, or
+// of the message the filter is about if operand is nil.
+func (t *translator) path(operand celast.Expr, name string) (field, bool) {
+	var (
+		parent field
+		md     = t.md
+	)
+	if operand != nil {
+		var ok bool
+		if parent, ok = t.fieldOf(operand); !ok || parent.isList() || parent.isMap() ||
+			parent.fd.Kind() != protoreflect.MessageKind || scalarField(parent.fd) != nil {
+			return field{}, false
+		}
+		md = parent.fd.Message()
+	}
+
+	fd := md.Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		return field{}, false
+	}
+	return field{path: append(slices.Clone(parent.path), fd.JSONName()), fd: fd}, true
+}
+
+// constant returns the attribute value of a constant compared with fd.
+func (t *translator) constant(fd protoreflect.FieldDescriptor, e celast.Expr) (types.AttributeValue, bool) {
+	var v any
+	switch e.Kind() {
+	case celast.LiteralKind:
+		v = e.AsLiteral().Value()
+	case celast.IdentKind, celast.SelectKind:
+		ref, ok := t.ast.ReferenceMap()[e.ID()]
+		if !ok || ref.Value == nil {
+			return nil, false
+		}
+		v = ref.Value.Value()
+	case celast.CallKind:
+		call := e.AsCall()
+		if call.FunctionName() != "timestamp" || len(call.Args()) != 1 || call.Args()[0].Kind() != celast.LiteralKind {
+			return nil, false
+		}
+		if fd.Kind() != protoreflect.MessageKind || fd.Message().FullName() != "google.protobuf.Timestamp" {
+			return nil, false
+		}
+		v = call.Args()[0].AsLiteral().Value()
+	default:
+		return nil, false
+	}
+
+	var text string
+	switch v := v.(type) {
+	case string:
+		text = v
+	case bool:
+		text = strconv.FormatBool(v)
+	case int64:
+		text = strconv.FormatInt(v, 10)
+		if fd.Kind() == protoreflect.EnumKind {
+			ev := fd.Enum().Values().ByNumber(protoreflect.EnumNumber(v))
+			if ev == nil {
+				return nil, false
+			}
+			text = string(ev.Name())
+		}
+	case uint64:
+		text = strconv.FormatUint(v, 10)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, false
+	}
+
+	av, err := literal(fd, token{text: text})
+	return av, err == nil
+}
+
+// orderable reports whether DynamoDB compares the values of f with op as
+// CEL does.
+func orderable(f field, op string) bool {
+	if f.isList() || f.isMap() {
+		return false
+	}
+	fd := f.fd
+	if isSortable(fd) {
+		return true
+	}
+	if fd.Kind() == protoreflect.MessageKind {
+		// Unset wrappers are null in CEL, and other messages are not
+		// stored as scalars.
+		return fd.Message().FullName() == "google.protobuf.Timestamp"
+	}
+	switch fd.Kind() {
+	case protoreflect.BytesKind:
+		return false
+	case protoreflect.BoolKind, protoreflect.EnumKind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return op == "=" || op == "<>"
+	}
+	return true
+}
+
+// zeroMatches reports whether the value CEL reads from a field that is not
+// stored, its zero value, satisfies the comparison.
+func zeroMatches(f field, op string, values []types.AttributeValue) bool {
+	var zero types.AttributeValue
+	switch f.fd.Kind() {
+	case protoreflect.MessageKind:
+		zero = &types.AttributeValueMemberS{Value: "1970-01-01T00:00:00Z"}
+	case protoreflect.StringKind:
+		zero = &types.AttributeValueMemberS{Value: ""}
+	case protoreflect.BoolKind:
+		zero = &types.AttributeValueMemberBOOL{Value: false}
+	case protoreflect.EnumKind:
+		zero = &types.AttributeValueMemberS{Value: string(f.fd.Enum().Values().ByNumber(0).Name())}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		zero = &types.AttributeValueMemberS{Value: "0"}
+	default:
+		zero = &types.AttributeValueMemberN{Value: "0"}
+	}
+
+	cmp := func(v types.AttributeValue) int {
+		switch v := v.(type) {
+		case *types.AttributeValueMemberN:
+			r, _ := new(big.Rat).SetString(v.Value)
+			return new(big.Rat).Neg(r).Sign()
+		case *types.AttributeValueMemberS:
+			return strings.Compare(zero.(*types.AttributeValueMemberS).Value, v.Value)
+		case *types.AttributeValueMemberBOOL:
+			if !v.Value {
+				return 0
+			}
+			return -1
+		}
+		return -1
+	}
+
+	switch op {
+	case "=":
+		return cmp(values[0]) == 0
+	case "<>":
+		return cmp(values[0]) != 0
+	case "<":
+		return cmp(values[0]) < 0
+	case "<=":
+		return cmp(values[0]) <= 0
+	case ">":
+		return cmp(values[0]) > 0
+	case ">=":
+		return cmp(values[0]) >= 0
+	case "IN":
+		return slices.ContainsFunc(values, func(v types.AttributeValue) bool { return cmp(v) == 0 })
+	}
+	return false
+}
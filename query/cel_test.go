@@ -0,0 +1,168 @@
+package query_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/picatz/dynabuf/query"
+	"github.com/shoenig/test/must"
+)
+
+func TestCompileCEL(t *testing.T) {
+	tests := []struct {
+		name         string
+		expr         string
+		keys         []string
+		keyCondition string
+		filter       string
+		exact        bool
+	}{
+		{
+			name:   "comparisons",
+			expr:   `name == "Alice" && 30 < age`,
+			filter: "#n0 = :v0 AND #n1 > :v1",
+			exact:  true,
+		},
+		{
+			name:   "zero values",
+			expr:   `age <= 30 && name != ""`,
+			filter: "(attribute_not_exists(#n0) OR #n0 <= :v0) AND (attribute_exists(#n1) AND #n1 <> :v1)",
+			exact:  true,
+		},
+		{
+			name:   "functions",
+			expr:   `(name.startsWith("Al") || address.street.contains("Main")) && !has(address.zip_code)`,
+			filter: "(begins_with(#n0, :v0) OR contains(#n1.#n2, :v1)) AND NOT attribute_exists(#n1.#n3)",
+			exact:  true,
+		},
+		{
+			name:   "timestamps",
+			expr:   `create_time >= timestamp("2024-01-01T00:00:00Z")`,
+			filter: "#n0 >= :v0",
+			exact:  true,
+		},
+		{
+			name:   "lists",
+			expr:   `age in [30, 40]`,
+			filter: "#n0 IN (:v0, :v1)",
+			exact:  true,
+		},
+		{
+			name:   "client-side conditions",
+			expr:   `age > 18 && previous_addresses.exists(a, a.zip_code == 12345)`,
+			filter: "#n0 > :v0",
+		},
+		{
+			name:   "client-side disjunctions",
+			expr:   `age > 18 || size(previous_addresses) > 1`,
+			filter: "",
+		},
+		{
+			name:         "key conditions",
+			expr:         `id == "1" && name.startsWith("A") && age > 3`,
+			keys:         []string{"id", "name"},
+			keyCondition: "#n0 = :v0 AND begins_with(#n1, :v1)",
+			filter:       "#n2 > :v2",
+			exact:        true,
+		},
+		{
+			name:         "key comparisons left to the client",
+			expr:         `id == "1" && name > "A" && name < "C"`,
+			keys:         []string{"id", "name"},
+			keyCondition: "#n0 = :v0 AND #n1 > :v1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := query.CompileCEL(userDescriptor, test.expr, test.keys...)
+			must.NoError(t, err)
+			must.Eq(t, test.keyCondition, filter.KeyCondition)
+			must.Eq(t, test.filter, filter.Filter)
+			must.Eq(t, test.exact, filter.Exact())
+		})
+	}
+}
+
+func TestCompileCELEnums(t *testing.T) {
+	md := (&testpb.Job{}).ProtoReflect().Descriptor()
+
+	filter, err := query.CompileCEL(md, "state == Job.State.STATE_RUNNING || state == 3")
+	must.NoError(t, err)
+	must.Eq(t, "#n0 = :v0 OR #n0 = :v1", filter.Filter)
+	must.Eq(t, map[string]types.AttributeValue{
+		":v0": &types.AttributeValueMemberS{Value: "STATE_RUNNING"},
+		":v1": &types.AttributeValueMemberS{Value: "STATE_FAILED"},
+	}, filter.Values)
+	must.True(t, filter.Exact())
+
+	filter, err = query.CompileCEL(md, "state > Job.State.STATE_RUNNING")
+	must.NoError(t, err)
+	must.Eq(t, "", filter.Filter)
+	must.False(t, filter.Exact())
+
+	ok, err := filter.Match(&testpb.Job{State: testpb.Job_STATE_FAILED})
+	must.NoError(t, err)
+	must.True(t, ok)
+}
+
+func TestCompileCELInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"email == 'a'",
+		"name == 1",
+		"name",
+		"age >",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := query.CompileCEL(userDescriptor, expr)
+			must.ErrorIs(t, err, query.ErrInvalidQuery)
+		})
+	}
+}
+
+func TestCompileCELRead(t *testing.T) {
+	ctx := context.Background()
+	client := newUsersTable(t, users...)
+
+	// Every filter must select the same users whether it is evaluated by
+	// DynamoDB, where it can be, or by Match.
+	for _, expr := range []string{
+		`age < 35`,
+		`age == 0 || age > 35`,
+		`address.zip_code == 0`,
+		`address.zip_code != 0`,
+		`has(address.zip_code)`,
+		`!has(address.zip_code) && age in [0, 40]`,
+		`create_time < timestamp("2025-01-01T00:00:00Z")`,
+		`name.contains("a") && size(name) > 3`,
+		`previous_addresses.exists(a, a.street == "Main St")`,
+		`id == "1" && name >= "Alan" && name < "B"`,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			filter, err := query.CompileCEL(userDescriptor, expr, "id", "name")
+			must.NoError(t, err)
+
+			var want []string
+			for _, user := range users {
+				ok, err := filter.Match(user)
+				must.NoError(t, err)
+				if ok {
+					want = append(want, user.GetName())
+				}
+			}
+
+			var got []string
+			for user, err := range query.Read[*testpb.User](ctx, client, "users", filter) {
+				must.NoError(t, err)
+				got = append(got, user.GetName())
+			}
+
+			slices.Sort(want)
+			slices.Sort(got)
+			must.Eq(t, want, got)
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/avtext"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultPageSize is the number of messages listed when no page size
+	// is requested.
+	DefaultPageSize = 50
+
+	// MaxPageSize is the largest number of messages listed in a page.
+	// Larger page sizes are coerced to it.
+	MaxPageSize = 1000
+)
+
+// ErrInvalidPageToken is returned when a page token is malformed, was not
+// issued by a [Lister] with the same key, or was issued for a different
+// query.
+var ErrInvalidPageToken = errors.New("query: invalid page token")
+
+// Lister lists messages of type T matching expressions a page at a time,
+// following AIP-158, so the List methods of APIs only need to pass their
+// page size and page token along.
+//
+// Page tokens are opaque to callers. They hold the key to continue reading
+// from, and a fingerprint of the table and expression, signed with the
+// Lister's key, so tokens cannot be forged or reused with another filter.
+//
+// A Lister is safe for concurrent use.
+type Lister[T proto.Message] struct {
+	client Client
+	table  string
+	cfg    *dynabuf.Config
+	key    []byte
+}
+
+// NewLister returns a [Lister] reading the given table, and signing its
+// page tokens with key, which must be kept secret. Its requests are sent
+// with the configuration of opts.
+func NewLister[T proto.Message](client Client, table string, key []byte, opts ...dynabuf.ConfigOption) *Lister[T] {
+	return &Lister[T]{
+		client: client,
+		table:  table,
+		cfg:    dynabuf.NewConfig(opts...),
+		key:    key,
+	}
+}
+
+// List returns a page of at most pageSize messages matching the expression,
+// starting where the page of pageToken ended, or at the first message if it
+// is empty, and the token of the next page, which is empty if there are no
+// more messages.
+//
+// A pageSize of zero lists [DefaultPageSize] messages, and sizes above
+// [MaxPageSize] are coerced to it. Page tokens must be used with the same
+// expression they were returned for, or [ErrInvalidPageToken] is returned.
+//
+// # Example
+//
+//	expr, err := query.CompileAIP160(md, req.GetFilter(), "customerId", "createTime")
+//	if err != nil {
+//	  return nil, status.Error(codes.InvalidArgument, err.Error())
+//	}
+//
+//	orders, next, err := lister.List(ctx, expr, int(req.GetPageSize()), req.GetPageToken())
+//	if errors.Is(err, query.ErrInvalidPageToken) {
+//	  return nil, status.Error(codes.InvalidArgument, err.Error())
+//	}
+func (l *Lister[T]) List(ctx context.Context, expr *Expression, pageSize int, pageToken string) ([]T, string, error) {
+	switch {
+	case pageSize < 0:
+		return nil, "", fmt.Errorf("query: page size must not be negative, got %d", pageSize)
+	case pageSize == 0:
+		pageSize = DefaultPageSize
+	case pageSize > MaxPageSize:
+		pageSize = MaxPageSize
+	}
+
+	fingerprint := l.fingerprint(expr)
+
+	var start map[string]types.AttributeValue
+	if pageToken != "" {
+		var err error
+		if start, err = l.decodeToken(pageToken, fingerprint); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var (
+		zero  T
+		query = expr.QueryInput(l.table)
+		scan  = expr.ScanInput(l.table)
+		msgs  []T
+	)
+	ctx = dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	// Each read is limited to the messages still missing from the page, so
+	// the page never ends part way through a read, and the last evaluated
+	// key of the last read is where the next page starts.
+	for {
+		limit := aws.Int32(int32(pageSize - len(msgs)))
+
+		var items []map[string]types.AttributeValue
+		if expr.KeyCondition != "" {
+			query.ExclusiveStartKey, query.Limit = start, limit
+			out, err := dynabuf.Do(ctx, l.cfg, l.client.Query, query)
+			if err != nil {
+				return nil, "", fmt.Errorf("query: failed to list %q: %w", l.table, err)
+			}
+			items, start = out.Items, out.LastEvaluatedKey
+		} else {
+			scan.ExclusiveStartKey, scan.Limit = start, limit
+			out, err := dynabuf.Do(ctx, l.cfg, l.client.Scan, scan)
+			if err != nil {
+				return nil, "", fmt.Errorf("query: failed to list %q: %w", l.table, err)
+			}
+			items, start = out.Items, out.LastEvaluatedKey
+		}
+
+		for _, item := range items {
+			msg := zero.ProtoReflect().New().Interface().(T)
+			if err := dynabuf.Unmarshal(item, msg); err != nil {
+				return nil, "", err
+			}
+			if !expr.Exact() {
+				ok, err := expr.Match(msg)
+				if err != nil {
+					return nil, "", err
+				}
+				if !ok {
+					continue
+				}
+			}
+			msgs = append(msgs, msg)
+		}
+
+		if len(start) == 0 {
+			return msgs, "", nil
+		}
+		if len(msgs) >= pageSize {
+			next, err := l.encodeToken(start, fingerprint)
+			if err != nil {
+				return nil, "", err
+			}
+			return msgs, next, nil
+		}
+	}
+}
+
+// pageToken is the content of a page token.
+type pageToken struct {
+	Key         map[string]keyValue `json:"k"`
+	Fingerprint []byte              `json:"f"`
+}
+
+// keyValue is a key attribute value, which is a string, number, or binary.
+type keyValue struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+	B []byte  `json:"B,omitempty"`
+}
+
+// fingerprint returns a hash of the table and expression, identifying the
+// query a page token continues.
+func (l *Lister[T]) fingerprint(expr *Expression) []byte {
+	h := sha256.New()
+	var zero T
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00", zero.ProtoReflect().Descriptor().FullName(), l.table, expr.KeyCondition, expr.Filter)
+	for _, name := range slices.Sorted(maps.Keys(expr.Names)) {
+		fmt.Fprintf(h, "%s=%s\x00", name, expr.Names[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(expr.Values)) {
+		fmt.Fprintf(h, "%s=%s\x00", name, avtext.Format(expr.Values[name]))
+	}
+	return h.Sum(nil)[:16]
+}
+
+// encodeToken returns the signed page token continuing from key.
+func (l *Lister[T]) encodeToken(key map[string]types.AttributeValue, fingerprint []byte) (string, error) {
+	t := pageToken{Key: make(map[string]keyValue, len(key)), Fingerprint: fingerprint}
+	for name, av := range key {
+		switch av := av.(type) {
+		case *types.AttributeValueMemberS:
+			t.Key[name] = keyValue{S: &av.Value}
+		case *types.AttributeValueMemberN:
+			t.Key[name] = keyValue{N: &av.Value}
+		case *types.AttributeValueMemberB:
+			t.Key[name] = keyValue{B: av.Value}
+		default:
+			return "", fmt.Errorf("query: unexpected key attribute %q of type %T", name, av)
+		}
+	}
+
+	payload, err := json.Marshal(t)
+	if err != nil {
+		return "", fmt.Errorf("query: failed to encode page token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(l.sign(payload)), nil
+}
+
+// decodeToken returns the key a page token continues from, checking it is
+// signed and was issued for the query with the fingerprint.
+func (l *Lister[T]) decodeToken(token string, fingerprint []byte) (map[string]types.AttributeValue, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidPageToken)
+	}
+	payload, err1 := base64.RawURLEncoding.DecodeString(encoded)
+	mac, err2 := base64.RawURLEncoding.DecodeString(sig)
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidPageToken)
+	}
+	if !hmac.Equal(mac, l.sign(payload)) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidPageToken)
+	}
+
+	var t pageToken
+	if err := json.Unmarshal(payload, &t); err != nil {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidPageToken)
+	}
+	if !bytes.Equal(t.Fingerprint, fingerprint) {
+		return nil, fmt.Errorf("%w: issued for a different query", ErrInvalidPageToken)
+	}
+
+	key := make(map[string]types.AttributeValue, len(t.Key))
+	for name, v := range t.Key {
+		switch {
+		case v.S != nil:
+			key[name] = &types.AttributeValueMemberS{Value: *v.S}
+		case v.N != nil:
+			key[name] = &types.AttributeValueMemberN{Value: *v.N}
+		default:
+			key[name] = &types.AttributeValueMemberB{Value: v.B}
+		}
+	}
+	return key, nil
+}
+
+func (l *Lister[T]) sign(payload []byte) []byte {
+	h := hmac.New(sha256.New, l.key)
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,95 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/picatz/dynabuf/query"
+	"github.com/shoenig/test/must"
+)
+
+func TestListerList(t *testing.T) {
+	ctx := context.Background()
+	lister := query.NewLister[*testpb.User](newUsersTable(t, users...), "users", []byte("secret"))
+
+	tests := []struct {
+		filter string
+		size   int
+		want   [][]string
+	}{
+		{filter: "", size: 0, want: [][]string{{"Alice", "Alan", "Bob", "Carol", "Dan"}}},
+		{filter: "", size: 2, want: [][]string{{"Alice", "Alan"}, {"Bob", "Carol"}, {"Dan"}}},
+		{filter: "id = 1", size: 3, want: [][]string{{"Alan", "Alice", "Bob"}}},
+		{filter: "age = 30 OR age = 40", size: 2, want: [][]string{{"Alice", "Bob"}, {"Carol"}}},
+		{filter: "id = 2 -name = Dan", size: 1, want: [][]string{{"Carol"}, nil}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			expr, err := query.CompileAIP160(userDescriptor, test.filter, "id", "name")
+			must.NoError(t, err)
+
+			var (
+				pages [][]string
+				token string
+			)
+			for {
+				msgs, next, err := lister.List(ctx, expr, test.size, token)
+				must.NoError(t, err)
+
+				var page []string
+				for _, msg := range msgs {
+					page = append(page, msg.GetName())
+				}
+				pages = append(pages, page)
+
+				if next == "" {
+					break
+				}
+				token = next
+			}
+			must.Eq(t, test.want, pages)
+		})
+	}
+}
+
+func TestListerListInvalidPageToken(t *testing.T) {
+	ctx := context.Background()
+	client := newUsersTable(t, users...)
+	lister := query.NewLister[*testpb.User](client, "users", []byte("secret"))
+
+	expr, err := query.CompileAIP160(userDescriptor, "age > 0", "id", "name")
+	must.NoError(t, err)
+
+	_, token, err := lister.List(ctx, expr, 1, "")
+	must.NoError(t, err)
+	must.NotEq(t, "", token)
+
+	other, err := query.CompileAIP160(userDescriptor, "age > 1", "id", "name")
+	must.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		lister *query.Lister[*testpb.User]
+		expr   *query.Expression
+		token  string
+		err    string
+	}{
+		{name: "malformed", lister: lister, expr: expr, token: "abc", err: "malformed"},
+		{name: "tampered", lister: lister, expr: expr, token: "e30" + token[3:], err: "signature mismatch"},
+		{name: "other key", lister: query.NewLister[*testpb.User](client, "users", []byte("other")), expr: expr, token: token, err: "signature mismatch"},
+		{name: "other filter", lister: lister, expr: other, token: token, err: "issued for a different query"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := test.lister.List(ctx, test.expr, 1, test.token)
+			must.ErrorIs(t, err, query.ErrInvalidPageToken)
+			must.StrContains(t, err.Error(), test.err)
+		})
+	}
+
+	_, _, err = lister.List(ctx, expr, -1, "")
+	must.ErrorContains(t, err, "page size must not be negative")
+}
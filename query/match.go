@@ -0,0 +1,299 @@
+package query
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"iter"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Client is the subset of the DynamoDB API used by [Read].
+type Client interface {
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
+// Read returns the messages in the table matching the expression, reading
+// them page by page as the sequence is iterated. The table is read with a
+// Query if the expression has a key condition, or a Scan otherwise, and if
+// the expression is not [Expression.Exact], the decoded messages are
+// checked with [Expression.Match].
+//
+// Messages are decoded with opts. With [dynabuf.WithMessagePool], they are
+// taken from the pool, and the messages not matching the expression are
+// given back to it.
+func Read[T proto.Message](ctx context.Context, client Client, table string, expr *Expression, opts ...dynabuf.Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		ctx := dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+		for item, err := range items(ctx, client, table, expr) {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			msg, err := dynabuf.UnmarshalNew[T](item, opts...)
+			if err != nil {
+				if !yield(zero, err) {
+					return
+				}
+				continue
+			}
+
+			if !expr.Exact() {
+				ok, err := expr.Match(msg)
+				if err != nil {
+					dynabuf.Release(msg, opts...)
+					if !yield(zero, err) {
+						return
+					}
+					continue
+				}
+				if !ok {
+					dynabuf.Release(msg, opts...)
+					continue
+				}
+			}
+
+			if !yield(msg, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ReadLazy is like [Read], but returns the items matching the expression
+// as [dynabuf.LazyItem] values, decoded with opts only when their message is
+// read, so callers filtering most items away by their attributes do not
+// pay to decode them.
+//
+// Items are checked against expressions which are not [Expression.Exact]
+// by their attributes, without decoding them, unless the expression can
+// only be evaluated on messages, as the expressions of [CompileCEL] are.
+func ReadLazy[T proto.Message](ctx context.Context, client Client, table string, expr *Expression, opts ...dynabuf.Option) iter.Seq2[*dynabuf.LazyItem[T], error] {
+	return func(yield func(*dynabuf.LazyItem[T], error) bool) {
+		var zero T
+		ctx := dynabuf.ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+		for item, err := range items(ctx, client, table, expr) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			lazy := dynabuf.NewLazyItem[T](item, opts...)
+			if !expr.Exact() {
+				ok, err := expr.matchLazy(lazy.Item(), func() (proto.Message, error) { return lazy.Message() })
+				if err != nil {
+					if !yield(nil, err) {
+						return
+					}
+					continue
+				}
+				if !ok {
+					continue
+				}
+			}
+
+			if !yield(lazy, nil) {
+				return
+			}
+		}
+	}
+}
+
+// items returns the items of the table the expression selects, reading
+// them page by page with a Query if the expression has a key condition, or
+// a Scan otherwise, without checking them with [Expression.Match].
+func items(ctx context.Context, client Client, table string, expr *Expression) iter.Seq2[map[string]types.AttributeValue, error] {
+	return func(yield func(map[string]types.AttributeValue, error) bool) {
+		var (
+			query = expr.QueryInput(table)
+			scan  = expr.ScanInput(table)
+		)
+		page := func() ([]map[string]types.AttributeValue, map[string]types.AttributeValue, error) {
+			if expr.KeyCondition != "" {
+				out, err := client.Query(ctx, query)
+				if err != nil {
+					return nil, nil, err
+				}
+				query.ExclusiveStartKey = out.LastEvaluatedKey
+				return out.Items, out.LastEvaluatedKey, nil
+			}
+			out, err := client.Scan(ctx, scan)
+			if err != nil {
+				return nil, nil, err
+			}
+			scan.ExclusiveStartKey = out.LastEvaluatedKey
+			return out.Items, out.LastEvaluatedKey, nil
+		}
+
+		for {
+			items, next, err := page()
+			if err != nil {
+				yield(nil, fmt.Errorf("query: failed to read %q: %w", table, err))
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(next) == 0 {
+				return
+			}
+		}
+	}
+}
+
+// eval reports whether an item matches a node, as DynamoDB evaluates the
+// node's expression, or as its comparisons are meant to if DynamoDB cannot
+// evaluate them.
+func eval(n node, item map[string]types.AttributeValue) bool {
+	switch n := n.(type) {
+	case *logical:
+		if n.op == "AND" {
+			return eval(n.left, item) && eval(n.right, item)
+		}
+		return eval(n.left, item) || eval(n.right, item)
+	case *not:
+		return !eval(n.node, item)
+	}
+
+	c := n.(*comparison)
+
+	v := lookupPath(item, c.path)
+	if v == nil {
+		return c.missing && c.op != "attribute_exists"
+	}
+
+	switch c.op {
+	case "attribute_exists":
+		return true
+	case "begins_with":
+		s, ok := v.(*types.AttributeValueMemberS)
+		return ok && strings.HasPrefix(s.Value, c.values[0].(*types.AttributeValueMemberS).Value)
+	case "contains":
+		switch v := v.(type) {
+		case *types.AttributeValueMemberS:
+			sub, ok := c.values[0].(*types.AttributeValueMemberS)
+			return ok && strings.Contains(v.Value, sub.Value)
+		case *types.AttributeValueMemberL:
+			return slices.ContainsFunc(v.Value, func(elem types.AttributeValue) bool {
+				order, ok := c.compare(elem, c.values[0])
+				return ok && order == 0
+			})
+		}
+		return false
+	case "IN":
+		return slices.ContainsFunc(c.values, func(value types.AttributeValue) bool {
+			order, ok := c.compare(v, value)
+			return ok && order == 0
+		})
+	case "BETWEEN":
+		lo, ok1 := c.compare(v, c.values[0])
+		hi, ok2 := c.compare(v, c.values[1])
+		return ok1 && ok2 && lo >= 0 && hi <= 0
+	}
+
+	order, ok := c.compare(v, c.values[0])
+	switch c.op {
+	case "=":
+		return ok && order == 0
+	case "<>":
+		return !ok || order != 0
+	case "<":
+		return ok && order < 0
+	case "<=":
+		return ok && order <= 0
+	case ">":
+		return ok && order > 0
+	case ">=":
+		return ok && order >= 0
+	}
+	return false
+}
+
+// compare compares attribute values of the comparison's field, reporting
+// whether they are comparable. Enums compare by number, and 64-bit integers
+// by value, even though DynamoDB cannot compare their attribute values so.
+func (c *comparison) compare(a, b types.AttributeValue) (int, bool) {
+	fd := c.fd
+	if s := scalarField(fd); s != nil && fd.Message().FullName() != "google.protobuf.Timestamp" {
+		fd = s
+	}
+
+	switch a := a.(type) {
+	case *types.AttributeValueMemberN:
+		b, ok := b.(*types.AttributeValueMemberN)
+		if !ok {
+			return 0, false
+		}
+		x, ok1 := new(big.Rat).SetString(a.Value)
+		y, ok2 := new(big.Rat).SetString(b.Value)
+		if !ok1 || !ok2 {
+			return 0, false
+		}
+		return x.Cmp(y), true
+	case *types.AttributeValueMemberS:
+		b, ok := b.(*types.AttributeValueMemberS)
+		if !ok {
+			return 0, false
+		}
+		if isSortable(c.fd) {
+			return strings.Compare(a.Value, b.Value), true
+		}
+		switch fd.Kind() {
+		case protoreflect.EnumKind:
+			x := fd.Enum().Values().ByName(protoreflect.Name(a.Value))
+			y := fd.Enum().Values().ByName(protoreflect.Name(b.Value))
+			if x == nil || y == nil {
+				return strings.Compare(a.Value, b.Value), true
+			}
+			return cmp.Compare(x.Number(), y.Number()), true
+		case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+			protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+			x, ok1 := new(big.Int).SetString(a.Value, 10)
+			y, ok2 := new(big.Int).SetString(b.Value, 10)
+			if ok1 && ok2 {
+				return x.Cmp(y), true
+			}
+		}
+		return strings.Compare(a.Value, b.Value), true
+	case *types.AttributeValueMemberBOOL:
+		b, ok := b.(*types.AttributeValueMemberBOOL)
+		if !ok || a.Value != b.Value {
+			return 1, ok
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// lookupPath returns the attribute at a path of attribute names, or nil if
+// there is none.
+func lookupPath(item map[string]types.AttributeValue, path []string) types.AttributeValue {
+	var v types.AttributeValue = &types.AttributeValueMemberM{Value: item}
+	for _, name := range path {
+		m, ok := v.(*types.AttributeValueMemberM)
+		if !ok {
+			return nil
+		}
+		if v = m.Value[name]; v == nil {
+			return nil
+		}
+	}
+	return v
+}
@@ -0,0 +1,152 @@
+package query_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/picatz/dynabuf/query"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestExpressionMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		filter string
+		exact  bool
+		match  []proto.Message
+		skip   []proto.Message
+	}{
+		{
+			name:   "enums are ordered by number",
+			query:  "state > STATE_RUNNING",
+			filter: "",
+			match:  []proto.Message{&testpb.Job{State: testpb.Job_STATE_FAILED}, &testpb.Job{State: testpb.Job_STATE_SUCCEEDED}},
+			skip:   []proto.Message{&testpb.Job{State: testpb.Job_STATE_RUNNING}, &testpb.Job{}},
+		},
+		{
+			name:   "64-bit integers are ordered by value",
+			query:  "positive_int_value BETWEEN 9 AND 10 AND identifier_value = x",
+			filter: "#n0 = :v0",
+			match: []proto.Message{
+				&descriptorpb.UninterpretedOption{PositiveIntValue: proto.Uint64(9), IdentifierValue: proto.String("x")},
+				&descriptorpb.UninterpretedOption{PositiveIntValue: proto.Uint64(10), IdentifierValue: proto.String("x")},
+			},
+			skip: []proto.Message{
+				&descriptorpb.UninterpretedOption{PositiveIntValue: proto.Uint64(100), IdentifierValue: proto.String("x")},
+				&descriptorpb.UninterpretedOption{PositiveIntValue: proto.Uint64(10), IdentifierValue: proto.String("y")},
+			},
+		},
+		{
+			name:   "zero values",
+			query:  "age = 0 OR NOT address.zip_code != 0",
+			filter: "(attribute_not_exists(#n0) OR #n0 = :v0) OR NOT (attribute_exists(#n1.#n2) AND #n1.#n2 <> :v1)",
+			exact:  true,
+			match:  []proto.Message{&testpb.User{}, &testpb.User{Age: 1}, &testpb.User{Age: 1, Address: &testpb.Address{}}},
+			skip:   []proto.Message{&testpb.User{Age: 1, Address: &testpb.Address{ZipCode: 1}}},
+		},
+		{
+			name:   "functions",
+			query:  "begins_with(name, Al) AND NOT contains(address.street, Main) AND exists(previous_addresses)",
+			filter: "begins_with(#n0, :v0) AND NOT contains(#n1.#n2, :v1) AND attribute_exists(#n3)",
+			exact:  true,
+			match:  []proto.Message{&testpb.User{Name: "Alice", PreviousAddresses: []*testpb.Address{{}}}},
+			skip: []proto.Message{
+				&testpb.User{Name: "Bob", PreviousAddresses: []*testpb.Address{{}}},
+				&testpb.User{Name: "Alice", Address: &testpb.Address{Street: "Main St"}, PreviousAddresses: []*testpb.Address{{}}},
+				&testpb.User{Name: "Alice"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			md := append(test.match, test.skip...)[0].ProtoReflect().Descriptor()
+
+			expr, err := query.Compile(md, test.query)
+			must.NoError(t, err)
+			must.Eq(t, test.filter, expr.Filter)
+			must.Eq(t, test.exact, expr.Exact())
+
+			for _, msg := range test.match {
+				ok, err := expr.Match(msg)
+				must.NoError(t, err)
+				must.True(t, ok, must.Sprintf("%v should match", msg))
+			}
+			for _, msg := range test.skip {
+				ok, err := expr.Match(msg)
+				must.NoError(t, err)
+				must.False(t, ok, must.Sprintf("%v should not match", msg))
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	ctx := context.Background()
+	client := newUsersTable(t, users...)
+
+	tests := []struct {
+		query string
+		exact bool
+		want  []string
+	}{
+		{query: "", exact: true, want: []string{"Alan", "Alice", "Bob", "Carol", "Dan"}},
+		{query: "id = 1 AND age > 0", exact: true, want: []string{"Alice", "Bob"}},
+		{query: "id = 1 AND name >= Alan AND name < B", want: []string{"Alan", "Alice"}},
+		{query: "age = 30 OR address.zip_code = 0", exact: true, want: []string{"Alice", "Bob", "Carol", "Dan"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			expr, err := query.Compile(userDescriptor, test.query, "id", "name")
+			must.NoError(t, err)
+			must.Eq(t, test.exact, expr.Exact())
+
+			var got []string
+			for user, err := range query.Read[*testpb.User](ctx, client, "users", expr) {
+				must.NoError(t, err)
+				got = append(got, user.GetName())
+
+				ok, err := expr.Match(user)
+				must.NoError(t, err)
+				must.True(t, ok)
+			}
+			slices.Sort(got)
+			must.Eq(t, test.want, got)
+		})
+	}
+}
+
+func TestReadLazy(t *testing.T) {
+	ctx := context.Background()
+	client := newUsersTable(t, users...)
+
+	// The partial expression is checked on the attributes of the items.
+	expr, err := query.Compile(userDescriptor, "id = 1 AND name >= Alan AND name < B", "id", "name")
+	must.NoError(t, err)
+	must.False(t, expr.Exact())
+
+	var got []string
+	for lazy, err := range query.ReadLazy[*testpb.User](ctx, client, "users", expr) {
+		must.NoError(t, err)
+		name := lazy.Item()["name"].(*types.AttributeValueMemberS).Value
+
+		user, err := lazy.Message()
+		must.NoError(t, err)
+		must.Eq(t, name, user.GetName())
+
+		again, err := lazy.Message()
+		must.NoError(t, err)
+		must.True(t, user == again)
+
+		got = append(got, name)
+	}
+	slices.Sort(got)
+	must.Eq(t, []string{"Alan", "Alice"}, got)
+}
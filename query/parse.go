@@ -0,0 +1,739 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// token is a single lexical token of a query.
+type token struct {
+	kind   string // "word", "string", "op", or "eof"
+	text   string
+	offset int
+}
+
+// lex splits a query into tokens. Words are runs of characters other than
+// spaces, quotes, parentheses, commas, and comparison operators, so paths,
+// numbers, enum values, and dates need no quoting.
+func lex(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, invalid(i, "unterminated string")
+			}
+			text, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, invalid(i, "invalid string %s", s[i:j+1])
+			}
+			toks = append(toks, token{kind: "string", text: text, offset: i})
+			i = j + 1
+		case strings.HasPrefix(s[i:], "!=") || strings.HasPrefix(s[i:], "<>") ||
+			strings.HasPrefix(s[i:], "<=") || strings.HasPrefix(s[i:], ">=") || strings.HasPrefix(s[i:], "=="):
+			toks = append(toks, token{kind: "op", text: s[i : i+2], offset: i})
+			i += 2
+		case strings.ContainsRune("=<>(),", c):
+			toks = append(toks, token{kind: "op", text: string(c), offset: i})
+			i++
+		default:
+			j := i
+			for j < len(s) && !unicode.IsSpace(rune(s[j])) && !strings.ContainsRune("\"=<>!(),", rune(s[j])) {
+				j++
+			}
+			if j == i {
+				return nil, invalid(i, "unexpected %q", c)
+			}
+			toks = append(toks, token{kind: "word", text: s[i:j], offset: i})
+			i = j
+		}
+	}
+	return append(toks, token{kind: "eof", offset: len(s)}), nil
+}
+
+// node is a parsed and validated query.
+type node interface {
+	// attributes returns the names of the top-level attributes the node
+	// refers to.
+	attributes() []string
+}
+
+// logical is a conjunction or disjunction of nodes.
+type logical struct {
+	op          string // "AND" or "OR"
+	left, right node
+}
+
+func (n *logical) attributes() []string {
+	return append(n.left.attributes(), n.right.attributes()...)
+}
+
+// not is a negated node.
+type not struct {
+	node node
+}
+
+func (n *not) attributes() []string { return n.node.attributes() }
+
+// comparison compares the attribute at a path with literal values.
+type comparison struct {
+	// path is the attribute names of the path.
+	path []string
+
+	// op is a DynamoDB comparator, "BETWEEN", "IN", or a function name.
+	op string
+
+	values []types.AttributeValue
+
+	// fd is the field the path refers to, or its value field for values of
+	// map fields.
+	fd protoreflect.FieldDescriptor
+
+	// client reports whether DynamoDB cannot evaluate the comparison, so
+	// it is only evaluated on decoded messages.
+	client bool
+
+	// missing reports whether items without the attribute match. Fields
+	// holding their zero value are not stored by [dynabuf.Marshal], so
+	// comparisons satisfied by the zero value match them.
+	missing bool
+}
+
+func (n *comparison) attributes() []string { return n.path[:1] }
+
+// field is the resolution of a path to the field it refers to.
+type field struct {
+	path []string
+	fd   protoreflect.FieldDescriptor
+
+	// value reports whether the path refers to a value of a map field
+	// rather than the map itself.
+	value bool
+}
+
+// isList reports whether the path refers to a repeated field.
+func (f field) isList() bool { return !f.value && f.fd.IsList() }
+
+// isMap reports whether the path refers to a map field.
+func (f field) isMap() bool { return !f.value && f.fd.IsMap() }
+
+// valueField returns the field describing the values the path refers to,
+// which is the value field of a map for map values.
+func (f field) valueField() protoreflect.FieldDescriptor {
+	if f.value {
+		return f.fd.MapValue()
+	}
+	return f.fd
+}
+
+// parser is a recursive descent parser of queries about messages of a
+// single type.
+type parser struct {
+	md   protoreflect.MessageDescriptor
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != "eof" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == "word" && strings.EqualFold(t.text, kw)
+}
+
+func (p *parser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == "op" && t.text == op
+}
+
+func (p *parser) expectOp(op string) error {
+	if t := p.next(); t.kind != "op" || t.text != op {
+		return unexpected(t, "expected %q", op)
+	}
+	return nil
+}
+
+func (p *parser) parse() (node, error) {
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != "eof" {
+		return nil, unexpected(t, "expected AND, OR, or end of query")
+	}
+	return n, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &not{node: n}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.isOp("(") {
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expectOp(")")
+	}
+
+	t := p.peek()
+	if t.kind == "word" && p.toks[p.pos+1].kind == "op" && p.toks[p.pos+1].text == "(" {
+		return p.parseFunction()
+	}
+
+	f, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkComparable(f, t); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.isKeyword("BETWEEN"):
+		p.next()
+		client, err := p.checkOrdered(f, t)
+		if err != nil {
+			return nil, err
+		}
+		lo, err := p.parseValue(f.valueField())
+		if err != nil {
+			return nil, err
+		}
+		if !p.isKeyword("AND") {
+			return nil, unexpected(p.peek(), "expected AND")
+		}
+		p.next()
+		hi, err := p.parseValue(f.valueField())
+		if err != nil {
+			return nil, err
+		}
+		return &comparison{path: f.path, fd: f.valueField(), op: "BETWEEN", values: []types.AttributeValue{lo, hi}, client: client}, nil
+
+	case p.isKeyword("IN"):
+		p.next()
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		c := &comparison{path: f.path, fd: f.valueField(), op: "IN"}
+		for {
+			v, err := p.parseValue(f.valueField())
+			if err != nil {
+				return nil, err
+			}
+			c.values = append(c.values, v)
+			c.missing = c.missing || isZero(f, v)
+			if !p.isOp(",") {
+				break
+			}
+			p.next()
+		}
+		if len(c.values) > 100 {
+			return nil, invalid(t.offset, "IN accepts at most 100 values")
+		}
+		return c, p.expectOp(")")
+	}
+
+	op := p.next()
+	if op.kind != "op" || op.text == "(" || op.text == ")" || op.text == "," {
+		return nil, unexpected(op, "expected a comparison operator")
+	}
+	switch op.text {
+	case "==":
+		op.text = "="
+	case "!=":
+		op.text = "<>"
+	}
+	return p.parseComparison(f, t, op.text)
+}
+
+// parseComparison parses the value f, named by token t, is compared with
+// using a DynamoDB comparator.
+func (p *parser) parseComparison(f field, t token, comparator string) (node, error) {
+	c := &comparison{path: f.path, fd: f.valueField(), op: comparator}
+	switch comparator {
+	case "<", "<=", ">", ">=":
+		var err error
+		if c.client, err = p.checkOrdered(f, t); err != nil {
+			return nil, err
+		}
+	}
+
+	v, err := p.parseValue(f.valueField())
+	if err != nil {
+		return nil, err
+	}
+	c.values = []types.AttributeValue{v}
+	switch comparator {
+	case "=":
+		c.missing = isZero(f, v)
+	case "<>":
+		c.missing = !isZero(f, v)
+	}
+	return c, nil
+}
+
+// checkComparable returns an error if f, named by token t, cannot be
+// compared with values.
+func checkComparable(f field, t token) error {
+	if f.isList() || f.isMap() || (f.valueField().Kind() == protoreflect.MessageKind && scalarField(f.valueField()) == nil) {
+		return invalid(t.offset, "field %q cannot be compared, only used with exists or contains", strings.Join(f.path, "."))
+	}
+	return nil
+}
+
+// parseFunction parses a call of exists, begins_with, or contains.
+func (p *parser) parseFunction() (node, error) {
+	name := p.next()
+	p.next()
+
+	f, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &comparison{path: f.path, fd: f.valueField(), op: strings.ToLower(name.text)}
+	switch c.op {
+	case "exists":
+		c.op = "attribute_exists"
+	case "begins_with":
+		if f.isList() || f.isMap() || !isTextual(f.valueField()) {
+			return nil, invalid(name.offset, "begins_with requires a string or timestamp field, got %q", strings.Join(f.path, "."))
+		}
+		if err := p.expectOp(","); err != nil {
+			return nil, err
+		}
+		// Prefixes of timestamps are partial times, such as "2024-01",
+		// so they are not parsed.
+		t := p.next()
+		if t.kind != "word" && t.kind != "string" {
+			return nil, unexpected(t, "expected a value")
+		}
+		c.values = []types.AttributeValue{&types.AttributeValueMemberS{Value: t.text}}
+	case "contains":
+		if err := p.expectOp(","); err != nil {
+			return nil, err
+		}
+		var fd protoreflect.FieldDescriptor
+		switch {
+		case f.isList():
+			fd = f.fd
+		case !f.isMap() && f.valueField().Kind() == protoreflect.StringKind && !isDecimal(f.valueField()):
+			fd = f.valueField()
+		default:
+			return nil, invalid(name.offset, "contains requires a string or repeated field, got %q", strings.Join(f.path, "."))
+		}
+		v, err := p.parseValue(fd)
+		if err != nil {
+			return nil, err
+		}
+		c.values = []types.AttributeValue{v}
+	default:
+		return nil, invalid(name.offset, "unknown function %q", name.text)
+	}
+
+	return c, p.expectOp(")")
+}
+
+// parsePath parses a dot separated path of field names, either as declared
+// or in their JSON form, and of keys of map fields.
+func (p *parser) parsePath() (field, error) {
+	t := p.next()
+	if t.kind != "word" {
+		return field{}, unexpected(t, "expected a field")
+	}
+
+	var (
+		f  field
+		md = p.md
+	)
+	for i, name := range strings.Split(t.text, ".") {
+		switch {
+		case name == "":
+			return field{}, invalid(t.offset, "invalid field %q", t.text)
+		case i > 0 && f.isMap():
+			if f.fd.MapKey().Kind() != protoreflect.StringKind {
+				return field{}, invalid(t.offset, "map field %q does not have string keys", strings.Join(f.path, "."))
+			}
+			f.path = append(f.path, name)
+			f.value = true
+			md = f.fd.MapValue().Message()
+			continue
+		case i > 0 && (md == nil || f.isList() || scalarField(f.valueField()) != nil):
+			return field{}, invalid(t.offset, "field %q has no fields", strings.Join(f.path, "."))
+		}
+
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(name)
+		}
+		if fd == nil {
+			return field{}, invalid(t.offset, "unknown field %q of %s", name, md.FullName())
+		}
+		f = field{path: append(f.path, fd.JSONName()), fd: fd}
+		md = fd.Message()
+	}
+
+	return f, nil
+}
+
+// checkOrdered returns an error if the values of f cannot be ordered, and
+// reports whether they must be ordered client-side because their attribute
+// values do not sort in the same order.
+func (p *parser) checkOrdered(f field, t token) (bool, error) {
+	fd := f.valueField()
+	if isSortable(fd) {
+		return false, nil
+	}
+	if s := scalarField(fd); s != nil {
+		fd = s
+	}
+	switch fd.Kind() {
+	case protoreflect.BoolKind, protoreflect.BytesKind:
+		return false, invalid(t.offset, "field %q cannot be ordered", t.text)
+	case protoreflect.EnumKind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return true, nil
+	}
+	return false, nil
+}
+
+// parseValue parses a literal value of the field, and returns its attribute
+// value as encoded by [dynabuf.Marshal].
+func (p *parser) parseValue(fd protoreflect.FieldDescriptor) (types.AttributeValue, error) {
+	t := p.next()
+	if t.kind != "word" && t.kind != "string" {
+		return nil, unexpected(t, "expected a value")
+	}
+	return literal(fd, t)
+}
+
+// literal returns the attribute value of the field's value written as the
+// token's text.
+func literal(fd protoreflect.FieldDescriptor, t token) (types.AttributeValue, error) {
+	if isSortable(fd) {
+		return sortableLiteral(fd, t)
+	}
+	if fd.Kind() == protoreflect.MessageKind {
+		switch fd.Message().FullName() {
+		case "google.type.Date":
+			if !datePattern.MatchString(t.text) {
+				return nil, invalid(t.offset, "invalid date %q, expected YYYY-MM-DD", t.text)
+			}
+			return &types.AttributeValueMemberS{Value: t.text}, nil
+		case "google.type.TimeOfDay":
+			return timeOfDayValue(t)
+		}
+	}
+	if s := scalarField(fd); s != nil {
+		if fd.Message().FullName() == "google.protobuf.Timestamp" {
+			return timestampValue(t)
+		}
+		fd = s
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		if isDecimal(fd) {
+			if !decimalPattern.MatchString(t.text) {
+				return nil, invalid(t.offset, "invalid decimal %q", t.text)
+			}
+			return &types.AttributeValueMemberN{Value: t.text}, nil
+		}
+		return &types.AttributeValueMemberS{Value: t.text}, nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(t.text)
+		if err != nil {
+			return nil, invalid(t.offset, "invalid bool %q", t.text)
+		}
+		return &types.AttributeValueMemberBOOL{Value: b}, nil
+	case protoreflect.EnumKind:
+		if fd.Enum().Values().ByName(protoreflect.Name(t.text)) == nil {
+			return nil, invalid(t.offset, "unknown value %q of enum %s", t.text, fd.Enum().FullName())
+		}
+		return &types.AttributeValueMemberS{Value: t.text}, nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if _, err := strconv.ParseInt(t.text, 10, 32); err != nil {
+			return nil, invalid(t.offset, "invalid 32-bit integer %q", t.text)
+		}
+		return &types.AttributeValueMemberN{Value: t.text}, nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if _, err := strconv.ParseUint(t.text, 10, 32); err != nil {
+			return nil, invalid(t.offset, "invalid 32-bit unsigned integer %q", t.text)
+		}
+		return &types.AttributeValueMemberN{Value: t.text}, nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, invalid(t.offset, "invalid 64-bit integer %q", t.text)
+		}
+		return &types.AttributeValueMemberS{Value: strconv.FormatInt(n, 10)}, nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(t.text, 10, 64)
+		if err != nil {
+			return nil, invalid(t.offset, "invalid 64-bit unsigned integer %q", t.text)
+		}
+		return &types.AttributeValueMemberS{Value: strconv.FormatUint(n, 10)}, nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, invalid(t.offset, "invalid number %q", t.text)
+		}
+		return &types.AttributeValueMemberN{Value: t.text}, nil
+	default:
+		return nil, invalid(t.offset, "fields of kind %s cannot be compared", fd.Kind())
+	}
+}
+
+// timestampValue parses an RFC 3339 time or date, and returns it formatted
+// as by protojson.
+func timestampValue(t token) (types.AttributeValue, error) {
+	ts, err := time.Parse(time.RFC3339Nano, t.text)
+	if err != nil {
+		ts, err = time.Parse(time.DateOnly, t.text)
+	}
+	if err != nil {
+		return nil, invalid(t.offset, "invalid time %q, expected an RFC 3339 time or date", t.text)
+	}
+
+	b, err := protojson.Marshal(timestamppb.New(ts))
+	if err != nil {
+		return nil, invalid(t.offset, "invalid time %q: %v", t.text, err)
+	}
+	return &types.AttributeValueMemberS{Value: strings.Trim(string(b), `"`)}, nil
+}
+
+// sortableLiteral returns the attribute value of the value of a sortable
+// field written as the token's text, which is its sortable string.
+func sortableLiteral(fd protoreflect.FieldDescriptor, t token) (types.AttributeValue, error) {
+	var (
+		v   any
+		err error
+	)
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var n int64
+		n, err = strconv.ParseInt(t.text, 10, 32)
+		v = int32(n)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err = strconv.ParseInt(t.text, 10, 64)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var n uint64
+		n, err = strconv.ParseUint(t.text, 10, 32)
+		v = uint32(n)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err = strconv.ParseUint(t.text, 10, 64)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		v, err = strconv.ParseFloat(t.text, 64)
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		v = t.text
+	case protoreflect.MessageKind:
+		var ts types.AttributeValue
+		if ts, err = timestampValue(t); err != nil {
+			return nil, err
+		}
+		v, err = time.Parse(time.RFC3339Nano, ts.(*types.AttributeValueMemberS).Value)
+	}
+	if err != nil {
+		return nil, invalid(t.offset, "invalid value %q of sortable field %s", t.text, fd.Name())
+	}
+
+	s, err := dynabuf.SortableString(v)
+	if err != nil {
+		return nil, invalid(t.offset, "invalid value %q of sortable field %s", t.text, fd.Name())
+	}
+	return &types.AttributeValueMemberS{Value: s}, nil
+}
+
+// timeOfDayValue parses a time of day, and returns it formatted as stored,
+// without trailing zeros in the fraction of a second.
+func timeOfDayValue(t token) (types.AttributeValue, error) {
+	if !timeOfDayPattern.MatchString(t.text) {
+		return nil, invalid(t.offset, "invalid time of day %q, expected HH:MM:SS", t.text)
+	}
+	s := t.text
+	if strings.Contains(s, ".") {
+		s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	}
+	return &types.AttributeValueMemberS{Value: s}, nil
+}
+
+// scalarField returns the field holding the value of a message field that
+// is stored as a scalar, or nil if fd is not such a field. Timestamps, and
+// dates and times of day, are held by the field itself.
+func scalarField(fd protoreflect.FieldDescriptor) protoreflect.FieldDescriptor {
+	if fd.Kind() != protoreflect.MessageKind {
+		return nil
+	}
+	md := fd.Message()
+	switch md.FullName() {
+	case "google.protobuf.Timestamp", "google.type.Date", "google.type.TimeOfDay":
+		return fd
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value",
+		"google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue":
+		return md.Fields().ByName("value")
+	}
+	return nil
+}
+
+// isTextual reports whether the values of fd are stored as strings that
+// can be matched by prefix.
+func isTextual(fd protoreflect.FieldDescriptor) bool {
+	if s := scalarField(fd); s != nil {
+		if s == fd {
+			return true
+		}
+		fd = s
+	}
+	return fd.Kind() == protoreflect.StringKind && !isDecimal(fd)
+}
+
+var (
+	// decimalPattern matches the JSON numbers held by decimal fields.
+	decimalPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+	// datePattern matches google.type.Date values, as stored.
+	datePattern = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+
+	// timeOfDayPattern matches google.type.TimeOfDay values.
+	timeOfDayPattern = regexp.MustCompile(`^[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]{1,9})?$`)
+)
+
+// isSortable reports whether the field is annotated as sortable, so its
+// values are stored as strings sorting as they do.
+func isSortable(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := proto.GetExtension(fd.Options(), dynabufpb.E_Field).(*dynabufpb.FieldOptions)
+	return ok && opts.GetSortable()
+}
+
+// isDecimal reports whether the string field is annotated as decimal, so
+// its values are stored as numbers.
+func isDecimal(fd protoreflect.FieldDescriptor) bool {
+	if fd.Kind() != protoreflect.StringKind {
+		return false
+	}
+	opts, ok := proto.GetExtension(fd.Options(), dynabufpb.E_Field).(*dynabufpb.FieldOptions)
+	return ok && opts.GetDecimal()
+}
+
+// isZero reports whether f has no presence and v is the attribute value of
+// its zero value.
+func isZero(f field, v types.AttributeValue) bool {
+	if f.value || f.fd.HasPresence() {
+		return false
+	}
+
+	fd := f.fd
+	if isSortable(fd) {
+		zero, err := sortableLiteral(fd, token{text: "0"})
+		s, ok := v.(*types.AttributeValueMemberS)
+		return ok && err == nil && zero.(*types.AttributeValueMemberS).Value == s.Value
+	}
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		switch fd.Kind() {
+		case protoreflect.EnumKind:
+			return v.Value == string(fd.Enum().Values().Get(0).Name())
+		case protoreflect.StringKind:
+			return v.Value == ""
+		}
+		return v.Value == "0"
+	case *types.AttributeValueMemberN:
+		if fd.Kind() == protoreflect.StringKind {
+			// Decimal fields hold "0" when set to zero.
+			return false
+		}
+		f, err := strconv.ParseFloat(v.Value, 64)
+		return err == nil && f == 0
+	case *types.AttributeValueMemberBOOL:
+		return !v.Value
+	}
+	return false
+}
+
+func invalid(offset int, format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalidQuery, fmt.Sprintf(format, args...), offset)
+}
+
+func unexpected(t token, format string, args ...any) error {
+	if t.kind == "eof" {
+		return invalid(t.offset, "%s, got end of query", fmt.Sprintf(format, args...))
+	}
+	return invalid(t.offset, "%s, got %q", fmt.Sprintf(format, args...), t.text)
+}
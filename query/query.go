@@ -0,0 +1,419 @@
+// Package query compiles a small filter language over protobuf messages to
+// DynamoDB key condition and filter expressions.
+//
+// Queries are meant to be accepted from users of admin tools and APIs, so
+// they are validated against the message descriptor, and values are only
+// ever sent to DynamoDB as expression attribute values:
+//
+//	status = ACTIVE AND create_time > 2024-01-01
+//	(age >= 18 OR NOT exists(address)) AND name != "Alice"
+//
+// # Syntax
+//
+// A query combines comparisons with AND, OR, NOT, and parentheses. Fields
+// are named as declared or in their JSON form, with nested fields and
+// string keys of map fields separated by dots, such as address.zip_code
+// or labels.env. The comparisons are:
+//
+//	field = value, field != value
+//	field < value, field <= value, field > value, field >= value
+//	field BETWEEN value AND value
+//	field IN (value, ...)
+//	begins_with(field, prefix)
+//	contains(field, value)
+//	exists(field)
+//
+// Values are parsed according to the field they are compared with, and
+// only need quoting when they contain spaces or operators. Enum values are
+// named, and timestamps are RFC 3339 times or dates. contains matches a
+// substring of a string field or an element of a repeated field, and
+// begins_with also matches prefixes of timestamps, such as 2024-01.
+//
+// Fields holding their zero value are not stored by [dynabuf.Marshal], so
+// comparing a field for equality with its zero value, with = or IN, also
+// matches items without it. Ordering comparisons only match stored values.
+//
+// # Reading
+//
+// Queries DynamoDB cannot evaluate exactly, such as orderings of enums or
+// of 64-bit integers, which are stored as strings, are compiled to
+// expressions matching more items than the query, and the decoded messages
+// are checked with [Expression.Match]. [Read] does both, so callers get
+// the same results whatever the query.
+//
+// # CEL
+//
+// Filters can also be written in the Common Expression Language and
+// compiled with [CompileCEL], which compiles what DynamoDB can evaluate
+// and evaluates the rest on decoded messages, so the same filter can be
+// used both server-side and client-side.
+//
+// # AIP-160
+//
+// APIs following Google's API Improvement Proposals accept filters of
+// List methods in the syntax of AIP-160, which [CompileAIP160] compiles
+// to the same expressions, so they can be read with [Read]:
+//
+//	state = RUNNING create_time > "2024-01-01T00:00:00Z"
+//	labels:env AND (name = "jobs/a*" OR -retries:*)
+//
+// A [Lister] reads them a page at a time, with the signed page tokens of
+// AIP-158.
+//
+// The package is a separate module, github.com/picatz/dynabuf/query, so
+// that programs not filtering items do not depend on the CEL libraries.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrInvalidQuery is returned when a query cannot be parsed, or does not
+// apply to the message it is compiled for.
+var ErrInvalidQuery = errors.New("query: invalid query")
+
+// Expression is a compiled query.
+type Expression struct {
+	// KeyCondition is the key condition expression of the query, or empty
+	// if the query does not compare the partition key for equality, in
+	// which case the table must be scanned.
+	KeyCondition string
+
+	// Filter is the filter expression of the query, or empty if every
+	// item matched by the key condition matches the query.
+	Filter string
+
+	// Names are the expression attribute names of the expressions.
+	Names map[string]string
+
+	// Values are the expression attribute values of the expressions.
+	Values map[string]types.AttributeValue
+
+	// partial reports whether the expressions also match items that do
+	// not match the query.
+	partial bool
+
+	// match reports whether a message matches the query, or is nil if
+	// every message does.
+	match func(proto.Message) (bool, error)
+
+	// matchItem reports whether an item matches the query, or is nil if
+	// the query can only be evaluated on messages.
+	matchItem func(map[string]types.AttributeValue) bool
+}
+
+// Compile parses a query about messages described by md and compiles it to
+// DynamoDB expressions, returning an error wrapping [ErrInvalidQuery] if it
+// is not valid.
+//
+// keys are the names of the partition key and, optionally, the sort key
+// attributes of the table or index being read. If the query requires the
+// partition key to be equal to a value, that comparison and a comparison of
+// the sort key become the key condition, so the query can be read with a
+// Query rather than a Scan.
+//
+// Comparisons DynamoDB cannot evaluate, such as orderings of enums and
+// 64-bit integers, or further comparisons of key attributes, are left to
+// [Expression.Match], and the expression is not [Expression.Exact].
+//
+// # Example
+//
+//	expr, err := query.Compile((&example.Order{}).ProtoReflect().Descriptor(), r.URL.Query().Get("filter"), "customerId", "createTime")
+//	if err != nil {
+//	  http.Error(w, err.Error(), http.StatusBadRequest)
+//	  return
+//	}
+//
+//	for order, err := range query.Read[*example.Order](ctx, client, "orders", expr) {
+//	  ...
+//	}
+func Compile(md protoreflect.MessageDescriptor, query string, keys ...string) (*Expression, error) {
+	if len(keys) > 2 {
+		return nil, fmt.Errorf("query: expected a partition key and an optional sort key, got %d keys", len(keys))
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return &Expression{}, nil
+	}
+
+	toks, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	n, err := (&parser{md: md, toks: toks}).parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return compile(n, keys), nil
+}
+
+// compile compiles a parsed query to expressions, leaving comparisons
+// DynamoDB cannot evaluate to [Expression.Match].
+func compile(n node, keys []string) *Expression {
+	conds := conjuncts(n)
+	server := slices.DeleteFunc(slices.Clone(conds), isClient)
+
+	expr, rest := build(server, keys)
+	expr.partial = len(server) < len(conds) || len(rest) > 0
+	expr.match = func(msg proto.Message) (bool, error) {
+		item, err := dynabuf.Marshal(msg)
+		if err != nil {
+			return false, fmt.Errorf("query: failed to encode %s: %w", msg.ProtoReflect().Descriptor().FullName(), err)
+		}
+		return eval(n, item.(map[string]types.AttributeValue)), nil
+	}
+	expr.matchItem = func(item map[string]types.AttributeValue) bool {
+		return eval(n, item)
+	}
+
+	return expr
+}
+
+// Exact reports whether the expressions match exactly the items matching
+// the query, so they need not be checked with [Expression.Match].
+func (e *Expression) Exact() bool { return !e.partial }
+
+// Match reports whether msg matches the query, evaluating it as a whole on
+// the message, with the same meaning as DynamoDB gives the expressions.
+func (e *Expression) Match(msg proto.Message) (bool, error) {
+	if e.match == nil {
+		return true, nil
+	}
+	return e.match(msg)
+}
+
+// matchLazy reports whether item matches the query, evaluating it on the
+// item if possible, or on its message, returned by decode, otherwise.
+func (e *Expression) matchLazy(item map[string]types.AttributeValue, decode func() (proto.Message, error)) (bool, error) {
+	if e.match == nil {
+		return true, nil
+	}
+	if e.matchItem != nil {
+		return e.matchItem(item), nil
+	}
+	msg, err := decode()
+	if err != nil {
+		return false, err
+	}
+	return e.match(msg)
+}
+
+// build compiles the conjuncts of a query to expressions. If the partition
+// key is compared for equality, the key comparisons become the key
+// condition, and the other conjuncts referring to key attributes are
+// returned rather than compiled, since queries cannot filter on them.
+func build(conds []node, keys []string) (*Expression, []node) {
+	var keyConds, rest []node
+	if len(keys) > 0 {
+		if i := slices.IndexFunc(conds, isKeyCondition(keys[0], "=")); i >= 0 {
+			keyConds = append(keyConds, conds[i])
+			conds = slices.Delete(conds, i, i+1)
+
+			if len(keys) > 1 {
+				if i := slices.IndexFunc(conds, isKeyCondition(keys[1], "=", "<", "<=", ">", ">=", "BETWEEN", "begins_with")); i >= 0 {
+					keyConds = append(keyConds, conds[i])
+					conds = slices.Delete(conds, i, i+1)
+				}
+			}
+
+			conds = slices.DeleteFunc(conds, func(c node) bool {
+				if slices.ContainsFunc(c.attributes(), func(name string) bool { return slices.Contains(keys, name) }) {
+					rest = append(rest, c)
+					return true
+				}
+				return false
+			})
+		}
+	}
+
+	expr := &Expression{}
+	e := &emitter{expr: expr}
+	expr.KeyCondition = e.join(keyConds, true)
+	expr.Filter = e.join(conds, false)
+
+	return expr, rest
+}
+
+// QueryInput returns the input of a Query of the table reading the items
+// matching the expression.
+func (e *Expression) QueryInput(table string) *dynamodb.QueryInput {
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(table),
+		KeyConditionExpression:    aws.String(e.KeyCondition),
+		ExpressionAttributeNames:  e.Names,
+		ExpressionAttributeValues: e.Values,
+	}
+	if e.Filter != "" {
+		input.FilterExpression = aws.String(e.Filter)
+	}
+	return input
+}
+
+// ScanInput returns the input of a Scan of the table reading the items
+// matching the expression, including its key condition as a filter.
+func (e *Expression) ScanInput(table string) *dynamodb.ScanInput {
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String(table),
+		ExpressionAttributeNames:  e.Names,
+		ExpressionAttributeValues: e.Values,
+	}
+
+	var filters []string
+	for _, f := range []string{e.KeyCondition, e.Filter} {
+		if f != "" {
+			filters = append(filters, f)
+		}
+	}
+	if len(filters) > 0 {
+		input.FilterExpression = aws.String(strings.Join(filters, " AND "))
+	}
+	return input
+}
+
+// conjuncts returns the nodes of the top-level conjunction of n.
+func conjuncts(n node) []node {
+	if l, ok := n.(*logical); ok && l.op == "AND" {
+		return append(conjuncts(l.left), conjuncts(l.right)...)
+	}
+	return []node{n}
+}
+
+// isClient reports whether a node includes comparisons DynamoDB cannot
+// evaluate.
+func isClient(n node) bool {
+	switch n := n.(type) {
+	case *logical:
+		return isClient(n.left) || isClient(n.right)
+	case *not:
+		return isClient(n.node)
+	}
+	return n.(*comparison).client
+}
+
+// isKeyCondition returns a function reporting whether a node compares the
+// named key attribute using one of ops.
+func isKeyCondition(name string, ops ...string) func(node) bool {
+	return func(n node) bool {
+		c, ok := n.(*comparison)
+		return ok && len(c.path) == 1 && c.path[0] == name && slices.Contains(ops, c.op)
+	}
+}
+
+// emitter renders nodes as DynamoDB expressions, collecting their names and
+// values.
+type emitter struct {
+	expr *Expression
+}
+
+// join renders nodes joined by AND. Key conditions are rendered as they
+// are, since key attributes are always stored.
+func (e *emitter) join(nodes []node, key bool) string {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = e.render(n, key, len(nodes) > 1)
+	}
+	return strings.Join(parts, " AND ")
+}
+
+func (e *emitter) render(n node, key, nested bool) string {
+	switch n := n.(type) {
+	case *logical:
+		// Operands combined with a different operator are parenthesized, so
+		// the expression does not depend on precedence.
+		s := e.render(n.left, key, !sameOp(n.left, n.op)) + " " + n.op + " " + e.render(n.right, key, !sameOp(n.right, n.op))
+		if nested {
+			s = "(" + s + ")"
+		}
+		return s
+	case *not:
+		return "NOT " + e.render(n.node, key, true)
+	}
+
+	c := n.(*comparison)
+	path := e.path(c.path)
+
+	switch c.op {
+	case "attribute_exists":
+		return "attribute_exists(" + path + ")"
+	case "begins_with", "contains":
+		return c.op + "(" + path + ", " + e.value(c.values[0]) + ")"
+	case "BETWEEN":
+		return path + " BETWEEN " + e.value(c.values[0]) + " AND " + e.value(c.values[1])
+	case "IN":
+		values := make([]string, len(c.values))
+		for i, v := range c.values {
+			values[i] = e.value(v)
+		}
+		return e.missing(c, key, path+" IN ("+strings.Join(values, ", ")+")")
+	}
+
+	return e.missing(c, key, path+" "+c.op+" "+e.value(c.values[0]))
+}
+
+// missing returns the rendered comparison s, changed to match items without
+// the attribute only if c does. DynamoDB comparisons of missing attributes
+// are false, except <> which is true. Key attributes are always stored.
+func (e *emitter) missing(c *comparison, key bool, s string) string {
+	switch {
+	case key:
+		return s
+	case c.missing && c.op != "<>":
+		return "(attribute_not_exists(" + e.path(c.path) + ") OR " + s + ")"
+	case !c.missing && c.op == "<>":
+		return "(attribute_exists(" + e.path(c.path) + ") AND " + s + ")"
+	}
+	return s
+}
+
+// sameOp reports whether n combines nodes with op.
+func sameOp(n node, op string) bool {
+	l, ok := n.(*logical)
+	return ok && l.op == op
+}
+
+// path returns the document path of the attribute names, using expression
+// attribute names for every element.
+func (e *emitter) path(names []string) string {
+	if e.expr.Names == nil {
+		e.expr.Names = map[string]string{}
+	}
+
+	elems := make([]string, len(names))
+	for i, name := range names {
+		placeholder := ""
+		for p, n := range e.expr.Names {
+			if n == name {
+				placeholder = p
+				break
+			}
+		}
+		if placeholder == "" {
+			placeholder = fmt.Sprintf("#n%d", len(e.expr.Names))
+			e.expr.Names[placeholder] = name
+		}
+		elems[i] = placeholder
+	}
+	return strings.Join(elems, ".")
+}
+
+// value returns the placeholder of a new expression attribute value.
+func (e *emitter) value(v types.AttributeValue) string {
+	if e.expr.Values == nil {
+		e.expr.Values = map[string]types.AttributeValue{}
+	}
+
+	placeholder := fmt.Sprintf(":v%d", len(e.expr.Values))
+	e.expr.Values[placeholder] = v
+	return placeholder
+}
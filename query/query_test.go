@@ -0,0 +1,310 @@
+package query_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/picatz/dynabuf/query"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var userDescriptor = (&testpb.User{}).ProtoReflect().Descriptor()
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		keys         []string
+		keyCondition string
+		filter       string
+		values       map[string]types.AttributeValue
+	}{
+		{
+			name:   "empty",
+			query:  "  ",
+			filter: "",
+		},
+		{
+			name:   "comparison",
+			query:  "name = Alice",
+			filter: "#n0 = :v0",
+			values: map[string]types.AttributeValue{
+				":v0": &types.AttributeValueMemberS{Value: "Alice"},
+			},
+		},
+		{
+			name:   "nested fields by JSON name",
+			query:  `address.zipCode >= 10000 AND address.street != "Main St"`,
+			filter: "#n0.#n1 >= :v0 AND #n0.#n2 <> :v1",
+			values: map[string]types.AttributeValue{
+				":v0": &types.AttributeValueMemberN{Value: "10000"},
+				":v1": &types.AttributeValueMemberS{Value: "Main St"},
+			},
+		},
+		{
+			name:   "precedence",
+			query:  "name = a OR name = b AND NOT (age < 3 OR age > 9)",
+			filter: "#n0 = :v0 OR (#n0 = :v1 AND NOT (#n1 < :v2 OR #n1 > :v3))",
+		},
+		{
+			name:   "parentheses",
+			query:  "name = x OR ((name = a OR name = b) AND age = 1)",
+			filter: "#n0 = :v0 OR ((#n0 = :v1 OR #n0 = :v2) AND #n1 = :v3)",
+		},
+		{
+			name:   "timestamps",
+			query:  "create_time BETWEEN 2024-01-01 AND 2024-06-30T12:00:00+02:00",
+			filter: "#n0 BETWEEN :v0 AND :v1",
+			values: map[string]types.AttributeValue{
+				":v0": &types.AttributeValueMemberS{Value: "2024-01-01T00:00:00Z"},
+				":v1": &types.AttributeValueMemberS{Value: "2024-06-30T10:00:00Z"},
+			},
+		},
+		{
+			name:   "functions",
+			query:  "begins_with(name, Al) AND contains(address.street, Main) OR exists(previous_addresses)",
+			filter: "(begins_with(#n0, :v0) AND contains(#n1.#n2, :v1)) OR attribute_exists(#n3)",
+		},
+		{
+			name:   "zero values",
+			query:  "age = 0 AND name != \"\"",
+			filter: "(attribute_not_exists(#n0) OR #n0 = :v0) AND (attribute_exists(#n1) AND #n1 <> :v1)",
+		},
+		{
+			name:         "key conditions",
+			query:        "age > 3 AND id = 1 AND begins_with(name, A)",
+			keys:         []string{"id", "name"},
+			keyCondition: "#n0 = :v0 AND begins_with(#n1, :v1)",
+			filter:       "#n2 > :v2",
+		},
+		{
+			name:   "scans without partition key equality",
+			query:  "id = 1 OR name = Alice",
+			keys:   []string{"id", "name"},
+			filter: "#n0 = :v0 OR #n1 = :v1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expr, err := query.Compile(userDescriptor, test.query, test.keys...)
+			must.NoError(t, err)
+			must.Eq(t, test.keyCondition, expr.KeyCondition)
+			must.Eq(t, test.filter, expr.Filter)
+			if test.values != nil {
+				must.Eq(t, test.values, expr.Values)
+			}
+		})
+	}
+}
+
+func TestCompileEnums(t *testing.T) {
+	md := (&testpb.Job{}).ProtoReflect().Descriptor()
+
+	expr, err := query.Compile(md, "state IN (STATE_RUNNING, STATE_FAILED)")
+	must.NoError(t, err)
+	must.Eq(t, "#n0 IN (:v0, :v1)", expr.Filter)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "STATE_FAILED"}, expr.Values[":v1"])
+
+	expr, err = query.Compile(md, "state = STATE_UNSPECIFIED")
+	must.NoError(t, err)
+	must.Eq(t, "(attribute_not_exists(#n0) OR #n0 = :v0)", expr.Filter)
+
+	_, err = query.Compile(md, "state = RUNNING")
+	must.ErrorIs(t, err, query.ErrInvalidQuery)
+	must.StrContains(t, err.Error(), `unknown value "RUNNING" of enum dynabuf.test.v1.Job.State`)
+}
+
+func TestCompileDecimals(t *testing.T) {
+	md := (&testpb.Product{}).ProtoReflect().Descriptor()
+
+	expr, err := query.Compile(md, "price >= 9.99 AND price < 0")
+	must.NoError(t, err)
+	must.Eq(t, "#n0 >= :v0 AND #n0 < :v1", expr.Filter)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberN{Value: "9.99"}, expr.Values[":v0"])
+
+	_, err = query.Compile(md, "price = 9,99")
+	must.ErrorIs(t, err, query.ErrInvalidQuery)
+
+	_, err = query.Compile(md, "price = abc")
+	must.ErrorIs(t, err, query.ErrInvalidQuery)
+	must.StrContains(t, err.Error(), `invalid decimal "abc"`)
+}
+
+func TestCompileGoogleTypes(t *testing.T) {
+	md := (&testpb.Invoice{}).ProtoReflect().Descriptor()
+
+	expr, err := query.Compile(md, `due_date >= "2024-03-01" AND due_time < "17:30:00.500"`)
+	must.NoError(t, err)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "2024-03-01"}, expr.Values[":v0"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "17:30:00.5"}, expr.Values[":v1"])
+
+	_, err = query.Compile(md, `due_date = "March 1"`)
+	must.ErrorIs(t, err, query.ErrInvalidQuery)
+	must.StrContains(t, err.Error(), "expected YYYY-MM-DD")
+
+	_, err = query.Compile(md, "due_date.year = 2024")
+	must.ErrorIs(t, err, query.ErrInvalidQuery)
+}
+
+func TestCompileSortable(t *testing.T) {
+	md := (&testpb.Entry{}).ProtoReflect().Descriptor()
+
+	expr, err := query.Compile(md, `sequence BETWEEN -5 AND 5 AND time > "2024-03-01"`)
+	must.NoError(t, err)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "-9999999999999999995"}, expr.Values[":v0"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "0000000000000000005"}, expr.Values[":v1"])
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "001709251200.000000000"}, expr.Values[":v2"])
+
+	expr, err = query.Compile(md, "priority = 0")
+	must.NoError(t, err)
+	must.Eq(t, "(attribute_not_exists(#n0) OR #n0 = :v0)", expr.Filter)
+
+	_, err = query.Compile(md, "request_id = abc")
+	must.ErrorIs(t, err, query.ErrInvalidQuery)
+	must.StrContains(t, err.Error(), `invalid value "abc" of sortable field request_id`)
+}
+
+func TestCompileInvalid(t *testing.T) {
+	tests := []struct {
+		query string
+		keys  []string
+		err   string
+	}{
+		{query: "email = a", err: `unknown field "email" of dynabuf.test.v1.User at offset 0`},
+		{query: "age = old", err: `invalid 32-bit integer "old" at offset 6`},
+		{query: "name = a AND", err: "expected a field, got end of query"},
+		{query: "name = a b", err: `expected AND, OR, or end of query, got "b"`},
+		{query: "(name = a", err: `expected ")", got end of query`},
+		{query: `name = "a`, err: "unterminated string"},
+		{query: "name ! a", err: `unexpected '!'`},
+		{query: "address = x", err: `field "address" cannot be compared`},
+		{query: "previous_addresses = x", err: `field "previousAddresses" cannot be compared`},
+		{query: "name.first = x", err: `field "name" has no fields`},
+		{query: "create_time > yesterday", err: `invalid time "yesterday"`},
+		{query: "begins_with(age, 1)", err: "begins_with requires a string or timestamp field"},
+		{query: "contains(age, 1)", err: "contains requires a string or repeated field"},
+		{query: "contains(previous_addresses, x)", err: "fields of kind message cannot be compared"},
+		{query: "matches(name, a)", err: `unknown function "matches"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			_, err := query.Compile(userDescriptor, test.query, test.keys...)
+			must.ErrorIs(t, err, query.ErrInvalidQuery)
+			must.StrContains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestCompileRepeatedKey(t *testing.T) {
+	// Further comparisons of key attributes, which DynamoDB rejects, are
+	// left to Match rather than failing to compile.
+	expr, err := query.Compile(userDescriptor, "id = 1 AND id = 2", "id")
+	must.NoError(t, err)
+	must.NotEq(t, "", expr.KeyCondition)
+	must.False(t, expr.Exact())
+
+	for _, id := range []string{"1", "2"} {
+		ok, err := expr.Match(&testpb.User{Id: id})
+		must.NoError(t, err)
+		must.False(t, ok)
+	}
+}
+
+// newUsersTable returns a client with a users table keyed by id and name,
+// holding the given users.
+func newUsersTable(t *testing.T, users ...*testpb.User) *dynamotest.Client {
+	t.Helper()
+	ctx := context.Background()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("users"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("name"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	for _, user := range users {
+		_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String("users"),
+			Item:      dynabuf.MustMarshalItem(user),
+		})
+		must.NoError(t, err)
+	}
+
+	return client
+}
+
+var created = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+var users = []*testpb.User{
+	{Id: "1", Name: "Alice", Age: 30, CreateTime: timestamppb.New(created)},
+	{Id: "1", Name: "Alan", Address: &testpb.Address{ZipCode: 12345}},
+	{Id: "1", Name: "Bob", Age: 40, PreviousAddresses: []*testpb.Address{{Street: "Main St"}, {ZipCode: 1}}},
+	{Id: "2", Name: "Carol", Age: 30, CreateTime: timestamppb.New(created.AddDate(1, 0, 0))},
+	{Id: "2", Name: "Dan", Address: &testpb.Address{}},
+}
+
+func TestExpressionInputs(t *testing.T) {
+	ctx := context.Background()
+	client := newUsersTable(t, users...)
+
+	names := func(items []map[string]types.AttributeValue) []string {
+		var names []string
+		for _, item := range items {
+			var user testpb.User
+			dynabuf.MustUnmarshal(item, &user)
+			names = append(names, user.GetName())
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "id = 1 AND begins_with(name, Al)", want: []string{"Alan", "Alice"}},
+		{query: "id = 1 AND age = 0", want: []string{"Alan"}},
+		{query: "id = 1 AND address.zip_code != 0", want: []string{"Alan"}},
+		{query: "age = 30 AND create_time < 2025-01-01", want: []string{"Alice"}},
+		{query: "begins_with(create_time, 2025)", want: []string{"Carol"}},
+		{query: "NOT exists(create_time) AND age IN (0, 40)", want: []string{"Alan", "Bob", "Dan"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			expr, err := query.Compile(userDescriptor, test.query, "id", "name")
+			must.NoError(t, err)
+
+			if expr.KeyCondition != "" {
+				out, err := client.Query(ctx, expr.QueryInput("users"))
+				must.NoError(t, err)
+				must.Eq(t, test.want, names(out.Items))
+			}
+
+			out, err := client.Scan(ctx, expr.ScanInput("users"))
+			must.NoError(t, err)
+			must.Eq(t, test.want, names(out.Items))
+		})
+	}
+}
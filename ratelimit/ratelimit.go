@@ -0,0 +1,156 @@
+// Package ratelimit implements a distributed token bucket rate limiter
+// backed by DynamoDB items.
+//
+// Each rate limited key is a single [Bucket] item. Taking a token reads the
+// bucket, refills it based on the time elapsed since it was last updated,
+// and writes it back conditioned on nobody else having updated it in the
+// meantime, so concurrent callers across processes share the same budget.
+//
+// # Table Schema
+//
+// The table must use a string partition key named "key":
+//
+//	KeySchema: []types.KeySchemaElement{
+//	  {
+//	    AttributeName: aws.String("key"),
+//	    KeyType:       types.KeyTypeHash,
+//	  },
+//	},
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxAttempts is the number of times [Limiter.Allow] retries after losing
+// a race with a concurrent caller for the same key.
+const maxAttempts = 5
+
+// ErrContention is returned when a token could not be taken because the
+// bucket was repeatedly updated by concurrent callers.
+var ErrContention = errors.New("ratelimit: too much contention on bucket")
+
+// Client is the subset of the DynamoDB API used by a [Limiter].
+type Client interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// Limiter is a token bucket rate limiter storing its buckets in a DynamoDB
+// table.
+//
+// A Limiter is safe for concurrent use.
+type Limiter struct {
+	client Client
+	table  string
+	cfg    *dynabuf.Config
+	rate   float64
+	burst  float64
+}
+
+// New returns a [Limiter] storing buckets in the given table, sending its
+// requests with the configuration of opts. Buckets hold at most burst
+// tokens, and refill at rate tokens per second.
+func New(client Client, table string, rate float64, burst int, opts ...dynabuf.ConfigOption) *Limiter {
+	return &Limiter{
+		client: client,
+		table:  table,
+		cfg:    dynabuf.NewConfig(opts...),
+		rate:   rate,
+		burst:  float64(burst),
+	}
+}
+
+// Allow reports whether a token could be taken from the bucket for key,
+// consuming it if so. New keys start with a full bucket.
+func (l *Limiter) Allow(ctx context.Context, key string) (bool, error) {
+	for range maxAttempts {
+		allowed, err := l.take(ctx, key)
+		if err != nil {
+			var ccf *types.ConditionalCheckFailedException
+			if errors.As(err, &ccf) {
+				continue
+			}
+			return false, err
+		}
+		return allowed, nil
+	}
+
+	return false, fmt.Errorf("%w: %q", ErrContention, key)
+}
+
+// take makes a single attempt at taking a token from the bucket for key.
+func (l *Limiter) take(ctx context.Context, key string) (bool, error) {
+	ctx = dynabuf.ContextWithMessageType(ctx, &Bucket{})
+
+	out, err := dynabuf.Do(ctx, l.cfg, l.client.GetItem, &dynamodb.GetItemInput{
+		TableName: aws.String(l.table),
+		Key: map[string]types.AttributeValue{
+			"key": &types.AttributeValueMemberS{Value: key},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return false, fmt.Errorf("ratelimit: failed to get bucket %q: %w", key, err)
+	}
+
+	now := l.cfg.Now()
+
+	bucket := &Bucket{
+		Key:    key,
+		Tokens: l.burst,
+	}
+	if len(out.Item) > 0 {
+		if err := dynabuf.Unmarshal(out.Item, bucket); err != nil {
+			return false, fmt.Errorf("ratelimit: failed to decode bucket %q: %w", key, err)
+		}
+		elapsed := now.Sub(bucket.GetUpdateTime().AsTime()).Seconds()
+		bucket.Tokens = math.Min(l.burst, bucket.GetTokens()+math.Max(0, elapsed)*l.rate)
+	}
+
+	if bucket.GetTokens() < 1 {
+		return false, nil
+	}
+
+	bucket.Tokens--
+	bucket.UpdateTime = timestamppb.New(now)
+
+	item, err := dynabuf.Marshal(bucket)
+	if err != nil {
+		return false, fmt.Errorf("ratelimit: failed to encode bucket %q: %w", key, err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(l.table),
+		Item:      item.(map[string]types.AttributeValue),
+	}
+
+	// Only write the bucket back if it is exactly as we read it.
+	if updateTime, ok := out.Item["updateTime"]; ok {
+		input.ConditionExpression = aws.String("#updateTime = :updateTime")
+		input.ExpressionAttributeNames = map[string]string{"#updateTime": "updateTime"}
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{":updateTime": updateTime}
+	} else {
+		input.ConditionExpression = aws.String("attribute_not_exists(#key)")
+		input.ExpressionAttributeNames = map[string]string{"#key": "key"}
+	}
+
+	if _, err := dynabuf.Do(ctx, l.cfg, l.client.PutItem, input); err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return false, err
+		}
+		return false, fmt.Errorf("ratelimit: failed to write bucket %q: %w", key, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,109 @@
+package ratelimit_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/ratelimit"
+	"github.com/shoenig/test/must"
+)
+
+func newClient(t *testing.T) *dynamotest.Client {
+	t.Helper()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("buckets"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("key"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	return client
+}
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		check func(t *testing.T, limiter *ratelimit.Limiter)
+	}{
+		{
+			name:  "allows up to burst",
+			rate:  0.001,
+			burst: 2,
+			check: func(t *testing.T, limiter *ratelimit.Limiter) {
+				for _, want := range []bool{true, true, false, false} {
+					allowed, err := limiter.Allow(ctx, "alice")
+					must.NoError(t, err)
+					must.Eq(t, want, allowed)
+				}
+
+				allowed, err := limiter.Allow(ctx, "bob")
+				must.NoError(t, err)
+				must.True(t, allowed)
+			},
+		},
+		{
+			name:  "refills over time",
+			rate:  100,
+			burst: 1,
+			check: func(t *testing.T, limiter *ratelimit.Limiter) {
+				allowed, err := limiter.Allow(ctx, "alice")
+				must.NoError(t, err)
+				must.True(t, allowed)
+
+				time.Sleep(20 * time.Millisecond)
+
+				allowed, err = limiter.Allow(ctx, "alice")
+				must.NoError(t, err)
+				must.True(t, allowed)
+			},
+		},
+		{
+			name:  "concurrent callers share the bucket",
+			rate:  0.001,
+			burst: 5,
+			check: func(t *testing.T, limiter *ratelimit.Limiter) {
+				var (
+					wg      sync.WaitGroup
+					allowed atomic.Int64
+				)
+
+				for range 10 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						ok, err := limiter.Allow(ctx, "alice")
+						if err == nil && ok {
+							allowed.Add(1)
+						}
+					}()
+				}
+				wg.Wait()
+
+				must.LessEq(t, 5, allowed.Load())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.check(t, ratelimit.New(newClient(t), "buckets", test.rate, test.burst))
+		})
+	}
+}
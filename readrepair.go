@@ -0,0 +1,112 @@
+package dynabuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// PutItemAPIClient is the subset of the DynamoDB API used by [ReadRepair].
+type PutItemAPIClient interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// ReadRepair writes msg back to the table if item, the stored form it was
+// decoded from with [Unmarshal], differs from the form [Marshal] writes
+// today. It reports whether the item was rewritten.
+//
+// Items written by older versions of a message, or by other writers, often
+// decode successfully while being stored differently than they would be now,
+// such as enums stored by number, numbers stored as strings, or fields that
+// were explicitly stored with their default value. Calling ReadRepair after
+// reads gradually upgrades a table in place, without a separate backfill.
+//
+// The write is conditional on the stored item being unchanged since it was
+// read, so concurrent writes are never overwritten. If the item changed, it
+// is left as is and ReadRepair reports false without an error.
+//
+// # Example
+//
+//	out, _ := client.GetItem(ctx, input)
+//
+//	var user example.User
+//	_ = dynabuf.Unmarshal(out.Item, &user)
+//
+//	repaired, err := dynabuf.ReadRepair(ctx, client, "users", out.Item, &user)
+func ReadRepair(ctx context.Context, client PutItemAPIClient, table string, item map[string]types.AttributeValue, msg proto.Message) (bool, error) {
+	current, err := marshalProtoMessage(msg)
+	if err != nil {
+		return false, err
+	}
+
+	if reflect.DeepEqual(item, current) {
+		return false, nil
+	}
+
+	condition, names, values := unchangedItemCondition(item, msg)
+
+	_, err = client.PutItem(ContextWithMessage(ctx, msg), &dynamodb.PutItemInput{
+		TableName:                 aws.String(table),
+		Item:                      current,
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return false, nil
+		}
+		return false, fmt.Errorf("dynabuf: failed to repair item: %w", err)
+	}
+
+	return true, nil
+}
+
+// unchangedItemCondition returns a condition expression that only holds if
+// the stored item still has every attribute of item, and none of the
+// message's other fields.
+func unchangedItemCondition(item map[string]types.AttributeValue, msg proto.Message) (string, map[string]string, map[string]types.AttributeValue) {
+	var (
+		condition string
+		names     = map[string]string{}
+		values    = map[string]types.AttributeValue{}
+	)
+
+	and := func(clause string) {
+		if condition != "" {
+			condition += " AND "
+		}
+		condition += clause
+	}
+
+	for i, name := range slices.Sorted(maps.Keys(item)) {
+		names[fmt.Sprintf("#a%d", i)] = name
+		values[fmt.Sprintf(":a%d", i)] = item[name]
+		and(fmt.Sprintf("#a%d = :a%d", i, i))
+	}
+
+	fields := msg.ProtoReflect().Descriptor().Fields()
+	for i := range fields.Len() {
+		name := fields.Get(i).JSONName()
+		if _, ok := item[name]; ok {
+			continue
+		}
+		names[fmt.Sprintf("#f%d", i)] = name
+		and(fmt.Sprintf("attribute_not_exists(#f%d)", i))
+	}
+
+	if len(values) == 0 {
+		values = nil
+	}
+
+	return condition, names, values
+}
@@ -0,0 +1,84 @@
+package dynabuf_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestReadRepair(t *testing.T) {
+	ctx := context.Background()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("jobs"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	key := map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberS{Value: "1"},
+	}
+
+	get := func() (map[string]types.AttributeValue, *testpb.Job) {
+		out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String("jobs"),
+			Key:       key,
+		})
+		must.NoError(t, err)
+
+		job := &testpb.Job{}
+		must.NoError(t, dynabuf.Unmarshal(out.Item, job))
+		return out.Item, job
+	}
+
+	// An old writer stored the enum by number.
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String("jobs"),
+		Item: map[string]types.AttributeValue{
+			"id":    &types.AttributeValueMemberS{Value: "1"},
+			"state": &types.AttributeValueMemberN{Value: "1"},
+		},
+	})
+	must.NoError(t, err)
+
+	item, job := get()
+	must.Eq(t, testpb.Job_STATE_RUNNING, job.GetState())
+
+	repaired, err := dynabuf.ReadRepair(ctx, client, "jobs", item, job)
+	must.NoError(t, err)
+	must.True(t, repaired)
+
+	item, job = get()
+	must.Eq(t, "STATE_RUNNING", item["state"].(*types.AttributeValueMemberS).Value)
+
+	// Items already in the current form are not rewritten.
+	repaired, err = dynabuf.ReadRepair(ctx, client, "jobs", item, job)
+	must.NoError(t, err)
+	must.False(t, repaired)
+
+	// Items changed since they were read are left as they are.
+	stale := map[string]types.AttributeValue{
+		"id":    &types.AttributeValueMemberS{Value: "1"},
+		"state": &types.AttributeValueMemberN{Value: "1"},
+	}
+
+	repaired, err = dynabuf.ReadRepair(ctx, client, "jobs", stale, &testpb.Job{Id: "1", State: testpb.Job_STATE_FAILED})
+	must.NoError(t, err)
+	must.False(t, repaired)
+
+	_, job = get()
+	must.Eq(t, testpb.Job_STATE_RUNNING, job.GetState())
+}
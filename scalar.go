@@ -0,0 +1,37 @@
+package dynabuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// scalarJSON returns the JSON form of a single attribute value other than
+// a map, as read into a message of the type of msg by [Unmarshal].
+//
+// The google.protobuf messages, such as google.protobuf.Value and the
+// wrapper types, are read from the value itself, as protojson reads them,
+// and other messages are read with the value as their only field.
+func scalarJSON(av types.AttributeValue, msg proto.Message) ([]byte, error) {
+	var v any
+	d := attributevalue.NewDecoder(func(o *attributevalue.DecoderOptions) {
+		o.UseNumber = true
+	})
+	if err := d.Decode(av, &v); err != nil {
+		return nil, err
+	}
+	v = convertNumbers[attributevalue.Number, json.Number](v)
+
+	md := msg.ProtoReflect().Descriptor()
+	if !strings.HasPrefix(string(md.FullName()), "google.protobuf.") {
+		if md.Fields().Len() != 1 {
+			return nil, fmt.Errorf("a single attribute value can only be read into a message with one field, %s has %d", md.FullName(), md.Fields().Len())
+		}
+		v = map[string]any{md.Fields().Get(0).JSONName(): v}
+	}
+	return json.Marshal(v)
+}
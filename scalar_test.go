@@ -0,0 +1,135 @@
+package dynabuf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestUnmarshalScalar(t *testing.T) {
+	tests := []struct {
+		name string
+		av   types.AttributeValue
+		msg  proto.Message
+		want proto.Message
+		err  string
+	}{
+		{
+			name: "string value",
+			av:   &types.AttributeValueMemberS{Value: "hello"},
+			msg:  &structpb.Value{},
+			want: structpb.NewStringValue("hello"),
+		},
+		{
+			name: "number value",
+			av:   &types.AttributeValueMemberN{Value: "12.5"},
+			msg:  &structpb.Value{},
+			want: structpb.NewNumberValue(12.5),
+		},
+		{
+			name: "bool value",
+			av:   &types.AttributeValueMemberBOOL{Value: true},
+			msg:  &structpb.Value{},
+			want: structpb.NewBoolValue(true),
+		},
+		{
+			name: "null value",
+			av:   &types.AttributeValueMemberNULL{Value: true},
+			msg:  &structpb.Value{},
+			want: structpb.NewNullValue(),
+		},
+		{
+			name: "list value",
+			av: &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberS{Value: "a"},
+				&types.AttributeValueMemberN{Value: "1"},
+			}},
+			msg: &structpb.Value{},
+			want: structpb.NewListValue(&structpb.ListValue{Values: []*structpb.Value{
+				structpb.NewStringValue("a"),
+				structpb.NewNumberValue(1),
+			}}),
+		},
+		{
+			name: "string set value",
+			av:   &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+			msg:  &structpb.ListValue{},
+			want: &structpb.ListValue{Values: []*structpb.Value{
+				structpb.NewStringValue("a"),
+				structpb.NewStringValue("b"),
+			}},
+		},
+		{
+			name: "int64 wrapper",
+			av:   &types.AttributeValueMemberN{Value: "9007199254740993"},
+			msg:  &wrapperspb.Int64Value{},
+			want: wrapperspb.Int64(9007199254740993),
+		},
+		{
+			name: "string wrapper",
+			av:   &types.AttributeValueMemberS{Value: "hello"},
+			msg:  &wrapperspb.StringValue{},
+			want: wrapperspb.String("hello"),
+		},
+		{
+			name: "bytes wrapper",
+			av:   &types.AttributeValueMemberB{Value: []byte("hello")},
+			msg:  &wrapperspb.BytesValue{},
+			want: wrapperspb.Bytes([]byte("hello")),
+		},
+		{
+			name: "timestamp",
+			av:   &types.AttributeValueMemberS{Value: "2024-03-01T09:30:00Z"},
+			msg:  &timestamppb.Timestamp{},
+			want: timestamppb.New(time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)),
+		},
+		{
+			name: "single field message",
+			av:   &types.AttributeValueMemberN{Value: "12.50"},
+			msg:  &testpb.Amount{},
+			want: &testpb.Amount{Value: "12.50"},
+		},
+		{
+			name: "map",
+			av: &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"street": &types.AttributeValueMemberS{Value: "Main St"},
+			}},
+			msg:  &testpb.Address{},
+			want: &testpb.Address{Street: "Main St"},
+		},
+		{
+			name: "several fields",
+			av:   &types.AttributeValueMemberS{Value: "Main St"},
+			msg:  &testpb.Address{},
+			err:  "dynabuf.test.v1.Address has 2",
+		},
+		{
+			name: "mismatched type",
+			av:   &types.AttributeValueMemberS{Value: "hello"},
+			msg:  &wrapperspb.Int32Value{},
+			err:  "invalid value for int32 field value",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := dynabuf.Unmarshal(test.av, test.msg)
+			if test.err != "" {
+				must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+				must.ErrorContains(t, err, test.err)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, test.want, test.msg, must.Cmp(protocmp.Transform()))
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Package sdkv1 adapts dynabuf to version 1 of the AWS SDK for Go, for
+// programs that have not yet moved their DynamoDB code to version 2.
+//
+// Messages are encoded exactly as [dynabuf.Marshal] encodes them, so items
+// written with either version of the SDK can be read with the other while
+// a program is migrated.
+//
+// # Example
+//
+//	item, _ := sdkv1.Marshal(&example.User{Id: "123", Name: "Alice"})
+//
+//	_, _ = client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+//	  TableName: aws.String("users"),
+//	  Item:      item,
+//	})
+//
+// It is a separate module, github.com/picatz/dynabuf/sdkv1, so that
+// programs using version 2 of the SDK do not depend on version 1.
+package sdkv1
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/picatz/dynabuf"
+	"google.golang.org/protobuf/proto"
+)
+
+// Marshal encodes msg as an item of attribute values of version 1 of the
+// SDK, as [dynabuf.Marshal] encodes it.
+func Marshal(msg proto.Message) (map[string]*dynamodb.AttributeValue, error) {
+	av, err := dynabuf.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return FromItem(av.(map[string]types.AttributeValue)), nil
+}
+
+// Unmarshal decodes an item of attribute values of version 1 of the SDK
+// into msg, as [dynabuf.Unmarshal] decodes it.
+func Unmarshal(item map[string]*dynamodb.AttributeValue, msg proto.Message, opts ...dynabuf.Option) error {
+	av, err := ToItem(item)
+	if err != nil {
+		return err
+	}
+	return dynabuf.Unmarshal(av, msg, opts...)
+}
+
+// FromItem converts an item of attribute values of version 2 of the SDK to
+// version 1.
+func FromItem(item map[string]types.AttributeValue) map[string]*dynamodb.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	out := make(map[string]*dynamodb.AttributeValue, len(item))
+	for name, v := range item {
+		out[name] = From(v)
+	}
+	return out
+}
+
+// ToItem converts an item of attribute values of version 1 of the SDK to
+// version 2, returning an error if an attribute value has no type set.
+func ToItem(item map[string]*dynamodb.AttributeValue) (map[string]types.AttributeValue, error) {
+	out, err := toItem(item)
+	if err != nil {
+		return nil, fmt.Errorf("sdkv1: %w", err)
+	}
+	return out, nil
+}
+
+// From converts an attribute value of version 2 of the SDK to version 1.
+func From(v types.AttributeValue) *dynamodb.AttributeValue {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		return &dynamodb.AttributeValue{S: &v.Value}
+	case *types.AttributeValueMemberN:
+		return &dynamodb.AttributeValue{N: &v.Value}
+	case *types.AttributeValueMemberB:
+		return &dynamodb.AttributeValue{B: v.Value}
+	case *types.AttributeValueMemberBOOL:
+		return &dynamodb.AttributeValue{BOOL: &v.Value}
+	case *types.AttributeValueMemberNULL:
+		return &dynamodb.AttributeValue{NULL: &v.Value}
+	case *types.AttributeValueMemberSS:
+		return &dynamodb.AttributeValue{SS: pointers(v.Value)}
+	case *types.AttributeValueMemberNS:
+		return &dynamodb.AttributeValue{NS: pointers(v.Value)}
+	case *types.AttributeValueMemberBS:
+		return &dynamodb.AttributeValue{BS: v.Value}
+	case *types.AttributeValueMemberL:
+		l := make([]*dynamodb.AttributeValue, len(v.Value))
+		for i, elem := range v.Value {
+			l[i] = From(elem)
+		}
+		return &dynamodb.AttributeValue{L: l}
+	case *types.AttributeValueMemberM:
+		return &dynamodb.AttributeValue{M: FromItem(v.Value)}
+	}
+	return nil
+}
+
+// To converts an attribute value of version 1 of the SDK to version 2,
+// returning an error if it has no type set.
+func To(v *dynamodb.AttributeValue) (types.AttributeValue, error) {
+	av, err := to(v)
+	if err != nil {
+		return nil, fmt.Errorf("sdkv1: %w", err)
+	}
+	return av, nil
+}
+
+func toItem(item map[string]*dynamodb.AttributeValue) (map[string]types.AttributeValue, error) {
+	if item == nil {
+		return nil, nil
+	}
+	out := make(map[string]types.AttributeValue, len(item))
+	for name, v := range item {
+		av, err := to(v)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", name, err)
+		}
+		out[name] = av
+	}
+	return out, nil
+}
+
+func to(v *dynamodb.AttributeValue) (types.AttributeValue, error) {
+	switch {
+	case v == nil:
+		return nil, fmt.Errorf("attribute value is nil")
+	case v.S != nil:
+		return &types.AttributeValueMemberS{Value: *v.S}, nil
+	case v.N != nil:
+		return &types.AttributeValueMemberN{Value: *v.N}, nil
+	case v.B != nil:
+		return &types.AttributeValueMemberB{Value: v.B}, nil
+	case v.BOOL != nil:
+		return &types.AttributeValueMemberBOOL{Value: *v.BOOL}, nil
+	case v.NULL != nil:
+		return &types.AttributeValueMemberNULL{Value: *v.NULL}, nil
+	case v.SS != nil:
+		return &types.AttributeValueMemberSS{Value: values(v.SS)}, nil
+	case v.NS != nil:
+		return &types.AttributeValueMemberNS{Value: values(v.NS)}, nil
+	case v.BS != nil:
+		return &types.AttributeValueMemberBS{Value: v.BS}, nil
+	case v.L != nil:
+		l := make([]types.AttributeValue, len(v.L))
+		for i, elem := range v.L {
+			av, err := to(elem)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			l[i] = av
+		}
+		return &types.AttributeValueMemberL{Value: l}, nil
+	case v.M != nil:
+		m, err := toItem(v.M)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	}
+	return nil, fmt.Errorf("attribute value has no type set")
+}
+
+func pointers(values []string) []*string {
+	out := make([]*string, len(values))
+	for i := range values {
+		out[i] = &values[i]
+	}
+	return out
+}
+
+func values(pointers []*string) []string {
+	out := make([]string, len(pointers))
+	for i, p := range pointers {
+		if p != nil {
+			out[i] = *p
+		}
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package sdkv1_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/picatz/dynabuf/sdkv1"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestMarshalUnmarshal(t *testing.T) {
+	user := &testpb.User{
+		Id:                "123",
+		Name:              "Alice",
+		Age:               30,
+		Address:           &testpb.Address{Street: "Main St", ZipCode: 12345},
+		PreviousAddresses: []*testpb.Address{{Street: "Elm St"}},
+		CreateTime:        timestamppb.Now(),
+	}
+
+	item, err := sdkv1.Marshal(user)
+	must.NoError(t, err)
+	must.Eq(t, "Alice", aws.StringValue(item["name"].S))
+	must.Eq(t, "30", aws.StringValue(item["age"].N))
+	must.Eq(t, "12345", aws.StringValue(item["address"].M["zipCode"].N))
+	must.Eq(t, "Elm St", aws.StringValue(item["previousAddresses"].L[0].M["street"].S))
+
+	// Items are encoded as with version 2 of the SDK.
+	v2, err := sdkv1.ToItem(item)
+	must.NoError(t, err)
+	must.Eq(t, dynabuf.MustMarshalItem(user), v2)
+
+	var got testpb.User
+	must.NoError(t, sdkv1.Unmarshal(item, &got))
+	must.True(t, proto.Equal(user, &got))
+}
+
+func TestFromTo(t *testing.T) {
+	values := []types.AttributeValue{
+		&types.AttributeValueMemberS{Value: "a"},
+		&types.AttributeValueMemberN{Value: "1.5"},
+		&types.AttributeValueMemberB{Value: []byte("b")},
+		&types.AttributeValueMemberBOOL{Value: true},
+		&types.AttributeValueMemberNULL{Value: true},
+		&types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		&types.AttributeValueMemberNS{Value: []string{"1", "2"}},
+		&types.AttributeValueMemberBS{Value: [][]byte{[]byte("a")}},
+		&types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "a"}}},
+		&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"a": &types.AttributeValueMemberN{Value: "1"}}},
+	}
+
+	for _, v := range values {
+		got, err := sdkv1.To(sdkv1.From(v))
+		must.NoError(t, err)
+		must.Eq(t, v, got)
+	}
+}
+
+func TestToInvalid(t *testing.T) {
+	_, err := sdkv1.ToItem(map[string]*dynamodb.AttributeValue{
+		"a": {L: []*dynamodb.AttributeValue{{}}},
+	})
+	must.EqError(t, err, `sdkv1: attribute "a": element 0: attribute value has no type set`)
+}
@@ -0,0 +1,88 @@
+// Package sequence implements monotonically increasing sequence numbers
+// backed by DynamoDB counter items.
+//
+// Each named sequence is a single item whose counter is incremented with an
+// atomic ADD update, so concurrent callers always receive distinct, ordered
+// identifiers without any coordination beyond the table itself.
+//
+// # Table Schema
+//
+// The table must use a string partition key named "name":
+//
+//	KeySchema: []types.KeySchemaElement{
+//	  {
+//	    AttributeName: aws.String("name"),
+//	    KeyType:       types.KeyTypeHash,
+//	  },
+//	},
+package sequence
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+)
+
+// Client is the subset of the DynamoDB API used by a [Sequence].
+type Client interface {
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+}
+
+// Sequence hands out sequence numbers stored in a DynamoDB table.
+//
+// A Sequence is safe for concurrent use.
+type Sequence struct {
+	client Client
+	table  string
+	cfg    *dynabuf.Config
+}
+
+// New returns a [Sequence] storing its counters in the given table,
+// sending its requests with the configuration of opts.
+func New(client Client, table string, opts ...dynabuf.ConfigOption) *Sequence {
+	return &Sequence{
+		client: client,
+		table:  table,
+		cfg:    dynabuf.NewConfig(opts...),
+	}
+}
+
+// NextID returns the next number of the named sequence. The first call for
+// a name returns 1, and every following call returns a number greater than
+// all numbers previously returned for that name.
+func (s *Sequence) NextID(ctx context.Context, name string) (uint64, error) {
+	out, err := dynabuf.Do(ctx, s.cfg, s.client.UpdateItem, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.table),
+		Key: map[string]types.AttributeValue{
+			"name": &types.AttributeValueMemberS{Value: name},
+		},
+		UpdateExpression: aws.String("ADD #value :one"),
+		ExpressionAttributeNames: map[string]string{
+			"#value": "value",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("sequence: failed to increment %q: %w", name, err)
+	}
+
+	value, ok := out.Attributes["value"].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, fmt.Errorf("sequence: missing counter value for %q", name)
+	}
+
+	id, err := strconv.ParseUint(value.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("sequence: invalid counter value for %q: %w", name, err)
+	}
+
+	return id, nil
+}
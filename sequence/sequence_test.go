@@ -0,0 +1,89 @@
+package sequence_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/sequence"
+	"github.com/shoenig/test/must"
+)
+
+func newSequence(t *testing.T) *sequence.Sequence {
+	t.Helper()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("sequences"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("name"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	return sequence.New(client, "sequences")
+}
+
+func TestSequence(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		check func(t *testing.T, seq *sequence.Sequence)
+	}{
+		{
+			name: "increments per name",
+			check: func(t *testing.T, seq *sequence.Sequence) {
+				for want := uint64(1); want <= 3; want++ {
+					id, err := seq.NextID(ctx, "orders")
+					must.NoError(t, err)
+					must.Eq(t, want, id)
+				}
+
+				id, err := seq.NextID(ctx, "users")
+				must.NoError(t, err)
+				must.Eq(t, uint64(1), id)
+			},
+		},
+		{
+			name: "concurrent callers get distinct ids",
+			check: func(t *testing.T, seq *sequence.Sequence) {
+				var (
+					mu   sync.Mutex
+					wg   sync.WaitGroup
+					seen = map[uint64]bool{}
+				)
+
+				for range 50 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						id, err := seq.NextID(ctx, "orders")
+						must.NoError(t, err)
+
+						mu.Lock()
+						defer mu.Unlock()
+						must.False(t, seen[id])
+						seen[id] = true
+					}()
+				}
+				wg.Wait()
+
+				must.MapLen(t, 50, seen)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.check(t, newSequence(t))
+		})
+	}
+}
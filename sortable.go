@@ -0,0 +1,286 @@
+package dynabuf
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// uuidPattern matches UUIDs in canonical form, in either case.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// SortableString returns v as a string sorting as v does, so numbers,
+// times, and UUIDs can be stored in string sort keys, and compared with
+// begins_with and BETWEEN as expected. It returns an error if v is not one
+// of:
+//
+//   - signed integers, zero-padded to 10 digits, or 19 for int and int64,
+//     with negative numbers prefixed by "-" and offset by a power of ten,
+//     so -1 is "-9999999999"
+//   - unsigned integers, zero-padded to 10 digits, or 20 for uint and
+//     uint64
+//   - float32 and float64, as the 16 hex digits of the bits of the float64
+//     value, with the sign bit flipped, or all bits if negative
+//   - [time.Time] and [*timestamppb.Timestamp], as epoch seconds formatted
+//     as 12-digit signed integers, followed by a dot and 9 digits of
+//     nanoseconds, such as "001709251200.000000000"
+//   - [16]byte UUIDs, and UUID strings, in lowercase canonical form, which
+//     sort by creation time for UUIDv7
+//
+// Values of a sort key must all have the same type, since the widths of
+// integers depend on it.
+//
+// Fields annotated as sortable are stored as sortable strings:
+//
+//	int64 sequence = 2 [(dynabuf.v1.field) = { sortable: true }];
+//
+// # Example
+//
+//	sk, _ := dynabuf.SortableString(int32(42)) // "0000000042"
+func SortableString(v any) (string, error) {
+	switch v := v.(type) {
+	case int8:
+		return sortableInt(int64(v), 10), nil
+	case int16:
+		return sortableInt(int64(v), 10), nil
+	case int32:
+		return sortableInt(int64(v), 10), nil
+	case int:
+		return sortableInt(int64(v), 19), nil
+	case int64:
+		return sortableInt(v, 19), nil
+	case uint8:
+		return fmt.Sprintf("%010d", v), nil
+	case uint16:
+		return fmt.Sprintf("%010d", v), nil
+	case uint32:
+		return fmt.Sprintf("%010d", v), nil
+	case uint:
+		return fmt.Sprintf("%020d", v), nil
+	case uint64:
+		return fmt.Sprintf("%020d", v), nil
+	case float32:
+		return sortableFloat(float64(v)), nil
+	case float64:
+		return sortableFloat(v), nil
+	case time.Time:
+		return sortableInt(v.Unix(), 12) + fmt.Sprintf(".%09d", v.Nanosecond()), nil
+	case *timestamppb.Timestamp:
+		if err := v.CheckValid(); err != nil {
+			return "", fmt.Errorf("dynabuf: %w", err)
+		}
+		return SortableString(v.AsTime())
+	case [16]byte:
+		h := hex.EncodeToString(v[:])
+		return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+	case string:
+		if !uuidPattern.MatchString(v) {
+			return "", fmt.Errorf("dynabuf: %q is not a UUID", v)
+		}
+		return strings.ToLower(v), nil
+	}
+	return "", fmt.Errorf("dynabuf: %T values cannot be sortable", v)
+}
+
+// sortableInt returns n zero-padded to width digits, with negative numbers
+// prefixed by "-" and offset by 10^width, so they sort before positive
+// numbers, and by value.
+func sortableInt(n int64, width int) string {
+	if n >= 0 {
+		return fmt.Sprintf("%0*d", width, n)
+	}
+	return fmt.Sprintf("-%0*d", width, pow10(width)-uint64(-n))
+}
+
+// parseSortableInt parses a string returned by sortableInt.
+func parseSortableInt(s string, width int) (int64, bool) {
+	digits, negative := strings.CutPrefix(s, "-")
+	if len(digits) != width {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		return -int64(pow10(width) - n), n != 0
+	}
+	return int64(n), n <= math.MaxInt64
+}
+
+// pow10 returns 10^n, for n up to 19.
+func pow10(n int) uint64 {
+	p := uint64(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
+
+// sortableFloat returns the hex digits of the bits of f, transformed so
+// they sort as floats do.
+func sortableFloat(f float64) string {
+	bits := math.Float64bits(f)
+	if bits>>63 == 1 {
+		bits = ^bits
+	} else {
+		bits |= 1 << 63
+	}
+	return fmt.Sprintf("%016x", bits)
+}
+
+// parseSortableFloat parses a string returned by sortableFloat.
+func parseSortableFloat(s string) (float64, bool) {
+	if len(s) != 16 {
+		return 0, false
+	}
+	bits, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	if bits>>63 == 1 {
+		bits &^= 1 << 63
+	} else {
+		bits = ^bits
+	}
+	return math.Float64frombits(bits), true
+}
+
+// isSortable reports whether the field is annotated as sortable.
+func isSortable(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := proto.GetExtension(fd.Options(), dynabufpb.E_Field).(*dynabufpb.FieldOptions)
+	return ok && opts.GetSortable()
+}
+
+// encodeSortable returns the sortable string of a JSON value of fd.
+func encodeSortable(fd protoreflect.FieldDescriptor, v any) (any, error) {
+	var (
+		x   any
+		err error
+		s   = fmt.Sprint(v)
+	)
+	if f, ok := v.(float64); ok {
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var n int64
+		n, err = strconv.ParseInt(s, 10, 32)
+		x = int32(n)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		x, err = strconv.ParseInt(s, 10, 64)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, 32)
+		x = uint32(n)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		x, err = strconv.ParseUint(s, 10, 64)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		x, err = strconv.ParseFloat(s, 64)
+	case protoreflect.StringKind:
+		x = s
+	case protoreflect.BytesKind:
+		var b []byte
+		if b, err = base64.StdEncoding.DecodeString(s); err == nil && len(b) != 16 {
+			err = fmt.Errorf("%d bytes is not a UUID", len(b))
+		}
+		if err == nil {
+			x = [16]byte(b)
+		}
+	case protoreflect.MessageKind:
+		if fd.Message().FullName() != "google.protobuf.Timestamp" {
+			return nil, fmt.Errorf("%s fields cannot be sortable", fd.Message().FullName())
+		}
+		x, err = time.Parse(time.RFC3339Nano, s)
+	default:
+		return nil, fmt.Errorf("%s fields cannot be sortable", fd.Kind())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q of sortable field %s: %w", s, fd.FullName(), err)
+	}
+
+	sortable, err := SortableString(x)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q of sortable field %s: %w", s, fd.FullName(), err)
+	}
+	return sortable, nil
+}
+
+// decodeSortable returns the JSON value of a sortable string of fd.
+func decodeSortable(fd protoreflect.FieldDescriptor, v any) (any, error) {
+	s, isString := v.(string)
+	if !isString {
+		return v, nil
+	}
+
+	var (
+		x  any
+		ok bool
+	)
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var n int64
+		n, ok = parseSortableInt(s, 10)
+		x = json.Number(strconv.FormatInt(n, 10))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var n int64
+		n, ok = parseSortableInt(s, 19)
+		x = strconv.FormatInt(n, 10)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(s, 10, 32)
+		x, ok = json.Number(strconv.FormatUint(n, 10)), err == nil && len(s) == 10
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(s, 10, 64)
+		x, ok = strconv.FormatUint(n, 10), err == nil && len(s) == 20
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		var f float64
+		f, ok = parseSortableFloat(s)
+		switch {
+		case math.IsNaN(f):
+			x = "NaN"
+		case math.IsInf(f, 1):
+			x = "Infinity"
+		case math.IsInf(f, -1):
+			x = "-Infinity"
+		default:
+			x = json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case protoreflect.StringKind:
+		x, ok = s, true
+	case protoreflect.BytesKind:
+		var b []byte
+		b, ok = parseUUID(s)
+		x = base64.StdEncoding.EncodeToString(b)
+	case protoreflect.MessageKind:
+		seconds, nanos, found := strings.Cut(s, ".")
+		var sec, nsec int64
+		sec, ok = parseSortableInt(seconds, 12)
+		nsec, err := strconv.ParseInt(nanos, 10, 64)
+		ok = ok && found && err == nil && len(nanos) == 9
+		x = time.Unix(sec, nsec).UTC().Format(time.RFC3339Nano)
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid sortable value %q of field %s", s, fd.FullName())
+	}
+	return x, nil
+}
+
+// parseUUID returns the bytes of a UUID in canonical form.
+func parseUUID(s string) ([]byte, bool) {
+	if !uuidPattern.MatchString(s) {
+		return nil, false
+	}
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	return b, err == nil
+}
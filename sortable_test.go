@@ -0,0 +1,122 @@
+package dynabuf_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSortableString(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []any
+		want   []string
+	}{
+		{
+			name:   "int32",
+			values: []any{int32(math.MinInt32), int32(-1), int32(0), int32(42)},
+			want:   []string{"-7852516352", "-9999999999", "0000000000", "0000000042"},
+		},
+		{
+			name:   "int64",
+			values: []any{int64(math.MinInt64), int64(-10), int64(7), int64(math.MaxInt64)},
+			want:   []string{"-0776627963145224192", "-9999999999999999990", "0000000000000000007", "9223372036854775807"},
+		},
+		{
+			name:   "uint64",
+			values: []any{uint64(0), uint64(10), uint64(math.MaxUint64)},
+			want:   []string{"00000000000000000000", "00000000000000000010", "18446744073709551615"},
+		},
+		{
+			name:   "float64",
+			values: []any{math.Inf(-1), -1.5, 0.0, 1e-300, 2.0, math.Inf(1)},
+		},
+		{
+			name: "time",
+			values: []any{
+				time.Date(1969, 12, 31, 23, 59, 59, 500, time.UTC),
+				time.Unix(0, 0),
+				timestamppb.New(time.Unix(1709251200, 5)),
+			},
+			want: []string{"-999999999999.000000500", "000000000000.000000000", "001709251200.000000005"},
+		},
+		{
+			name: "uuid",
+			values: []any{
+				"01890A5D-AC96-774B-BCCE-B302099A8057",
+				[16]byte{0x01, 0x8f, 0x00, 0x00, 0x00, 0x00, 0x70, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+			},
+			want: []string{"01890a5d-ac96-774b-bcce-b302099a8057", "018f0000-0000-7000-8000-000000000001"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, v := range test.values {
+				s, err := dynabuf.SortableString(v)
+				must.NoError(t, err)
+				got = append(got, s)
+			}
+			must.True(t, slices.IsSorted(got))
+			if test.want != nil {
+				must.Eq(t, test.want, got)
+			}
+		})
+	}
+
+	for _, v := range []any{"abc", true, []byte("x")} {
+		_, err := dynabuf.SortableString(v)
+		must.Error(t, err)
+	}
+}
+
+func TestMarshalSortable(t *testing.T) {
+	entry := &testpb.Entry{
+		Log:       "app",
+		Sequence:  -3,
+		Priority:  7,
+		Offset:    math.MaxUint64,
+		Score:     -0.25,
+		Time:      timestamppb.New(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)),
+		Uuid:      []byte{0x01, 0x8f, 0x00, 0x00, 0x00, 0x00, 0x70, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+		RequestId: "018F0000-0000-7000-8000-000000000002",
+	}
+
+	item := dynabuf.MustMarshalItem(entry)
+	must.Eq(t, map[string]types.AttributeValue{
+		"log":       &types.AttributeValueMemberS{Value: "app"},
+		"sequence":  &types.AttributeValueMemberS{Value: "-9999999999999999997"},
+		"priority":  &types.AttributeValueMemberS{Value: "0000000007"},
+		"offset":    &types.AttributeValueMemberS{Value: "18446744073709551615"},
+		"score":     &types.AttributeValueMemberS{Value: "402fffffffffffff"},
+		"time":      &types.AttributeValueMemberS{Value: "001709294400.000000000"},
+		"uuid":      &types.AttributeValueMemberS{Value: "018f0000-0000-7000-8000-000000000001"},
+		"requestId": &types.AttributeValueMemberS{Value: "018f0000-0000-7000-8000-000000000002"},
+	}, item)
+
+	var got testpb.Entry
+	must.NoError(t, dynabuf.Unmarshal(item, &got))
+	entry.RequestId = "018f0000-0000-7000-8000-000000000002"
+	must.True(t, proto.Equal(entry, &got))
+
+	_, err := dynabuf.Marshal(&testpb.Entry{RequestId: "request-1"})
+	must.ErrorIs(t, err, dynabuf.ErrFailedToMarshal)
+	must.StrContains(t, err.Error(), "sortable field")
+
+	_, err = dynabuf.Marshal(&testpb.Entry{Uuid: []byte("short")})
+	must.ErrorContains(t, err, "5 bytes is not a UUID")
+
+	err = dynabuf.Unmarshal(map[string]types.AttributeValue{
+		"sequence": &types.AttributeValueMemberS{Value: "42"},
+	}, &testpb.Entry{})
+	must.ErrorIs(t, err, dynabuf.ErrFailedToUnmarshal)
+}
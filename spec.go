@@ -0,0 +1,182 @@
+package dynabuf
+
+import (
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Spec returns a machine readable description of how [Marshal] maps
+// messages of the given type to the attributes of a DynamoDB item: the
+// attribute name and value type of every field, how values are encoded
+// within their type, and when attributes are omitted, as well as the
+// composite attributes of items.
+//
+// The returned spec is a protobuf message, so it can be serialized as JSON
+// with protojson for tools outside of Go, such as documentation generators
+// or implementations of the mapping in other languages.
+//
+// # Example
+//
+//	spec := dynabuf.Spec((&example.User{}).ProtoReflect().Descriptor())
+//
+//	b, _ := protojson.Marshal(spec)
+func Spec(md protoreflect.MessageDescriptor) *dynabufpb.MappingSpec {
+	spec := &dynabufpb.MappingSpec{Root: string(md.FullName())}
+
+	seen := map[protoreflect.FullName]bool{}
+
+	var visit func(md protoreflect.MessageDescriptor)
+	visit = func(md protoreflect.MessageDescriptor) {
+		if seen[md.FullName()] {
+			return
+		}
+		seen[md.FullName()] = true
+
+		msg := &dynabufpb.MessageSpec{Name: string(md.FullName())}
+		spec.Messages = append(spec.Messages, msg)
+
+		var nested []protoreflect.MessageDescriptor
+
+		fields := md.Fields()
+		for i := range fields.Len() {
+			fd := fields.Get(i)
+
+			attr := &dynabufpb.AttributeSpec{
+				Name:               fd.JSONName(),
+				Field:              string(fd.FullName()),
+				Number:             int32(fd.Number()),
+				OmittedWhenDefault: fd.IsList() || fd.IsMap() || !fd.HasPresence(),
+				Volatile:           isVolatile(fd),
+				OutputOnly:         isOutputOnly(fd),
+				Immutable:          isImmutable(fd),
+				Aliases:            fieldAliases(fd),
+				WriteAliases:       writesAliases(fd),
+			}
+
+			switch {
+			case fd.IsMap():
+				attr.Value = &dynabufpb.ValueSpec{Type: "M"}
+				attr.Element = valueSpec(fd.MapValue())
+			case fd.IsList():
+				attr.Value = &dynabufpb.ValueSpec{Type: "L"}
+				attr.Element = valueSpec(fd)
+			default:
+				attr.Value = valueSpec(fd)
+			}
+
+			for _, v := range []*dynabufpb.ValueSpec{attr.Value, attr.Element} {
+				if v.GetMessage() != "" {
+					nested = append(nested, fieldMessage(fd))
+				}
+			}
+
+			msg.Attributes = append(msg.Attributes, attr)
+		}
+
+		// Composite attributes are only stored alongside the fields of
+		// items.
+		if len(spec.Messages) == 1 {
+			opts, _ := proto.GetExtension(md.Options(), dynabufpb.E_Message).(*dynabufpb.MessageOptions)
+			for _, c := range opts.GetCompositeAttributes() {
+				msg.Attributes = append(msg.Attributes, &dynabufpb.AttributeSpec{
+					Name:     c.GetName(),
+					Value:    &dynabufpb.ValueSpec{Type: "S"},
+					Template: c.GetTemplate(),
+				})
+			}
+		}
+
+		for _, md := range nested {
+			visit(md)
+		}
+	}
+	visit(md)
+
+	return spec
+}
+
+// fieldMessage returns the message type of a message field, or of the
+// values of a map field.
+func fieldMessage(fd protoreflect.FieldDescriptor) protoreflect.MessageDescriptor {
+	if fd.IsMap() {
+		return fd.MapValue().Message()
+	}
+	return fd.Message()
+}
+
+// valueSpec returns the spec of a single value of the field, ignoring
+// whether the field is repeated.
+func valueSpec(fd protoreflect.FieldDescriptor) *dynabufpb.ValueSpec {
+	if isSortable(fd) {
+		return &dynabufpb.ValueSpec{Type: "S", Encoding: dynabufpb.Encoding_ENCODING_SORTABLE}
+	}
+
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return &dynabufpb.ValueSpec{Type: "BOOL"}
+	case protoreflect.StringKind:
+		if isDecimal(fd) {
+			return &dynabufpb.ValueSpec{Type: "N", Encoding: dynabufpb.Encoding_ENCODING_DECIMAL}
+		}
+		return &dynabufpb.ValueSpec{Type: "S"}
+	case protoreflect.BytesKind:
+		return &dynabufpb.ValueSpec{Type: "S", Encoding: dynabufpb.Encoding_ENCODING_BASE64}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &dynabufpb.ValueSpec{Type: "N"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return &dynabufpb.ValueSpec{Type: "S", Encoding: dynabufpb.Encoding_ENCODING_DECIMAL_STRING}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return &dynabufpb.ValueSpec{Encoding: dynabufpb.Encoding_ENCODING_FLOAT}
+	case protoreflect.EnumKind:
+		if fd.Enum().FullName() == "google.protobuf.NullValue" {
+			return &dynabufpb.ValueSpec{Type: "NULL"}
+		}
+		return &dynabufpb.ValueSpec{
+			Type:     "S",
+			Encoding: dynabufpb.Encoding_ENCODING_ENUM_NAME,
+			Enum:     string(fd.Enum().FullName()),
+		}
+	default:
+		if googleTypeEncoding(fd) == dynabufpb.Encoding_ENCODING_DECIMAL_MONEY {
+			return &dynabufpb.ValueSpec{Type: "M", Encoding: dynabufpb.Encoding_ENCODING_DECIMAL_MONEY}
+		}
+		return messageValueSpec(fd.Message())
+	}
+}
+
+// messageValueSpec returns the spec of a message value, which is a map of
+// attributes unless the message is a well-known type with a special JSON
+// mapping.
+func messageValueSpec(md protoreflect.MessageDescriptor) *dynabufpb.ValueSpec {
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		return &dynabufpb.ValueSpec{Type: "S", Encoding: dynabufpb.Encoding_ENCODING_RFC3339}
+	case "google.protobuf.Duration":
+		return &dynabufpb.ValueSpec{Type: "S", Encoding: dynabufpb.Encoding_ENCODING_DURATION}
+	case "google.type.Date":
+		return &dynabufpb.ValueSpec{Type: "S", Encoding: dynabufpb.Encoding_ENCODING_DATE}
+	case "google.type.TimeOfDay":
+		return &dynabufpb.ValueSpec{Type: "S", Encoding: dynabufpb.Encoding_ENCODING_TIME_OF_DAY}
+	case "google.protobuf.FieldMask":
+		return &dynabufpb.ValueSpec{Type: "S", Encoding: dynabufpb.Encoding_ENCODING_FIELD_MASK}
+	case "google.protobuf.Struct":
+		return &dynabufpb.ValueSpec{Type: "M", Encoding: dynabufpb.Encoding_ENCODING_JSON}
+	case "google.protobuf.ListValue":
+		return &dynabufpb.ValueSpec{Type: "L", Encoding: dynabufpb.Encoding_ENCODING_JSON}
+	case "google.protobuf.Value":
+		return &dynabufpb.ValueSpec{Encoding: dynabufpb.Encoding_ENCODING_JSON}
+	case "google.protobuf.Any":
+		return &dynabufpb.ValueSpec{Type: "M", Encoding: dynabufpb.Encoding_ENCODING_ANY}
+	case "google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue",
+		"google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value",
+		"google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		// Wrappers are stored as the value they wrap.
+		return valueSpec(md.Fields().ByName("value"))
+	default:
+		return &dynabufpb.ValueSpec{Type: "M", Message: string(md.FullName())}
+	}
+}
@@ -0,0 +1,71 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/dynabufpb"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestSpec(t *testing.T) {
+	spec := dynabuf.Spec((&testpb.User{}).ProtoReflect().Descriptor())
+
+	must.Eq(t, "dynabuf.test.v1.User", spec.GetRoot())
+	must.SliceLen(t, 2, spec.GetMessages())
+
+	user := spec.GetMessages()[0]
+	must.Eq(t, "dynabuf.test.v1.User", user.GetName())
+
+	attrs := map[string]*dynabufpb.AttributeSpec{}
+	for _, attr := range user.GetAttributes() {
+		attrs[attr.GetName()] = attr
+	}
+
+	must.Eq(t, "N", attrs["age"].GetValue().GetType())
+	must.True(t, attrs["age"].GetOmittedWhenDefault())
+
+	must.Eq(t, "M", attrs["address"].GetValue().GetType())
+	must.Eq(t, "dynabuf.test.v1.Address", attrs["address"].GetValue().GetMessage())
+	must.False(t, attrs["address"].GetOmittedWhenDefault())
+
+	must.Eq(t, "L", attrs["previousAddresses"].GetValue().GetType())
+	must.Eq(t, "dynabuf.test.v1.Address", attrs["previousAddresses"].GetElement().GetMessage())
+
+	must.Eq(t, "S", attrs["createTime"].GetValue().GetType())
+	must.Eq(t, dynabufpb.Encoding_ENCODING_RFC3339, attrs["createTime"].GetValue().GetEncoding())
+	must.True(t, attrs["createTime"].GetVolatile())
+	must.True(t, attrs["createTime"].GetOutputOnly())
+	must.True(t, attrs["id"].GetImmutable())
+	must.False(t, attrs["name"].GetImmutable())
+
+	address := spec.GetMessages()[1]
+	must.Eq(t, "dynabuf.test.v1.Address", address.GetName())
+	must.Eq(t, "zipCode", address.GetAttributes()[1].GetName())
+
+	job := dynabuf.Spec((&testpb.Job{}).ProtoReflect().Descriptor())
+	state := job.GetMessages()[0].GetAttributes()[1]
+	must.Eq(t, dynabufpb.Encoding_ENCODING_ENUM_NAME, state.GetValue().GetEncoding())
+	must.Eq(t, "dynabuf.test.v1.Job.State", state.GetValue().GetEnum())
+
+	product := dynabuf.Spec((&testpb.Product{}).ProtoReflect().Descriptor())
+	price := product.GetMessages()[0].GetAttributes()[1]
+	must.Eq(t, "N", price.GetValue().GetType())
+	must.Eq(t, dynabufpb.Encoding_ENCODING_DECIMAL, price.GetValue().GetEncoding())
+	must.Eq(t, "N", product.GetMessages()[0].GetAttributes()[2].GetElement().GetType())
+
+	invoice := dynabuf.Spec((&testpb.Invoice{}).ProtoReflect().Descriptor()).GetMessages()[0].GetAttributes()
+	must.Eq(t, dynabufpb.Encoding_ENCODING_DECIMAL_MONEY, invoice[2].GetValue().GetEncoding())
+	must.Eq(t, dynabufpb.Encoding_ENCODING_DATE, invoice[3].GetValue().GetEncoding())
+	must.Eq(t, dynabufpb.Encoding_ENCODING_TIME_OF_DAY, invoice[4].GetValue().GetEncoding())
+
+	// The spec can be shared with other tools as JSON.
+	b, err := protojson.Marshal(spec)
+	must.NoError(t, err)
+
+	var decoded dynabufpb.MappingSpec
+	must.NoError(t, protojson.Unmarshal(b, &decoded))
+	must.Eq(t, spec.GetRoot(), decoded.GetRoot())
+}
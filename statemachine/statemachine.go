@@ -0,0 +1,185 @@
+// Package statemachine persists state machines whose states are protobuf
+// enum values stored in DynamoDB items.
+//
+// The allowed transitions are declared on the enum itself, using the
+// (dynabuf.v1.enum_value) option:
+//
+//	enum State {
+//	  STATE_UNSPECIFIED = 0 [(dynabuf.v1.enum_value) = { transitions: ["STATE_RUNNING"] }];
+//	  STATE_RUNNING = 1 [(dynabuf.v1.enum_value) = { transitions: ["STATE_SUCCEEDED", "STATE_FAILED"] }];
+//	  STATE_SUCCEEDED = 2;
+//	  STATE_FAILED = 3;
+//	}
+//
+// A [Machine] only changes the state attribute of an item with a
+// conditional update that checks the stored state is one of the states
+// allowed to transition to the target, so concurrent writers can never
+// move an item through a transition the enum doesn't declare.
+package statemachine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/dynabufpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Set of errors that can be returned by a [Machine].
+var (
+	// ErrInvalidField is returned when the state field is not a singular enum field.
+	ErrInvalidField = errors.New("statemachine: state field must be a singular enum field")
+
+	// ErrUnknownState is returned when a transition refers to an enum value
+	// that doesn't exist.
+	ErrUnknownState = errors.New("statemachine: unknown state")
+
+	// ErrInvalidTransition is returned when no state is allowed to
+	// transition to the requested state.
+	ErrInvalidTransition = errors.New("statemachine: invalid transition")
+
+	// ErrTransitionFailed is returned when the item doesn't exist, or its
+	// current state is not allowed to transition to the requested state.
+	ErrTransitionFailed = errors.New("statemachine: item is not in a state that allows the transition")
+)
+
+// Client is the subset of the DynamoDB API used by a [Machine].
+type Client interface {
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+}
+
+// Machine transitions the state field of items stored in a DynamoDB table.
+//
+// A Machine is safe for concurrent use.
+type Machine struct {
+	client Client
+	table  string
+	cfg    *dynabuf.Config
+	field  protoreflect.FieldDescriptor
+
+	// sources maps each state to the states allowed to transition to it.
+	sources map[protoreflect.EnumNumber][]protoreflect.EnumNumber
+}
+
+// New returns a [Machine] for the given enum field of the messages stored
+// in table, sending its requests with the configuration of opts. The
+// transitions are read from the enum's value options.
+func New(client Client, table string, field protoreflect.FieldDescriptor, opts ...dynabuf.ConfigOption) (*Machine, error) {
+	if field.Kind() != protoreflect.EnumKind || field.Cardinality() == protoreflect.Repeated {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidField, field.FullName())
+	}
+
+	m := &Machine{
+		client:  client,
+		table:   table,
+		cfg:     dynabuf.NewConfig(opts...),
+		field:   field,
+		sources: map[protoreflect.EnumNumber][]protoreflect.EnumNumber{},
+	}
+
+	values := field.Enum().Values()
+	for i := 0; i < values.Len(); i++ {
+		from := values.Get(i)
+		valueOpts := proto.GetExtension(from.Options(), dynabufpb.E_EnumValue).(*dynabufpb.EnumValueOptions)
+		for _, name := range valueOpts.GetTransitions() {
+			to := values.ByName(protoreflect.Name(name))
+			if to == nil {
+				return nil, fmt.Errorf("%w: %s transitions to %q", ErrUnknownState, from.FullName(), name)
+			}
+			m.sources[to.Number()] = append(m.sources[to.Number()], from.Number())
+		}
+	}
+
+	return m, nil
+}
+
+// Allowed reports whether the enum declares a transition from one state
+// to another.
+func (m *Machine) Allowed(from, to protoreflect.EnumNumber) bool {
+	return slices.Contains(m.sources[to], from)
+}
+
+// Transition moves the item with the given key to the state to, returning
+// [ErrTransitionFailed] if the item doesn't exist or is not in a state
+// allowed to transition to it.
+func (m *Machine) Transition(ctx context.Context, key map[string]types.AttributeValue, to protoreflect.EnumNumber) error {
+	target := m.field.Enum().Values().ByNumber(to)
+	if target == nil {
+		return fmt.Errorf("%w: %d is not a value of %s", ErrUnknownState, to, m.field.Enum().FullName())
+	}
+
+	sources := m.sources[to]
+	if len(sources) == 0 {
+		return fmt.Errorf("%w: no state may transition to %s", ErrInvalidTransition, target.Name())
+	}
+
+	names := map[string]string{
+		"#state": m.field.JSONName(),
+	}
+	values := map[string]types.AttributeValue{}
+
+	// The item must already exist, otherwise the update would create it.
+	var conditions []string
+	for i, k := range slices.Sorted(maps.Keys(key)) {
+		name := fmt.Sprintf("#key%d", i)
+		names[name] = k
+		conditions = append(conditions, fmt.Sprintf("attribute_exists(%s)", name))
+	}
+
+	// Default values are omitted from the marshaled item, so an item in the
+	// zero state has no state attribute at all.
+	var states []string
+	for i, from := range sources {
+		if from == 0 {
+			states = append(states, "attribute_not_exists(#state)")
+			continue
+		}
+		name := fmt.Sprintf(":from%d", i)
+		values[name] = enumValue(m.field, from)
+		states = append(states, fmt.Sprintf("#state = %s", name))
+	}
+	conditions = append(conditions, "("+strings.Join(states, " OR ")+")")
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                aws.String(m.table),
+		Key:                      key,
+		ConditionExpression:      aws.String(strings.Join(conditions, " AND ")),
+		ExpressionAttributeNames: names,
+	}
+
+	if to == 0 {
+		input.UpdateExpression = aws.String("REMOVE #state")
+	} else {
+		values[":to"] = enumValue(m.field, to)
+		input.UpdateExpression = aws.String("SET #state = :to")
+	}
+
+	if len(values) > 0 {
+		input.ExpressionAttributeValues = values
+	}
+
+	if _, err := dynabuf.Do(ctx, m.cfg, m.client.UpdateItem, input); err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return fmt.Errorf("%w: %s", ErrTransitionFailed, target.Name())
+		}
+		return fmt.Errorf("statemachine: failed to transition to %s: %w", target.Name(), err)
+	}
+
+	return nil
+}
+
+// enumValue returns the attribute value of an enum state as written by
+// [github.com/picatz/dynabuf.Marshal], which encodes enums by name.
+func enumValue(field protoreflect.FieldDescriptor, n protoreflect.EnumNumber) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: string(field.Enum().Values().ByNumber(n).Name())}
+}
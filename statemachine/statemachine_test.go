@@ -0,0 +1,119 @@
+package statemachine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/picatz/dynabuf/statemachine"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestMachine(t *testing.T) {
+	ctx := context.Background()
+
+	stateField := (&testpb.Job{}).ProtoReflect().Descriptor().Fields().ByName("state")
+
+	key := map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberS{Value: "job-1"},
+	}
+
+	num := func(s testpb.Job_State) protoreflect.EnumNumber {
+		return s.Number()
+	}
+
+	tests := []struct {
+		name  string
+		put   *testpb.Job
+		check func(t *testing.T, client *dynamotest.Client, machine *statemachine.Machine)
+	}{
+		{
+			name: "allowed transitions",
+			put:  &testpb.Job{Id: "job-1"},
+			check: func(t *testing.T, client *dynamotest.Client, machine *statemachine.Machine) {
+				must.NoError(t, machine.Transition(ctx, key, num(testpb.Job_STATE_RUNNING)))
+				must.NoError(t, machine.Transition(ctx, key, num(testpb.Job_STATE_FAILED)))
+				must.NoError(t, machine.Transition(ctx, key, num(testpb.Job_STATE_RUNNING)))
+				must.NoError(t, machine.Transition(ctx, key, num(testpb.Job_STATE_SUCCEEDED)))
+
+				out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+					TableName: aws.String("jobs"),
+					Key:       key,
+				})
+				must.NoError(t, err)
+
+				job := &testpb.Job{}
+				must.NoError(t, dynabuf.Unmarshal(out.Item, job))
+				must.Eq(t, testpb.Job_STATE_SUCCEEDED, job.GetState())
+			},
+		},
+		{
+			name: "disallowed transition",
+			put:  &testpb.Job{Id: "job-1", State: testpb.Job_STATE_SUCCEEDED},
+			check: func(t *testing.T, client *dynamotest.Client, machine *statemachine.Machine) {
+				err := machine.Transition(ctx, key, num(testpb.Job_STATE_RUNNING))
+				must.ErrorIs(t, err, statemachine.ErrTransitionFailed)
+			},
+		},
+		{
+			name: "unreachable state",
+			put:  &testpb.Job{Id: "job-1"},
+			check: func(t *testing.T, client *dynamotest.Client, machine *statemachine.Machine) {
+				err := machine.Transition(ctx, key, num(testpb.Job_STATE_UNSPECIFIED))
+				must.ErrorIs(t, err, statemachine.ErrInvalidTransition)
+			},
+		},
+		{
+			name: "missing item",
+			check: func(t *testing.T, client *dynamotest.Client, machine *statemachine.Machine) {
+				err := machine.Transition(ctx, key, num(testpb.Job_STATE_RUNNING))
+				must.ErrorIs(t, err, statemachine.ErrTransitionFailed)
+			},
+		},
+		{
+			name: "allowed",
+			check: func(t *testing.T, client *dynamotest.Client, machine *statemachine.Machine) {
+				must.True(t, machine.Allowed(num(testpb.Job_STATE_RUNNING), num(testpb.Job_STATE_FAILED)))
+				must.False(t, machine.Allowed(num(testpb.Job_STATE_SUCCEEDED), num(testpb.Job_STATE_RUNNING)))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := dynamotest.NewClient()
+			_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+				TableName: aws.String("jobs"),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String("id"),
+						KeyType:       types.KeyTypeHash,
+					},
+				},
+			})
+			must.NoError(t, err)
+
+			if test.put != nil {
+				item, err := dynabuf.Marshal(test.put)
+				must.NoError(t, err)
+
+				_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+					TableName: aws.String("jobs"),
+					Item:      item.(map[string]types.AttributeValue),
+				})
+				must.NoError(t, err)
+			}
+
+			machine, err := statemachine.New(client, "jobs", stateField)
+			must.NoError(t, err)
+
+			test.check(t, client, machine)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package dynabuf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SizeBuckets are the upper bounds, in bytes, of the size histogram
+// buckets of [AttributeStats]. Sizes above the last bound are counted in
+// a final overflow bucket.
+var SizeBuckets = []int{16, 64, 256, 1024, 4096, 16384, 65536}
+
+// TableStats describes the items of a table, as collected by [Stats].
+type TableStats struct {
+	// Items is the number of items scanned.
+	Items int `json:"items"`
+
+	// Attributes are the statistics of every top-level attribute found in
+	// the items or expected by the message, keyed by attribute name.
+	Attributes map[string]*AttributeStats `json:"attributes"`
+}
+
+// AttributeStats describes the values of a single attribute.
+type AttributeStats struct {
+	// Field is the full name of the message field the attribute maps to,
+	// or empty if the attribute is not a field of the message.
+	Field protoreflect.FullName `json:"field,omitempty"`
+
+	// ExpectedType is the attribute value type [Marshal] writes for the
+	// field, such as "S" or "N", or empty if any type is expected.
+	ExpectedType string `json:"expectedType,omitempty"`
+
+	// Count is the number of items with the attribute.
+	Count int `json:"count"`
+
+	// Types counts the attribute value types found, keyed by type.
+	Types map[string]int `json:"types"`
+
+	// Mismatches is the number of values whose type differs from the
+	// expected type.
+	Mismatches int `json:"mismatches"`
+
+	// MinSize, MaxSize, and TotalSize describe the sizes of the values in
+	// bytes, as counted towards the DynamoDB item size limit.
+	MinSize   int `json:"minSize"`
+	MaxSize   int `json:"maxSize"`
+	TotalSize int `json:"totalSize"`
+
+	// SizeHistogram counts the values by size, with one bucket per bound
+	// of [SizeBuckets] and a final overflow bucket.
+	SizeHistogram []int `json:"sizeHistogram"`
+}
+
+// Presence returns the fraction of scanned items with the attribute.
+func (s *TableStats) Presence(name string) float64 {
+	a, ok := s.Attributes[name]
+	if !ok || s.Items == 0 {
+		return 0
+	}
+	return float64(a.Count) / float64(s.Items)
+}
+
+// Stats scans the table and collects statistics about every top-level
+// attribute of its items, compared to the schema of the message T: how
+// often each attribute is present, the distribution of its sizes, and how
+// often its type differs from the one [Marshal] writes for the field.
+//
+// It is meant to understand what is actually stored in a table before
+// tightening a schema, so items are not decoded and never fail the scan.
+//
+// # Example
+//
+//	stats, _ := dynabuf.Stats[*example.User](ctx, dynabuf.Table{Client: client, Name: "users"})
+//
+//	for name, attr := range stats.Attributes {
+//	  fmt.Printf("%s: %.0f%% present, %d mismatches\n", name, 100*stats.Presence(name), attr.Mismatches)
+//	}
+func Stats[T proto.Message](ctx context.Context, table Table) (*TableStats, error) {
+	var zero T
+
+	stats := &TableStats{Attributes: map[string]*AttributeStats{}}
+
+	fields := zero.ProtoReflect().Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		stats.Attributes[fd.JSONName()] = &AttributeStats{
+			Field:         fd.FullName(),
+			ExpectedType:  attributeType(fd),
+			Types:         map[string]int{},
+			SizeHistogram: make([]int, len(SizeBuckets)+1),
+		}
+	}
+
+	ctx = ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+	err := table.scanPages(ctx, nil, func(items []map[string]types.AttributeValue, _ map[string]types.AttributeValue) error {
+		for _, item := range items {
+			stats.Items++
+
+			for name, v := range item {
+				a, ok := stats.Attributes[name]
+				if !ok {
+					a = &AttributeStats{
+						Types:         map[string]int{},
+						SizeHistogram: make([]int, len(SizeBuckets)+1),
+					}
+					stats.Attributes[name] = a
+				}
+				a.add(name, v)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dynabuf: failed to collect table statistics: %w", err)
+	}
+
+	return stats, nil
+}
+
+// add records a value of the attribute.
+func (a *AttributeStats) add(name string, v types.AttributeValue) {
+	typ := attributeValueType(v)
+	a.Types[typ]++
+
+	if a.ExpectedType != "" && typ != a.ExpectedType {
+		a.Mismatches++
+	}
+
+	size := len(name) + attributeValueSize(v)
+	if a.Count == 0 || size < a.MinSize {
+		a.MinSize = size
+	}
+	a.MaxSize = max(a.MaxSize, size)
+	a.TotalSize += size
+
+	bucket := len(SizeBuckets)
+	for i, bound := range SizeBuckets {
+		if size <= bound {
+			bucket = i
+			break
+		}
+	}
+	a.SizeHistogram[bucket]++
+
+	a.Count++
+}
+
+// attributeType returns the attribute value type written by [Marshal] for
+// the field, or empty if the type depends on the value.
+func attributeType(fd protoreflect.FieldDescriptor) string {
+	switch {
+	case fd.IsMap():
+		return "M"
+	case fd.IsList():
+		return "L"
+	default:
+		return valueSpec(fd).GetType()
+	}
+}
+
+// attributeValueType returns the DynamoDB type of an attribute value, such
+// as "S" or "M".
+func attributeValueType(v types.AttributeValue) string {
+	switch v.(type) {
+	case *types.AttributeValueMemberS:
+		return "S"
+	case *types.AttributeValueMemberN:
+		return "N"
+	case *types.AttributeValueMemberB:
+		return "B"
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL"
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberM:
+		return "M"
+	case *types.AttributeValueMemberL:
+		return "L"
+	case *types.AttributeValueMemberSS:
+		return "SS"
+	case *types.AttributeValueMemberNS:
+		return "NS"
+	case *types.AttributeValueMemberBS:
+		return "BS"
+	default:
+		return "?"
+	}
+}
+
+// itemSize returns the approximate size of an item in bytes, following the
+// rules DynamoDB uses to compute item sizes.
+func itemSize(item map[string]types.AttributeValue) int {
+	size := 0
+	for name, v := range item {
+		size += len(name) + attributeValueSize(v)
+	}
+	return size
+}
+
+// attributeValueSize returns the approximate size of an attribute value in
+// bytes, following the rules DynamoDB uses to compute item sizes.
+func attributeValueSize(v types.AttributeValue) int {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return (len(v.Value)+1)/2 + 1
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberM:
+		size := 3
+		for name, e := range v.Value {
+			size += len(name) + attributeValueSize(e) + 1
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := 3
+		for _, e := range v.Value {
+			size += attributeValueSize(e) + 1
+		}
+		return size
+	case *types.AttributeValueMemberSS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		size := 0
+		for _, n := range v.Value {
+			size += (len(n)+1)/2 + 1
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		size := 0
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	default:
+		return 0
+	}
+}
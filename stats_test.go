@@ -0,0 +1,82 @@
+package dynabuf_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("users"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	for _, item := range []map[string]types.AttributeValue{
+		{
+			"id":   &types.AttributeValueMemberS{Value: "1"},
+			"name": &types.AttributeValueMemberS{Value: "Alice"},
+			"age":  &types.AttributeValueMemberN{Value: "30"},
+		},
+		{
+			"id":  &types.AttributeValueMemberS{Value: "2"},
+			"age": &types.AttributeValueMemberS{Value: "forty"},
+		},
+		{
+			"id":       &types.AttributeValueMemberS{Value: "3"},
+			"nickname": &types.AttributeValueMemberS{Value: "C"},
+			"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"street": &types.AttributeValueMemberS{Value: "Main St"},
+			}},
+		},
+	} {
+		_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String("users"),
+			Item:      item,
+		})
+		must.NoError(t, err)
+	}
+
+	stats, err := dynabuf.Stats[*testpb.User](ctx, dynabuf.Table{Client: client, Name: "users"})
+	must.NoError(t, err)
+
+	must.Eq(t, 3, stats.Items)
+	must.Eq(t, 1, stats.Presence("id"))
+	must.Eq(t, 2.0/3, stats.Presence("age"))
+	must.Eq(t, 0, stats.Presence("createTime"))
+
+	age := stats.Attributes["age"]
+	must.Eq(t, "dynabuf.test.v1.User.age", string(age.Field))
+	must.Eq(t, "N", age.ExpectedType)
+	must.Eq(t, map[string]int{"N": 1, "S": 1}, age.Types)
+	must.Eq(t, 1, age.Mismatches)
+
+	must.Eq(t, "M", stats.Attributes["address"].ExpectedType)
+	must.Eq(t, 0, stats.Attributes["address"].Mismatches)
+	must.Eq(t, "S", stats.Attributes["createTime"].ExpectedType)
+
+	nickname := stats.Attributes["nickname"]
+	must.Eq(t, "", string(nickname.Field))
+	must.Eq(t, 1, nickname.Count)
+
+	name := stats.Attributes["name"]
+	must.Eq(t, len("name")+len("Alice"), name.MinSize)
+	must.Eq(t, name.MinSize, name.MaxSize)
+	must.Eq(t, []int{1, 0, 0, 0, 0, 0, 0, 0}, name.SizeHistogram)
+}
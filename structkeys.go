@@ -0,0 +1,146 @@
+package dynabuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrReservedAttribute is returned by [Marshal] when a key of a
+// google.protobuf.Struct stored as an item is the name of an attribute
+// written by dynabuf with the options given, such as [MetadataAttribute]
+// with [WithMetadata].
+var ErrReservedAttribute = errors.New("dynabuf: struct key is a reserved attribute name")
+
+// StructKeyPolicy is how [Marshal] handles the keys of a
+// google.protobuf.Struct stored as an item which are the names of
+// attributes written by dynabuf alongside the fields of messages with the
+// options given, such as [MetadataAttribute] with [WithMetadata], and which
+// would otherwise be overwritten, or read back as such attributes. Keys are
+// not reserved without such options.
+type StructKeyPolicy int
+
+const (
+	// StructKeyError fails with an error wrapping [ErrReservedAttribute],
+	// which is the default.
+	StructKeyError StructKeyPolicy = iota
+
+	// StructKeyEscape stores the keys prefixed with an underscore, such as
+	// "__meta", along with keys of the same form, which get another one,
+	// so the keys are read back as they were when the items are read with
+	// the same policy and options.
+	StructKeyEscape
+)
+
+// WithStructKeys handles the keys of a google.protobuf.Struct stored as an
+// item which are the names of attributes written by dynabuf with the given
+// policy, when marshaling, and unescapes them when unmarshaling with
+// [StructKeyEscape].
+//
+// # Example
+//
+//	item, err := dynabuf.Marshal(doc, dynabuf.WithStructKeys(dynabuf.StructKeyEscape))
+//
+//	err = dynabuf.Unmarshal(item, doc, dynabuf.WithStructKeys(dynabuf.StructKeyEscape))
+func WithStructKeys(policy StructKeyPolicy) Option {
+	return func(o *options) {
+		o.structKeys = policy
+	}
+}
+
+// reservedAttributes returns the names of the attributes written by
+// dynabuf alongside the fields of messages with the configured options.
+func (o *options) reservedAttributes() []string {
+	var reserved []string
+	if o.metadata {
+		reserved = append(reserved, MetadataAttribute)
+	}
+	if o.mapItems && o.mapKeyAttr != "" {
+		reserved = append(reserved, o.mapKeyAttr)
+	}
+	return reserved
+}
+
+// isStruct reports whether md is google.protobuf.Struct, whose fields are
+// stored as the attributes of its keys.
+func isStruct(md protoreflect.MessageDescriptor) bool {
+	return md.FullName() == "google.protobuf.Struct"
+}
+
+// reservedKey returns the reserved attribute name key is, or is an escaped
+// form of, prefixed with underscores, such as "_meta" for "__meta", or "".
+func reservedKey(reserved []string, key string) string {
+	for _, name := range reserved {
+		if prefix, ok := strings.CutSuffix(key, name); ok && strings.Trim(prefix, "_") == "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// escapeStructKeys handles the keys of the stored fields of a
+// google.protobuf.Struct which are reserved attribute names with the
+// configured policy.
+func (o *options) escapeStructKeys(fields map[string]any) error {
+	reserved := o.reservedAttributes()
+
+	var conflicts []string
+	for key := range fields {
+		if slices.Contains(reserved, key) {
+			conflicts = append(conflicts, key)
+		}
+	}
+	if o.structKeys == StructKeyError {
+		if len(conflicts) > 0 {
+			slices.Sort(conflicts)
+			return fmt.Errorf("%w: %s", ErrReservedAttribute, quoteNames(conflicts))
+		}
+		return nil
+	}
+
+	escaped := map[string]any{}
+	for key, v := range fields {
+		if reservedKey(reserved, key) != "" {
+			escaped["_"+key] = v
+			delete(fields, key)
+		}
+	}
+	for key, v := range escaped {
+		fields[key] = v
+	}
+	return nil
+}
+
+// unescapeStructKeys returns the JSON form of a google.protobuf.Struct with
+// the keys escaped by [StructKeyEscape] unescaped.
+func (o *options) unescapeStructKeys(data []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var fields map[string]any
+	if err := d.Decode(&fields); err != nil {
+		// Values other than objects are left to fail to unmarshal.
+		return data, nil
+	}
+
+	reserved := o.reservedAttributes()
+	unescaped := map[string]any{}
+	for key, v := range fields {
+		if base := reservedKey(reserved, key); base != "" && key != base {
+			unescaped[key[1:]] = v
+			delete(fields, key)
+		}
+	}
+	if len(unescaped) == 0 {
+		return data, nil
+	}
+	for key, v := range unescaped {
+		fields[key] = v
+	}
+	return json.Marshal(fields)
+}
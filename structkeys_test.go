@@ -0,0 +1,54 @@
+package dynabuf_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestStructKeys(t *testing.T) {
+	doc, err := structpb.NewStruct(map[string]any{
+		"_meta":  "user data",
+		"__meta": "more user data",
+		"name":   "Alice",
+	})
+	must.NoError(t, err)
+
+	// Reserved keys are rejected by default.
+	_, err = dynabuf.Marshal(doc, dynabuf.WithMetadata("v1"))
+	must.ErrorIs(t, err, dynabuf.ErrReservedAttribute)
+
+	// Escaped keys are read back as they were.
+	escape := dynabuf.WithStructKeys(dynabuf.StructKeyEscape)
+	av, err := dynabuf.Marshal(doc, escape, dynabuf.WithMetadata("v1"))
+	must.NoError(t, err)
+	item := av.(map[string]types.AttributeValue)
+	must.MapContainsKeys(t, item, []string{"__meta", "___meta", "name", dynabuf.MetadataAttribute})
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "user data"}, item["__meta"])
+
+	var got structpb.Struct
+	must.NoError(t, dynabuf.Unmarshal(item, &got, escape, dynabuf.WithMetadata("")))
+	must.Eq(t, doc, &got, must.Cmp(protocmp.Transform()))
+}
+
+func TestStructKeysNotReserved(t *testing.T) {
+	doc, err := structpb.NewStruct(map[string]any{
+		"_meta": "user data",
+		"name":  "Alice",
+	})
+	must.NoError(t, err)
+
+	// Keys are only reserved by the options writing such attributes.
+	av, err := dynabuf.Marshal(doc)
+	must.NoError(t, err)
+	item := av.(map[string]types.AttributeValue)
+	must.Eq[types.AttributeValue](t, &types.AttributeValueMemberS{Value: "user data"}, item[dynabuf.MetadataAttribute])
+
+	var got structpb.Struct
+	must.NoError(t, dynabuf.Unmarshal(item, &got))
+	must.Eq(t, doc, &got, must.Cmp(protocmp.Transform()))
+}
@@ -0,0 +1,494 @@
+package dynabuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/picatz/dynabuf/internal/avtext"
+)
+
+// ErrUnprocessedItems is returned when DynamoDB keeps leaving items of a
+// batch write unprocessed, usually because the table is being throttled.
+var ErrUnprocessedItems = errors.New("dynabuf: items were left unprocessed by batch write")
+
+// TableAPIClient is the subset of the DynamoDB API used by the table
+// utilities of this package, such as [Copy] and [Backfill].
+type TableAPIClient interface {
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+// Table identifies a DynamoDB table and the client used to access it.
+type Table struct {
+	// Client is the DynamoDB client of the table's account and region.
+	Client TableAPIClient
+
+	// Name is the name of the table.
+	Name string
+
+	// MinAttemptBudget is the minimum time given to each request of a batch
+	// write when the context has a deadline, which defaults to 100ms.
+	// Batches are not sent once less time than this is left.
+	MinAttemptBudget time.Duration
+
+	// MaxConcurrency is the maximum number of batches written at once by
+	// batch writes, which defaults to 1. The number written at once starts
+	// at 1, and adapts to throttling up to this maximum.
+	MaxConcurrency int
+
+	// WriteLimiter, if set, limits the write capacity consumed by batch
+	// writes to the table.
+	WriteLimiter *WriteLimiter
+
+	// DeadLetter, if set, receives the messages [BatchPut] fails to write,
+	// once retries are exhausted.
+	DeadLetter DeadLetterSink
+
+	// Config, if set, configures the requests sent to the table, such as
+	// their retry policy and timeout.
+	Config *Config
+}
+
+// defaultMinAttemptBudget is the default of [Table.MinAttemptBudget].
+const defaultMinAttemptBudget = 100 * time.Millisecond
+
+// BatchDeadlineError is returned when the deadline of the context of a
+// batch write is reached before every request is written. It describes
+// the requests left, so they can be written later, and matches
+// [context.DeadlineExceeded] with [errors.Is].
+type BatchDeadlineError struct {
+	// Written is the number of requests written.
+	Written int
+
+	// Unprocessed are the requests sent, but not written, either because
+	// DynamoDB left them unprocessed, or because their request timed out.
+	Unprocessed []types.WriteRequest
+
+	// NotAttempted are the requests never sent.
+	NotAttempted []types.WriteRequest
+}
+
+// Error implements the error interface.
+func (e *BatchDeadlineError) Error() string {
+	return fmt.Sprintf("dynabuf: deadline reached after writing %d requests, with %d unprocessed and %d not attempted", e.Written, len(e.Unprocessed), len(e.NotAttempted))
+}
+
+// Unwrap returns [context.DeadlineExceeded].
+func (e *BatchDeadlineError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// batchWriteSize is the maximum number of requests in a BatchWriteItem call.
+const batchWriteSize = 25
+
+// batchWrite writes the requests to the table in batches, retrying
+// unprocessed items with the backoff of the retry policy of the table's
+// [Config], and returns the outcome of
+// every request, indexed by its position in requests. Batches failing are
+// recorded as failed and the following batches still sent, and the error
+// returned, if any, describes every request not written.
+//
+// Up to [Table.MaxConcurrency] batches are written at once, adjusting the
+// number in flight to throttling: it grows by one each time as many
+// requests as are in flight are not throttled, and is halved whenever a
+// request is throttled, either failing or leaving items unprocessed.
+//
+// If ctx has a deadline, the time left is split evenly between the batches
+// left, so a slow request cannot use up the time of the following ones,
+// and requests timing out are retried as if unprocessed. Once less than
+// [Table.MinAttemptBudget] is left, a [BatchDeadlineError] is returned.
+//
+// If the table has a [Table.WriteLimiter], every batch first waits for the
+// capacity it is expected to consume, and corrects the limiter with the
+// capacity it consumed.
+func (t Table) batchWrite(ctx context.Context, requests []types.WriteRequest) (*BatchResult, error) {
+	w := &batchWriter{
+		table:    t,
+		requests: requests,
+		limit:    newConcurrencyLimit(t.MaxConcurrency),
+		result:   &BatchResult{Failed: map[int]error{}},
+	}
+
+	var wg sync.WaitGroup
+	for {
+		if err := w.limit.acquire(ctx); err != nil {
+			w.stop(nil, err)
+			break
+		}
+
+		w.mu.Lock()
+		if w.err != nil || w.next == len(requests) {
+			w.mu.Unlock()
+			w.limit.release()
+			break
+		}
+		pending := make([]int, 0, batchWriteSize)
+		for ; w.next < len(requests) && len(pending) < batchWriteSize; w.next++ {
+			pending = append(pending, w.next)
+		}
+		w.mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer w.limit.release()
+			w.write(ctx, pending)
+		}()
+	}
+	wg.Wait()
+
+	result := w.result
+	for i := w.next; i < len(requests); i++ {
+		result.NotAttempted = append(result.NotAttempted, i)
+	}
+	slices.Sort(result.Succeeded)
+	slices.Sort(result.Unprocessed)
+
+	switch {
+	case w.err == nil:
+		return result, result.Err()
+	case !errors.Is(w.err, context.DeadlineExceeded):
+		return result, w.err
+	}
+	return result, &BatchDeadlineError{
+		Written:      len(result.Succeeded),
+		Unprocessed:  indexed(requests, result.Unprocessed),
+		NotAttempted: requests[w.next:],
+	}
+}
+
+// batchWriter holds the state of a batch write shared by the batches being
+// written.
+type batchWriter struct {
+	table    Table
+	requests []types.WriteRequest
+	limit    *concurrencyLimit
+
+	// next is the index of the first request not yet given to a batch, and
+	// err the error stopping the batch write, if any.
+	mu     sync.Mutex
+	next   int
+	result *BatchResult
+	err    error
+}
+
+// write writes a batch of the requests with the given indexes, recording
+// their outcome, and adjusting the concurrency limit to whether DynamoDB
+// throttles its requests.
+func (w *batchWriter) write(ctx context.Context, pending []int) {
+	t := w.table
+
+	attempts := t.Config.batchAttempts()
+	for attempt := 1; ; attempt++ {
+		input := &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{t.Name: indexed(w.requests, pending)},
+		}
+
+		// Capacity is waited for before the time of the batch is budgeted,
+		// so waiting does not use it up.
+		var units float64
+		if t.WriteLimiter != nil {
+			units = writeUnits(input.RequestItems[t.Name])
+			if err := t.WriteLimiter.Wait(ctx, units); err != nil {
+				w.stop(pending, err)
+				return
+			}
+			input.ReturnConsumedCapacity = types.ReturnConsumedCapacityTotal
+		}
+
+		attemptCtx, cancel, ok := t.attemptContext(ctx, w.batchesLeft())
+		if !ok {
+			if t.WriteLimiter != nil {
+				t.WriteLimiter.adjust(units)
+			}
+			w.stop(pending, context.DeadlineExceeded)
+			return
+		}
+
+		// Requests timing out, either by the budget of the batch or the
+		// timeout of the configuration, are retried.
+		out, err := Do(attemptCtx, t.Config, t.Client.BatchWriteItem, input)
+		timedOut := err != nil && ctx.Err() == nil && (attemptCtx.Err() != nil || errors.Is(err, context.DeadlineExceeded))
+		cancel()
+
+		if t.WriteLimiter != nil {
+			consumed := 0.0
+			if err == nil {
+				if c, ok := consumedUnits(out.ConsumedCapacity, t.Name); ok {
+					consumed = c
+				} else {
+					consumed = units - writeUnits(out.UnprocessedItems[t.Name])
+				}
+			}
+			t.WriteLimiter.adjust(units - consumed)
+		}
+
+		var unprocessed []int
+		switch {
+		case timedOut:
+			unprocessed = pending
+		case err != nil && ctx.Err() != nil:
+			w.stop(pending, ctx.Err())
+			return
+		case err != nil:
+			err = fmt.Errorf("dynabuf: failed to write batch to table %q: %w", t.Name, err)
+			w.mu.Lock()
+			for _, i := range pending {
+				w.result.Failed[i] = err
+			}
+			w.mu.Unlock()
+			if isThrottle(err) {
+				w.limit.observe(true)
+			}
+			return
+		default:
+			unprocessed = unprocessedRequests(w.requests, pending, out.UnprocessedItems[t.Name])
+			w.limit.observe(len(unprocessed) > 0)
+		}
+
+		w.mu.Lock()
+		for _, i := range pending {
+			if !slices.Contains(unprocessed, i) {
+				w.result.Succeeded = append(w.result.Succeeded, i)
+			}
+		}
+		if len(unprocessed) > 0 && attempt == attempts {
+			w.result.Unprocessed = append(w.result.Unprocessed, unprocessed...)
+		}
+		w.mu.Unlock()
+
+		if len(unprocessed) == 0 || attempt == attempts {
+			return
+		}
+		pending = unprocessed
+
+		select {
+		case <-ctx.Done():
+			w.stop(pending, ctx.Err())
+			return
+		case <-time.After(t.Config.backoff(attempt)):
+		}
+	}
+}
+
+// stop records the pending requests of a batch as unprocessed, and stops
+// the batch write with err, unless it was stopped already.
+func (w *batchWriter) stop(pending []int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.result.Unprocessed = append(w.result.Unprocessed, pending...)
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// batchesLeft returns the number of batches the time left is split
+// between: the current batch, and the batches not yet sent, divided
+// between the batches written at once.
+func (w *batchWriter) batchesLeft() int {
+	w.mu.Lock()
+	left := (len(w.requests) - w.next + batchWriteSize - 1) / batchWriteSize
+	w.mu.Unlock()
+
+	n := w.limit.current()
+	return 1 + (left+n-1)/n
+}
+
+// isThrottle reports whether err is a throttling error of the table or the
+// account.
+func isThrottle(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "ProvisionedThroughputExceededException", "ThrottlingException", "RequestLimitExceeded":
+		return true
+	}
+	return false
+}
+
+// unprocessedRequests returns the indexes of the pending requests left
+// unprocessed, which DynamoDB returns as copies of the requests.
+func unprocessedRequests(requests []types.WriteRequest, pending []int, unprocessed []types.WriteRequest) []int {
+	if len(unprocessed) == 0 {
+		return nil
+	}
+
+	left := map[string]int{}
+	for _, r := range unprocessed {
+		left[writeRequestText(r)]++
+	}
+
+	var indexes []int
+	for _, i := range pending {
+		if text := writeRequestText(requests[i]); left[text] > 0 {
+			left[text]--
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// writeRequestText returns the text of the item or key of a write request,
+// identifying it within a batch.
+func writeRequestText(r types.WriteRequest) string {
+	switch {
+	case r.PutRequest != nil:
+		return "put " + avtext.Format(&types.AttributeValueMemberM{Value: r.PutRequest.Item})
+	case r.DeleteRequest != nil:
+		return "delete " + avtext.Format(&types.AttributeValueMemberM{Value: r.DeleteRequest.Key})
+	}
+	return ""
+}
+
+// indexed returns the values at the given indexes.
+func indexed[T any](values []T, indexes []int) []T {
+	out := make([]T, len(indexes))
+	for i, idx := range indexes {
+		out[i] = values[idx]
+	}
+	return out
+}
+
+// attemptContext returns the context of a request of a batch write, given
+// the number of batches left including the current one, whose deadline is
+// the time left split evenly between them, or false if the time left is
+// less than the minimum attempt budget.
+func (t Table) attemptContext(ctx context.Context, batches int) (context.Context, context.CancelFunc, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx, func() {}, true
+	}
+
+	minBudget := t.MinAttemptBudget
+	if minBudget <= 0 {
+		minBudget = defaultMinAttemptBudget
+	}
+
+	left := time.Until(deadline)
+	if left < minBudget {
+		return nil, nil, false
+	}
+
+	budget := max(left/time.Duration(batches), minBudget)
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	return ctx, cancel, true
+}
+
+// scanPages scans the table from startKey, calling fn with every page of
+// items and the key to resume the scan after it, which is nil after the
+// last page.
+func (t Table) scanPages(ctx context.Context, startKey map[string]types.AttributeValue, fn func(items []map[string]types.AttributeValue, next map[string]types.AttributeValue) error) error {
+	return t.scanSegment(ctx, startKey, 0, 1, fn)
+}
+
+// scanSegment is like scanPages, but only scans segment of the
+// totalSegments segments of the table.
+func (t Table) scanSegment(ctx context.Context, startKey map[string]types.AttributeValue, segment, totalSegments int, fn func(items []map[string]types.AttributeValue, next map[string]types.AttributeValue) error) error {
+	input := &dynamodb.ScanInput{
+		TableName:      aws.String(t.Name),
+		ConsistentRead: t.Config.ConsistentRead(true),
+	}
+	if totalSegments > 1 {
+		input.Segment = aws.Int32(int32(segment))
+		input.TotalSegments = aws.Int32(int32(totalSegments))
+	}
+
+	for {
+		input.ExclusiveStartKey = startKey
+		out, err := Do(ctx, t.Config, t.Client.Scan, input)
+		if err != nil {
+			return fmt.Errorf("dynabuf: failed to scan table %q: %w", t.Name, err)
+		}
+
+		if err := fn(out.Items, out.LastEvaluatedKey); err != nil {
+			return err
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		startKey = out.LastEvaluatedKey
+	}
+}
+
+// concurrencyLimit limits the number of batches written at once, adjusting
+// the limit with additive increase and multiplicative decrease (AIMD) as
+// requests are throttled or not.
+type concurrencyLimit struct {
+	max int
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	released chan struct{}
+}
+
+// newConcurrencyLimit returns a limit of one batch at once, which can grow
+// up to n.
+func newConcurrencyLimit(n int) *concurrencyLimit {
+	return &concurrencyLimit{
+		max:      max(n, 1),
+		limit:    1,
+		released: make(chan struct{}),
+	}
+}
+
+// current returns the number of batches allowed at once.
+func (l *concurrencyLimit) current() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// acquire waits until another batch is allowed, or ctx is done.
+func (l *concurrencyLimit) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+// release ends a batch acquired.
+func (l *concurrencyLimit) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// observe adjusts the limit to the outcome of a request, halving it if it
+// was throttled, and growing it by one per limit requests otherwise.
+func (l *concurrencyLimit) observe(throttled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if throttled {
+		l.limit = max(1, l.limit/2)
+	} else {
+		l.limit = min(float64(l.max), l.limit+1/l.limit)
+	}
+}
@@ -0,0 +1,172 @@
+package dynabuf_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/shoenig/test/must"
+)
+
+// hangingClient blocks its first hangs batch writes until their context is
+// done.
+type hangingClient struct {
+	*dynamotest.Client
+
+	hangs atomic.Int64
+}
+
+func (c *hangingClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	if c.hangs.Add(-1) >= 0 {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return c.Client.BatchWriteItem(ctx, params, optFns...)
+}
+
+// congestedClient delays batch writes, leaving every request unprocessed
+// while more than limit batch writes are in flight, if limit is set.
+type congestedClient struct {
+	*dynamotest.Client
+
+	limit     int64
+	inFlight  atomic.Int64
+	peak      atomic.Int64
+	throttled atomic.Int64
+}
+
+func (c *congestedClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for peak := c.peak.Load(); n > peak && !c.peak.CompareAndSwap(peak, n); peak = c.peak.Load() {
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if c.limit > 0 && n > c.limit {
+		c.throttled.Add(1)
+		return &dynamodb.BatchWriteItemOutput{UnprocessedItems: params.RequestItems}, nil
+	}
+	return c.Client.BatchWriteItem(ctx, params, optFns...)
+}
+
+// duplicateUsers returns a group of n duplicates to remove from a new
+// users table.
+func duplicateUsers(t *testing.T, n int) (*dynamotest.Client, []dynabuf.Duplicates) {
+	t.Helper()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("users"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	dups := []dynabuf.Duplicates{{}}
+	for i := range n {
+		dups[0].Remove = append(dups[0].Remove, map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: fmt.Sprint(i)},
+		})
+	}
+	return client, dups
+}
+
+func TestBatchWriteRetriesTimedOutRequests(t *testing.T) {
+	client, dups := duplicateUsers(t, 50)
+
+	hanging := &hangingClient{Client: client}
+	hanging.hangs.Store(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	removed, err := dynabuf.RemoveDuplicates(ctx, dynabuf.Table{Client: hanging, Name: "users"}, dups)
+	must.NoError(t, err)
+	must.Eq(t, 50, removed)
+}
+
+func TestBatchWriteDeadline(t *testing.T) {
+	client, dups := duplicateUsers(t, 60)
+
+	hanging := &hangingClient{Client: client}
+	hanging.hangs.Store(math.MaxInt64)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	table := dynabuf.Table{Client: hanging, Name: "users", MinAttemptBudget: 50 * time.Millisecond}
+
+	removed, err := dynabuf.RemoveDuplicates(ctx, table, dups)
+	must.Eq(t, 0, removed)
+	must.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var deadline *dynabuf.BatchDeadlineError
+	must.True(t, errors.As(err, &deadline))
+	must.Eq(t, 0, deadline.Written)
+	must.SliceLen(t, 25, deadline.Unprocessed)
+	must.SliceLen(t, 35, deadline.NotAttempted)
+}
+
+func TestBatchWriteConcurrency(t *testing.T) {
+	tests := []struct {
+		name      string
+		max       int
+		limit     int64
+		wantPeak  int64
+		throttled bool
+	}{
+		{
+			name:     "sequential",
+			wantPeak: 1,
+		},
+		{
+			name:     "grows to max",
+			max:      4,
+			wantPeak: 4,
+		},
+		{
+			// More than two batches at once are throttled, so the number
+			// in flight stays well below the maximum.
+			name:      "backs off when throttled",
+			max:       8,
+			limit:     2,
+			wantPeak:  4,
+			throttled: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, dups := duplicateUsers(t, 1000)
+
+			congested := &congestedClient{Client: client, limit: test.limit}
+			table := dynabuf.Table{Client: congested, Name: "users", MaxConcurrency: test.max}
+
+			removed, err := dynabuf.RemoveDuplicates(context.Background(), table, dups)
+			must.NoError(t, err)
+			must.Eq(t, 1000, removed)
+
+			if test.throttled {
+				must.Positive(t, congested.throttled.Load())
+				must.LessEq(t, test.wantPeak, congested.peak.Load())
+			} else {
+				must.Eq(t, test.wantPeak, congested.peak.Load())
+				must.Zero(t, congested.throttled.Load())
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package dynabuf_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+func TestWithRequestTags(t *testing.T) {
+	var userAgents []string
+
+	client := dynamodb.New(dynamodb.Options{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+		HTTPClient: httpClientFunc(func(r *http.Request) (*http.Response, error) {
+			userAgents = append(userAgents, r.Header.Get("User-Agent"))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+				Body:       io.NopCloser(strings.NewReader(`{}`)),
+			}, nil
+		}),
+	}, dynabuf.WithRequestTags())
+
+	user := &testpb.User{Id: "1", Name: "Alice"}
+
+	item, err := dynabuf.Marshal(user)
+	must.NoError(t, err)
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String("users"),
+		Item:      item.(map[string]types.AttributeValue),
+	}
+
+	out, err := client.PutItem(dynabuf.ContextWithMessageType(context.Background(), user), input)
+	must.NoError(t, err)
+
+	tags, ok := dynabuf.GetRequestTags(out.ResultMetadata)
+	must.True(t, ok)
+	must.Eq(t, "dynabuf.test.v1.User", string(tags.MessageType))
+	must.Eq(t, "PutItem", tags.Operation)
+
+	must.SliceLen(t, 1, userAgents)
+	must.StrContains(t, userAgents[0], "dynabuf/message#dynabuf.test.v1.User")
+
+	// Requests without a message type are not tagged.
+	out, err = client.PutItem(context.Background(), input)
+	must.NoError(t, err)
+
+	_, ok = dynabuf.GetRequestTags(out.ResultMetadata)
+	must.False(t, ok)
+
+	must.SliceLen(t, 2, userAgents)
+	must.StrNotContains(t, userAgents[1], "dynabuf/message#")
+}
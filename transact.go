@@ -0,0 +1,120 @@
+package dynabuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// transactGetSize is the maximum number of items in a TransactGetItems
+// call.
+const transactGetSize = 100
+
+// ErrItemNotFound is returned when an item read does not exist.
+var ErrItemNotFound = errors.New("dynabuf: item not found")
+
+// TransactGetItemsAPIClient is the subset of the DynamoDB API used by
+// [TransactGet].
+type TransactGetItemsAPIClient interface {
+	TransactGetItems(ctx context.Context, params *dynamodb.TransactGetItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactGetItemsOutput, error)
+}
+
+// TypedGet is a read of [TransactGet], pairing the key of an item with the
+// message it is decoded into.
+type TypedGet struct {
+	// Table is the name of the table of the item.
+	Table string
+
+	// Key is the key of the item.
+	Key map[string]types.AttributeValue
+
+	// Msg is the message the item is decoded into, whose type may differ
+	// from the other reads of the transaction.
+	Msg proto.Message
+}
+
+// NotFoundError is returned by [TransactGet] when some of the items read do
+// not exist, after decoding the items that do. It matches
+// [ErrItemNotFound] with [errors.Is].
+type NotFoundError struct {
+	// Indexes are the indexes of the reads of items that do not exist,
+	// whose messages are reset.
+	Indexes []int
+}
+
+// Error implements the error interface.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%v: %d of the items read, starting with read %d", ErrItemNotFound, len(e.Indexes), e.Indexes[0])
+}
+
+// Unwrap returns [ErrItemNotFound].
+func (e *NotFoundError) Unwrap() error {
+	return ErrItemNotFound
+}
+
+// TransactGet reads the items of reqs atomically with TransactGetItems, and
+// decodes each into the message of its read, so items of different types
+// and tables are read in a consistent snapshot. Up to 100 items can be read
+// at once.
+//
+// If some of the items do not exist, their messages are reset, and the
+// error is a [NotFoundError] listing them once the others are decoded.
+//
+// # Example
+//
+//	user := &example.User{}
+//	order := &example.Order{}
+//
+//	err := dynabuf.TransactGet(ctx, client,
+//	  dynabuf.TypedGet{Table: "users", Key: userKey, Msg: user},
+//	  dynabuf.TypedGet{Table: "orders", Key: orderKey, Msg: order},
+//	)
+func TransactGet(ctx context.Context, client TransactGetItemsAPIClient, reqs ...TypedGet) error {
+	if len(reqs) == 0 {
+		return nil
+	}
+	if len(reqs) > transactGetSize {
+		return fmt.Errorf("dynabuf: a transaction reads up to %d items, got %d", transactGetSize, len(reqs))
+	}
+
+	input := &dynamodb.TransactGetItemsInput{TransactItems: make([]types.TransactGetItem, len(reqs))}
+	for i, req := range reqs {
+		if req.Msg == nil {
+			return fmt.Errorf("dynabuf: read %d of the transaction has no message", i)
+		}
+		input.TransactItems[i] = types.TransactGetItem{Get: &types.Get{
+			TableName: aws.String(req.Table),
+			Key:       req.Key,
+		}}
+	}
+
+	out, err := client.TransactGetItems(ctx, input)
+	if err != nil {
+		return fmt.Errorf("dynabuf: failed to get items in a transaction: %w", err)
+	}
+	if len(out.Responses) != len(reqs) {
+		return fmt.Errorf("dynabuf: transaction returned %d responses for %d reads", len(out.Responses), len(reqs))
+	}
+
+	var missing []int
+	for i, req := range reqs {
+		item := out.Responses[i].Item
+		if item == nil {
+			proto.Reset(req.Msg)
+			missing = append(missing, i)
+			continue
+		}
+		if err := Unmarshal(item, req.Msg); err != nil {
+			return fmt.Errorf("dynabuf: failed to decode read %d of the transaction from table %q: %w", i, req.Table, err)
+		}
+	}
+	if len(missing) > 0 {
+		return &NotFoundError{Indexes: missing}
+	}
+	return nil
+}
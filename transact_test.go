@@ -0,0 +1,77 @@
+package dynabuf_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTransactGet(t *testing.T) {
+	ctx := context.Background()
+
+	client := newUsersTable(t)
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("orders"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("customer"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("sk"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	alice := &testpb.User{Id: "1", Name: "Alice"}
+	order := &testpb.Order{
+		Customer:   "1",
+		Id:         "a",
+		Status:     "OPEN",
+		CreateTime: timestamppb.New(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	_, err = dynabuf.BatchPut(ctx, dynabuf.Table{Client: client, Name: "users"}, []*testpb.User{alice})
+	must.NoError(t, err)
+	_, err = dynabuf.BatchPut(ctx, dynabuf.Table{Client: client, Name: "orders"}, []*testpb.Order{order})
+	must.NoError(t, err)
+
+	orderKey, err := dynabuf.Key(order, []string{"customer", "sk"})
+	must.NoError(t, err)
+
+	var (
+		gotUser  testpb.User
+		gotOrder testpb.Order
+	)
+	err = dynabuf.TransactGet(ctx, client,
+		dynabuf.TypedGet{Table: "users", Key: userKey("1"), Msg: &gotUser},
+		dynabuf.TypedGet{Table: "orders", Key: orderKey, Msg: &gotOrder},
+	)
+	must.NoError(t, err)
+	must.True(t, proto.Equal(alice, &gotUser))
+	must.True(t, proto.Equal(order, &gotOrder))
+
+	// Items that do not exist are reported once the others are decoded.
+	missing := &testpb.User{Id: "stale"}
+	err = dynabuf.TransactGet(ctx, client,
+		dynabuf.TypedGet{Table: "users", Key: userKey("2"), Msg: missing},
+		dynabuf.TypedGet{Table: "orders", Key: orderKey, Msg: &gotOrder},
+	)
+	must.ErrorIs(t, err, dynabuf.ErrItemNotFound)
+	var notFound *dynabuf.NotFoundError
+	must.True(t, errors.As(err, &notFound))
+	must.Eq(t, []int{0}, notFound.Indexes)
+	must.True(t, proto.Equal(&testpb.User{}, missing))
+	must.True(t, proto.Equal(order, &gotOrder))
+}
@@ -0,0 +1,163 @@
+package dynabuf
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultTruncateSegments is the default number of segments scanned at once
+// by [Truncate].
+const defaultTruncateSegments = 4
+
+// truncateOptions holds the configuration of [Truncate], set by the options
+// of a [Config].
+type truncateOptions struct {
+	segments int
+	keys     []string
+}
+
+// WithTruncateSegments scans the table in n segments at once, which
+// defaults to 4.
+func WithTruncateSegments(n int) ConfigOption {
+	return func(c *Config) {
+		c.truncate.segments = n
+	}
+}
+
+// WithTruncateMessageType uses the key attributes annotated with
+// key_attributes on the type of msg, instead of describing the table.
+func WithTruncateMessageType(msg proto.Message) ConfigOption {
+	return func(c *Config) {
+		c.truncate.keys = keyAttributes(msg.ProtoReflect().Descriptor())
+	}
+}
+
+// Truncate deletes every item of the table, and returns the number of
+// items deleted, including when it fails part way. It is meant for test
+// environments, where deleting and creating the table again is slower.
+//
+// The table is scanned in parallel segments, projecting only the key
+// attributes, and the keys of each page deleted in batches. The key
+// attributes are those of the table's key schema, so the client of the
+// table must be able to describe it, unless they are taken from the
+// annotations of a message type with [WithTruncateMessageType]. The options
+// extend the [Table.Config] of the table.
+//
+// # Example
+//
+//	deleted, err := dynabuf.Truncate(ctx, dynabuf.Table{Client: client, Name: "users"})
+func Truncate(ctx context.Context, table Table, opts ...ConfigOption) (int, error) {
+	table.Config = table.Config.with(opts)
+
+	o := truncateOptions{segments: defaultTruncateSegments}
+	if table.Config != nil {
+		o.keys = table.Config.truncate.keys
+		o.segments = cmp.Or(table.Config.truncate.segments, o.segments)
+	}
+
+	keys := o.keys
+	if len(keys) == 0 {
+		var err error
+		if keys, err = table.keySchema(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	names := map[string]string{}
+	projection := make([]string, len(keys))
+	for i, key := range keys {
+		placeholder := fmt.Sprintf("#k%d", i)
+		names[placeholder] = key
+		projection[i] = placeholder
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		deleted int
+		errs    []error
+	)
+	segments := max(o.segments, 1)
+	for segment := range segments {
+		input := &dynamodb.ScanInput{
+			ProjectionExpression:     aws.String(strings.Join(projection, ", ")),
+			ExpressionAttributeNames: names,
+			ConsistentRead:           table.Config.ConsistentRead(true),
+		}
+		if segments > 1 {
+			input.Segment = aws.Int32(int32(segment))
+			input.TotalSegments = aws.Int32(int32(segments))
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := table.selectPages(ctx, input, func(items []map[string]types.AttributeValue) error {
+				requests := make([]types.WriteRequest, len(items))
+				for i, item := range items {
+					key, err := itemKey(item, keys)
+					if err != nil {
+						return fmt.Errorf("dynabuf: failed to delete item: %w", err)
+					}
+					requests[i] = types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}}
+				}
+
+				result, err := table.batchWrite(ctx, requests)
+				mu.Lock()
+				deleted += len(result.Succeeded)
+				mu.Unlock()
+				return err
+			})
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return deleted, errors.Join(errs...)
+}
+
+// keySchema describes the table and returns the names of its key
+// attributes, the partition key and then the sort key, if any.
+func (t Table) keySchema(ctx context.Context) ([]string, error) {
+	client, ok := t.Client.(interface {
+		DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("dynabuf: the key attributes of table %q are unknown, as its client cannot describe it", t.Name)
+	}
+
+	out, err := Do(ctx, t.Config, client.DescribeTable, &dynamodb.DescribeTableInput{TableName: aws.String(t.Name)})
+	if err != nil {
+		return nil, fmt.Errorf("dynabuf: failed to describe table %q: %w", t.Name, err)
+	}
+
+	var partition, sort string
+	for _, k := range out.Table.KeySchema {
+		switch k.KeyType {
+		case types.KeyTypeHash:
+			partition = aws.ToString(k.AttributeName)
+		case types.KeyTypeRange:
+			sort = aws.ToString(k.AttributeName)
+		}
+	}
+	if partition == "" {
+		return nil, fmt.Errorf("dynabuf: table %q has no partition key", t.Name)
+	}
+	if sort == "" {
+		return []string{partition}, nil
+	}
+	return []string{partition, sort}, nil
+}
@@ -0,0 +1,90 @@
+package dynabuf_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+// undescribedClient is a table client which cannot describe tables.
+type undescribedClient struct {
+	client *dynamotest.Client
+}
+
+func (c undescribedClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return c.client.UpdateItem(ctx, params, optFns...)
+}
+
+func (c undescribedClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return c.client.Scan(ctx, params, optFns...)
+}
+
+func (c undescribedClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return c.client.Query(ctx, params, optFns...)
+}
+
+func (c undescribedClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	return c.client.BatchWriteItem(ctx, params, optFns...)
+}
+
+func TestTruncate(t *testing.T) {
+	ctx := context.Background()
+
+	client := newUsersTable(t)
+	table := dynabuf.Table{Client: client, Name: "users"}
+
+	users := make([]*testpb.User, 60)
+	for i := range users {
+		users[i] = &testpb.User{Id: fmt.Sprint(i), Name: "Alice"}
+	}
+	_, err := dynabuf.BatchPut(ctx, table, users)
+	must.NoError(t, err)
+
+	deleted, err := dynabuf.Truncate(ctx, table, dynabuf.WithTruncateSegments(3))
+	must.NoError(t, err)
+	must.Eq(t, 60, deleted)
+
+	out, err := client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("users")})
+	must.NoError(t, err)
+	must.SliceEmpty(t, out.Items)
+
+	// Without a description of the table, the key attributes are those
+	// annotated on the message type.
+	_, err = client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("orders"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("customer"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("sk"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	orders := dynabuf.Table{Client: undescribedClient{client}, Name: "orders"}
+	_, err = dynabuf.BatchPut(ctx, orders, []*testpb.Order{
+		{Customer: "acme", Id: "1"},
+		{Customer: "acme", Id: "2"},
+		{Customer: "globex", Id: "1"},
+	})
+	must.NoError(t, err)
+
+	_, err = dynabuf.Truncate(ctx, orders)
+	must.ErrorContains(t, err, "cannot describe it")
+
+	deleted, err = dynabuf.Truncate(ctx, orders, dynabuf.WithTruncateMessageType(&testpb.Order{}))
+	must.NoError(t, err)
+	must.Eq(t, 3, deleted)
+}
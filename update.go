@@ -0,0 +1,250 @@
+package dynabuf
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// ErrInvalidFieldMask is returned when a field mask names fields that do
+// not exist, or that cannot be updated.
+var ErrInvalidFieldMask = errors.New("dynabuf: invalid field mask")
+
+// UpdateItemInput returns the input of an UpdateItem call updating the
+// fields of the stored message named by mask to their values in msg, as
+// the Update methods of AIP-134 do. keys are the names of the table's key
+// attributes, whose values in msg identify the item.
+//
+// Fields set in msg are set in the item, and fields holding their zero
+// value are removed, as [Marshal] would not store them. Paths of nested
+// fields, and keys of map fields, are separated by dots, such as
+// "address.zip_code" or "labels.env", and only update the nested attribute,
+// which requires its parent to be stored.
+//
+// A nil or empty mask updates the fields set in msg, and a mask of "*"
+// replaces every field. Both leave out key attributes and fields annotated
+// as output only or immutable, with dynabuf's field options or the
+// OUTPUT_ONLY, IMMUTABLE, or IDENTIFIER field behaviors of AIP-203, while
+// masks naming them explicitly return an error wrapping
+// [ErrInvalidFieldMask].
+//
+// Composite attributes composed of updated fields are updated too, from
+// the values of all their fields in msg, which must be set. Fields of
+// composite key attributes cannot be updated.
+//
+// The update is conditional on the item existing, so updating a missing
+// item fails with a [types.ConditionalCheckFailedException] rather than
+// creating it.
+//
+// # Example
+//
+//	input, err := dynabuf.UpdateItemInput("books", req.GetBook(), req.GetUpdateMask(), "name")
+//	if err != nil {
+//	  return nil, status.Error(codes.InvalidArgument, err.Error())
+//	}
+//
+//	out, err := client.UpdateItem(ctx, input)
+func UpdateItemInput(table string, msg proto.Message, mask *fieldmaskpb.FieldMask, keys ...string) (*dynamodb.UpdateItemInput, error) {
+	item, err := marshalProtoMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	key, err := Key(msg, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	md := msg.ProtoReflect().Descriptor()
+
+	var paths [][]string
+	switch {
+	case len(mask.GetPaths()) == 1 && mask.GetPaths()[0] == "*":
+		paths = updatableFields(md, keys, func(string) bool { return true })
+	case len(mask.GetPaths()) == 0:
+		paths = updatableFields(md, keys, func(name string) bool {
+			_, ok := item[name]
+			return ok
+		})
+	default:
+		for _, p := range mask.GetPaths() {
+			path, err := updatePath(md, p, keys)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, path)
+		}
+		if err := checkOverlap(paths); err != nil {
+			return nil, err
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w: no fields to update", ErrInvalidFieldMask)
+	}
+
+	composites, err := compositeAttributes(md)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToMarshal, err)
+	}
+	for _, c := range composites {
+		i := slices.IndexFunc(paths, func(path []string) bool { return c.uses(path[0]) })
+		switch {
+		case i < 0:
+			continue
+		case slices.Contains(keys, c.name):
+			return nil, fmt.Errorf("%w: field %q is part of key attribute %q", ErrInvalidFieldMask, paths[i][0], c.name)
+		}
+		paths = append(paths, []string{c.name})
+	}
+
+	var (
+		set, remove []string
+		names       = map[string]string{"#k0": keys[0]}
+		values      = map[string]types.AttributeValue{}
+		placeholder = map[string]string{}
+	)
+	for _, path := range paths {
+		var (
+			placeholders = make([]string, len(path))
+			v            types.AttributeValue
+			m            = item
+		)
+		for i, name := range path {
+			if placeholder[name] == "" {
+				placeholder[name] = fmt.Sprintf("#u%d", len(placeholder))
+				names[placeholder[name]] = name
+			}
+			placeholders[i] = placeholder[name]
+
+			if m != nil {
+				v = m[name]
+				av, _ := v.(*types.AttributeValueMemberM)
+				m = nil
+				if av != nil {
+					m = av.Value
+				}
+			}
+		}
+
+		p := strings.Join(placeholders, ".")
+		if v == nil {
+			remove = append(remove, p)
+			continue
+		}
+		value := fmt.Sprintf(":u%d", len(values))
+		values[value] = v
+		set = append(set, p+" = "+value)
+	}
+
+	var update []string
+	if len(set) > 0 {
+		update = append(update, "SET "+strings.Join(set, ", "))
+	}
+	if len(remove) > 0 {
+		update = append(update, "REMOVE "+strings.Join(remove, ", "))
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                aws.String(table),
+		Key:                      key,
+		UpdateExpression:         aws.String(strings.Join(update, " ")),
+		ConditionExpression:      aws.String("attribute_exists(#k0)"),
+		ExpressionAttributeNames: names,
+	}
+	if len(values) > 0 {
+		input.ExpressionAttributeValues = values
+	}
+	return input, nil
+}
+
+// updatableFields returns the paths of the top-level fields of md updated
+// by implied and full replacement masks, leaving out key attributes and
+// fields that cannot be updated.
+func updatableFields(md protoreflect.MessageDescriptor, keys []string, include func(name string) bool) [][]string {
+	var paths [][]string
+	fields := md.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if slices.Contains(keys, fd.JSONName()) || fieldBehavior(fd) != "" || !include(fd.JSONName()) {
+			continue
+		}
+		paths = append(paths, []string{fd.JSONName()})
+	}
+	return paths
+}
+
+// updatePath returns the attribute names of a field mask path, returning
+// an error if it does not name a field of md that can be updated.
+func updatePath(md protoreflect.MessageDescriptor, p string, keys []string) ([]string, error) {
+	var (
+		path []string
+		fd   protoreflect.FieldDescriptor
+	)
+	names := strings.Split(p, ".")
+	for i, name := range names {
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("%w: invalid path %q", ErrInvalidFieldMask, p)
+		case fd != nil && fd.IsMap():
+			if fd.MapKey().Kind() != protoreflect.StringKind {
+				return nil, fmt.Errorf("%w: invalid path %q", ErrInvalidFieldMask, p)
+			}
+			path = append(path, name)
+			md, fd = fd.MapValue().Message(), nil
+			continue
+		case md == nil || (fd != nil && fd.IsList()):
+			return nil, fmt.Errorf("%w: field %q has no fields", ErrInvalidFieldMask, strings.Join(path, "."))
+		}
+
+		fd = md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(name)
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("%w: unknown field %q of %s", ErrInvalidFieldMask, name, md.FullName())
+		}
+		if i == 0 && slices.Contains(keys, fd.JSONName()) {
+			return nil, fmt.Errorf("%w: key attribute %q cannot be updated", ErrInvalidFieldMask, fd.JSONName())
+		}
+		if behavior := fieldBehavior(fd); behavior != "" {
+			return nil, fmt.Errorf("%w: field %q is %s", ErrInvalidFieldMask, strings.Join(names[:i+1], "."), behavior)
+		}
+		path = append(path, fd.JSONName())
+		// Well-known types are stored as JSON scalars, so they have no
+		// fields to update.
+		md = fd.Message()
+		if md != nil && strings.HasPrefix(string(md.FullName()), "google.protobuf.") {
+			md = nil
+		}
+	}
+	return path, nil
+}
+
+// checkOverlap returns an error if a path is repeated, or is the parent of
+// another, which DynamoDB does not allow in an update expression.
+func checkOverlap(paths [][]string) error {
+	seen := map[string]bool{}
+	for _, path := range paths {
+		seen[strings.Join(path, ".")] = true
+	}
+	if len(seen) < len(paths) {
+		return fmt.Errorf("%w: paths are repeated", ErrInvalidFieldMask)
+	}
+	for _, p := range slices.Sorted(maps.Keys(seen)) {
+		for parent := p; strings.Contains(parent, "."); {
+			parent = parent[:strings.LastIndex(parent, ".")]
+			if seen[parent] {
+				return fmt.Errorf("%w: path %q overlaps %q", ErrInvalidFieldMask, p, parent)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package dynabuf_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestUpdateItemInput(t *testing.T) {
+	ctx := context.Background()
+
+	stored := &testpb.Book{
+		Name:       "books/1",
+		Title:      "Dune",
+		Author:     "Frank Herbert",
+		Pages:      412,
+		Publisher:  &testpb.Address{Street: "Chilton", ZipCode: 19106},
+		Labels:     map[string]string{"genre": "science fiction", "format": "paperback"},
+		CreateTime: timestamppb.Now(),
+	}
+
+	tests := []struct {
+		name   string
+		update *testpb.Book
+		paths  []string
+		want   *testpb.Book
+	}{
+		{
+			name:   "set and remove",
+			update: &testpb.Book{Name: "books/1", Title: "Dune Messiah"},
+			paths:  []string{"title", "pages"},
+			want: &testpb.Book{
+				Name:       "books/1",
+				Title:      "Dune Messiah",
+				Author:     stored.Author,
+				Publisher:  stored.Publisher,
+				Labels:     stored.Labels,
+				CreateTime: stored.CreateTime,
+			},
+		},
+		{
+			name:   "nested fields and map keys",
+			update: &testpb.Book{Name: "books/1", Publisher: &testpb.Address{ZipCode: 10001}, Labels: map[string]string{"format": "hardcover"}},
+			paths:  []string{"publisher.zip_code", "labels.format", "labels.genre"},
+			want: &testpb.Book{
+				Name:       "books/1",
+				Title:      stored.Title,
+				Author:     stored.Author,
+				Pages:      stored.Pages,
+				Publisher:  &testpb.Address{Street: "Chilton", ZipCode: 10001},
+				Labels:     map[string]string{"format": "hardcover"},
+				CreateTime: stored.CreateTime,
+			},
+		},
+		{
+			name:   "implied mask",
+			update: &testpb.Book{Name: "books/1", Author: "ignored", Pages: 500, CreateTime: timestamppb.Now()},
+			want: &testpb.Book{
+				Name:       "books/1",
+				Title:      stored.Title,
+				Author:     stored.Author,
+				Pages:      500,
+				Publisher:  stored.Publisher,
+				Labels:     stored.Labels,
+				CreateTime: stored.CreateTime,
+			},
+		},
+		{
+			name:   "full replacement",
+			update: &testpb.Book{Name: "books/1", Author: "ignored", Title: "Children of Dune"},
+			paths:  []string{"*"},
+			want: &testpb.Book{
+				Name:       "books/1",
+				Title:      "Children of Dune",
+				Author:     stored.Author,
+				CreateTime: stored.CreateTime,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := dynamotest.NewClient()
+			_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+				TableName: aws.String("books"),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String("name"),
+						KeyType:       types.KeyTypeHash,
+					},
+				},
+			})
+			must.NoError(t, err)
+
+			_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+				TableName: aws.String("books"),
+				Item:      dynabuf.MustMarshalItem(stored),
+			})
+			must.NoError(t, err)
+
+			var mask *fieldmaskpb.FieldMask
+			if test.paths != nil {
+				mask = &fieldmaskpb.FieldMask{Paths: test.paths}
+			}
+			input, err := dynabuf.UpdateItemInput("books", test.update, mask, "name")
+			must.NoError(t, err)
+
+			_, err = client.UpdateItem(ctx, input)
+			must.NoError(t, err)
+
+			got, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+				TableName: aws.String("books"),
+				Key:       dynabuf.MustKey(test.want, []string{"name"}),
+			})
+			must.NoError(t, err)
+
+			var book testpb.Book
+			dynabuf.MustUnmarshal(got.Item, &book)
+			must.True(t, proto.Equal(test.want, &book))
+		})
+	}
+}
+
+func TestUpdateItemInputMissingItem(t *testing.T) {
+	ctx := context.Background()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("books"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("name"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	input, err := dynabuf.UpdateItemInput("books", &testpb.Book{Name: "books/1", Title: "Dune"}, nil, "name")
+	must.NoError(t, err)
+
+	_, err = client.UpdateItem(ctx, input)
+	var ccf *types.ConditionalCheckFailedException
+	must.True(t, errors.As(err, &ccf))
+}
+
+func TestUpdateItemInputInvalid(t *testing.T) {
+	tests := []struct {
+		paths []string
+		err   string
+	}{
+		{paths: []string{"isbn"}, err: `unknown field "isbn" of dynabuf.test.v1.Book`},
+		{paths: []string{"name"}, err: `key attribute "name" cannot be updated`},
+		{paths: []string{"author"}, err: `field "author" is immutable`},
+		{paths: []string{"create_time"}, err: `field "create_time" is output only`},
+		{paths: []string{"create_time.seconds"}, err: `field "create_time" is output only`},
+		{paths: []string{"title.first"}, err: `field "title" has no fields`},
+		{paths: []string{"labels.env.x"}, err: `field "labels.env" has no fields`},
+		{paths: []string{"publisher", "publisher.street"}, err: `path "publisher.street" overlaps "publisher"`},
+		{paths: []string{"title", "title"}, err: "paths are repeated"},
+		{paths: []string{"title."}, err: `invalid path "title."`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.err, func(t *testing.T) {
+			_, err := dynabuf.UpdateItemInput("books", &testpb.Book{Name: "books/1"}, &fieldmaskpb.FieldMask{Paths: test.paths}, "name")
+			must.ErrorIs(t, err, dynabuf.ErrInvalidFieldMask)
+			must.StrContains(t, err.Error(), test.err)
+		})
+	}
+
+	_, err := dynabuf.UpdateItemInput("users", &testpb.User{Id: "1", Name: "Alice"}, &fieldmaskpb.FieldMask{Paths: []string{"create_time"}}, "name")
+	must.ErrorIs(t, err, dynabuf.ErrInvalidFieldMask)
+	must.StrContains(t, err.Error(), `field "create_time" is output only`)
+
+	_, err = dynabuf.UpdateItemInput("users", &testpb.User{Id: "1", Name: "Alice"}, &fieldmaskpb.FieldMask{Paths: []string{"id"}}, "name")
+	must.ErrorIs(t, err, dynabuf.ErrInvalidFieldMask)
+	must.StrContains(t, err.Error(), `field "id" is immutable`)
+
+	_, err = dynabuf.UpdateItemInput("books", &testpb.Book{Name: "books/1", Author: "x"}, nil, "name")
+	must.ErrorIs(t, err, dynabuf.ErrInvalidFieldMask)
+	must.StrContains(t, err.Error(), "no fields to update")
+}
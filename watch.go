@@ -0,0 +1,106 @@
+package dynabuf
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// GetItemAPIClient is the subset of the DynamoDB API used by [Watch].
+type GetItemAPIClient interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+}
+
+// Watch polls the item with the given key every interval, and yields the
+// decoded message each time it changes. It is useful to follow an item
+// where [DynamoDB Streams] are unavailable, such as with DynamoDB Local.
+//
+// The item is read with strongly consistent reads. The first poll yields
+// the item as it currently is, and following polls only yield when the
+// decoded message differs from the previously yielded one. Polls that find
+// no item are not yielded.
+//
+// Errors are yielded along with a nil message, and polling continues until
+// ctx is done or the caller stops iterating. An interval that is not
+// positive yields an error and stops. The requests are configured by opts,
+// whose [WithConsistentReads] can allow eventually consistent reads.
+//
+// # Example
+//
+//	key := map[string]types.AttributeValue{
+//	  "id": &types.AttributeValueMemberS{Value: "123"},
+//	}
+//
+//	for user, err := range dynabuf.Watch[*example.User](ctx, client, "users", key, time.Second) {
+//	  if err != nil {
+//	    log.Println(err)
+//	    continue
+//	  }
+//	  fmt.Println(user)
+//	}
+//
+// [DynamoDB Streams]: https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/Streams.html
+func Watch[T proto.Message](ctx context.Context, client GetItemAPIClient, table string, key map[string]types.AttributeValue, interval time.Duration, opts ...ConfigOption) iter.Seq2[T, error] {
+	cfg := NewConfig(opts...)
+
+	return func(yield func(T, error) bool) {
+		var (
+			zero T
+			last proto.Message
+		)
+
+		if interval <= 0 {
+			yield(zero, fmt.Errorf("dynabuf: watch interval %s is not positive", interval))
+			return
+		}
+
+		ctx := ContextWithMessageType(ctx, zero.ProtoReflect().Interface())
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			out, err := Do(ctx, cfg, client.GetItem, &dynamodb.GetItemInput{
+				TableName:      aws.String(table),
+				Key:            key,
+				ConsistentRead: cfg.ConsistentRead(true),
+			})
+
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				if !yield(zero, fmt.Errorf("dynabuf: failed to get watched item: %w", err)) {
+					return
+				}
+			case len(out.Item) > 0:
+				msg := zero.ProtoReflect().New().Interface().(T)
+				if err := Unmarshal(out.Item, msg); err != nil {
+					if !yield(zero, err) {
+						return
+					}
+					break
+				}
+
+				if last == nil || !proto.Equal(last, msg) {
+					last = msg
+					if !yield(msg, nil) {
+						return
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package dynabuf_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/shoenig/test/must"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := dynamotest.NewClient()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("items"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	put := func(value string) {
+		_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String("items"),
+			Item: map[string]types.AttributeValue{
+				"id":    &types.AttributeValueMemberS{Value: "1"},
+				"value": &types.AttributeValueMemberS{Value: value},
+			},
+		})
+		must.NoError(t, err)
+	}
+
+	put("first")
+
+	key := map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberS{Value: "1"},
+	}
+
+	var seen []string
+	for msg, err := range dynabuf.Watch[*structpb.Struct](ctx, client, "items", key, time.Millisecond) {
+		must.NoError(t, err)
+
+		seen = append(seen, msg.Fields["value"].GetStringValue())
+		if len(seen) == 2 {
+			break
+		}
+
+		// Give the watcher a few unchanged polls before the update.
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			put("second")
+		}()
+	}
+
+	must.Eq(t, []string{"first", "second"}, seen)
+}
+
+func TestWatchInterval(t *testing.T) {
+	key := map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberS{Value: "1"},
+	}
+
+	var errs []error
+	for _, err := range dynabuf.Watch[*structpb.Struct](context.Background(), dynamotest.NewClient(), "items", key, 0) {
+		errs = append(errs, err)
+	}
+	must.SliceLen(t, 1, errs)
+	must.ErrorContains(t, errs[0], "watch interval 0s is not positive")
+}
+
+// consistencyClient records whether the reads it serves are strongly
+// consistent.
+type consistencyClient struct {
+	*dynamotest.Client
+	consistent []bool
+}
+
+func (c *consistencyClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	c.consistent = append(c.consistent, aws.ToBool(params.ConsistentRead))
+	return c.Client.GetItem(ctx, params, optFns...)
+}
+
+func TestWatchConfig(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := &consistencyClient{Client: dynamotest.NewClient()}
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("items"),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	must.NoError(t, err)
+
+	key := map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberS{Value: "1"},
+	}
+
+	// The reads are configured by the options.
+	for _, err := range dynabuf.Watch[*structpb.Struct](ctx, client, "items", key, time.Millisecond, dynabuf.WithConsistentReads(false)) {
+		must.NoError(t, err)
+	}
+	must.SliceNotEmpty(t, client.consistent)
+	must.SliceNotContains(t, client.consistent, true)
+}
@@ -0,0 +1,142 @@
+package dynabuf
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// WriteLimiter is a token bucket of write capacity units, limiting the
+// capacity consumed by the batch writes of the tables using it as their
+// [Table.WriteLimiter], so backfills and migrations leave the provisioned
+// capacity of a table to production traffic.
+//
+// Before a batch is sent, the units it is expected to consume, one per KB
+// of every item put, rounded up, and one per item deleted, are taken from
+// the bucket, waiting for it to refill if it is empty. Once the batch is
+// written, the bucket is corrected by the capacity DynamoDB reports the
+// batch consumed, which accounts for the sizes of items deleted and for
+// writes to secondary indexes, so the limit adapts to the actual cost of
+// the writes.
+//
+// A WriteLimiter is safe for concurrent use, and can be shared by tables
+// on the same provisioned capacity, such as the tables of a copy.
+//
+// # Example
+//
+//	// Leave three quarters of the table's 400 WCU to production traffic.
+//	table := dynabuf.Table{
+//	  Client:       client,
+//	  Name:         "users",
+//	  WriteLimiter: dynabuf.NewWriteLimiter(100),
+//	}
+type WriteLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// NewWriteLimiter returns a [WriteLimiter] allowing unitsPerSecond write
+// capacity units per second, with bursts of up to one second of units.
+func NewWriteLimiter(unitsPerSecond float64) *WriteLimiter {
+	return &WriteLimiter{
+		rate:   unitsPerSecond,
+		tokens: unitsPerSecond,
+		last:   time.Now(),
+	}
+}
+
+// Rate returns the write capacity units allowed per second.
+func (l *WriteLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// SetRate changes the write capacity units allowed per second, such as when
+// the provisioned capacity of the table changes.
+func (l *WriteLimiter) SetRate(unitsPerSecond float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill()
+	l.rate = unitsPerSecond
+	l.tokens = min(l.tokens, l.rate)
+}
+
+// Wait takes units from the bucket, waiting until they are refilled if the
+// bucket holds fewer. Requests larger than the bucket are allowed once it
+// is full, leaving it in debt. An error is returned, and the units given
+// back, if ctx is done first.
+func (l *WriteLimiter) Wait(ctx context.Context, units float64) error {
+	l.mu.Lock()
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return fmt.Errorf("dynabuf: write limiter rate %v is not positive", l.rate)
+	}
+	l.refill()
+	l.tokens -= units
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.adjust(units)
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// refill adds the units accrued since the last refill, up to one second of
+// units. l.mu must be held.
+func (l *WriteLimiter) refill() {
+	now := time.Now()
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, l.rate)
+	l.last = now
+}
+
+// adjust adds units to the bucket, or takes them if negative, correcting
+// the units taken for a batch once its consumed capacity is known.
+func (l *WriteLimiter) adjust(units float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill()
+	l.tokens = min(l.tokens+units, l.rate)
+}
+
+// writeUnits returns the write capacity units the requests are expected to
+// consume.
+func writeUnits(requests []types.WriteRequest) float64 {
+	var units float64
+	for _, r := range requests {
+		if r.PutRequest == nil {
+			units++
+			continue
+		}
+		units += max(1, math.Ceil(float64(itemSize(r.PutRequest.Item))/1024))
+	}
+	return units
+}
+
+// consumedUnits returns the capacity units consumed by the table, and
+// whether DynamoDB reported any.
+func consumedUnits(consumed []types.ConsumedCapacity, table string) (float64, bool) {
+	for _, c := range consumed {
+		if c.TableName != nil && *c.TableName == table && c.CapacityUnits != nil {
+			return *c.CapacityUnits, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,127 @@
+package dynabuf_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/picatz/dynabuf"
+	"github.com/picatz/dynabuf/internal/dynamotest"
+	"github.com/picatz/dynabuf/internal/testpb"
+	"github.com/shoenig/test/must"
+)
+
+// capacityClient reports batch writes consuming factor units per request.
+type capacityClient struct {
+	*dynamotest.Client
+
+	factor float64
+	asked  []types.ReturnConsumedCapacity
+}
+
+func (c *capacityClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	c.asked = append(c.asked, params.ReturnConsumedCapacity)
+
+	out, err := c.Client.BatchWriteItem(ctx, params, optFns...)
+	if err != nil || c.factor == 0 {
+		return out, err
+	}
+	for table, requests := range params.RequestItems {
+		out.ConsumedCapacity = append(out.ConsumedCapacity, types.ConsumedCapacity{
+			TableName:     aws.String(table),
+			CapacityUnits: aws.Float64(c.factor * float64(len(requests))),
+		})
+	}
+	return out, nil
+}
+
+func TestWriteLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		users   int
+		rate    float64
+		factor  float64
+		atLeast time.Duration
+		atMost  time.Duration
+	}{
+		{
+			name:   "within burst",
+			users:  100,
+			rate:   1000,
+			atMost: 100 * time.Millisecond,
+		},
+		{
+			// 50 units are left after the burst, taking half a second.
+			name:    "estimated",
+			users:   150,
+			rate:    100,
+			atLeast: 400 * time.Millisecond,
+			atMost:  900 * time.Millisecond,
+		},
+		{
+			// Batches consume twice the units estimated, so the limiter
+			// is in debt after the first two batches.
+			name:    "consumed",
+			users:   100,
+			rate:    100,
+			factor:  2,
+			atLeast: 650 * time.Millisecond,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &capacityClient{Client: newUsersTable(t), factor: test.factor}
+			table := dynabuf.Table{
+				Client:       client,
+				Name:         "users",
+				WriteLimiter: dynabuf.NewWriteLimiter(test.rate),
+			}
+			users := make([]*testpb.User, test.users)
+			for i := range users {
+				users[i] = &testpb.User{Id: fmt.Sprint(i)}
+			}
+
+			start := time.Now()
+			result, err := dynabuf.BatchPut(ctx, table, users)
+			elapsed := time.Since(start)
+
+			must.NoError(t, err)
+			must.SliceLen(t, len(users), result.Succeeded)
+			must.GreaterEq(t, test.atLeast, elapsed)
+			if test.atMost > 0 {
+				must.Less(t, test.atMost, elapsed)
+			}
+			for _, asked := range client.asked {
+				must.Eq(t, types.ReturnConsumedCapacityTotal, asked)
+			}
+		})
+	}
+}
+
+func TestWriteLimiterWait(t *testing.T) {
+	limiter := dynabuf.NewWriteLimiter(10)
+	must.NoError(t, limiter.Wait(context.Background(), 10))
+
+	// The bucket is empty, so waiting for more fails once the context is
+	// done, giving the units back.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	must.ErrorIs(t, limiter.Wait(ctx, 10), context.DeadlineExceeded)
+
+	limiter.SetRate(1000)
+	must.Eq(t, 1000, limiter.Rate())
+
+	start := time.Now()
+	must.NoError(t, limiter.Wait(context.Background(), 100))
+	must.Less(t, 200*time.Millisecond, time.Since(start))
+
+	limiter.SetRate(0)
+	must.ErrorContains(t, limiter.Wait(context.Background(), 1), "not positive")
+}